@@ -1,243 +0,0 @@
-package api
-
-import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
-
-	"github.com/achronon/cvps/internal/config"
-	"github.com/achronon/cvps/internal/version"
-)
-
-// Client is the HTTP client for the ClaudeVPS API
-type Client struct {
-	baseURL    string
-	apiKey     string
-	token      string
-	httpClient *http.Client
-	verbose    bool
-}
-
-// ClientOption is a function that configures a Client
-type ClientOption func(*Client)
-
-// WithVerbose enables verbose logging
-func WithVerbose(verbose bool) ClientOption {
-	return func(c *Client) {
-		c.verbose = verbose
-	}
-}
-
-// WithTimeout sets the HTTP client timeout
-func WithTimeout(timeout time.Duration) ClientOption {
-	return func(c *Client) {
-		c.httpClient.Timeout = timeout
-	}
-}
-
-// NewClient creates a new API client with an API key
-func NewClient(baseURL, apiKey string, opts ...ClientOption) *Client {
-	c := &Client{
-		baseURL: baseURL,
-		apiKey:  apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-
-	for _, opt := range opts {
-		opt(c)
-	}
-
-	return c
-}
-
-// NewClientWithToken creates a new API client with an OAuth access token
-func NewClientWithToken(baseURL, token string, opts ...ClientOption) *Client {
-	c := &Client{
-		baseURL: baseURL,
-		token:   token,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-
-	for _, opt := range opts {
-		opt(c)
-	}
-
-	return c
-}
-
-// NewClientFromConfig creates a client from config (tries token first, then API key)
-func NewClientFromConfig(cfg *config.Config, opts ...ClientOption) *Client {
-	if cfg.AccessToken != "" {
-		return NewClientWithToken(cfg.APIBaseURL, cfg.AccessToken, opts...)
-	}
-	return NewClient(cfg.APIBaseURL, cfg.APIKey, opts...)
-}
-
-// doAuthenticatedRequest adds authentication headers to a request and executes it
-func (c *Client) doAuthenticatedRequest(req *http.Request) (*http.Response, error) {
-	// Set auth header
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
-	} else if c.apiKey != "" {
-		req.Header.Set("X-API-Key", c.apiKey)
-	}
-
-	// Set common headers
-	req.Header.Set("User-Agent", fmt.Sprintf("cvps-cli/%s", version.Version))
-	if req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Accept", "application/json")
-
-	if c.verbose {
-		fmt.Printf("-> %s %s\n", req.Method, req.URL)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if c.verbose {
-		fmt.Printf("<- %d %s\n", resp.StatusCode, resp.Status)
-	}
-
-	return resp, nil
-}
-
-// Get performs a GET request
-func (c *Client) Get(ctx context.Context, path string, result interface{}) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.doAuthenticatedRequest(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if err := c.checkResponse(resp); err != nil {
-		return err
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// Post performs a POST request
-func (c *Client) Post(ctx context.Context, path string, body interface{}, result interface{}) error {
-	var bodyReader io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to encode request: %w", err)
-		}
-		bodyReader = bytes.NewReader(data)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bodyReader)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.doAuthenticatedRequest(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if err := c.checkResponse(resp); err != nil {
-		return err
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// Delete performs a DELETE request
-func (c *Client) Delete(ctx context.Context, path string) error {
-	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+path, nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.doAuthenticatedRequest(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return c.checkResponse(resp)
-}
-
-// Patch performs a PATCH request
-func (c *Client) Patch(ctx context.Context, path string, body interface{}, result interface{}) error {
-	var bodyReader io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to encode request: %w", err)
-		}
-		bodyReader = bytes.NewReader(data)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "PATCH", c.baseURL+path, bodyReader)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.doAuthenticatedRequest(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if err := c.checkResponse(resp); err != nil {
-		return err
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func (c *Client) checkResponse(resp *http.Response) error {
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return nil
-	}
-
-	var apiErr APIError
-	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("unexpected status: %d", resp.StatusCode),
-		}
-	}
-
-	apiErr.StatusCode = resp.StatusCode
-	return &apiErr
-}
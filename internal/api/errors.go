@@ -1,38 +0,0 @@
-package api
-
-import "fmt"
-
-type APIError struct {
-	StatusCode int    `json:"-"`
-	Message    string `json:"message"`
-	Code       string `json:"code,omitempty"`
-	Details    any    `json:"details,omitempty"`
-}
-
-func (e *APIError) Error() string {
-	if e.Code != "" {
-		return fmt.Sprintf("%s: %s", e.Code, e.Message)
-	}
-	return e.Message
-}
-
-func IsNotFound(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 404
-	}
-	return false
-}
-
-func IsUnauthorized(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 401
-	}
-	return false
-}
-
-func IsForbidden(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 403
-	}
-	return false
-}
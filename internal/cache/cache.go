@@ -0,0 +1,107 @@
+// Package cache stores the last sandbox list seen from the API under
+// ~/.cvps/cache so completion and offline commands can work without it.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+const sandboxesFile = "sandboxes.json"
+
+// Sandboxes is a cached sandbox list and when it was fetched
+type Sandboxes struct {
+	FetchedAt time.Time           `json:"fetched_at"`
+	Sandboxes []claudevps.Sandbox `json:"sandboxes"`
+}
+
+// Age returns how long ago the list was fetched
+func (s *Sandboxes) Age() time.Duration {
+	return time.Since(s.FetchedAt)
+}
+
+// Find returns the cached sandbox with the given ID or name (case-insensitive)
+func (s *Sandboxes) Find(ref string) *claudevps.Sandbox {
+	for i := range s.Sandboxes {
+		if s.Sandboxes[i].ID == ref || strings.EqualFold(s.Sandboxes[i].Name, ref) {
+			return &s.Sandboxes[i]
+		}
+	}
+	return nil
+}
+
+// Dir returns the cache directory
+func Dir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+// LoadSandboxes reads the cached list. It returns nil without error if nothing is cached.
+func LoadSandboxes() (*Sandboxes, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, sandboxesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sandbox cache: %w", err)
+	}
+
+	var s Sandboxes
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse sandbox cache: %w", err)
+	}
+	return &s, nil
+}
+
+// SaveSandboxes replaces the cached list with a complete listing from the API
+func SaveSandboxes(sandboxes []claudevps.Sandbox) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(Sandboxes{FetchedAt: time.Now().UTC(), Sandboxes: sandboxes})
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir, sandboxesFile, data); err != nil {
+		return fmt.Errorf("failed to write sandbox cache: %w", err)
+	}
+	return nil
+}
+
+// writeFile writes name in dir, then renames it into place so a concurrent
+// reader never sees a partial file
+func writeFile(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
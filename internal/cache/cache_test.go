@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSaveLoadSandboxes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	s, err := LoadSandboxes()
+	if err != nil || s != nil {
+		t.Fatalf("LoadSandboxes() with no cache = %v, %v", s, err)
+	}
+
+	err = SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-1", Name: "Web", SSHHost: "ssh.example.com", SSHPort: 2222}})
+	if err != nil {
+		t.Fatalf("SaveSandboxes() error = %v", err)
+	}
+
+	s, err = LoadSandboxes()
+	if err != nil {
+		t.Fatalf("LoadSandboxes() error = %v", err)
+	}
+	if s.FetchedAt.IsZero() || s.Age() < 0 {
+		t.Errorf("FetchedAt = %v", s.FetchedAt)
+	}
+	if got := s.Find("web"); got == nil || got.SSHPort != 2222 {
+		t.Errorf("Find(web) = %+v", got)
+	}
+	if s.Find("sbx-1") == nil || s.Find("other") != nil {
+		t.Error("Find() by ID mismatch")
+	}
+}
+
+func TestPendingDeletions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if p, err := LoadPendingDeletions(); err != nil || p != nil {
+		t.Fatalf("LoadPendingDeletions() with nothing pending = %v, %v", p, err)
+	}
+
+	AddPendingDeletion(PendingDeletion{SandboxID: "sbx-1", Name: "web"})
+	AddPendingDeletion(PendingDeletion{SandboxID: "sbx-2", Name: "db"})
+	AddPendingDeletion(PendingDeletion{SandboxID: "sbx-1", Name: "web", Purge: true})
+
+	pending, err := LoadPendingDeletions()
+	if err != nil {
+		t.Fatalf("LoadPendingDeletions() error = %v", err)
+	}
+	if len(pending) != 2 || pending[0].SandboxID != "sbx-1" || !pending[0].Purge {
+		t.Errorf("Unexpected pending deletions: %+v", pending)
+	}
+
+	if err := SavePendingDeletions(nil); err != nil {
+		t.Fatalf("SavePendingDeletions(nil) error = %v", err)
+	}
+	if p, _ := LoadPendingDeletions(); p != nil {
+		t.Errorf("Expected no pending deletions, got %+v", p)
+	}
+}
+
+func TestSaveLoadListing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if l, err := LoadListing("sbx-1", "/workspace/"); err != nil || l != nil {
+		t.Fatalf("LoadListing() with no cache = %v, %v", l, err)
+	}
+	if err := SaveListing("sbx-1", "/workspace/", []ListingEntry{{Name: "src", IsDir: true}}); err != nil {
+		t.Fatalf("SaveListing() error = %v", err)
+	}
+
+	l, err := LoadListing("sbx-1", "/workspace/")
+	if err != nil || l == nil || len(l.Entries) != 1 || !l.Entries[0].IsDir {
+		t.Fatalf("LoadListing() = %+v, %v", l, err)
+	}
+	if l, _ := LoadListing("sbx-2", "/workspace/"); l != nil {
+		t.Errorf("LoadListing() of another sandbox = %+v", l)
+	}
+}
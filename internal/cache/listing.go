@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const listingsFile = "listings.json"
+
+// ListingTTL is how long a cached directory listing is used
+const ListingTTL = time.Minute
+
+// ListingEntry is a file in a cached directory listing
+type ListingEntry struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"is_dir,omitempty"`
+}
+
+// Listing is a sandbox directory as it was listed at FetchedAt
+type Listing struct {
+	FetchedAt time.Time      `json:"fetched_at"`
+	Entries   []ListingEntry `json:"entries"`
+}
+
+func listingKey(sandboxID, dir string) string {
+	return sandboxID + ":" + dir
+}
+
+func loadListings(dir string) (map[string]Listing, error) {
+	data, err := os.ReadFile(filepath.Join(dir, listingsFile))
+	if os.IsNotExist(err) {
+		return map[string]Listing{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read listing cache: %w", err)
+	}
+	listings := map[string]Listing{}
+	if err := json.Unmarshal(data, &listings); err != nil {
+		return nil, fmt.Errorf("failed to parse listing cache: %w", err)
+	}
+	return listings, nil
+}
+
+// LoadListing returns the cached listing of dir in a sandbox, or nil if there
+// is none younger than ListingTTL
+func LoadListing(sandboxID, dir string) (*Listing, error) {
+	cacheDir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	listings, err := loadListings(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := listings[listingKey(sandboxID, dir)]
+	if !ok || time.Since(l.FetchedAt) > ListingTTL {
+		return nil, nil
+	}
+	return &l, nil
+}
+
+// SaveListing caches the listing of dir in a sandbox, dropping expired ones
+func SaveListing(sandboxID, dir string, entries []ListingEntry) error {
+	cacheDir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	listings, err := loadListings(cacheDir)
+	if err != nil {
+		listings = map[string]Listing{}
+	}
+	for key, l := range listings {
+		if time.Since(l.FetchedAt) > ListingTTL {
+			delete(listings, key)
+		}
+	}
+	listings[listingKey(sandboxID, dir)] = Listing{FetchedAt: time.Now().UTC(), Entries: entries}
+
+	data, err := json.Marshal(listings)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(cacheDir, listingsFile, data); err != nil {
+		return fmt.Errorf("failed to write listing cache: %w", err)
+	}
+	return nil
+}
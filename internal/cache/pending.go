@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pendingDeletionsFile = "pending-deletions.json"
+
+// PendingDeletion is a sandbox whose deletion was requested without waiting
+// for it to finish ('cvps down --no-wait')
+type PendingDeletion struct {
+	SandboxID   string    `json:"sandbox_id"`
+	Name        string    `json:"name"`
+	Purge       bool      `json:"purge,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// LoadPendingDeletions reads the deletions not yet confirmed
+func LoadPendingDeletions() ([]PendingDeletion, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, pendingDeletionsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending deletions: %w", err)
+	}
+
+	var pending []PendingDeletion
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse pending deletions: %w", err)
+	}
+	return pending, nil
+}
+
+// SavePendingDeletions replaces the pending deletions, removing the file
+// when none are left
+func SavePendingDeletions(pending []PendingDeletion) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		if err := os.Remove(filepath.Join(dir, pendingDeletionsFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir, pendingDeletionsFile, data); err != nil {
+		return fmt.Errorf("failed to write pending deletions: %w", err)
+	}
+	return nil
+}
+
+// AddPendingDeletion records a deletion, replacing an earlier record of the
+// same sandbox
+func AddPendingDeletion(p PendingDeletion) error {
+	pending, err := LoadPendingDeletions()
+	if err != nil {
+		return err
+	}
+	out := []PendingDeletion{p}
+	for _, existing := range pending {
+		if existing.SandboxID != p.SandboxID {
+			out = append(out, existing)
+		}
+	}
+	return SavePendingDeletions(out)
+}
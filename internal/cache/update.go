@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const updateCheckFile = "update-check.json"
+
+// UpdateCheck is the result of the last check for a newer cvps release
+type UpdateCheck struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	LatestVersion string    `json:"latest_version,omitempty"`
+}
+
+// LoadUpdateCheck reads the last update check. It returns nil without error
+// if there has been none.
+func LoadUpdateCheck() (*UpdateCheck, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, updateCheckFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read update check: %w", err)
+	}
+
+	var check UpdateCheck
+	if err := json.Unmarshal(data, &check); err != nil {
+		return nil, fmt.Errorf("failed to parse update check: %w", err)
+	}
+	return &check, nil
+}
+
+// SaveUpdateCheck records the result of an update check
+func SaveUpdateCheck(check *UpdateCheck) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(check, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir, updateCheckFile, data); err != nil {
+		return fmt.Errorf("failed to write update check: %w", err)
+	}
+	return nil
+}
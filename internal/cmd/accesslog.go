@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	accessLogSince time.Duration
+	accessLogLimit int
+	accessLogJSON  bool
+)
+
+var accessLogCmd = &cobra.Command{
+	Use:   "access-log [sandbox]",
+	Short: "List SSH and terminal connections to a sandbox",
+	Long: `List who connected to a sandbox over SSH or the web terminal, when, from which
+IP address and for how long, newest first.
+
+Useful on shared sandboxes and for security reviews. Connections still open
+are shown as active.`,
+	Example: `  # Connections to the current sandbox in the last week
+  cvps access-log
+
+  # The last 30 days of a named sandbox, as JSON
+  cvps access-log web --since 720h --json`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runAccessLog,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(accessLogCmd)
+
+	accessLogCmd.Flags().DurationVar(&accessLogSince, "since", 7*24*time.Hour, "show connections started within this long")
+	accessLogCmd.Flags().IntVar(&accessLogLimit, "limit", 100, "maximum number of connections to show")
+	accessLogCmd.Flags().BoolVar(&accessLogJSON, "json", false, "output in JSON format")
+}
+
+func runAccessLog(cmd *cobra.Command, args []string) error {
+	if accessLogSince <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if accessLogLimit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	id, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+
+	entries, err := client.ListAccessLog(ctx, id, time.Now().Add(-accessLogSince), accessLogLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get access log: %w", err)
+	}
+
+	if accessLogJSON {
+		if entries == nil {
+			entries = []claudevps.AccessLogEntry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No connections to %s in the last %s\n", id, shortDuration(accessLogSince.Round(time.Minute)))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tUSER\tFROM\tTYPE\tDURATION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatTime(e.StartedAt), e.User, e.SourceIP, e.Kind, accessDuration(e))
+	}
+	w.Flush()
+
+	if len(entries) == accessLogLimit {
+		color.Yellow("\n⚠ Showing the newest %d connections; raise --limit to see more", accessLogLimit)
+	}
+	return nil
+}
+
+// accessDuration formats how long a connection lasted, marking open ones
+func accessDuration(e claudevps.AccessLogEntry) string {
+	d := "<1m"
+	if e.Duration() >= time.Minute {
+		d = shortDuration(e.Duration().Truncate(time.Minute))
+	}
+	if e.Active() {
+		return color.GreenString("active") + " (" + d + ")"
+	}
+	return d
+}
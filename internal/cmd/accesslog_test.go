@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestAccessDuration(t *testing.T) {
+	tests := []struct {
+		entry claudevps.AccessLogEntry
+		want  string
+	}{
+		{claudevps.AccessLogEntry{EndedAt: "2024-03-29T10:00:00Z", DurationSeconds: 20}, "<1m"},
+		{claudevps.AccessLogEntry{EndedAt: "2024-03-29T10:00:00Z", DurationSeconds: 5430}, "1h30m"},
+		{claudevps.AccessLogEntry{DurationSeconds: 600}, "active (10m)"},
+	}
+	for _, tt := range tests {
+		if got := accessDuration(tt.entry); got != tt.want {
+			t.Errorf("accessDuration(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestRunAccessLog(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-1/access-log" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("since") == "" || r.URL.Query().Get("limit") != "2" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.AccessLog{Data: []claudevps.AccessLogEntry{
+			{ID: "acc-2", Kind: "terminal", User: "bob@example.com", SourceIP: "198.51.100.7", StartedAt: "2024-03-29T17:00:00Z", DurationSeconds: 600},
+			{ID: "acc-1", Kind: "ssh", User: "alice@example.com", SourceIP: "203.0.113.4", StartedAt: "2024-03-29T09:00:00Z", EndedAt: "2024-03-29T10:30:00Z", DurationSeconds: 5400},
+		}})
+	}))
+
+	accessLogCmd.Flags().Set("limit", "2")
+	defer func() {
+		accessLogLimit = 100
+		accessLogCmd.Flags().Lookup("limit").Changed = false
+	}()
+
+	out, err := captureStdout(t, func() error { return runAccessLog(accessLogCmd, []string{"sbx-1"}) })
+	if err != nil {
+		t.Fatalf("runAccessLog() error = %v", err)
+	}
+	for _, want := range []string{"alice@example.com", "203.0.113.4", "1h30m", "bob@example.com", "active"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/spf13/cobra"
+)
+
+var aliasJSON bool
+
+// aliasNamePattern keeps alias names usable as config keys and command names
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage command aliases",
+	Long: `Aliases are shortcuts for commands and their flags, stored under aliases:
+in the config. 'cvps <alias> [args]' runs the expansion followed by args.
+Aliases cannot replace built-in commands and do not expand inside other
+aliases.`,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases",
+	Args:  cobra.NoArgs,
+	RunE:  runAliasList,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <command>",
+	Short: "Create or replace an alias",
+	Example: `  # 'cvps all' lists every sandbox
+  cvps alias set all "status --all"
+
+  # 'cvps sshc' connects over SSH
+  cvps alias set sshc "connect --method ssh"
+
+  # Quote arguments that contain spaces
+  cvps alias set top "exec -- sh -c 'top -bn1 | head -20'"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAliasSet,
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an alias",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAliasRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+
+	aliasListCmd.Flags().BoolVar(&aliasJSON, "json", false, "output in JSON format")
+}
+
+// splitCommandLine splits s into arguments at unquoted whitespace. Single
+// quotes keep everything literally; double quotes allow \" and \\ escapes.
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				cur.WriteRune(runes[i])
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// expandAlias replaces an alias in args[0] with its expansion. It reports
+// whether an alias was expanded.
+func expandAlias(args []string, aliases map[string]string) ([]string, bool, error) {
+	if len(args) == 0 || isBuiltinCommand(args[0]) {
+		return args, false, nil
+	}
+	expansion, ok := aliases[args[0]]
+	if !ok {
+		return args, false, nil
+	}
+
+	expanded, err := splitCommandLine(expansion)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid alias %s: %w", args[0], err)
+	}
+	if len(expanded) == 0 {
+		return nil, false, fmt.Errorf("alias %s is empty", args[0])
+	}
+	return append(expanded, args[1:]...), true, nil
+}
+
+// expandConfiguredAlias expands args with the aliases in the config. Config
+// errors are left for the command itself to report.
+func expandConfiguredAlias(args []string) ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Aliases) == 0 {
+		return args, nil
+	}
+	expanded, ok, err := expandAlias(args, cfg.Aliases)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		debuglog.Printf("alias: %s expanded to %q", args[0], expanded)
+	}
+	return expanded, nil
+}
+
+func runAliasList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if aliasJSON {
+		aliases := cfg.Aliases
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(aliases)
+	}
+
+	if len(cfg.Aliases) == 0 {
+		fmt.Println("No aliases defined. Run 'cvps alias set <name> <command>' to add one.")
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALIAS\tCOMMAND")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, cfg.Aliases[name])
+	}
+	w.Flush()
+	return nil
+}
+
+func runAliasSet(cmd *cobra.Command, args []string) error {
+	name, expansion := args[0], strings.TrimSpace(args[1])
+
+	if !aliasNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid alias name %q: use lowercase letters, digits and dashes", name)
+	}
+	if isBuiltinCommand(name) {
+		return fmt.Errorf("%s is a built-in command and cannot be aliased", name)
+	}
+	fields, err := splitCommandLine(expansion)
+	if err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("command must not be empty")
+	}
+	if fields[0] == "cvps" {
+		return fmt.Errorf("leave out the leading 'cvps': cvps alias set %s %q", name, strings.Join(fields[1:], " "))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = make(map[string]string)
+	}
+	cfg.Aliases[name] = expansion
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ 'cvps %s' now runs 'cvps %s'\n", name, expansion)
+	return nil
+}
+
+func runAliasRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Aliases[args[0]]; !ok {
+		return fmt.Errorf("alias not found: %s", args[0])
+	}
+
+	delete(cfg.Aliases, args[0])
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %s removed\n", args[0])
+	return nil
+}
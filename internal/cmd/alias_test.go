@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "status --all", want: []string{"status", "--all"}},
+		{in: "  connect   --method ssh ", want: []string{"connect", "--method", "ssh"}},
+		{in: `exec -- sh -c 'top -bn1 | head'`, want: []string{"exec", "--", "sh", "-c", "top -bn1 | head"}},
+		{in: `exec -- echo "say \"hi\"" ''`, want: []string{"exec", "--", "echo", `say "hi"`, ""}},
+		{in: "", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.in)
+		if err != nil {
+			t.Errorf("splitCommandLine(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommandLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := splitCommandLine(`exec -- sh -c 'oops`); err == nil {
+		t.Error("Expected error for unterminated quote")
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"all":    "status --all",
+		"status": "down --force",
+	}
+
+	got, ok, err := expandAlias([]string{"all", "--json"}, aliases)
+	if err != nil || !ok {
+		t.Fatalf("expandAlias() = %v, %v", ok, err)
+	}
+	if want := []string{"status", "--all", "--json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expandAlias() = %q, want %q", got, want)
+	}
+
+	// Built-in commands are never replaced
+	got, ok, _ = expandAlias([]string{"status"}, aliases)
+	if ok || got[0] != "status" {
+		t.Errorf("Expected built-in status to be left alone, got %q", got)
+	}
+
+	if _, ok, _ := expandAlias([]string{"unknown"}, aliases); ok {
+		t.Error("Expected unknown command to be left alone")
+	}
+}
+
+func TestAliasSetRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := runAliasSet(nil, []string{"sshc", "connect --method ssh"}); err != nil {
+		t.Fatalf("runAliasSet() error = %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Aliases["sshc"] != "connect --method ssh" {
+		t.Errorf("Expected alias to be saved, got %v", cfg.Aliases)
+	}
+
+	args, err := expandConfiguredAlias([]string{"sshc", "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"connect", "--method", "ssh", "web"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expandConfiguredAlias() = %q, want %q", args, want)
+	}
+
+	for _, bad := range [][]string{{"ls", "status --all"}, {"Bad_Name", "status"}, {"x", "  "}, {"x", "cvps status"}} {
+		if err := runAliasSet(nil, bad); err == nil {
+			t.Errorf("runAliasSet(%q) expected error", bad)
+		}
+	}
+
+	if err := runAliasRemove(nil, []string{"sshc"}); err != nil {
+		t.Fatalf("runAliasRemove() error = %v", err)
+	}
+	if err := runAliasRemove(nil, []string{"sshc"}); err == nil {
+		t.Error("Expected error removing a missing alias")
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var annotateClear bool
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <sandbox> [description]",
+	Short: "Describe what a sandbox is for",
+	Long: `Set the description of a sandbox, a free-text note on why it exists.
+
+The description is shown by 'cvps status' and in sandbox listings. Without a
+description the current one is printed; --clear removes it.`,
+	Example: `  # Note why a sandbox exists
+  cvps annotate repro-123 "staging repro for bug #123"
+
+  # Show the description
+  cvps annotate repro-123
+
+  # Remove it
+  cvps annotate repro-123 --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runAnnotate,
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().BoolVar(&annotateClear, "clear", false, "remove the description")
+	annotateCmd.ValidArgsFunction = completeSandboxes
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) error {
+	description := ""
+	if len(args) > 1 {
+		description = strings.TrimSpace(args[1])
+		if description == "" {
+			return fmt.Errorf("description is empty. Use --clear to remove it")
+		}
+	}
+	if annotateClear && description != "" {
+		return fmt.Errorf("--clear cannot be combined with a description")
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	if description == "" && !annotateClear {
+		sandbox, err := client.GetSandbox(ctx, sandboxID)
+		if err != nil {
+			if claudevps.IsNotFound(err) {
+				return &sandboxNotFoundError{Ref: args[0]}
+			}
+			return fmt.Errorf("failed to get sandbox: %w", err)
+		}
+		if sandbox.Description == "" {
+			fmt.Printf("Sandbox '%s' has no description. Set one with: cvps annotate %s \"...\"\n", sandbox.Name, args[0])
+			return nil
+		}
+		fmt.Println(sandbox.Description)
+		return nil
+	}
+
+	sandbox, err := client.SetSandboxDescription(ctx, sandboxID, description)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return &sandboxNotFoundError{Ref: args[0]}
+		}
+		return fmt.Errorf("failed to update sandbox: %w", err)
+	}
+
+	if annotateClear {
+		fmt.Printf("✓ Removed the description of '%s'\n", sandbox.Name)
+	} else {
+		fmt.Printf("✓ Described '%s': %s\n", sandbox.Name, description)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestRunAnnotate(t *testing.T) {
+	var patched []map[string]string
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-abc123":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-abc123", Name: "repro", Description: "staging repro"})
+		case r.Method == "PATCH" && r.URL.Path == "/sandboxes/sbx-abc123":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			patched = append(patched, body)
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-abc123", Name: "repro", Description: body["description"]})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	out, err := captureStdout(t, func() error {
+		return runAnnotate(nil, []string{"sbx-abc123", "  staging repro for bug #123 "})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(patched) != 1 || patched[0]["description"] != "staging repro for bug #123" {
+		t.Errorf("Unexpected updates: %v", patched)
+	}
+	if !strings.Contains(out, "Described 'repro'") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+
+	out, err = captureStdout(t, func() error { return runAnnotate(nil, []string{"sbx-abc123"}) })
+	if err != nil || strings.TrimSpace(out) != "staging repro" {
+		t.Errorf("Expected the description to be shown, got %q, %v", out, err)
+	}
+
+	annotateClear = true
+	defer func() { annotateClear = false }()
+	if err := runAnnotate(nil, []string{"sbx-abc123", "text"}); err == nil {
+		t.Error("Expected --clear with a description to be rejected")
+	}
+	if _, err := captureStdout(t, func() error { return runAnnotate(nil, []string{"sbx-abc123"}) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(patched) != 2 || patched[1]["description"] != "" {
+		t.Errorf("Expected the description to be cleared, got %v", patched)
+	}
+}
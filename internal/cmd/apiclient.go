@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/version"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// cliClientOptions identify the CLI to the API and send requests to the debug
+// log. With --dry-run, requests that would change anything are refused.
+func cliClientOptions() []claudevps.ClientOption {
+	opts := []claudevps.ClientOption{
+		claudevps.WithUserAgent("cvps-cli/" + version.Version),
+		claudevps.WithLogger(debuglog.Printf),
+	}
+	if dryRun {
+		opts = append(opts, claudevps.WithReadOnly())
+	}
+	return opts
+}
+
+// newClientFromConfig creates a client from config (tries token first, then API key)
+func newClientFromConfig(cfg *config.Config, opts ...claudevps.ClientOption) *claudevps.Client {
+	base := cliClientOptions()
+	if timeout := cfg.RequestTimeout(); timeout > 0 {
+		base = append(base, claudevps.WithTimeout(timeout))
+	}
+	opts = append(base, opts...)
+	if cfg.AccessToken != "" {
+		return claudevps.NewClientWithToken(cfg.APIBaseURL, cfg.AccessToken, opts...)
+	}
+	return claudevps.NewClient(cfg.APIBaseURL, cfg.APIKey, opts...)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+func TestNewClientFromConfig(t *testing.T) {
+	var auth, apiKey, userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, apiKey, userAgent = r.Header.Get("Authorization"), r.Header.Get("X-API-Key"), r.Header.Get("User-Agent")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// Should prefer the token over the API key
+	cfg := &config.Config{APIBaseURL: server.URL, AccessToken: "test-token", APIKey: "test-api-key"}
+	if err := newClientFromConfig(cfg).Get(context.Background(), "/users/me", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if auth != "Bearer test-token" || apiKey != "" {
+		t.Errorf("Expected bearer token only, got Authorization %q, X-API-Key %q", auth, apiKey)
+	}
+	if !strings.HasPrefix(userAgent, "cvps-cli/") {
+		t.Errorf("Expected cvps-cli user agent, got %q", userAgent)
+	}
+
+	cfg = &config.Config{APIBaseURL: server.URL, APIKey: "test-api-key"}
+	if err := newClientFromConfig(cfg).Get(context.Background(), "/users/me", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if auth != "" || apiKey != "test-api-key" {
+		t.Errorf("Expected API key only, got Authorization %q, X-API-Key %q", auth, apiKey)
+	}
+}
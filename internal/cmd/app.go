@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/fatih/color"
+	"github.com/pkg/browser"
+	"github.com/spf13/cobra"
+)
+
+// appReadyTimeout is how long an app has to answer after starting
+var appReadyTimeout = 2 * time.Minute
+
+// appServicePrefix starts the names of the services apps run as
+const appServicePrefix = "app-"
+
+var (
+	appSandbox   string
+	appPort      int
+	appLocalPort int
+	appDir       string
+	appNoOpen    bool
+)
+
+// appPreset knows how to install and run one kind of app. {port}, {token}
+// and {dir} in Command and {token} in Path are filled in on start.
+type appPreset struct {
+	Description string
+	Port        int
+	// Install runs in the app's directory before every start; it must do
+	// nothing when the app is already installed
+	Install string
+	Command string
+	// Path is the path and query of the URL to open
+	Path string
+	// Token is set if the app requires a token to be reached
+	Token bool
+}
+
+var appPresets = map[string]appPreset{
+	"jupyter": {
+		Description: "JupyterLab notebooks",
+		Port:        8888,
+		Install: `command -v jupyter >/dev/null 2>&1 || [ -x "$HOME/.local/bin/jupyter" ] ||
+  python3 -m pip install --user jupyterlab || python3 -m pip install --user --break-system-packages jupyterlab`,
+		Command: `PATH="$HOME/.local/bin:$PATH" jupyter lab --no-browser --ip=127.0.0.1 --port={port} --ServerApp.token={token}`,
+		Path:    "/lab?token={token}",
+		Token:   true,
+	},
+	"code-server": {
+		Description: "VS Code in the browser",
+		Port:        8080,
+		Install: `command -v code-server >/dev/null 2>&1 || [ -x "$HOME/.local/bin/code-server" ] ||
+  curl -fsSL https://code-server.dev/install.sh | sh -s -- --method standalone`,
+		// Only reachable through the forward, so the sandbox login is the auth
+		Command: `PATH="$HOME/.local/bin:$PATH" code-server --bind-addr 127.0.0.1:{port} --auth none --disable-telemetry {dir}`,
+		Path:    "/",
+	},
+	"vite": {
+		Description: "Vite dev server for the project in --dir",
+		Port:        5173,
+		Install: `[ -f package.json ] || { echo "no package.json in $(pwd); vite needs a project" >&2; exit 1; }
+[ -d node_modules ] || npm install`,
+		Command: `npx vite --host 127.0.0.1 --port {port} --strictPort`,
+		Path:    "/",
+	},
+}
+
+// appPresetNames returns the preset names in order
+func appPresetNames() []string {
+	names := make([]string, 0, len(appPresets))
+	for name := range appPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupAppPreset(name string) (appPreset, error) {
+	p, ok := appPresets[name]
+	if !ok {
+		return appPreset{}, fmt.Errorf("unknown app %q (use %s)", name, strings.Join(appPresetNames(), ", "))
+	}
+	return p, nil
+}
+
+// command returns the shell command that runs the app
+func (p appPreset) command(port int, token, dir string) string {
+	return strings.NewReplacer("{port}", strconv.Itoa(port), "{token}", token, "{dir}", remote.Quote(dir)).Replace(p.Command)
+}
+
+// url returns the address of the app forwarded to localhost:local
+func (p appPreset) url(local int, token string) string {
+	return fmt.Sprintf("http://localhost:%d%s", local, strings.ReplaceAll(p.Path, "{token}", token))
+}
+
+var appCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Quick-launch Jupyter, code-server or a dev server in a sandbox",
+	Long: `Start a common app in a sandbox and open it in the browser in one step:
+cvps installs the app if needed, runs it as a service that survives
+disconnects ('cvps service'), forwards its port to localhost and opens the URL.
+
+The app only listens on localhost in the sandbox and is reached through the
+forward. With mutagen installed the forward keeps running in the background;
+otherwise cvps forwards until you press Ctrl-C, and the app keeps running.`,
+	Example: `  # JupyterLab in the current sandbox
+  cvps app start jupyter
+
+  # VS Code in the browser, on another local port
+  cvps app start code-server --local-port 9000
+
+  # The vite dev server of a project
+  cvps app start vite --dir /workspace/web
+
+  # Stop it again
+  cvps app stop jupyter`,
+}
+
+var appStartCmd = &cobra.Command{
+	Use:       "start <app>",
+	Short:     "Install, start and open an app",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: appPresetNames(),
+	RunE:      runAppStart,
+}
+
+var appStopCmd = &cobra.Command{
+	Use:       "stop <app>",
+	Short:     "Stop an app and its port forward",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: appPresetNames(),
+	RunE:      runAppStop,
+}
+
+var appListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the apps that can be started",
+	Args:  cobra.NoArgs,
+	RunE:  runAppList,
+}
+
+func init() {
+	rootCmd.AddCommand(appCmd)
+	appCmd.AddCommand(appStartCmd)
+	appCmd.AddCommand(appStopCmd)
+	appCmd.AddCommand(appListCmd)
+
+	appCmd.PersistentFlags().StringVarP(&appSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	appStartCmd.Flags().IntVar(&appPort, "port", 0, "port the app listens on in the sandbox (default per app)")
+	appStartCmd.Flags().IntVar(&appLocalPort, "local-port", 0, "localhost port to forward (default: the sandbox port)")
+	appStartCmd.Flags().StringVar(&appDir, "dir", "/workspace", "directory to run the app in")
+	appStartCmd.Flags().BoolVar(&appNoOpen, "no-open", false, "print the URL without opening a browser")
+}
+
+// appState is what a running app was started with, kept next to its service
+type appState struct {
+	Port  int
+	Local int
+	Token string
+}
+
+// appStateScript prints the saved state of an app if its service is running
+func appStateScript(name string) string {
+	return "dir=" + serviceDirShell(appServicePrefix+name) + "\n" +
+		`if [ -f "$dir/pid" ] && kill -0 "$(cat "$dir/pid")" 2>/dev/null && [ -f "$dir/app" ]; then cat "$dir/app"; fi` + "\n"
+}
+
+// appSaveScript saves the state of an app, readable only by the sandbox user
+func appSaveScript(name string, st appState) string {
+	token := st.Token
+	if token == "" {
+		token = "-"
+	}
+	return "umask 077 && printf '%s\\n' " + remote.Quote(fmt.Sprintf("%d %d %s", st.Port, st.Local, token)) +
+		" > " + serviceDirShell(appServicePrefix+name) + "/app\n"
+}
+
+// parseAppState parses appStateScript output, returning nil if the app is
+// not running
+func parseAppState(out string) *appState {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return nil
+	}
+	port, err1 := strconv.Atoi(fields[0])
+	local, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	st := &appState{Port: port, Local: local}
+	if fields[2] != "-" {
+		st.Token = fields[2]
+	}
+	return st
+}
+
+func newAppToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func runAppStart(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	preset, err := lookupAppPreset(name)
+	if err != nil {
+		return err
+	}
+	for _, p := range []int{appPort, appLocalPort} {
+		if p < 0 || p > 65535 {
+			return fmt.Errorf("invalid port %d", p)
+		}
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, appSandbox)
+	if err != nil {
+		return err
+	}
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// A running app is reused as it is, with the URL it was started with
+	out, err := conn.Output(ctx, appStateScript(name))
+	if err != nil {
+		return fmt.Errorf("failed to check on %s: %w", name, err)
+	}
+	st := parseAppState(string(out))
+	if st != nil {
+		fmt.Printf("%s is already running in %s\n", name, sandbox.Name)
+		if appLocalPort != 0 {
+			st.Local = appLocalPort
+		}
+	} else {
+		st = &appState{Port: orDefault(appPort, preset.Port), Local: orDefault(appLocalPort, orDefault(appPort, preset.Port))}
+		if preset.Token {
+			if st.Token, err = newAppToken(); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Installing %s in %s if needed...\n", name, sandbox.Name)
+		install := "cd " + remote.Quote(appDir) + " && " + preset.Install
+		if err := runRemoteStep(ctx, conn, install, "installing "+name); err != nil {
+			return err
+		}
+		if err := startService(ctx, conn, appServicePrefix+name, preset.command(st.Port, st.Token, appDir), appDir); err != nil {
+			return err
+		}
+		if _, err := conn.Output(ctx, appSaveScript(name, *st)); err != nil {
+			return fmt.Errorf("failed to save the state of %s: %w", name, err)
+		}
+	}
+
+	forwards := []portForward{{Local: st.Local, Remote: st.Port}}
+	background := mutagen.IsInstalled()
+	if background {
+		if err := createForwardSessions(sandbox, forwards); err != nil {
+			return err
+		}
+	} else {
+		closeForwards, err := startPortForwards(ctx, sandbox, forwards)
+		if err != nil {
+			return err
+		}
+		defer closeForwards()
+	}
+
+	fmt.Printf("Waiting for %s to answer...\n", name)
+	if err := waitForHTTP(ctx, fmt.Sprintf("http://localhost:%d/", st.Local), appReadyTimeout); err != nil {
+		return fmt.Errorf("%s did not answer on port %d: %w. See 'cvps service logs %s%s'", name, st.Port, err, appServicePrefix, name)
+	}
+
+	url := preset.url(st.Local, st.Token)
+	color.Green("✓ %s is ready at %s", name, url)
+	if !appNoOpen {
+		if err := browser.OpenURL(url); err != nil {
+			fmt.Println("Open the URL in your browser.")
+		}
+	}
+
+	if background {
+		fmt.Printf("The forward keeps running in the background. Stop both with 'cvps app stop %s'.\n", name)
+		return nil
+	}
+	fmt.Println("Forwarding until Ctrl-C; the app keeps running in the sandbox.")
+	<-ctx.Done()
+	return nil
+}
+
+// orDefault returns v, or def if v is zero
+func orDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// waitForHTTP waits until url gives any HTTP response
+func waitForHTTP(ctx context.Context, url string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		// A redirect to a login page still means the app is up
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no answer within %s", shortDuration(timeout))
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func runAppStop(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if _, err := lookupAppPreset(name); err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	sandboxID, err := resolveSandboxRef(ctx, client, appSandbox)
+	if err != nil {
+		return err
+	}
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := conn.Output(ctx, appStateScript(name))
+	if err != nil {
+		return fmt.Errorf("failed to check on %s: %w", name, err)
+	}
+	st := parseAppState(string(out))
+	if st == nil {
+		fmt.Printf("%s is not running in %s\n", name, sandbox.Name)
+		return nil
+	}
+	if _, err := conn.Output(ctx, serviceStopScript(appServicePrefix+name)); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+
+	if mutagen.IsInstalled() {
+		forward := forwardSessionName(sandbox.ID, st.Local)
+		current, _ := mutagen.ListForwards(forward)
+		for _, f := range current {
+			if f.Name != forward {
+				continue
+			}
+			if err := mutagen.TerminateForward(forward); err != nil {
+				color.Yellow("⚠ Forward of localhost:%d not stopped: %v", st.Local, err)
+			}
+		}
+	}
+	fmt.Printf("✓ %s stopped\n", name)
+	return nil
+}
+
+func runAppList(cmd *cobra.Command, args []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tPORT\tDESCRIPTION")
+	for _, name := range appPresetNames() {
+		p := appPresets[name]
+		fmt.Fprintf(w, "%s\t%d\t%s\n", name, p.Port, p.Description)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppPreset(t *testing.T) {
+	p, err := lookupAppPreset("jupyter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.command(8890, "abc", "/workspace"); !strings.Contains(got, "--port=8890 --ServerApp.token=abc") {
+		t.Errorf("command() = %s", got)
+	}
+	if got := p.url(9000, "abc"); got != "http://localhost:9000/lab?token=abc" {
+		t.Errorf("url() = %s", got)
+	}
+
+	cs := appPresets["code-server"]
+	if got := cs.command(8080, "", "/workspace/my app"); !strings.HasSuffix(got, "127.0.0.1:8080 --auth none --disable-telemetry '/workspace/my app'") {
+		t.Errorf("command() = %s", got)
+	}
+
+	if _, err := lookupAppPreset("rstudio"); err == nil || !strings.Contains(err.Error(), "code-server, jupyter, vite") {
+		t.Errorf("lookupAppPreset(unknown) = %v", err)
+	}
+}
+
+func TestAppState(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	home := t.TempDir()
+	run := func(script string) string {
+		c := exec.Command("sh", "-c", script)
+		c.Env = append(os.Environ(), "HOME="+home)
+		out, err := c.Output()
+		if err != nil {
+			t.Fatalf("script failed: %v", err)
+		}
+		return string(out)
+	}
+
+	dir := filepath.Join(home, servicesDir, "app-jupyter")
+	os.MkdirAll(dir, 0o700)
+	run(appSaveScript("jupyter", appState{Port: 8888, Local: 9000, Token: "abc"}))
+
+	// Not running without a live pid
+	if st := parseAppState(run(appStateScript("jupyter"))); st != nil {
+		t.Errorf("stopped app reported as %+v", st)
+	}
+	os.WriteFile(filepath.Join(dir, "pid"), []byte(fmt.Sprint(os.Getpid())), 0o600)
+	st := parseAppState(run(appStateScript("jupyter")))
+	if st == nil || *st != (appState{Port: 8888, Local: 9000, Token: "abc"}) {
+		t.Errorf("parseAppState() = %+v", st)
+	}
+
+	run(appSaveScript("jupyter", appState{Port: 5173, Local: 5173}))
+	if st := parseAppState(run(appStateScript("jupyter"))); st == nil || st.Token != "" {
+		t.Errorf("app without token = %+v", st)
+	}
+}
+
+func TestWaitForHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer srv.Close()
+	if err := waitForHTTP(context.Background(), srv.URL, time.Second); err != nil {
+		t.Errorf("waitForHTTP() = %v", err)
+	}
+
+	srv.Close()
+	if err := waitForHTTP(context.Background(), srv.URL, 600*time.Millisecond); err == nil {
+		t.Error("expected an error for a closed server")
+	}
+}
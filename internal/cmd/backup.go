@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Names of the server-only secrets holding the backup target credentials
+const (
+	backupAccessKeyIDSecret     = "CVPS_BACKUP_ACCESS_KEY_ID"
+	backupSecretAccessKeySecret = "CVPS_BACKUP_SECRET_ACCESS_KEY"
+)
+
+var (
+	backupEndpoint    string
+	backupRegion      string
+	backupAccessKeyID string
+	backupJSON        bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage where snapshots and backups are stored",
+}
+
+var backupTargetCmd = &cobra.Command{
+	Use:   "target",
+	Short: "Manage the bucket snapshots and backups are written to",
+	Long: `Store snapshots and backups in your own S3-compatible bucket (AWS S3, R2,
+MinIO, ...) instead of platform storage.
+
+The access key is stored as server-only secrets (see 'cvps secrets list'); it
+is never injected into sandboxes or returned by the API.`,
+}
+
+var backupTargetSetCmd = &cobra.Command{
+	Use:   "set <s3://bucket[/prefix]>",
+	Short: "Write snapshots and backups to an S3-compatible bucket",
+	Long: `Write snapshots and backups to an S3-compatible bucket, replacing any
+existing target. Write access is verified before the command returns.
+
+The access key ID is read from --access-key-id or $AWS_ACCESS_KEY_ID, and the
+secret access key from $AWS_SECRET_ACCESS_KEY, standard input when it is
+piped, or a hidden prompt.`,
+	Example: `  # AWS S3, credentials from the environment
+  cvps backup target set s3://acme-backups/cvps --region eu-west-1
+
+  # Cloudflare R2
+  echo -n "$R2_SECRET" | cvps backup target set s3://backups \
+    --endpoint https://<account>.r2.cloudflarestorage.com --access-key-id "$R2_KEY_ID"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupTargetSet,
+}
+
+var backupTargetShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the backup target",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupTargetShow,
+}
+
+var backupTargetVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the backup target is writable",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupTargetVerify,
+}
+
+var backupTargetRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Go back to storing backups on the platform",
+	Long: `Remove the backup target and its stored credentials. New snapshots and
+backups go to platform storage; objects already in the bucket are left alone.`,
+	Args: cobra.NoArgs,
+	RunE: runBackupTargetRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupTargetCmd)
+	backupTargetCmd.AddCommand(backupTargetSetCmd)
+	backupTargetCmd.AddCommand(backupTargetShowCmd)
+	backupTargetCmd.AddCommand(backupTargetVerifyCmd)
+	backupTargetCmd.AddCommand(backupTargetRemoveCmd)
+
+	backupTargetSetCmd.Flags().StringVar(&backupEndpoint, "endpoint", "", "S3 API endpoint URL (default: AWS S3)")
+	backupTargetSetCmd.Flags().StringVar(&backupRegion, "region", "", "bucket region")
+	backupTargetSetCmd.Flags().StringVar(&backupAccessKeyID, "access-key-id", "", "access key ID (default: $AWS_ACCESS_KEY_ID)")
+	backupTargetShowCmd.Flags().BoolVar(&backupJSON, "json", false, "output in JSON format")
+}
+
+// parseBucketURL splits s3://bucket/prefix into its bucket and prefix
+func parseBucketURL(raw string) (bucket, prefix string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid bucket %q: expected s3://bucket[/prefix]", raw)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+func runBackupTargetSet(cmd *cobra.Command, args []string) error {
+	bucket, prefix, err := parseBucketURL(args[0])
+	if err != nil {
+		return err
+	}
+	if backupEndpoint != "" {
+		if u, err := url.Parse(backupEndpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid --endpoint %q: expected an http(s) URL", backupEndpoint)
+		}
+	}
+
+	keyID := backupAccessKeyID
+	if keyID == "" {
+		keyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if keyID == "" {
+		return fmt.Errorf("--access-key-id is required (or set AWS_ACCESS_KEY_ID)")
+	}
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if secretKey == "" {
+		if secretKey, err = readSecretValue("", os.Stdin, term.IsTerminal(int(os.Stdin.Fd()))); err != nil {
+			return err
+		}
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	for name, value := range map[string]string{
+		backupAccessKeyIDSecret:     keyID,
+		backupSecretAccessKeySecret: secretKey,
+	} {
+		if _, err := client.SetSecret(ctx, &claudevps.SetSecretRequest{
+			Name:  name,
+			Value: value,
+			Mount: claudevps.SecretMountNone,
+		}); err != nil {
+			return fmt.Errorf("failed to store credentials: %w", err)
+		}
+	}
+
+	target, err := client.SetBackupTarget(ctx, &claudevps.SetBackupTargetRequest{
+		Bucket:                bucket,
+		Prefix:                prefix,
+		Endpoint:              backupEndpoint,
+		Region:                backupRegion,
+		AccessKeyIDSecret:     backupAccessKeyIDSecret,
+		SecretAccessKeySecret: backupSecretAccessKeySecret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set backup target: %w", err)
+	}
+	fmt.Printf("✓ Backup target set to %s\n", backupTargetURL(target))
+
+	return verifyBackupTarget(ctx, client)
+}
+
+// verifyBackupTarget has the API check write access to the target
+func verifyBackupTarget(ctx context.Context, client *claudevps.Client) error {
+	result, err := client.VerifyBackupTarget(ctx)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("no backup target set. Run 'cvps backup target set s3://bucket' first")
+		}
+		return fmt.Errorf("failed to verify backup target: %w", err)
+	}
+	if !result.OK {
+		color.Red("✗ Backup target is not writable: %s", result.Error)
+		return fmt.Errorf("backup target verification failed; fix the bucket policy or credentials and run 'cvps backup target verify'")
+	}
+	fmt.Println("✓ Write access verified")
+	return nil
+}
+
+// backupTargetURL renders a target as s3://bucket/prefix
+func backupTargetURL(t *claudevps.BackupTarget) string {
+	if t.Prefix == "" {
+		return "s3://" + t.Bucket
+	}
+	return "s3://" + t.Bucket + "/" + t.Prefix
+}
+
+func runBackupTargetShow(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	target, err := client.GetBackupTarget(context.Background())
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			fmt.Println("No backup target; snapshots and backups use platform storage.")
+			return nil
+		}
+		return fmt.Errorf("failed to get backup target: %w", err)
+	}
+
+	if backupJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(target)
+	}
+
+	endpoint := target.Endpoint
+	if endpoint == "" {
+		endpoint = "AWS S3"
+	}
+	fmt.Printf("Bucket:      %s\n", backupTargetURL(target))
+	fmt.Printf("Endpoint:    %s\n", endpoint)
+	if target.Region != "" {
+		fmt.Printf("Region:      %s\n", target.Region)
+	}
+	fmt.Printf("Credentials: secrets %s, %s\n", target.AccessKeyIDSecret, target.SecretAccessKeySecret)
+	if target.VerifiedAt != "" {
+		fmt.Printf("Verified:    %s\n", formatTime(target.VerifiedAt))
+	} else {
+		fmt.Println("Verified:    never (run 'cvps backup target verify')")
+	}
+	return nil
+}
+
+func runBackupTargetVerify(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	return verifyBackupTarget(context.Background(), client)
+}
+
+func runBackupTargetRemove(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if err := client.DeleteBackupTarget(ctx); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("no backup target set")
+		}
+		return fmt.Errorf("failed to remove backup target: %w", err)
+	}
+	for _, name := range []string{backupAccessKeyIDSecret, backupSecretAccessKeySecret} {
+		if err := client.DeleteSecret(ctx, name); err != nil && !claudevps.IsNotFound(err) {
+			color.Yellow("⚠ Failed to delete secret %s: %v", name, err)
+		}
+	}
+
+	fmt.Println("✓ Backup target removed; snapshots and backups use platform storage again")
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestParseBucketURL(t *testing.T) {
+	tests := []struct {
+		in             string
+		bucket, prefix string
+		wantErr        bool
+	}{
+		{"s3://acme", "acme", "", false},
+		{"s3://acme/cvps/backups/", "acme", "cvps/backups", false},
+		{"https://acme.s3.amazonaws.com", "", "", true},
+		{"acme", "", "", true},
+		{"s3:///prefix", "", "", true},
+	}
+	for _, tt := range tests {
+		bucket, prefix, err := parseBucketURL(tt.in)
+		if (err != nil) != tt.wantErr || bucket != tt.bucket || prefix != tt.prefix {
+			t.Errorf("parseBucketURL(%q) = %q, %q, %v", tt.in, bucket, prefix, err)
+		}
+	}
+}
+
+// fakeBackupAPI records the secrets and target set through it and answers
+// verification with verifyErr, or success if empty
+func fakeBackupAPI(t *testing.T, verifyErr string) (map[string]claudevps.SetSecretRequest, *claudevps.SetBackupTargetRequest) {
+	secrets := make(map[string]claudevps.SetSecretRequest)
+	target := &claudevps.SetBackupTargetRequest{}
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/secrets":
+			var req claudevps.SetSecretRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			secrets[req.Name] = req
+			json.NewEncoder(w).Encode(claudevps.Secret{Name: req.Name, Mount: req.Mount})
+		case "/backup/target":
+			json.NewDecoder(r.Body).Decode(target)
+			json.NewEncoder(w).Encode(claudevps.BackupTarget{Bucket: target.Bucket, Prefix: target.Prefix})
+		case "/backup/target/verify":
+			json.NewEncoder(w).Encode(claudevps.BackupTargetVerification{OK: verifyErr == "", Error: verifyErr})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return secrets, target
+}
+
+func TestRunBackupTargetSet(t *testing.T) {
+	secrets, target := fakeBackupAPI(t, "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "s3cr3t")
+	backupEndpoint = "https://minio.acme.dev"
+	defer func() { backupEndpoint = "" }()
+
+	out, err := captureStdout(t, func() error {
+		return runBackupTargetSet(nil, []string{"s3://acme/cvps"})
+	})
+	if err != nil {
+		t.Fatalf("runBackupTargetSet() error = %v", err)
+	}
+
+	if s := secrets[backupAccessKeyIDSecret]; s.Value != "AKIAEXAMPLE" || s.Mount != claudevps.SecretMountNone {
+		t.Errorf("Unexpected access key secret: %+v", s)
+	}
+	if s := secrets[backupSecretAccessKeySecret]; s.Value != "s3cr3t" || s.Mount != claudevps.SecretMountNone {
+		t.Errorf("Unexpected secret key secret: %+v", s)
+	}
+	if target.Bucket != "acme" || target.Prefix != "cvps" || target.Endpoint != "https://minio.acme.dev" ||
+		target.AccessKeyIDSecret != backupAccessKeyIDSecret || target.SecretAccessKeySecret != backupSecretAccessKeySecret {
+		t.Errorf("Unexpected target: %+v", target)
+	}
+	if !strings.Contains(out, "s3://acme/cvps") || !strings.Contains(out, "Write access verified") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestRunBackupTargetSet_NotWritable(t *testing.T) {
+	fakeBackupAPI(t, "AccessDenied: s3:PutObject")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "s3cr3t")
+
+	_, err := captureStdout(t, func() error {
+		return runBackupTargetSet(nil, []string{"s3://acme"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "verification failed") {
+		t.Errorf("runBackupTargetSet() error = %v, want verification error", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// fillBatch turns batch results into a sandbox and an error per item. If the
+// API has no batch endpoint, the items the batch did not cover are done one
+// request at a time with each. Any other failure of the batch request is
+// reported for the uncovered items rather than retried, as the server may
+// have acted on them already.
+func fillBatch(results []claudevps.BatchResult, err error, n int, each func(i int) (*claudevps.Sandbox, error)) ([]*claudevps.Sandbox, []error) {
+	sandboxes := make([]*claudevps.Sandbox, n)
+	errs := make([]error, n)
+	for i, r := range results {
+		sandboxes[i], errs[i] = r.Sandbox, r.Err()
+	}
+	if err != nil && claudevps.IsBatchUnsupported(err) {
+		debuglog.Printf("batch endpoint unavailable, one request per sandbox: %v", err)
+	}
+	for i := len(results); i < n; i++ {
+		if claudevps.IsBatchUnsupported(err) {
+			sandboxes[i], errs[i] = each(i)
+		} else {
+			errs[i] = err
+		}
+	}
+	return sandboxes, errs
+}
+
+// createSandboxes creates sandboxes in as few requests as the API allows
+func createSandboxes(ctx context.Context, client *claudevps.Client, reqs []*claudevps.CreateSandboxRequest) ([]*claudevps.Sandbox, []error) {
+	results, err := client.BatchCreateSandboxes(ctx, reqs)
+	return fillBatch(results, err, len(reqs), func(i int) (*claudevps.Sandbox, error) {
+		return client.CreateSandbox(ctx, reqs[i])
+	})
+}
+
+// deleteSandboxes deletes sandboxes in as few requests as the API allows,
+// honouring --purge
+func deleteSandboxes(ctx context.Context, client *claudevps.Client, ids []string) []error {
+	results, err := client.BatchDeleteSandboxes(ctx, ids, downPurge)
+	_, errs := fillBatch(results, err, len(ids), func(i int) (*claudevps.Sandbox, error) {
+		return nil, deleteSandbox(ctx, client, ids[i])
+	})
+	return errs
+}
+
+// sandboxStatuses fetches sandboxes in as few requests as the API allows
+func sandboxStatuses(ctx context.Context, client *claudevps.Client, ids []string) ([]*claudevps.Sandbox, []error) {
+	results, err := client.BatchSandboxStatus(ctx, ids)
+	return fillBatch(results, err, len(ids), func(i int) (*claudevps.Sandbox, error) {
+		return client.GetSandbox(ctx, ids[i])
+	})
+}
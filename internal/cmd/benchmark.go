@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/internal/terminal"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// benchmarkSamples is how many round trips each latency test times
+const benchmarkSamples = 10
+
+// benchmarkSmallFiles is how many files the small-file test creates
+const benchmarkSmallFiles = 1000
+
+var (
+	benchmarkSizeMB int
+	benchmarkJSON   bool
+)
+
+var benchmarkCmd = &cobra.Command{
+	Use:     "benchmark [sandbox]",
+	Aliases: []string{"benchmarks", "bench"},
+	Short:   "Measure network and disk performance of a sandbox",
+	Long: `Measure how fast a sandbox is from here: SSH round trips and throughput in
+both directions, the websocket terminal's round trip, and the sandbox disk's
+sequential write and read speed and small-file creation rate, using dd and
+the shell in the sandbox.
+
+Each result is compared with the baseline a healthy sandbox reaches, so when a
+sandbox feels slow the output shows whether the network or the sandbox is to
+blame. Include it when reporting a slow sandbox.`,
+	Example: `  # Benchmark the current sandbox
+  cvps benchmark
+
+  # A quicker run with less data, as JSON
+  cvps benchmark myproject --size 16 --json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSandboxes,
+	RunE:              runBenchmark,
+}
+
+func init() {
+	rootCmd.AddCommand(benchmarkCmd)
+
+	benchmarkCmd.Flags().IntVar(&benchmarkSizeMB, "size", 64, "megabytes transferred over SSH and written to disk")
+	benchmarkCmd.Flags().BoolVar(&benchmarkJSON, "json", false, "output in JSON format")
+}
+
+// benchmarkResult is one measurement and how it compares with its baseline
+type benchmarkResult struct {
+	Name     string  `json:"name"`
+	Value    float64 `json:"value,omitempty"`
+	Unit     string  `json:"unit"`
+	Baseline float64 `json:"baseline"`
+	Slow     bool    `json:"slow"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// benchmarkBaseline is what a healthy sandbox reaches in a test
+type benchmarkBaseline struct {
+	Unit          string
+	Value         float64
+	LowerIsBetter bool
+	Area          string // "network" or "disk", for the advice on slow results
+}
+
+var benchmarkBaselines = map[string]benchmarkBaseline{
+	"ssh round trip":       {"ms", 150, true, "network"},
+	"ssh upload":           {"MB/s", 10, false, "network"},
+	"ssh download":         {"MB/s", 10, false, "network"},
+	"websocket round trip": {"ms", 150, true, "network"},
+	"disk write":           {"MB/s", 150, false, "disk"},
+	"disk read":            {"MB/s", 300, false, "disk"},
+	"small files":          {"files/s", 2000, false, "disk"},
+}
+
+// newBenchmarkResult compares value with the baseline of name
+func newBenchmarkResult(name string, value float64) benchmarkResult {
+	b := benchmarkBaselines[name]
+	r := benchmarkResult{Name: name, Value: value, Unit: b.Unit, Baseline: b.Value}
+	if b.LowerIsBetter {
+		r.Slow = value > b.Value
+	} else {
+		r.Slow = value < b.Value
+	}
+	return r
+}
+
+func failedBenchmark(name string, err error) benchmarkResult {
+	b := benchmarkBaselines[name]
+	return benchmarkResult{Name: name, Unit: b.Unit, Baseline: b.Value, Error: err.Error()}
+}
+
+func runBenchmark(cmd *cobra.Command, args []string) error {
+	if benchmarkSizeMB < 1 {
+		return fmt.Errorf("--size must be at least 1")
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	step := func(format string, a ...any) {
+		if !benchmarkJSON {
+			fmt.Printf(format+"\n", a...)
+		}
+	}
+	size := int64(benchmarkSizeMB) << 20
+	var results []benchmarkResult
+
+	step("Timing SSH round trips...")
+	results = append(results, benchSSHRoundTrip(ctx, conn))
+	step("Sending and receiving %d MB over SSH...", benchmarkSizeMB)
+	results = append(results, benchSSHThroughput(ctx, conn, size)...)
+	step("Timing websocket terminal round trips...")
+	results = append(results, benchWebSocket(ctx, client, sandbox))
+	step("Testing the sandbox disk...")
+	results = append(results, benchDisk(ctx, conn, benchmarkSizeMB)...)
+
+	if benchmarkJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	fmt.Println()
+	printBenchmarkResults(os.Stdout, sandbox.Name, results)
+	return nil
+}
+
+// benchSSHRoundTrip times running a no-op command, each in a new channel
+func benchSSHRoundTrip(ctx context.Context, conn *remote.Client) benchmarkResult {
+	var lat terminal.Latency
+	for range benchmarkSamples {
+		start := time.Now()
+		if err := conn.Run(ctx, "true", nil, nil, nil); err != nil {
+			return failedBenchmark("ssh round trip", err)
+		}
+		lat.Add(time.Since(start))
+	}
+	return newBenchmarkResult("ssh round trip", milliseconds(lat.Summary().Avg))
+}
+
+// benchSSHThroughput sends size bytes to the sandbox and reads as many back
+func benchSSHThroughput(ctx context.Context, conn *remote.Client, size int64) []benchmarkResult {
+	var results []benchmarkResult
+
+	start := time.Now()
+	if err := conn.Run(ctx, "cat > /dev/null", io.LimitReader(zeroReader{}, size), nil, nil); err != nil {
+		results = append(results, failedBenchmark("ssh upload", err))
+	} else {
+		results = append(results, newBenchmarkResult("ssh upload", megabytesPerSecond(size, time.Since(start))))
+	}
+
+	start = time.Now()
+	if err := conn.Run(ctx, fmt.Sprintf("head -c %d /dev/zero", size), nil, io.Discard, nil); err != nil {
+		results = append(results, failedBenchmark("ssh download", err))
+	} else {
+		results = append(results, newBenchmarkResult("ssh download", megabytesPerSecond(size, time.Since(start))))
+	}
+	return results
+}
+
+// benchWebSocket opens a terminal session and times websocket pings
+func benchWebSocket(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox) benchmarkResult {
+	const name = "websocket round trip"
+	info, err := client.GetTerminalWebSocket(ctx, sandbox.ID)
+	if err != nil {
+		return failedBenchmark(name, err)
+	}
+	term, err := terminal.NewSocketIOTerminal(ctx, info.URL, info.Token, sandbox.ID)
+	if err != nil {
+		return failedBenchmark(name, err)
+	}
+	defer term.Close()
+
+	// Pongs are only read while the session runs
+	stdin, stop := io.Pipe()
+	defer stop.Close()
+	ended := make(chan error, 1)
+	go func() { ended <- term.Run(stdin, io.Discard) }()
+
+	for range benchmarkSamples {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := term.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			return failedBenchmark(name, err)
+		}
+		select {
+		case err := <-ended:
+			return failedBenchmark(name, fmt.Errorf("terminal session ended: %v", err))
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return newBenchmarkResult(name, milliseconds(term.RTT().Summary().Avg))
+}
+
+// diskBenchmarkScript writes, reads and removes a test file of $1 MB and
+// creates $2 small files, printing each step's time in nanoseconds. Reads
+// bypass the page cache where dd supports it. The file goes in the home
+// directory, as /tmp may be in memory.
+const diskBenchmarkScript = `set -e
+dir=$(mktemp -d "$HOME/.cvps-bench.XXXXXX")
+trap 'rm -rf "$dir"' EXIT
+s=$(date +%s%N); dd if=/dev/zero of="$dir/f" bs=1M count="$1" conv=fdatasync 2>/dev/null; e=$(date +%s%N)
+echo "write $((e - s))"
+s=$(date +%s%N); dd if="$dir/f" of=/dev/null bs=1M iflag=direct 2>/dev/null || dd if="$dir/f" of=/dev/null bs=1M 2>/dev/null; e=$(date +%s%N)
+echo "read $((e - s))"
+mkdir "$dir/small"
+s=$(date +%s%N); i=0; while [ $i -lt "$2" ]; do echo x > "$dir/small/$i"; i=$((i + 1)); done; sync; e=$(date +%s%N)
+echo "files $((e - s))"`
+
+// benchDisk runs diskBenchmarkScript in the sandbox
+func benchDisk(ctx context.Context, conn *remote.Client, sizeMB int) []benchmarkResult {
+	script := fmt.Sprintf("sh -c %s cvps-bench %d %d", remote.Quote(diskBenchmarkScript), sizeMB, benchmarkSmallFiles)
+	out, err := conn.Output(ctx, script)
+	if err == nil {
+		var results []benchmarkResult
+		if results, err = parseDiskBenchmark(string(out), sizeMB); err == nil {
+			return results
+		}
+	}
+	return []benchmarkResult{
+		failedBenchmark("disk write", err),
+		failedBenchmark("disk read", err),
+		failedBenchmark("small files", err),
+	}
+}
+
+// parseDiskBenchmark turns the output of diskBenchmarkScript into results
+func parseDiskBenchmark(out string, sizeMB int) ([]benchmarkResult, error) {
+	took := map[string]time.Duration{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		step, ns, ok := strings.Cut(strings.TrimSpace(line), " ")
+		n, err := strconv.ParseInt(ns, 10, 64)
+		if !ok || err != nil || n <= 0 {
+			return nil, fmt.Errorf("unexpected disk benchmark output %q", line)
+		}
+		took[step] = time.Duration(n)
+	}
+	for _, step := range []string{"write", "read", "files"} {
+		if _, ok := took[step]; !ok {
+			return nil, fmt.Errorf("disk benchmark did not report %s", step)
+		}
+	}
+	size := int64(sizeMB) << 20
+	return []benchmarkResult{
+		newBenchmarkResult("disk write", megabytesPerSecond(size, took["write"])),
+		newBenchmarkResult("disk read", megabytesPerSecond(size, took["read"])),
+		newBenchmarkResult("small files", float64(benchmarkSmallFiles)/took["files"].Seconds()),
+	}, nil
+}
+
+// printBenchmarkResults shows results next to their baselines, with advice
+// on the areas that fell short
+func printBenchmarkResults(w io.Writer, name string, results []benchmarkResult) {
+	fmt.Fprintf(w, "Benchmark of %s:\n\n", name)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEST\tRESULT\tEXPECTED\t")
+	slowAreas := map[string]bool{}
+	for _, r := range results {
+		b := benchmarkBaselines[r.Name]
+		expected := "≥ " + formatBenchmarkValue(b.Value, b.Unit)
+		if b.LowerIsBetter {
+			expected = "≤ " + formatBenchmarkValue(b.Value, b.Unit)
+		}
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, "-", expected, color.YellowString("failed: %s", r.Error))
+		case r.Slow:
+			slowAreas[b.Area] = true
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, formatBenchmarkValue(r.Value, r.Unit), expected, color.RedString("slow"))
+		default:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, formatBenchmarkValue(r.Value, r.Unit), expected, color.GreenString("ok"))
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	switch {
+	case slowAreas["network"] && slowAreas["disk"]:
+		fmt.Fprintln(w, "Both the network and the sandbox disk are slower than expected.")
+	case slowAreas["network"]:
+		fmt.Fprintln(w, "The network to the sandbox is slower than expected; a region closer to you may help ('cvps migrate-region').")
+	case slowAreas["disk"]:
+		fmt.Fprintln(w, "The sandbox disk is slower than expected; check 'cvps df' and report it with this output if it persists.")
+	default:
+		fmt.Fprintln(w, "Everything measured is as fast as expected.")
+	}
+}
+
+func formatBenchmarkValue(v float64, unit string) string {
+	if v >= 10 {
+		return fmt.Sprintf("%.0f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func megabytesPerSecond(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / (1 << 20) / d.Seconds()
+}
+
+// zeroReader is an endless stream of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
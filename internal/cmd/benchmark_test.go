@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseDiskBenchmark(t *testing.T) {
+	// 64 MB written in 0.5s, read in 0.1s, 1000 files in 0.25s
+	results, err := parseDiskBenchmark("write 500000000\nread 100000000\nfiles 250000000\n", 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"disk write": 128, "disk read": 640, "small files": 4000}
+	for _, r := range results {
+		if math.Abs(r.Value-want[r.Name]) > 0.01 {
+			t.Errorf("%s = %v, want %v", r.Name, r.Value, want[r.Name])
+		}
+	}
+	if !results[0].Slow || results[1].Slow || results[2].Slow {
+		t.Errorf("Unexpected verdicts: %+v", results)
+	}
+
+	for _, out := range []string{"write 1\nread 1\n", "write %N\nread 1\nfiles 1", ""} {
+		if _, err := parseDiskBenchmark(out, 64); err == nil {
+			t.Errorf("parseDiskBenchmark(%q) expected error", out)
+		}
+	}
+}
+
+func TestNewBenchmarkResult(t *testing.T) {
+	if r := newBenchmarkResult("ssh round trip", 40); r.Slow || r.Unit != "ms" {
+		t.Errorf("fast round trip: %+v", r)
+	}
+	if r := newBenchmarkResult("ssh round trip", 400); !r.Slow {
+		t.Errorf("slow round trip not flagged: %+v", r)
+	}
+	if r := newBenchmarkResult("ssh upload", 2); !r.Slow {
+		t.Errorf("slow upload not flagged: %+v", r)
+	}
+}
+
+func TestPrintBenchmarkResults(t *testing.T) {
+	var buf bytes.Buffer
+	printBenchmarkResults(&buf, "web", []benchmarkResult{
+		newBenchmarkResult("ssh round trip", 30),
+		newBenchmarkResult("disk write", 20),
+		{Name: "websocket round trip", Unit: "ms", Baseline: 150, Error: "no terminal"},
+	})
+	out := buf.String()
+	for _, want := range []string{"Benchmark of web", "30 ms", "≤ 150 ms", "slow", "failed: no terminal", "sandbox disk is slower"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
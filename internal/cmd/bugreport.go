@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/version"
+	"github.com/pkg/browser"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	bugReportLogLines   = 200
+	bugReportRequestIDs = 10
+	bugReportIssueURL   = "https://github.com/Achronon/cvps/issues/new"
+)
+
+var (
+	bugReportOutput string
+	bugReportIssue  bool
+)
+
+var bugReportRequestIDPattern = regexp.MustCompile(`request_id=(\S+)`)
+
+var bugReportCmd = &cobra.Command{
+	Use:   "bug-report",
+	Short: "Collect diagnostics for a support ticket",
+	Long: `Collect diagnostic information into a tarball to attach to support tickets.
+
+The bundle contains version information, your configuration with credentials
+redacted, the local sandbox context, environment checks, recent debug log
+entries, and the IDs of the most recent API requests.`,
+	Example: `  # Write cvps-bug-report-<timestamp>.tar.gz to the current directory
+  cvps bug-report
+
+  # Write the bundle to a specific path
+  cvps bug-report -o /tmp/report.tar.gz
+
+  # Open a pre-filled GitHub issue instead
+  cvps bug-report --issue`,
+	RunE: runBugReport,
+}
+
+func init() {
+	rootCmd.AddCommand(bugReportCmd)
+
+	bugReportCmd.Flags().StringVarP(&bugReportOutput, "output", "o", "", "path of the tarball to write")
+	bugReportCmd.Flags().BoolVar(&bugReportIssue, "issue", false, "open a pre-filled GitHub issue instead of writing a tarball")
+}
+
+type bugReport struct {
+	Version    string
+	Config     string
+	Context    string
+	Checks     []bugReportCheck
+	RequestIDs []string
+	Log        []string
+}
+
+type bugReportCheck struct {
+	Name   string
+	Result string
+}
+
+func runBugReport(cmd *cobra.Command, args []string) error {
+	report := collectBugReport(context.Background())
+
+	if bugReportIssue {
+		issueURL := bugReportIssueLink(report)
+		fmt.Println("Open the following URL to file an issue:")
+		fmt.Printf("  %s\n", issueURL)
+		if err := browser.OpenURL(issueURL); err != nil {
+			fmt.Println("(Could not open browser automatically)")
+		}
+		return nil
+	}
+
+	path := bugReportOutput
+	if path == "" {
+		path = fmt.Sprintf("cvps-bug-report-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	if err := writeBugReportArchive(path, report); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+
+	fmt.Printf("✓ Bug report written to %s\n", path)
+	fmt.Println("Review the contents, then attach it to your support ticket.")
+	return nil
+}
+
+func collectBugReport(ctx context.Context) *bugReport {
+	report := &bugReport{Version: version.Full()}
+
+	cfg, err := config.Load()
+	if err != nil {
+		report.Config = fmt.Sprintf("failed to load config: %s\n", err)
+	} else if data, err := yaml.Marshal(maskConfig(cfg)); err == nil {
+		report.Config = string(data)
+	}
+
+	if data, err := os.ReadFile(".cvps.yaml"); err == nil {
+		report.Context = string(data)
+	} else {
+		report.Context = "no local context\n"
+	}
+
+	report.Checks = runBugReportChecks(ctx, cfg)
+
+	lines, err := debuglog.Tail(bugReportLogLines)
+	if err != nil {
+		lines = []string{fmt.Sprintf("failed to read debug log: %s", err)}
+	}
+	report.Log = lines
+	report.RequestIDs = extractRequestIDs(lines, bugReportRequestIDs)
+
+	return report
+}
+
+func runBugReportChecks(ctx context.Context, cfg *config.Config) []bugReportCheck {
+	checks := []bugReportCheck{
+		{Name: "os/arch", Result: runtime.GOOS + "/" + runtime.GOARCH},
+	}
+
+	for _, bin := range []string{"ssh", "mutagen", "rsync"} {
+		result := "not found"
+		if path, err := exec.LookPath(bin); err == nil {
+			result = path
+		}
+		checks = append(checks, bugReportCheck{Name: bin, Result: result})
+	}
+
+	for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"} {
+		result := "not set"
+		if os.Getenv(name) != "" || os.Getenv(strings.ToLower(name)) != "" {
+			result = "set"
+		}
+		checks = append(checks, bugReportCheck{Name: name, Result: result})
+	}
+
+	checks = append(checks, bugReportCheck{Name: "TERM", Result: os.Getenv("TERM")})
+
+	switch {
+	case cfg == nil:
+		checks = append(checks, bugReportCheck{Name: "api", Result: "skipped (config not loaded)"})
+	case !cfg.IsAuthenticated():
+		checks = append(checks, bugReportCheck{Name: "api", Result: "skipped (not logged in)"})
+	default:
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		client := newClientFromConfig(cfg)
+		result := "ok"
+		if _, err := client.GetCurrentUser(ctx); err != nil {
+			result = fmt.Sprintf("failed: %s", err)
+		}
+		checks = append(checks, bugReportCheck{Name: "api", Result: result})
+	}
+
+	return checks
+}
+
+// extractRequestIDs returns the last n distinct request IDs found in log lines, newest first
+func extractRequestIDs(lines []string, n int) []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0, n)
+
+	for i := len(lines) - 1; i >= 0 && len(ids) < n; i-- {
+		match := bugReportRequestIDPattern.FindStringSubmatch(lines[i])
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		ids = append(ids, match[1])
+	}
+
+	return ids
+}
+
+func renderBugReportSummary(w io.Writer, report *bugReport) {
+	fmt.Fprintln(w, "## Version")
+	fmt.Fprintln(w, report.Version)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Environment")
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "- %s: %s\n", check.Name, check.Result)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Recent API request IDs")
+	if len(report.RequestIDs) == 0 {
+		fmt.Fprintln(w, "none recorded")
+	}
+	for _, id := range report.RequestIDs {
+		fmt.Fprintf(w, "- %s\n", id)
+	}
+}
+
+func writeBugReportArchive(path string, report *bugReport) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	var summary strings.Builder
+	renderBugReportSummary(&summary, report)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"summary.md", summary.String()},
+		{"config.yaml", report.Config},
+		{"context.yaml", report.Context},
+		{"debug.log", strings.Join(report.Log, "\n") + "\n"},
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		hdr := &tar.Header{
+			Name:    "cvps-bug-report/" + file.name,
+			Mode:    0600,
+			Size:    int64(len(file.content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, file.content); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func bugReportIssueLink(report *bugReport) string {
+	var body strings.Builder
+	body.WriteString("## Description\n\n<!-- What happened, and what did you expect? -->\n\n")
+	renderBugReportSummary(&body, report)
+
+	query := url.Values{}
+	query.Set("title", "Bug report: ")
+	query.Set("body", body.String())
+	return bugReportIssueURL + "?" + query.Encode()
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+func TestExtractRequestIDs(t *testing.T) {
+	lines := []string{
+		"2024-01-15T10:00:00Z GET /sandboxes status=200 request_id=req-1 duration=10ms",
+		"2024-01-15T10:00:01Z GET /users/me status=401 request_id=req-2 duration=5ms",
+		"2024-01-15T10:00:02Z error: not logged in",
+		"2024-01-15T10:00:03Z GET /sandboxes status=200 request_id=req-1 duration=9ms",
+		"2024-01-15T10:00:04Z POST /sandboxes status=201 request_id=req-3 duration=30ms",
+	}
+
+	got := extractRequestIDs(lines, 2)
+	want := []string{"req-3", "req-1"}
+	if len(got) != len(want) {
+		t.Fatalf("extractRequestIDs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("extractRequestIDs() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCollectBugReport_RedactsCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "cvps_supersecretkey"
+	cfg.AccessToken = "oauth-token-value"
+	cfg.APIBaseURL = "http://127.0.0.1:1"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	report := collectBugReport(context.Background())
+
+	if strings.Contains(report.Config, "supersecret") || strings.Contains(report.Config, "oauth-token-value") {
+		t.Fatalf("bug report config leaked credentials:\n%s", report.Config)
+	}
+	if !strings.Contains(report.Config, "***") {
+		t.Fatalf("bug report config should contain masked values:\n%s", report.Config)
+	}
+	if report.Context != "no local context\n" {
+		t.Fatalf("report.Context = %q, want no local context", report.Context)
+	}
+}
+
+func TestWriteBugReportArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tar.gz")
+	report := &bugReport{
+		Version:    "cvps version test",
+		Config:     "api_key: '***'\n",
+		Context:    "sandbox_id: sbx-123\n",
+		Checks:     []bugReportCheck{{Name: "ssh", Result: "/usr/bin/ssh"}},
+		RequestIDs: []string{"req-1"},
+		Log:        []string{"line one", "line two"},
+	}
+
+	if err := writeBugReportArchive(path, report); err != nil {
+		t.Fatalf("writeBugReportArchive() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+
+	contents := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		contents[hdr.Name] = string(data)
+	}
+
+	for _, name := range []string{"summary.md", "config.yaml", "context.yaml", "debug.log"} {
+		if _, ok := contents["cvps-bug-report/"+name]; !ok {
+			t.Errorf("archive missing %s", name)
+		}
+	}
+	if !strings.Contains(contents["cvps-bug-report/summary.md"], "req-1") {
+		t.Errorf("summary should list request IDs, got:\n%s", contents["cvps-bug-report/summary.md"])
+	}
+}
+
+func TestBugReportIssueLink(t *testing.T) {
+	link := bugReportIssueLink(&bugReport{
+		Version:    "cvps version test",
+		RequestIDs: []string{"req-42"},
+	})
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("bugReportIssueLink() returned invalid URL: %v", err)
+	}
+	body := parsed.Query().Get("body")
+	if !strings.Contains(body, "req-42") || !strings.Contains(body, "cvps version test") {
+		t.Fatalf("issue body missing report details:\n%s", body)
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// buildContextRoot is where build contexts are unpacked in the sandbox,
+// relative to home
+const buildContextRoot = ".cvps/build"
+
+var (
+	buildSandbox    string
+	buildTags       []string
+	buildDockerfile string
+	buildArgs       []string
+	buildTarget     string
+	buildPlatform   string
+	buildNoCache    bool
+	buildLoad       bool
+	buildPush       bool
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build [context-dir]",
+	Short: "Run docker build in a sandbox",
+	Long: `Run 'docker build' in a sandbox instead of on this machine, to offload heavy
+builds from a laptop. The build context (default: the current directory) is
+streamed to the sandbox as a compressed archive that honors .dockerignore,
+built there with BuildKit, and the build output is streamed back as it runs.
+
+--load copies the finished image into the local Docker, and --push pushes it
+from the sandbox to its registry, which needs 'docker login' in the sandbox
+first. The sandbox needs Docker; the image is built for the sandbox's
+architecture unless --platform says otherwise.
+
+To build an image to create sandboxes from, use 'cvps image build'.`,
+	Example: `  # Build in the current sandbox and load the image locally
+  cvps build -t myapp:dev --load
+
+  # Build a subdirectory for amd64 and push it
+  cvps build ./api -t ghcr.io/acme/api:1.4 --platform linux/amd64 --push
+
+  # Build in a particular sandbox
+  cvps build -s builder -t myapp:dev --build-arg GO_VERSION=1.23`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBuild,
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().StringVarP(&buildSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	buildCmd.Flags().StringArrayVarP(&buildTags, "tag", "t", nil, "name and tag of the image, e.g. myapp:dev (repeatable)")
+	buildCmd.Flags().StringVarP(&buildDockerfile, "file", "f", "Dockerfile", "Dockerfile path relative to the context")
+	buildCmd.Flags().StringArrayVar(&buildArgs, "build-arg", nil, "build argument as KEY=VALUE (repeatable)")
+	buildCmd.Flags().StringVar(&buildTarget, "target", "", "build stage to stop at")
+	buildCmd.Flags().StringVar(&buildPlatform, "platform", "", "platform to build for, e.g. linux/amd64")
+	buildCmd.Flags().BoolVar(&buildNoCache, "no-cache", false, "do not use the sandbox's build cache")
+	buildCmd.Flags().BoolVar(&buildLoad, "load", false, "load the image into the local Docker when done")
+	buildCmd.Flags().BoolVar(&buildPush, "push", false, "push the image from the sandbox when done")
+}
+
+// dockerBuildOptions are the arguments of a docker build
+type dockerBuildOptions struct {
+	Tags       []string
+	Dockerfile string
+	BuildArgs  map[string]string
+	Target     string
+	Platform   string
+	NoCache    bool
+}
+
+// dockerBuildCommand returns the shell command that builds the context in dir
+func dockerBuildCommand(dir string, o dockerBuildOptions) string {
+	args := []string{"DOCKER_BUILDKIT=1", "docker", "build", "-f", remote.Quote(o.Dockerfile)}
+	for _, tag := range o.Tags {
+		args = append(args, "-t", remote.Quote(tag))
+	}
+	keys := make([]string, 0, len(o.BuildArgs))
+	for k := range o.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", remote.Quote(k+"="+o.BuildArgs[k]))
+	}
+	if o.Target != "" {
+		args = append(args, "--target", remote.Quote(o.Target))
+	}
+	if o.Platform != "" {
+		args = append(args, "--platform", remote.Quote(o.Platform))
+	}
+	if o.NoCache {
+		args = append(args, "--no-cache")
+	}
+	return "cd " + remote.Quote(dir) + " && " + strings.Join(args, " ") + " ."
+}
+
+func runBuild(cmd *cobra.Command, args []string) error {
+	if (buildLoad || buildPush) && len(buildTags) == 0 {
+		return fmt.Errorf("--load and --push need an image name; pass one with --tag")
+	}
+	parsedArgs, err := parseBuildArgs(buildArgs)
+	if err != nil {
+		return err
+	}
+	if buildLoad {
+		if _, err := exec.LookPath("docker"); err != nil {
+			return fmt.Errorf("--load needs docker on this machine: %w", err)
+		}
+	}
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if _, err := os.Stat(filepath.Join(dir, buildDockerfile)); err != nil {
+		return fmt.Errorf("no %s in %s", buildDockerfile, dir)
+	}
+	patterns, err := readDockerignore(dir)
+	if err != nil {
+		return err
+	}
+	files, err := migration.NewScanner(dir, append(patterns, ".git")).Scan()
+	if err != nil {
+		return fmt.Errorf("failed to scan build context: %w", err)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	sandboxID, err := resolveSandboxRef(ctx, client, buildSandbox)
+	if err != nil {
+		return err
+	}
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Run(ctx, "command -v docker", nil, io.Discard, io.Discard); err != nil {
+		return fmt.Errorf("docker is not installed in sandbox %s", sandbox.Name)
+	}
+
+	remoteDir, err := newBuildContextDir()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Run(context.Background(), "rm -rf "+remote.Quote(remoteDir), nil, nil, nil); err != nil {
+			color.Yellow("⚠ Failed to remove the build context %s from the sandbox: %v", remoteDir, err)
+		}
+	}()
+
+	fmt.Printf("Sending build context to %s (%d files, %s)\n", sandbox.Name, files.Count, formatBytes(files.TotalSize))
+	bar := newTransferBar(files.TotalSize, "Sending context", "context_upload")
+	migrator := migration.NewMigrator(migration.Config{RemotePath: remoteDir})
+	if _, err := migrator.RunArchive(ctx, files, conn, func(n int64) { bar.Set64(n) }); err != nil {
+		fmt.Println()
+		return fmt.Errorf("failed to send build context: %w", err)
+	}
+	bar.Finish()
+	fmt.Println()
+
+	build := dockerBuildCommand(remoteDir, dockerBuildOptions{
+		Tags:       buildTags,
+		Dockerfile: filepath.ToSlash(buildDockerfile),
+		BuildArgs:  parsedArgs,
+		Target:     buildTarget,
+		Platform:   buildPlatform,
+		NoCache:    buildNoCache,
+	})
+	if err := runRemoteStep(ctx, conn, build, "docker build"); err != nil {
+		return err
+	}
+
+	if buildPush {
+		for _, tag := range buildTags {
+			fmt.Printf("\nPushing %s\n", tag)
+			if err := runRemoteStep(ctx, conn, "docker push "+remote.Quote(tag), "docker push"); err != nil {
+				return err
+			}
+		}
+	}
+	if buildLoad {
+		fmt.Printf("\nLoading %s into the local Docker\n", strings.Join(buildTags, ", "))
+		if err := loadRemoteImage(ctx, conn, buildTags); err != nil {
+			return err
+		}
+	}
+
+	if len(buildTags) > 0 {
+		color.Green("\n✓ Built %s in %s", strings.Join(buildTags, ", "), sandbox.Name)
+	} else {
+		color.Green("\n✓ Built in %s", sandbox.Name)
+	}
+	return nil
+}
+
+// newBuildContextDir returns a fresh directory for a build context, so
+// concurrent builds in one sandbox do not overwrite each other's
+func newBuildContextDir() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return buildContextRoot + "/" + hex.EncodeToString(b), nil
+}
+
+// runRemoteStep runs command with its output streamed to the terminal
+func runRemoteStep(ctx context.Context, conn *remote.Client, command, step string) error {
+	err := conn.Run(ctx, command, nil, os.Stdout, os.Stderr)
+	var exitErr *remote.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s failed with status %d", step, exitErr.Status)
+	}
+	return err
+}
+
+// loadRemoteImage pipes 'docker save' in the sandbox into a local
+// 'docker load'
+func loadRemoteImage(ctx context.Context, conn *remote.Client, tags []string) error {
+	pr, pw := io.Pipe()
+	load := exec.CommandContext(ctx, "docker", "load")
+	load.Stdin, load.Stdout, load.Stderr = pr, os.Stdout, os.Stderr
+	if err := load.Start(); err != nil {
+		return fmt.Errorf("failed to start docker load: %w", err)
+	}
+
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = remote.Quote(tag)
+	}
+	saveErr := conn.Run(ctx, "docker save "+strings.Join(quoted, " "), nil, pw, os.Stderr)
+	pw.CloseWithError(saveErr)
+	loadErr := load.Wait()
+	if saveErr != nil {
+		return fmt.Errorf("docker save failed in the sandbox: %w", saveErr)
+	}
+	if loadErr != nil {
+		return fmt.Errorf("docker load failed: %w", loadErr)
+	}
+	return nil
+}
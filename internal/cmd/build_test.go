@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockerBuildCommand(t *testing.T) {
+	got := dockerBuildCommand(".cvps/build/abc", dockerBuildOptions{
+		Tags:       []string{"myapp:dev", "myapp:latest"},
+		Dockerfile: "docker/Dockerfile",
+		BuildArgs:  map[string]string{"B": "two words", "A": "1"},
+		Platform:   "linux/amd64",
+		NoCache:    true,
+	})
+	want := "cd .cvps/build/abc && DOCKER_BUILDKIT=1 docker build -f docker/Dockerfile -t myapp:dev -t myapp:latest " +
+		"--build-arg A=1 --build-arg 'B=two words' --platform linux/amd64 --no-cache ."
+	if got != want {
+		t.Errorf("dockerBuildCommand() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunBuild_LoadNeedsTag(t *testing.T) {
+	buildLoad = true
+	defer func() { buildLoad = false }()
+
+	err := runBuild(buildCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--tag") {
+		t.Errorf("runBuild() = %v, want an error asking for --tag", err)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// useCassette replays a recorded cassette from testdata for every API client.
+// It is copied first since replay state is kept per cassette path.
+func useCassette(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	data, err := os.ReadFile(filepath.Join("testdata", "cassettes", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(claudevps.ReplayEnv, path)
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "cvps_test"
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, _ := os.Pipe()
+	oldStdout := os.Stdout
+	os.Stdout = w
+	err := fn()
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	return string(out), err
+}
+
+func TestWebhooksFromCassette(t *testing.T) {
+	useCassette(t, "webhooks.json")
+
+	out, err := captureStdout(t, func() error { return runWebhooksList(nil, nil) })
+	if err != nil {
+		t.Fatalf("runWebhooksList() error = %v", err)
+	}
+	if !strings.Contains(out, "wh-1") || !strings.Contains(out, "created,failed") {
+		t.Errorf("Expected the recorded webhook in the listing, got:\n%s", out)
+	}
+
+	defer func(f bool) { webhooksForce = f }(webhooksForce)
+	webhooksForce = true
+
+	out, err = captureStdout(t, func() error { return runWebhooksDelete(nil, []string{"wh-1"}) })
+	if err != nil {
+		t.Fatalf("runWebhooksDelete() error = %v", err)
+	}
+	if !strings.Contains(out, "Webhook wh-1 deleted") {
+		t.Errorf("Expected deletion message, got %q", out)
+	}
+
+	// The cassette records the webhook being gone on the second attempt
+	_, err = captureStdout(t, func() error { return runWebhooksDelete(nil, []string{"wh-1"}) })
+	if err == nil || err.Error() != "webhook not found: wh-1" {
+		t.Errorf("Expected not found on second delete, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var catCmd = &cobra.Command{
+	Use:   "cat <[sandbox:]path>...",
+	Short: "Print files from a sandbox",
+	Long: `Print the contents of one or more sandbox files to standard output.
+
+All paths must refer to the same sandbox. Prefix the first path with
+"sandbox:" to target a sandbox other than the current context.`,
+	Example: `  # Print a log file from the current sandbox
+  cvps cat /var/log/app.log
+
+  # Check a build artifact in a named sandbox
+  cvps cat myproject:/workspace/dist/version.txt`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCat,
+}
+
+func init() {
+	rootCmd.AddCommand(catCmd)
+}
+
+func runCat(cmd *cobra.Command, args []string) error {
+	var sandboxRef string
+	paths := make([]string, 0, len(args))
+	for i, arg := range args {
+		target, ok := parseRemotePath(arg)
+		if !ok {
+			target = remotePath{Path: arg}
+		}
+		if i == 0 {
+			sandboxRef = target.Sandbox
+		} else if target.Sandbox != "" && target.Sandbox != sandboxRef {
+			return fmt.Errorf("all paths must refer to the same sandbox")
+		}
+		paths = append(paths, target.Path)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, sandboxRef)
+	if err != nil {
+		return err
+	}
+
+	fs, err := openSandboxFS(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	for _, p := range paths {
+		remoteFile, err := fs.Resolve(p)
+		if err != nil {
+			return err
+		}
+
+		f, err := fs.Open(remoteFile)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(os.Stdout, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", remoteFile, err)
+		}
+	}
+	return nil
+}
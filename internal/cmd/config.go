@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/internal/project"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -11,7 +20,9 @@ import (
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "View or modify configuration",
-	Long:  `View or modify cvps configuration settings.`,
+	Long: `View or modify cvps configuration settings.
+
+` + configPrecedence,
 }
 
 var configShowCmd = &cobra.Command{
@@ -23,20 +34,7 @@ var configShowCmd = &cobra.Command{
 			return err
 		}
 
-		// Mask sensitive values
-		masked := *cfg
-		if masked.APIKey != "" {
-			if len(masked.APIKey) > 4 {
-				masked.APIKey = "***" + masked.APIKey[len(masked.APIKey)-4:]
-			} else {
-				masked.APIKey = "***"
-			}
-		}
-		if masked.AccessToken != "" {
-			masked.AccessToken = "***"
-		}
-
-		data, err := yaml.Marshal(masked)
+		data, err := yaml.Marshal(maskConfig(cfg))
 		if err != nil {
 			return err
 		}
@@ -63,6 +61,37 @@ var configSetCmd = &cobra.Command{
 			cfg.APIKey = value
 		case "api_base_url":
 			cfg.APIBaseURL = value
+		case "dotfiles":
+			cfg.Dotfiles = value
+		case "down.snapshot_before_delete":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q (use true or false)", key, value)
+			}
+			cfg.Down.SnapshotBeforeDelete = enabled
+		case "connect.sync":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q (use true or false)", key, value)
+			}
+			cfg.Connect.Sync = enabled
+		case "connect.clipboard":
+			if value != clipboardAllow && value != clipboardDeny {
+				return fmt.Errorf("invalid value for %s: %q (use allow or deny)", key, value)
+			}
+			cfg.Connect.Clipboard = value
+		case "update_check":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q (use true or false)", key, value)
+			}
+			cfg.UpdateCheck = &enabled
+		case "history":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q (use true or false)", key, value)
+			}
+			cfg.History = &enabled
 		default:
 			return fmt.Errorf("unknown config key: %s", key)
 		}
@@ -89,9 +118,244 @@ var configPathCmd = &cobra.Command{
 	},
 }
 
+var configExplainCmd = &cobra.Command{
+	Use:   "explain KEY",
+	Short: "Show where the value of a setting comes from",
+	Long: `Show the effective value of a setting, the source it came from and the
+values of lower-precedence sources it overrides. ` + configPrecedence,
+	Example: `  cvps config explain defaults.cpu_cores`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runConfigExplain,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and show where overridden settings come from",
+	Long: `List the settings that differ from the defaults with their source, and check
+for problems: invalid values, project settings that are not allowed and
+CVPS_ environment variables that match no setting. Exits non-zero if any
+problem is found. ` + configPrecedence,
+	Args: cobra.NoArgs,
+	RunE: runConfigDoctor,
+}
+
+// configPrecedence explains the resolution order in help texts
+const configPrecedence = `Settings come from the first of these that has them:
+
+  1. command flags, e.g. 'cvps up --cpu'
+  2. environment variables, CVPS_<KEY> (e.g. CVPS_DEFAULTS_CPU_CORES)
+  3. the config section of cvps.project.yaml in the current directory
+  4. ~/.cvps/config.yaml
+  5. built-in defaults
+
+Project settings live in cvps.project.yaml rather than .cvps.yaml because cvps
+rewrites .cvps.yaml as sandboxes come and go and deletes it with the project's
+last sandbox, which would lose them. Settings found in .cvps.yaml are ignored;
+'cvps config doctor' reports them.`
+
+// otherEnvVars are CVPS_ environment variables that are not settings
+var otherEnvVars = []string{
+	"CVPS_HTTP_RECORD", "CVPS_HTTP_REPLAY", "CVPS_REPO_TOKEN", "CVPS_BIN", "CVPS_HOOK", "CVPS_SUPERVISOR",
+	"CVPS_SANDBOX_ID", "CVPS_SANDBOX_NAME", "CVPS_SANDBOX_STATUS", "CVPS_SSH_HOST", "CVPS_SSH_PORT", "CVPS_SSH_USER",
+	"CVPS_BACKUP_ACCESS_KEY_ID", "CVPS_BACKUP_SECRET_ACCESS_KEY",
+}
+
+// secretKeys are settings whose values are masked for display
+var secretKeys = []string{"api_key", "access_token"}
+
+func runConfigExplain(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	if !slices.Contains(config.Keys(), key) {
+		msg := fmt.Sprintf("unknown config key: %s", key)
+		if matches := closestMatches(key, config.Keys()); len(matches) > 0 {
+			msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(matches, " or "))
+		}
+		return errors.New(msg)
+	}
+
+	settings, err := config.Explain()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(settings, func(s config.Setting) bool { return s.Key == key })
+	s := settings[i]
+
+	effective := s.Effective()
+	fmt.Printf("%s = %s\n", key, displaySetting(key, effective.Value))
+	fmt.Printf("  from %s\n", effective.Origin)
+	if len(s.Values) > 1 {
+		fmt.Println("  overrides:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, v := range s.Values[1:] {
+			fmt.Fprintf(w, "    %s\t%s\n", displaySetting(key, v.Value), v.Origin)
+		}
+		w.Flush()
+	}
+
+	if env := config.EnvVar(key); env != "" {
+		fmt.Printf("  environment variable: %s\n", env)
+	}
+	if slices.Contains(config.ProjectKeys, key) {
+		fmt.Printf("  may be set in the config section of %s\n", project.FileName)
+	}
+	return nil
+}
+
+func runConfigDoctor(cmd *cobra.Command, args []string) error {
+	var problems []string
+
+	settings, err := config.Explain()
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		var changed []config.Setting
+		for _, s := range settings {
+			if s.Effective().Origin.Source != config.SourceDefault {
+				changed = append(changed, s)
+			}
+		}
+		if len(changed) == 0 {
+			fmt.Println("All settings are at their defaults.")
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+			for _, s := range changed {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, truncate(displaySetting(s.Key, s.Effective().Value), 40), s.Effective().Origin)
+			}
+			w.Flush()
+		}
+
+		if cfg, err := config.Load(); err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			problems = append(problems, configValueProblems(cfg)...)
+		}
+	}
+	problems = append(problems, contextFileProblems(".")...)
+	problems = append(problems, unknownEnvProblems(os.Environ())...)
+
+	fmt.Println()
+	if len(problems) == 0 {
+		color.Green("✓ No problems found")
+		return nil
+	}
+	for _, p := range problems {
+		fmt.Printf("%s %s\n", color.RedString("✗"), p)
+	}
+	return fmt.Errorf("found %d configuration problem(s)", len(problems))
+}
+
+// configValueProblems checks values no type check catches
+func configValueProblems(cfg *config.Config) []string {
+	var problems []string
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.Sync.Mode != "" && cfg.Sync.Mode != "mutagen" && cfg.Sync.Mode != "rsync" {
+		problems = append(problems, fmt.Sprintf("sync.mode is %q; use mutagen or rsync", cfg.Sync.Mode))
+	}
+	if c := cfg.Connect.Clipboard; c != "" && c != clipboardAllow && c != clipboardDeny {
+		problems = append(problems, fmt.Sprintf("connect.clipboard is %q; use %s or %s", c, clipboardAllow, clipboardDeny))
+	}
+	return problems
+}
+
+// contextFileProblems reports settings written to the .cvps.yaml in dir,
+// which is not a config source
+func contextFileProblems(dir string) []string {
+	data, err := os.ReadFile(localctx.Path(dir))
+	if err != nil {
+		return nil
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	var sections []string
+	for _, key := range config.Keys() {
+		section, _, _ := strings.Cut(key, ".")
+		if _, ok := raw[section]; ok && !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	if _, ok := raw["config"]; ok {
+		sections = append(sections, "config")
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+	slices.Sort(sections)
+	return []string{fmt.Sprintf("%s sets %s, which is ignored; project settings go in the config section of %s",
+		localctx.FileName, strings.Join(sections, ", "), project.FileName)}
+}
+
+// unknownEnvProblems reports CVPS_ variables in environ that nothing reads,
+// usually a misspelt setting
+func unknownEnvProblems(environ []string) []string {
+	known := slices.Clone(otherEnvVars)
+	for _, key := range config.Keys() {
+		if env := config.EnvVar(key); env != "" {
+			known = append(known, env)
+		}
+	}
+
+	var problems []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, "CVPS_") || slices.Contains(known, name) {
+			continue
+		}
+		msg := fmt.Sprintf("%s is not a cvps setting and is ignored", name)
+		if matches := closestMatches(name, known); len(matches) > 0 {
+			msg += fmt.Sprintf(" (did you mean %s?)", matches[0])
+		}
+		problems = append(problems, msg)
+	}
+	slices.Sort(problems)
+	return problems
+}
+
+// displaySetting masks credentials
+func displaySetting(key, value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+	if slices.Contains(secretKeys, key) {
+		return maskSecret(value)
+	}
+	return value
+}
+
+// maskSecret redacts a credential for display, keeping the last four
+// characters of long ones
+func maskSecret(s string) string {
+	if len(s) > 4 {
+		return "***" + s[len(s)-4:]
+	}
+	return "***"
+}
+
+// maskConfig returns a copy of cfg with credentials redacted for display
+func maskConfig(cfg *config.Config) config.Config {
+	masked := *cfg
+	if masked.APIKey != "" {
+		masked.APIKey = maskSecret(masked.APIKey)
+	}
+	if masked.AccessToken != "" {
+		masked.AccessToken = "***"
+	}
+	return masked
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configExplainCmd)
+	configCmd.AddCommand(configDoctorCmd)
 }
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnknownEnvProblems(t *testing.T) {
+	problems := unknownEnvProblems([]string{
+		"PATH=/usr/bin",
+		"CVPS_API_KEY=cvps_xxx",
+		"CVPS_DEFAULTS_CPU_CORES=4",
+		"CVPS_HTTP_REPLAY=demo.yaml",
+		"CVPS_DEFAULTS_CPU_CORE=4",
+	})
+	if len(problems) != 1 {
+		t.Fatalf("problems = %v, want one", problems)
+	}
+	if !strings.Contains(problems[0], "CVPS_DEFAULTS_CPU_CORE is not a cvps setting") ||
+		!strings.Contains(problems[0], "did you mean CVPS_DEFAULTS_CPU_CORES?") {
+		t.Errorf("problem = %q", problems[0])
+	}
+}
+
+func TestDisplaySetting(t *testing.T) {
+	if got := displaySetting("api_key", "cvps_secret1234"); got != "***1234" {
+		t.Errorf("displaySetting(api_key) = %q", got)
+	}
+	if got := displaySetting("defaults.image", ""); got != "(empty)" {
+		t.Errorf("displaySetting(empty) = %q", got)
+	}
+}
+
+func TestContextFileProblems(t *testing.T) {
+	dir := t.TempDir()
+	if problems := contextFileProblems(dir); len(problems) != 0 {
+		t.Errorf("problems without .cvps.yaml = %v", problems)
+	}
+
+	ctx := "current: sb-1\nsandboxes:\n  - sandbox_id: sb-1\n    created_at: \"2024-01-01\"\n"
+	if err := os.WriteFile(filepath.Join(dir, ".cvps.yaml"), []byte(ctx), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if problems := contextFileProblems(dir); len(problems) != 0 {
+		t.Errorf("problems for a plain context = %v", problems)
+	}
+
+	ctx += "defaults:\n  cpu_cores: 8\nconfig:\n  sync:\n    mode: rsync\n"
+	if err := os.WriteFile(filepath.Join(dir, ".cvps.yaml"), []byte(ctx), 0600); err != nil {
+		t.Fatal(err)
+	}
+	problems := contextFileProblems(dir)
+	if len(problems) != 1 || !strings.Contains(problems[0], ".cvps.yaml sets config, defaults, which is ignored") ||
+		!strings.Contains(problems[0], "cvps.project.yaml") {
+		t.Errorf("problems = %v", problems)
+	}
+}
@@ -1,24 +1,41 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
 	"github.com/achronon/cvps/internal/terminal"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
-	connectMethod string
-	connectName   string
+	connectMethod     string
+	connectName       string
+	connectNoDotfiles bool
+	connectSync       bool
+	connectWait       bool
+	connectTimeout    time.Duration
+	connectRetries    int
+	connectClipboard  string
+	connectScrollback int
+	connectStats      bool
+	connectNoEnv      bool
+	connectTokenScope []string
 )
 
 var (
@@ -37,6 +54,28 @@ var connectCmd = &cobra.Command{
 
 By default, uses SSH.
 
+With --sync (or connect.sync: true in the config), the working directory is
+synced to /workspace for as long as the session is open, so files edited
+locally show up in the remote shell.
+
+If the sandbox is still provisioning or starting, connect offers to wait for
+it (showing the same stages as 'cvps up') and then connects. --wait waits
+without asking, which also works when not on a terminal.
+
+Connection failures while the endpoint is still warming up are retried with
+backoff, up to --retries times within --timeout, before giving up with the
+stage that failed (DNS, TCP, proxy or auth).
+
+Shells in the session know which sandbox they run in: cvps writes
+CVPS_SANDBOX_ID, CVPS_SANDBOX_NAME, CVPS_SANDBOX_REGION and CVPS_API_URL to
+~/.cvps/env in the sandbox, and the first time adds a line sourcing it to
+~/.profile, ~/.bashrc and ~/.zshrc there. With --token-scope (or
+connect.token_scopes in the config) it also puts a token there as
+CVPS_API_KEY, limited to those scopes on this sandbox and expiring after 12
+hours, so tooling in the sandbox can call the API without your own
+credentials; the next connect revokes it. --no-env skips all of it, leaving
+the sandbox's files and profiles untouched.
+
 Use either a sandbox ID argument or --name to select a sandbox.`,
 	Example: `  # Connect to current sandbox
   cvps connect
@@ -44,19 +83,42 @@ Use either a sandbox ID argument or --name to select a sandbox.`,
   # Connect to specific sandbox
   cvps connect sbx-abc123
 
-  # Connect by exact sandbox name
+  # Connect to the current sandbox of a project from any directory
+  cvps connect myproject
+
+  # Connect by sandbox name
   cvps connect --name openclaw
 
+  # Sync the working directory while connected
+  cvps connect --sync
+
+  # Wait for a sandbox that is still starting, then connect
+  cvps connect sbx-abc123 --wait
+
   # Force SSH connection
-  cvps connect --method ssh`,
-	RunE: runConnect,
+  cvps connect --method ssh
+
+  # Let tooling in the sandbox read its own status through the API
+  cvps connect --token-scope sandboxes:read`,
+	ValidArgsFunction: completeSandboxes,
+	RunE:              runConnect,
 }
 
 func init() {
 	rootCmd.AddCommand(connectCmd)
 
 	connectCmd.Flags().StringVarP(&connectMethod, "method", "m", "", "connection method (ssh|websocket)")
-	connectCmd.Flags().StringVar(&connectName, "name", "", "sandbox name (exact match, alternative to sandbox ID argument)")
+	connectCmd.Flags().StringVar(&connectName, "name", "", "sandbox name or unique abbreviation (alternative to the sandbox argument)")
+	connectCmd.Flags().BoolVar(&connectNoDotfiles, "no-dotfiles", false, "skip applying configured dotfiles when missing")
+	connectCmd.Flags().BoolVar(&connectSync, "sync", false, "sync the working directory while connected (default from connect.sync)")
+	connectCmd.Flags().BoolVar(&connectWait, "wait", false, "wait for a sandbox that is still starting instead of asking")
+	connectCmd.Flags().DurationVar(&connectTimeout, "timeout", time.Minute, "how long to keep retrying a failing connection")
+	connectCmd.Flags().IntVar(&connectRetries, "retries", 5, "how many times to retry a failing connection (0 to disable)")
+	connectCmd.Flags().IntVar(&connectScrollback, "scrollback", 10000, "lines of websocket terminal output kept for Ctrl-] [ browsing and search (0 to disable)")
+	connectCmd.Flags().BoolVar(&connectStats, "stats", false, "print network and echo latency when the session ends")
+	connectCmd.Flags().BoolVar(&connectNoEnv, "no-env", false, "do not export the sandbox context (CVPS_SANDBOX_ID and others) or edit shell profiles in the sandbox")
+	connectCmd.Flags().StringSliceVar(&connectTokenScope, "token-scope", nil, "scope of a sandbox-only API token exported as CVPS_API_KEY, repeatable (default from connect.token_scopes)")
+	connectCmd.Flags().StringVar(&connectClipboard, "clipboard", "", "allow or deny clipboard copies (OSC 52) from the sandbox (default from connect.clipboard)")
 }
 
 func runConnect(cmd *cobra.Command, args []string) error {
@@ -70,7 +132,7 @@ func runConnect(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
 	ctx := context.Background()
 
 	sandboxID, err := resolveSandboxIDForConnect(ctx, client, args, connectName)
@@ -81,25 +143,101 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	// Get sandbox info
 	sandbox, err := client.GetSandbox(ctx, sandboxID)
 	if err != nil {
-		if api.IsNotFound(err) {
-			if len(args) > 0 {
-				message := fmt.Sprintf("sandbox not found: %s", args[0])
-				if !looksLikeSandboxID(args[0]) {
-					message += fmt.Sprintf(". If you meant a name, use 'cvps connect --name %s'.", args[0])
-				}
-				return fmt.Errorf(message)
-			}
-
+		if claudevps.IsNotFound(err) {
 			if connectName != "" {
 				return fmt.Errorf("sandbox named %q no longer exists. Run 'cvps status --all' and try again", connectName)
 			}
-
-			return fmt.Errorf("sandbox not found: %s", sandboxID)
+			if len(args) > 0 {
+				return &sandboxNotFoundError{Ref: args[0]}
+			}
+			return &sandboxNotFoundError{Ref: sandboxID}
 		}
 
 		return fmt.Errorf("failed to get sandbox: %w", err)
 	}
 
+	interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	sandbox, err = awaitConnectable(ctx, client, sandbox, connectWait, interactive, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	if !connectSyncEnabled(cmd, cfg) {
+		return openSandboxSession(ctx, cfg, client, sandbox, false)
+	}
+
+	if !isRunningStatus(sandbox.Status) {
+		return fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
+	}
+	if stopSync := startSyncSession(cfg, sandbox); stopSync != nil {
+		defer stopSync()
+	}
+	// Wait for the session so the sync can be stopped when it ends
+	return openSandboxSession(ctx, cfg, client, sandbox, true)
+}
+
+// isStartingStatus reports whether a sandbox is on its way to running
+func isStartingStatus(status string) bool {
+	status = strings.ToLower(strings.TrimSpace(status))
+	return status == "provisioning" || status == "starting"
+}
+
+// awaitConnectable waits for a sandbox that is still provisioning or starting
+// and returns it once running. Without wait the user is asked first, and off
+// a terminal an error suggests --wait. Sandboxes in any other state are
+// returned as they are.
+func awaitConnectable(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, wait, interactive bool, in io.Reader) (*claudevps.Sandbox, error) {
+	if !isStartingStatus(sandbox.Status) {
+		return sandbox, nil
+	}
+	if !wait {
+		if !interactive {
+			return nil, fmt.Errorf("sandbox is not running yet (status: %s). Use --wait to wait for it and connect", sandbox.Status)
+		}
+		fmt.Printf("Sandbox %s is still %s. Wait for it and connect? [Y/n]: ", sandbox.Name, strings.ToLower(sandbox.Status))
+		input, _ := bufio.NewReader(in).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "" && input != "y" && input != "yes" {
+			return nil, fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
+		}
+	}
+	return waitForSandboxReady(ctx, client, sandbox.ID)
+}
+
+// connectSyncEnabled reports whether to sync during the session. An explicit
+// --sync or --sync=false wins over connect.sync in the config.
+func connectSyncEnabled(cmd *cobra.Command, cfg *config.Config) bool {
+	if cmd != nil && cmd.Flags().Changed("sync") {
+		return connectSync
+	}
+	return cfg.Connect.Sync
+}
+
+const (
+	clipboardAllow = "allow"
+	clipboardDeny  = "deny"
+)
+
+// connectClipboardPolicy returns whether clipboard copies from the sandbox
+// reach the local clipboard. --clipboard wins over connect.clipboard.
+func connectClipboardPolicy(cfg *config.Config) (string, error) {
+	policy := connectClipboard
+	if policy == "" {
+		policy = cfg.Connect.Clipboard
+	}
+	switch policy {
+	case "", clipboardAllow:
+		return clipboardAllow, nil
+	case clipboardDeny:
+		return clipboardDeny, nil
+	}
+	return "", fmt.Errorf("invalid clipboard setting %q (use allow or deny)", policy)
+}
+
+// openSandboxSession opens an interactive shell on a running sandbox using the
+// method selected by --method. With wait set, ssh runs as a child process and
+// control returns when the session ends instead of ssh replacing cvps.
+func openSandboxSession(ctx context.Context, cfg *config.Config, client *claudevps.Client, sandbox *claudevps.Sandbox, wait bool) error {
 	if !isRunningStatus(sandbox.Status) {
 		return fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
 	}
@@ -108,26 +246,48 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	clipboard, err := connectClipboardPolicy(cfg)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Connecting to sandbox %s via %s...\n", sandbox.Name, method)
+	exportSandboxContext(ctx, cfg, client, sandbox)
 
 	switch method {
 	case "ssh":
+		// Routes behind a ProxyCommand cannot be probed directly
+		if !sandbox.Connectivity.SSHProxyRequired {
+			if err := retryConnect(ctx, connectTimeout, connectRetries, sshDialAttempt(sandbox)); err != nil {
+				return err
+			}
+		}
+		if !connectNoDotfiles {
+			bootstrapDotfiles(ctx, cfg, sandbox)
+		}
+		// Copies are left to the local terminal unless they must be filtered
+		// out, which needs ssh's output to pass through cvps
+		if connectStats {
+			return runSSHSessionWithStats(ctx, sandbox, clipboard)
+		}
+		if wait || clipboard == clipboardDeny {
+			return runSSHSession(ctx, sandbox, clipboard)
+		}
 		return connectSSH(sandbox)
 	case "websocket":
-		return connectWebSocket(ctx, client, sandbox)
+		return connectWebSocket(ctx, client, sandbox, clipboard)
 	default:
 		return fmt.Errorf("unknown connection method: %s", method)
 	}
 }
 
-func resolveSandboxIDForConnect(ctx context.Context, client *api.Client, args []string, byName string) (string, error) {
+func resolveSandboxIDForConnect(ctx context.Context, client *claudevps.Client, args []string, byName string) (string, error) {
 	if len(args) > 0 && byName != "" {
 		return "", fmt.Errorf("provide either a sandbox ID argument or --name, not both")
 	}
 
 	if len(args) > 0 {
-		return args[0], nil
+		return resolveSandboxRef(ctx, client, args[0])
 	}
 
 	if byName != "" {
@@ -142,60 +302,70 @@ func resolveSandboxIDForConnect(ctx context.Context, client *api.Client, args []
 	return id, nil
 }
 
-func resolveSandboxIDByName(ctx context.Context, client *api.Client, name string) (string, error) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return "", fmt.Errorf("sandbox name cannot be empty")
-	}
+// listPageConcurrency is how many sandbox list pages are fetched at once
+// once the first page has revealed the total
+const listPageConcurrency = 4
+
+func listAllSandboxesForConnect(ctx context.Context, client *claudevps.Client) ([]claudevps.Sandbox, error) {
+	const pageSize = 100
+	const maxPages = 20
 
-	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	first, err := client.ListSandboxes(ctx, 1, pageSize)
 	if err != nil {
-		return "", fmt.Errorf("failed to list sandboxes: %w", err)
+		return nil, err
 	}
 
-	matches := make([]api.Sandbox, 0, 2)
-	for _, sandbox := range sandboxes {
-		if strings.EqualFold(strings.TrimSpace(sandbox.Name), name) {
-			matches = append(matches, sandbox)
-		}
+	pages := 1
+	if len(first.Data) == pageSize && first.Total > pageSize {
+		pages = min((first.Total+pageSize-1)/pageSize, maxPages)
 	}
 
-	switch len(matches) {
-	case 0:
-		return "", fmt.Errorf("sandbox named %q not found. Run 'cvps status --all' to view available sandboxes", name)
-	case 1:
-		return matches[0].ID, nil
-	default:
-		var b strings.Builder
-		fmt.Fprintf(&b, "sandbox name %q is ambiguous. Use a sandbox ID:\n", name)
-		for _, sandbox := range matches {
-			fmt.Fprintf(&b, "  - %s (%s)\n", sandbox.ID, sandbox.Name)
-		}
-		return "", fmt.Errorf(strings.TrimRight(b.String(), "\n"))
+	// Fetch the remaining pages concurrently, keeping them in page order
+	results := make([][]claudevps.Sandbox, pages)
+	results[0] = first.Data
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, listPageConcurrency)
+	var wg sync.WaitGroup
+	for page := 2; page <= pages; page++ {
+		wg.Add(1)
+		go func(page int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			list, err := client.ListSandboxes(ctx, page, pageSize)
+			if err != nil {
+				// Later pages fail with context.Canceled; keep the cause
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			results[page-1] = list.Data
+		}(page)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
 	}
-}
-
-func listAllSandboxesForConnect(ctx context.Context, client *api.Client) ([]api.Sandbox, error) {
-	const pageSize = 100
-	const maxPages = 20
-
-	all := make([]api.Sandbox, 0, pageSize)
-	for page := 1; page <= maxPages; page++ {
-		list, err := client.ListSandboxes(ctx, page, pageSize)
-		if err != nil {
-			return nil, err
-		}
 
-		all = append(all, list.Data...)
-		if len(list.Data) < pageSize || len(all) >= list.Total {
-			break
-		}
+	all := make([]claudevps.Sandbox, 0, pages*pageSize)
+	for _, data := range results {
+		all = append(all, data...)
 	}
 
+	cacheSandboxes(all)
 	return all, nil
 }
 
-func resolveConnectMethod(requested string, sandbox *api.Sandbox) (string, error) {
+func resolveConnectMethod(requested string, sandbox *claudevps.Sandbox) (string, error) {
 	method := strings.ToLower(strings.TrimSpace(requested))
 
 	switch method {
@@ -251,20 +421,11 @@ func isSSHAvailable() bool {
 	return err == nil
 }
 
-func connectSSH(sandbox *api.Sandbox) error {
+func connectSSH(sandbox *claudevps.Sandbox) error {
 	if sandbox.SSHHost == "" {
 		return fmt.Errorf("SSH not available for this sandbox")
 	}
 
-	// Build SSH command
-	sshArgs := []string{
-		"-o", "StrictHostKeyChecking=accept-new",
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "LogLevel=ERROR",
-		"-p", fmt.Sprintf("%d", sandbox.SSHPort),
-		fmt.Sprintf("%s@%s", sandbox.SSHUser, sandbox.SSHHost),
-	}
-
 	// Execute SSH
 	sshPath, err := exec.LookPath("ssh")
 	if err != nil {
@@ -272,20 +433,71 @@ func connectSSH(sandbox *api.Sandbox) error {
 	}
 
 	// Replace current process with SSH
-	return syscall.Exec(sshPath, append([]string{"ssh"}, sshArgs...), os.Environ())
+	return syscall.Exec(sshPath, append([]string{"ssh"}, sshSessionArgs(sandbox)...), os.Environ())
 }
 
-func connectWebSocket(ctx context.Context, client *api.Client, sandbox *api.Sandbox) error {
-	// Get terminal websocket info from API
-	wsInfo, err := client.GetTerminalWebSocket(ctx, sandbox.ID)
+// runSSHSession runs an interactive ssh session as a child process. With the
+// clipboard denied, OSC 52 copies are stripped from the session output.
+func runSSHSession(ctx context.Context, sandbox *claudevps.Sandbox, clipboard string) error {
+	if sandbox.SSHHost == "" {
+		return fmt.Errorf("SSH not available for this sandbox")
+	}
+	sshPath, err := exec.LookPath("ssh")
 	if err != nil {
-		return fmt.Errorf("failed to get terminal connection: %w", err)
+		return fmt.Errorf("ssh not found in PATH")
 	}
 
-	// Create Socket.IO terminal connection
-	term, err := terminal.NewSocketIOTerminal(wsInfo.URL, wsInfo.Token, sandbox.ID)
+	args := sshSessionArgs(sandbox)
+	var stdout io.Writer = os.Stdout
+	if clipboard == clipboardDeny {
+		// stdout is a pipe now, so the remote tty has to be asked for
+		args = append([]string{"-tt"}, args...)
+		stdout = terminal.NewOSC52Filter(os.Stdout, nil)
+	}
+	c := exec.CommandContext(ctx, sshPath, args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() != 255 {
+			// The remote shell's own exit status, not a connection failure
+			return nil
+		}
+		return fmt.Errorf("ssh session failed: %w", err)
+	}
+	return nil
+}
+
+func sshSessionArgs(sandbox *claudevps.Sandbox) []string {
+	return []string{
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "LogLevel=ERROR",
+		"-p", fmt.Sprintf("%d", sandbox.SSHPort),
+		fmt.Sprintf("%s@%s", sandbox.SSHUser, sandbox.SSHHost),
+	}
+}
+
+func connectWebSocket(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, clipboard string) error {
+	var term *terminal.SocketIOTerminal
+	err := retryConnect(ctx, connectTimeout, connectRetries, func(ctx context.Context) error {
+		// Get terminal websocket info from API
+		wsInfo, err := client.GetTerminalWebSocket(ctx, sandbox.ID)
+		if err != nil {
+			if claudevps.IsUnauthorized(err) || claudevps.IsForbidden(err) {
+				return &connectError{Stage: connectStageAuth, Err: err}
+			}
+			return fmt.Errorf("failed to get terminal connection: %w", err)
+		}
+
+		// Create Socket.IO terminal connection
+		term, err = terminal.NewSocketIOTerminal(ctx, wsInfo.URL, wsInfo.Token, sandbox.ID)
+		if err != nil {
+			return classifyWebSocketError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create terminal: %w", err)
+		return err
 	}
 	defer term.Close()
 
@@ -300,6 +512,38 @@ func connectWebSocket(ctx context.Context, client *api.Client, sandbox *api.Sand
 		}
 	}()
 
+	// Measure latency for the Ctrl-] s status title and --stats
+	start := time.Now()
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	var showStatus atomic.Bool
+	var sb *terminal.Scrollback
+	setTitle := func() {
+		if showStatus.Load() {
+			fmt.Fprintf(sb, "\x1b]0;%s\x07", latencyTitle(sandbox.Name, term.RTT().Summary(), term.Echo().Summary()))
+		}
+	}
+	go watchLatency(watchCtx, term, setTitle)
+
+	// Keep scrollback, since the server does not
+	var stdin io.Reader = os.Stdin
+	var stdout io.Writer = os.Stdout
+	if connectScrollback > 0 {
+		sb = terminal.NewScrollback(os.Stdout, connectScrollback, terminal.CopyToClipboard)
+		stdin, stdout = sb.Input(os.Stdin), sb
+		// The status goes in the window title so it never covers output;
+		// the previous title is saved on the terminal's title stack
+		sb.Bind('s', func() {
+			if showStatus.CompareAndSwap(false, true) {
+				fmt.Fprint(sb, "\x1b[22;0t")
+				setTitle()
+			} else if showStatus.CompareAndSwap(true, false) {
+				fmt.Fprint(sb, "\x1b[23;0t")
+			}
+		})
+		fmt.Println("Press Ctrl-] [ to scroll back, Ctrl-] / to search, Ctrl-] s to show connection quality")
+	}
+
 	// Set raw mode
 	restore, err := terminal.SetRaw()
 	if err != nil {
@@ -312,6 +556,19 @@ func connectWebSocket(ctx context.Context, client *api.Client, sandbox *api.Sand
 		_ = term.Resize(cols, rows)
 	}
 
-	// Start I/O forwarding
-	return term.Run(os.Stdin, os.Stdout)
+	// Start I/O forwarding, putting clipboard copies on the local clipboard
+	var copyFn func([]byte) error
+	if clipboard == clipboardAllow {
+		copyFn = terminal.CopyToClipboard
+	}
+	err = term.Run(stdin, terminal.NewOSC52Filter(stdout, copyFn))
+	stopWatch()
+	if showStatus.Load() {
+		fmt.Fprint(sb, "\x1b[23;0t")
+	}
+	restore()
+	if connectStats {
+		printConnectionStats(os.Stdout, term.RTT().Summary(), term.Echo().Summary(), time.Since(start))
+	}
+	return err
 }
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// sandboxEnvFile holds the sandbox context in the sandbox, relative to home
+const sandboxEnvFile = ".cvps/env"
+
+// connectTokenExpiry is how long a token put in the sandbox's environment
+// lasts. Every connect replaces it with a new one and revokes the old one.
+const connectTokenExpiry = 12 * time.Hour
+
+// envTokenIDPrefix starts the line of the env file recording which token it
+// holds, so the next connect can revoke it
+const envTokenIDPrefix = "# token_id: "
+
+// sandboxEnvScript prints the ID of the token in the current env file, writes
+// the new file from stdin, readable only by the sandbox user, and sources it
+// from the shell profiles once
+const sandboxEnvScript = `umask 077 && mkdir -p "$HOME/.cvps" || exit 1
+sed -n 's/^` + envTokenIDPrefix + `//p' "$HOME/` + sandboxEnvFile + `" 2>/dev/null
+cat > "$HOME/` + sandboxEnvFile + `" || exit 1
+for f in .profile .bashrc .zshrc; do
+  [ "$f" = .zshrc ] && [ ! -f "$HOME/$f" ] && continue
+  grep -qF '` + sandboxEnvFile + `' "$HOME/$f" 2>/dev/null ||
+    printf '\n[ -f "$HOME/` + sandboxEnvFile + `" ] && . "$HOME/` + sandboxEnvFile + `"\n' >> "$HOME/$f"
+done`
+
+// connectTokenScopes returns the scopes of the token to put in the sandbox's
+// environment. --token-scope wins over connect.token_scopes.
+func connectTokenScopes(cfg *config.Config) ([]string, error) {
+	scopes := cfg.Connect.TokenScopes
+	if connectTokenScope != nil { // set by the flag, even to nothing
+		scopes = connectTokenScope
+	}
+	return normalizeTokenScopes(scopes)
+}
+
+// sandboxContextEnv returns the variables that tell tooling in the sandbox
+// which sandbox it runs in and how to reach the API, as NAME=value
+func sandboxContextEnv(cfg *config.Config, sandbox *claudevps.Sandbox, token string) []string {
+	env := []string{
+		"CVPS_SANDBOX_ID=" + sandbox.ID,
+		"CVPS_SANDBOX_NAME=" + sandbox.Name,
+	}
+	if sandbox.Region != "" {
+		env = append(env, "CVPS_SANDBOX_REGION="+sandbox.Region)
+	}
+	env = append(env, "CVPS_API_URL="+cfg.APIBaseURL)
+	if token != "" {
+		env = append(env, "CVPS_API_KEY="+token)
+	}
+	return env
+}
+
+// renderEnvFile turns NAME=value pairs into a file for sh to source, noting
+// the ID of the token among them if there is one
+func renderEnvFile(env []string, tokenID string) string {
+	var b strings.Builder
+	b.WriteString("# Written by 'cvps connect'; replaced on every connect\n")
+	if tokenID != "" {
+		b.WriteString(envTokenIDPrefix + tokenID + "\n")
+	}
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", name, remote.Quote(value))
+	}
+	return b.String()
+}
+
+// createConnectToken creates a token that only reaches sandbox, for tooling
+// in the sandbox to call the API with instead of the user's credentials
+func createConnectToken(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, scopes []string) (*claudevps.APIToken, error) {
+	token, err := client.CreateToken(ctx, &claudevps.CreateTokenRequest{
+		Name:             "cvps connect " + sandbox.Name,
+		Scopes:           scopes,
+		ExpiresInSeconds: int(connectTokenExpiry / time.Second),
+		Sandbox:          sandbox.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+	return token, nil
+}
+
+// exportSandboxContext writes the sandbox context to ~/.cvps/env in the
+// sandbox, where login and interactive shells source it, so tooling started
+// in the session knows where it runs. It goes over SSH like dotfiles, for
+// either connect method, and failures only warn.
+func exportSandboxContext(ctx context.Context, cfg *config.Config, client *claudevps.Client, sandbox *claudevps.Sandbox) {
+	if connectNoEnv {
+		return
+	}
+	if sandbox.SSHHost == "" || sandbox.Connectivity.SSHProxyRequired {
+		debuglog.Printf("not exporting sandbox context: no direct SSH to %s", sandbox.ID)
+		return
+	}
+	scopes, err := connectTokenScopes(cfg)
+	if err != nil {
+		color.Yellow("⚠ Sandbox context not exported: %v", err)
+		return
+	}
+
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		color.Yellow("⚠ Sandbox context not exported: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var token *claudevps.APIToken
+	if len(scopes) > 0 {
+		if token, err = createConnectToken(ctx, client, sandbox, scopes); err != nil {
+			color.Yellow("⚠ Sandbox context not exported: %v", err)
+			return
+		}
+	}
+	secret, tokenID := "", ""
+	if token != nil {
+		secret, tokenID = token.Token, token.ID
+	}
+	env := renderEnvFile(sandboxContextEnv(cfg, sandbox, secret), tokenID)
+	var previous strings.Builder
+	if err := conn.Run(ctx, sandboxEnvScript, strings.NewReader(env), &previous, nil); err != nil {
+		color.Yellow("⚠ Sandbox context not exported: %v", err)
+		// Nothing can use the token now
+		if token != nil {
+			revokeConnectToken(ctx, client, token.ID)
+		}
+		return
+	}
+	// The token the file held before is no longer used
+	if old := strings.TrimSpace(previous.String()); old != "" && old != tokenID {
+		revokeConnectToken(ctx, client, old)
+	}
+}
+
+// revokeConnectToken revokes a token cvps put in a sandbox. Failures are only
+// logged, as the token expires on its own.
+func revokeConnectToken(ctx context.Context, client *claudevps.Client, id string) {
+	if err := client.RevokeToken(ctx, id); err != nil && !claudevps.IsNotFound(err) {
+		debuglog.Printf("failed to revoke token %s: %v", id, err)
+	}
+}
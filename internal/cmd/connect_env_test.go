@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSandboxContextEnv(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.APIBaseURL = "https://api.example.com"
+	sandbox := &claudevps.Sandbox{ID: "sbx-1", Name: "it's web", Region: "eu-west"}
+
+	env := sandboxContextEnv(cfg, sandbox, "cvps_tok")
+	want := []string{
+		"CVPS_SANDBOX_ID=sbx-1",
+		"CVPS_SANDBOX_NAME=it's web",
+		"CVPS_SANDBOX_REGION=eu-west",
+		"CVPS_API_URL=https://api.example.com",
+		"CVPS_API_KEY=cvps_tok",
+	}
+	if !slices.Equal(env, want) {
+		t.Fatalf("sandboxContextEnv() = %v, want %v", env, want)
+	}
+
+	// The file must give the same values back when sourced
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	out, err := exec.Command("sh", "-c", renderEnvFile(env, "tok-1")+"env").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range want {
+		if !strings.Contains(string(out), kv+"\n") {
+			t.Errorf("sourced env missing %q", kv)
+		}
+	}
+}
+
+func TestConnectTokenScopes(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Connect.TokenScopes = []string{"sandboxes:read"}
+	defer func() { connectTokenScope = nil }()
+
+	if got, _ := connectTokenScopes(cfg); !slices.Equal(got, []string{"sandboxes:read"}) {
+		t.Errorf("scopes from config = %v", got)
+	}
+	connectTokenScope = []string{}
+	if got, _ := connectTokenScopes(cfg); len(got) != 0 {
+		t.Errorf("--token-scope= should create no token, got %v", got)
+	}
+	connectTokenScope = []string{"sandboxes:admin"}
+	if _, err := connectTokenScopes(cfg); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
+
+func TestCreateConnectToken(t *testing.T) {
+	var req claudevps.CreateTokenRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.APIToken{ID: "tok-1", Token: "cvps_tok"})
+	}))
+	client, err := newAPIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := createConnectToken(context.Background(), client, &claudevps.Sandbox{ID: "sbx-1", Name: "web"}, []string{"sandboxes:read"})
+	if err != nil || token.Token != "cvps_tok" {
+		t.Fatalf("createConnectToken() = %+v, %v", token, err)
+	}
+	if req.Sandbox != "sbx-1" || req.ExpiresInSeconds != 12*3600 || req.Name != "cvps connect web" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+}
+
+func TestSandboxEnvScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	home := t.TempDir()
+	run := func(env string) string {
+		t.Helper()
+		cmd := exec.Command("sh", "-c", sandboxEnvScript)
+		cmd.Env = []string{"HOME=" + home, "PATH=" + os.Getenv("PATH")}
+		cmd.Stdin = strings.NewReader(env)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if prev := run(renderEnvFile([]string{"CVPS_SANDBOX_ID=sbx-1"}, "tok-1")); prev != "" {
+		t.Errorf("first run reported previous token %q", prev)
+	}
+	if prev := run(renderEnvFile([]string{"CVPS_SANDBOX_ID=sbx-1"}, "")); prev != "tok-1" {
+		t.Errorf("second run reported previous token %q, want tok-1", prev)
+	}
+
+	// Profiles source the file once, however often connect runs
+	profile, err := os.ReadFile(filepath.Join(home, ".profile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(profile), sandboxEnvFile); n != 2 {
+		t.Errorf(".profile mentions the env file %d times, want one line:\n%s", n, profile)
+	}
+}
@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
 )
 
 func TestIsSSHAvailable(t *testing.T) {
@@ -28,7 +34,7 @@ func TestIsSSHAvailable(t *testing.T) {
 }
 
 func TestConnectSSH_NoSSHHost(t *testing.T) {
-	sandbox := &api.Sandbox{
+	sandbox := &claudevps.Sandbox{
 		ID:      "sbx-test123",
 		Name:    "test-sandbox",
 		Status:  "running",
@@ -49,7 +55,7 @@ func TestConnectSSH_NoSSHHost(t *testing.T) {
 }
 
 func TestConnectSSH_BuildsCorrectArgs(t *testing.T) {
-	sandbox := &api.Sandbox{
+	sandbox := &claudevps.Sandbox{
 		ID:      "sbx-test123",
 		Name:    "test-sandbox",
 		Status:  "running",
@@ -212,6 +218,8 @@ func TestResolveSandboxIDForConnect_MutuallyExclusiveSelectors(t *testing.T) {
 }
 
 func TestResolveSandboxIDByName_SingleMatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/sandboxes" {
 			t.Fatalf("unexpected path: %s", r.URL.Path)
@@ -228,7 +236,7 @@ func TestResolveSandboxIDByName_SingleMatch(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "cvps_test")
+	client := claudevps.NewClient(server.URL, "cvps_test")
 	sandboxID, err := resolveSandboxIDByName(context.Background(), client, "OpenClaw")
 	if err != nil {
 		t.Fatalf("resolveSandboxIDByName() error = %v, want nil", err)
@@ -239,6 +247,8 @@ func TestResolveSandboxIDByName_SingleMatch(t *testing.T) {
 }
 
 func TestResolveSandboxIDByName_NoMatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"data":  []map[string]any{},
@@ -249,17 +259,21 @@ func TestResolveSandboxIDByName_NoMatch(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "cvps_test")
+	client := claudevps.NewClient(server.URL, "cvps_test")
 	_, err := resolveSandboxIDByName(context.Background(), client, "openclaw")
 	if err == nil {
 		t.Fatal("resolveSandboxIDByName() error = nil, want non-nil")
 	}
-	if !strings.Contains(err.Error(), "Run 'cvps status --all'") {
-		t.Fatalf("resolveSandboxIDByName() error = %q, expected status hint", err.Error())
+	var buf bytes.Buffer
+	presentError(&buf, err)
+	if !strings.Contains(buf.String(), "Run 'cvps status --all'") {
+		t.Fatalf("presentError() = %q, expected status hint", buf.String())
 	}
 }
 
 func TestResolveSandboxIDByName_Ambiguous(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"data": []map[string]any{
@@ -273,7 +287,7 @@ func TestResolveSandboxIDByName_Ambiguous(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "cvps_test")
+	client := claudevps.NewClient(server.URL, "cvps_test")
 	_, err := resolveSandboxIDByName(context.Background(), client, "openclaw")
 	if err == nil {
 		t.Fatal("resolveSandboxIDByName() error = nil, want non-nil")
@@ -293,7 +307,7 @@ func TestResolveConnectMethod(t *testing.T) {
 	tests := []struct {
 		name     string
 		request  string
-		sandbox  *api.Sandbox
+		sandbox  *claudevps.Sandbox
 		want     string
 		wantErr  bool
 		errMatch string
@@ -301,26 +315,26 @@ func TestResolveConnectMethod(t *testing.T) {
 		{
 			name:    "auto without ssh host falls back to websocket",
 			request: "",
-			sandbox: &api.Sandbox{ID: "sbx-abc123", SSHHost: ""},
+			sandbox: &claudevps.Sandbox{ID: "sbx-abc123", SSHHost: ""},
 			want:    "websocket",
 		},
 		{
 			name:    "websocket explicit method",
 			request: "websocket",
-			sandbox: &api.Sandbox{ID: "sbx-abc123", SSHHost: "sbx.example.com"},
+			sandbox: &claudevps.Sandbox{ID: "sbx-abc123", SSHHost: "sbx.example.com"},
 			want:    "websocket",
 		},
 		{
 			name:     "ssh without host errors",
 			request:  "ssh",
-			sandbox:  &api.Sandbox{ID: "sbx-abc123", SSHHost: ""},
+			sandbox:  &claudevps.Sandbox{ID: "sbx-abc123", SSHHost: ""},
 			wantErr:  true,
 			errMatch: "SSH connection is not available",
 		},
 		{
 			name:     "unknown method errors",
 			request:  "telnet",
-			sandbox:  &api.Sandbox{ID: "sbx-abc123", SSHHost: "sbx.example.com"},
+			sandbox:  &claudevps.Sandbox{ID: "sbx-abc123", SSHHost: "sbx.example.com"},
 			wantErr:  true,
 			errMatch: "unknown connection method",
 		},
@@ -362,3 +376,165 @@ func TestLooksLikeSandboxID(t *testing.T) {
 		}
 	}
 }
+
+func TestConnectSyncEnabled(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if connectSyncEnabled(nil, cfg) {
+		t.Error("sync should be off by default")
+	}
+
+	cfg.Connect.Sync = true
+	if !connectSyncEnabled(nil, cfg) {
+		t.Error("connect.sync: true should enable sync")
+	}
+
+	cmd := &cobra.Command{Use: "connect"}
+	cmd.Flags().BoolVar(&connectSync, "sync", false, "")
+	defer func() { connectSync = false }()
+	if err := cmd.Flags().Parse([]string{"--sync=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if connectSyncEnabled(cmd, cfg) {
+		t.Error("--sync=false should override connect.sync")
+	}
+
+	cfg.Connect.Sync = false
+	if err := cmd.Flags().Parse([]string{"--sync"}); err != nil {
+		t.Fatal(err)
+	}
+	if !connectSyncEnabled(cmd, cfg) {
+		t.Error("--sync should enable sync")
+	}
+}
+
+func TestConnectClipboardPolicy(t *testing.T) {
+	defer func() { connectClipboard = "" }()
+	cfg := config.DefaultConfig()
+
+	if got, err := connectClipboardPolicy(cfg); err != nil || got != clipboardAllow {
+		t.Errorf("default policy = %q, %v; want allow", got, err)
+	}
+
+	cfg.Connect.Clipboard = clipboardDeny
+	if got, _ := connectClipboardPolicy(cfg); got != clipboardDeny {
+		t.Errorf("connect.clipboard: deny gave %q", got)
+	}
+
+	connectClipboard = clipboardAllow
+	if got, _ := connectClipboardPolicy(cfg); got != clipboardAllow {
+		t.Errorf("--clipboard allow should override the config, got %q", got)
+	}
+
+	connectClipboard = "sometimes"
+	if _, err := connectClipboardPolicy(cfg); err == nil {
+		t.Error("Expected error for an unknown clipboard setting")
+	}
+}
+
+func TestListAllSandboxesForConnect_ConcurrentPages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	const total = 350
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		// Later pages answer first so ordering has to be restored
+		time.Sleep(time.Duration(10-page) * 5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		var data []claudevps.Sandbox
+		for i := (page - 1) * limit; i < min(page*limit, total); i++ {
+			data = append(data, claudevps.Sandbox{ID: fmt.Sprintf("sbx-%03d", i)})
+		}
+		json.NewEncoder(w).Encode(claudevps.SandboxList{Data: data, Total: total, Page: page, Limit: limit})
+	}))
+	defer server.Close()
+
+	sandboxes, err := listAllSandboxesForConnect(context.Background(), claudevps.NewClient(server.URL, "cvps_test"))
+	if err != nil {
+		t.Fatalf("listAllSandboxesForConnect() error = %v", err)
+	}
+	if len(sandboxes) != total {
+		t.Fatalf("got %d sandboxes, want %d", len(sandboxes), total)
+	}
+	for i, s := range sandboxes {
+		if want := fmt.Sprintf("sbx-%03d", i); s.ID != want {
+			t.Fatalf("sandbox %d = %s, want %s", i, s.ID, want)
+		}
+	}
+	if maxInFlight > listPageConcurrency {
+		t.Errorf("%d requests in flight, want at most %d", maxInFlight, listPageConcurrency)
+	}
+}
+
+func TestListAllSandboxesForConnect_PageError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(claudevps.APIError{StatusCode: 500, Message: "page 3 failed"})
+			return
+		}
+		data := make([]claudevps.Sandbox, 100)
+		json.NewEncoder(w).Encode(claudevps.SandboxList{Data: data, Total: 500, Page: page, Limit: 100})
+	}))
+	defer server.Close()
+
+	_, err := listAllSandboxesForConnect(context.Background(), claudevps.NewClient(server.URL, "cvps_test"))
+	if err == nil || !strings.Contains(err.Error(), "page 3 failed") {
+		t.Fatalf("listAllSandboxesForConnect() error = %v, want page 3 failure", err)
+	}
+}
+
+func TestAwaitConnectable(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-1/status" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		polls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-1", Name: "dev", Status: "running", SSHHost: "dev.example.com"})
+	}))
+	defer server.Close()
+	client := claudevps.NewClient(server.URL, "test-key")
+	ctx := context.Background()
+	starting := &claudevps.Sandbox{ID: "sbx-1", Name: "dev", Status: "starting"}
+
+	if _, err := awaitConnectable(ctx, client, starting, false, false, strings.NewReader("")); err == nil || !strings.Contains(err.Error(), "--wait") {
+		t.Errorf("Expected error suggesting --wait off a terminal, got %v", err)
+	}
+	if _, err := awaitConnectable(ctx, client, starting, false, true, strings.NewReader("n\n")); err == nil {
+		t.Error("Expected error when declining to wait")
+	}
+	if polls != 0 {
+		t.Fatalf("Expected no polling without waiting, got %d", polls)
+	}
+
+	sandbox, err := awaitConnectable(ctx, client, starting, false, true, strings.NewReader("\n"))
+	if err != nil {
+		t.Fatalf("awaitConnectable() error = %v", err)
+	}
+	if sandbox.Status != "running" || sandbox.SSHHost != "dev.example.com" {
+		t.Errorf("Expected the running sandbox, got %+v", sandbox)
+	}
+
+	stopped := &claudevps.Sandbox{ID: "sbx-1", Status: "stopped"}
+	if got, err := awaitConnectable(ctx, client, stopped, true, false, nil); err != nil || got != stopped {
+		t.Errorf("Expected stopped sandbox to be returned unchanged, got %+v, %v", got, err)
+	}
+	if polls != 1 {
+		t.Errorf("Expected 1 status poll, got %d", polls)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/achronon/cvps/internal/terminal"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// connectRetryDelay is the pause before the first retry; it doubles after
+// each attempt up to connectRetryMaxDelay
+var connectRetryDelay = time.Second
+
+const connectRetryMaxDelay = 8 * time.Second
+
+// Stages a connection attempt can fail at
+const (
+	connectStageDNS   = "DNS"
+	connectStageTCP   = "TCP"
+	connectStageProxy = "proxy"
+	connectStageAuth  = "auth"
+)
+
+// connectStageHints tell the user what a failure at each stage usually means
+var connectStageHints = map[string]string{
+	connectStageDNS:   "The sandbox hostname does not resolve; new sandboxes can take a minute to appear in DNS",
+	connectStageTCP:   "Nothing accepted the connection; the sandbox may still be booting, or a firewall is in the way",
+	connectStageProxy: "The connection was accepted but the sandbox behind the proxy did not answer; it may still be warming up",
+	connectStageAuth:  "The credentials were rejected. Run 'cvps login' and try again",
+}
+
+// connectError is a failed connection attempt and the stage it failed at
+type connectError struct {
+	Stage string
+	Err   error
+}
+
+func (e *connectError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *connectError) Unwrap() error { return e.Err }
+
+// transient reports whether another attempt may succeed; rejected credentials
+// will not fix themselves
+func (e *connectError) transient() bool {
+	return e.Stage != connectStageAuth
+}
+
+// retryConnect calls attempt until it succeeds, backing off between attempts,
+// for at most retries retries or until timeout has passed. Errors that are
+// not a *connectError, and auth failures, are returned without retrying.
+func retryConnect(ctx context.Context, timeout time.Duration, retries int, attempt func(context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	delay := connectRetryDelay
+	for n := 1; ; n++ {
+		err := attempt(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var ce *connectError
+		if !errors.As(err, &ce) {
+			return err
+		}
+		if !ce.transient() || n > retries || !waitFits(ctx, delay) {
+			return connectDiagnosis(ce, n, time.Since(start))
+		}
+
+		color.Yellow("⚠ Connection failed at %s (%v); retrying in %s (%d/%d)", ce.Stage, ce.Err, delay, n, retries)
+		select {
+		case <-ctx.Done():
+			return connectDiagnosis(ce, n, time.Since(start))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, connectRetryMaxDelay)
+	}
+}
+
+// waitFits reports whether ctx leaves time for another attempt after delay
+func waitFits(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// connectDiagnosis is the error shown once retrying gives up
+func connectDiagnosis(ce *connectError, attempts int, elapsed time.Duration) error {
+	plural := "s"
+	if attempts == 1 {
+		plural = ""
+	}
+	return fmt.Errorf("could not connect to sandbox: %s check failed after %d attempt%s in %s: %w. %s",
+		ce.Stage, attempts, plural, elapsed.Round(time.Second), ce.Err, connectStageHints[ce.Stage])
+}
+
+// sshDialAttempt checks that the sandbox's SSH endpoint resolves, accepts a
+// connection and answers with an SSH banner, so ssh is only started once it
+// can get through
+func sshDialAttempt(sandbox *claudevps.Sandbox) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if sandbox.SSHHost == "" {
+			return fmt.Errorf("SSH not available for this sandbox")
+		}
+		if _, err := net.DefaultResolver.LookupHost(ctx, sandbox.SSHHost); err != nil {
+			return &connectError{Stage: connectStageDNS, Err: fmt.Errorf("lookup %s failed", sandbox.SSHHost)}
+		}
+
+		result := probeSSH(ctx, sandbox.SSHHost, sandbox.SSHPort, probeTimeout)
+		switch {
+		case !result.Reachable:
+			return &connectError{Stage: connectStageTCP, Err: errors.New(result.Error)}
+		case result.Banner == "":
+			return &connectError{Stage: connectStageProxy, Err: errors.New(result.Error)}
+		}
+		return nil
+	}
+}
+
+// classifyWebSocketError works out which stage a terminal dial failed at
+func classifyWebSocketError(err error) *connectError {
+	var dnsErr *net.DNSError
+	var hsErr *terminal.HandshakeError
+	switch {
+	case errors.As(err, &dnsErr):
+		return &connectError{Stage: connectStageDNS, Err: err}
+	case errors.As(err, &hsErr):
+		if hsErr.StatusCode == http.StatusUnauthorized || hsErr.StatusCode == http.StatusForbidden {
+			return &connectError{Stage: connectStageAuth, Err: err}
+		}
+		// Something answered over HTTP, but not the terminal
+		return &connectError{Stage: connectStageProxy, Err: err}
+	default:
+		return &connectError{Stage: connectStageTCP, Err: err}
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/terminal"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func zeroConnectRetryDelay(t *testing.T) {
+	old := connectRetryDelay
+	connectRetryDelay = 0
+	t.Cleanup(func() { connectRetryDelay = old })
+}
+
+func TestRetryConnect_SucceedsAfterTransientFailures(t *testing.T) {
+	zeroConnectRetryDelay(t)
+
+	attempts := 0
+	err := retryConnect(context.Background(), time.Minute, 5, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return &connectError{Stage: connectStageTCP, Err: errors.New("connection refused")}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryConnect() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryConnect_GivesUpWithDiagnosis(t *testing.T) {
+	zeroConnectRetryDelay(t)
+
+	attempts := 0
+	err := retryConnect(context.Background(), time.Minute, 2, func(context.Context) error {
+		attempts++
+		return &connectError{Stage: connectStageDNS, Err: errors.New("lookup sbx.example.com failed")}
+	})
+	if attempts != 3 {
+		t.Errorf("Expected 1 attempt and 2 retries, got %d attempts", attempts)
+	}
+	if err == nil || !strings.Contains(err.Error(), "DNS check failed after 3 attempts") {
+		t.Errorf("Expected DNS diagnosis, got %v", err)
+	}
+}
+
+func TestRetryConnect_DoesNotRetryAuthOrOtherErrors(t *testing.T) {
+	zeroConnectRetryDelay(t)
+
+	for _, failure := range []error{
+		&connectError{Stage: connectStageAuth, Err: errors.New("HTTP 401")},
+		fmt.Errorf("SSH not available for this sandbox"),
+	} {
+		attempts := 0
+		err := retryConnect(context.Background(), time.Minute, 5, func(context.Context) error {
+			attempts++
+			return failure
+		})
+		if err == nil || attempts != 1 {
+			t.Errorf("Expected a single failed attempt for %v, got %d (err = %v)", failure, attempts, err)
+		}
+	}
+}
+
+func TestSSHDialAttempt(t *testing.T) {
+	host, port := startBannerServer(t, "SSH-2.0-OpenSSH_9.6\r\n")
+	if err := sshDialAttempt(&claudevps.Sandbox{SSHHost: host, SSHPort: port})(context.Background()); err != nil {
+		t.Errorf("Expected SSH endpoint to pass, got %v", err)
+	}
+
+	host, port = startBannerServer(t, "HTTP/1.1 502 Bad Gateway\r\n")
+	var ce *connectError
+	err := sshDialAttempt(&claudevps.Sandbox{SSHHost: host, SSHPort: port})(context.Background())
+	if !errors.As(err, &ce) || ce.Stage != connectStageProxy {
+		t.Errorf("Expected proxy failure for non-SSH endpoint, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	err = sshDialAttempt(&claudevps.Sandbox{SSHHost: "127.0.0.1", SSHPort: closedPort})(context.Background())
+	if !errors.As(err, &ce) || ce.Stage != connectStageTCP {
+		t.Errorf("Expected TCP failure for closed port, got %v", err)
+	}
+}
+
+func TestClassifyWebSocketError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "term.example.com"}, connectStageDNS},
+		{&terminal.HandshakeError{StatusCode: 401, Err: errors.New("bad handshake")}, connectStageAuth},
+		{&terminal.HandshakeError{StatusCode: 502, Err: errors.New("bad handshake")}, connectStageProxy},
+		{errors.New("connection refused"), connectStageTCP},
+	}
+	for _, tt := range tests {
+		if got := classifyWebSocketError(fmt.Errorf("failed to connect: %w", tt.err)); got.Stage != tt.want {
+			t.Errorf("classifyWebSocketError(%v) stage = %s, want %s", tt.err, got.Stage, tt.want)
+		}
+	}
+}
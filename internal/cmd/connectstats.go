@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/achronon/cvps/internal/terminal"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// latencyProbeInterval is how often the terminal connection is pinged
+var latencyProbeInterval = 2 * time.Second
+
+// sshProbeInterval is how often the SSH endpoint is dialled with --stats;
+// each probe is a new TCP connection, so it is kept infrequent
+var sshProbeInterval = 10 * time.Second
+
+// connectionQuality rates a network round trip
+func connectionQuality(rtt time.Duration) string {
+	switch {
+	case rtt < 100*time.Millisecond:
+		return "good"
+	case rtt < 300*time.Millisecond:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+func formatLatency(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+// latencyTitle is the terminal title shown by the Ctrl-] s status toggle
+func latencyTitle(name string, rtt, echo terminal.LatencySummary) string {
+	if rtt.Count == 0 {
+		return fmt.Sprintf("cvps: %s · measuring latency…", name)
+	}
+	title := fmt.Sprintf("cvps: %s · network %s (%s)", name, formatLatency(rtt.Last), connectionQuality(rtt.Last))
+	if echo.Count > 0 {
+		title += " · echo " + formatLatency(echo.Last)
+	}
+	return title
+}
+
+// watchLatency pings the terminal connection until ctx is done, calling
+// onSample after each successful ping
+func watchLatency(ctx context.Context, term *terminal.SocketIOTerminal, onSample func()) {
+	ticker := time.NewTicker(latencyProbeInterval)
+	defer ticker.Stop()
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := term.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			onSample()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSSHSessionWithStats runs an ssh session while timing TCP connections to
+// the SSH endpoint, and prints the results when it ends
+func runSSHSessionWithStats(ctx context.Context, sandbox *claudevps.Sandbox, clipboard string) error {
+	probeCtx, cancel := context.WithCancel(ctx)
+	var rtt terminal.Latency
+	go probeTCPLatency(probeCtx, net.JoinHostPort(sandbox.SSHHost, strconv.Itoa(sandbox.SSHPort)), &rtt)
+
+	start := time.Now()
+	err := runSSHSession(ctx, sandbox, clipboard)
+	cancel()
+	printConnectionStats(os.Stdout, rtt.Summary(), terminal.LatencySummary{}, time.Since(start))
+	return err
+}
+
+// probeTCPLatency times a TCP connect to addr every sshProbeInterval
+func probeTCPLatency(ctx context.Context, addr string, lat *terminal.Latency) {
+	ticker := time.NewTicker(sshProbeInterval)
+	defer ticker.Stop()
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	for {
+		start := time.Now()
+		if conn, err := dialer.DialContext(ctx, "tcp", addr); err == nil {
+			lat.Add(time.Since(start))
+			conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// printConnectionStats summarises the latency measured during a session and
+// says whether delay came from the network or the sandbox
+func printConnectionStats(w io.Writer, rtt, echo terminal.LatencySummary, elapsed time.Duration) {
+	fmt.Fprintf(w, "\nConnection stats (%s):\n", shortDuration(elapsed))
+	if rtt.Count == 0 {
+		fmt.Fprintln(w, "  No latency samples collected")
+		return
+	}
+	row := func(label string, s terminal.LatencySummary) {
+		fmt.Fprintf(w, "  %-20s min %s  avg %s  p95 %s  max %s  (%d samples)\n", label,
+			formatLatency(s.Min), formatLatency(s.Avg), formatLatency(s.P95), formatLatency(s.Max), s.Count)
+	}
+	row("network round trip", rtt)
+	if echo.Count > 0 {
+		row("input to echo", echo)
+	}
+
+	switch {
+	case echo.Count > 0 && echo.Avg > 2*rtt.Avg && echo.Avg-rtt.Avg > 100*time.Millisecond:
+		fmt.Fprintln(w, "  Most of the delay is in the sandbox: output takes much longer than the network round trip")
+	case connectionQuality(rtt.Avg) != "good":
+		fmt.Fprintf(w, "  Network latency is %s; a region closer to you may help\n", connectionQuality(rtt.Avg))
+	default:
+		fmt.Fprintln(w, "  Connection quality: good")
+	}
+}
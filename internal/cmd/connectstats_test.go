@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/terminal"
+)
+
+func TestPrintConnectionStats(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		rtt  terminal.LatencySummary
+		echo terminal.LatencySummary
+		want string
+	}{
+		{"no samples", terminal.LatencySummary{}, terminal.LatencySummary{}, "No latency samples"},
+		{"slow sandbox", terminal.LatencySummary{Count: 5, Avg: 30 * ms}, terminal.LatencySummary{Count: 5, Avg: 400 * ms}, "delay is in the sandbox"},
+		{"slow network", terminal.LatencySummary{Count: 5, Avg: 350 * ms}, terminal.LatencySummary{Count: 5, Avg: 380 * ms}, "Network latency is poor"},
+		{"good", terminal.LatencySummary{Count: 5, Avg: 30 * ms}, terminal.LatencySummary{Count: 5, Avg: 45 * ms}, "Connection quality: good"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printConnectionStats(&buf, tt.rtt, tt.echo, time.Minute)
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Expected %q in:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestLatencyTitle(t *testing.T) {
+	title := latencyTitle("web", terminal.LatencySummary{Count: 1, Last: 150 * time.Millisecond},
+		terminal.LatencySummary{Count: 1, Last: 210 * time.Millisecond})
+	if title != "cvps: web · network 150ms (fair) · echo 210ms" {
+		t.Errorf("latencyTitle() = %q", title)
+	}
+}
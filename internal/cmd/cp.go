@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cpRecursive bool
+	cpTransport string
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <source> <destination>",
+	Short: "Copy files to or from a sandbox",
+	Long: `Copy files between the local machine and a sandbox.
+
+Remote paths are written as [sandbox]:path, where sandbox is an ID or name.
+Leave the sandbox empty (":path") to use the current context sandbox.
+Relative remote paths are resolved against the sandbox user's home directory.
+
+Files are transferred over SFTP, with no external scp or rsync binary required.
+When SSH is blocked (for example by a corporate firewall), cp falls back to
+chunked HTTPS transfers through the API, which resume after interruptions.`,
+	Example: `  # Upload a file to the current sandbox
+  cvps cp ./config.json :/workspace/config.json
+
+  # Download a file from a named sandbox
+  cvps cp myproject:/var/log/app.log ./app.log
+
+  # Upload a directory recursively
+  cvps cp -r ./assets :/workspace/
+
+  # Force HTTPS transfer when SSH is blocked
+  cvps cp --transport api ./dump.sql :/workspace/`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runCp,
+	ValidArgsFunction: completeCpArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+
+	cpCmd.Flags().BoolVarP(&cpRecursive, "recursive", "r", false, "copy directories recursively")
+	cpCmd.Flags().StringVar(&cpTransport, "transport", transportAuto, "transfer method (auto|ssh|api)")
+}
+
+func runCp(cmd *cobra.Command, args []string) error {
+	src, srcRemote := parseRemotePath(args[0])
+	dst, dstRemote := parseRemotePath(args[1])
+
+	switch {
+	case srcRemote && dstRemote:
+		return fmt.Errorf("copying between two remote paths is not supported")
+	case !srcRemote && !dstRemote:
+		return fmt.Errorf("one of source or destination must be a remote path ([sandbox]:path)")
+	}
+	if err := validTransport(cpTransport); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	ref := dst.Sandbox
+	if srcRemote {
+		ref = src.Sandbox
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+
+	store, err := openRemoteStore(ctx, client, sandboxID, cpTransport)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var stats copyStats
+	if srcRemote {
+		remoteSrc, err := store.Resolve(src.Path)
+		if err != nil {
+			return err
+		}
+		if err := downloadPath(store, remoteSrc, args[1], cpRecursive, &stats); err != nil {
+			return err
+		}
+	} else {
+		remoteDst, err := store.Resolve(dst.Path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(dst.Path, "/") {
+			remoteDst += "/"
+		}
+		if err := uploadPath(store, args[0], remoteDst, cpRecursive, &stats); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Copied %d file(s), %s\n", stats.Files, formatBytes(stats.Bytes))
+	return nil
+}
+
+// copyStats accumulates transfer totals
+type copyStats struct {
+	Files int
+	Bytes int64
+}
+
+// copyTarget returns the final destination for src. Copying into an existing
+// directory, or a destination with a trailing slash, keeps the source name.
+func copyTarget(dst string, dstIsDir bool, srcBase string, join func(...string) string) string {
+	if dstIsDir || strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator)) {
+		return join(dst, srcBase)
+	}
+	return dst
+}
+
+func uploadPath(store remoteStore, localSrc, remoteDst string, recursive bool, stats *copyStats) error {
+	info, err := os.Stat(localSrc)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory (use -r to copy recursively)", localSrc)
+	}
+
+	dstIsDir := false
+	if dstInfo, err := store.Stat(strings.TrimSuffix(remoteDst, "/")); err == nil {
+		dstIsDir = dstInfo.IsDir()
+	}
+	target := copyTarget(remoteDst, dstIsDir, filepath.Base(localSrc), path.Join)
+
+	if !info.IsDir() {
+		if err := store.MkdirAll(path.Dir(target)); err != nil {
+			return err
+		}
+		return uploadFile(store, localSrc, target, info, stats)
+	}
+
+	return filepath.Walk(localSrc, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localSrc, p)
+		if err != nil {
+			return err
+		}
+		remote := path.Join(target, filepath.ToSlash(rel))
+
+		switch {
+		case fi.IsDir():
+			return store.MkdirAll(remote)
+		case fi.Mode().IsRegular():
+			return uploadFile(store, p, remote, fi, stats)
+		default:
+			// Symlinks and special files are skipped
+			return nil
+		}
+	})
+}
+
+func uploadFile(store remoteStore, localPath, remotePath string, info os.FileInfo, stats *copyStats) error {
+	n, err := store.Upload(localPath, remotePath, info)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", localPath, err)
+	}
+	stats.Files++
+	stats.Bytes += n
+	return nil
+}
+
+func downloadPath(store remoteStore, remoteSrc, localDst string, recursive bool, stats *copyStats) error {
+	info, err := store.Stat(remoteSrc)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory (use -r to copy recursively)", remoteSrc)
+	}
+
+	dstIsDir := false
+	if dstInfo, err := os.Stat(localDst); err == nil {
+		dstIsDir = dstInfo.IsDir()
+	}
+	target := copyTarget(localDst, dstIsDir, path.Base(remoteSrc), filepath.Join)
+
+	if !info.IsDir() {
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return downloadFile(store, remoteSrc, target, info, stats)
+	}
+
+	return downloadDir(store, remoteSrc, target, stats)
+}
+
+func downloadDir(store remoteStore, remoteDir, localDir string, stats *copyStats) error {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return err
+	}
+
+	entries, err := store.ReadDir(remoteDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		remote := path.Join(remoteDir, entry.Name())
+		local := filepath.Join(localDir, entry.Name())
+
+		switch {
+		case entry.IsDir():
+			if err := downloadDir(store, remote, local, stats); err != nil {
+				return err
+			}
+		case entry.Mode().IsRegular():
+			if err := downloadFile(store, remote, local, entry, stats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func downloadFile(store remoteStore, remotePath, localPath string, info os.FileInfo, stats *copyStats) error {
+	n, err := store.Download(remotePath, localPath, info)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+	if err := os.Chtimes(localPath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	stats.Files++
+	stats.Bytes += n
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"path"
+	"testing"
+)
+
+func TestParseRemotePath(t *testing.T) {
+	tests := []struct {
+		arg        string
+		wantRemote bool
+		wantBox    string
+		wantPath   string
+	}{
+		{arg: ":/workspace/file", wantRemote: true, wantBox: "", wantPath: "/workspace/file"},
+		{arg: "sbx-abc123:/tmp", wantRemote: true, wantBox: "sbx-abc123", wantPath: "/tmp"},
+		{arg: "myproject:notes.txt", wantRemote: true, wantBox: "myproject", wantPath: "notes.txt"},
+		{arg: "./local/file", wantRemote: false},
+		{arg: "./dir:with:colons", wantRemote: false},
+		{arg: `C:\Users\dev\file`, wantRemote: false},
+		{arg: "C:/Users/dev/file", wantRemote: false},
+		{arg: "plain.txt", wantRemote: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, remote := parseRemotePath(tt.arg)
+			if remote != tt.wantRemote {
+				t.Fatalf("parseRemotePath(%q) remote = %v, want %v", tt.arg, remote, tt.wantRemote)
+			}
+			if !remote {
+				return
+			}
+			if got.Sandbox != tt.wantBox || got.Path != tt.wantPath {
+				t.Fatalf("parseRemotePath(%q) = %+v, want sandbox %q path %q", tt.arg, got, tt.wantBox, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestCopyTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      string
+		dstIsDir bool
+		want     string
+	}{
+		{name: "explicit file", dst: "/workspace/out.txt", want: "/workspace/out.txt"},
+		{name: "existing directory", dst: "/workspace", dstIsDir: true, want: "/workspace/in.txt"},
+		{name: "trailing slash", dst: "/workspace/new/", want: "/workspace/new/in.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := copyTarget(tt.dst, tt.dstIsDir, "in.txt", path.Join); got != tt.want {
+				t.Fatalf("copyTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/daemon"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/internal/version"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonListen string
+	daemonJSON   bool
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a local control API for editors and the dashboard",
+	Long: `Run cvps as a long-running daemon with an HTTP API on localhost, for editor
+extensions, the 'cvps ui' dashboard and scripts. It keeps the sandbox list
+warm, watches sync sessions and reports those that need attention, manages
+port forwards and lends the CLI's credentials. Clients get all of that without
+starting cvps or logging in for every operation.
+
+The daemon runs in the foreground and logs to stderr; start it from your
+editor, a login item or a service manager. Its address and a bearer token are
+written to ~/.cvps/daemon.json, readable only by you. Every request must carry
+the token:
+
+  TOKEN=$(jq -r .token ~/.cvps/daemon.json)
+  ADDR=$(jq -r .addr ~/.cvps/daemon.json)
+  curl -H "Authorization: Bearer $TOKEN" http://$ADDR/v1/sandboxes
+
+Endpoints:
+  GET    /v1/status                 the daemon's version, uptime and cache state
+  GET    /v1/sandboxes              the cached sandbox list (?refresh=true to fetch)
+  GET    /v1/sandboxes/{sandbox}    one sandbox, fetched from the API
+  GET    /v1/sync                   sync sessions and any problem with them
+  POST   /v1/sync/{name}/flush      propagate pending changes now
+  DELETE /v1/sync/{name}            stop a sync session
+  GET    /v1/forwards               port forwards
+  POST   /v1/forwards               forward a port: {"sandbox":"web","local":3000}
+  DELETE /v1/forwards/{name}        stop a port forward
+  GET    /v1/credentials            the API URL and the credentials to call it with
+  POST   /v1/shutdown               stop the daemon`,
+	Example: `  # Run the daemon
+  cvps daemon
+
+  # Check on it, and stop it
+  cvps daemon status
+  cvps daemon stop`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is running",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStatus,
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStop,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+
+	daemonCmd.Flags().StringVar(&daemonListen, "listen", "127.0.0.1:0", "loopback address to listen on; port 0 picks a free port")
+	daemonStatusCmd.Flags().BoolVar(&daemonJSON, "json", false, "output in JSON format")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	srv, err := daemon.NewServer(daemonBackend{}, daemon.Options{
+		Addr:    daemonListen,
+		Version: version.Version,
+		Logf:    logger.Printf,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.Run(ctx)
+}
+
+func runDaemonStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	c, err := daemon.Connect(ctx)
+	if err != nil {
+		if daemonJSON && errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Println(`{"running": false}`)
+			return nil
+		}
+		return err
+	}
+	st, err := c.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if daemonJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Running bool   `json:"running"`
+			Addr    string `json:"addr"`
+			*daemon.Status
+		}{true, c.State().Addr, st})
+	}
+
+	fmt.Printf("✓ Daemon running (pid %d, version %s) on %s\n", st.PID, st.Version, c.State().Addr)
+	fmt.Printf("  Up:        %s\n", time.Since(st.StartedAt).Round(time.Second))
+	switch {
+	case st.SandboxesError != "":
+		fmt.Printf("  Sandboxes: %d, refresh failing: %s\n", st.Sandboxes, st.SandboxesError)
+	case !st.SandboxesAt.IsZero():
+		fmt.Printf("  Sandboxes: %d, fetched %s\n", st.Sandboxes, formatAge(time.Since(st.SandboxesAt)))
+	}
+	if st.MutagenDetected {
+		fmt.Printf("  Sync:      %d sessions\n", st.SyncSessions)
+	} else {
+		fmt.Println("  Sync:      mutagen is not installed")
+	}
+	return nil
+}
+
+func runDaemonStop(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	c, err := daemon.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.Shutdown(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Daemon stopped (pid %d)\n", c.State().PID)
+	return nil
+}
+
+// daemonBackend does the daemon's work with the same helpers as the
+// commands. It loads the config for every call, so a login or logout while
+// the daemon runs takes effect at once.
+type daemonBackend struct{}
+
+func (daemonBackend) ListSandboxes(ctx context.Context) ([]claudevps.Sandbox, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	cacheSandboxes(sandboxes)
+	return sandboxes, nil
+}
+
+func (daemonBackend) GetSandbox(ctx context.Context, ref string) (*claudevps.Sandbox, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	id, err := resolveSandbox(ctx, client, ref, false)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetSandbox(ctx, id)
+}
+
+func (daemonBackend) SyncSessions() ([]daemon.SyncSession, error) {
+	if !mutagen.IsInstalled() {
+		return nil, nil
+	}
+	names, err := mutagen.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]daemon.SyncSession, 0, len(names))
+	for _, name := range names {
+		ss := daemon.SyncSession{Name: name, SandboxID: strings.TrimPrefix(name, "cvps-")}
+		status, err := mutagen.GetSessionStatus(name)
+		if err != nil {
+			debuglog.Printf("daemon: %v", err)
+			ss.Status = "unknown"
+		} else {
+			ss.Status = status.Status
+			ss.LocalPath = status.LocalPath
+			ss.RemotePath = status.RemotePath
+			ss.Conflicts = status.Conflicts
+		}
+		sessions = append(sessions, ss)
+	}
+	return sessions, nil
+}
+
+// checkSessionName keeps the daemon to the sessions cvps created
+func checkSessionName(name string) error {
+	if !strings.HasPrefix(name, "cvps-") {
+		return fmt.Errorf("%s is not a cvps session", name)
+	}
+	return nil
+}
+
+func (daemonBackend) FlushSync(name string) error {
+	if err := checkSessionName(name); err != nil {
+		return err
+	}
+	return mutagen.FlushSession(name)
+}
+
+func (daemonBackend) TerminateSync(name string) error {
+	if err := checkSessionName(name); err != nil {
+		return err
+	}
+	return mutagen.TerminateSession(name)
+}
+
+func (daemonBackend) Forwards() ([]mutagen.ForwardStatus, error) {
+	if err := requireMutagen(); err != nil {
+		return nil, err
+	}
+	return mutagen.ListForwards("cvps-")
+}
+
+func (daemonBackend) CreateForward(ctx context.Context, req daemon.ForwardRequest) error {
+	if err := requireMutagen(); err != nil {
+		return err
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	id, err := resolveSandbox(ctx, client, req.Sandbox, false)
+	if err != nil {
+		return err
+	}
+	sandbox, err := client.GetSandbox(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	if !isRunningStatus(sandbox.Status) {
+		return fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
+	}
+	return createForwardSessions(sandbox, []portForward{{Local: req.Local, Remote: req.Remote}})
+}
+
+func (daemonBackend) TerminateForward(name string) error {
+	if err := checkSessionName(name); err != nil {
+		return err
+	}
+	if err := requireMutagen(); err != nil {
+		return err
+	}
+	return mutagen.TerminateForward(name)
+}
+
+func (daemonBackend) Credentials() (*daemon.Credentials, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.IsAuthenticated() {
+		return nil, fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+	creds := &daemon.Credentials{APIURL: cfg.APIBaseURL}
+	if cfg.AccessToken != "" {
+		creds.AccessToken = cfg.AccessToken
+	} else {
+		creds.APIKey = cfg.APIKey
+	}
+	return creds, nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/achronon/cvps/internal/daemon"
+)
+
+func TestRunDaemonStatus_NotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	daemonJSON = false
+	t.Cleanup(func() { daemonJSON = false })
+
+	if err := runDaemonStatus(daemonStatusCmd, nil); !errors.Is(err, daemon.ErrNotRunning) {
+		t.Errorf("runDaemonStatus() = %v, want ErrNotRunning", err)
+	}
+
+	daemonJSON = true
+	out, err := captureStdout(t, func() error { return runDaemonStatus(daemonStatusCmd, nil) })
+	if err != nil || out != "{\"running\": false}\n" {
+		t.Errorf("runDaemonStatus() with --json = %q, %v", out, err)
+	}
+}
+
+func TestCheckSessionName(t *testing.T) {
+	if err := checkSessionName("cvps-sbx-1"); err != nil {
+		t.Error(err)
+	}
+	if err := checkSessionName("other"); err == nil {
+		t.Error("expected a session cvps did not create to be refused")
+	}
+}
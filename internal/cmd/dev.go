@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	devName     string
+	devForwards []string
+	devNoSync   bool
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev [sandbox]",
+	Short: "Start a development session",
+	Long: `Get a project ready to work on in one step.
+
+If the project has no sandbox yet, one is created from the config defaults. A
+stopped sandbox is started. Once it is running, file sync is started, ports are
+forwarded to localhost and a terminal is opened. Forwards and sync are stopped
+when the terminal exits.
+
+Ports come from dev.forwards in the config and from --forward, either as a
+single port or as local:remote.`,
+	Example: `  # Work on the current project
+  cvps dev
+
+  # Also forward the app and its debugger
+  cvps dev --forward 3000 --forward 9229
+
+  # Reach the sandbox's port 80 on localhost:8080
+  cvps dev -L 8080:80
+
+  # Terminal and forwards only
+  cvps dev --no-sync`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDev,
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+
+	devCmd.Flags().StringVar(&devName, "name", "", "name for the sandbox if one is created (default: directory name)")
+	devCmd.Flags().StringSliceVarP(&devForwards, "forward", "L", nil, "forward a port (port or local:remote), in addition to dev.forwards")
+	devCmd.Flags().BoolVar(&devNoSync, "no-sync", false, "don't start file sync")
+	devCmd.ValidArgsFunction = completeSandboxes
+}
+
+func runDev(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	forwards, err := parsePortForwards(append(slices.Clone(cfg.Dev.Forwards), devForwards...))
+	if err != nil {
+		return err
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sandbox, err := devSandbox(ctx, cfg, client, args)
+	if err != nil {
+		return err
+	}
+
+	if !devNoSync {
+		if stopSync := startSyncSession(cfg, sandbox); stopSync != nil {
+			defer stopSync()
+		}
+	}
+
+	if len(forwards) > 0 {
+		closeForwards, err := startPortForwards(ctx, sandbox, forwards)
+		if err != nil {
+			color.Yellow("⚠ Ports not forwarded: %v", err)
+		} else {
+			defer closeForwards()
+		}
+	}
+
+	fmt.Println()
+	return openSandboxSession(ctx, cfg, client, sandbox, true)
+}
+
+// devSandbox returns the running sandbox to work in. A project without a
+// sandbox gets a new one; a stopped sandbox is started.
+func devSandbox(ctx context.Context, cfg *config.Config, client *claudevps.Client, args []string) (*claudevps.Sandbox, error) {
+	var id string
+	if len(args) > 0 {
+		ref, err := resolveSandboxRef(ctx, client, args[0])
+		if err != nil {
+			return nil, err
+		}
+		id = ref
+	} else {
+		c, err := localctx.Load(".")
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return createDevSandbox(ctx, cfg, client)
+		}
+		if id, err = getCurrentSandboxID(); err != nil {
+			return nil, err
+		}
+	}
+
+	sandbox, err := client.GetSandbox(ctx, id)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return nil, &sandboxNotFoundError{Ref: id}
+		}
+		return nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+
+	switch {
+	case isRunningStatus(sandbox.Status):
+		return sandbox, nil
+	case isFailedStatus(sandbox.Status):
+		if summary := failureSummary(sandbox); summary != "" {
+			return nil, fmt.Errorf("sandbox %s has failed: %s", sandbox.Name, summary)
+		}
+		return nil, fmt.Errorf("sandbox %s has failed (status: %s)", sandbox.Name, sandbox.Status)
+	case sandbox.Status == "stopped":
+		fmt.Printf("Starting sandbox '%s'...\n", sandbox.Name)
+		if _, err := client.StartSandbox(ctx, sandbox.ID); err != nil {
+			// Someone else started it first; wait for it like any other start
+			if !isConflict(err, claudevps.CodeAlreadyRunning, claudevps.CodeTransitioning) {
+				return nil, fmt.Errorf("failed to start sandbox: %w", err)
+			}
+		}
+	}
+	return waitForSandboxReady(ctx, client, sandbox.ID)
+}
+
+// createDevSandbox creates a sandbox for the working directory from the config
+// defaults. It is recorded in the project before waiting so an interrupted
+// dev doesn't create a second one next time.
+func createDevSandbox(ctx context.Context, cfg *config.Config, client *claudevps.Client) (*claudevps.Sandbox, error) {
+	req := &claudevps.CreateSandboxRequest{Name: devName}
+	if req.Name == "" {
+		req.Name = wizardDefaultName()
+	}
+	applyUpDefaults(req, cfg)
+
+	fmt.Printf("No sandbox for this project yet. Creating sandbox '%s'...\n", req.Name)
+	sandbox, err := client.CreateSandbox(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+	fmt.Printf("Sandbox created: %s\n", sandbox.ID)
+	saveLocalContext(sandbox.ID, sandbox.Name)
+
+	return waitForSandboxReady(ctx, client, sandbox.ID)
+}
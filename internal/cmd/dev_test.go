@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestParsePortForwards(t *testing.T) {
+	got, err := parsePortForwards([]string{"3000", "8080:80", " 3000 ", "9229:9229"})
+	if err != nil {
+		t.Fatalf("parsePortForwards() error = %v", err)
+	}
+	want := []portForward{{3000, 3000}, {8080, 80}, {9229, 9229}}
+	if len(got) != len(want) {
+		t.Fatalf("parsePortForwards() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("forward %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"", "web", "0", "70000", "3000:", ":80", "1:2:3"} {
+		if _, err := parsePortForwards([]string{bad}); err == nil {
+			t.Errorf("parsePortForwards(%q) expected error", bad)
+		}
+	}
+
+	if _, err := parsePortForwards([]string{"8080:80", "8080:81"}); err == nil {
+		t.Error("expected error when a local port is forwarded twice")
+	}
+}
+
+func TestPortForwardString(t *testing.T) {
+	if got := (portForward{3000, 3000}).String(); got != "3000" {
+		t.Errorf("String() = %q, want 3000", got)
+	}
+	if got := (portForward{8080, 80}).String(); got != "8080:80" {
+		t.Errorf("String() = %q, want 8080:80", got)
+	}
+}
+
+func TestDevSandbox_CreatesWhenNoContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(t.TempDir())
+
+	var created *claudevps.CreateSandboxRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			created = &claudevps.CreateSandboxRequest{}
+			json.NewDecoder(r.Body).Decode(created)
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-new", Name: created.Name, Status: "provisioning"})
+		case r.URL.Path == "/sandboxes/sbx-new/status":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-new", Name: "myproj", Status: "running", SSHHost: "h"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldName := devName
+	devName = "myproj"
+	defer func() { devName = oldName }()
+
+	sandbox, err := devSandbox(context.Background(), config.DefaultConfig(), claudevps.NewClient(server.URL, "test-key"), nil)
+	if err != nil {
+		t.Fatalf("devSandbox() error = %v", err)
+	}
+	if sandbox.ID != "sbx-new" || sandbox.Status != "running" {
+		t.Errorf("devSandbox() = %+v, want running sbx-new", sandbox)
+	}
+	if created == nil || created.Name != "myproj" || created.CPUCores != 1 {
+		t.Errorf("create request = %+v, want name myproj with config defaults", created)
+	}
+
+	id, err := getCurrentSandboxID()
+	if err != nil || id != "sbx-new" {
+		t.Errorf("getCurrentSandboxID() = %q, %v; want sbx-new recorded in the project", id, err)
+	}
+}
+
+func TestDevSandbox_StartsStopped(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(t.TempDir())
+
+	if err := saveLocalContext("sbx-1", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	started := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/sbx-1":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-1", Name: "web", Status: "stopped"})
+		case "/sandboxes/sbx-1/start":
+			started = true
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-1", Name: "web", Status: "starting"})
+		case "/sandboxes/sbx-1/status":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-1", Name: "web", Status: "running"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sandbox, err := devSandbox(context.Background(), config.DefaultConfig(), claudevps.NewClient(server.URL, "test-key"), nil)
+	if err != nil {
+		t.Fatalf("devSandbox() error = %v", err)
+	}
+	if !started {
+		t.Error("expected the stopped sandbox to be started")
+	}
+	if sandbox.Status != "running" {
+		t.Errorf("status = %q, want running", sandbox.Status)
+	}
+}
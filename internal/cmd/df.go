@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dfPath string
+	dfTop  int
+	dfJSON bool
+)
+
+var dfCmd = &cobra.Command{
+	Use:   "df [sandbox]",
+	Short: "Show sandbox disk usage",
+	Long: `Show total, used and free storage for a sandbox, along with the largest
+directories under the workspace.
+
+A full disk makes syncs and builds fail silently, so check here first when
+something stops updating.`,
+	Example: `  # Disk usage of the current sandbox
+  cvps df
+
+  # Top 20 directories under a different path
+  cvps df myproject --path /home/ubuntu --top 20`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDf,
+}
+
+func init() {
+	rootCmd.AddCommand(dfCmd)
+
+	dfCmd.Flags().StringVar(&dfPath, "path", "/workspace", "directory to break down")
+	dfCmd.Flags().IntVarP(&dfTop, "top", "n", 10, "number of largest directories to show (0 to skip)")
+	dfCmd.Flags().BoolVar(&dfJSON, "json", false, "output in JSON format")
+}
+
+// diskUsage is the storage summary for the filesystem holding Path
+type diskUsage struct {
+	Path       string     `json:"path"`
+	Filesystem string     `json:"filesystem"`
+	Mount      string     `json:"mount"`
+	Total      int64      `json:"totalBytes"`
+	Used       int64      `json:"usedBytes"`
+	Free       int64      `json:"freeBytes"`
+	Largest    []dirUsage `json:"largest,omitempty"`
+}
+
+// dirUsage is the size of a single directory
+type dirUsage struct {
+	Path string `json:"path"`
+	Size int64  `json:"sizeBytes"`
+}
+
+func runDf(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := conn.Output(ctx, "df -Pk "+remote.Quote(dfPath))
+	if err != nil {
+		return fmt.Errorf("failed to read disk usage: %w", err)
+	}
+	usage, err := parseDf(out)
+	if err != nil {
+		return err
+	}
+	usage.Path = dfPath
+
+	if dfTop > 0 {
+		// du exits non-zero on unreadable directories; keep whatever it reported
+		out, _ := conn.Output(ctx, "du -xk -d 1 "+remote.Quote(dfPath)+" 2>/dev/null")
+		usage.Largest = largestDirs(parseDu(out, dfPath), dfTop)
+	}
+
+	if dfJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(usage)
+	}
+
+	fmt.Printf("Filesystem: %s (mounted on %s)\n", usage.Filesystem, usage.Mount)
+	fmt.Printf("Total:      %s\n", formatBytes(usage.Total))
+	fmt.Printf("Used:       %s (%s)\n", formatBytes(usage.Used), usagePercent(usage.Used, usage.Total))
+	fmt.Printf("Free:       %s\n", formatBytes(usage.Free))
+	if usage.Total > 0 && usage.Used*10 >= usage.Total*9 {
+		color.Yellow("⚠ Disk is nearly full; grow it with 'cvps storage expand --add <GB>'")
+	}
+
+	if len(usage.Largest) > 0 {
+		fmt.Printf("\nLargest directories under %s:\n", dfPath)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, d := range usage.Largest {
+			fmt.Fprintf(w, "  %s\t%s\n", formatBytes(d.Size), d.Path)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+// parseDf parses POSIX "df -Pk" output for a single path
+func parseDf(out []byte) (*diskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected df output")
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("unexpected df output: %q", lines[len(lines)-1])
+	}
+
+	var kb [3]int64
+	for i := range kb {
+		v, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected df output: %q", lines[len(lines)-1])
+		}
+		kb[i] = v
+	}
+
+	return &diskUsage{
+		Filesystem: fields[0],
+		Mount:      strings.Join(fields[5:], " "),
+		Total:      kb[0] * 1024,
+		Used:       kb[1] * 1024,
+		Free:       kb[2] * 1024,
+	}, nil
+}
+
+// parseDu parses "du -k" output, skipping the entry for root itself
+func parseDu(out []byte, root string) []dirUsage {
+	root = strings.TrimRight(root, "/")
+	var dirs []dirUsage
+	for _, line := range strings.Split(string(out), "\n") {
+		size, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if strings.TrimRight(p, "/") == root {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil {
+			continue
+		}
+		dirs = append(dirs, dirUsage{Path: p, Size: kb * 1024})
+	}
+	return dirs
+}
+
+// largestDirs returns the n largest directories, biggest first
+func largestDirs(dirs []dirUsage, n int) []dirUsage {
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Size > dirs[j].Size })
+	if len(dirs) > n {
+		dirs = dirs[:n]
+	}
+	return dirs
+}
+
+func usagePercent(used, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(used)*100/float64(total))
+}
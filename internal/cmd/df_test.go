@@ -0,0 +1,53 @@
+package cmd
+
+import "testing"
+
+func TestParseDf(t *testing.T) {
+	out := []byte(`Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/vda1         20511312 15734040   4760888      77% /
+`)
+
+	usage, err := parseDf(out)
+	if err != nil {
+		t.Fatalf("parseDf() error = %v", err)
+	}
+	if usage.Filesystem != "/dev/vda1" || usage.Mount != "/" {
+		t.Fatalf("parseDf() filesystem = %q mount = %q", usage.Filesystem, usage.Mount)
+	}
+	if usage.Total != 20511312*1024 || usage.Used != 15734040*1024 || usage.Free != 4760888*1024 {
+		t.Fatalf("parseDf() sizes = %d/%d/%d", usage.Total, usage.Used, usage.Free)
+	}
+
+	if _, err := parseDf([]byte("garbage")); err == nil {
+		t.Fatal("parseDf() expected error for malformed output")
+	}
+}
+
+func TestParseDuAndLargest(t *testing.T) {
+	out := []byte("120\t/workspace/src\n4096\t/workspace/node_modules\n8\t/workspace/.git\n4224\t/workspace\n")
+
+	dirs := parseDu(out, "/workspace/")
+	if len(dirs) != 3 {
+		t.Fatalf("parseDu() returned %d entries, want 3 (root excluded)", len(dirs))
+	}
+
+	top := largestDirs(dirs, 2)
+	if len(top) != 2 {
+		t.Fatalf("largestDirs() returned %d entries, want 2", len(top))
+	}
+	if top[0].Path != "/workspace/node_modules" || top[0].Size != 4096*1024 {
+		t.Fatalf("largestDirs()[0] = %+v, want node_modules first", top[0])
+	}
+	if top[1].Path != "/workspace/src" {
+		t.Fatalf("largestDirs()[1] = %+v, want src second", top[1])
+	}
+}
+
+func TestUsagePercent(t *testing.T) {
+	if got := usagePercent(77, 100); got != "77%" {
+		t.Errorf("usagePercent(77, 100) = %q, want 77%%", got)
+	}
+	if got := usagePercent(1, 0); got != "-" {
+		t.Errorf("usagePercent(1, 0) = %q, want -", got)
+	}
+}
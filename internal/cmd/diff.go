@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffRemote  string
+	diffSandbox string
+	diffHash    bool
+	diffUnified bool
+	diffJSON    bool
+)
+
+// maxUnifiedDiffSize bounds the files shown with --unified
+const maxUnifiedDiffSize = 1024 * 1024
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [local-path]",
+	Short: "Compare a local directory with the sandbox workspace",
+	Long: `Compare a local directory with a directory in the sandbox and list files
+that were added locally, modified, or exist only in the sandbox.
+
+Files are compared by size and modification time. Use --hash to compare file
+contents instead, which is slower but ignores timestamp-only differences.
+Sync ignore patterns from the config apply to both sides.`,
+	Example: `  # Compare the current directory with /workspace
+  cvps diff
+
+  # Compare contents and show unified diffs of changed text files
+  cvps diff --hash -u
+
+  # Compare a subdirectory with a custom remote path
+  cvps diff ./api --remote /workspace/api`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffRemote, "remote", "/workspace", "remote directory to compare against")
+	diffCmd.Flags().StringVarP(&diffSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	diffCmd.Flags().BoolVar(&diffHash, "hash", false, "compare SHA-256 hashes instead of size and mtime")
+	diffCmd.Flags().BoolVarP(&diffUnified, "unified", "u", false, "show unified diffs for modified text files")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "output in JSON format")
+}
+
+// manifestEntry is the metadata compared for a single file
+type manifestEntry struct {
+	Size    int64
+	ModTime int64
+	Hash    string
+}
+
+// manifestDiff lists paths that differ, from the point of view of the local tree
+type manifestDiff struct {
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Deleted  []string `json:"deleted"`
+}
+
+func (d manifestDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Modified) == 0 && len(d.Deleted) == 0
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	localRoot := "."
+	if len(args) > 0 {
+		localRoot = args[0]
+	}
+	localRoot, err := filepath.Abs(localRoot)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, diffSandbox)
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	scanner := migration.NewScanner(localRoot, cfg.Sync.IgnorePatterns)
+	scan, err := scanner.Scan()
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", localRoot, err)
+	}
+	local := make(map[string]manifestEntry, len(scan.Files))
+	for _, f := range scan.Files {
+		local[filepath.ToSlash(f.RelPath)] = manifestEntry{Size: f.Size, ModTime: f.ModTime}
+	}
+
+	out, err := conn.Output(ctx, "cd "+remote.Quote(diffRemote)+" && find . -type f -printf '%s %T@ %P\\n'")
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", diffRemote, err)
+	}
+	remoteFiles := parseRemoteManifest(out)
+	for p := range remoteFiles {
+		if scanner.Excludes(p) {
+			delete(remoteFiles, p)
+		}
+	}
+
+	if diffHash {
+		if err := hashManifests(ctx, conn, localRoot, local, remoteFiles); err != nil {
+			return err
+		}
+	}
+
+	result := diffManifests(local, remoteFiles)
+
+	if diffJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	if result.empty() {
+		fmt.Printf("No differences between %s and %s\n", localRoot, diffRemote)
+		return nil
+	}
+
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed)
+	for _, p := range result.Added {
+		green.Printf("A  %s\n", p)
+	}
+	for _, p := range result.Modified {
+		yellow.Printf("M  %s\n", p)
+	}
+	for _, p := range result.Deleted {
+		red.Printf("D  %s\n", p)
+	}
+	fmt.Printf("\n%d added locally, %d modified, %d only in sandbox\n", len(result.Added), len(result.Modified), len(result.Deleted))
+
+	if diffUnified {
+		for _, p := range result.Modified {
+			if err := printUnifiedDiff(ctx, conn, localRoot, p); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", p, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseRemoteManifest parses "find -printf '%s %T@ %P\n'" output
+func parseRemoteManifest(out []byte) map[string]manifestEntry {
+	files := make(map[string]manifestEntry)
+	for _, line := range strings.Split(string(out), "\n") {
+		size, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		mtime, p, ok := strings.Cut(rest, " ")
+		if !ok || p == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			continue
+		}
+		t, err := strconv.ParseFloat(mtime, 64)
+		if err != nil {
+			continue
+		}
+		files[p] = manifestEntry{Size: n, ModTime: int64(t)}
+	}
+	return files
+}
+
+// diffManifests compares two manifests. Hashes are used when both sides have
+// one; otherwise size and whole-second modification times are compared.
+func diffManifests(local, remote map[string]manifestEntry) manifestDiff {
+	result := manifestDiff{Added: []string{}, Modified: []string{}, Deleted: []string{}}
+
+	for p, l := range local {
+		r, ok := remote[p]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, p)
+		case l.Hash != "" && r.Hash != "":
+			if l.Hash != r.Hash {
+				result.Modified = append(result.Modified, p)
+			}
+		case l.Size != r.Size || l.ModTime != r.ModTime:
+			result.Modified = append(result.Modified, p)
+		}
+	}
+	for p := range remote {
+		if _, ok := local[p]; !ok {
+			result.Deleted = append(result.Deleted, p)
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Modified)
+	sort.Strings(result.Deleted)
+	return result
+}
+
+// hashManifests fills in hashes for files present on both sides
+func hashManifests(ctx context.Context, conn *remote.Client, localRoot string, local, remoteFiles map[string]manifestEntry) error {
+	var common []string
+	for p := range local {
+		if _, ok := remoteFiles[p]; ok {
+			common = append(common, p)
+		}
+	}
+	if len(common) == 0 {
+		return nil
+	}
+
+	for _, p := range common {
+		sum, err := hashLocalFile(filepath.Join(localRoot, filepath.FromSlash(p)))
+		if err != nil {
+			return err
+		}
+		e := local[p]
+		e.Hash = sum
+		local[p] = e
+	}
+
+	// Paths are passed NUL-separated on stdin so names never reach the shell
+	var stdin bytes.Buffer
+	for _, p := range common {
+		stdin.WriteString(p)
+		stdin.WriteByte(0)
+	}
+	var stdout bytes.Buffer
+	cmd := "cd " + remote.Quote(diffRemote) + " && xargs -0 sha256sum --"
+	if err := conn.Run(ctx, cmd, &stdin, &stdout, nil); err != nil {
+		return fmt.Errorf("failed to hash remote files: %w", err)
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		sum, p, ok := strings.Cut(line, "  ")
+		if !ok {
+			continue
+		}
+		if e, ok := remoteFiles[p]; ok {
+			e.Hash = sum
+			remoteFiles[p] = e
+		}
+	}
+	return nil
+}
+
+func hashLocalFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func printUnifiedDiff(ctx context.Context, conn *remote.Client, localRoot, p string) error {
+	localData, err := os.ReadFile(filepath.Join(localRoot, filepath.FromSlash(p)))
+	if err != nil {
+		return err
+	}
+	if len(localData) > maxUnifiedDiffSize || !isText(localData) {
+		fmt.Printf("\nBinary or large file %s differs\n", p)
+		return nil
+	}
+
+	remoteData, err := conn.Output(ctx, "cat "+remote.Quote(path.Join(diffRemote, p)))
+	if err != nil {
+		return err
+	}
+	if len(remoteData) > maxUnifiedDiffSize || !isText(remoteData) {
+		fmt.Printf("\nBinary or large file %s differs\n", p)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Print(unifiedDiff(splitLines(string(remoteData)), splitLines(string(localData)), "sandbox/"+p, "local/"+p, 3))
+	return nil
+}
+
+// isText reports whether data looks like text (no NUL bytes in the first 8KB)
+func isText(data []byte) bool {
+	if len(data) > 8192 {
+		data = data[:8192]
+	}
+	return bytes.IndexByte(data, 0) < 0
+}
+
+// splitLines splits s into lines, keeping line terminators
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// maxSnakeSearch bounds the edit distance middleSnake searches up to, so files
+// with little in common get a coarser diff instead of taking seconds
+const maxSnakeSearch = 1000
+
+// editScript computes a line-level edit script from a to b with Myers'
+// algorithm, in space linear in the number of lines. It is minimal unless
+// the files have little in common.
+func editScript(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	return appendEdits(ops, a, b)
+}
+
+// appendEdits appends the edit script from a to b to ops, splitting the
+// problem at the middle of an optimal path until one side is empty
+func appendEdits(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+	default:
+		x, y := middleSnake(a, b)
+		ops = appendEdits(ops, a[:x], b[:y])
+		ops = appendEdits(ops, a[x:], b[y:])
+	}
+
+	for _, line := range common {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// middleSnake searches for an optimal path from both ends at once and
+// returns a point on it where the two searches meet. a and b must be
+// non-empty and differ in their first and last lines.
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[k] and backward[k] hold the furthest x reached on diagonal k-offset
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the paths meet while extending forwards
+	odd := delta%2 != 0
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d < min(maxD, maxSnakeSearch); d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return fx, offset + fx - j
+					}
+				}
+			}
+		}
+	}
+	// No line in common, or too many changes to keep looking: remove all of
+	// a, then add all of b
+	return n, 0
+}
+
+// unifiedDiff renders the differences between a and b in unified format
+func unifiedDiff(a, b []string, nameA, nameB string, context int) string {
+	ops := editScript(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+
+		lo := max(start-context, 0)
+		hi := min(end+context, len(ops))
+
+		aStart, bStart := 1, 1
+		for _, op := range ops[:lo] {
+			if op.kind != '+' {
+				aStart++
+			}
+			if op.kind != '-' {
+				bStart++
+			}
+		}
+		aLen, bLen := 0, 0
+		for _, op := range ops[lo:hi] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, op := range ops[lo:hi] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n")
+			}
+		}
+		start = hi
+	}
+
+	return out.String()
+}
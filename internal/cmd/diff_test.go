@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteManifest(t *testing.T) {
+	out := []byte("120 1705314600.1234567890 src/main.go\n0 1705314601.0000000000 with space.txt\nbad line\n")
+
+	files := parseRemoteManifest(out)
+	if len(files) != 2 {
+		t.Fatalf("parseRemoteManifest() returned %d files, want 2", len(files))
+	}
+	if e := files["src/main.go"]; e.Size != 120 || e.ModTime != 1705314600 {
+		t.Errorf("src/main.go = %+v", e)
+	}
+	if _, ok := files["with space.txt"]; !ok {
+		t.Error("expected file names with spaces to be preserved")
+	}
+}
+
+func TestDiffManifests(t *testing.T) {
+	local := map[string]manifestEntry{
+		"same.txt":    {Size: 10, ModTime: 100},
+		"changed.txt": {Size: 10, ModTime: 100},
+		"touched.txt": {Size: 5, ModTime: 100, Hash: "abc"},
+		"new.txt":     {Size: 1, ModTime: 100},
+	}
+	remote := map[string]manifestEntry{
+		"same.txt":    {Size: 10, ModTime: 100},
+		"changed.txt": {Size: 12, ModTime: 100},
+		"touched.txt": {Size: 5, ModTime: 200, Hash: "abc"},
+		"old.txt":     {Size: 1, ModTime: 100},
+	}
+
+	d := diffManifests(local, remote)
+	if strings.Join(d.Added, ",") != "new.txt" {
+		t.Errorf("Added = %v, want [new.txt]", d.Added)
+	}
+	if strings.Join(d.Modified, ",") != "changed.txt" {
+		t.Errorf("Modified = %v, want [changed.txt] (hash match overrides mtime)", d.Modified)
+	}
+	if strings.Join(d.Deleted, ",") != "old.txt" {
+		t.Errorf("Deleted = %v, want [old.txt]", d.Deleted)
+	}
+	if d.empty() {
+		t.Error("empty() = true, want false")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := splitLines("one\ntwo\nthree\nfour\n")
+	b := splitLines("one\n2\nthree\nfour\nfive\n")
+
+	got := unifiedDiff(a, b, "sandbox/f", "local/f", 1)
+	want := `--- sandbox/f
++++ local/f
+@@ -1,4 +1,5 @@
+ one
+-two
++2
+ three
+ four
++five
+`
+	if got != want {
+		t.Fatalf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a'+i)) + "\n"
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[1] = "changed\n"
+	b[18] = "changed\n"
+
+	got := unifiedDiff(a, b, "a", "b", 3)
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -16,5 +16,5 @@") {
+		t.Fatalf("unexpected hunk headers:\n%s", got)
+	}
+}
+
+func TestEditScriptMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	// lcsLen is the quadratic reference the edit script must match
+	lcsLen := func(a, b []string) int {
+		prev := make([]int, len(b)+1)
+		for i := range a {
+			cur := make([]int, len(b)+1)
+			for j := range b {
+				if a[i] == b[j] {
+					cur[j+1] = prev[j] + 1
+				} else {
+					cur[j+1] = max(prev[j+1], cur[j])
+				}
+			}
+			prev = cur
+		}
+		return prev[len(b)]
+	}
+
+	for n := 0; n < 500; n++ {
+		a, b := randomLines(), randomLines()
+		var gotA, gotB []string
+		kept := 0
+		for _, op := range editScript(a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				kept++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("editScript(%q, %q) does not rebuild both sides", a, b)
+		}
+		if want := lcsLen(a, b); kept != want {
+			t.Fatalf("editScript(%q, %q) kept %d lines, want %d", a, b, kept, want)
+		}
+	}
+}
+
+func TestEditScriptLargeFiles(t *testing.T) {
+	a := make([]string, 20000)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d\n", i)
+	}
+	b := slices.Clone(a)
+	b[100], b[15000] = "changed\n", "changed\n"
+
+	changes := 0
+	for _, op := range editScript(a, b) {
+		if op.kind != ' ' {
+			changes++
+		}
+	}
+	if changes != 4 {
+		t.Errorf("editScript() made %d changes, want 4", changes)
+	}
+}
+
+func TestIsText(t *testing.T) {
+	if !isText([]byte("hello\nworld\n")) {
+		t.Error("isText(text) = false")
+	}
+	if isText([]byte{0x7f, 'E', 'L', 'F', 0x00}) {
+		t.Error("isText(binary) = true")
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// checkRemoteFreeSpace fails if payload bytes will not fit in the free space
+// on the sandbox disk, so a transfer does not die half way with ENOSPC. With
+// force it only warns. The check is best effort: if the disk usage cannot be
+// read the transfer goes ahead.
+func checkRemoteFreeSpace(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, payload int64, force bool) error {
+	metrics, err := client.GetSandboxMetrics(ctx, sandbox.ID)
+	if err != nil {
+		debuglog.Printf("free space check skipped: %v", err)
+		return nil
+	}
+	if metrics.DiskTotalBytes <= 0 {
+		debuglog.Printf("free space check skipped: no disk size reported")
+		return nil
+	}
+
+	free := max(metrics.DiskTotalBytes-metrics.DiskUsedBytes, 0)
+	if payload <= free {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%s to transfer but only %s free on %s", formatBytes(payload), formatBytes(free), sandbox.Name)
+	if force {
+		color.Yellow("⚠ %s; continuing because of --force", msg)
+		return nil
+	}
+	return fmt.Errorf("%s. Free up space or grow the disk with 'cvps storage expand', or pass --force to try anyway", msg)
+}
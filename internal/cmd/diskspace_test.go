@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestCheckRemoteFreeSpace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-1/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.SandboxMetrics{DiskUsedBytes: 9 << 30, DiskTotalBytes: 10 << 30})
+	}))
+	defer server.Close()
+	client := claudevps.NewClient(server.URL, "test-key")
+	ctx := context.Background()
+	sandbox := &claudevps.Sandbox{ID: "sbx-1", Name: "dev"}
+
+	if err := checkRemoteFreeSpace(ctx, client, sandbox, 512<<20, false); err != nil {
+		t.Errorf("Expected 512 MB to fit in 1 GB free, got %v", err)
+	}
+
+	err := checkRemoteFreeSpace(ctx, client, sandbox, 2<<30, false)
+	if err == nil || !strings.Contains(err.Error(), "only 1.0 GB free on dev") || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected free space error, got %v", err)
+	}
+
+	if err := checkRemoteFreeSpace(ctx, client, sandbox, 2<<30, true); err != nil {
+		t.Errorf("Expected --force to proceed, got %v", err)
+	}
+
+	// Without metrics the transfer goes ahead
+	if err := checkRemoteFreeSpace(ctx, client, &claudevps.Sandbox{ID: "sbx-2"}, 2<<30, false); err != nil {
+		t.Errorf("Expected check to be skipped without metrics, got %v", err)
+	}
+}
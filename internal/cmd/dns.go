@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/hostsfile"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// dnsDomain is the domain sandboxes get hostnames under
+const dnsDomain = "cvps.local"
+
+// dnsAddress is where 'cvps forward' listens
+const dnsAddress = "127.0.0.1"
+
+var dnsYes bool
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Give forwarded sandboxes stable local hostnames",
+	Long: `Give each sandbox with port forwards a hostname under ` + dnsDomain + `, such as
+web.` + dnsDomain + `, so a project's services keep the same URLs, cookies and
+CORS settings from day to day, whichever sandbox is behind them.
+
+The names are written to the hosts file (/etc/hosts, or its Windows
+equivalent) between "` + hostsfile.BeginMarker + `" and "` + hostsfile.EndMarker + `" lines, and point at
+` + dnsAddress + ` where 'cvps forward' listens. A hostname does not carry a port;
+use it with the forwarded local port, as 'cvps dns list' shows. Editing the
+hosts file needs administrator rights, so cvps asks before changing it and
+runs sudo if it has to.`,
+}
+
+var dnsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Write hostnames for the forwarded sandboxes to the hosts file",
+	Long: `Update the cvps block of the hosts file to hold a hostname for every sandbox
+with forwards from 'cvps forward', removing names of sandboxes that no longer
+have any. Run it again after adding or stopping forwards.`,
+	Example: `  cvps forward create 3000 5173 --sandbox web
+  cvps dns sync
+  curl http://web.` + dnsDomain + `:3000`,
+	Args: cobra.NoArgs,
+	RunE: runDNSSync,
+}
+
+var dnsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the hostnames and URLs of forwarded sandboxes",
+	Args:  cobra.NoArgs,
+	RunE:  runDNSList,
+}
+
+var dnsCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove the cvps block from the hosts file",
+	Args:  cobra.NoArgs,
+	RunE:  runDNSClean,
+}
+
+func init() {
+	rootCmd.AddCommand(dnsCmd)
+	dnsCmd.AddCommand(dnsSyncCmd)
+	dnsCmd.AddCommand(dnsListCmd)
+	dnsCmd.AddCommand(dnsCleanCmd)
+
+	dnsSyncCmd.Flags().BoolVarP(&dnsYes, "yes", "y", false, "change the hosts file without asking")
+	dnsCleanCmd.Flags().BoolVarP(&dnsYes, "yes", "y", false, "change the hosts file without asking")
+}
+
+// dnsName is the hostname of a sandbox and the ports forwarded to it
+type dnsName struct {
+	Host    string
+	Sandbox string
+	Ports   []portForward
+}
+
+func runDNSSync(cmd *cobra.Command, args []string) error {
+	names, err := loadDNSNames(context.Background())
+	if err != nil {
+		return err
+	}
+	var entries []hostsfile.Entry
+	for _, n := range names {
+		entries = append(entries, hostsfile.Entry{Address: dnsAddress, Host: n.Host})
+	}
+	return updateHostsFile(hostsfile.Path(), entries)
+}
+
+func runDNSList(cmd *cobra.Command, args []string) error {
+	names, err := loadDNSNames(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No forwarded sandboxes. Forward ports with 'cvps forward create'.")
+		return nil
+	}
+
+	var written []hostsfile.Entry
+	if data, err := os.ReadFile(hostsfile.Path()); err == nil {
+		written = hostsfile.Managed(string(data))
+	}
+
+	missing := false
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tSANDBOX\tFORWARDS TO")
+	for _, n := range names {
+		mark := ""
+		if !slices.Contains(written, hostsfile.Entry{Address: dnsAddress, Host: n.Host}) {
+			mark, missing = " *", true
+		}
+		for _, p := range n.Ports {
+			fmt.Fprintf(w, "http://%s:%d%s\t%s\tsandbox:%d\n", n.Host, p.Local, mark, n.Sandbox, p.Remote)
+		}
+	}
+	w.Flush()
+
+	if missing {
+		color.Yellow("\n⚠ Names marked * are not in the hosts file yet. Run 'cvps dns sync' to add them.")
+	}
+	return nil
+}
+
+func runDNSClean(cmd *cobra.Command, args []string) error {
+	return updateHostsFile(hostsfile.Path(), nil)
+}
+
+// loadDNSNames returns the hostnames of the sandboxes with forwards
+func loadDNSNames(ctx context.Context) ([]dnsName, error) {
+	if err := requireMutagen(); err != nil {
+		return nil, err
+	}
+	forwards, err := mutagen.ListForwards("cvps-")
+	if err != nil {
+		return nil, err
+	}
+	if len(forwards) == 0 {
+		return nil, nil
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	return dnsNames(sandboxes, forwards), nil
+}
+
+// dnsNames groups forwards by sandbox, named <sandbox>.cvps.local. Forwards of
+// sandboxes that no longer exist are left out.
+func dnsNames(sandboxes []claudevps.Sandbox, forwards []mutagen.ForwardStatus) []dnsName {
+	var names []dnsName
+	byID := make(map[string]int)
+	for _, f := range forwards {
+		id := f.Labels[forwardSandboxLabel]
+		i, ok := byID[id]
+		if !ok {
+			si := slices.IndexFunc(sandboxes, func(s claudevps.Sandbox) bool { return s.ID == id })
+			if si < 0 {
+				debuglog.Printf("forward %s: sandbox %q not found", f.Name, id)
+				continue
+			}
+			label := hostLabel(sandboxes[si].Name)
+			if label == "" {
+				label = hostLabel(id)
+			}
+			host := label + "." + dnsDomain
+			if slices.ContainsFunc(names, func(n dnsName) bool { return n.Host == host }) {
+				debuglog.Printf("forward %s: %s is taken by another sandbox", f.Name, host)
+				continue
+			}
+			i = len(names)
+			byID[id] = i
+			names = append(names, dnsName{Host: host, Sandbox: sandboxes[si].Name})
+		}
+
+		local, lerr := endpointPort(f.Source)
+		remote, rerr := endpointPort(f.Destination)
+		if lerr != nil || rerr != nil {
+			continue
+		}
+		names[i].Ports = append(names[i].Ports, portForward{Local: local, Remote: remote})
+	}
+
+	for _, n := range names {
+		slices.SortFunc(n.Ports, func(a, b portForward) int { return a.Local - b.Local })
+	}
+	slices.SortFunc(names, func(a, b dnsName) int { return strings.Compare(a.Host, b.Host) })
+	return names
+}
+
+// hostLabel turns a sandbox name into a DNS label: lowercase letters, digits
+// and hyphens
+func hostLabel(name string) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	label = strings.Trim(label, "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// updateHostsFile sets the entries of the cvps block of the hosts file at
+// path, showing the changes and asking first unless --yes was given
+func updateHostsFile(path string, entries []hostsfile.Entry) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	content := string(data)
+
+	current := hostsfile.Managed(content)
+	var changes []string
+	for _, e := range current {
+		if !slices.Contains(entries, e) {
+			changes = append(changes, color.RedString("  - %s\t%s", e.Address, e.Host))
+		}
+	}
+	for _, e := range entries {
+		if !slices.Contains(current, e) {
+			changes = append(changes, color.GreenString("  + %s\t%s", e.Address, e.Host))
+		}
+	}
+	if len(changes) == 0 {
+		fmt.Printf("✓ %s is up to date\n", path)
+		return nil
+	}
+
+	fmt.Printf("Changes to %s:\n", path)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if !dnsYes {
+		fmt.Print("\nContinue? [y/N]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := writeHostsFile(path, hostsfile.Replace(content, entries)); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Updated %s\n", path)
+	return nil
+}
+
+// writeHostsFile replaces the hosts file, going through sudo when cvps may
+// not write it itself
+func writeHostsFile(path, content string) error {
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("no permission to write %s. Run cvps from a terminal opened as administrator", path)
+	}
+
+	fmt.Println("Writing the hosts file needs administrator rights; running sudo.")
+	cmd := exec.Command("sudo", "tee", path)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to write %s with sudo: %w", path, err)
+	}
+	return nil
+}
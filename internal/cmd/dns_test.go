@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/hostsfile"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestDNSNames(t *testing.T) {
+	sandboxes := []claudevps.Sandbox{
+		{ID: "sbx-1", Name: "Web_App"},
+		{ID: "sbx-2", Name: "api"},
+	}
+	forward := func(id, local, remote string) mutagen.ForwardStatus {
+		return mutagen.ForwardStatus{
+			Name:        forwardSessionPrefix(id) + local,
+			Source:      "tcp:localhost:" + local,
+			Destination: "root@ssh.example.com:tcp:localhost:" + remote,
+			Labels:      map[string]string{forwardSandboxLabel: id},
+		}
+	}
+	names := dnsNames(sandboxes, []mutagen.ForwardStatus{
+		forward("sbx-1", "5173", "5173"),
+		forward("sbx-1", "3000", "80"),
+		forward("sbx-2", "8080", "8080"),
+		forward("sbx-gone", "9000", "9000"),
+	})
+
+	if len(names) != 2 {
+		t.Fatalf("Expected 2 names, got %+v", names)
+	}
+	if names[0].Host != "api.cvps.local" || names[1].Host != "web-app.cvps.local" {
+		t.Errorf("Unexpected hosts: %s, %s", names[0].Host, names[1].Host)
+	}
+	if p := names[1].Ports; len(p) != 2 || p[0] != (portForward{Local: 3000, Remote: 80}) {
+		t.Errorf("Unexpected ports: %v", p)
+	}
+}
+
+func TestUpdateHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dnsYes = true
+	defer func() { dnsYes = false }()
+
+	entries := []hostsfile.Entry{{Address: dnsAddress, Host: "web.cvps.local"}}
+	out, err := captureStdout(t, func() error { return updateHostsFile(path, entries) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "+ 127.0.0.1\tweb.cvps.local") {
+		t.Errorf("Expected the added name to be shown, got %q", out)
+	}
+	data, _ := os.ReadFile(path)
+	if got := hostsfile.Managed(string(data)); len(got) != 1 || got[0] != entries[0] {
+		t.Errorf("Unexpected hosts file:\n%s", data)
+	}
+
+	out, _ = captureStdout(t, func() error { return updateHostsFile(path, entries) })
+	if !strings.Contains(out, "up to date") {
+		t.Errorf("Expected no changes, got %q", out)
+	}
+
+	if _, err := captureStdout(t, func() error { return updateHostsFile(path, nil) }); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "127.0.0.1\tlocalhost\n" {
+		t.Errorf("Expected clean to restore the file, got %q", data)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/internal/sftp"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// dotfilesDir is where dotfiles are placed in the sandbox, relative to home
+const dotfilesDir = ".dotfiles"
+
+// dotfilesMarker is written in dotfilesDir once the install has succeeded, so
+// a failed install is retried on the next up or connect
+const dotfilesMarker = ".cvps-installed"
+
+// dotfilesApplyScript runs the first install script found, or otherwise
+// symlinks top-level dotfiles into the home directory, keeping any existing
+// files as <name>.pre-dotfiles.
+const dotfilesApplyScript = `cd "$HOME/` + dotfilesDir + `" || exit 1
+for s in install.sh install bootstrap.sh bootstrap setup.sh setup script/bootstrap script/setup; do
+  if [ -f "$s" ]; then chmod +x "$s" && exec "./$s"; fi
+done
+for f in .[!.]*; do
+  [ -e "$f" ] || continue
+  [ "$f" = .git ] && continue
+  [ "$f" = ` + dotfilesMarker + ` ] && continue
+  if [ -e "$HOME/$f" ] && [ ! -L "$HOME/$f" ]; then mv "$HOME/$f" "$HOME/$f.pre-dotfiles"; fi
+  ln -sfn "$HOME/` + dotfilesDir + `/$f" "$HOME/$f"
+done`
+
+// isGitSource reports whether a dotfiles source is a git URL rather than a local directory
+func isGitSource(source string) bool {
+	return strings.Contains(source, "://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(source, ".git")
+}
+
+// expandHome replaces a leading ~ with the local home directory
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+// bootstrapDotfiles installs the configured dotfiles in a sandbox when they are
+// missing. Failures are reported as warnings so they never block up or connect.
+func bootstrapDotfiles(ctx context.Context, cfg *config.Config, sandbox *claudevps.Sandbox) {
+	if cfg.Dotfiles == "" {
+		return
+	}
+	if err := ensureDotfiles(ctx, cfg.Dotfiles, sandbox); err != nil {
+		color.Yellow("⚠ Dotfiles not applied: %v", err)
+	}
+}
+
+func ensureDotfiles(ctx context.Context, source string, sandbox *claudevps.Sandbox) error {
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Run(ctx, "test -f \"$HOME/"+dotfilesDir+"/"+dotfilesMarker+"\"", nil, nil, nil); err == nil {
+		return nil
+	}
+
+	fmt.Println("Applying dotfiles...")
+	// A clone left by a failed install is reused; uploads are simply redone
+	cloned := conn.Run(ctx, "test -d \"$HOME/"+dotfilesDir+"\"", nil, nil, nil) == nil
+	if isGitSource(source) {
+		if !cloned {
+			if _, err := conn.Output(ctx, "git clone --depth 1 "+remote.Quote(source)+" \"$HOME/"+dotfilesDir+"\""); err != nil {
+				return fmt.Errorf("failed to clone %s: %w", source, err)
+			}
+		}
+	} else if err := uploadDotfiles(conn, expandHome(source)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Output(ctx, dotfilesApplyScript); err != nil {
+		return fmt.Errorf("install script failed: %w", err)
+	}
+	if _, err := conn.Output(ctx, "touch \"$HOME/"+dotfilesDir+"/"+dotfilesMarker+"\""); err != nil {
+		return fmt.Errorf("failed to record the dotfiles install: %w", err)
+	}
+
+	color.Green("✓ Dotfiles applied")
+	return nil
+}
+
+func uploadDotfiles(conn *remote.Client, localDir string) error {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return fmt.Errorf("dotfiles directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("dotfiles source %s is not a directory", localDir)
+	}
+
+	sc, err := sftp.NewClient(conn.SSH())
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	home, err := sc.RealPath(".")
+	if err != nil {
+		return err
+	}
+
+	var stats copyStats
+	return uploadPath(sftpStore{sc}, localDir, home+"/"+dotfilesDir, true, &stats)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGitSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{source: "https://github.com/alice/dotfiles", want: true},
+		{source: "git@github.com:alice/dotfiles.git", want: true},
+		{source: "ssh://git@example.com/dotfiles", want: true},
+		{source: "~/dotfiles", want: false},
+		{source: "/home/alice/.config/dotfiles", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isGitSource(tt.source); got != tt.want {
+			t.Errorf("isGitSource(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestDotfilesApplyScript_SkipsMarker(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	home := t.TempDir()
+	dir := filepath.Join(home, dotfilesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".vimrc", dotfilesMarker} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("sh", "-c", dotfilesApplyScript)
+	cmd.Env = []string{"HOME=" + home, "PATH=" + os.Getenv("PATH")}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("apply script failed: %v\n%s", err, out)
+	}
+	if _, err := os.Lstat(filepath.Join(home, ".vimrc")); err != nil {
+		t.Errorf(".vimrc not linked: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(home, dotfilesMarker)); !os.IsNotExist(err) {
+		t.Errorf("the install marker should not be linked into home")
+	}
+}
@@ -8,16 +8,28 @@ import (
 	"strings"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
+	"github.com/achronon/cvps/internal/cache"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+// snapshotPollInterval is how often snapshot progress is checked
+var snapshotPollInterval = 2 * time.Second
+
 var (
 	downForce bool
 	downAll   bool
+	downGroup string
+
+	downSnapshot   bool
+	downNoSnapshot bool
+	downPurge      bool
+	downNoWait     bool
 )
 
 var downCmd = &cobra.Command{
@@ -28,19 +40,35 @@ var downCmd = &cobra.Command{
 Without arguments, terminates the current context sandbox
 (determined by .cvps.yaml in the current directory).
 
-Warning: This action is irreversible. All data in the sandbox will be lost.`,
+Deleted sandboxes are kept in the trash for a few days and can be brought
+back with 'cvps restore'. Use --purge to delete permanently; all data in the
+sandbox is then lost unless a final snapshot is taken with --snapshot (or by
+setting down.snapshot_before_delete in the config).`,
 	Example: `  # Terminate current sandbox
   cvps down
 
   # Terminate specific sandbox
   cvps down sbx-abc123
 
+  # Keep a final snapshot that can be restored with 'cvps up --from-snapshot'
+  cvps down --snapshot
+
+  # Delete permanently instead of moving to the trash
+  cvps down --purge
+
   # Force terminate without confirmation
   cvps down --force
 
+  # Terminate every sandbox in a group
+  cvps down --group workshop
+
+  # Request deletion without waiting for it, e.g. in CI teardown
+  cvps down sbx-abc123 --force --no-wait
+
   # Terminate all sandboxes
   cvps down --all`,
-	RunE: runDown,
+	ValidArgsFunction: completeSandboxes,
+	RunE:              runDown,
 }
 
 func init() {
@@ -48,9 +76,21 @@ func init() {
 
 	downCmd.Flags().BoolVarP(&downForce, "force", "f", false, "skip confirmation prompt")
 	downCmd.Flags().BoolVar(&downAll, "all", false, "terminate all sandboxes")
+	downCmd.Flags().StringVarP(&downGroup, "group", "g", "", "terminate every sandbox in a group")
+	downCmd.Flags().BoolVar(&downSnapshot, "snapshot", false, "take a final snapshot before deleting")
+	downCmd.Flags().BoolVar(&downPurge, "purge", false, "delete permanently instead of moving to the trash")
+	downCmd.Flags().BoolVar(&downNoSnapshot, "no-snapshot", false, "skip the final snapshot even if enabled in the config")
+	downCmd.Flags().BoolVar(&downNoWait, "no-wait", false, "return once deletion is requested; 'cvps status' confirms it later")
 }
 
 func runDown(cmd *cobra.Command, args []string) error {
+	if (downGroup != "" || downAll) && len(args) > 0 {
+		return fmt.Errorf("cannot combine a sandbox argument with --group or --all")
+	}
+	if downGroup != "" && downAll {
+		return fmt.Errorf("cannot combine --group with --all")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -60,46 +100,67 @@ func runDown(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'cvps login' first")
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
 	ctx := context.Background()
+	snapshot := !downNoSnapshot && (downSnapshot || cfg.Down.SnapshotBeforeDelete)
+
+	if downGroup != "" {
+		return terminateGroup(ctx, client, downGroup, snapshot)
+	}
 
 	// Terminate all sandboxes
 	if downAll {
-		return terminateAllSandboxes(ctx, client)
+		return terminateAllSandboxes(ctx, client, snapshot)
 	}
 
 	// Get sandbox ID from args or context
-	sandboxID := ""
+	sandboxID, contextName := "", ""
 	if len(args) > 0 {
-		sandboxID = args[0]
+		// Only exact names and prefixes here: a fuzzy guess should not delete anything
+		if sandboxID, err = resolveSandbox(ctx, client, args[0], false); err != nil {
+			return err
+		}
 	} else {
 		id, err := getCurrentSandboxID()
 		if err != nil {
 			return fmt.Errorf("no sandbox specified and no context found: %w", err)
 		}
 		sandboxID = id
+		if entry, err := loadLocalContext(); err == nil && entry != nil && entry.SandboxID == id {
+			contextName = entry.Name
+		}
 	}
 
-	return terminateSandbox(ctx, client, sandboxID)
+	return terminateSandbox(ctx, client, sandboxID, contextName, snapshot)
 }
 
-func terminateSandbox(ctx context.Context, client *api.Client, sandboxID string) error {
+// terminateSandbox deletes a sandbox after confirmation. contextName is the
+// name recorded for it in .cvps.yaml when the ID came from there.
+func terminateSandbox(ctx context.Context, client *claudevps.Client, sandboxID, contextName string, snapshot bool) error {
 	// Get sandbox info for confirmation
 	sandbox, err := client.GetSandbox(ctx, sandboxID)
 	if err != nil {
-		if api.IsNotFound(err) {
+		if claudevps.IsNotFound(err) {
 			fmt.Printf("Sandbox %s not found (may already be deleted)\n", sandboxID)
 			cleanupLocalContext(sandboxID)
 			return nil
 		}
 		return fmt.Errorf("failed to get sandbox: %w", err)
 	}
+	if err := checkContextName(sandbox, contextName); err != nil {
+		return err
+	}
 
 	// Confirm deletion
 	if !downForce {
 		warning := color.New(color.FgYellow, color.Bold)
-		warning.Printf("⚠ Warning: This will permanently delete sandbox '%s' (%s)\n", sandbox.Name, sandboxID)
-		fmt.Println("All data in the sandbox will be lost.")
+		if downPurge {
+			warning.Printf("⚠ Warning: This will permanently delete sandbox '%s' (%s)\n", sandbox.Name, sandboxID)
+			fmt.Println("All data in the sandbox will be lost.")
+		} else {
+			warning.Printf("⚠ Warning: This will delete sandbox '%s' (%s)\n", sandbox.Name, sandboxID)
+			fmt.Println("It will be kept in the trash and can be restored with 'cvps restore' until it is purged.")
+		}
 		fmt.Print("\nType the sandbox name to confirm: ")
 
 		reader := bufio.NewReader(os.Stdin)
@@ -111,11 +172,36 @@ func terminateSandbox(ctx context.Context, client *api.Client, sandboxID string)
 		}
 	}
 
+	if snapshot {
+		if _, err := takeFinalSnapshot(ctx, client, sandbox); err != nil {
+			return fmt.Errorf("final snapshot failed, sandbox was not deleted: %w", err)
+		}
+	}
+
 	// Delete sandbox
 	fmt.Printf("Terminating sandbox %s...\n", sandboxID)
 
-	if err := client.DeleteSandbox(ctx, sandboxID); err != nil {
-		return fmt.Errorf("failed to terminate sandbox: %w", err)
+	if err := deleteSandbox(ctx, client, sandboxID); err != nil {
+		if !deleteRaced(err) {
+			return fmt.Errorf("failed to terminate sandbox: %w", err)
+		}
+		fmt.Println("Sandbox is already being deleted; waiting for it to finish")
+	}
+
+	if downNoWait {
+		err := cache.AddPendingDeletion(cache.PendingDeletion{
+			SandboxID:   sandboxID,
+			Name:        sandbox.Name,
+			Purge:       downPurge,
+			RequestedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			debuglog.Printf("cache: %v", err)
+		}
+		fmt.Println("✓ Sandbox termination requested; 'cvps status' reports when it is done")
+		printTrashHint(sandboxID)
+		cleanupLocalContext(sandboxID)
+		return nil
 	}
 
 	// Wait for termination
@@ -125,33 +211,86 @@ func terminateSandbox(ctx context.Context, client *api.Client, sandboxID string)
 
 	timeout := 2 * time.Minute
 	deadline := time.Now().Add(timeout)
-
-	for time.Now().Before(deadline) {
-		_, err := client.GetSandbox(ctx, sandboxID)
-		if err != nil {
-			if api.IsNotFound(err) {
-				s.Stop()
-				fmt.Println("✓ Sandbox terminated successfully")
-				cleanupLocalContext(sandboxID)
-				return nil
-			}
+	poller := newSandboxPoller(client, sandboxID, deadline, func(e *claudevps.SandboxEvent) bool {
+		return e.Type == claudevps.SandboxEventDeleted
+	})
+	defer poller.stop()
+
+	terminated := false
+	for !terminated && time.Now().Before(deadline) {
+		current, err := client.GetSandbox(ctx, sandboxID)
+		if (err != nil && claudevps.IsNotFound(err)) || (err == nil && current.DeletedAt != "") {
+			terminated = true
+		} else {
+			terminated = poller.wait(ctx, err) != nil
 		}
-		time.Sleep(2 * time.Second)
+	}
+	if terminated {
+		s.Stop()
+		fmt.Println("✓ Sandbox terminated successfully")
+		printTrashHint(sandboxID)
+		cleanupLocalContext(sandboxID)
+		return nil
 	}
 
 	s.Stop()
 	fmt.Println("✓ Sandbox termination initiated (may take a few more seconds)")
+	printTrashHint(sandboxID)
 	cleanupLocalContext(sandboxID)
 	return nil
 }
 
-func terminateAllSandboxes(ctx context.Context, client *api.Client) error {
-	list, err := client.ListSandboxes(ctx, 1, 100)
+// checkContextName guards against a stale context file: the sandbox its ID
+// points at must still carry the name recorded with it, or the ID may have
+// been reused or the file may predate a restore. A mismatch aborts under
+// --force and is called out before the confirmation prompt otherwise.
+func checkContextName(sandbox *claudevps.Sandbox, recorded string) error {
+	if recorded == "" || sandbox.Name == recorded {
+		return nil
+	}
+	if downForce {
+		return fmt.Errorf("%s records sandbox %s as '%s' but it is now named '%s'; not deleting it with --force. Check it with 'cvps status %s' and pass the ID explicitly if it is the right one",
+			localctx.FileName, sandbox.ID, recorded, sandbox.Name, sandbox.ID)
+	}
+	color.New(color.FgYellow, color.Bold).Printf("⚠ %s records sandbox %s as '%s', but it is now named '%s'\n", localctx.FileName, sandbox.ID, recorded, sandbox.Name)
+	fmt.Println("The project context may be stale; make sure this is the sandbox you mean.")
+	return nil
+}
+
+// deleteSandbox moves a sandbox to the trash, or purges it with --purge
+func deleteSandbox(ctx context.Context, client *claudevps.Client, sandboxID string) error {
+	if downPurge {
+		return client.PurgeSandbox(ctx, sandboxID)
+	}
+	return client.DeleteSandbox(ctx, sandboxID)
+}
+
+// deleteRaced reports whether a delete failed only because the sandbox is
+// already being deleted or gone, which counts as success
+func deleteRaced(err error) bool {
+	return claudevps.IsNotFound(err) || isConflict(err, claudevps.CodeAlreadyDeleting)
+}
+
+func deleteVerb() string {
+	if downPurge {
+		return "permanently delete"
+	}
+	return "delete"
+}
+
+func printTrashHint(sandboxID string) {
+	if !downPurge {
+		fmt.Printf("  Restore it with: cvps restore %s\n", sandboxID)
+	}
+}
+
+func terminateAllSandboxes(ctx context.Context, client *claudevps.Client, snapshot bool) error {
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
 	if err != nil {
 		return fmt.Errorf("failed to list sandboxes: %w", err)
 	}
 
-	if len(list.Data) == 0 {
+	if len(sandboxes) == 0 {
 		fmt.Println("No sandboxes to terminate.")
 		return nil
 	}
@@ -159,9 +298,9 @@ func terminateAllSandboxes(ctx context.Context, client *api.Client) error {
 	// Confirm
 	if !downForce {
 		warning := color.New(color.FgRed, color.Bold)
-		warning.Printf("⚠ DANGER: This will permanently delete ALL %d sandboxes!\n\n", len(list.Data))
+		warning.Printf("⚠ DANGER: This will %s ALL %d sandboxes!\n\n", deleteVerb(), len(sandboxes))
 
-		for _, s := range list.Data {
+		for _, s := range sandboxes {
 			fmt.Printf("  - %s (%s)\n", s.Name, s.ID)
 		}
 
@@ -178,29 +317,134 @@ func terminateAllSandboxes(ctx context.Context, client *api.Client) error {
 
 	// Delete all
 	fmt.Println()
-	for _, s := range list.Data {
-		fmt.Printf("Terminating %s (%s)... ", s.Name, s.ID)
-		if err := client.DeleteSandbox(ctx, s.ID); err != nil {
-			fmt.Printf("failed: %s\n", err)
-		} else {
-			fmt.Println("done")
+	gone, err := terminateMany(ctx, client, sandboxes, snapshot)
+	if err != nil {
+		return err
+	}
+	for _, id := range gone {
+		cleanupLocalContext(id)
+	}
+
+	fmt.Printf("\n✓ Terminated %d sandboxes\n", len(gone))
+	return nil
+}
+
+func terminateGroup(ctx context.Context, client *claudevps.Client, name string, snapshot bool) error {
+	sandboxes, err := groupSandboxes(ctx, client, name)
+	if err != nil {
+		return err
+	}
+
+	if len(sandboxes) == 0 {
+		fmt.Println("No sandboxes to terminate.")
+		return nil
+	}
+
+	if !downForce {
+		warning := color.New(color.FgRed, color.Bold)
+		warning.Printf("⚠ DANGER: This will %s %d sandboxes in group '%s'!\n\n", deleteVerb(), len(sandboxes), name)
+
+		for _, s := range sandboxes {
+			fmt.Printf("  - %s (%s)\n", s.Name, s.ID)
+		}
+
+		fmt.Print("\nType the group name to confirm: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		if input != name {
+			return fmt.Errorf("confirmation failed: expected '%s', got '%s'", name, input)
 		}
 	}
 
-	// Cleanup local context
-	os.Remove(".cvps.yaml")
+	fmt.Println()
+	gone, err := terminateMany(ctx, client, sandboxes, snapshot)
+	if err != nil {
+		return err
+	}
+	for _, id := range gone {
+		cleanupLocalContext(id)
+	}
 
-	fmt.Printf("\n✓ Terminated %d sandboxes\n", len(list.Data))
+	fmt.Printf("\n✓ Terminated %d sandboxes\n", len(gone))
 	return nil
 }
 
-func cleanupLocalContext(sandboxID string) {
-	localCtx, err := loadLocalContext()
-	if err != nil || localCtx == nil {
-		return
+// terminateMany takes final snapshots if asked, deletes the sandboxes in
+// batches and reports on each. It returns the IDs of the sandboxes now gone,
+// or the error of a dry run that stopped before changing anything.
+func terminateMany(ctx context.Context, client *claudevps.Client, sandboxes []claudevps.Sandbox, snapshot bool) ([]string, error) {
+	var targets []claudevps.Sandbox
+	for _, s := range sandboxes {
+		if snapshot {
+			if _, err := takeFinalSnapshot(ctx, client, &s); err != nil {
+				if claudevps.IsReadOnly(err) {
+					return nil, err
+				}
+				fmt.Printf("Skipping %s (%s): final snapshot failed: %s\n", s.Name, s.ID, err)
+				continue
+			}
+		}
+		targets = append(targets, s)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(targets))
+	for i, s := range targets {
+		ids[i] = s.ID
+	}
+	fmt.Printf("Terminating %d sandboxes...\n", len(targets))
+	errs := deleteSandboxes(ctx, client, ids)
+	for _, err := range errs {
+		if claudevps.IsReadOnly(err) {
+			return nil, err
+		}
+	}
+
+	var gone []string
+	for i, s := range targets {
+		fmt.Printf("  %s (%s): ", s.Name, s.ID)
+		switch err := errs[i]; {
+		case err == nil:
+			fmt.Println("done")
+			gone = append(gone, s.ID)
+		case deleteRaced(err):
+			fmt.Println("already being deleted")
+			gone = append(gone, s.ID)
+		default:
+			fmt.Printf("failed: %s\n", err)
+		}
 	}
+	return gone, nil
+}
 
-	if localCtx.SandboxID == sandboxID {
-		os.Remove(".cvps.yaml")
+// takeFinalSnapshot snapshots a sandbox and waits until the snapshot is usable
+func takeFinalSnapshot(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox) (*claudevps.Snapshot, error) {
+	snap, err := client.CreateSnapshot(ctx, sandbox.ID, &claudevps.CreateSnapshotRequest{
+		Name: fmt.Sprintf("%s-final-%s", sandbox.Name, time.Now().Format("20060102-150405")),
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	snap, err = waitForSnapshot(ctx, client, snap, "snapshot", fmt.Sprintf(" Taking final snapshot of %s...", sandbox.Name), 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✓ Final snapshot %s saved. Restore with: cvps up --from-snapshot %s\n", snap.ID, snap.ID)
+	return snap, nil
+}
+
+// cleanupLocalContext drops sandboxID from the project context. The file is
+// removed once no sandboxes are left.
+func cleanupLocalContext(sandboxID string) {
+	updateLocalContext(func(c *localctx.Context) error {
+		c.Drop(sandboxID)
+		return nil
+	})
 }
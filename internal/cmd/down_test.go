@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
-	"github.com/achronon/cvps/internal/api"
+	"github.com/achronon/cvps/internal/cache"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
 )
 
 func TestRunDown_NotAuthenticated(t *testing.T) {
@@ -74,7 +76,7 @@ func TestRunDown_SandboxNotFound(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/sandboxes/sbx-notfound" {
 			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(api.APIError{
+			json.NewEncoder(w).Encode(claudevps.APIError{
 				StatusCode: 404,
 				Message:    "Sandbox not found",
 			})
@@ -117,13 +119,13 @@ func TestRunDown_WithForceFlag(t *testing.T) {
 				if deleted {
 					// After deletion, return 404
 					w.WriteHeader(http.StatusNotFound)
-					json.NewEncoder(w).Encode(api.APIError{
+					json.NewEncoder(w).Encode(claudevps.APIError{
 						StatusCode: 404,
 						Message:    "Sandbox not found",
 					})
 				} else {
 					// First call - return sandbox
-					resp := api.Sandbox{
+					resp := claudevps.Sandbox{
 						ID:     "sbx-force",
 						Name:   "force-test",
 						Status: "running",
@@ -181,12 +183,12 @@ func TestRunDown_FromContext(t *testing.T) {
 				if deleted {
 					// After deletion, return 404
 					w.WriteHeader(http.StatusNotFound)
-					json.NewEncoder(w).Encode(api.APIError{
+					json.NewEncoder(w).Encode(claudevps.APIError{
 						StatusCode: 404,
 						Message:    "Sandbox not found",
 					})
 				} else {
-					resp := api.Sandbox{
+					resp := claudevps.Sandbox{
 						ID:     "sbx-ctx-123",
 						Name:   "context-sandbox",
 						Status: "running",
@@ -226,6 +228,39 @@ func TestRunDown_FromContext(t *testing.T) {
 	}
 }
 
+func TestRunDown_ContextNameMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	deleteCalled := false
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleteCalled = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		// The ID now belongs to a different sandbox than the one recorded
+		json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-ctx-123", Name: "someone-else", Status: "running"})
+	}))
+	saveLocalContext("sbx-ctx-123", "context-sandbox")
+
+	downForce, downAll = true, false
+	defer func() { downForce = false }()
+
+	err := runDown(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "now named 'someone-else'") {
+		t.Fatalf("Expected name mismatch error, got %v", err)
+	}
+	if deleteCalled {
+		t.Error("Expected no DELETE for a stale context")
+	}
+	if _, err := os.Stat(".cvps.yaml"); err != nil {
+		t.Error("Expected .cvps.yaml to be kept")
+	}
+}
+
 func TestRunDown_AllSandboxes(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldConfigDir := os.Getenv("HOME")
@@ -241,8 +276,8 @@ func TestRunDown_AllSandboxes(t *testing.T) {
 		switch r.URL.Path {
 		case "/sandboxes":
 			if r.Method == "GET" {
-				resp := api.SandboxList{
-					Data: []api.Sandbox{
+				resp := claudevps.SandboxList{
+					Data: []claudevps.Sandbox{
 						{ID: "sbx-1", Name: "sandbox-1", Status: "running"},
 						{ID: "sbx-2", Name: "sandbox-2", Status: "running"},
 						{ID: "sbx-3", Name: "sandbox-3", Status: "running"},
@@ -253,6 +288,9 @@ func TestRunDown_AllSandboxes(t *testing.T) {
 				}
 				json.NewEncoder(w).Encode(resp)
 			}
+		case "/sandboxes/batch":
+			// An API without batch support
+			w.WriteHeader(http.StatusNotFound)
 		case "/sandboxes/sbx-1", "/sandboxes/sbx-2", "/sandboxes/sbx-3":
 			if r.Method == "DELETE" {
 				deleteCount++
@@ -280,6 +318,92 @@ func TestRunDown_AllSandboxes(t *testing.T) {
 	}
 }
 
+func TestRunDown_AllSandboxes_Batch(t *testing.T) {
+	batches := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes":
+			json.NewEncoder(w).Encode(claudevps.SandboxList{
+				Data: []claudevps.Sandbox{
+					{ID: "sbx-1", Name: "sandbox-1", Status: "running"},
+					{ID: "sbx-2", Name: "sandbox-2", Status: "running"},
+				},
+				Total: 2,
+			})
+		case "/sandboxes/batch":
+			batches++
+			var req struct {
+				Op  string   `json:"op"`
+				IDs []string `json:"ids"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Op != claudevps.BatchDelete || len(req.IDs) != 2 {
+				t.Errorf("Unexpected batch request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"results": []claudevps.BatchResult{
+				{ID: "sbx-1", Status: http.StatusNoContent},
+				{ID: "sbx-2", Status: http.StatusInternalServerError, Error: &claudevps.APIError{Message: "host unreachable"}},
+			}})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+	saveLocalContext("sbx-1", "sandbox-1")
+	saveLocalContext("sbx-2", "sandbox-2")
+
+	downForce, downAll = true, true
+	defer func() { downForce, downAll = false, false }()
+
+	out, err := captureStdout(t, func() error { return runDown(nil, nil) })
+	if err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+	if batches != 1 {
+		t.Errorf("Expected one batch request, got %d", batches)
+	}
+	if !strings.Contains(out, "sandbox-2 (sbx-2): failed: host unreachable") || !strings.Contains(out, "Terminated 1 sandboxes") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+	// The sandbox that failed to delete stays in the project context
+	if ctx, _ := loadLocalContext(); ctx == nil || ctx.SandboxID != "sbx-2" {
+		t.Errorf("Expected sbx-2 to remain in the local context, got %+v", ctx)
+	}
+}
+
+func TestRunDown_AllSandboxes_DryRun(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/sandboxes" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		json.NewEncoder(w).Encode(claudevps.SandboxList{
+			Data:  []claudevps.Sandbox{{ID: "sbx-1", Name: "sandbox-1", Status: "running"}},
+			Total: 1,
+		})
+	}))
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+	saveLocalContext("sbx-1", "sandbox-1")
+
+	dryRun, downForce, downAll = true, true, true
+	defer func() { dryRun, downForce, downAll = false, false, false }()
+
+	out, err := captureStdout(t, func() error { return runDown(nil, nil) })
+	if !claudevps.IsReadOnly(err) {
+		t.Fatalf("runDown() error = %v, want the delete refused", err)
+	}
+	if strings.Contains(out, "Terminated") {
+		t.Errorf("Dry run should not report terminations:\n%s", out)
+	}
+	if ctx, _ := loadLocalContext(); ctx == nil || ctx.SandboxID != "sbx-1" {
+		t.Errorf("Dry run changed the local context: %+v", ctx)
+	}
+}
+
 func TestRunDown_AllSandboxes_Empty(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldConfigDir := os.Getenv("HOME")
@@ -288,8 +412,8 @@ func TestRunDown_AllSandboxes_Empty(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/sandboxes" && r.Method == "GET" {
-			resp := api.SandboxList{
-				Data:  []api.Sandbox{},
+			resp := claudevps.SandboxList{
+				Data:  []claudevps.Sandbox{},
 				Total: 0,
 				Page:  1,
 				Limit: 100,
@@ -315,6 +439,9 @@ func TestRunDown_AllSandboxes_Empty(t *testing.T) {
 
 func TestCleanupLocalContext(t *testing.T) {
 	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
 	oldWd, _ := os.Getwd()
 	os.Chdir(tmpDir)
 	defer os.Chdir(oldWd)
@@ -338,6 +465,9 @@ func TestCleanupLocalContext(t *testing.T) {
 
 func TestCleanupLocalContext_DifferentSandbox(t *testing.T) {
 	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
 	oldWd, _ := os.Getwd()
 	os.Chdir(tmpDir)
 	defer os.Chdir(oldWd)
@@ -353,3 +483,207 @@ func TestCleanupLocalContext_DifferentSandbox(t *testing.T) {
 		t.Error("Expected .cvps.yaml to still exist")
 	}
 }
+
+func TestRunDown_SnapshotFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldConfigDir := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldConfigDir)
+
+	oldInterval := snapshotPollInterval
+	snapshotPollInterval = 0
+	defer func() { snapshotPollInterval = oldInterval }()
+
+	var calls []string
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-snap":
+			if deleted {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(claudevps.APIError{StatusCode: 404, Message: "Sandbox not found"})
+				return
+			}
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-snap", Name: "snap-test", Status: "running"})
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-snap/snapshots":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusPending})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusReady})
+		case r.Method == "DELETE":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.APIBaseURL = server.URL
+	cfg.Down.SnapshotBeforeDelete = true
+	config.Save(cfg)
+
+	downForce = true
+	downAll = false
+
+	if err := runDown(nil, []string{"sbx-snap"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	snapshotAt, deleteAt := -1, -1
+	for i, c := range calls {
+		switch c {
+		case "POST /sandboxes/sbx-snap/snapshots":
+			snapshotAt = i
+		case "DELETE /sandboxes/sbx-snap":
+			deleteAt = i
+		}
+	}
+	if snapshotAt < 0 || deleteAt < 0 || snapshotAt > deleteAt {
+		t.Errorf("Expected snapshot before delete, got calls %v", calls)
+	}
+}
+
+func TestRunDown_SnapshotFailureKeepsSandbox(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldConfigDir := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldConfigDir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-snap", Name: "snap-test", Status: "running"})
+		case r.Method == "POST":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusFailed})
+		case r.Method == "DELETE":
+			t.Error("Sandbox should not be deleted when the snapshot fails")
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.APIBaseURL = server.URL
+	config.Save(cfg)
+
+	downForce = true
+	downAll = false
+	downSnapshot = true
+	defer func() { downSnapshot = false }()
+
+	if err := runDown(nil, []string{"sbx-snap"}); err == nil {
+		t.Fatal("Expected error when final snapshot fails")
+	}
+}
+
+func TestRunDown_AlreadyBeingDeleted(t *testing.T) {
+	deleted := false
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			sandbox := claudevps.Sandbox{ID: "sbx-race", Name: "race", Status: "running"}
+			if deleted {
+				sandbox.DeletedAt = "2024-01-15T10:00:00Z"
+			}
+			json.NewEncoder(w).Encode(sandbox)
+		case "DELETE":
+			// Another client got there first
+			deleted = true
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(claudevps.APIError{Code: claudevps.CodeAlreadyDeleting, Message: "Sandbox is already being deleted"})
+		}
+	}))
+
+	downForce = true
+	defer func() { downForce = false }()
+
+	out, err := captureStdout(t, func() error { return runDown(nil, []string{"sbx-race"}) })
+	if err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+	if !strings.Contains(out, "already being deleted") || !strings.Contains(out, "terminated successfully") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+}
+
+func TestRunDown_NoWait(t *testing.T) {
+	gets := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" {
+			gets++
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-ci", Name: "ci", Status: "running"})
+		}
+	}))
+
+	downForce, downNoWait = true, true
+	defer func() { downForce, downNoWait = false, false }()
+
+	out, err := captureStdout(t, func() error { return runDown(nil, []string{"sbx-ci"}) })
+	if err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+	if gets != 1 {
+		t.Errorf("Expected no polling after the delete request, got %d GETs", gets)
+	}
+	if !strings.Contains(out, "termination requested") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	pending, _ := cache.LoadPendingDeletions()
+	if len(pending) != 1 || pending[0].SandboxID != "sbx-ci" || pending[0].Name != "ci" {
+		t.Errorf("Unexpected pending deletions: %+v", pending)
+	}
+}
+
+func TestRunDown_DryRun(t *testing.T) {
+	var methods []string
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-dry", Name: "dry", Status: "running"})
+	}))
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+
+	dryRun, downForce = true, true
+	defer func() { dryRun, downForce = false, false }()
+
+	err := runDown(nil, []string{"sbx-dry"})
+	if !claudevps.IsReadOnly(err) {
+		t.Fatalf("runDown() error = %v, want the delete refused", err)
+	}
+	for _, m := range methods {
+		if m != "GET" {
+			t.Errorf("sent a %s request during a dry run", m)
+		}
+	}
+
+	var out strings.Builder
+	presentDryRun(&out, err)
+	if !strings.Contains(out.String(), "Dry run: would DELETE /sandboxes/sbx-dry") {
+		t.Errorf("presentDryRun() = %q", out.String())
+	}
+}
+
+func TestRunDown_ConflictingTargets(t *testing.T) {
+	defer func() { downAll, downGroup = false, "" }()
+
+	downAll, downGroup = true, "workshop"
+	if err := runDown(nil, nil); err == nil || !strings.Contains(err.Error(), "--group with --all") {
+		t.Errorf("Expected --group and --all to conflict, got %v", err)
+	}
+	downAll, downGroup = false, "workshop"
+	if err := runDown(nil, []string{"sbx-1"}); err == nil || !strings.Contains(err.Error(), "sandbox argument") {
+		t.Errorf("Expected a sandbox argument and --group to conflict, got %v", err)
+	}
+	downAll, downGroup = true, ""
+	if err := runDown(nil, []string{"sbx-1"}); err == nil || !strings.Contains(err.Error(), "sandbox argument") {
+		t.Errorf("Expected a sandbox argument and --all to conflict, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var editForce bool
+
+var editCmd = &cobra.Command{
+	Use:   "edit <[sandbox:]path>",
+	Short: "Edit a sandbox file in your local editor",
+	Long: `Download a file from a sandbox, open it in your local editor and upload it
+back when the editor exits.
+
+The editor is taken from $VISUAL or $EDITOR. If the remote file changed while
+you were editing, the upload is refused and your copy is kept locally unless
+--force is given. Missing files are created on save, unless left empty.`,
+	Example: `  # Edit a file in the current sandbox
+  cvps edit /etc/nginx/nginx.conf
+
+  # Edit a file in a named sandbox
+  cvps edit myproject:~/.bashrc
+
+  # Use a specific editor
+  EDITOR="code --wait" cvps edit /workspace/.env`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	editCmd.Flags().BoolVarP(&editForce, "force", "f", false, "upload even if the remote file changed while editing")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	target, ok := parseRemotePath(args[0])
+	if !ok {
+		target = remotePath{Path: args[0]}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, target.Sandbox)
+	if err != nil {
+		return err
+	}
+
+	fs, err := openSandboxFS(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	remoteFile, err := fs.Resolve(target.Path)
+	if err != nil {
+		return err
+	}
+
+	var original []byte
+	perm := os.FileMode(0644)
+	before, err := fs.Stat(remoteFile)
+	switch {
+	case err == nil:
+		if before.IsDir() {
+			return fmt.Errorf("%s is a directory", remoteFile)
+		}
+		perm = before.Mode().Perm()
+		if original, err = fs.ReadFile(remoteFile); err != nil {
+			return err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		before = nil
+	default:
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "cvps-edit-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	// Keep the remote file name so editors pick the right syntax highlighting
+	localFile := filepath.Join(tmpDir, path.Base(remoteFile))
+	if err := os.WriteFile(localFile, original, 0600); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	if err := runEditor(localFile); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	edited, err := os.ReadFile(localFile)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+	// A new file left empty is not created, as when quitting without saving
+	if bytes.Equal(edited, original) {
+		os.RemoveAll(tmpDir)
+		if before == nil {
+			fmt.Printf("No changes made; %s was not created.\n", remoteFile)
+		} else {
+			fmt.Println("No changes made.")
+		}
+		return nil
+	}
+
+	after, err := fs.Stat(remoteFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check remote file (your changes are in %s): %w", localFile, err)
+	}
+	if err != nil {
+		after = nil
+	}
+	if remoteChanged(before, after) && !editForce {
+		return fmt.Errorf("%s changed on the sandbox while you were editing. Your changes are saved in %s; re-run with --force to overwrite", remoteFile, localFile)
+	}
+
+	if err := fs.WriteFile(remoteFile, edited, perm); err != nil {
+		return fmt.Errorf("failed to upload (your changes are in %s): %w", localFile, err)
+	}
+
+	os.RemoveAll(tmpDir)
+	fmt.Printf("Saved %s (%s)\n", remoteFile, formatBytes(int64(len(edited))))
+	return nil
+}
+
+// remoteChanged reports whether the remote file differs from the copy taken before editing
+func remoteChanged(before, after os.FileInfo) bool {
+	if before == nil || after == nil {
+		return (before == nil) != (after == nil)
+	}
+	return !before.ModTime().Equal(after.ModTime()) || before.Size() != after.Size()
+}
+
+// editorCommand returns the editor argv from $VISUAL or $EDITOR
+func editorCommand(getenv func(string) string) []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(getenv(key)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func runEditor(file string) error {
+	argv := editorCommand(os.Getenv)
+	editor := exec.Command(argv[0], append(argv[1:], file)...)
+	editor.Stdin = os.Stdin
+	editor.Stdout = os.Stdout
+	editor.Stderr = os.Stderr
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", argv[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubFileInfo struct {
+	size  int64
+	mtime time.Time
+}
+
+func (s stubFileInfo) Name() string       { return "file" }
+func (s stubFileInfo) Size() int64        { return s.size }
+func (s stubFileInfo) Mode() os.FileMode  { return 0644 }
+func (s stubFileInfo) ModTime() time.Time { return s.mtime }
+func (s stubFileInfo) IsDir() bool        { return false }
+func (s stubFileInfo) Sys() any           { return nil }
+
+func TestRemoteChanged(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	orig := stubFileInfo{size: 10, mtime: base}
+
+	tests := []struct {
+		name   string
+		before os.FileInfo
+		after  os.FileInfo
+		want   bool
+	}{
+		{name: "unchanged", before: orig, after: stubFileInfo{size: 10, mtime: base}, want: false},
+		{name: "mtime changed", before: orig, after: stubFileInfo{size: 10, mtime: base.Add(time.Second)}, want: true},
+		{name: "size changed", before: orig, after: stubFileInfo{size: 11, mtime: base}, want: true},
+		{name: "deleted remotely", before: orig, after: nil, want: true},
+		{name: "created remotely", before: nil, after: orig, want: true},
+		{name: "still missing", before: nil, after: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := remoteChanged(tt.before, tt.after); got != tt.want {
+				t.Fatalf("remoteChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	env := map[string]string{"EDITOR": "code --wait"}
+	got := editorCommand(func(k string) string { return env[k] })
+	if strings.Join(got, " ") != "code --wait" {
+		t.Fatalf("editorCommand() = %v, want [code --wait]", got)
+	}
+
+	env["VISUAL"] = "nvim"
+	got = editorCommand(func(k string) string { return env[k] })
+	if strings.Join(got, " ") != "nvim" {
+		t.Fatalf("editorCommand() = %v, want VISUAL to take precedence", got)
+	}
+
+	got = editorCommand(func(string) string { return "" })
+	if len(got) != 1 {
+		t.Fatalf("editorCommand() fallback = %v, want a single default editor", got)
+	}
+}
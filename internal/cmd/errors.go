@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// suggestionDistance is the largest edit distance still offered as a
+// "did you mean" suggestion, the same as cobra's default
+const suggestionDistance = 2
+
+// maxSuggestions caps how many suggestions are printed for one error
+const maxSuggestions = 3
+
+// sandboxNotFoundError is returned when a sandbox reference matches nothing
+type sandboxNotFoundError struct {
+	Ref string
+}
+
+func (e *sandboxNotFoundError) Error() string {
+	return fmt.Sprintf("sandbox not found: %s", e.Ref)
+}
+
+// exitCodeError makes cvps exit with Code instead of 1, for commands whose
+// scripts need to tell failures apart
+type exitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *exitCodeError) Error() string { return e.Err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.Err }
+
+// exitCode returns the status cvps exits with for err
+func exitCode(err error) int {
+	var e *exitCodeError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 1
+}
+
+// unknownCommandError is returned for a subcommand that does not exist
+type unknownCommandError struct {
+	Name   string
+	Parent *cobra.Command
+}
+
+func (e *unknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command %q for %q", e.Name, e.Parent.CommandPath())
+}
+
+// imagePullAuthError is returned when provisioning fails because the image
+// registry rejected the pull
+type imagePullAuthError struct {
+	Image  string
+	Reason string
+}
+
+func (e *imagePullAuthError) Error() string {
+	msg := "sandbox provisioning failed: not authorized to pull image"
+	if e.Image != "" {
+		msg += " " + e.Image
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// isConflict reports whether err is a 409 from the API with one of codes,
+// meaning the request raced with another change to the same sandbox
+func isConflict(err error, codes ...string) bool {
+	code := claudevps.ConflictCode(err)
+	return code != "" && slices.Contains(codes, code)
+}
+
+// presentError prints err followed by suggestions for what was meant
+func presentError(w io.Writer, err error) {
+	fmt.Fprintln(w, err)
+
+	var unknownCmd *unknownCommandError
+	var notFound *sandboxNotFoundError
+	var pullAuth *imagePullAuthError
+	switch {
+	case errors.As(err, &unknownCmd):
+		printSuggestions(w, commandSuggestions(unknownCmd.Parent, unknownCmd.Name))
+		fmt.Fprintf(w, "Run '%s --help' for usage.\n", unknownCmd.Parent.CommandPath())
+	case errors.As(err, &notFound):
+		printSuggestions(w, sandboxSuggestions(notFound.Ref))
+		fmt.Fprintln(w, "Run 'cvps status --all' to view available sandboxes.")
+	case errors.As(err, &pullAuth):
+		registry := "<registry>"
+		if pullAuth.Image != "" {
+			registry = imageRegistry(pullAuth.Image)
+		}
+		fmt.Fprintf(w, "\nIf the image is private, store credentials with 'cvps registry login %s'.\n", registry)
+		fmt.Fprintln(w, "Check stored credentials with 'cvps registry list'.")
+	}
+
+	if claudevps.IsTimeout(err) {
+		fmt.Fprintln(w, "\nIf the API is just slow, wait longer with api_timeout in ~/.cvps/config.yaml or CVPS_API_TIMEOUT (e.g. 2m).")
+	}
+}
+
+// presentDryRun describes the change a --dry-run command stopped at
+func presentDryRun(w io.Writer, err error) {
+	var readOnlyErr *claudevps.ReadOnlyError
+	if !errors.As(err, &readOnlyErr) {
+		return
+	}
+	fmt.Fprintf(w, "\nDry run: would %s %s\n", readOnlyErr.Method, readOnlyErr.Path)
+	if len(readOnlyErr.Body) > 0 {
+		var body bytes.Buffer
+		if json.Indent(&body, readOnlyErr.Body, "  ", "  ") == nil {
+			fmt.Fprintf(w, "  %s\n", body.String())
+		}
+	}
+	fmt.Fprintln(w, "Stopped there; nothing was changed.")
+}
+
+func printSuggestions(w io.Writer, suggestions []string) {
+	if len(suggestions) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nDid you mean this?")
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "\t%s\n", s)
+	}
+	fmt.Fprintln(w)
+}
+
+// findUnknownCommand returns an unknownCommandError if args name a subcommand
+// that does not exist, which cobra would otherwise answer with a usage dump
+// for command groups
+func findUnknownCommand(args []string) error {
+	c, rest, err := rootCmd.Find(args)
+	if err != nil {
+		if name := firstCommandArg(c, rest); name != "" {
+			return &unknownCommandError{Name: name, Parent: c}
+		}
+		return err
+	}
+	if c == rootCmd || c.Runnable() || !c.HasAvailableSubCommands() {
+		return nil
+	}
+	if name := firstCommandArg(c, rest); name != "" {
+		return &unknownCommandError{Name: name, Parent: c}
+	}
+	return nil
+}
+
+// firstCommandArg returns the first argument of args that is not a flag or
+// a flag's value
+func firstCommandArg(c *cobra.Command, args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return ""
+		case strings.HasPrefix(arg, "--"):
+			if !strings.Contains(arg, "=") && flagTakesValue(c, arg[2:], "") {
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if len(arg) == 2 && flagTakesValue(c, "", arg[1:]) {
+				i++
+			}
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+// flagTakesValue reports whether the flag with the given name or shorthand,
+// local or inherited, is followed by a separate value argument
+func flagTakesValue(c *cobra.Command, name, shorthand string) bool {
+	for _, fs := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags(), c.InheritedFlags()} {
+		f := fs.Lookup(name)
+		if shorthand != "" {
+			f = fs.ShorthandLookup(shorthand)
+		}
+		if f != nil {
+			return f.NoOptDefVal == ""
+		}
+	}
+	return false
+}
+
+// commandSuggestions returns subcommands of parent close to name. At the top
+// level aliases and plugins are candidates too.
+func commandSuggestions(parent *cobra.Command, name string) []string {
+	if parent.SuggestionsMinimumDistance <= 0 {
+		parent.SuggestionsMinimumDistance = suggestionDistance
+	}
+	suggestions := parent.SuggestionsFor(name)
+	if parent != rootCmd {
+		return suggestions
+	}
+
+	var extra []string
+	if cfg, err := config.Load(); err == nil {
+		for alias := range cfg.Aliases {
+			extra = append(extra, alias)
+		}
+	}
+	for _, p := range findPlugins() {
+		if p.Warning == "" {
+			extra = append(extra, p.Name)
+		}
+	}
+	return append(suggestions, closestMatches(name, extra)...)
+}
+
+// sandboxSuggestions returns names from the cached sandbox list close to ref.
+// Sandboxes without a name are suggested by ID.
+func sandboxSuggestions(ref string) []string {
+	c, err := cache.LoadSandboxes()
+	if err != nil || c == nil {
+		return nil
+	}
+	var candidates []string
+	for _, s := range c.Sandboxes {
+		if s.Name != "" {
+			candidates = append(candidates, s.Name)
+		} else {
+			candidates = append(candidates, s.ID)
+		}
+	}
+	return closestMatches(ref, candidates)
+}
+
+// closestMatches returns up to maxSuggestions candidates within
+// suggestionDistance of s, closest first
+func closestMatches(s string, candidates []string) []string {
+	type match struct {
+		value    string
+		distance int
+	}
+	var matches []match
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		if d := editDistance(strings.ToLower(s), strings.ToLower(c)); d <= suggestionDistance {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].value < matches[j].value
+	})
+
+	var out []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		out = append(out, matches[i].value)
+	}
+	return out
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
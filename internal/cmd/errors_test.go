@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"status", "status", 0},
+		{"stauts", "status", 2},
+		{"conect", "connect", 1},
+		{"", "up", 2},
+		{"web", "", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindUnknownCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, args := range [][]string{nil, {"status", "--all"}, {"alias", "list"}, {"alias"}, {"--config", "x.yaml", "status"}} {
+		if err := findUnknownCommand(args); err != nil {
+			t.Errorf("findUnknownCommand(%q) = %v, want nil", args, err)
+		}
+	}
+
+	err := findUnknownCommand([]string{"--config", "x.yaml", "conect"})
+	var unknown *unknownCommandError
+	if !errors.As(err, &unknown) || unknown.Name != "conect" || unknown.Parent != rootCmd {
+		t.Fatalf("findUnknownCommand() = %v, want unknown command conect", err)
+	}
+	var buf bytes.Buffer
+	presentError(&buf, err)
+	if !strings.Contains(buf.String(), "Did you mean this?\n\tconnect\n") {
+		t.Errorf("presentError() = %q, expected connect suggestion", buf.String())
+	}
+
+	err = findUnknownCommand([]string{"alias", "lsit"})
+	if !errors.As(err, &unknown) || unknown.Parent != aliasCmd {
+		t.Fatalf("findUnknownCommand() = %v, want unknown alias subcommand", err)
+	}
+	buf.Reset()
+	presentError(&buf, err)
+	if !strings.Contains(buf.String(), "\tlist\n") || !strings.Contains(buf.String(), "Run 'cvps alias --help'") {
+		t.Errorf("presentError() = %q, expected list suggestion", buf.String())
+	}
+}
+
+func TestSandboxSuggestions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := sandboxSuggestions("web"); got != nil {
+		t.Errorf("sandboxSuggestions() without cache = %q, want nil", got)
+	}
+
+	if err := cache.SaveSandboxes([]claudevps.Sandbox{
+		{ID: "sbx-1", Name: "web-backend"},
+		{ID: "sbx-2", Name: "web-frontend"},
+		{ID: "sbx-3", Name: "worker"},
+		{ID: "sbx-4"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := sandboxSuggestions("Web-Bakend"), []string{"web-backend"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sandboxSuggestions() = %q, want %q", got, want)
+	}
+	if got, want := sandboxSuggestions("sbx-5"), []string{"sbx-4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sandboxSuggestions() = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	presentError(&buf, &sandboxNotFoundError{Ref: "wroker"})
+	want := "sandbox not found: wroker\n\nDid you mean this?\n\tworker\n\nRun 'cvps status --all' to view available sandboxes.\n"
+	if buf.String() != want {
+		t.Errorf("presentError() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPresentError_Wrapped(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	err := fmt.Errorf("migration to eu-west failed: %w", &imagePullAuthError{Image: "ghcr.io/acme/app:1"})
+	var buf bytes.Buffer
+	presentError(&buf, err)
+	if !strings.Contains(buf.String(), "cvps registry login ghcr.io") {
+		t.Errorf("presentError() = %q, want the registry login hint", buf.String())
+	}
+
+	buf.Reset()
+	presentError(&buf, fmt.Errorf("failed to connect: %w", &sandboxNotFoundError{Ref: "web"}))
+	if !strings.Contains(buf.String(), "cvps status --all") {
+		t.Errorf("presentError() = %q, want the status hint", buf.String())
+	}
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execSelector string
+	execAll      bool
+	execGroup    string
+	execParallel int
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [sandbox] -- <command>",
+	Short: "Run a command in one or more sandboxes",
+	Long: `Run a command in a sandbox over SSH.
+
+With --selector, --group or --all the command runs concurrently across every
+matching running sandbox. Each output line is prefixed with the sandbox name,
+and the exit status is non-zero if the command failed on any sandbox.
+
+Selectors are comma-separated label requirements: key=value, key!=value, or
+just key to require the label to be present. Labels are set with
+'cvps up --label'.`,
+	Example: `  # Run in the current sandbox
+  cvps exec -- uname -a
+
+  # Run in a named sandbox
+  cvps exec myproject -- df -h /workspace
+
+  # Fleet maintenance across labelled sandboxes, 5 at a time
+  cvps exec --selector class=intro --parallel 5 -- sudo apt-get upgrade -y
+
+  # Every sandbox in a group
+  cvps exec --group workshop -- uptime
+
+  # Every running sandbox
+  cvps exec --all -- uptime`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExec,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringVarP(&execSelector, "selector", "l", "", "run on sandboxes matching a label selector (e.g. class=intro)")
+	execCmd.Flags().StringVarP(&execGroup, "group", "g", "", "run on the sandboxes in a group")
+	execCmd.Flags().BoolVar(&execAll, "all", false, "run on all running sandboxes")
+	execCmd.Flags().IntVarP(&execParallel, "parallel", "p", 10, "maximum number of sandboxes to run on at once")
+}
+
+// execResult is the outcome of a command on one sandbox
+type execResult struct {
+	Sandbox claudevps.Sandbox
+	Status  int
+	Err     error
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	ref, command, err := splitExecArgs(args, cmd.ArgsLenAtDash())
+	if err != nil {
+		return err
+	}
+	fanOut := execAll || execSelector != "" || execGroup != ""
+	if fanOut && ref != "" {
+		return fmt.Errorf("cannot combine a sandbox argument with --selector, --group or --all")
+	}
+	if execAll && execGroup != "" {
+		return fmt.Errorf("cannot combine --group with --all")
+	}
+	if execParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	selector, err := parseSelector(execSelector)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	if !fanOut {
+		sandboxID, err := resolveSandboxRef(ctx, client, ref)
+		if err != nil {
+			return err
+		}
+		conn, _, err := openSandboxSSH(ctx, client, sandboxID)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		err = conn.Run(ctx, command, nil, os.Stdout, os.Stderr)
+		var exitErr *remote.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("command exited with status %d", exitErr.Status)
+		}
+		return err
+	}
+
+	var sandboxes []claudevps.Sandbox
+	if execGroup != "" {
+		if sandboxes, err = groupSandboxes(ctx, client, execGroup); err != nil {
+			return err
+		}
+	} else if sandboxes, err = listAllSandboxesForConnect(ctx, client); err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	targets, skipped := execTargets(sandboxes, selector)
+	for _, s := range skipped {
+		color.Yellow("⚠ Skipping %s (status: %s)", s.Name, s.Status)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no running sandboxes match")
+	}
+
+	results := execFanOut(ctx, targets, command, execParallel)
+	return summarizeExec(results)
+}
+
+// splitExecArgs separates the optional sandbox argument from the command.
+// dash is the index of "--" in args, or -1 when it was not given.
+func splitExecArgs(args []string, dash int) (string, string, error) {
+	var ref string
+	switch {
+	case dash < 0 || dash == 0:
+	case dash == 1:
+		ref, args = args[0], args[1:]
+	default:
+		return "", "", fmt.Errorf("expected at most one sandbox before --")
+	}
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("no command given")
+	}
+	return ref, watchCommandLine(args), nil
+}
+
+// labelRequirement is one clause of a label selector
+type labelRequirement struct {
+	Key    string
+	Value  string
+	Negate bool
+	Exists bool
+}
+
+// labelSelector matches sandboxes whose labels satisfy every requirement
+type labelSelector []labelRequirement
+
+// parseSelector parses "key=value,key!=value,key" selectors
+func parseSelector(s string) (labelSelector, error) {
+	var sel labelSelector
+	for _, clause := range strings.Split(s, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		var req labelRequirement
+		if k, v, ok := strings.Cut(clause, "!="); ok {
+			req = labelRequirement{Key: k, Value: v, Negate: true}
+		} else if k, v, ok := strings.Cut(clause, "="); ok {
+			req = labelRequirement{Key: k, Value: v}
+		} else {
+			req = labelRequirement{Key: clause, Exists: true}
+		}
+
+		req.Key = strings.TrimSpace(req.Key)
+		req.Value = strings.TrimSpace(req.Value)
+		if req.Key == "" {
+			return nil, fmt.Errorf("invalid selector %q: missing label key", clause)
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// Matches reports whether labels satisfy the selector. An empty selector matches everything.
+func (sel labelSelector) Matches(labels map[string]string) bool {
+	for _, req := range sel {
+		v, ok := labels[req.Key]
+		switch {
+		case req.Exists:
+			if !ok {
+				return false
+			}
+		case req.Negate:
+			if ok && v == req.Value {
+				return false
+			}
+		default:
+			if !ok || v != req.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseLabels converts key=value flags into a label map
+func parseLabels(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", pair)
+		}
+		labels[k] = strings.TrimSpace(v)
+	}
+	return labels, nil
+}
+
+// execTargets returns the running sandboxes matching sel, sorted by name,
+// along with matching sandboxes that were skipped because they are not running
+func execTargets(sandboxes []claudevps.Sandbox, sel labelSelector) (targets, skipped []claudevps.Sandbox) {
+	for _, s := range sandboxes {
+		if !sel.Matches(s.Labels) {
+			continue
+		}
+		if isRunningStatus(s.Status) {
+			targets = append(targets, s)
+		} else {
+			skipped = append(skipped, s)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets, skipped
+}
+
+// execFanOut runs command on every target, at most parallel at a time
+func execFanOut(ctx context.Context, targets []claudevps.Sandbox, command string, parallel int) []execResult {
+	width := 0
+	for _, s := range targets {
+		width = max(width, len(s.Name))
+	}
+
+	var outMu sync.Mutex
+	results := make([]execResult, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, s := range targets {
+		wg.Add(1)
+		go func(i int, s claudevps.Sandbox) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prefix := color.CyanString("%-*s", width, s.Name) + " | "
+			stdout := &prefixWriter{mu: &outMu, out: os.Stdout, prefix: prefix}
+			stderr := &prefixWriter{mu: &outMu, out: os.Stderr, prefix: prefix}
+
+			results[i] = execOn(ctx, s, command, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
+		}(i, s)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func execOn(ctx context.Context, s claudevps.Sandbox, command string, stdout, stderr io.Writer) execResult {
+	result := execResult{Sandbox: s}
+
+	conn, err := dialSandbox(ctx, &s)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+
+	err = conn.Run(ctx, command, nil, stdout, stderr)
+	var exitErr *remote.ExitError
+	if errors.As(err, &exitErr) {
+		result.Status = exitErr.Status
+		return result
+	}
+	result.Err = err
+	return result
+}
+
+// summarizeExec prints per-sandbox failures and returns an error if any run failed
+func summarizeExec(results []execResult) error {
+	failed := 0
+	fmt.Println()
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			color.Red("✗ %s: %v", r.Sandbox.Name, r.Err)
+		case r.Status != 0:
+			failed++
+			color.Red("✗ %s: exited with status %d", r.Sandbox.Name, r.Status)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d sandboxes", failed, len(results))
+	}
+	color.Green("✓ Command succeeded on %d sandbox(es)", len(results))
+	return nil
+}
+
+// prefixWriter writes complete lines to out, each preceded by prefix. Writers
+// sharing mu never interleave partial lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	idx := bytes.LastIndexByte(w.buf, '\n')
+	if idx < 0 {
+		return len(p), nil
+	}
+
+	w.writeLines(w.buf[:idx+1])
+	w.buf = append(w.buf[:0], w.buf[idx+1:]...)
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line
+func (w *prefixWriter) Flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.writeLines(append(w.buf, '\n'))
+	w.buf = w.buf[:0]
+}
+
+func (w *prefixWriter) writeLines(chunk []byte) {
+	var b bytes.Buffer
+	for _, line := range bytes.SplitAfter(chunk, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		b.WriteString(w.prefix)
+		b.Write(line)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out.Write(b.Bytes())
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSplitExecArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		dash    int
+		ref     string
+		command string
+		wantErr bool
+	}{
+		{[]string{"uptime"}, -1, "", "uptime", false},
+		{[]string{"ls", "-la"}, 0, "", "ls -la", false},
+		{[]string{"myproject", "df", "-h"}, 1, "myproject", "df -h", false},
+		{[]string{"a", "b", "uptime"}, 2, "", "", true},
+		{[]string{"myproject"}, 1, "", "", true},
+	}
+
+	for _, tt := range tests {
+		ref, command, err := splitExecArgs(tt.args, tt.dash)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitExecArgs(%q, %d) error = %v, wantErr %v", tt.args, tt.dash, err, tt.wantErr)
+			continue
+		}
+		if ref != tt.ref || command != tt.command {
+			t.Errorf("splitExecArgs(%q, %d) = %q, %q, want %q, %q", tt.args, tt.dash, ref, command, tt.ref, tt.command)
+		}
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{"class": "intro", "seat": "3"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"class=intro", true},
+		{"class=advanced", false},
+		{"class=intro,seat=3", true},
+		{"class=intro, seat=4", false},
+		{"seat!=4", true},
+		{"seat!=3", false},
+		{"missing!=x", true},
+		{"class", true},
+		{"missing", false},
+	}
+
+	for _, tt := range tests {
+		sel, err := parseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parseSelector(%q) error = %v", tt.selector, err)
+		}
+		if got := sel.Matches(labels); got != tt.want {
+			t.Errorf("selector %q matches = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+
+	if _, err := parseSelector("=intro"); err == nil {
+		t.Error("expected error for selector without key")
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"class=intro", "seat = 3", "empty="})
+	if err != nil {
+		t.Fatalf("parseLabels() error = %v", err)
+	}
+	want := map[string]string{"class": "intro", "seat": "3", "empty": ""}
+	for k, v := range want {
+		if labels[k] != v {
+			t.Errorf("labels[%q] = %q, want %q", k, labels[k], v)
+		}
+	}
+
+	if _, err := parseLabels([]string{"novalue"}); err == nil {
+		t.Error("expected error for label without =")
+	}
+	if labels, _ := parseLabels(nil); labels != nil {
+		t.Errorf("parseLabels(nil) = %v, want nil", labels)
+	}
+}
+
+func TestExecTargets(t *testing.T) {
+	sandboxes := []claudevps.Sandbox{
+		{ID: "sb-3", Name: "student-03", Status: "running", Labels: map[string]string{"class": "intro"}},
+		{ID: "sb-1", Name: "student-01", Status: "running", Labels: map[string]string{"class": "intro"}},
+		{ID: "sb-2", Name: "student-02", Status: "stopped", Labels: map[string]string{"class": "intro"}},
+		{ID: "sb-4", Name: "teacher", Status: "running"},
+	}
+
+	sel, _ := parseSelector("class=intro")
+	targets, skipped := execTargets(sandboxes, sel)
+
+	if len(targets) != 2 || targets[0].Name != "student-01" || targets[1].Name != "student-03" {
+		t.Errorf("targets = %+v, want student-01 and student-03", targets)
+	}
+	if len(skipped) != 1 || skipped[0].Name != "student-02" {
+		t.Errorf("skipped = %+v, want student-02", skipped)
+	}
+
+	all, _ := execTargets(sandboxes, nil)
+	if len(all) != 3 {
+		t.Errorf("got %d targets for empty selector, want 3", len(all))
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := &prefixWriter{mu: &mu, out: &out, prefix: "a | "}
+
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\nthree"))
+	if got := out.String(); got != "a | one\na | two\n" {
+		t.Errorf("before flush = %q", got)
+	}
+
+	w.Flush()
+	if got := out.String(); got != "a | one\na | two\na | three\n" {
+		t.Errorf("after flush = %q", got)
+	}
+}
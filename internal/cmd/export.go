@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/internal/project"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportForce  bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [sandbox]",
+	Short: "Write a sandbox's configuration as a project bootstrap template",
+	Long: `Render the configuration of an existing sandbox in the ` + project.FileName + `
+format, so it can be checked in and reproduced with 'cvps up --bootstrap'.
+
+The template records the sandbox's name, description, resources, image,
+region, architecture and labels, the names of the secrets it gets as
+environment variables and the ports forwarded to it with 'cvps forward'.
+Secret values are never exported. Only the configuration is captured, not the
+disk; take a snapshot with 'cvps snapshot' for that.`,
+	Example: `  # Print the template of the current sandbox
+  cvps export
+
+  # Capture a hand-built sandbox so it can be recreated
+  cvps export legacy-box -o ` + project.FileName,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runExport,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "file to write, or - for stdout")
+	exportCmd.Flags().BoolVarP(&exportForce, "force", "f", false, "overwrite the output file if it exists")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportOutput != "-" && !exportForce {
+		if _, err := os.Stat(exportOutput); err == nil {
+			return fmt.Errorf("%s already exists. Use --force to overwrite it", exportOutput)
+		}
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+	sandbox, err := client.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return &sandboxNotFoundError{Ref: sandboxID}
+		}
+		return fmt.Errorf("failed to get sandbox: %w", err)
+	}
+
+	secrets, err := client.ListSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	// Forwards are only known to the local mutagen daemon
+	var forwards []mutagen.ForwardStatus
+	if mutagen.IsInstalled() {
+		if forwards, err = mutagen.ListForwards(forwardSessionPrefix(sandbox.ID)); err != nil {
+			debuglog.Printf("forwards not exported: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Exported from sandbox %s (%s) on %s.\n", sandbox.Name, sandbox.ID, time.Now().Format("2006-01-02"))
+	fmt.Fprintln(&buf, "# Recreate it with 'cvps up --bootstrap' in the directory holding this file.")
+	fmt.Fprintln(&buf, "# Only the configuration is captured, not the disk.")
+	if err := project.Write(&buf, sandboxTemplate(sandbox, secrets, forwards)); err != nil {
+		return err
+	}
+
+	if exportOutput == "-" {
+		_, err := io.Copy(os.Stdout, &buf)
+		return err
+	}
+	if err := os.WriteFile(exportOutput, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+	}
+	fmt.Printf("✓ Wrote the template of '%s' to %s\n", sandbox.Name, exportOutput)
+	return nil
+}
+
+// sandboxTemplate describes a sandbox as a bootstrap template. env lists the
+// environment secrets the sandbox gets, whether account-wide or its own.
+func sandboxTemplate(s *claudevps.Sandbox, secrets []claudevps.Secret, forwards []mutagen.ForwardStatus) *project.Config {
+	b := &project.Bootstrap{
+		Name:        s.Name,
+		Description: s.Description,
+		CPUCores:    s.CPUCores,
+		MemoryGB:    s.MemoryGB,
+		StorageGB:   s.StorageGB,
+		Image:       s.Image,
+		Region:      s.Region,
+		Arch:        s.Arch,
+		Labels:      s.Labels,
+	}
+
+	for _, secret := range secrets {
+		if secret.Mount == claudevps.SecretMountEnv && (secret.SandboxID == "" || secret.SandboxID == s.ID) {
+			b.Env = append(b.Env, secret.Name)
+		}
+	}
+	slices.Sort(b.Env)
+	b.Env = slices.Compact(b.Env)
+
+	var ports []portForward
+	for _, f := range forwards {
+		local, lerr := endpointPort(f.Source)
+		remote, rerr := endpointPort(f.Destination)
+		if lerr != nil || rerr != nil {
+			debuglog.Printf("forward %s not exported: %s -> %s", f.Name, f.Source, f.Destination)
+			continue
+		}
+		ports = append(ports, portForward{Local: local, Remote: remote})
+	}
+	slices.SortFunc(ports, func(a, b portForward) int { return a.Local - b.Local })
+	for _, p := range ports {
+		b.Ports = append(b.Ports, p.String())
+	}
+
+	return &project.Config{Bootstrap: b}
+}
+
+// endpointPort returns the port of a mutagen TCP endpoint such as
+// tcp:localhost:3000 or user@host:22:tcp:localhost:80
+func endpointPort(endpoint string) (int, error) {
+	i := strings.LastIndex(endpoint, ":")
+	return strconv.Atoi(endpoint[i+1:])
+}
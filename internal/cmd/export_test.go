@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/internal/project"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSandboxTemplate(t *testing.T) {
+	s := &claudevps.Sandbox{
+		ID: "sbx-abc123", Name: "legacy", CPUCores: 4, MemoryGB: 8, StorageGB: 50,
+		Image: "node:20", Arch: "arm64", Labels: map[string]string{"team": "web"},
+	}
+	secrets := []claudevps.Secret{
+		{Name: "NPM_TOKEN", Mount: claudevps.SecretMountEnv},
+		{Name: "DB_URL", Mount: claudevps.SecretMountEnv, SandboxID: "sbx-abc123"},
+		{Name: "OTHER", Mount: claudevps.SecretMountEnv, SandboxID: "sbx-other"},
+		{Name: "TLS_KEY", Mount: "file"},
+	}
+	forwards := []mutagen.ForwardStatus{
+		{Name: "f2", Source: "tcp:localhost:8080", Destination: "root@ssh.example.com:2222:tcp:localhost:80"},
+		{Name: "f1", Source: "tcp:localhost:3000", Destination: "root@ssh.example.com:tcp:localhost:3000"},
+		{Name: "bad", Source: "unix:/tmp/sock", Destination: "root@ssh.example.com:tcp:localhost:5432"},
+	}
+
+	b := sandboxTemplate(s, secrets, forwards).Bootstrap
+	if b.Name != "legacy" || b.CPUCores != 4 || b.Image != "node:20" || b.Arch != "arm64" || b.Labels["team"] != "web" {
+		t.Errorf("Unexpected template: %+v", b)
+	}
+	if !slices.Equal(b.Env, []string{"DB_URL", "NPM_TOKEN"}) {
+		t.Errorf("Env = %v", b.Env)
+	}
+	if !slices.Equal(b.Ports, []string{"3000", "8080:80"}) {
+		t.Errorf("Ports = %v", b.Ports)
+	}
+
+	// The export must load back as a valid project config
+	var buf bytes.Buffer
+	if err := project.Write(&buf, &project.Config{Bootstrap: b}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, project.FileName), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := project.Load(dir)
+	if err != nil {
+		t.Fatalf("Exported template does not load: %v", err)
+	}
+	if c.Bootstrap.StorageGB != 50 || !slices.Equal(c.Bootstrap.Ports, b.Ports) {
+		t.Errorf("Round trip lost data: %+v", c.Bootstrap)
+	}
+}
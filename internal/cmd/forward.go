@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// forwardSandboxLabel is the mutagen label recording which sandbox a
+// forwarding session belongs to
+const forwardSandboxLabel = "cvps-sandbox"
+
+var (
+	forwardSandbox string
+	forwardAll     bool
+	forwardJSON    bool
+)
+
+var forwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "Manage port forwards run by mutagen",
+	Long: `Forward localhost ports to a sandbox with mutagen forwarding sessions.
+
+Unlike 'cvps dev --forward', these forwards are managed by the mutagen daemon:
+they survive cvps exiting, reconnect after network changes and show up next to
+your sync sessions in 'mutagen forward list'. Requires mutagen.`,
+}
+
+var forwardCreateCmd = &cobra.Command{
+	Use:   "create <port|local:remote>...",
+	Short: "Forward localhost ports to the sandbox",
+	Example: `  # Forward localhost:3000 to port 3000 in the current sandbox
+  cvps forward create 3000
+
+  # Forward localhost:8080 to port 80 in a named sandbox
+  cvps forward create 8080:80 --sandbox web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForwardCreate,
+}
+
+var forwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List port forwards",
+	Args:  cobra.NoArgs,
+	RunE:  runForwardList,
+}
+
+var forwardTerminateCmd = &cobra.Command{
+	Use:   "terminate [local-port]...",
+	Short: "Stop port forwards",
+	Long: `Stop the forwards of the given local ports, or all forwards of the sandbox
+when no ports are given. --all stops the forwards of every sandbox.`,
+	RunE: runForwardTerminate,
+}
+
+func init() {
+	rootCmd.AddCommand(forwardCmd)
+	forwardCmd.AddCommand(forwardCreateCmd)
+	forwardCmd.AddCommand(forwardListCmd)
+	forwardCmd.AddCommand(forwardTerminateCmd)
+
+	forwardCmd.PersistentFlags().StringVarP(&forwardSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	forwardListCmd.Flags().BoolVar(&forwardJSON, "json", false, "output in JSON format")
+	forwardTerminateCmd.Flags().BoolVar(&forwardAll, "all", false, "stop the forwards of every sandbox")
+}
+
+// forwardSessionName names the mutagen session forwarding localPort to sandboxID
+func forwardSessionName(sandboxID string, localPort int) string {
+	return fmt.Sprintf("%s%d", forwardSessionPrefix(sandboxID), localPort)
+}
+
+// forwardSessionPrefix is shared by all forwarding sessions of sandboxID
+func forwardSessionPrefix(sandboxID string) string {
+	return fmt.Sprintf("cvps-%s-", sandboxID)
+}
+
+func requireMutagen() error {
+	if !mutagen.IsInstalled() {
+		return fmt.Errorf("mutagen is not installed. Install it from https://mutagen.io or use 'cvps dev --forward'")
+	}
+	return nil
+}
+
+// forwardSandboxID resolves --sandbox, falling back to the current context
+// without contacting the API
+func forwardSandboxID(ctx context.Context) (string, error) {
+	if forwardSandbox == "" {
+		id, err := getCurrentSandboxID()
+		if err != nil {
+			return "", fmt.Errorf("no sandbox specified: %w", err)
+		}
+		return id, nil
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return "", err
+	}
+	return resolveSandboxRef(ctx, client, forwardSandbox)
+}
+
+func runForwardCreate(cmd *cobra.Command, args []string) error {
+	forwards, err := parsePortForwards(args)
+	if err != nil {
+		return err
+	}
+	if err := requireMutagen(); err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, forwardSandbox)
+	if err != nil {
+		return err
+	}
+	sandbox, err := client.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	if !isRunningStatus(sandbox.Status) {
+		return fmt.Errorf("sandbox is not running (status: %s). Start it with 'cvps up'", sandbox.Status)
+	}
+
+	if err := createForwardSessions(sandbox, forwards); err != nil {
+		return err
+	}
+
+	fmt.Println("\nForwards keep running in the background. Stop them with 'cvps forward terminate'.")
+	return nil
+}
+
+// createForwardSessions starts a background forwarding session for each
+// forward that does not have one yet
+func createForwardSessions(sandbox *claudevps.Sandbox, forwards []portForward) error {
+	existing := make(map[string]bool)
+	if current, err := mutagen.ListForwards(forwardSessionPrefix(sandbox.ID)); err == nil {
+		for _, f := range current {
+			existing[f.Name] = true
+		}
+	}
+
+	for _, f := range forwards {
+		name := forwardSessionName(sandbox.ID, f.Local)
+		if existing[name] {
+			fmt.Printf("✓ localhost:%d is already forwarded (%s)\n", f.Local, name)
+			continue
+		}
+		err := mutagen.CreateForward(mutagen.ForwardConfig{
+			Name:       name,
+			RemoteHost: fmt.Sprintf("%s@%s", sandbox.SSHUser, sandbox.SSHHost),
+			RemotePort: sandbox.SSHPort,
+			LocalPort:  f.Local,
+			TargetPort: f.Remote,
+			Labels:     map[string]string{forwardSandboxLabel: sandbox.ID},
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Forwarding localhost:%d → sandbox:%d\n", f.Local, f.Remote)
+	}
+	return nil
+}
+
+func runForwardList(cmd *cobra.Command, args []string) error {
+	if err := requireMutagen(); err != nil {
+		return err
+	}
+
+	prefix := "cvps-"
+	if forwardSandbox != "" {
+		id, err := forwardSandboxID(context.Background())
+		if err != nil {
+			return err
+		}
+		prefix = forwardSessionPrefix(id)
+	}
+	forwards, err := mutagen.ListForwards(prefix)
+	if err != nil {
+		return err
+	}
+
+	if forwardJSON {
+		if forwards == nil {
+			forwards = []mutagen.ForwardStatus{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(forwards)
+	}
+
+	if len(forwards) == 0 {
+		fmt.Println("No port forwards. Create one with 'cvps forward create <port>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSANDBOX\tLOCAL\tREMOTE\tSTATUS")
+	for _, f := range forwards {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Name, f.Labels[forwardSandboxLabel], f.Source, f.Destination, f.Status)
+	}
+	w.Flush()
+	return nil
+}
+
+func runForwardTerminate(cmd *cobra.Command, args []string) error {
+	if forwardAll && len(args) > 0 {
+		return fmt.Errorf("provide either local ports or --all, not both")
+	}
+	ports := make([]int, 0, len(args))
+	for _, arg := range args {
+		f, err := parsePortForward(arg)
+		if err != nil || f.Local != f.Remote {
+			return fmt.Errorf("invalid local port: %s", arg)
+		}
+		ports = append(ports, f.Local)
+	}
+	if err := requireMutagen(); err != nil {
+		return err
+	}
+
+	prefix := "cvps-"
+	if !forwardAll {
+		id, err := forwardSandboxID(context.Background())
+		if err != nil {
+			return err
+		}
+		prefix = forwardSessionPrefix(id)
+	}
+	forwards, err := mutagen.ListForwards(prefix)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, f := range forwards {
+		names[f.Name] = true
+	}
+	var targets []string
+	if len(ports) == 0 {
+		for _, f := range forwards {
+			targets = append(targets, f.Name)
+		}
+	}
+	for _, port := range ports {
+		name := prefix + fmt.Sprint(port)
+		if !names[name] {
+			color.Yellow("⚠ localhost:%d is not forwarded", port)
+			continue
+		}
+		targets = append(targets, name)
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No port forwards to stop")
+		return nil
+	}
+	for _, name := range targets {
+		if err := mutagen.TerminateForward(name); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Stopped %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestForwardSessionName(t *testing.T) {
+	if got := forwardSessionName("sbx1", 3000); got != "cvps-sbx1-3000" {
+		t.Errorf("forwardSessionName() = %q", got)
+	}
+	if !strings.HasPrefix(forwardSessionName("sbx1", 80), forwardSessionPrefix("sbx1")) {
+		t.Error("session name should start with the sandbox prefix")
+	}
+}
+
+func TestRunForwardArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer func() { forwardAll = false }()
+
+	if err := runForwardCreate(nil, []string{"web"}); err == nil || !strings.Contains(err.Error(), "invalid port forward") {
+		t.Errorf("runForwardCreate() error = %v, want invalid port", err)
+	}
+	if err := runForwardTerminate(nil, []string{"8080:80"}); err == nil || !strings.Contains(err.Error(), "invalid local port") {
+		t.Errorf("runForwardTerminate() error = %v, want invalid local port", err)
+	}
+
+	forwardAll = true
+	if err := runForwardTerminate(nil, []string{"3000"}); err == nil || !strings.Contains(err.Error(), "--all") {
+		t.Errorf("runForwardTerminate() error = %v, want ports/--all conflict", err)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gitRemoteName     string
+	gitRemoteBranch   string
+	gitRemoteWorkTree string
+	gitRemoteForce    bool
+)
+
+var gitRemoteCmd = &cobra.Command{
+	Use:   "git-remote",
+	Short: "Deploy to a sandbox with git push",
+}
+
+var gitRemoteAddCmd = &cobra.Command{
+	Use:   "add [sandbox]",
+	Short: "Set up a git remote that deploys into the sandbox",
+	Long: `Create a bare repository in the sandbox with a post-receive hook that checks
+pushed commits out into the sandbox workspace, and add it as a remote of the
+local repository.
+
+Afterwards 'git push cvps main' transfers code without running a file sync.
+The work tree is overwritten on every push, so don't edit files there that
+you want to keep.`,
+	Example: `  # Add a "cvps" remote for the current sandbox
+  cvps git-remote add
+  git push cvps main
+
+  # Only deploy the release branch, into a different directory
+  cvps git-remote add web --branch release --work-tree /srv/app`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGitRemoteAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(gitRemoteCmd)
+	gitRemoteCmd.AddCommand(gitRemoteAddCmd)
+
+	gitRemoteAddCmd.Flags().StringVar(&gitRemoteName, "name", "cvps", "name of the local git remote")
+	gitRemoteAddCmd.Flags().StringVar(&gitRemoteBranch, "branch", "", "only check out pushes to this branch (default any branch)")
+	gitRemoteAddCmd.Flags().StringVar(&gitRemoteWorkTree, "work-tree", "/workspace", "sandbox directory to check pushed code out into")
+	gitRemoteAddCmd.Flags().BoolVarP(&gitRemoteForce, "force", "f", false, "replace the URL of an existing remote with the same name")
+}
+
+func runGitRemoteAdd(cmd *cobra.Command, args []string) error {
+	toplevel, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not inside a git repository")
+	}
+	existing, _ := gitOutput("remote", "get-url", gitRemoteName)
+	if existing != "" && !gitRemoteForce {
+		return fmt.Errorf("git remote %q already exists (%s). Use --force to replace it or --name to pick another", gitRemoteName, existing)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	repoPath := ".cvps/repos/" + filepath.Base(toplevel) + ".git"
+	var stderr bytes.Buffer
+	if err := conn.Run(ctx, gitRemoteSetupScript(repoPath, gitRemoteWorkTree, gitRemoteBranch), nil, nil, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("failed to set up repository in sandbox: %s", msg)
+		}
+		return fmt.Errorf("failed to set up repository in sandbox: %w", err)
+	}
+
+	url := gitRemoteURL(sandbox, repoPath)
+	if existing != "" {
+		_, err = gitOutput("remote", "set-url", gitRemoteName, url)
+	} else {
+		_, err = gitOutput("remote", "add", gitRemoteName, url)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add git remote: %w", err)
+	}
+
+	color.Green("✓ Added git remote '%s' for sandbox '%s'", gitRemoteName, sandbox.Name)
+	fmt.Printf("  Push with: git push %s %s\n", gitRemoteName, firstNonEmpty(gitRemoteBranch, "main"))
+	fmt.Printf("  Pushed code is checked out into %s\n", gitRemoteWorkTree)
+	return nil
+}
+
+// gitRemoteURL is the ssh URL of a repository relative to the sandbox user's home
+func gitRemoteURL(sandbox *claudevps.Sandbox, repoPath string) string {
+	return fmt.Sprintf("ssh://%s@%s:%d/~/%s", sandbox.SSHUser, sandbox.SSHHost, sandbox.SSHPort, repoPath)
+}
+
+// gitRemoteSetupScript creates the bare repository (relative to $HOME) and
+// installs the checkout hook. Running it again only refreshes the hook.
+func gitRemoteSetupScript(repoPath, workTree, branch string) string {
+	repo := `"$HOME"/` + remote.Quote(repoPath)
+	return strings.Join([]string{
+		"set -e",
+		`command -v git >/dev/null 2>&1 || { echo "git is not installed in the sandbox" >&2; exit 1; }`,
+		"mkdir -p " + remote.Quote(workTree) + " " + repo,
+		"git init --bare -q " + repo,
+		"cat > " + repo + "/hooks/post-receive <<'CVPS_HOOK'\n" + postReceiveHook(workTree, branch) + "CVPS_HOOK",
+		"chmod +x " + repo + "/hooks/post-receive",
+	}, "\n") + "\n"
+}
+
+// postReceiveHook checks pushed branches out into workTree
+func postReceiveHook(workTree, branch string) string {
+	return `#!/bin/sh
+# Installed by 'cvps git-remote add': check pushed code out into the work tree
+WORK_TREE=` + remote.Quote(workTree) + `
+ONLY_BRANCH=` + remote.Quote(branch) + `
+while read old new ref; do
+	branch=${ref#refs/heads/}
+	[ "$branch" = "$ref" ] && continue
+	[ "$new" = "0000000000000000000000000000000000000000" ] && continue
+	if [ -n "$ONLY_BRANCH" ] && [ "$branch" != "$ONLY_BRANCH" ]; then
+		echo "cvps: $branch not checked out (only $ONLY_BRANCH is deployed)"
+		continue
+	fi
+	git --work-tree="$WORK_TREE" checkout -q -f "$branch" || exit 1
+	echo "cvps: checked out $branch into $WORK_TREE"
+done
+`
+}
+
+// gitOutput runs git and returns its trimmed stdout. Errors carry git's message.
+func gitOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	c := exec.Command("git", args...)
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestGitRemoteURL(t *testing.T) {
+	sandbox := &claudevps.Sandbox{SSHUser: "dev", SSHHost: "ssh.example.com", SSHPort: 2222}
+	got := gitRemoteURL(sandbox, ".cvps/repos/app.git")
+	if want := "ssh://dev@ssh.example.com:2222/~/.cvps/repos/app.git"; got != want {
+		t.Errorf("gitRemoteURL() = %q, want %q", got, want)
+	}
+}
+
+// TestGitRemoteSetupScript runs the sandbox setup locally and pushes to the result
+func TestGitRemoteSetupScript(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	home := t.TempDir()
+	workTree := filepath.Join(home, "workspace")
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		c.Env = append(os.Environ(), "HOME="+home, "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+
+	setup := exec.Command("sh", "-c", gitRemoteSetupScript(".cvps/repos/app.git", workTree, "main"))
+	setup.Env = append(os.Environ(), "HOME="+home)
+	if out, err := setup.CombinedOutput(); err != nil {
+		t.Fatalf("setup script failed: %v\n%s", err, out)
+	}
+
+	local := t.TempDir()
+	git(local, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(local, "app.txt"), []byte("v1\n"), 0644)
+	git(local, "add", ".")
+	git(local, "commit", "-q", "-m", "v1")
+	git(local, "remote", "add", "cvps", filepath.Join(home, ".cvps/repos/app.git"))
+
+	out := git(local, "push", "-q", "cvps", "main")
+	if !strings.Contains(out, "checked out main") {
+		t.Errorf("Expected hook output, got %q", out)
+	}
+	if data, err := os.ReadFile(filepath.Join(workTree, "app.txt")); err != nil || string(data) != "v1\n" {
+		t.Errorf("Expected app.txt to be checked out, got %q, %v", data, err)
+	}
+
+	git(local, "checkout", "-q", "-b", "feature")
+	out = git(local, "push", "-q", "cvps", "feature")
+	if !strings.Contains(out, "only main is deployed") {
+		t.Errorf("Expected other branches to be skipped, got %q", out)
+	}
+}
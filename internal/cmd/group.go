@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/groups"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var groupListJSON bool
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage named groups of sandboxes",
+	Long: `Manage named groups of sandboxes for bulk operations.
+
+Groups are stored locally in ~/.cvps/groups.yaml and can be targeted with
+--group on status, down, exec and snapshot create.`,
+	Example: `  # Create a group and add sandboxes to it
+  cvps group create workshop
+  cvps group add workshop student-01 student-02 sbx-abc123
+
+  # Check on the whole group
+  cvps status --group workshop
+
+  # Tear it down afterwards
+  cvps down --group workshop`,
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create <name> [sandbox...]",
+	Short: "Create a group, optionally with initial members",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runGroupCreate,
+}
+
+var groupAddCmd = &cobra.Command{
+	Use:   "add <name> <sandbox>...",
+	Short: "Add sandboxes to a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runGroupAdd,
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <sandbox>...",
+	Short: "Remove sandboxes from a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runGroupRemove,
+}
+
+var groupDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a group (its sandboxes are not affected)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGroupDelete,
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups",
+	Args:  cobra.NoArgs,
+	RunE:  runGroupList,
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupCreateCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+	groupCmd.AddCommand(groupDeleteCmd)
+	groupCmd.AddCommand(groupListCmd)
+
+	groupListCmd.Flags().BoolVar(&groupListJSON, "json", false, "output in JSON format")
+}
+
+func runGroupCreate(cmd *cobra.Command, args []string) error {
+	store, err := groups.Load()
+	if err != nil {
+		return err
+	}
+	if err := store.Create(args[0]); err != nil {
+		return err
+	}
+
+	if len(args) > 1 {
+		ids, err := resolveGroupMembers(args[1:])
+		if err != nil {
+			return err
+		}
+		if _, err := store.Add(args[0], ids...); err != nil {
+			return err
+		}
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Created group '%s' with %d sandbox(es)\n", args[0], len(args)-1)
+	return nil
+}
+
+func runGroupAdd(cmd *cobra.Command, args []string) error {
+	store, err := groups.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(args[0]); err != nil {
+		return err
+	}
+
+	ids, err := resolveGroupMembers(args[1:])
+	if err != nil {
+		return err
+	}
+	added, err := store.Add(args[0], ids...)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Added %d sandbox(es) to '%s'\n", added, args[0])
+	return nil
+}
+
+func runGroupRemove(cmd *cobra.Command, args []string) error {
+	store, err := groups.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(args[0]); err != nil {
+		return err
+	}
+
+	ids, err := resolveGroupMembers(args[1:])
+	if err != nil {
+		return err
+	}
+	removed, err := store.Remove(args[0], ids...)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Removed %d sandbox(es) from '%s'\n", removed, args[0])
+	return nil
+}
+
+func runGroupDelete(cmd *cobra.Command, args []string) error {
+	store, err := groups.Load()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(args[0]); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Deleted group '%s'\n", args[0])
+	return nil
+}
+
+func runGroupList(cmd *cobra.Command, args []string) error {
+	store, err := groups.Load()
+	if err != nil {
+		return err
+	}
+
+	if groupListJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(store.Groups)
+	}
+
+	if len(store.Groups) == 0 {
+		fmt.Println("No groups found. Run 'cvps group create <name>' to create one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSANDBOXES\tMEMBERS")
+	for _, g := range store.Groups {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", g.Name, len(g.Members), strings.Join(g.Members, ", "))
+	}
+	return w.Flush()
+}
+
+// resolveGroupMembers turns sandbox names or IDs into IDs
+func resolveGroupMembers(refs []string) ([]string, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		id, err := resolveSandboxRef(ctx, client, ref)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// groupSandboxes fetches the members of a group, skipping ones that no longer exist
+func groupSandboxes(ctx context.Context, client *claudevps.Client, name string) ([]claudevps.Sandbox, error) {
+	store, err := groups.Load()
+	if err != nil {
+		return nil, err
+	}
+	g, err := store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	found, errs := sandboxStatuses(ctx, client, g.Members)
+	sandboxes := make([]claudevps.Sandbox, 0, len(g.Members))
+	for i, id := range g.Members {
+		if err := errs[i]; err != nil {
+			if claudevps.IsNotFound(err) {
+				color.Yellow("⚠ Sandbox %s in group '%s' no longer exists", id, name)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get sandbox %s: %w", id, err)
+		}
+		sandboxes = append(sandboxes, *found[i])
+	}
+	return sandboxes, nil
+}
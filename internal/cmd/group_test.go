@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/groups"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// saveTestGroup stores a group of sandboxes under the test's HOME
+func saveTestGroup(t *testing.T, name string, ids ...string) {
+	t.Helper()
+	store, err := groups.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(name, ids...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGroupSandboxes_ReadOnly(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/sandboxes/batch" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": []claudevps.BatchResult{
+			{ID: "sbx-1", Status: http.StatusOK, Sandbox: &claudevps.Sandbox{ID: "sbx-1", Status: "running"}},
+			{ID: "sbx-2", Status: http.StatusOK, Sandbox: &claudevps.Sandbox{ID: "sbx-2", Status: "suspended"}},
+		}})
+	}))
+
+	saveTestGroup(t, "web", "sbx-1", "sbx-2")
+
+	cfg, _ := config.Load()
+	client := newClientFromConfig(cfg, claudevps.WithReadOnly())
+	sandboxes, err := groupSandboxes(context.Background(), client, "web")
+	if err != nil {
+		t.Fatalf("groupSandboxes() error = %v", err)
+	}
+	if len(sandboxes) != 2 || sandboxes[1].Status != "suspended" {
+		t.Errorf("groupSandboxes() = %+v", sandboxes)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// diskPressureThreshold is the fraction of the disk in use at which a sandbox
+// counts as unhealthy
+const diskPressureThreshold = 0.9
+
+// Health states
+const (
+	healthHealthy   = "healthy"
+	healthUnhealthy = "unhealthy"
+	healthInactive  = "inactive"
+)
+
+// healthResult is the combined health of a sandbox: its API status, SSH
+// reachability, disk usage and sync session. Sandboxes that are stopped or
+// still provisioning are inactive and not checked.
+type healthResult struct {
+	State    string   `json:"state"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func (h healthResult) String() string {
+	if len(h.Problems) == 0 {
+		return h.State
+	}
+	return h.State + ": " + strings.Join(h.Problems, "; ")
+}
+
+func (h healthResult) colored() string {
+	switch h.State {
+	case healthHealthy:
+		return color.GreenString(h.String())
+	case healthUnhealthy:
+		return color.RedString(h.String())
+	default:
+		return color.HiBlackString(h.String())
+	}
+}
+
+// lookupSyncSession returns the sandbox's sync session, or nil if there is
+// none or mutagen is not installed
+var lookupSyncSession = func(sandboxID string) *mutagen.SessionStatus {
+	if !mutagen.IsInstalled() {
+		return nil
+	}
+	status, err := mutagen.GetSessionStatus(fmt.Sprintf("cvps-%s", sandboxID))
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// sandboxHealth judges a sandbox from what was gathered about it. probe,
+// metrics and sync may be nil when they are unknown, in which case they are
+// not held against it.
+func sandboxHealth(s *claudevps.Sandbox, probe *probeResult, metrics *claudevps.SandboxMetrics, sync *mutagen.SessionStatus) healthResult {
+	if isFailedStatus(s.Status) {
+		problem := "status " + s.Status
+		if summary := failureSummary(s); summary != "" {
+			problem += ": " + summary
+		}
+		return healthResult{State: healthUnhealthy, Problems: []string{problem}}
+	}
+	if !isRunningStatus(s.Status) {
+		return healthResult{State: healthInactive}
+	}
+
+	var problems []string
+	// Routes that need a ProxyCommand can't be probed directly
+	if probe != nil && !probe.Reachable && !s.Connectivity.SSHProxyRequired {
+		problems = append(problems, "ssh unreachable: "+probe.Error)
+	}
+	if metrics != nil && metrics.DiskTotalBytes > 0 {
+		used := float64(metrics.DiskUsedBytes) / float64(metrics.DiskTotalBytes)
+		if used >= diskPressureThreshold {
+			problems = append(problems, fmt.Sprintf("disk %.0f%% full", used*100))
+		}
+	}
+	if sync != nil {
+		state := strings.ToLower(sync.Status)
+		if strings.Contains(state, "halted") || strings.Contains(state, "error") {
+			problems = append(problems, "sync "+sync.Status)
+		}
+		if sync.Conflicts > 0 {
+			problems = append(problems, fmt.Sprintf("%d sync conflicts", sync.Conflicts))
+		}
+	}
+
+	if len(problems) > 0 {
+		return healthResult{State: healthUnhealthy, Problems: problems}
+	}
+	return healthResult{State: healthHealthy}
+}
+
+// checkHealth checks every sandbox concurrently, keyed by sandbox ID. probes
+// holds the SSH probes already made for the running sandboxes.
+func checkHealth(ctx context.Context, client *claudevps.Client, sandboxes []claudevps.Sandbox, probes map[string]probeResult) map[string]healthResult {
+	results := make(map[string]healthResult, len(sandboxes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, s := range sandboxes {
+		wg.Add(1)
+		go func(s claudevps.Sandbox) {
+			defer wg.Done()
+
+			var probe *probeResult
+			if p, ok := probes[s.ID]; ok {
+				probe = &p
+			}
+			var metrics *claudevps.SandboxMetrics
+			var session *mutagen.SessionStatus
+			if isRunningStatus(s.Status) {
+				m, err := client.GetSandboxMetrics(ctx, s.ID)
+				if err != nil {
+					debuglog.Printf("disk check of %s skipped: %v", s.ID, err)
+				}
+				metrics = m
+				session = lookupSyncSession(s.ID)
+			}
+
+			result := sandboxHealth(&s, probe, metrics, session)
+			mu.Lock()
+			results[s.ID] = result
+			mu.Unlock()
+		}(s)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// unhealthyError returns the error 'cvps status --health' exits with when any
+// of the sandboxes is unhealthy, or nil
+func unhealthyError(sandboxes []claudevps.Sandbox, health map[string]healthResult) error {
+	var unhealthy []string
+	for _, s := range sandboxes {
+		if health[s.ID].State == healthUnhealthy {
+			unhealthy = append(unhealthy, s.Name)
+		}
+	}
+	switch {
+	case len(unhealthy) == 0:
+		return nil
+	case len(sandboxes) == 1:
+		return fmt.Errorf("sandbox %s is unhealthy: %s", sandboxes[0].Name, strings.Join(health[sandboxes[0].ID].Problems, "; "))
+	default:
+		return fmt.Errorf("%d of %d sandboxes are unhealthy: %s", len(unhealthy), len(sandboxes), strings.Join(unhealthy, ", "))
+	}
+}
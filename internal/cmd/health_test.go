@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSandboxHealth(t *testing.T) {
+	running := &claudevps.Sandbox{Status: "running"}
+	tests := []struct {
+		name    string
+		sandbox *claudevps.Sandbox
+		probe   *probeResult
+		metrics *claudevps.SandboxMetrics
+		sync    *mutagen.SessionStatus
+		want    string
+	}{
+		{"all good", running, &probeResult{Reachable: true}, &claudevps.SandboxMetrics{DiskUsedBytes: 5, DiskTotalBytes: 10}, &mutagen.SessionStatus{Status: "Watching for changes"}, "healthy"},
+		{"nothing known", running, nil, nil, nil, "healthy"},
+		{"stopped", &claudevps.Sandbox{Status: "stopped"}, nil, nil, nil, "inactive"},
+		{"failed", &claudevps.Sandbox{Status: "failed", StatusReason: "quota_exceeded"}, nil, nil, nil, "unhealthy: status failed: quota_exceeded"},
+		{"ssh down", running, &probeResult{Error: "connection refused"}, nil, nil, "unhealthy: ssh unreachable: connection refused"},
+		{"disk full", running, nil, &claudevps.SandboxMetrics{DiskUsedBytes: 95, DiskTotalBytes: 100}, nil, "unhealthy: disk 95% full"},
+		{"sync halted", running, nil, nil, &mutagen.SessionStatus{Status: "Halted on root deletion", Conflicts: 2}, "unhealthy: sync Halted on root deletion; 2 sync conflicts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sandboxHealth(tt.sandbox, tt.probe, tt.metrics, tt.sync).String(); got != tt.want {
+				t.Errorf("sandboxHealth() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	proxied := &claudevps.Sandbox{Status: "running"}
+	proxied.Connectivity.SSHProxyRequired = true
+	if got := sandboxHealth(proxied, &probeResult{Error: "timed out"}, nil, nil); got.State != healthHealthy {
+		t.Errorf("Failed direct probe of a proxied route should not count, got %q", got)
+	}
+}
+
+func TestRunStatus_HealthExitsNonZero(t *testing.T) {
+	// An SSH endpoint that answers with a banner
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+			conn.Close()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes":
+			json.NewEncoder(w).Encode(claudevps.SandboxList{
+				Data: []claudevps.Sandbox{
+					{ID: "sbx-ok", Name: "fine", Status: "running", SSHHost: "127.0.0.1", SSHPort: port},
+					{ID: "sbx-full", Name: "full", Status: "running", SSHHost: "127.0.0.1", SSHPort: port},
+					{ID: "sbx-off", Name: "off", Status: "stopped"},
+				},
+				Total: 3,
+			})
+		case "/sandboxes/sbx-ok/metrics":
+			json.NewEncoder(w).Encode(claudevps.SandboxMetrics{DiskUsedBytes: 1, DiskTotalBytes: 10})
+		case "/sandboxes/sbx-full/metrics":
+			json.NewEncoder(w).Encode(claudevps.SandboxMetrics{DiskUsedBytes: 19, DiskTotalBytes: 20})
+		}
+	}))
+
+	oldLookup := lookupSyncSession
+	lookupSyncSession = func(string) *mutagen.SessionStatus { return nil }
+	defer func() { lookupSyncSession = oldLookup }()
+
+	statusAll, statusHealth, statusOut = true, true, outputJSON
+	defer func() { statusAll, statusHealth, statusOut = false, false, outputTable }()
+
+	out, err := captureStdout(t, func() error { return runStatus(nil, nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 sandboxes are unhealthy: full") {
+		t.Errorf("Expected an unhealthy error naming full, got %v", err)
+	}
+
+	var results []sandboxWithChecks
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", out, err)
+	}
+	states := map[string]string{}
+	for _, r := range results {
+		if r.Probe != nil {
+			t.Errorf("Probe results should only be shown with --probe")
+		}
+		states[r.Name] = r.Health.State
+	}
+	if states["fine"] != healthHealthy || states["full"] != healthUnhealthy || states["off"] != healthInactive {
+		t.Errorf("Unexpected health states: %v", states)
+	}
+}
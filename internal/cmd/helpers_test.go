@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+// setupFakeAPI logs in against a test server running handler and disables
+// snapshot polling delays
+func setupFakeAPI(t *testing.T, handler http.Handler) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.APIBaseURL = server.URL
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	oldInterval := snapshotPollInterval
+	snapshotPollInterval = 0
+	t.Cleanup(func() { snapshotPollInterval = oldInterval })
+}
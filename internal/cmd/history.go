@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/history"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySandbox string
+	historyCommand string
+	historySince   string
+	historyUntil   string
+	historyFailed  bool
+	historyLimit   int
+	historyJSON    bool
+	historyClear   bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the cvps commands run on this machine",
+	Long: `Show the cvps commands run on this machine: when, from which directory,
+against which sandboxes, whether they succeeded and how long they took,
+oldest first.
+
+The history is kept in ~/.cvps/history.jsonl and never leaves the machine.
+Values of flags such as --api-key are not recorded. Turn recording off with
+'cvps config set history false'.`,
+	Example: `  # Everything done to the sandbox "web"
+  cvps history --sandbox web
+
+  # What happened on Tuesday 13 October
+  cvps history --since 2026-10-13 --until 2026-10-14
+
+  # Failed deletes in the last week
+  cvps history --command down --failed --since 7d`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVarP(&historySandbox, "sandbox", "s", "", "only commands run against this sandbox (name or ID)")
+	historyCmd.Flags().StringVar(&historyCommand, "command", "", "only this command, e.g. down or snapshot")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only commands run after this date (2006-01-02, RFC 3339) or within this long (12h, 7d)")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "only commands run before this date or this long ago")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "only commands that failed")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 50, "show at most the last N matching commands (0 for all)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "output in JSON format")
+	historyCmd.Flags().BoolVar(&historyClear, "clear", false, "delete the history")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyClear {
+		if err := history.Clear(); err != nil {
+			return err
+		}
+		fmt.Println("✓ History cleared")
+		return nil
+	}
+	if historyLimit < 0 {
+		return fmt.Errorf("--limit cannot be negative")
+	}
+
+	now := time.Now()
+	filter := history.Filter{
+		Sandbox: historySandbox,
+		Command: strings.Join(strings.Fields(historyCommand), " "),
+		Failed:  historyFailed,
+	}
+	var err error
+	if filter.Since, err = parseHistoryTime(historySince, now); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseHistoryTime(historyUntil, now); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+	var matched []history.Entry
+	for _, e := range entries {
+		if filter.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	if historyLimit > 0 && len(matched) > historyLimit {
+		matched = matched[len(matched)-historyLimit:]
+	}
+
+	if historyJSON {
+		if matched == nil {
+			matched = []history.Entry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matched)
+	}
+	if len(matched) == 0 {
+		fmt.Println("No commands recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCOMMAND\tSANDBOX\tRESULT\tDURATION")
+	for _, e := range matched {
+		result := color.GreenString("ok")
+		if !e.Success {
+			result = color.RedString("failed")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04"),
+			truncate("cvps "+strings.Join(e.Args, " "), 60),
+			historySandboxNames(e.Sandboxes),
+			result,
+			(time.Duration(e.DurationMS) * time.Millisecond).Round(100*time.Millisecond))
+	}
+	return w.Flush()
+}
+
+// parseHistoryTime parses a --since or --until value: a date, a date and time,
+// or how long ago as a duration or a number of days such as 7d. "" is the
+// zero time.
+func parseHistoryTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02) or a duration (12h, 7d)", s)
+}
+
+func historySandboxNames(sandboxes []history.Sandbox) string {
+	if len(sandboxes) == 0 {
+		return "-"
+	}
+	names := make([]string, len(sandboxes))
+	for i, s := range sandboxes {
+		names[i] = s.Name
+		if names[i] == "" {
+			names[i] = s.ID
+		}
+	}
+	return truncate(strings.Join(names, ","), 30)
+}
+
+// historySandboxes are the sandboxes the running command resolved or created,
+// recorded with it in the history
+var historySandboxes []history.Sandbox
+
+// noteSandbox records that the running command works on a sandbox. name may
+// be "" if it is not known.
+func noteSandbox(id, name string) {
+	if id == "" {
+		return
+	}
+	i := slices.IndexFunc(historySandboxes, func(s history.Sandbox) bool { return s.ID == id })
+	if i < 0 {
+		historySandboxes = append(historySandboxes, history.Sandbox{ID: id, Name: name})
+	} else if name != "" {
+		historySandboxes[i].Name = name
+	}
+}
+
+// recordHistory adds a finished command to the local history
+func recordHistory(c *cobra.Command, args []string, elapsed time.Duration, runErr error) {
+	name := telemetryCommandName(c)
+	if name == "" || c == historyCmd {
+		return
+	}
+	if cfg, err := config.Load(); err == nil && !cfg.HistoryEnabled() {
+		return
+	}
+
+	sandboxes := slices.Clone(historySandboxes)
+	if cached, err := cache.LoadSandboxes(); err == nil && cached != nil {
+		for i, s := range sandboxes {
+			if s.Name != "" {
+				continue
+			}
+			if j := slices.IndexFunc(cached.Sandboxes, func(cs claudevps.Sandbox) bool { return cs.ID == s.ID }); j >= 0 {
+				sandboxes[i].Name = cached.Sandboxes[j].Name
+			}
+		}
+	}
+
+	e := history.Entry{
+		Time:       time.Now().Add(-elapsed).UTC(),
+		Command:    name,
+		Args:       redactArgs(c, args),
+		Sandboxes:  sandboxes,
+		Success:    runErr == nil,
+		DurationMS: elapsed.Milliseconds(),
+	}
+	e.Dir, _ = os.Getwd()
+	if runErr != nil {
+		e.Error = truncate(runErr.Error(), 200)
+	}
+	if err := history.Append(e); err != nil {
+		debuglog.Printf("history: %v", err)
+	}
+}
+
+// redactArgs replaces the values of flags that carry credentials, the value
+// given to 'config set' for a credential key, and credentials in URLs
+func redactArgs(c *cobra.Command, args []string) []string {
+	out := slices.Clone(args)
+	for i := range out {
+		out[i] = stripURLUserinfo(out[i])
+	}
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+		if c == configSetCmd && (slices.Contains(secretKeys, arg) || sensitiveFlag(arg)) && i+1 < len(out) {
+			i++
+			out[i] = "***"
+			continue
+		}
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			continue
+		}
+		name, _, hasValue := strings.Cut(name, "=")
+		if !sensitiveFlag(name) {
+			continue
+		}
+		if hasValue {
+			out[i] = "--" + name + "=***"
+			continue
+		}
+		if f := c.Flags().Lookup(name); f != nil && f.Value.Type() != "bool" && i+1 < len(out) {
+			i++
+			out[i] = "***"
+		}
+	}
+	return out
+}
+
+// stripURLUserinfo removes the user and password from arg if it is a URL,
+// or a --flag=URL, as they are often a token
+func stripURLUserinfo(arg string) string {
+	prefix, value := "", arg
+	if strings.HasPrefix(arg, "-") {
+		name, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			return arg
+		}
+		prefix, value = name+"=", v
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.User == nil {
+		return arg
+	}
+	u.User = nil
+	return prefix + u.String()
+}
+
+// sensitiveFlag reports whether a flag's value may be a credential
+func sensitiveFlag(name string) bool {
+	for _, word := range []string{"key", "token", "password", "secret"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookShell   string
+	hookRefresh bool
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Integrate cvps with your shell",
+}
+
+var hookEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print environment exports for the current sandbox",
+	Long: `Print shell statements that export details of the current sandbox:
+
+  CVPS_SANDBOX_ID, CVPS_SANDBOX_NAME, CVPS_SANDBOX_STATUS,
+  CVPS_SSH_HOST, CVPS_SSH_PORT, CVPS_SSH_USER
+
+Outside a project the variables are unset instead, so stale values do not
+linger. Details come from the local sandbox cache unless --refresh is given.`,
+	Example: `  # Load into the current shell
+  eval "$(cvps hook env)"
+
+  # direnv: add this line to .envrc
+  eval "$(cvps hook env --refresh)"
+
+  # fish
+  cvps hook env --shell fish | source`,
+	Args: cobra.NoArgs,
+	RunE: runHookEnv,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookEnvCmd)
+
+	hookEnvCmd.Flags().StringVar(&hookShell, "shell", "bash", "shell syntax (bash|zsh|fish|powershell)")
+	hookEnvCmd.Flags().BoolVar(&hookRefresh, "refresh", false, "fetch sandbox details from the API instead of the cache")
+}
+
+// hookEnvVars lists the exported variables in output order
+var hookEnvVars = []string{
+	"CVPS_SANDBOX_ID", "CVPS_SANDBOX_NAME", "CVPS_SANDBOX_STATUS",
+	"CVPS_SSH_HOST", "CVPS_SSH_PORT", "CVPS_SSH_USER",
+}
+
+func runHookEnv(cmd *cobra.Command, args []string) error {
+	switch hookShell {
+	case "bash", "zsh", "fish", "powershell":
+	default:
+		return fmt.Errorf("unsupported shell %q (use bash, zsh, fish or powershell)", hookShell)
+	}
+
+	env, err := currentSandboxEnv()
+	if err != nil {
+		return err
+	}
+	writeHookEnv(os.Stdout, hookShell, env)
+	return nil
+}
+
+// currentSandboxEnv returns the variables for the current sandbox, or nil
+// outside a project
+func currentSandboxEnv() (map[string]string, error) {
+	c, err := localctx.Load(".")
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.CurrentEntry() == nil {
+		return nil, nil
+	}
+	e := c.CurrentEntry()
+
+	var sandbox *claudevps.Sandbox
+	if hookRefresh {
+		client, err := newAPIClient()
+		if err != nil {
+			return nil, err
+		}
+		if sandbox, err = client.GetSandbox(context.Background(), e.SandboxID); err != nil {
+			return nil, fmt.Errorf("failed to get sandbox: %w", err)
+		}
+	} else if cached, err := cache.LoadSandboxes(); err == nil && cached != nil {
+		sandbox = cached.Find(e.SandboxID)
+	}
+
+	env := map[string]string{
+		"CVPS_SANDBOX_ID":   e.SandboxID,
+		"CVPS_SANDBOX_NAME": e.Name,
+	}
+	if sandbox != nil {
+		env["CVPS_SANDBOX_NAME"] = sandbox.Name
+		env["CVPS_SANDBOX_STATUS"] = sandbox.Status
+		if sandbox.SSHHost != "" {
+			env["CVPS_SSH_HOST"] = sandbox.SSHHost
+			env["CVPS_SSH_PORT"] = strconv.Itoa(sandbox.SSHPort)
+			env["CVPS_SSH_USER"] = sandbox.SSHUser
+		}
+	}
+	return env, nil
+}
+
+// writeHookEnv prints an export for every known variable and an unset for the rest
+func writeHookEnv(w io.Writer, shell string, env map[string]string) {
+	for _, name := range hookEnvVars {
+		value, ok := env[name]
+		switch {
+		case ok && shell == "fish":
+			fmt.Fprintf(w, "set -gx %s %s;\n", name, remote.Quote(value))
+		case ok && shell == "powershell":
+			fmt.Fprintf(w, "$env:%s = '%s'\n", name, strings.ReplaceAll(value, "'", "''"))
+		case ok:
+			fmt.Fprintf(w, "export %s=%s\n", name, remote.Quote(value))
+		case shell == "fish":
+			fmt.Fprintf(w, "set -e %s;\n", name)
+		case shell == "powershell":
+			fmt.Fprintf(w, "Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name)
+		default:
+			fmt.Fprintf(w, "unset %s\n", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestCurrentSandboxEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	if env, err := currentSandboxEnv(); err != nil || env != nil {
+		t.Fatalf("Expected nothing outside a project, got %v, %v", env, err)
+	}
+
+	saveLocalContext("sbx-1", "web")
+	cache.SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-1", Name: "web", Status: "running", SSHHost: "ssh.example.com", SSHPort: 2222, SSHUser: "dev"}})
+
+	env, err := currentSandboxEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if env["CVPS_SANDBOX_ID"] != "sbx-1" || env["CVPS_SSH_HOST"] != "ssh.example.com" || env["CVPS_SSH_PORT"] != "2222" {
+		t.Errorf("Unexpected env: %v", env)
+	}
+}
+
+func TestWriteHookEnv(t *testing.T) {
+	env := map[string]string{"CVPS_SANDBOX_ID": "sbx-1", "CVPS_SANDBOX_NAME": "it's mine"}
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{"export CVPS_SANDBOX_ID=sbx-1\n", `export CVPS_SANDBOX_NAME='it'\''s mine'` + "\n", "unset CVPS_SSH_HOST\n"}},
+		{"fish", []string{"set -gx CVPS_SANDBOX_ID sbx-1;\n", "set -e CVPS_SSH_HOST;\n"}},
+		{"powershell", []string{"$env:CVPS_SANDBOX_NAME = 'it''s mine'\n", "Remove-Item Env:CVPS_SSH_HOST -ErrorAction SilentlyContinue\n"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		writeHookEnv(&buf, tt.shell, env)
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s output missing %q:\n%s", tt.shell, want, buf.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	idleJSON bool
+
+	idleSuspendAfter time.Duration
+	idleDisable      bool
+	idleExemptLabel  string
+)
+
+var idleCmd = &cobra.Command{
+	Use:   "idle [sandbox]",
+	Short: "Show sandbox activity and auto-suspend status",
+	Long: `Show when each sandbox last had SSH, terminal and CPU activity, and when
+auto-suspend will stop it if it stays idle.
+
+Idle running sandboxes are the most common source of wasted spend. Turn on
+auto-suspend with 'cvps idle configure --suspend-after 1h'. To stop a sandbox
+soon after everyone disconnects, busy or not, see 'cvps policy set'.`,
+	Example: `  # Activity of every sandbox
+  cvps idle
+
+  # Activity of one sandbox
+  cvps idle web`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runIdle,
+	ValidArgsFunction: completeSandboxes,
+}
+
+var idleConfigureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Configure auto-suspend of idle sandboxes",
+	Long: `Configure auto-suspend, which stops running sandboxes after a period with no
+SSH, terminal or CPU activity. Stopped sandboxes keep their disk and start
+again with 'cvps up'.
+
+Sandboxes carrying the exempt label, with any value, are never suspended. Set
+it when creating a sandbox with 'cvps up --label <exempt-label>=true'.
+
+Without flags, the current policy is shown.`,
+	Example: `  # Suspend sandboxes idle for an hour
+  cvps idle configure --suspend-after 1h
+
+  # Never suspend sandboxes labelled always-on
+  cvps idle configure --exempt-label always-on
+
+  # Turn auto-suspend off
+  cvps idle configure --disable`,
+	Args: cobra.NoArgs,
+	RunE: runIdleConfigure,
+}
+
+func init() {
+	rootCmd.AddCommand(idleCmd)
+	idleCmd.AddCommand(idleConfigureCmd)
+
+	idleCmd.Flags().BoolVar(&idleJSON, "json", false, "output in JSON format")
+	idleConfigureCmd.Flags().DurationVar(&idleSuspendAfter, "suspend-after", 0, "stop running sandboxes after this long without activity (e.g. 30m, 1h)")
+	idleConfigureCmd.Flags().BoolVar(&idleDisable, "disable", false, "turn auto-suspend off")
+	idleConfigureCmd.Flags().StringVar(&idleExemptLabel, "exempt-label", "", "label that exempts a sandbox from auto-suspend")
+	idleConfigureCmd.MarkFlagsMutuallyExclusive("suspend-after", "disable")
+}
+
+func runIdle(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var activity []claudevps.SandboxActivity
+	if len(args) > 0 {
+		id, err := resolveSandboxRef(ctx, client, args[0])
+		if err != nil {
+			return err
+		}
+		a, err := client.GetSandboxActivity(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get activity: %w", err)
+		}
+		activity = []claudevps.SandboxActivity{*a}
+	} else if activity, err = client.ListSandboxActivity(ctx); err != nil {
+		return fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	if idleJSON {
+		if activity == nil {
+			activity = []claudevps.SandboxActivity{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(activity)
+	}
+
+	policy, err := client.GetIdlePolicy(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get idle policy: %w", err)
+	}
+	printIdlePolicy(policy)
+	fmt.Println()
+
+	if len(activity) == 0 {
+		fmt.Println("No sandboxes found. Run 'cvps up' to create one.")
+		return nil
+	}
+
+	// Longest idle first: those are the ones costing money for nothing
+	now := time.Now()
+	sort.SliceStable(activity, func(i, j int) bool {
+		return idleFor(activity[i], now) > idleFor(activity[j], now)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tLAST SSH\tLAST TERMINAL\tLAST CPU\tCPU\tAUTO-SUSPEND")
+	for _, a := range activity {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.1f%%\t%s\n",
+			a.Name, colorStatus(a.Status),
+			activityAge(a.LastSSHAt, now), activityAge(a.LastTerminalAt, now), activityAge(a.LastCPUActiveAt, now),
+			a.CPUPercent, suspendStatus(a, policy, now))
+	}
+	w.Flush()
+	return nil
+}
+
+// idleFor returns how long a running sandbox has been idle, or 0
+func idleFor(a claudevps.SandboxActivity, now time.Time) time.Duration {
+	if !isRunningStatus(a.Status) || a.IdleSince == "" {
+		return 0
+	}
+	since, err := time.Parse(time.RFC3339, a.IdleSince)
+	if err != nil {
+		return 0
+	}
+	return now.Sub(since)
+}
+
+// activityAge renders an activity timestamp relative to now
+func activityAge(ts string, now time.Time) string {
+	if ts == "" {
+		return "never"
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return formatAge(now.Sub(t))
+}
+
+// suspendStatus describes what auto-suspend will do to a sandbox
+func suspendStatus(a claudevps.SandboxActivity, policy *claudevps.IdlePolicy, now time.Time) string {
+	switch {
+	case !isRunningStatus(a.Status):
+		return "-"
+	case a.Exempt:
+		return "exempt"
+	case !policy.Enabled():
+		return "off"
+	case a.SuspendAt == "":
+		return "active"
+	}
+	at, err := time.Parse(time.RFC3339, a.SuspendAt)
+	if err != nil {
+		return a.SuspendAt
+	}
+	d := at.Sub(now)
+	if d < time.Minute {
+		return color.YellowString("now")
+	}
+	return "in " + shortDuration(d.Round(time.Minute))
+}
+
+// shortDuration formats whole minutes without trailing zero units ("1h",
+// "1h30m", "45m")
+func shortDuration(d time.Duration) string {
+	s := strings.TrimSuffix(d.String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// printIdlePolicy prints a one-line summary of the auto-suspend policy
+func printIdlePolicy(policy *claudevps.IdlePolicy) {
+	if !policy.Enabled() {
+		fmt.Println("Auto-suspend: off (enable with 'cvps idle configure --suspend-after 1h')")
+		return
+	}
+	fmt.Printf("Auto-suspend: after %s idle", shortDuration(policy.SuspendAfter()))
+	if policy.ExemptLabel != "" {
+		fmt.Printf(", except sandboxes labelled %s", policy.ExemptLabel)
+	}
+	fmt.Println()
+}
+
+func runIdleConfigure(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	req := &claudevps.UpdateIdlePolicyRequest{}
+	if flags.Changed("suspend-after") {
+		if idleSuspendAfter < 5*time.Minute {
+			return fmt.Errorf("--suspend-after must be at least 5m")
+		}
+		seconds := int(idleSuspendAfter / time.Second)
+		req.SuspendAfterSeconds = &seconds
+	}
+	if idleDisable {
+		seconds := 0
+		req.SuspendAfterSeconds = &seconds
+	}
+	if flags.Changed("exempt-label") {
+		req.ExemptLabel = &idleExemptLabel
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var policy *claudevps.IdlePolicy
+	if req.SuspendAfterSeconds == nil && req.ExemptLabel == nil {
+		policy, err = client.GetIdlePolicy(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get idle policy: %w", err)
+		}
+	} else {
+		policy, err = client.UpdateIdlePolicy(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to update idle policy: %w", err)
+		}
+		fmt.Print("✓ ")
+	}
+	printIdlePolicy(policy)
+	return nil
+}
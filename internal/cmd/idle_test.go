@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSuspendStatus(t *testing.T) {
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.UTC)
+	on := &claudevps.IdlePolicy{SuspendAfterSeconds: 3600}
+	off := &claudevps.IdlePolicy{}
+
+	tests := []struct {
+		name     string
+		activity claudevps.SandboxActivity
+		policy   *claudevps.IdlePolicy
+		want     string
+	}{
+		{"stopped", claudevps.SandboxActivity{Status: "stopped"}, on, "-"},
+		{"exempt", claudevps.SandboxActivity{Status: "running", Exempt: true}, on, "exempt"},
+		{"policy off", claudevps.SandboxActivity{Status: "running"}, off, "off"},
+		{"in use", claudevps.SandboxActivity{Status: "running"}, on, "active"},
+		{"idle", claudevps.SandboxActivity{Status: "running", SuspendAt: "2024-03-29T18:25:10Z"}, on, "in 25m"},
+		{"overdue", claudevps.SandboxActivity{Status: "running", SuspendAt: "2024-03-29T17:59:00Z"}, on, "now"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suspendStatus(tt.activity, tt.policy, now); !strings.Contains(got, tt.want) {
+				t.Errorf("suspendStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Hour:                     "1h",
+		90 * time.Minute:              "1h30m",
+		45 * time.Minute:              "45m",
+		10*time.Hour + 10*time.Minute: "10h10m",
+	}
+	for d, want := range tests {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestIdleFor(t *testing.T) {
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.UTC)
+	running := claudevps.SandboxActivity{Status: "running", IdleSince: "2024-03-29T16:00:00Z"}
+	if got := idleFor(running, now); got != 2*time.Hour {
+		t.Errorf("idleFor(running) = %s, want 2h", got)
+	}
+	stopped := claudevps.SandboxActivity{Status: "stopped", IdleSince: "2024-03-29T16:00:00Z"}
+	if got := idleFor(stopped, now); got != 0 {
+		t.Errorf("idleFor(stopped) = %s, want 0", got)
+	}
+}
+
+func TestRunIdleConfigure(t *testing.T) {
+	var patch map[string]any
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/idle-policy" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&patch)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.IdlePolicy{SuspendAfterSeconds: 3600, ExemptLabel: "always-on"})
+	}))
+
+	flags := idleConfigureCmd.Flags()
+	flags.Set("suspend-after", "1h")
+	flags.Set("exempt-label", "always-on")
+	defer func() {
+		idleSuspendAfter, idleExemptLabel = 0, ""
+		flags.Lookup("suspend-after").Changed = false
+		flags.Lookup("exempt-label").Changed = false
+	}()
+
+	out, err := captureStdout(t, func() error {
+		return runIdleConfigure(idleConfigureCmd, nil)
+	})
+	if err != nil {
+		t.Fatalf("runIdleConfigure() error = %v", err)
+	}
+	if patch["suspendAfterSeconds"] != float64(3600) || patch["exemptLabel"] != "always-on" {
+		t.Errorf("Unexpected patch: %v", patch)
+	}
+	if !strings.Contains(out, "after 1h idle, except sandboxes labelled always-on") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestRunIdleConfigure_TooShort(t *testing.T) {
+	flags := idleConfigureCmd.Flags()
+	flags.Set("suspend-after", "30s")
+	defer func() {
+		idleSuspendAfter = 0
+		flags.Lookup("suspend-after").Changed = false
+	}()
+
+	if err := runIdleConfigure(idleConfigureCmd, nil); err == nil || !strings.Contains(err.Error(), "at least 5m") {
+		t.Errorf("runIdleConfigure() error = %v, want minimum error", err)
+	}
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+// imageBuildPollInterval is how often build status is checked once the log
+// stream has ended
+var imageBuildPollInterval = 2 * time.Second
+
+var (
+	imageName       string
+	imageTag        string
+	imageDockerfile string
+	imageGitURL     string
+	imageGitRef     string
+	imageBuildArgs  []string
+	imageJSON       bool
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Build and manage custom sandbox images",
+}
+
+var imageBuildCmd = &cobra.Command{
+	Use:   "build [context-dir]",
+	Short: "Build a custom image for 'cvps up --image'",
+	Long: `Build an image on the ClaudeVPS build service so toolchains are baked in
+instead of installed on every boot.
+
+The build context is a local directory (default: the current directory),
+uploaded as a compressed archive that honors .dockerignore, or a git
+repository given with --git. Build output is streamed as it runs, and the
+finished image is registered for use with 'cvps up --image'.`,
+	Example: `  # Build the Dockerfile in the current directory
+  cvps image build --name toolchain
+
+  # Build from a git repository with a build argument
+  cvps image build --name toolchain --git https://github.com/acme/images --ref main \
+    --file go/Dockerfile --build-arg GO_VERSION=1.23`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImageBuild,
+}
+
+var imageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List custom images",
+	Args:  cobra.NoArgs,
+	RunE:  runImageList,
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+	imageCmd.AddCommand(imageBuildCmd)
+	imageCmd.AddCommand(imageListCmd)
+
+	imageBuildCmd.Flags().StringVar(&imageName, "name", "", "image name (required)")
+	imageBuildCmd.Flags().StringVar(&imageTag, "tag", "", "image tag (default latest)")
+	imageBuildCmd.Flags().StringVarP(&imageDockerfile, "file", "f", "Dockerfile", "Dockerfile path relative to the context")
+	imageBuildCmd.Flags().StringVar(&imageGitURL, "git", "", "build from a git repository instead of a local directory")
+	imageBuildCmd.Flags().StringVar(&imageGitRef, "ref", "", "git branch, tag or commit to build (with --git)")
+	imageBuildCmd.Flags().StringArrayVar(&imageBuildArgs, "build-arg", nil, "build argument as KEY=VALUE (repeatable)")
+	imageBuildCmd.MarkFlagRequired("name")
+	addProgressFlag(imageBuildCmd)
+	imageListCmd.Flags().BoolVar(&imageJSON, "json", false, "output in JSON format")
+}
+
+func runImageBuild(cmd *cobra.Command, args []string) error {
+	if imageGitURL != "" && len(args) > 0 {
+		return fmt.Errorf("provide either a context directory or --git, not both")
+	}
+	if imageGitRef != "" && imageGitURL == "" {
+		return fmt.Errorf("--ref requires --git")
+	}
+	if err := validProgress(); err != nil {
+		return err
+	}
+	buildArgs, err := parseBuildArgs(imageBuildArgs)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	req := &claudevps.BuildImageRequest{
+		Name:       imageName,
+		Tag:        imageTag,
+		Dockerfile: filepath.ToSlash(imageDockerfile),
+		GitURL:     imageGitURL,
+		GitRef:     imageGitRef,
+		BuildArgs:  buildArgs,
+	}
+	if imageGitURL == "" {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		if req.ContextID, err = uploadBuildContext(ctx, client, dir, imageDockerfile); err != nil {
+			return err
+		}
+	}
+
+	build, err := client.BuildImage(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to start build: %w", err)
+	}
+	fmt.Printf("Building %s (build %s)\n", build.Name, build.ID)
+
+	// With --progress json each line of the build log is an event
+	var log io.Writer = os.Stdout
+	var logEvents *progressLogWriter
+	if jsonProgress() {
+		logEvents = &progressLogWriter{stage: "build"}
+		log = logEvents
+	}
+	emitStage("build", progressRunning, "")
+	build, err = followImageBuild(ctx, client, build, log)
+	if logEvents != nil {
+		logEvents.Flush()
+	}
+	if err != nil {
+		emitStage("build", progressFailed, err.Error())
+		return err
+	}
+	if build.Status == claudevps.ImageBuildStatusFailed {
+		emitStage("build", progressFailed, build.Error)
+		if build.Error != "" {
+			return fmt.Errorf("image build failed: %s", build.Error)
+		}
+		return fmt.Errorf("image build failed")
+	}
+	emitStage("build", progressDone, build.Image)
+
+	fmt.Printf("\n✓ Built image %s. Use it with: cvps up --image %s\n", build.Image, build.Image)
+	return nil
+}
+
+// uploadBuildContext archives dir, leaving out .dockerignore matches, and
+// uploads it as a build context
+func uploadBuildContext(ctx context.Context, client *claudevps.Client, dir, dockerfile string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, dockerfile)); err != nil {
+		return "", fmt.Errorf("no %s in %s", dockerfile, dir)
+	}
+
+	patterns, err := readDockerignore(dir)
+	if err != nil {
+		return "", err
+	}
+	files, err := migration.NewScanner(dir, append(patterns, ".git")).Scan()
+	if err != nil {
+		return "", fmt.Errorf("failed to scan build context: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "cvps-context-*.tar.gz")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fmt.Printf("Packing build context (%d files, %s)\n", files.Count, formatBytes(files.TotalSize))
+	if err := migration.WriteArchive(ctx, tmp, files, nil); err != nil {
+		return "", fmt.Errorf("failed to pack build context: %w", err)
+	}
+	sum, err := fileSHA256(tmp)
+	if err != nil {
+		return "", err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	bar := newTransferBar(info.Size(), "Uploading context", "context_upload")
+	id, err := client.UploadImageContext(ctx, &claudevps.StartImageContextRequest{Size: info.Size(), SHA256: sum}, tmp, func(n int64) {
+		bar.Set64(n)
+	})
+	if err != nil {
+		fmt.Println()
+		emitStage("context_upload", progressFailed, err.Error())
+		return "", fmt.Errorf("failed to upload build context: %w", err)
+	}
+	bar.Finish()
+	fmt.Println()
+	return id, nil
+}
+
+// followImageBuild copies the build log to w and returns the finished build
+func followImageBuild(ctx context.Context, client *claudevps.Client, build *claudevps.ImageBuild, w io.Writer) (*claudevps.ImageBuild, error) {
+	logs, err := client.ImageBuildLogs(ctx, build.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream build logs: %w", err)
+	}
+	_, copyErr := io.Copy(w, logs)
+	logs.Close()
+
+	// The stream can end before the final status is recorded
+	for {
+		if build, err = client.GetImageBuild(ctx, build.ID); err != nil {
+			return nil, fmt.Errorf("failed to get build status: %w", err)
+		}
+		if build.Done() {
+			return build, nil
+		}
+		if copyErr != nil {
+			return nil, fmt.Errorf("build log stream interrupted: %w", copyErr)
+		}
+		time.Sleep(imageBuildPollInterval)
+	}
+}
+
+// readDockerignore returns the patterns of dir/.dockerignore, if any
+func readDockerignore(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			// Re-inclusion is not supported by the scanner; keep the file
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(strings.TrimPrefix(line, "/"), "/"))
+	}
+	return patterns, scanner.Err()
+}
+
+// parseBuildArgs parses KEY=VALUE build arguments
+func parseBuildArgs(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid build argument %q, expected KEY=VALUE", arg)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+func runImageList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	images, err := client.ListImages(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list images: %w", err)
+	}
+
+	if imageJSON {
+		if images == nil {
+			images = []claudevps.Image{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(images)
+	}
+
+	if len(images) == 0 {
+		fmt.Println("No custom images. Build one with 'cvps image build --name <name>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTAG\tSIZE\tCREATED\tREF")
+	for _, img := range images {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", img.Name, img.Tag, formatBytes(img.SizeBytes), formatTime(img.CreatedAt), img.Ref)
+	}
+	w.Flush()
+	return nil
+}
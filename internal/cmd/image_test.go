@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestParseBuildArgs(t *testing.T) {
+	args, err := parseBuildArgs([]string{"GO_VERSION=1.23", "EMPTY=", "URL=a=b"})
+	if err != nil {
+		t.Fatalf("parseBuildArgs() error = %v", err)
+	}
+	if args["GO_VERSION"] != "1.23" || args["EMPTY"] != "" || args["URL"] != "a=b" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	for _, bad := range []string{"NOVALUE", "=x"} {
+		if _, err := parseBuildArgs([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestReadDockerignore(t *testing.T) {
+	dir := t.TempDir()
+	content := "# comment\n\nnode_modules/\n/dist\n*.log\n!keep.log\n"
+	if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := readDockerignore(dir)
+	if err != nil {
+		t.Fatalf("readDockerignore() error = %v", err)
+	}
+	want := []string{"node_modules", "dist", "*.log"}
+	if strings.Join(patterns, ",") != strings.Join(want, ",") {
+		t.Errorf("patterns = %v, want %v", patterns, want)
+	}
+
+	if patterns, err := readDockerignore(t.TempDir()); err != nil || patterns != nil {
+		t.Errorf("expected no patterns without .dockerignore, got %v, %v", patterns, err)
+	}
+}
+
+func TestRunImageBuild_LocalContext(t *testing.T) {
+	var uploaded []byte
+	var req claudevps.BuildImageRequest
+	statusPolls := 0
+
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/images/contexts":
+			json.NewEncoder(w).Encode(claudevps.UploadSession{ID: "ctx-1"})
+		case r.Method == "PUT" && r.URL.Path == "/images/contexts/ctx-1":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, data...)
+			json.NewEncoder(w).Encode(claudevps.UploadSession{ID: "ctx-1", Offset: int64(len(uploaded))})
+		case r.Method == "POST" && r.URL.Path == "/images/contexts/ctx-1/complete":
+			w.Write([]byte("{}"))
+		case r.Method == "POST" && r.URL.Path == "/images/builds":
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(claudevps.ImageBuild{ID: "bld-1", Name: req.Name, Status: claudevps.ImageBuildStatusQueued})
+		case r.Method == "GET" && r.URL.Path == "/images/builds/bld-1/logs":
+			w.Write([]byte("Step 1/1 : FROM ubuntu\n"))
+		case r.Method == "GET" && r.URL.Path == "/images/builds/bld-1":
+			statusPolls++
+			build := claudevps.ImageBuild{ID: "bld-1", Status: claudevps.ImageBuildStatusBuilding}
+			if statusPolls > 1 {
+				build.Status = claudevps.ImageBuildStatusSucceeded
+				build.Image = "images.claudevps.dev/acme/toolchain:latest"
+			}
+			json.NewEncoder(w).Encode(build)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	oldInterval := imageBuildPollInterval
+	imageBuildPollInterval = 0
+	defer func() { imageBuildPollInterval = oldInterval }()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"Dockerfile":         "FROM ubuntu\n",
+		".dockerignore":      "secrets\n",
+		"setup.sh":           "make\n",
+		"secrets/token":      "hunter2",
+		".git/HEAD":          "ref: refs/heads/main\n",
+		"scripts/install.sh": "apt-get install -y make\n",
+	} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imageName = "toolchain"
+	imageBuildArgs = []string{"GO_VERSION=1.23"}
+	defer func() { imageName, imageBuildArgs = "", nil }()
+
+	output, err := captureStdout(t, func() error {
+		return runImageBuild(nil, []string{dir})
+	})
+	if err != nil {
+		t.Fatalf("runImageBuild() error = %v", err)
+	}
+
+	if req.ContextID != "ctx-1" || req.Name != "toolchain" || req.Dockerfile != "Dockerfile" || req.BuildArgs["GO_VERSION"] != "1.23" {
+		t.Errorf("Unexpected build request: %+v", req)
+	}
+	if !strings.Contains(output, "Step 1/1") || !strings.Contains(output, "cvps up --image images.claudevps.dev/acme/toolchain:latest") {
+		t.Errorf("Unexpected output: %q", output)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(uploaded))
+	if err != nil {
+		t.Fatalf("uploaded context is not gzipped: %v", err)
+	}
+	var names []string
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid context archive: %v", err)
+		}
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	want := ".dockerignore,Dockerfile,scripts/install.sh,setup.sh"
+	if strings.Join(names, ",") != want {
+		t.Errorf("context files = %v, want %s", names, want)
+	}
+}
+
+func TestRunImageBuild_Failed(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/images/builds":
+			json.NewEncoder(w).Encode(claudevps.ImageBuild{ID: "bld-1", Status: claudevps.ImageBuildStatusQueued})
+		case "/images/builds/bld-1/logs":
+			w.Write([]byte("RUN make: exit code 2\n"))
+		case "/images/builds/bld-1":
+			json.NewEncoder(w).Encode(claudevps.ImageBuild{ID: "bld-1", Status: claudevps.ImageBuildStatusFailed, Error: "step 2 exited with code 2"})
+		}
+	}))
+
+	imageName = "toolchain"
+	imageGitURL = "https://github.com/acme/images"
+	defer func() { imageName, imageGitURL = "", "" }()
+
+	_, err := captureStdout(t, func() error {
+		return runImageBuild(nil, nil)
+	})
+	if err == nil || !strings.Contains(err.Error(), "step 2 exited with code 2") {
+		t.Fatalf("expected build failure, got %v", err)
+	}
+}
+
+func TestRunImageBuild_ContextAndGit(t *testing.T) {
+	imageGitURL = "https://github.com/acme/images"
+	defer func() { imageGitURL = "" }()
+
+	if err := runImageBuild(nil, []string{"."}); err == nil {
+		t.Fatal("expected error when both a context directory and --git are given")
+	}
+}
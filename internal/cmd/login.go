@@ -3,17 +3,23 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
+// loginMFAAttempts is how many two-factor codes may be tried
+const loginMFAAttempts = 3
+
 var (
 	loginAPIKey string
 )
@@ -65,7 +71,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 }
 
 func loginWithAPIKey(cfg *config.Config, apiKey string) error {
-	client := api.NewClient(cfg.APIBaseURL, apiKey)
+	client := claudevps.NewClient(cfg.APIBaseURL, apiKey, cliClientOptions()...)
 
 	// Validate the API key
 	user, err := client.GetCurrentUser(context.Background())
@@ -82,8 +88,33 @@ func loginWithAPIKey(cfg *config.Config, apiKey string) error {
 	return nil
 }
 
+// confirmVerificationPhrase asks whether the browser shows phrase too
+func confirmVerificationPhrase(in *bufio.Reader, phrase string) bool {
+	fmt.Printf("Does the browser show %q? [y/N]: ", phrase)
+	input, _ := in.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+// completeMFAChallenge asks for a one-time code until the server accepts one
+func completeMFAChallenge(ctx context.Context, client *claudevps.Client, in *bufio.Reader, mfaToken string) (*claudevps.TokenResponse, error) {
+	for attempt := 1; ; attempt++ {
+		fmt.Print("Two-factor code: ")
+		code, err := in.ReadString('\n')
+		code = strings.TrimSpace(code)
+		if code == "" && err != nil {
+			return nil, fmt.Errorf("no two-factor code entered")
+		}
+		token, err := client.CompleteMFAChallenge(ctx, mfaToken, code)
+		if err == nil || err.Error() != "invalid_otp" || attempt == loginMFAAttempts {
+			return token, err
+		}
+		fmt.Println("Invalid code, try again.")
+	}
+}
+
 func loginWithOAuth(cfg *config.Config) error {
-	client := api.NewClient(cfg.APIBaseURL, "")
+	client := claudevps.NewClient(cfg.APIBaseURL, "", cliClientOptions()...)
 
 	// Initiate device authorization flow
 	deviceAuth, err := client.InitiateDeviceAuth(context.Background())
@@ -95,19 +126,41 @@ func loginWithOAuth(cfg *config.Config) error {
 	fmt.Printf("To authenticate, visit:\n")
 	fmt.Printf("  %s\n\n", deviceAuth.VerificationURI)
 	fmt.Printf("And enter code: %s\n\n", deviceAuth.UserCode)
+	if deviceAuth.VerificationPhrase != "" {
+		fmt.Printf("The browser will show this verification phrase:\n  ")
+		color.New(color.Bold).Println(deviceAuth.VerificationPhrase)
+		fmt.Println()
+	}
 
 	// Try to open browser automatically
 	if err := browser.OpenURL(deviceAuth.VerificationURIComplete); err != nil {
 		fmt.Println("(Could not open browser automatically)")
 	}
 
-	fmt.Println("Waiting for authentication...")
-
-	// Poll for completion
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deviceAuth.ExpiresIn)*time.Second)
 	defer cancel()
+	in := bufio.NewReader(os.Stdin)
+
+	if deviceAuth.VerificationPhrase != "" {
+		if !confirmVerificationPhrase(in, deviceAuth.VerificationPhrase) {
+			if err := client.DenyDeviceAuth(ctx, deviceAuth.DeviceCode); err != nil {
+				debuglog.Printf("login: failed to deny device code: %v", err)
+			}
+			return fmt.Errorf("login cancelled: the verification phrases did not match. Only approve logins you started yourself")
+		}
+		if err := client.ConfirmDeviceAuth(ctx, deviceAuth.DeviceCode, deviceAuth.VerificationPhrase); err != nil {
+			return fmt.Errorf("failed to confirm login: %w", err)
+		}
+	}
 
+	fmt.Println("Waiting for authentication...")
+
+	// Poll for completion
 	token, err := client.PollDeviceAuth(ctx, deviceAuth.DeviceCode, time.Duration(deviceAuth.Interval)*time.Second)
+	var mfaErr *claudevps.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		token, err = completeMFAChallenge(ctx, client, in, mfaErr.MFAToken)
+	}
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
@@ -118,7 +171,7 @@ func loginWithOAuth(cfg *config.Config) error {
 	}
 
 	// Fetch user info
-	client = api.NewClientWithToken(cfg.APIBaseURL, token.AccessToken)
+	client = claudevps.NewClientWithToken(cfg.APIBaseURL, token.AccessToken, cliClientOptions()...)
 	user, err := client.GetCurrentUser(context.Background())
 	if err != nil {
 		fmt.Println("✓ Logged in successfully")
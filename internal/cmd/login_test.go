@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestConfirmVerificationPhrase(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		var got bool
+		captureStdout(t, func() error {
+			got = confirmVerificationPhrase(bufio.NewReader(strings.NewReader(input)), "amber falcon river")
+			return nil
+		})
+		if got != want {
+			t.Errorf("confirmVerificationPhrase(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestCompleteMFAChallenge(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("otp") != "123456" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_otp"})
+			return
+		}
+		json.NewEncoder(w).Encode(claudevps.TokenResponse{AccessToken: "test-access-token"})
+	}))
+	defer server.Close()
+	client := claudevps.NewClient(server.URL, "")
+
+	// A mistyped code is retried
+	in := bufio.NewReader(strings.NewReader("111111\n123456\n"))
+	var token *claudevps.TokenResponse
+	_, err := captureStdout(t, func() error {
+		var err error
+		token, err = completeMFAChallenge(context.Background(), client, in, "mfa-1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("completeMFAChallenge() error = %v", err)
+	}
+	if token.AccessToken != "test-access-token" || attempts != 2 {
+		t.Errorf("token = %+v after %d attempts", token, attempts)
+	}
+
+	// Attempts are limited
+	attempts = 0
+	in = bufio.NewReader(strings.NewReader(strings.Repeat("000000\n", 5)))
+	_, err = captureStdout(t, func() error {
+		_, err := completeMFAChallenge(context.Background(), client, in, "mfa-1")
+		return err
+	})
+	if err == nil || attempts != loginMFAAttempts {
+		t.Errorf("completeMFAChallenge() error = %v after %d attempts, want failure after %d", err, attempts, loginMFAAttempts)
+	}
+}
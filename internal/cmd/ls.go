@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsLong bool
+	lsAll  bool
+	lsJSON bool
+)
+
+var lsCmd = &cobra.Command{
+	Use:   "ls [[sandbox:]path]",
+	Short: "List files in a sandbox",
+	Long: `List a directory in a sandbox without opening an interactive session.
+
+Paths default to the sandbox user's home directory. Prefix the path with
+"sandbox:" to target a sandbox other than the current context.`,
+	Example: `  # List the home directory of the current sandbox
+  cvps ls
+
+  # Long listing of a build output directory
+  cvps ls -l /workspace/dist
+
+  # Machine-readable listing from a named sandbox
+  cvps ls myproject:/workspace --json`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runLs,
+	ValidArgsFunction: completeLsArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+
+	lsCmd.Flags().BoolVarP(&lsLong, "long", "l", false, "show mode, size and modification time")
+	lsCmd.Flags().BoolVarP(&lsAll, "all", "a", false, "include hidden files")
+	lsCmd.Flags().BoolVar(&lsJSON, "json", false, "output in JSON format")
+}
+
+// remoteEntry is the JSON shape of a listed file
+type remoteEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	IsDir   bool      `json:"isDir"`
+	ModTime time.Time `json:"modTime"`
+}
+
+func runLs(cmd *cobra.Command, args []string) error {
+	target := remotePath{}
+	if len(args) > 0 {
+		if parsed, ok := parseRemotePath(args[0]); ok {
+			target = parsed
+		} else {
+			target.Path = args[0]
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, target.Sandbox)
+	if err != nil {
+		return err
+	}
+
+	fs, err := openSandboxFS(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	dir, err := fs.Resolve(target.Path)
+	if err != nil {
+		return err
+	}
+
+	info, err := fs.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	var infos []os.FileInfo
+	if info.IsDir() {
+		if infos, err = fs.ReadDir(dir); err != nil {
+			return err
+		}
+	} else {
+		infos = []os.FileInfo{info}
+		dir = path.Dir(dir)
+	}
+
+	entries := remoteEntries(dir, infos, lsAll)
+
+	if lsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if !lsLong {
+		for _, e := range entries {
+			fmt.Println(displayName(e))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Mode, formatBytes(e.Size), e.ModTime.Local().Format("2006-01-02 15:04"), displayName(e))
+	}
+	w.Flush()
+	return nil
+}
+
+// remoteEntries converts directory entries to their listing form, sorted by name
+func remoteEntries(dir string, infos []os.FileInfo, all bool) []remoteEntry {
+	entries := make([]remoteEntry, 0, len(infos))
+	for _, fi := range infos {
+		if !all && strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		entries = append(entries, remoteEntry{
+			Name:    fi.Name(),
+			Path:    path.Join(dir, fi.Name()),
+			Size:    fi.Size(),
+			Mode:    fi.Mode().String(),
+			IsDir:   fi.IsDir(),
+			ModTime: fi.ModTime().UTC(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func displayName(e remoteEntry) string {
+	if e.IsDir {
+		return e.Name + "/"
+	}
+	return e.Name
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type namedFileInfo struct {
+	stubFileInfo
+	name string
+	dir  bool
+}
+
+func (n namedFileInfo) Name() string { return n.name }
+func (n namedFileInfo) IsDir() bool  { return n.dir }
+
+func TestRemoteEntries(t *testing.T) {
+	mtime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	infos := []os.FileInfo{
+		namedFileInfo{stubFileInfo: stubFileInfo{size: 3, mtime: mtime}, name: "zeta.txt"},
+		namedFileInfo{stubFileInfo: stubFileInfo{mtime: mtime}, name: "dist", dir: true},
+		namedFileInfo{stubFileInfo: stubFileInfo{size: 1, mtime: mtime}, name: ".env"},
+	}
+
+	entries := remoteEntries("/workspace", infos, false)
+	if len(entries) != 2 {
+		t.Fatalf("remoteEntries() returned %d entries, want 2 (hidden excluded)", len(entries))
+	}
+	if entries[0].Name != "dist" || entries[1].Name != "zeta.txt" {
+		t.Fatalf("remoteEntries() order = %s, %s; want dist, zeta.txt", entries[0].Name, entries[1].Name)
+	}
+	if entries[1].Path != "/workspace/zeta.txt" {
+		t.Fatalf("Path = %q, want /workspace/zeta.txt", entries[1].Path)
+	}
+	if displayName(entries[0]) != "dist/" {
+		t.Fatalf("displayName() = %q, want dist/", displayName(entries[0]))
+	}
+
+	all := remoteEntries("/workspace", infos, true)
+	if len(all) != 3 || all[0].Name != ".env" {
+		t.Fatalf("remoteEntries(all) = %+v, want .env first of 3", all)
+	}
+}
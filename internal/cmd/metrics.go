@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsListen   string
+	metricsInterval time.Duration
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Export sandbox metrics",
+}
+
+var metricsServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve sandbox metrics for Prometheus",
+	Long: `Periodically list sandboxes and their resource usage and expose them as
+Prometheus gauges on a local HTTP endpoint.
+
+Exported metrics include sandbox counts by status, allocated and used CPU,
+memory and disk, and cost to date. Point a Prometheus scrape job at
+http://<listen>/metrics.`,
+	Example: `  # Serve on the default port
+  cvps metrics serve
+
+  # Listen on all interfaces and refresh every 30 seconds
+  cvps metrics serve --listen :9464 --interval 30s`,
+	Args: cobra.NoArgs,
+	RunE: runMetricsServe,
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+	metricsCmd.AddCommand(metricsServeCmd)
+
+	metricsServeCmd.Flags().StringVar(&metricsListen, "listen", "127.0.0.1:9464", "address to serve metrics on")
+	metricsServeCmd.Flags().DurationVar(&metricsInterval, "interval", time.Minute, "how often to poll the API")
+}
+
+func runMetricsServe(cmd *cobra.Command, args []string) error {
+	if metricsInterval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	exporter := &metricsExporter{}
+	exporter.refresh(ctx, client)
+
+	ln, err := net.Listen("tcp", metricsListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", metricsListen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		ticker := time.NewTicker(metricsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				exporter.refresh(ctx, client)
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving metrics on http://%s/metrics (refreshing every %s)\n", ln.Addr(), metricsInterval)
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// fleetSample is one poll of all sandboxes and their metrics
+type fleetSample struct {
+	Sandboxes []claudevps.Sandbox
+	Metrics   map[string]*claudevps.SandboxMetrics
+	Errors    int
+	Duration  time.Duration
+	Time      time.Time
+}
+
+// metricsExporter serves the most recently rendered sample
+type metricsExporter struct {
+	mu   sync.RWMutex
+	body []byte
+}
+
+func (e *metricsExporter) refresh(ctx context.Context, client *claudevps.Client) {
+	sample := collectFleet(ctx, client)
+
+	var buf bytes.Buffer
+	renderPrometheus(&buf, sample)
+
+	e.mu.Lock()
+	e.body = buf.Bytes()
+	e.mu.Unlock()
+}
+
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	body := e.body
+	e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(body)
+}
+
+// collectFleet lists sandboxes and fetches metrics for running ones concurrently.
+// Failures are logged and counted rather than aborting the poll.
+func collectFleet(ctx context.Context, client *claudevps.Client) fleetSample {
+	start := time.Now()
+	sample := fleetSample{Metrics: make(map[string]*claudevps.SandboxMetrics), Time: start}
+
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		debuglog.Printf("metrics: failed to list sandboxes: %v", err)
+		sample.Errors++
+		sample.Duration = time.Since(start)
+		return sample
+	}
+	sample.Sandboxes = sandboxes
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, s := range sandboxes {
+		if !isRunningStatus(s.Status) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			m, err := client.GetSandboxMetrics(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				debuglog.Printf("metrics: failed to get metrics for %s: %v", id, err)
+				sample.Errors++
+				return
+			}
+			sample.Metrics[id] = m
+		}(s.ID)
+	}
+	wg.Wait()
+
+	sample.Duration = time.Since(start)
+	return sample
+}
+
+// promWriter writes metrics in the Prometheus text exposition format
+type promWriter struct {
+	w io.Writer
+}
+
+func (p promWriter) header(name, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func (p promWriter) sample(name string, value float64, labels ...string) {
+	fmt.Fprint(p.w, name)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], escapeLabelValue(labels[i+1])))
+		}
+		fmt.Fprintf(p.w, "{%s}", strings.Join(pairs, ","))
+	}
+	fmt.Fprintf(p.w, " %g\n", value)
+}
+
+// escapeLabelValue drops characters that %q would escape differently from Prometheus
+func escapeLabelValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func renderPrometheus(w io.Writer, sample fleetSample) {
+	p := promWriter{w}
+
+	counts := make(map[string]int)
+	for _, s := range sample.Sandboxes {
+		counts[s.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	p.header("cvps_sandboxes", "Number of sandboxes by status.")
+	for _, status := range statuses {
+		p.sample("cvps_sandboxes", float64(counts[status]), "status", status)
+	}
+
+	sandboxes := append([]claudevps.Sandbox(nil), sample.Sandboxes...)
+	sort.Slice(sandboxes, func(i, j int) bool { return sandboxes[i].ID < sandboxes[j].ID })
+
+	p.header("cvps_sandbox_info", "Sandbox metadata, always 1.")
+	for _, s := range sandboxes {
+		p.sample("cvps_sandbox_info", 1, "id", s.ID, "name", s.Name, "status", s.Status)
+	}
+
+	allocated := []struct {
+		name, help string
+		value      func(claudevps.Sandbox) float64
+	}{
+		{"cvps_sandbox_cpu_cores", "Allocated CPU cores.", func(s claudevps.Sandbox) float64 { return float64(s.CPUCores) }},
+		{"cvps_sandbox_memory_limit_bytes", "Allocated memory in bytes.", func(s claudevps.Sandbox) float64 { return float64(s.MemoryGB) * (1 << 30) }},
+		{"cvps_sandbox_storage_limit_bytes", "Allocated storage in bytes.", func(s claudevps.Sandbox) float64 { return float64(s.StorageGB) * (1 << 30) }},
+	}
+	for _, m := range allocated {
+		p.header(m.name, m.help)
+		for _, s := range sandboxes {
+			p.sample(m.name, m.value(s), "id", s.ID, "name", s.Name)
+		}
+	}
+
+	usage := []struct {
+		name, help string
+		value      func(*claudevps.SandboxMetrics) float64
+	}{
+		{"cvps_sandbox_cpu_usage_percent", "CPU usage as a percentage of allocated cores.", func(m *claudevps.SandboxMetrics) float64 { return m.CPUPercent }},
+		{"cvps_sandbox_memory_used_bytes", "Memory in use.", func(m *claudevps.SandboxMetrics) float64 { return float64(m.MemoryUsedBytes) }},
+		{"cvps_sandbox_disk_used_bytes", "Disk space in use.", func(m *claudevps.SandboxMetrics) float64 { return float64(m.DiskUsedBytes) }},
+	}
+	for _, u := range usage {
+		p.header(u.name, u.help)
+		for _, s := range sandboxes {
+			if m, ok := sample.Metrics[s.ID]; ok {
+				p.sample(u.name, u.value(m), "id", s.ID, "name", s.Name)
+			}
+		}
+	}
+
+	p.header("cvps_sandbox_cost_to_date", "Cost accrued by the sandbox so far.")
+	for _, s := range sandboxes {
+		if m, ok := sample.Metrics[s.ID]; ok {
+			currency := m.Currency
+			if currency == "" {
+				currency = "USD"
+			}
+			p.sample("cvps_sandbox_cost_to_date", m.CostToDate, "id", s.ID, "name", s.Name, "currency", currency)
+		}
+	}
+
+	p.header("cvps_scrape_errors", "API errors during the last poll.")
+	p.sample("cvps_scrape_errors", float64(sample.Errors))
+	p.header("cvps_scrape_duration_seconds", "Duration of the last poll.")
+	p.sample("cvps_scrape_duration_seconds", sample.Duration.Seconds())
+	p.header("cvps_last_scrape_timestamp_seconds", "Unix time of the last poll.")
+	p.sample("cvps_last_scrape_timestamp_seconds", float64(sample.Time.Unix()))
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestRenderPrometheus(t *testing.T) {
+	sample := fleetSample{
+		Sandboxes: []claudevps.Sandbox{
+			{ID: "sbx-2", Name: `say "hi"`, Status: "stopped", CPUCores: 1, MemoryGB: 2},
+			{ID: "sbx-1", Name: "web", Status: "running", CPUCores: 2, MemoryGB: 4},
+			{ID: "sbx-3", Name: "api", Status: "running", CPUCores: 4, MemoryGB: 8},
+		},
+		Metrics: map[string]*claudevps.SandboxMetrics{
+			"sbx-1": {CPUPercent: 12.5, MemoryUsedBytes: 1024, CostToDate: 3.5},
+		},
+		Errors:   1,
+		Duration: 1500 * time.Millisecond,
+		Time:     time.Unix(1700000000, 0),
+	}
+
+	var buf bytes.Buffer
+	renderPrometheus(&buf, sample)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE cvps_sandboxes gauge\n",
+		`cvps_sandboxes{status="running"} 2` + "\n",
+		`cvps_sandboxes{status="stopped"} 1` + "\n",
+		`cvps_sandbox_info{id="sbx-2",name="say \"hi\"",status="stopped"} 1` + "\n",
+		`cvps_sandbox_cpu_cores{id="sbx-3",name="api"} 4` + "\n",
+		`cvps_sandbox_cpu_usage_percent{id="sbx-1",name="web"} 12.5` + "\n",
+		`cvps_sandbox_cost_to_date{id="sbx-1",name="web",currency="USD"} 3.5` + "\n",
+		"cvps_scrape_errors 1\n",
+		"cvps_scrape_duration_seconds 1.5\n",
+		"cvps_last_scrape_timestamp_seconds 1.7e+09\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+
+	if strings.Contains(out, `cvps_sandbox_cpu_usage_percent{id="sbx-3"`) {
+		t.Error("expected no usage sample for sandbox without metrics")
+	}
+}
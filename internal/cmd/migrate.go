@@ -7,17 +7,20 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
 	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 var (
 	migrateExclude []string
-	migrateDryRun  bool
 	migrateResume  bool
+	migrateArchive bool
+	migrateForce   bool
+
+	migrateTransport string
 )
 
 var migrateCmd = &cobra.Command{
@@ -37,7 +40,10 @@ For ongoing synchronization, use 'cvps sync' instead.`,
   cvps migrate . --exclude="node_modules" --exclude="*.log"
 
   # Preview without uploading
-  cvps migrate . --dry-run`,
+  cvps migrate . --dry-run
+
+  # Stream a tree of many small files as one archive
+  cvps migrate . --archive`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMigrate,
 }
@@ -46,11 +52,27 @@ func init() {
 	rootCmd.AddCommand(migrateCmd)
 
 	migrateCmd.Flags().StringSliceVar(&migrateExclude, "exclude", nil, "patterns to exclude")
-	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "preview migration without uploading")
 	migrateCmd.Flags().BoolVar(&migrateResume, "resume", false, "resume interrupted migration")
+	migrateCmd.Flags().BoolVar(&migrateArchive, "archive", false, "stream files as one tar archive over SSH; much faster for many small files")
+	migrateCmd.Flags().BoolVar(&migrateForce, "force", false, "migrate even if the files look too big for the free space on the sandbox")
+	migrateCmd.Flags().StringVar(&migrateTransport, "transport", transportAuto, "transfer method (auto|ssh|api); api uploads over HTTPS when SSH is blocked")
+	addProgressFlag(migrateCmd)
 }
 
 func runMigrate(cmd *cobra.Command, args []string) error {
+	if err := validTransport(migrateTransport); err != nil {
+		return err
+	}
+	if err := validProgress(); err != nil {
+		return err
+	}
+	if migrateArchive && migrateResume {
+		return fmt.Errorf("--archive cannot be combined with --resume")
+	}
+	if migrateArchive && migrateTransport == transportAPI {
+		return fmt.Errorf("--archive needs SSH and cannot be used with --transport api")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -60,7 +82,7 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'cvps login' first")
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
 	ctx := context.Background()
 
 	// Get sandbox ID
@@ -114,7 +136,7 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  To:     %s:/workspace\n", sandbox.Name)
 	fmt.Println()
 
-	if migrateDryRun {
+	if dryRun {
 		fmt.Println("Dry run - no files uploaded")
 		fmt.Println("\nTop 10 largest files:")
 		for i, f := range files.LargestFiles(10) {
@@ -123,6 +145,10 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if err := checkRemoteFreeSpace(ctx, client, sandbox, files.TotalSize, migrateForce); err != nil {
+		return err
+	}
+
 	// Confirm
 	fmt.Print("Continue with migration? (y/N): ")
 	var confirm string
@@ -131,6 +157,12 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("migration cancelled")
 	}
 
+	return transferWorkspace(ctx, client, sandbox, absPath, files, migrateArchive, migrateResume, migrateTransport)
+}
+
+// transferWorkspace uploads the scanned files of absPath to /workspace on the
+// sandbox with a progress bar, then prints what was transferred
+func transferWorkspace(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, absPath string, files *migration.ScanResult, archive, resume bool, transport string) error {
 	// Create migrator
 	migrator := migration.NewMigrator(migration.Config{
 		LocalPath:  absPath,
@@ -138,26 +170,50 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		SSHPort:    sandbox.SSHPort,
 		SSHUser:    sandbox.SSHUser,
 		RemotePath: "/workspace",
-		Resume:     migrateResume,
+		Resume:     resume,
 	})
 
 	// Progress bar
-	bar := progressbar.NewOptions64(
-		files.TotalSize,
-		progressbar.OptionSetDescription("Migrating"),
-		progressbar.OptionSetWriter(os.Stdout),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetPredictTime(true),
-		progressbar.OptionFullWidth(),
-	)
+	var bar transferProgress
+	if jsonProgress() {
+		bar = newTransferBar(files.TotalSize, "Migrating", "upload")
+	} else {
+		bar = progressbar.NewOptions64(
+			files.TotalSize,
+			progressbar.OptionSetDescription("Migrating"),
+			progressbar.OptionSetWriter(os.Stdout),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionShowCount(),
+			progressbar.OptionSetPredictTime(true),
+			progressbar.OptionFullWidth(),
+		)
+	}
 
 	// Run migration
 	startTime := time.Now()
-	result, err := migrator.Run(ctx, files, func(bytesTransferred int64) {
+	onProgress := func(bytesTransferred int64) {
 		bar.Set64(bytesTransferred)
-	})
+	}
+
+	var result *migration.Result
+	var err error
+	switch {
+	case archive:
+		conn, dialErr := dialSandbox(ctx, sandbox)
+		if dialErr != nil {
+			return dialErr
+		}
+		defer conn.Close()
+		result, err = migrator.RunArchive(ctx, files, conn, onProgress)
+	case useAPITransport(ctx, transport, sandbox):
+		fmt.Println("Uploading over HTTPS (SSH unavailable or --transport api)")
+		uploader := &apiUploader{client: client, sandboxID: sandbox.ID, dirs: make(map[string]bool)}
+		result, err = migrator.RunUpload(ctx, files, uploader, onProgress)
+	default:
+		result, err = migrator.Run(ctx, files, onProgress)
+	}
 	if err != nil {
+		emitStage("upload", progressFailed, err.Error())
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
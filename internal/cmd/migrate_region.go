@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// sandboxStopTimeout bounds how long a sandbox may take to shut down
+const sandboxStopTimeout = 5 * time.Minute
+
+var (
+	migrateRegionTo         string
+	migrateRegionForce      bool
+	migrateRegionKeepSource bool
+)
+
+var migrateRegionCmd = &cobra.Command{
+	Use:   "migrate-region [sandbox]",
+	Short: "Move a sandbox to another region",
+	Long: `Move a sandbox to another region, keeping its name and disk.
+
+The sandbox is stopped so its disk is consistent, snapshotted, and recreated
+from the snapshot in the target region with the same size, image and labels.
+Once the new sandbox is running it takes over the name and the project
+context, and the old one is moved to the trash (or kept stopped with
+--keep-source). If anything fails before the swap, the new sandbox is removed
+and the old one is started again.`,
+	Example: `  # Move the current sandbox to eu-west
+  cvps migrate-region --to eu-west
+
+  # Move a named sandbox and keep the old one around, stopped
+  cvps migrate-region web --to us-east --keep-source`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runMigrateRegion,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateRegionCmd)
+
+	migrateRegionCmd.Flags().StringVar(&migrateRegionTo, "to", "", "target region (required)")
+	migrateRegionCmd.Flags().BoolVarP(&migrateRegionForce, "force", "f", false, "skip confirmation prompt")
+	migrateRegionCmd.Flags().BoolVar(&migrateRegionKeepSource, "keep-source", false, "keep the old sandbox stopped instead of moving it to the trash")
+	migrateRegionCmd.MarkFlagRequired("to")
+	addProgressFlag(migrateRegionCmd)
+}
+
+func runMigrateRegion(cmd *cobra.Command, args []string) error {
+	if err := validProgress(); err != nil {
+		return err
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	id, err := resolveSandbox(ctx, client, ref, false)
+	if err != nil {
+		return err
+	}
+	source, err := client.GetSandbox(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	if source.Region != "" && strings.EqualFold(source.Region, migrateRegionTo) {
+		return fmt.Errorf("sandbox '%s' is already in %s", source.Name, source.Region)
+	}
+
+	if !migrateRegionForce {
+		from := source.Region
+		if from == "" {
+			from = "its current region"
+		}
+		color.New(color.FgYellow, color.Bold).Printf("⚠ This will move sandbox '%s' (%s) from %s to %s.\n", source.Name, source.ID, from, migrateRegionTo)
+		fmt.Println("The sandbox is stopped during the move; running processes and open sessions end.")
+		fmt.Print("\nContinue? [y/N]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	m := &regionMigration{client: client, source: source, region: migrateRegionTo}
+	return m.run(ctx)
+}
+
+// regionMigration moves one sandbox to another region, undoing its steps on
+// failure until the new sandbox has taken over
+type regionMigration struct {
+	client *claudevps.Client
+	source *claudevps.Sandbox
+	region string
+
+	stopped bool
+	target  *claudevps.Sandbox
+	stage   string // the step in progress, for progress events
+}
+
+const regionMigrationSteps = 5
+
+// regionMigrationStages name the steps in progress events
+var regionMigrationStages = [regionMigrationSteps]string{"stop", "snapshot", "create", "switch", "cleanup"}
+
+func (m *regionMigration) step(n int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Printf("[%d/%d] %s\n", n, regionMigrationSteps, msg)
+
+	if m.stage != "" {
+		emitStage(m.stage, progressDone, "")
+	}
+	m.stage = regionMigrationStages[n-1]
+	emitProgress(progressEvent{Stage: m.stage, Status: progressRunning, Percent: percentOf(int64(n-1), regionMigrationSteps), Sandbox: m.source.ID, Message: msg})
+}
+
+func (m *regionMigration) run(ctx context.Context) error {
+	name := m.source.Name
+
+	if isRunningStatus(m.source.Status) {
+		m.step(1, "Stopping %s", name)
+		if err := m.stopSource(ctx); err != nil {
+			return m.fail(ctx, err)
+		}
+	} else {
+		m.step(1, "%s is already %s", name, m.source.Status)
+	}
+
+	m.step(2, "Snapshotting %s", name)
+	snap, err := m.client.CreateSnapshot(ctx, m.source.ID, &claudevps.CreateSnapshotRequest{
+		Name: fmt.Sprintf("%s-migrate-%s", name, time.Now().Format("20060102-150405")),
+	})
+	if err != nil {
+		return m.fail(ctx, fmt.Errorf("failed to create snapshot: %w", err))
+	}
+	if snap, err = waitForSnapshot(ctx, m.client, snap, "snapshot", " Taking snapshot...", snapshotArchiveTimeout); err != nil {
+		return m.fail(ctx, err)
+	}
+	fmt.Printf("✓ Snapshot %s saved\n", snap.ID)
+
+	m.step(3, "Creating %s in %s", name, m.region)
+	target, err := m.client.CreateSandbox(ctx, &claudevps.CreateSandboxRequest{
+		Name:         regionMigrationName(name, m.region),
+		CPUCores:     m.source.CPUCores,
+		MemoryGB:     m.source.MemoryGB,
+		StorageGB:    m.source.StorageGB,
+		Image:        m.source.Image,
+		Region:       m.region,
+		Arch:         m.source.Arch,
+		Labels:       m.source.Labels,
+		FromSnapshot: snap.ID,
+	})
+	if err != nil {
+		return m.fail(ctx, fmt.Errorf("failed to create sandbox: %w", err))
+	}
+	m.target = target
+	ready, err := waitForSandboxReady(ctx, m.client, target.ID)
+	if err != nil {
+		return m.fail(ctx, err)
+	}
+	m.target = ready
+
+	m.step(4, "Switching the name %s to the new sandbox", name)
+	if err := m.swapNames(ctx); err != nil {
+		return m.fail(ctx, err)
+	}
+	m.swapLocalContext()
+
+	// The new sandbox is in service; failures from here on are only reported
+	old := m.source
+	if migrateRegionKeepSource {
+		m.step(5, "Keeping the old sandbox stopped as %s", old.Name)
+	} else {
+		m.step(5, "Moving the old sandbox to the trash")
+		if err := m.client.DeleteSandbox(ctx, old.ID); err != nil && !deleteRaced(err) {
+			color.Yellow("⚠ Failed to delete old sandbox %s: %v", old.ID, err)
+		} else {
+			fmt.Printf("✓ Old sandbox %s is in the trash; restore it with 'cvps restore %s'\n", old.ID, old.ID)
+		}
+	}
+
+	emitStage(m.stage, progressDone, "")
+	fmt.Printf("\n✓ Sandbox '%s' now runs in %s (%s)\n", name, m.region, m.target.ID)
+	fmt.Printf("  Snapshot %s is kept; delete it once you no longer need a way back.\n", snap.ID)
+	return nil
+}
+
+// stopSource stops the source sandbox and waits until it is down
+func (m *regionMigration) stopSource(ctx context.Context) error {
+	if _, err := m.client.StopSandbox(ctx, m.source.ID); err != nil {
+		if !isConflict(err, claudevps.CodeAlreadyStopped) {
+			return fmt.Errorf("failed to stop sandbox: %w", err)
+		}
+	} else {
+		m.stopped = true
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Waiting for the sandbox to stop..."
+	s.Start()
+	defer s.Stop()
+
+	deadline := time.Now().Add(sandboxStopTimeout)
+	for {
+		sandbox, err := m.client.GetSandbox(ctx, m.source.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get status: %w", err)
+		}
+		if sandbox.Status == "stopped" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for sandbox to stop (waited %s)", sandboxStopTimeout)
+		}
+		time.Sleep(snapshotPollInterval)
+	}
+}
+
+// swapNames gives the target the source's name. Names are unique, so the
+// source is renamed out of the way first and renamed back if the target
+// cannot take the name.
+func (m *regionMigration) swapNames(ctx context.Context) error {
+	name := m.source.Name
+	oldName := name + "-old"
+	if m.source.Region != "" {
+		oldName = regionMigrationName(name, m.source.Region)
+	}
+
+	if _, err := m.client.RenameSandbox(ctx, m.source.ID, oldName); err != nil {
+		return fmt.Errorf("failed to rename old sandbox: %w", err)
+	}
+	if _, err := m.client.RenameSandbox(ctx, m.target.ID, name); err != nil {
+		if _, undoErr := m.client.RenameSandbox(ctx, m.source.ID, name); undoErr != nil {
+			color.Yellow("⚠ Failed to restore the name of %s: %v", m.source.ID, undoErr)
+		}
+		return fmt.Errorf("failed to rename new sandbox: %w", err)
+	}
+
+	m.source.Name = oldName
+	m.target.Name = name
+	return nil
+}
+
+// swapLocalContext points the project context at the new sandbox if it
+// tracked the old one
+func (m *regionMigration) swapLocalContext() {
+	err := updateLocalContext(func(c *localctx.Context) error {
+		for i := range c.Sandboxes {
+			if c.Sandboxes[i].SandboxID != m.source.ID {
+				continue
+			}
+			c.Sandboxes[i] = localctx.Entry{
+				SandboxID: m.target.ID,
+				Name:      m.target.Name,
+				CreatedAt: time.Now().Format(time.RFC3339),
+			}
+			if c.Current == m.source.ID {
+				c.Current = m.target.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		color.Yellow("⚠ Failed to update the project context: %v", err)
+	}
+}
+
+// fail undoes the migration so far and returns err
+func (m *regionMigration) fail(ctx context.Context, err error) error {
+	emitStage(m.stage, progressFailed, err.Error())
+	if m.target != nil {
+		fmt.Printf("Removing new sandbox %s...\n", m.target.ID)
+		if delErr := m.client.PurgeSandbox(ctx, m.target.ID); delErr != nil {
+			color.Yellow("⚠ Failed to remove new sandbox %s: %v", m.target.ID, delErr)
+		}
+	}
+	if m.stopped {
+		fmt.Printf("Starting %s again...\n", m.source.Name)
+		if _, startErr := m.client.StartSandbox(ctx, m.source.ID); startErr != nil && !isConflict(startErr, claudevps.CodeAlreadyRunning) {
+			color.Yellow("⚠ Failed to start %s again: %v", m.source.ID, startErr)
+		}
+	}
+	return fmt.Errorf("migration to %s failed: %w", m.region, err)
+}
+
+// regionMigrationName names a sandbox after its region while two copies exist
+func regionMigrationName(name, region string) string {
+	return name + "-" + strings.ToLower(region)
+}
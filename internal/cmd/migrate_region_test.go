@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// fakeRegionAPI serves the endpoints used by migrate-region and records the
+// mutating calls in order
+type fakeRegionAPI struct {
+	t          *testing.T
+	mu         sync.Mutex
+	calls      []string
+	targetFail bool
+	create     claudevps.CreateSandboxRequest
+}
+
+func (f *fakeRegionAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != "GET" {
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-old":
+		status := "running"
+		if len(f.calls) > 0 {
+			status = "stopped"
+		}
+		json.NewEncoder(w).Encode(claudevps.Sandbox{
+			ID: "sbx-old", Name: "web", Status: status, Region: "us-east",
+			CPUCores: 4, MemoryGB: 8, Image: "ghcr.io/acme/tools:1",
+		})
+	case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-old/stop":
+		json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-old", Status: "stopping"})
+	case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-old/snapshots":
+		json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusReady})
+	case r.Method == "POST" && r.URL.Path == "/sandboxes":
+		json.NewDecoder(r.Body).Decode(&f.create)
+		json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-new", Name: f.create.Name, Status: "provisioning"})
+	case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-new/status":
+		sandbox := claudevps.Sandbox{ID: "sbx-new", Name: f.create.Name, Status: "running", Region: "eu-west"}
+		if f.targetFail {
+			sandbox.Status = "failed"
+		}
+		json.NewEncoder(w).Encode(sandbox)
+	case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-new/logs":
+		json.NewEncoder(w).Encode([]claudevps.LogEntry{})
+	case r.Method == "PATCH", r.Method == "DELETE", r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-old/start":
+		json.NewEncoder(w).Encode(claudevps.Sandbox{})
+	default:
+		f.t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupRegionMigrationTest(t *testing.T, fake *fakeRegionAPI) {
+	t.Helper()
+	setupFakeAPI(t, fake)
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	if err := saveLocalContext("sbx-old", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	migrateRegionTo = "eu-west"
+	migrateRegionForce = true
+	t.Cleanup(func() {
+		migrateRegionTo, migrateRegionForce, migrateRegionKeepSource = "", false, false
+	})
+}
+
+func TestRunMigrateRegion(t *testing.T) {
+	fake := &fakeRegionAPI{t: t}
+	setupRegionMigrationTest(t, fake)
+
+	output, err := captureStdout(t, func() error {
+		return runMigrateRegion(nil, []string{"sbx-old"})
+	})
+	if err != nil {
+		t.Fatalf("runMigrateRegion() error = %v\n%s", err, output)
+	}
+
+	if fake.create.Region != "eu-west" || fake.create.FromSnapshot != "snap-1" || fake.create.CPUCores != 4 || fake.create.Image != "ghcr.io/acme/tools:1" {
+		t.Errorf("Unexpected create request: %+v", fake.create)
+	}
+	want := []string{
+		"POST /sandboxes/sbx-old/stop",
+		"POST /sandboxes/sbx-old/snapshots",
+		"POST /sandboxes",
+		"PATCH /sandboxes/sbx-old",
+		"PATCH /sandboxes/sbx-new",
+		"DELETE /sandboxes/sbx-old",
+	}
+	if strings.Join(fake.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %v, want %v", fake.calls, want)
+	}
+
+	c, err := localctx.Load(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := c.CurrentEntry(); e == nil || e.SandboxID != "sbx-new" || e.Name != "web" {
+		t.Errorf("expected context to point at the new sandbox, got %+v", c)
+	}
+	if !strings.Contains(output, "now runs in eu-west") {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
+
+func TestRunMigrateRegion_RollsBackOnFailure(t *testing.T) {
+	fake := &fakeRegionAPI{t: t, targetFail: true}
+	setupRegionMigrationTest(t, fake)
+
+	_, err := captureStdout(t, func() error {
+		return runMigrateRegion(nil, []string{"sbx-old"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "migration to eu-west failed") {
+		t.Fatalf("expected migration failure, got %v", err)
+	}
+
+	want := []string{
+		"POST /sandboxes/sbx-old/stop",
+		"POST /sandboxes/sbx-old/snapshots",
+		"POST /sandboxes",
+		"DELETE /sandboxes/sbx-new",
+		"POST /sandboxes/sbx-old/start",
+	}
+	if strings.Join(fake.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %v, want %v", fake.calls, want)
+	}
+
+	c, _ := localctx.Load(".")
+	if e := c.CurrentEntry(); e == nil || e.SandboxID != "sbx-old" {
+		t.Errorf("expected context to be unchanged, got %+v", c)
+	}
+}
+
+func TestRunMigrateRegion_SameRegion(t *testing.T) {
+	fake := &fakeRegionAPI{t: t}
+	setupRegionMigrationTest(t, fake)
+	migrateRegionTo = "US-EAST"
+
+	err := runMigrateRegion(nil, []string{"sbx-old"})
+	if err == nil || !strings.Contains(err.Error(), "already in us-east") {
+		t.Fatalf("expected already-in-region error, got %v", err)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("expected no changes, got %v", fake.calls)
+	}
+}
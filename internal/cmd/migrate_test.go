@@ -54,7 +54,7 @@ func TestMigrateCmd_Flags(t *testing.T) {
 		t.Error("exclude flag not found")
 	}
 
-	dryRunFlag := migrateCmd.Flags().Lookup("dry-run")
+	dryRunFlag := migrateCmd.Flag("dry-run")
 	if dryRunFlag == nil {
 		t.Error("dry-run flag not found")
 	}
@@ -63,6 +63,28 @@ func TestMigrateCmd_Flags(t *testing.T) {
 	if resumeFlag == nil {
 		t.Error("resume flag not found")
 	}
+
+	archiveFlag := migrateCmd.Flags().Lookup("archive")
+	if archiveFlag == nil {
+		t.Error("archive flag not found")
+	}
+}
+
+func TestMigrateCmd_ArchiveConflicts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer func() {
+		migrateArchive, migrateResume, migrateTransport = false, false, transportAuto
+	}()
+
+	migrateArchive, migrateResume = true, true
+	if err := runMigrate(nil, []string{"."}); err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("runMigrate() error = %v, want --archive/--resume conflict", err)
+	}
+
+	migrateResume, migrateTransport = false, transportAPI
+	if err := runMigrate(nil, []string{"."}); err == nil || !strings.Contains(err.Error(), "--transport api") {
+		t.Errorf("runMigrate() error = %v, want --archive/--transport api conflict", err)
+	}
 }
 
 func TestMigrateCmd_NoArgs(t *testing.T) {
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Output formats accepted by -o/--output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+func validOutputFormat(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputCSV, outputTSV:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q (must be table, json, csv or tsv)", format)
+	}
+}
+
+// isDelimited reports whether format is one of the spreadsheet formats
+func isDelimited(format string) bool {
+	return format == outputCSV || format == outputTSV
+}
+
+// writeDelimited writes a header and rows as CSV or TSV. Fields containing the
+// separator, quotes or newlines are quoted so spreadsheets import them intact.
+func writeDelimited(w io.Writer, format string, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if format == outputTSV {
+		cw.Comma = '\t'
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", format, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestWriteDelimited(t *testing.T) {
+	rows := [][]string{
+		{"plain", `has "quotes"`},
+		{"has,comma", "has\ttab"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDelimited(&buf, outputCSV, []string{"a", "b"}, rows); err != nil {
+		t.Fatalf("writeDelimited() error = %v", err)
+	}
+	want := "a,b\nplain,\"has \"\"quotes\"\"\"\n\"has,comma\",has\ttab\n"
+	if got := buf.String(); got != want {
+		t.Errorf("csv = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := writeDelimited(&buf, outputTSV, []string{"a", "b"}, rows); err != nil {
+		t.Fatalf("writeDelimited() error = %v", err)
+	}
+	want = "a\tb\nplain\t\"has \"\"quotes\"\"\"\nhas,comma\t\"has\ttab\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("tsv = %q, want %q", got, want)
+	}
+}
+
+func TestValidOutputFormat(t *testing.T) {
+	for _, f := range []string{"table", "json", "csv", "tsv"} {
+		if err := validOutputFormat(f); err != nil {
+			t.Errorf("validOutputFormat(%q) error = %v", f, err)
+		}
+	}
+	if err := validOutputFormat("xml"); err == nil {
+		t.Error("expected error for xml")
+	}
+}
+
+func TestWriteSandboxRows(t *testing.T) {
+	sandboxes := []claudevps.Sandbox{
+		{ID: "sbx-1", Name: "web, api", Status: "running", CPUCores: 2, MemoryGB: 4, StorageGB: 20, CreatedAt: "2024-01-15T10:00:00Z"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSandboxRows(&buf, outputCSV, sandboxes, nil, nil); err != nil {
+		t.Fatalf("writeSandboxRows() error = %v", err)
+	}
+	want := "id,name,status,cpu_cores,memory_gb,storage_gb,created_at,last_active_at,description\n" +
+		"sbx-1,\"web, api\",running,2,4,20,2024-01-15T10:00:00Z,,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is the executable name prefix of plugins: 'cvps foo' runs
+// cvps-foo from PATH when foo is not a built-in command
+const pluginPrefix = "cvps-"
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage plugins",
+	Long: `Plugins extend cvps with new subcommands. Any executable on PATH named
+cvps-<name> can be run as 'cvps <name>'; built-in commands always take
+precedence.
+
+Plugins receive the remaining arguments and these environment variables:
+
+  CVPS_BIN                            path to the cvps executable
+  CVPS_API_URL                        API base URL
+  CVPS_API_KEY or CVPS_ACCESS_TOKEN   credentials of the logged in account
+  CVPS_SANDBOX_ID, CVPS_SANDBOX_NAME  current sandbox of the project, if any
+  CVPS_SANDBOX_STATUS, CVPS_SSH_*     cached details of that sandbox`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins found on PATH",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
+
+// plugin is a cvps-<name> executable found on PATH
+type plugin struct {
+	Name string
+	Path string
+
+	// Warning explains why the plugin will not run, if it won't
+	Warning string
+}
+
+// isBuiltinCommand reports whether name is handled by cvps itself
+func isBuiltinCommand(name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginName returns the plugin name for an executable file name, or "" if
+// it is not a plugin
+func pluginName(file string) string {
+	name, ok := strings.CutPrefix(file, pluginPrefix)
+	if !ok {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// findPlugins lists plugins in PATH order. Later plugins with the same name
+// and plugins named after built-in commands are flagged since they never run.
+func findPlugins() []plugin {
+	var plugins []plugin
+	seen := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := pluginName(entry.Name())
+			if name == "" || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			p := plugin{Name: name, Path: path}
+			if first, ok := seen[name]; ok {
+				p.Warning = "shadowed by " + first
+			} else if isBuiltinCommand(name) {
+				p.Warning = "overridden by the built-in command"
+			} else {
+				seen[name] = path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0111 != 0
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		fmt.Println("No plugins found. Add an executable named cvps-<name> to your PATH.")
+		return nil
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPATH\tNOTE")
+	for _, p := range plugins {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Path, p.Warning)
+	}
+	w.Flush()
+	return nil
+}
+
+// pluginEnv returns the environment passed to plugins: the caller's plus the
+// API settings, credentials and current sandbox
+func pluginEnv() []string {
+	env := os.Environ()
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "CVPS_BIN="+exe)
+	}
+
+	if cfg, err := config.Load(); err == nil {
+		env = append(env, "CVPS_API_URL="+cfg.APIBaseURL)
+		if cfg.AccessToken != "" {
+			env = append(env, "CVPS_ACCESS_TOKEN="+cfg.AccessToken)
+		} else if cfg.APIKey != "" {
+			env = append(env, "CVPS_API_KEY="+cfg.APIKey)
+		}
+	}
+
+	sandboxEnv, err := currentSandboxEnv()
+	if err != nil {
+		debuglog.Printf("plugin: no sandbox context: %v", err)
+	}
+	for _, name := range hookEnvVars {
+		if value, ok := sandboxEnv[name]; ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// runPlugin runs the plugin named by args[0] if it is not a built-in command
+// and a cvps-<name> executable exists. It reports whether a plugin handled
+// the command, and its exit code.
+func runPlugin(args []string) (int, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args[0]) {
+		return 0, false
+	}
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return 0, false
+	}
+	debuglog.Printf("plugin: running %s %v", path, args[1:])
+
+	c := exec.Command(path, args[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.Env = pluginEnv()
+
+	// Ctrl+C reaches the plugin directly; cvps waits for it to exit
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), true
+		}
+		fmt.Fprintf(os.Stderr, "failed to run plugin %s: %v\n", args[0], err)
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts in this test")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	writePlugin(t, first, "cvps-hello", "")
+	writePlugin(t, second, "cvps-hello", "")
+	writePlugin(t, second, "cvps-status", "")
+	os.WriteFile(filepath.Join(second, "cvps-notes"), []byte("not executable"), 0644)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := findPlugins()
+	if len(plugins) != 3 {
+		t.Fatalf("findPlugins() = %+v, want 3 plugins", plugins)
+	}
+	if plugins[0].Name != "hello" || plugins[0].Warning != "" {
+		t.Errorf("Expected first hello to run, got %+v", plugins[0])
+	}
+	if !strings.HasPrefix(plugins[1].Warning, "shadowed by "+first) {
+		t.Errorf("Expected second hello to be shadowed, got %+v", plugins[1])
+	}
+	if plugins[2].Name != "status" || plugins[2].Warning == "" {
+		t.Errorf("Expected status to be overridden by the built-in, got %+v", plugins[2])
+	}
+}
+
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts in this test")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+	saveLocalContext("sbx-1", "web")
+
+	bin := t.TempDir()
+	out := filepath.Join(tmpDir, "out")
+	writePlugin(t, bin, "cvps-hello", `echo "$@ $CVPS_SANDBOX_ID $CVPS_SANDBOX_NAME $CVPS_API_URL" > `+out+"\nexit 3\n")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	code, ok := runPlugin([]string{"hello", "a", "b"})
+	if !ok {
+		t.Fatal("Expected the plugin to handle the command")
+	}
+	if code != 3 {
+		t.Errorf("Expected the plugin's exit code 3, got %d", code)
+	}
+	data, _ := os.ReadFile(out)
+	if got := strings.TrimSpace(string(data)); got != "a b sbx-1 web https://api.claudevps.com" {
+		t.Errorf("plugin saw %q", got)
+	}
+
+	for _, args := range [][]string{nil, {"status"}, {"--help"}, {"nonexistent"}} {
+		if _, ok := runPlugin(args); ok {
+			t.Errorf("runPlugin(%q) should not run a plugin", args)
+		}
+	}
+}
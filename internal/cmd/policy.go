@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyJSON bool
+
+	policySuspendOnDisconnect time.Duration
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Manage per-sandbox policies",
+	Long: `Manage the policies of a single sandbox, such as suspending it after
+everyone disconnects.
+
+Suspend-on-disconnect stops a sandbox a fixed time after its last terminal, SSH
+or sync connection ends, however busy its CPU is. It complements auto-suspend
+('cvps idle configure'), which waits for CPU activity to stop and so never
+catches a sandbox left running a watcher or a stuck process.`,
+	Example: `  # Suspend the current sandbox 15 minutes after the last connection ends
+  cvps policy set --suspend-on-disconnect 15m
+
+  # Show the policy of a sandbox
+  cvps policy show web
+
+  # Turn it off again
+  cvps policy set web --suspend-on-disconnect 0`,
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:               "show [sandbox]",
+	Short:             "Show the policy of a sandbox",
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runPolicyShow,
+	ValidArgsFunction: completeSandboxes,
+}
+
+var policySetCmd = &cobra.Command{
+	Use:   "set [sandbox]",
+	Short: "Change the policy of a sandbox",
+	Long: `Change the policy of a sandbox (default: the current one). Stopped sandboxes
+keep their disk and start again with 'cvps up'.`,
+	Example: `  # Suspend 15 minutes after the last terminal, SSH or sync connection ends
+  cvps policy set web --suspend-on-disconnect 15m
+
+  # Turn suspend-on-disconnect off
+  cvps policy set web --suspend-on-disconnect 0`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runPolicySet,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyShowCmd)
+	policyCmd.AddCommand(policySetCmd)
+
+	policyShowCmd.Flags().BoolVar(&policyJSON, "json", false, "output in JSON format")
+	policySetCmd.Flags().DurationVar(&policySuspendOnDisconnect, "suspend-on-disconnect", 0, "stop the sandbox this long after the last connection ends (e.g. 15m); 0 turns it off")
+}
+
+func runPolicyShow(cmd *cobra.Command, args []string) error {
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	id, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+	policy, err := client.GetSandboxPolicy(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get sandbox policy: %w", err)
+	}
+
+	if policyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(policy)
+	}
+	printSandboxPolicy(policy, time.Now())
+	return nil
+}
+
+func runPolicySet(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	req := &claudevps.UpdateSandboxPolicyRequest{}
+	if flags.Changed("suspend-on-disconnect") {
+		if policySuspendOnDisconnect != 0 && policySuspendOnDisconnect < time.Minute {
+			return fmt.Errorf("--suspend-on-disconnect must be at least 1m, or 0 to turn it off")
+		}
+		seconds := int(policySuspendOnDisconnect / time.Second)
+		req.SuspendOnDisconnectSeconds = &seconds
+	}
+	if req.SuspendOnDisconnectSeconds == nil {
+		return fmt.Errorf("nothing to change; pass --suspend-on-disconnect")
+	}
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	id, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+	policy, err := client.UpdateSandboxPolicy(ctx, id, req)
+	if err != nil {
+		return fmt.Errorf("failed to update sandbox policy: %w", err)
+	}
+	fmt.Print("✓ ")
+	printSandboxPolicy(policy, time.Now())
+	return nil
+}
+
+// printSandboxPolicy prints a one-line summary of a sandbox policy
+func printSandboxPolicy(policy *claudevps.SandboxPolicy, now time.Time) {
+	if !policy.SuspendsOnDisconnect() {
+		fmt.Println("Suspend on disconnect: off (enable with 'cvps policy set --suspend-on-disconnect 15m')")
+		return
+	}
+	fmt.Printf("Suspend on disconnect: %s\n", disconnectSuspendStatus(policy, now))
+}
+
+// disconnectSuspendStatus describes when suspend-on-disconnect stops a
+// sandbox, with the countdown once nothing is connected
+func disconnectSuspendStatus(policy *claudevps.SandboxPolicy, now time.Time) string {
+	s := shortDuration(policy.SuspendOnDisconnect().Round(time.Minute)) + " after the last connection ends"
+	if policy.SuspendAt == "" {
+		return s
+	}
+	at, err := time.Parse(time.RFC3339, policy.SuspendAt)
+	if err != nil {
+		return s
+	}
+	d := at.Sub(now)
+	if d < time.Minute {
+		return s + " (" + color.YellowString("suspending now") + ")"
+	}
+	return s + " (nothing connected, suspending in " + shortDuration(d.Round(time.Minute)) + ")"
+}
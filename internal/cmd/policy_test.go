@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestDisconnectSuspendStatus(t *testing.T) {
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		policy *claudevps.SandboxPolicy
+		want   string
+	}{
+		{"connected", &claudevps.SandboxPolicy{SuspendOnDisconnectSeconds: 900}, "15m after the last connection ends"},
+		{"counting down", &claudevps.SandboxPolicy{SuspendOnDisconnectSeconds: 900, SuspendAt: "2024-03-29T18:12:10Z"}, "suspending in 12m"},
+		{"due", &claudevps.SandboxPolicy{SuspendOnDisconnectSeconds: 900, SuspendAt: "2024-03-29T17:59:50Z"}, "suspending now"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := disconnectSuspendStatus(tt.policy, now); !strings.Contains(got, tt.want) {
+				t.Errorf("disconnectSuspendStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPolicySet(t *testing.T) {
+	var patch map[string]any
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/sandboxes/sbx-1234abcd/policy" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&patch)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.SandboxPolicy{SuspendOnDisconnectSeconds: 900})
+	}))
+
+	flags := policySetCmd.Flags()
+	flags.Set("suspend-on-disconnect", "15m")
+	defer func() {
+		policySuspendOnDisconnect = 0
+		flags.Lookup("suspend-on-disconnect").Changed = false
+	}()
+
+	out, err := captureStdout(t, func() error {
+		return runPolicySet(policySetCmd, []string{"sbx-1234abcd"})
+	})
+	if err != nil {
+		t.Fatalf("runPolicySet() error = %v", err)
+	}
+	if patch["suspendOnDisconnectSeconds"] != float64(900) {
+		t.Errorf("Unexpected patch: %v", patch)
+	}
+	if !strings.Contains(out, "✓ Suspend on disconnect: 15m after the last connection ends") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestRunPolicySet_Invalid(t *testing.T) {
+	flags := policySetCmd.Flags()
+	defer func() {
+		policySuspendOnDisconnect = 0
+		flags.Lookup("suspend-on-disconnect").Changed = false
+	}()
+
+	if err := runPolicySet(policySetCmd, []string{"sbx-1234abcd"}); err == nil || !strings.Contains(err.Error(), "nothing to change") {
+		t.Errorf("runPolicySet() error = %v, want nothing to change", err)
+	}
+
+	flags.Set("suspend-on-disconnect", "30s")
+	if err := runPolicySet(policySetCmd, []string{"sbx-1234abcd"}); err == nil || !strings.Contains(err.Error(), "at least 1m") {
+		t.Errorf("runPolicySet() error = %v, want minimum error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// Waits on a sandbox poll quickly at first, so short provisions are not
+// slowed down, then back off so long ones cost fewer requests
+var (
+	pollInitialInterval = 500 * time.Millisecond
+	pollMaxInterval     = 10 * time.Second
+)
+
+const pollBackoffFactor = 1.5
+
+// sandboxPoller paces the polls of a wait on one sandbox. Intervals grow from
+// pollInitialInterval to pollMaxInterval, a Retry-After from the API takes
+// precedence, and an event on the sandbox's event stream that done accepts
+// ends the pause at once.
+type sandboxPoller struct {
+	client   *claudevps.Client
+	id       string
+	deadline time.Time
+	done     func(*claudevps.SandboxEvent) bool
+
+	interval time.Duration
+	events   chan *claudevps.SandboxEvent
+	cancel   context.CancelFunc // stops the event stream once it is open
+}
+
+func newSandboxPoller(client *claudevps.Client, id string, deadline time.Time, done func(*claudevps.SandboxEvent) bool) *sandboxPoller {
+	return &sandboxPoller{
+		client:   client,
+		id:       id,
+		deadline: deadline,
+		done:     done,
+		interval: pollInitialInterval,
+		events:   make(chan *claudevps.SandboxEvent, 1),
+	}
+}
+
+// wait pauses until the next poll is due, given the error of the last one.
+// It returns the event that ended the pause early, if any.
+func (p *sandboxPoller) wait(ctx context.Context, pollErr error) *claudevps.SandboxEvent {
+	// Waits that end on the first poll never open the stream
+	if p.cancel == nil {
+		p.follow(ctx)
+	}
+
+	delay := p.interval
+	if d := claudevps.RetryAfter(pollErr); d > 0 {
+		delay = d
+	} else {
+		p.interval = min(time.Duration(float64(p.interval)*pollBackoffFactor), pollMaxInterval)
+	}
+	if remaining := time.Until(p.deadline); remaining < delay {
+		delay = max(remaining, 0)
+	}
+
+	select {
+	case e := <-p.events:
+		return e
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+	return nil
+}
+
+// follow reads the sandbox's event stream in the background, passing on the
+// first event done accepts. Servers without the stream are simply polled.
+func (p *sandboxPoller) follow(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	go func() {
+		stream, err := p.client.SandboxEvents(ctx, p.id)
+		if err != nil {
+			debuglog.Printf("no event stream, polling: %v", err)
+			return
+		}
+		defer stream.Close()
+		for {
+			e, err := stream.Next()
+			if err != nil {
+				if ctx.Err() == nil {
+					debuglog.Printf("event stream ended: %v", err)
+				}
+				return
+			}
+			if p.done(e) {
+				p.events <- e
+				return
+			}
+		}
+	}()
+}
+
+// stop closes the event stream
+func (p *sandboxPoller) stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestSandboxPoller_Backoff(t *testing.T) {
+	setupFakeAPI(t, http.NotFoundHandler())
+	oldInitial, oldMax := pollInitialInterval, pollMaxInterval
+	pollInitialInterval, pollMaxInterval = time.Millisecond, 3*time.Millisecond
+	defer func() { pollInitialInterval, pollMaxInterval = oldInitial, oldMax }()
+
+	client, err := newAPIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p := newSandboxPoller(client, "sbx-1", time.Now().Add(time.Minute), func(*claudevps.SandboxEvent) bool { return true })
+	defer p.stop()
+
+	want := []time.Duration{1500 * time.Microsecond, 2250 * time.Microsecond, 3 * time.Millisecond, 3 * time.Millisecond}
+	for i, w := range want {
+		if e := p.wait(ctx, nil); e != nil {
+			t.Fatalf("wait() = %+v without an event stream", e)
+		}
+		if p.interval != w {
+			t.Errorf("interval after wait %d = %s, want %s", i+1, p.interval, w)
+		}
+	}
+
+	start := time.Now()
+	p.wait(ctx, &claudevps.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("wait() honored Retry-After for only %s", elapsed)
+	}
+	if p.interval != 3*time.Millisecond {
+		t.Errorf("Retry-After changed the interval to %s", p.interval)
+	}
+}
+
+func TestRunDown_EndsOnDeletedEvent(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-event1":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-event1", Name: "ev", Status: "running"})
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-event1/events":
+			json.NewEncoder(w).Encode(claudevps.SandboxEvent{Type: claudevps.SandboxEventStatus, Status: "stopping"})
+			json.NewEncoder(w).Encode(claudevps.SandboxEvent{Type: claudevps.SandboxEventDeleted})
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	// Polling alone would not finish within the test
+	oldInitial := pollInitialInterval
+	pollInitialInterval = time.Hour
+	defer func() { pollInitialInterval = oldInitial }()
+
+	downForce = true
+	defer func() { downForce = false }()
+
+	start := time.Now()
+	out, err := captureStdout(t, func() error { return runDown(nil, []string{"sbx-event1"}) })
+	if err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+	if !strings.Contains(out, "terminated successfully") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("runDown() took %s; expected the deleted event to end the wait", elapsed)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// portForward maps a localhost port to a port inside the sandbox
+type portForward struct {
+	Local  int
+	Remote int
+}
+
+func (f portForward) String() string {
+	if f.Local == f.Remote {
+		return strconv.Itoa(f.Local)
+	}
+	return fmt.Sprintf("%d:%d", f.Local, f.Remote)
+}
+
+// parsePortForward accepts "port" or "local:remote"
+func parsePortForward(spec string) (portForward, error) {
+	localStr, remoteStr, found := strings.Cut(strings.TrimSpace(spec), ":")
+	if !found {
+		remoteStr = localStr
+	}
+
+	local, err := strconv.Atoi(localStr)
+	if err != nil || local < 1 || local > 65535 {
+		return portForward{}, fmt.Errorf("invalid port forward %q (use port or local:remote)", spec)
+	}
+	remote, err := strconv.Atoi(remoteStr)
+	if err != nil || remote < 1 || remote > 65535 {
+		return portForward{}, fmt.Errorf("invalid port forward %q (use port or local:remote)", spec)
+	}
+	return portForward{Local: local, Remote: remote}, nil
+}
+
+// parsePortForwards parses specs in order, dropping exact duplicates. A local
+// port may only be forwarded to one remote port.
+func parsePortForwards(specs []string) ([]portForward, error) {
+	var forwards []portForward
+	seen := make(map[int]portForward)
+	for _, spec := range specs {
+		f, err := parsePortForward(spec)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[f.Local]; ok {
+			if prev != f {
+				return nil, fmt.Errorf("local port %d is forwarded twice (%s and %s)", f.Local, prev, f)
+			}
+			continue
+		}
+		seen[f.Local] = f
+		forwards = append(forwards, f)
+	}
+	return forwards, nil
+}
+
+// startPortForwards opens an SSH connection to the sandbox and listens on
+// localhost for each forward. Ports that cannot be bound are skipped with a
+// warning. The returned func closes the listeners and the connection.
+func startPortForwards(ctx context.Context, sandbox *claudevps.Sandbox, forwards []portForward) (func(), error) {
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		return nil, err
+	}
+
+	var listeners []net.Listener
+	for _, f := range forwards {
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(f.Local)))
+		if err != nil {
+			color.Yellow("⚠ Port %d not forwarded: %v", f.Local, err)
+			continue
+		}
+		listeners = append(listeners, ln)
+
+		remoteAddr := net.JoinHostPort("localhost", strconv.Itoa(f.Remote))
+		go func() {
+			if err := conn.Forward(ln, remoteAddr); err != nil {
+				debuglog.Printf("forward %s: %v", f, err)
+			}
+		}()
+		fmt.Printf("✓ Forwarding localhost:%d → sandbox:%d\n", f.Local, f.Remote)
+	}
+
+	return func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+		conn.Close()
+	}, nil
+}
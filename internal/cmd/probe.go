@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+const probeTimeout = 5 * time.Second
+
+// probeResult describes the outcome of an SSH reachability probe
+type probeResult struct {
+	Reachable bool          `json:"reachable"`
+	Latency   time.Duration `json:"latencyNs,omitempty"`
+	Banner    string        `json:"banner,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func (p probeResult) String() string {
+	if !p.Reachable {
+		return "unreachable: " + p.Error
+	}
+	if p.Error != "" {
+		return fmt.Sprintf("tcp ok, %s (%s)", p.Error, p.Latency.Round(time.Millisecond))
+	}
+	if p.Banner == "" {
+		return fmt.Sprintf("tcp ok (%s)", p.Latency.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("ok (%s, %s)", p.Latency.Round(time.Millisecond), p.Banner)
+}
+
+// probeSSH opens a TCP connection to the endpoint and reads the SSH identification
+// banner. Latency is the TCP connect round trip.
+func probeSSH(ctx context.Context, host string, port int, timeout time.Duration) probeResult {
+	if host == "" {
+		return probeResult{Error: "no SSH endpoint"}
+	}
+	if port == 0 {
+		port = 22
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := net.Dialer{}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return probeResult{Error: describeDialError(err)}
+	}
+	defer conn.Close()
+
+	result := probeResult{Reachable: true, Latency: time.Since(start)}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		result.Error = "no SSH banner received"
+		return result
+	}
+
+	banner := strings.TrimSpace(line)
+	if !strings.HasPrefix(banner, "SSH-") {
+		result.Error = "endpoint did not answer with an SSH banner"
+		return result
+	}
+	result.Banner = banner
+	return result
+}
+
+// describeDialError turns a dial error into a short diagnosis
+func describeDialError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "DNS lookup failed"
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return "connection refused"
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"):
+		return "timed out"
+	case strings.Contains(msg, "no route to host"), strings.Contains(msg, "network is unreachable"):
+		return "network unreachable"
+	default:
+		return msg
+	}
+}
+
+// probeSandboxes probes every sandbox concurrently, keyed by sandbox ID
+func probeSandboxes(ctx context.Context, sandboxes []claudevps.Sandbox) map[string]probeResult {
+	results := make(map[string]probeResult, len(sandboxes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, s := range sandboxes {
+		if !isRunningStatus(s.Status) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(s claudevps.Sandbox) {
+			defer wg.Done()
+			result := probeSSH(ctx, s.SSHHost, s.SSHPort, probeTimeout)
+			mu.Lock()
+			results[s.ID] = result
+			mu.Unlock()
+		}(s)
+	}
+
+	wg.Wait()
+	return results
+}
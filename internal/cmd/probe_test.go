@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startBannerServer(t *testing.T, banner string) (string, int) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(banner))
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbeSSH_Reachable(t *testing.T) {
+	host, port := startBannerServer(t, "SSH-2.0-OpenSSH_9.6\r\n")
+
+	result := probeSSH(context.Background(), host, port, time.Second)
+	if !result.Reachable {
+		t.Fatalf("probeSSH() reachable = false, error = %q", result.Error)
+	}
+	if result.Banner != "SSH-2.0-OpenSSH_9.6" {
+		t.Fatalf("probeSSH() banner = %q, want %q", result.Banner, "SSH-2.0-OpenSSH_9.6")
+	}
+	if !strings.HasPrefix(result.String(), "ok (") {
+		t.Fatalf("probeResult.String() = %q, want ok summary", result.String())
+	}
+}
+
+func TestProbeSSH_NotSSH(t *testing.T) {
+	host, port := startBannerServer(t, "HTTP/1.1 400 Bad Request\r\n")
+
+	result := probeSSH(context.Background(), host, port, time.Second)
+	if !result.Reachable {
+		t.Fatal("probeSSH() should report TCP reachability even without an SSH banner")
+	}
+	if result.Banner != "" {
+		t.Fatalf("probeSSH() banner = %q, want empty", result.Banner)
+	}
+	if got := result.String(); !strings.HasPrefix(got, "tcp ok, endpoint did not answer with an SSH banner (") {
+		t.Fatalf("probeResult.String() = %q, want the missing banner reported", got)
+	}
+}
+
+func TestProbeSSH_Refused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	result := probeSSH(context.Background(), "127.0.0.1", port, time.Second)
+	if result.Reachable {
+		t.Fatal("probeSSH() reachable = true, want false")
+	}
+	if result.Error != "connection refused" {
+		t.Fatalf("probeSSH() error = %q, want %q", result.Error, "connection refused")
+	}
+}
+
+func TestProbeSSH_NoHost(t *testing.T) {
+	result := probeSSH(context.Background(), "", 22, time.Second)
+	if result.Reachable || result.Error != "no SSH endpoint" {
+		t.Fatalf("probeSSH() = %+v, want no SSH endpoint error", result)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+// Values of --progress
+const (
+	progressText = "text"
+	progressJSON = "json"
+)
+
+// progressMode is --progress on commands with long-running steps
+var progressMode = progressText
+
+// Statuses of a progress event
+const (
+	progressRunning = "running"
+	progressDone    = "done"
+	progressFailed  = "failed"
+)
+
+// progressEvent is one line of --progress json output
+type progressEvent struct {
+	Time       time.Time `json:"time"`
+	Stage      string    `json:"stage"`
+	Status     string    `json:"status"`
+	Percent    *int      `json:"percent,omitempty"`
+	Bytes      int64     `json:"bytes,omitempty"`
+	TotalBytes int64     `json:"total_bytes,omitempty"`
+	Sandbox    string    `json:"sandbox,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// progressOut receives progress events. It is a variable so tests can
+// capture them.
+var progressOut io.Writer = os.Stderr
+
+var progressMu sync.Mutex
+
+// addProgressFlag registers --progress on c
+func addProgressFlag(c *cobra.Command) {
+	c.Flags().StringVar(&progressMode, "progress", progressText, "how to show progress (text|json); json writes one event per line to stderr for GUIs and editors")
+}
+
+// validProgress checks --progress
+func validProgress() error {
+	if progressMode != progressText && progressMode != progressJSON {
+		return fmt.Errorf("invalid --progress %q (must be text or json)", progressMode)
+	}
+	return nil
+}
+
+// jsonProgress reports whether progress is reported as JSON events
+func jsonProgress() bool {
+	return progressMode == progressJSON
+}
+
+// emitProgress writes e with --progress json and does nothing otherwise
+func emitProgress(e progressEvent) {
+	if !jsonProgress() {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progressOut.Write(append(line, '\n'))
+}
+
+// emitStage reports a stage without a measure of how far it has got
+func emitStage(stage, status, message string) {
+	emitProgress(progressEvent{Stage: stage, Status: status, Message: message})
+}
+
+func percentOf(n, total int64) *int {
+	if total <= 0 {
+		return nil
+	}
+	p := int(min(n, total) * 100 / total)
+	return &p
+}
+
+// transferProgress shows how far a transfer of a known size has got
+type transferProgress interface {
+	Set64(n int64) error
+	Finish() error
+}
+
+// newTransferBar returns a progress bar for a transfer of size bytes, or with
+// --progress json one that reports it as events of stage
+func newTransferBar(size int64, description, stage string) transferProgress {
+	if jsonProgress() {
+		return &jsonTransfer{stage: stage, total: size, last: -1}
+	}
+	return progressbar.NewOptions64(
+		size,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(os.Stdout),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionFullWidth(),
+	)
+}
+
+// jsonTransfer reports a transfer as events, one per percent of progress
+type jsonTransfer struct {
+	stage string
+	total int64
+	last  int // the last percent reported
+}
+
+func (t *jsonTransfer) Set64(n int64) error {
+	p := percentOf(n, t.total)
+	if p == nil || *p == t.last {
+		return nil
+	}
+	t.last = *p
+	emitProgress(progressEvent{Stage: t.stage, Status: progressRunning, Percent: p, Bytes: n, TotalBytes: t.total})
+	return nil
+}
+
+// Finish reports the transfer as done. Like a progress bar it is only
+// finished on success; callers report failures.
+func (t *jsonTransfer) Finish() error {
+	emitProgress(progressEvent{Stage: t.stage, Status: progressDone, Percent: percentOf(t.total, t.total), Bytes: t.total, TotalBytes: t.total})
+	return nil
+}
+
+// progressLogWriter turns each line written to it into a running event of
+// stage, such as the lines of a build log
+type progressLogWriter struct {
+	stage   string
+	partial []byte
+}
+
+func (w *progressLogWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		emitStage(w.stage, progressRunning, strings.TrimSuffix(string(w.partial[:i]), "\r"))
+		w.partial = w.partial[i+1:]
+	}
+}
+
+// Flush reports an unterminated last line
+func (w *progressLogWriter) Flush() {
+	if len(w.partial) > 0 {
+		emitStage(w.stage, progressRunning, string(w.partial))
+		w.partial = nil
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// captureProgress turns on --progress json and collects the events
+func captureProgress(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	oldMode, oldOut := progressMode, progressOut
+	progressMode, progressOut = progressJSON, &buf
+	t.Cleanup(func() { progressMode, progressOut = oldMode, oldOut })
+	return &buf
+}
+
+func parseProgress(t *testing.T, buf *bytes.Buffer) []progressEvent {
+	t.Helper()
+	var events []progressEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e progressEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("progress line %q is not JSON: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestJSONTransfer(t *testing.T) {
+	buf := captureProgress(t)
+
+	bar := newTransferBar(1000, "Uploading", "upload")
+	for n := int64(0); n <= 1000; n += 5 {
+		bar.Set64(n)
+	}
+	bar.Finish()
+
+	events := parseProgress(t, buf)
+	// One event per percent from 0 to 100, then done
+	if len(events) != 102 {
+		t.Fatalf("got %d events, want 102", len(events))
+	}
+	first, last := events[0], events[len(events)-1]
+	if first.Stage != "upload" || first.Status != progressRunning || first.Percent == nil || *first.Percent != 0 || first.TotalBytes != 1000 {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if last.Status != progressDone || last.Percent == nil || *last.Percent != 100 || last.Bytes != 1000 {
+		t.Errorf("Unexpected last event: %+v", last)
+	}
+}
+
+func TestProgressLogWriter(t *testing.T) {
+	buf := captureProgress(t)
+
+	w := &progressLogWriter{stage: "build"}
+	w.Write([]byte("Step 1/2 : FROM ubuntu\r\nStep 2"))
+	w.Write([]byte("/2 : RUN make\nlast"))
+	w.Flush()
+
+	var got []string
+	for _, e := range parseProgress(t, buf) {
+		if e.Stage != "build" || e.Status != progressRunning {
+			t.Errorf("Unexpected event: %+v", e)
+		}
+		got = append(got, e.Message)
+	}
+	want := []string{"Step 1/2 : FROM ubuntu", "Step 2/2 : RUN make", "last"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("messages = %q, want %q", got, want)
+	}
+}
+
+func TestEmitProgress_TextMode(t *testing.T) {
+	buf := captureProgress(t)
+	progressMode = progressText
+
+	emitStage("build", progressRunning, "")
+	if buf.Len() != 0 {
+		t.Errorf("expected no events without --progress json, got %q", buf.String())
+	}
+}
+
+func TestRunUp_ProgressJSON(t *testing.T) {
+	polls := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-prog", Name: "gui", Status: "provisioning"})
+		case "/sandboxes/sbx-prog/status":
+			polls++
+			sandbox := claudevps.Sandbox{ID: "sbx-prog", Name: "gui", Status: "provisioning",
+				Provisioning: &claudevps.ProvisioningProgress{Stage: claudevps.StagePullingImage, Progress: 40}}
+			if polls > 1 {
+				sandbox.Status, sandbox.Provisioning = "running", nil
+			}
+			json.NewEncoder(w).Encode(sandbox)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+	oldInterval := pollInitialInterval
+	pollInitialInterval = time.Millisecond
+	defer func() { pollInitialInterval = oldInterval }()
+	buf := captureProgress(t)
+
+	upName, upDetach, upNoDotfiles, upOutput = "gui", false, true, "json"
+	defer func() { upName, upNoDotfiles, upOutput = "", false, "" }()
+
+	if _, err := captureStdout(t, func() error { return runUp(nil, nil) }); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	events := parseProgress(t, buf)
+	if len(events) != 2 {
+		t.Fatalf("got events %+v, want a stage and ready", events)
+	}
+	if e := events[0]; e.Stage != claudevps.StagePullingImage || e.Status != progressRunning || e.Percent == nil || *e.Percent != 40 || e.Sandbox != "sbx-prog" {
+		t.Errorf("Unexpected stage event: %+v", e)
+	}
+	if e := events[1]; e.Stage != "ready" || e.Status != progressDone {
+		t.Errorf("Unexpected ready event: %+v", e)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/spf13/cobra"
+)
+
+var promptFormat string
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print the current sandbox for a shell prompt",
+	Long: `Print the current sandbox of this directory and a status glyph, for use
+in PS1 or prompt tools such as starship.
+
+Only .cvps.yaml and the local sandbox cache are read, never the network, so
+the status is as fresh as the last command that listed sandboxes. Nothing is
+printed outside a project.
+
+Glyphs: ● running, ◐ starting, ○ stopped, ✗ failed, ? unknown.
+
+Placeholders for --format: {name}, {id}, {status}, {glyph}.`,
+	Example: `  # bash / zsh
+  PS1='$(cvps prompt --format "[{name} {glyph}] ")'"$PS1"
+
+  # starship (~/.config/starship.toml)
+  [custom.cvps]
+  command = "cvps prompt"
+  when = "test -f .cvps.yaml"`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+
+	promptCmd.Flags().StringVar(&promptFormat, "format", "{name} {glyph}", "output template")
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	// A prompt must never fail loudly, so unreadable files print nothing
+	c, err := localctx.Load(".")
+	if err != nil || c == nil {
+		return nil
+	}
+	e := c.CurrentEntry()
+	if e == nil {
+		return nil
+	}
+
+	status := ""
+	if cached, err := cache.LoadSandboxes(); err == nil && cached != nil {
+		if s := cached.Find(e.SandboxID); s != nil {
+			status = s.Status
+		}
+	}
+
+	name := e.Name
+	if name == "" {
+		name = e.SandboxID
+	}
+	fmt.Print(strings.NewReplacer(
+		"{name}", name,
+		"{id}", e.SandboxID,
+		"{status}", status,
+		"{glyph}", statusGlyph(status),
+	).Replace(promptFormat))
+	return nil
+}
+
+// statusGlyph is a one-character summary of a sandbox status
+func statusGlyph(status string) string {
+	switch strings.ToLower(status) {
+	case "running":
+		return "●"
+	case "provisioning", "starting", "stopping":
+		return "◐"
+	case "stopped":
+		return "○"
+	case "failed", "error":
+		return "✗"
+	default:
+		return "?"
+	}
+}
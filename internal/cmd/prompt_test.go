@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func capturePrompt(t *testing.T) string {
+	t.Helper()
+	r, w, _ := os.Pipe()
+	oldStdout := os.Stdout
+	os.Stdout = w
+	err := runPrompt(nil, nil)
+	w.Close()
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("runPrompt() error = %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestRunPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	defer func(f string) { promptFormat = f }(promptFormat)
+	promptFormat = "{name} {glyph}"
+
+	if got := capturePrompt(t); got != "" {
+		t.Errorf("Expected no output outside a project, got %q", got)
+	}
+
+	saveLocalContext("sbx-1", "web")
+	if got := capturePrompt(t); got != "web ?" {
+		t.Errorf("Expected unknown status without cache, got %q", got)
+	}
+
+	cache.SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-1", Name: "web", Status: "running"}})
+	if got := capturePrompt(t); got != "web ●" {
+		t.Errorf("Expected running glyph, got %q", got)
+	}
+
+	promptFormat = "[{id}:{status}]"
+	if got := capturePrompt(t); got != "[sbx-1:running]" {
+		t.Errorf("Expected custom format, got %q", got)
+	}
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/spf13/cobra"
+)
+
+var (
+	psSort  string
+	psLimit int
+	psJSON  bool
+
+	psKillSandbox string
+	psKillSignal  string
+)
+
+// psCommand lists processes in a fixed, parseable format
+const psCommand = "ps -eo pid=,user=,pcpu=,pmem=,rss=,etime=,args="
+
+var psCmd = &cobra.Command{
+	Use:   "ps [sandbox]",
+	Short: "List processes running in a sandbox",
+	Long: `List processes running in a sandbox, busiest first.
+
+Use 'cvps ps kill' to stop a runaway process.`,
+	Example: `  # Top processes by CPU in the current sandbox
+  cvps ps
+
+  # Sort by memory and show everything
+  cvps ps --sort mem --limit 0
+
+  # Kill a process
+  cvps ps kill 4242`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPs,
+}
+
+var psKillCmd = &cobra.Command{
+	Use:   "kill <pid>...",
+	Short: "Send a signal to sandbox processes",
+	Example: `  # Terminate a process in the current sandbox
+  cvps ps kill 4242
+
+  # Force kill in a named sandbox
+  cvps ps kill 4242 --sandbox myproject --signal KILL`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPsKill,
+}
+
+func init() {
+	rootCmd.AddCommand(psCmd)
+	psCmd.AddCommand(psKillCmd)
+
+	psCmd.Flags().StringVar(&psSort, "sort", "cpu", "sort order (cpu|mem|pid)")
+	psCmd.Flags().IntVarP(&psLimit, "limit", "n", 20, "maximum number of processes to show (0 for all)")
+	psCmd.Flags().BoolVar(&psJSON, "json", false, "output in JSON format")
+
+	psKillCmd.Flags().StringVarP(&psKillSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	psKillCmd.Flags().StringVar(&psKillSignal, "signal", "TERM", "signal to send")
+}
+
+// process is a single row of remote ps output
+type process struct {
+	PID     int     `json:"pid"`
+	User    string  `json:"user"`
+	CPU     float64 `json:"cpu"`
+	Mem     float64 `json:"mem"`
+	RSS     int64   `json:"rssBytes"`
+	Elapsed string  `json:"elapsed"`
+	Command string  `json:"command"`
+}
+
+func runPs(cmd *cobra.Command, args []string) error {
+	switch psSort {
+	case "cpu", "mem", "pid":
+	default:
+		return fmt.Errorf("invalid --sort value %q (use cpu, mem or pid)", psSort)
+	}
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	conn, err := openSandboxSSHForRef(ref)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := conn.Output(context.Background(), psCommand)
+	if err != nil {
+		return fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	procs := parsePs(out)
+	sortProcesses(procs, psSort)
+	if psLimit > 0 && len(procs) > psLimit {
+		procs = procs[:psLimit]
+	}
+
+	if psJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(procs)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tUSER\tCPU%\tMEM%\tRSS\tELAPSED\tCOMMAND")
+	for _, p := range procs {
+		fmt.Fprintf(w, "%d\t%s\t%.1f\t%.1f\t%s\t%s\t%s\n",
+			p.PID, p.User, p.CPU, p.Mem, formatBytes(p.RSS), p.Elapsed, truncateCommand(p.Command, 60))
+	}
+	w.Flush()
+	return nil
+}
+
+// parsePids checks kill arguments are single process IDs. Zero and negative
+// values would make kill signal process groups or every process of the user.
+func parsePids(args []string) ([]string, error) {
+	pids := make([]string, 0, len(args))
+	for _, arg := range args {
+		pid, err := strconv.Atoi(arg)
+		if err != nil || pid <= 0 {
+			return nil, fmt.Errorf("invalid pid: %s", arg)
+		}
+		pids = append(pids, strconv.Itoa(pid))
+	}
+	return pids, nil
+}
+
+func runPsKill(cmd *cobra.Command, args []string) error {
+	pids, err := parsePids(args)
+	if err != nil {
+		return err
+	}
+
+	signal := strings.TrimPrefix(strings.ToUpper(psKillSignal), "SIG")
+	if signal == "" || strings.IndexFunc(signal, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) >= 0 {
+		return fmt.Errorf("invalid signal: %s", psKillSignal)
+	}
+
+	conn, err := openSandboxSSHForRef(psKillSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Output(context.Background(), "kill -s "+signal+" -- "+strings.Join(pids, " ")); err != nil {
+		return fmt.Errorf("failed to signal processes: %w", err)
+	}
+
+	fmt.Printf("Sent SIG%s to %s\n", signal, strings.Join(pids, ", "))
+	return nil
+}
+
+// openSandboxSSHForRef authenticates, resolves ref and connects over SSH
+func openSandboxSSHForRef(ref string) (*remote.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return nil, fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, _, err := openSandboxSSH(ctx, client, sandboxID)
+	return conn, err
+}
+
+// parsePs parses the output of psCommand, skipping malformed rows
+func parsePs(out []byte) []process {
+	var procs []process
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[2], 64)
+		mem, _ := strconv.ParseFloat(fields[3], 64)
+		rss, _ := strconv.ParseInt(fields[4], 10, 64)
+
+		procs = append(procs, process{
+			PID:     pid,
+			User:    fields[1],
+			CPU:     cpu,
+			Mem:     mem,
+			RSS:     rss * 1024,
+			Elapsed: fields[5],
+			Command: strings.Join(fields[6:], " "),
+		})
+	}
+	return procs
+}
+
+func sortProcesses(procs []process, by string) {
+	sort.SliceStable(procs, func(i, j int) bool {
+		switch by {
+		case "mem":
+			return procs[i].RSS > procs[j].RSS
+		case "pid":
+			return procs[i].PID < procs[j].PID
+		default:
+			return procs[i].CPU > procs[j].CPU
+		}
+	})
+}
+
+func truncateCommand(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
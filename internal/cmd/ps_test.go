@@ -0,0 +1,58 @@
+package cmd
+
+import "testing"
+
+func TestParsePsAndSort(t *testing.T) {
+	out := []byte(`    1 root      0.0  0.1  11520 02:13:45 /sbin/init
+ 4242 ubuntu   98.5  2.0 204800    05:12 node server.js --port 3000
+  310 ubuntu    1.2 12.5 819200 01:02:03 java -jar app.jar
+garbage line
+`)
+
+	procs := parsePs(out)
+	if len(procs) != 3 {
+		t.Fatalf("parsePs() returned %d processes, want 3", len(procs))
+	}
+	if procs[1].Command != "node server.js --port 3000" {
+		t.Fatalf("Command = %q, want full argv", procs[1].Command)
+	}
+	if procs[1].RSS != 204800*1024 {
+		t.Fatalf("RSS = %d, want %d", procs[1].RSS, 204800*1024)
+	}
+
+	sortProcesses(procs, "cpu")
+	if procs[0].PID != 4242 {
+		t.Fatalf("sort cpu: first pid = %d, want 4242", procs[0].PID)
+	}
+
+	sortProcesses(procs, "mem")
+	if procs[0].PID != 310 {
+		t.Fatalf("sort mem: first pid = %d, want 310", procs[0].PID)
+	}
+
+	sortProcesses(procs, "pid")
+	if procs[0].PID != 1 {
+		t.Fatalf("sort pid: first pid = %d, want 1", procs[0].PID)
+	}
+}
+
+func TestTruncateCommand(t *testing.T) {
+	if got := truncateCommand("short", 10); got != "short" {
+		t.Errorf("truncateCommand() = %q, want unchanged", got)
+	}
+	if got := truncateCommand("a very long command line", 10); got != "a very ..." {
+		t.Errorf("truncateCommand() = %q, want %q", got, "a very ...")
+	}
+}
+
+func TestParsePids(t *testing.T) {
+	pids, err := parsePids([]string{"4242", "+310"})
+	if err != nil || len(pids) != 2 || pids[0] != "4242" || pids[1] != "310" {
+		t.Fatalf("parsePids() = %v, %v", pids, err)
+	}
+	for _, arg := range []string{"-1", "0", "12abc", ""} {
+		if _, err := parsePids([]string{arg}); err == nil {
+			t.Errorf("parsePids(%q) expected error", arg)
+		}
+	}
+}
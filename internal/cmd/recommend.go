@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// The p95 utilisation a right-sized sandbox runs at, leaving headroom
+	// for spikes
+	recommendTargetCPU    = 0.7
+	recommendTargetMemory = 0.75
+
+	// recommendMinSamples is how many samples a sandbox needs before it is
+	// judged
+	recommendMinSamples = 12
+)
+
+// sizeSteps are the CPU core and memory GB sizes recommendations pick from
+var sizeSteps = []int{1, 2, 4, 8, 16, 32, 64}
+
+var (
+	recommendWindow time.Duration
+	recommendApply  bool
+	recommendForce  bool
+	recommendJSON   bool
+)
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend [sandbox]",
+	Short: "Suggest CPU and memory sizes from past usage",
+	Long: `Analyze the CPU and memory a sandbox actually used over a recent window and
+suggest a size that fits it, with headroom: the 95th percentile should use
+about 70% of the CPU and 75% of the memory.
+
+Without a sandbox, every sandbox is analyzed. With --apply, suggested sizes
+are applied after confirmation; running sandboxes restart to resize.`,
+	Example: `  # Suggestions for every sandbox, based on the last week
+  cvps recommend
+
+  # Look at the last 30 days of one sandbox
+  cvps recommend web --window 720h
+
+  # Apply every suggestion without prompting
+  cvps recommend --apply --force`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runRecommend,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(recommendCmd)
+
+	recommendCmd.Flags().DurationVar(&recommendWindow, "window", 7*24*time.Hour, "how much history to analyze")
+	recommendCmd.Flags().BoolVar(&recommendApply, "apply", false, "resize sandboxes to the suggested sizes")
+	recommendCmd.Flags().BoolVarP(&recommendForce, "force", "f", false, "with --apply, skip confirmation prompts")
+	recommendCmd.Flags().BoolVar(&recommendJSON, "json", false, "output in JSON format")
+	recommendCmd.MarkFlagsMutuallyExclusive("apply", "json")
+}
+
+// recommendation is the suggested size of one sandbox
+type recommendation struct {
+	SandboxID string `json:"sandboxId"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Samples   int    `json:"samples"`
+
+	CPUCores    int     `json:"cpuCores"`
+	MemoryGB    int     `json:"memoryGb"`
+	P95CPUCores float64 `json:"p95CpuCores"`
+	P95MemoryGB float64 `json:"p95MemoryGb"`
+
+	RecommendedCPUCores int `json:"recommendedCpuCores"`
+	RecommendedMemoryGB int `json:"recommendedMemoryGb"`
+
+	// Saved per month of running time; negative if the suggestion costs more
+	MonthlySavings float64 `json:"monthlySavings,omitempty"`
+	Currency       string  `json:"currency,omitempty"`
+}
+
+// enoughData reports whether there were enough samples to judge the sandbox
+func (r *recommendation) enoughData() bool {
+	return r.Samples >= recommendMinSamples
+}
+
+// changed reports whether the suggested size differs from the current one
+func (r *recommendation) changed() bool {
+	return r.enoughData() && (r.RecommendedCPUCores != r.CPUCores || r.RecommendedMemoryGB != r.MemoryGB)
+}
+
+// percentile returns the p-th percentile (0-1) of values by nearest rank
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// fitSize returns the smallest size step that holds need
+func fitSize(need float64) int {
+	for _, s := range sizeSteps {
+		if float64(s) >= need {
+			return s
+		}
+	}
+	return sizeSteps[len(sizeSteps)-1]
+}
+
+// recommendSize works out the suggested size of a sandbox from its samples.
+// pricing may be nil, in which case no savings are estimated.
+func recommendSize(sandbox claudevps.Sandbox, samples []claudevps.SandboxMetrics, pricing *claudevps.Pricing) recommendation {
+	r := recommendation{
+		SandboxID:           sandbox.ID,
+		Name:                sandbox.Name,
+		Status:              sandbox.Status,
+		Samples:             len(samples),
+		CPUCores:            sandbox.CPUCores,
+		MemoryGB:            sandbox.MemoryGB,
+		RecommendedCPUCores: sandbox.CPUCores,
+		RecommendedMemoryGB: sandbox.MemoryGB,
+	}
+	if !r.enoughData() {
+		return r
+	}
+
+	cpu := make([]float64, len(samples))
+	mem := make([]float64, len(samples))
+	for i, s := range samples {
+		// CPUPercent is relative to all allocated cores
+		cpu[i] = s.CPUPercent / 100 * float64(sandbox.CPUCores)
+		mem[i] = float64(s.MemoryUsedBytes) / (1 << 30)
+	}
+	r.P95CPUCores = percentile(cpu, 0.95)
+	r.P95MemoryGB = percentile(mem, 0.95)
+	r.RecommendedCPUCores = fitSize(r.P95CPUCores / recommendTargetCPU)
+	r.RecommendedMemoryGB = fitSize(r.P95MemoryGB / recommendTargetMemory)
+
+	if pricing != nil {
+		now := pricing.HourlyCost(r.CPUCores, r.MemoryGB, 0)
+		next := pricing.HourlyCost(r.RecommendedCPUCores, r.RecommendedMemoryGB, 0)
+		r.MonthlySavings = (now - next) * claudevps.HoursPerMonth
+		r.Currency = pricing.Currency
+	}
+	return r
+}
+
+func runRecommend(cmd *cobra.Command, args []string) error {
+	if recommendWindow < time.Hour {
+		return fmt.Errorf("--window must be at least 1h")
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sandboxes []claudevps.Sandbox
+	if len(args) > 0 {
+		id, err := resolveSandboxRef(ctx, client, args[0])
+		if err != nil {
+			return err
+		}
+		sandbox, err := client.GetSandbox(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get sandbox: %w", err)
+		}
+		sandboxes = []claudevps.Sandbox{*sandbox}
+	} else if sandboxes, err = listAllSandboxesForConnect(ctx, client); err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	// Savings are optional; recommendations work without them
+	pricing, _ := client.GetPricing(ctx)
+	recs, err := recommendSizes(ctx, client, sandboxes, pricing)
+	if err != nil {
+		return err
+	}
+
+	if recommendJSON {
+		if recs == nil {
+			recs = []recommendation{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+	}
+
+	if len(recs) == 0 {
+		fmt.Println("No sandboxes found. Run 'cvps up' to create one.")
+		return nil
+	}
+	printRecommendations(recs)
+
+	if recommendApply {
+		return applyRecommendations(ctx, client, recs)
+	}
+	for _, r := range recs {
+		if r.changed() {
+			fmt.Println("\nRun 'cvps recommend --apply' to resize.")
+			break
+		}
+	}
+	return nil
+}
+
+// recommendSizes fetches the history of each sandbox and works out its
+// suggested size, keeping the order of sandboxes
+func recommendSizes(ctx context.Context, client *claudevps.Client, sandboxes []claudevps.Sandbox, pricing *claudevps.Pricing) ([]recommendation, error) {
+	recs := make([]recommendation, len(sandboxes))
+	errs := make([]error, len(sandboxes))
+	sem := make(chan struct{}, listPageConcurrency)
+	var wg sync.WaitGroup
+	for i, sandbox := range sandboxes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			history, err := client.GetSandboxMetricsHistory(ctx, sandbox.ID, recommendWindow)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to get metrics of %s: %w", sandbox.Name, err)
+				return
+			}
+			recs[i] = recommendSize(sandbox, history.Samples, pricing)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+func printRecommendations(recs []recommendation) {
+	var rightSized, noData []string
+	for _, r := range recs {
+		switch {
+		case !r.enoughData():
+			noData = append(noData, fmt.Sprintf("%s (%d samples)", r.Name, r.Samples))
+		case !r.changed():
+			rightSized = append(rightSized, r.Name)
+		default:
+			fmt.Printf("%s (%s)\n", r.Name, r.SandboxID)
+			if r.RecommendedCPUCores != r.CPUCores {
+				fmt.Printf("  p95 CPU %.1f of %d cores — consider %d\n", r.P95CPUCores, r.CPUCores, r.RecommendedCPUCores)
+			}
+			if r.RecommendedMemoryGB != r.MemoryGB {
+				fmt.Printf("  p95 memory %.1fGB of %dGB — consider %dGB\n", r.P95MemoryGB, r.MemoryGB, r.RecommendedMemoryGB)
+			}
+			switch {
+			case r.MonthlySavings > 0:
+				fmt.Printf("  Saves ~%s per month of running time\n", formatMoney(r.MonthlySavings, r.Currency))
+			case r.MonthlySavings < 0:
+				color.Yellow("  Costs ~%s more per month of running time, but the sandbox is short on resources", formatMoney(-r.MonthlySavings, r.Currency))
+			}
+			fmt.Println()
+		}
+	}
+	if len(rightSized) > 0 {
+		fmt.Printf("Right-sized: %s\n", strings.Join(rightSized, ", "))
+	}
+	if len(noData) > 0 {
+		fmt.Printf("Not enough data yet: %s\n", strings.Join(noData, ", "))
+	}
+}
+
+// applyRecommendations resizes each sandbox with a changed suggestion,
+// asking first unless --force is set
+func applyRecommendations(ctx context.Context, client *claudevps.Client, recs []recommendation) error {
+	in := bufio.NewReader(os.Stdin)
+	failed := 0
+	for _, r := range recs {
+		if !r.changed() {
+			continue
+		}
+		if !recommendForce {
+			restart := ""
+			if isRunningStatus(r.Status) {
+				restart = " It will restart."
+			}
+			fmt.Printf("\nResize %s to %d CPU, %d GB?%s [y/N]: ", r.Name, r.RecommendedCPUCores, r.RecommendedMemoryGB, restart)
+			input, _ := in.ReadString('\n')
+			input = strings.ToLower(strings.TrimSpace(input))
+			if input != "y" && input != "yes" {
+				fmt.Printf("Skipped %s\n", r.Name)
+				continue
+			}
+		}
+		_, err := client.ResizeSandbox(ctx, r.SandboxID, &claudevps.ResizeSandboxRequest{
+			CPUCores: r.RecommendedCPUCores,
+			MemoryGB: r.RecommendedMemoryGB,
+		})
+		if err != nil {
+			color.Yellow("⚠ Failed to resize %s: %v", r.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ Resized %s to %d CPU, %d GB\n", r.Name, r.RecommendedCPUCores, r.RecommendedMemoryGB)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to resize %d sandbox(es)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// usageSamples returns n samples at the given CPU percent and memory GB
+func usageSamples(n int, cpuPercent, memoryGB float64) []claudevps.SandboxMetrics {
+	samples := make([]claudevps.SandboxMetrics, n)
+	for i := range samples {
+		samples[i] = claudevps.SandboxMetrics{CPUPercent: cpuPercent, MemoryUsedBytes: int64(memoryGB * (1 << 30))}
+	}
+	return samples
+}
+
+func TestPercentile(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[99-i] = float64(i + 1)
+	}
+	if got := percentile(values, 0.95); got != 95 {
+		t.Errorf("percentile(0.95) = %v, want 95", got)
+	}
+	if got := percentile([]float64{3}, 0.95); got != 3 {
+		t.Errorf("percentile(single) = %v, want 3", got)
+	}
+}
+
+func TestRecommendSize(t *testing.T) {
+	pricing := &claudevps.Pricing{Currency: "USD", CPUCoreHour: 0.01, MemoryGBHour: 0.005}
+	sandbox := claudevps.Sandbox{ID: "sbx-1", Name: "web", CPUCores: 4, MemoryGB: 8}
+
+	tests := []struct {
+		name    string
+		samples []claudevps.SandboxMetrics
+		cpu     int
+		mem     int
+		changed bool
+	}{
+		{"oversized", usageSamples(20, 10, 1.1), 1, 2, true},
+		{"right-sized", usageSamples(20, 50, 5), 4, 8, false},
+		{"undersized", usageSamples(20, 95, 7.5), 8, 16, true},
+		{"too few samples", usageSamples(3, 10, 1.1), 4, 8, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := recommendSize(sandbox, tt.samples, pricing)
+			if r.RecommendedCPUCores != tt.cpu || r.RecommendedMemoryGB != tt.mem {
+				t.Errorf("recommendSize() = %d CPU, %d GB, want %d CPU, %d GB", r.RecommendedCPUCores, r.RecommendedMemoryGB, tt.cpu, tt.mem)
+			}
+			if r.changed() != tt.changed {
+				t.Errorf("changed() = %v, want %v", r.changed(), tt.changed)
+			}
+		})
+	}
+
+	r := recommendSize(sandbox, usageSamples(20, 10, 1.1), pricing)
+	// (3 cores * 0.01 + 6 GB * 0.005) per hour
+	if want := 0.06 * claudevps.HoursPerMonth; r.MonthlySavings < want-0.01 || r.MonthlySavings > want+0.01 {
+		t.Errorf("MonthlySavings = %v, want %v", r.MonthlySavings, want)
+	}
+}
+
+func TestRunRecommend_Apply(t *testing.T) {
+	var resized []claudevps.ResizeSandboxRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sandboxes":
+			json.NewEncoder(w).Encode(claudevps.SandboxList{Total: 2, Data: []claudevps.Sandbox{
+				{ID: "sbx-big", Name: "big", Status: "running", CPUCores: 4, MemoryGB: 8},
+				{ID: "sbx-fit", Name: "fit", Status: "running", CPUCores: 2, MemoryGB: 4},
+			}})
+		case "/sandboxes/sbx-big/metrics/history":
+			json.NewEncoder(w).Encode(claudevps.MetricsHistory{Samples: usageSamples(20, 10, 1.1)})
+		case "/sandboxes/sbx-fit/metrics/history":
+			json.NewEncoder(w).Encode(claudevps.MetricsHistory{Samples: usageSamples(20, 60, 2.5)})
+		case "/pricing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/sandboxes/sbx-big/resize":
+			var req claudevps.ResizeSandboxRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			resized = append(resized, req)
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-big"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	recommendApply, recommendForce = true, true
+	defer func() { recommendApply, recommendForce = false, false }()
+
+	out, err := captureStdout(t, func() error {
+		return runRecommend(nil, nil)
+	})
+	if err != nil {
+		t.Fatalf("runRecommend() error = %v", err)
+	}
+	if len(resized) != 1 || resized[0].CPUCores != 1 || resized[0].MemoryGB != 2 {
+		t.Errorf("Unexpected resizes: %+v", resized)
+	}
+	for _, want := range []string{"p95 memory 1.1GB of 8GB — consider 2GB", "Right-sized: fit", "✓ Resized big to 1 CPU, 2 GB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %q", want, out)
+		}
+	}
+}
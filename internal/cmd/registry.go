@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// dockerHubRegistry is the registry of image references without a host
+const dockerHubRegistry = "docker.io"
+
+var (
+	registryUsername string
+	registryJSON     bool
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage credentials for private image registries",
+	Long: `Manage the credentials sandboxes use to pull private images.
+
+Credentials are stored server-side like secrets: passwords are write-only and
+never returned by the CLI or API. Use a read-only access token rather than
+your account password where the registry supports one.`,
+}
+
+var registryLoginCmd = &cobra.Command{
+	Use:   "login <server>",
+	Short: "Store credentials for a registry",
+	Long: `Store credentials for a registry so 'cvps up --image' can pull private images
+from it. The password or token is read from standard input when it is piped,
+or from a hidden prompt.`,
+	Example: `  # Pull private images from GitHub Container Registry
+  echo -n "$GHCR_TOKEN" | cvps registry login ghcr.io -u octocat
+  cvps up --image ghcr.io/acme/private:latest`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegistryLogin,
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registries with stored credentials",
+	Args:  cobra.NoArgs,
+	RunE:  runRegistryList,
+}
+
+var registryLogoutCmd = &cobra.Command{
+	Use:   "logout <server>",
+	Short: "Remove the credentials of a registry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRegistryLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryLoginCmd)
+	registryCmd.AddCommand(registryListCmd)
+	registryCmd.AddCommand(registryLogoutCmd)
+
+	registryLoginCmd.Flags().StringVarP(&registryUsername, "username", "u", "", "registry username (prompted if not set)")
+	registryListCmd.Flags().BoolVar(&registryJSON, "json", false, "output in JSON format")
+}
+
+// normalizeRegistry reduces a registry given as host, URL or Docker Hub alias
+// to the host form the API stores
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	server, _, _ = strings.Cut(server, "/")
+	server = strings.ToLower(server)
+	switch server {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return dockerHubRegistry
+	}
+	return server
+}
+
+// imageRegistry returns the registry an image reference is pulled from,
+// following Docker's rule that the first path component is a host only if it
+// contains a dot or port, or is localhost
+func imageRegistry(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found {
+		return dockerHubRegistry
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return normalizeRegistry(first)
+	}
+	return dockerHubRegistry
+}
+
+func runRegistryLogin(cmd *cobra.Command, args []string) error {
+	server := normalizeRegistry(args[0])
+	if server == "" {
+		return fmt.Errorf("invalid registry %q", args[0])
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	username := registryUsername
+	if username == "" {
+		if !interactive {
+			return fmt.Errorf("--username is required when the password is piped")
+		}
+		fmt.Print("Username: ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if username = strings.TrimSpace(line); username == "" {
+			return fmt.Errorf("username is empty")
+		}
+	}
+
+	password, err := readRegistryPassword(os.Stdin, interactive)
+	if err != nil {
+		return err
+	}
+
+	cred, err := client.SetRegistryCredential(context.Background(), &claudevps.SetRegistryCredentialRequest{
+		Server:   server,
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store registry credentials: %w", err)
+	}
+
+	fmt.Printf("✓ Credentials for %s saved (user %s)\n", cred.Server, cred.Username)
+	fmt.Println("New sandboxes can now pull private images from this registry.")
+	return nil
+}
+
+// readRegistryPassword reads a password or token from piped stdin or a
+// hidden prompt
+func readRegistryPassword(stdin io.Reader, interactive bool) (string, error) {
+	if !interactive {
+		return readSecretValue("", stdin, false)
+	}
+
+	fmt.Print("Password or token: ")
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("password is empty")
+	}
+	return string(data), nil
+}
+
+func runRegistryList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	creds, err := client.ListRegistryCredentials(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list registries: %w", err)
+	}
+
+	if registryJSON {
+		if creds == nil {
+			creds = []claudevps.RegistryCredential{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(creds)
+	}
+
+	if len(creds) == 0 {
+		fmt.Println("No registry credentials. Run 'cvps registry login <server>' to add some.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tUSERNAME\tUPDATED")
+	for _, c := range creds {
+		updated := c.UpdatedAt
+		if updated == "" {
+			updated = c.CreatedAt
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Server, c.Username, formatTime(updated))
+	}
+	w.Flush()
+	return nil
+}
+
+func runRegistryLogout(cmd *cobra.Command, args []string) error {
+	server := normalizeRegistry(args[0])
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteRegistryCredential(context.Background(), server); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("no credentials stored for %s", server)
+		}
+		return fmt.Errorf("failed to remove registry credentials: %w", err)
+	}
+
+	fmt.Printf("✓ Removed credentials for %s\n", server)
+	return nil
+}
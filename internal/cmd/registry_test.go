@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestNormalizeRegistry(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io":                     "ghcr.io",
+		"https://GHCR.io/":            "ghcr.io",
+		"registry.acme.dev:5000/v2/":  "registry.acme.dev:5000",
+		"index.docker.io":             "docker.io",
+		"https://index.docker.io/v1/": "docker.io",
+	}
+	for in, want := range tests {
+		if got := normalizeRegistry(in); got != want {
+			t.Errorf("normalizeRegistry(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"ubuntu:22.04":                     "docker.io",
+		"acme/tools:latest":                "docker.io",
+		"ghcr.io/acme/private:latest":      "ghcr.io",
+		"registry.acme.dev:5000/tools":     "registry.acme.dev:5000",
+		"localhost/tools":                  "localhost",
+		"localhost:5000/tools@sha256:abcd": "localhost:5000",
+	}
+	for in, want := range tests {
+		if got := imageRegistry(in); got != want {
+			t.Errorf("imageRegistry(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRunRegistryLogin_PipedPassword(t *testing.T) {
+	var req claudevps.SetRegistryCredentialRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/registries" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.RegistryCredential{Server: req.Server, Username: req.Username})
+	}))
+
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("ghp_token\n")
+	w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	registryUsername = "octocat"
+	defer func() { registryUsername = "" }()
+
+	if _, err := captureStdout(t, func() error {
+		return runRegistryLogin(nil, []string{"https://ghcr.io"})
+	}); err != nil {
+		t.Fatalf("runRegistryLogin() error = %v", err)
+	}
+	if req.Server != "ghcr.io" || req.Username != "octocat" || req.Password != "ghp_token" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+}
+
+func TestRunUp_ImagePullUnauthorized(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sandboxes":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-pull", Name: "private", Status: "provisioning"})
+		case "/sandboxes/sbx-pull/status":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{
+				ID:     "sbx-pull",
+				Status: "failed",
+				Image:  "ghcr.io/acme/private:latest",
+				Provisioning: &claudevps.ProvisioningProgress{
+					Stage:  claudevps.StagePullingImage,
+					Reason: "401 Unauthorized",
+					Code:   claudevps.ProvisioningErrorImagePullAuth,
+				},
+			})
+		}
+	}))
+	oldWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldWd)
+
+	upName = "private"
+	upDetach = false
+	defer func() { upName = "" }()
+
+	_, err := captureStdout(t, func() error {
+		return runUp(nil, nil)
+	})
+	if _, ok := err.(*imagePullAuthError); !ok {
+		t.Fatalf("expected imagePullAuthError, got %T: %v", err, err)
+	}
+
+	var out bytes.Buffer
+	presentError(&out, err)
+	for _, want := range []string{"not authorized to pull image ghcr.io/acme/private:latest", "401 Unauthorized", "cvps registry login ghcr.io"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got %q", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the SFTP listing behind remote path completion, so
+// a slow or unreachable sandbox never hangs the shell
+var completionTimeout = 2 * time.Second
+
+// completeCpArgs completes the remote side of "sandbox:path" arguments and
+// leaves local paths to the shell
+func completeCpArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	target, ok := parseRemotePath(toComplete)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completeRemotePath(target.Sandbox+":", target), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLsArgs completes paths in the current sandbox, or in another one
+// given as "sandbox:path"
+func completeLsArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	prefix := ""
+	target, ok := parseRemotePath(toComplete)
+	if ok {
+		prefix = target.Sandbox + ":"
+	} else {
+		target = remotePath{Path: toComplete}
+	}
+	return completeRemotePath(prefix, target), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRemotePath lists the directory target.Path is in and returns the
+// names in it that complete target.Path, each with prefix in front.
+// Directories end in a slash so completion can continue into them. Only
+// sandboxes in the local cache are listed, and listings are cached briefly.
+func completeRemotePath(prefix string, target remotePath) []string {
+	ref := target.Sandbox
+	if ref == "" {
+		id, err := getCurrentSandboxID()
+		if err != nil {
+			return nil
+		}
+		ref = id
+	}
+	sandboxes, err := cache.LoadSandboxes()
+	if err != nil || sandboxes == nil {
+		return nil
+	}
+	sandbox := sandboxes.Find(ref)
+	if sandbox == nil {
+		return nil
+	}
+
+	dir, base := "", target.Path
+	if i := strings.LastIndex(target.Path, "/"); i >= 0 {
+		dir, base = target.Path[:i+1], target.Path[i+1:]
+	}
+	listDir := trimHomePrefix(dir)
+
+	var entries []cache.ListingEntry
+	if l, err := cache.LoadListing(sandbox.ID, listDir); err == nil && l != nil {
+		entries = l.Entries
+	} else {
+		if entries, err = fetchRemoteListing(sandbox, listDir); err != nil {
+			debuglog.Printf("completion: listing %s on %s: %v", listDir, sandbox.ID, err)
+			return nil
+		}
+		if err := cache.SaveListing(sandbox.ID, listDir, entries); err != nil {
+			debuglog.Printf("completion: %v", err)
+		}
+	}
+
+	var out []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name, base) {
+			continue
+		}
+		if strings.HasPrefix(e.Name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		c := prefix + dir + e.Name
+		if e.IsDir {
+			c += "/"
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// fetchRemoteListing lists dir in a sandbox, giving up after completionTimeout
+func fetchRemoteListing(sandbox *claudevps.Sandbox, dir string) ([]cache.ListingEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	type result struct {
+		entries []cache.ListingEntry
+		err     error
+	}
+	// Buffered so the lister can finish after a timeout without leaking, and
+	// bound now so it never reads listRemoteDir while tests swap it
+	done := make(chan result, 1)
+	list := listRemoteDir
+	go func() {
+		entries, err := list(ctx, sandbox, dir)
+		done <- result{entries, err}
+	}()
+	select {
+	case r := <-done:
+		return r.entries, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// listRemoteDir lists dir over SFTP. It is a variable so tests need no sandbox.
+var listRemoteDir = func(ctx context.Context, sandbox *claudevps.Sandbox, dir string) ([]cache.ListingEntry, error) {
+	fs, err := newSandboxFS(ctx, sandbox)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Close()
+
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]cache.ListingEntry, len(infos))
+	for i, fi := range infos {
+		entries[i] = cache.ListingEntry{Name: fi.Name(), IsDir: fi.IsDir()}
+	}
+	return entries, nil
+}
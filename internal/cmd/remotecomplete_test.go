@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteCpArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := cache.SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-1", Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var listed []string
+	oldList := listRemoteDir
+	listRemoteDir = func(ctx context.Context, s *claudevps.Sandbox, dir string) ([]cache.ListingEntry, error) {
+		listed = append(listed, s.ID+" "+dir)
+		return []cache.ListingEntry{{Name: "dist", IsDir: true}, {Name: "docs.md"}, {Name: ".env"}, {Name: "go.mod"}}, nil
+	}
+	defer func() { listRemoteDir = oldList }()
+
+	got, directive := completeCpArgs(cpCmd, nil, "web:/workspace/d")
+	if want := []string{"web:/workspace/dist/", "web:/workspace/docs.md"}; !slices.Equal(got, want) {
+		t.Errorf("completions = %v, want %v", got, want)
+	}
+	if directive != cobra.ShellCompDirectiveNoSpace|cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v", directive)
+	}
+
+	// Hidden files only when asked for; the second lookup comes from the cache
+	got, _ = completeCpArgs(cpCmd, nil, "web:/workspace/.")
+	if want := []string{"web:/workspace/.env"}; !slices.Equal(got, want) {
+		t.Errorf("completions = %v, want %v", got, want)
+	}
+	if want := []string{"sbx-1 /workspace/"}; !slices.Equal(listed, want) {
+		t.Errorf("listed %v, want %v", listed, want)
+	}
+
+	// Local paths and unknown sandboxes are left alone
+	if got, directive := completeCpArgs(cpCmd, nil, "./src"); got != nil || directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("local path: %v, %v", got, directive)
+	}
+	if got, _ := completeCpArgs(cpCmd, nil, "api:/"); got != nil {
+		t.Errorf("unknown sandbox: %v", got)
+	}
+}
+
+func TestCompleteRemotePathTimeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := cache.SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-1", Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lister ignores ctx; the test waits for it before restoring
+	finished := make(chan struct{})
+	oldList, oldTimeout := listRemoteDir, completionTimeout
+	listRemoteDir = func(ctx context.Context, s *claudevps.Sandbox, dir string) ([]cache.ListingEntry, error) {
+		defer close(finished)
+		time.Sleep(200 * time.Millisecond)
+		return []cache.ListingEntry{{Name: "late"}}, nil
+	}
+	completionTimeout = 10 * time.Millisecond
+	defer func() {
+		<-finished
+		listRemoteDir, completionTimeout = oldList, oldTimeout
+	}()
+
+	start := time.Now()
+	if got := completeRemotePath("web:", remotePath{Sandbox: "web", Path: "~/"}); got != nil {
+		t.Errorf("completions = %v, want none", got)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("completion took %s", elapsed)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/internal/sftp"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// remotePath is a parsed "[sandbox]:path" argument
+type remotePath struct {
+	Sandbox string
+	Path    string
+}
+
+// parseRemotePath recognises "[sandbox]:path" arguments. An empty sandbox refers
+// to the current context. Local paths containing a slash before the colon and
+// Windows drive letters are not treated as remote.
+func parseRemotePath(arg string) (remotePath, bool) {
+	idx := strings.Index(arg, ":")
+	if idx < 0 {
+		return remotePath{}, false
+	}
+	if strings.ContainsAny(arg[:idx], `/\`) {
+		return remotePath{}, false
+	}
+	if idx == 1 && len(arg) > 2 && (arg[2] == '\\' || arg[2] == '/') {
+		return remotePath{}, false
+	}
+	return remotePath{Sandbox: arg[:idx], Path: arg[idx+1:]}, true
+}
+
+// remoteStore is the set of file operations shared by the SFTP and HTTPS transports
+type remoteStore interface {
+	Stat(p string) (os.FileInfo, error)
+	ReadDir(p string) ([]os.FileInfo, error)
+	MkdirAll(p string) error
+	Remove(p string) error
+	Upload(localPath, remotePath string, info os.FileInfo) (int64, error)
+	Download(remotePath, localPath string, info os.FileInfo) (int64, error)
+	Resolve(p string) (string, error)
+	Close() error
+}
+
+// sftpStore implements remoteStore over an SFTP session
+type sftpStore struct {
+	*sftp.Client
+}
+
+// Resolve makes p absolute, treating relative paths as relative to the remote home
+func (s sftpStore) Resolve(p string) (string, error) {
+	p = trimHomePrefix(p)
+	if path.IsAbs(p) {
+		return path.Clean(p), nil
+	}
+	return s.RealPath(p)
+}
+
+func (s sftpStore) Upload(localPath, remotePath string, info os.FileInfo) (int64, error) {
+	in, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := s.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+
+	return n, s.Chtimes(remotePath, info.ModTime(), info.ModTime())
+}
+
+func (s sftpStore) Download(remotePath, localPath string, info os.FileInfo) (int64, error) {
+	in, err := s.Open(remotePath)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// trimHomePrefix turns "~" and "~/x" into paths relative to the remote home
+func trimHomePrefix(p string) string {
+	if p == "" || p == "~" {
+		return "."
+	}
+	if strings.HasPrefix(p, "~/") {
+		return p[2:]
+	}
+	return p
+}
+
+// sandboxFS is an open SFTP session to a sandbox
+type sandboxFS struct {
+	sftpStore
+	conn    *remote.Client
+	Sandbox *claudevps.Sandbox
+}
+
+func (fs *sandboxFS) Close() error {
+	fs.Client.Close()
+	return fs.conn.Close()
+}
+
+// dialSandbox opens a native SSH connection to a running sandbox
+func dialSandbox(ctx context.Context, sandbox *claudevps.Sandbox) (*remote.Client, error) {
+	if !isRunningStatus(sandbox.Status) {
+		return nil, fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
+	}
+	if sandbox.SSHHost == "" {
+		return nil, fmt.Errorf("SSH is not available for this sandbox")
+	}
+	if sandbox.Connectivity.SSHProxyRequired {
+		return nil, fmt.Errorf("sandbox %s requires an SSH proxy, which is not supported for native SSH yet", sandbox.Name)
+	}
+
+	return remote.Dial(ctx, remote.Endpoint{
+		Host: sandbox.SSHHost,
+		Port: sandbox.SSHPort,
+		User: sandbox.SSHUser,
+	})
+}
+
+// openSandboxSSH looks up the sandbox and opens a native SSH connection to it
+func openSandboxSSH(ctx context.Context, client *claudevps.Client, sandboxID string) (*remote.Client, *claudevps.Sandbox, error) {
+	sandbox, err := client.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return nil, nil, &sandboxNotFoundError{Ref: sandboxID}
+		}
+		return nil, nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, sandbox, nil
+}
+
+// openSandboxFS looks up the sandbox and starts an SFTP session on it
+func openSandboxFS(ctx context.Context, client *claudevps.Client, sandboxID string) (*sandboxFS, error) {
+	sandbox, err := client.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return nil, &sandboxNotFoundError{Ref: sandboxID}
+		}
+		return nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	return newSandboxFS(ctx, sandbox)
+}
+
+func newSandboxFS(ctx context.Context, sandbox *claudevps.Sandbox) (*sandboxFS, error) {
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := sftp.NewClient(conn.SSH())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &sandboxFS{sftpStore: sftpStore{sc}, conn: conn, Sandbox: sandbox}, nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"golang.org/x/term"
+)
+
+// How a reference matched a sandbox, strongest first
+const (
+	matchExactID = iota
+	matchExactName
+	matchPrefix
+	matchFuzzy
+	matchNone
+)
+
+// sandboxMatch classifies how ref matches s
+func sandboxMatch(s claudevps.Sandbox, ref string) int {
+	name := strings.ToLower(strings.TrimSpace(s.Name))
+	lref := strings.ToLower(ref)
+	switch {
+	case s.ID == ref:
+		return matchExactID
+	case name != "" && name == lref:
+		return matchExactName
+	case strings.HasPrefix(s.ID, ref) || (name != "" && strings.HasPrefix(name, lref)):
+		return matchPrefix
+	case isSubsequence(lref, name):
+		return matchFuzzy
+	default:
+		return matchNone
+	}
+}
+
+// isSubsequence reports whether the characters of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	if sub == "" {
+		return false
+	}
+	rs := []rune(sub)
+	i := 0
+	for _, r := range s {
+		if r == rs[i] {
+			i++
+			if i == len(rs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSandboxes returns the sandboxes matching ref in the strongest tier that
+// has any, and that tier. Fuzzy matches are skipped unless allowFuzzy is set.
+func matchSandboxes(sandboxes []claudevps.Sandbox, ref string, allowFuzzy bool) ([]claudevps.Sandbox, int) {
+	best := matchNone
+	var matches []claudevps.Sandbox
+	for _, s := range sandboxes {
+		m := sandboxMatch(s, ref)
+		if m == matchFuzzy && !allowFuzzy {
+			continue
+		}
+		switch {
+		case m < best:
+			best = m
+			matches = []claudevps.Sandbox{s}
+		case m == best && m != matchNone:
+			matches = append(matches, s)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return len(matches[i].Name) < len(matches[j].Name) })
+	return matches, best
+}
+
+// ambiguousSandboxError is returned when a reference matches several sandboxes
+// and there is no terminal to ask which one was meant
+type ambiguousSandboxError struct {
+	Ref        string
+	Candidates []claudevps.Sandbox
+}
+
+func (e *ambiguousSandboxError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sandbox name %q is ambiguous. Use a sandbox ID:", e.Ref)
+	for _, s := range e.Candidates {
+		fmt.Fprintf(&b, "\n  - %s (%s)", s.ID, s.Name)
+	}
+	return b.String()
+}
+
+// pickSandbox asks the user to choose between candidates. It is a variable so
+// tests can avoid the terminal check.
+var pickSandbox = func(ref string, candidates []claudevps.Sandbox) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return "", &ambiguousSandboxError{Ref: ref, Candidates: candidates}
+	}
+	return promptSandboxChoice(bufio.NewReader(os.Stdin), os.Stderr, ref, candidates)
+}
+
+func promptSandboxChoice(in *bufio.Reader, out io.Writer, ref string, candidates []claudevps.Sandbox) (string, error) {
+	fmt.Fprintf(out, "%q matches several sandboxes:\n", ref)
+	for i, s := range candidates {
+		fmt.Fprintf(out, "  %d) %-20s %s  %s\n", i+1, s.Name, s.ID, s.Status)
+	}
+	fmt.Fprintf(out, "Select a sandbox [1-%d]: ", len(candidates))
+
+	line, _ := in.ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(candidates) {
+		return "", fmt.Errorf("no sandbox selected")
+	}
+	return candidates[n-1].ID, nil
+}
+
+// resolveSandboxInList picks the sandbox ref refers to from a listing
+func resolveSandboxInList(sandboxes []claudevps.Sandbox, ref string, allowFuzzy bool) (string, error) {
+	matches, how := matchSandboxes(sandboxes, ref, allowFuzzy)
+	switch len(matches) {
+	case 0:
+		return "", &sandboxNotFoundError{Ref: ref}
+	case 1:
+		if how >= matchPrefix {
+			fmt.Fprintf(os.Stderr, "Using sandbox '%s' (%s)\n", matches[0].Name, matches[0].ID)
+		}
+		noteSandbox(matches[0].ID, matches[0].Name)
+		return matches[0].ID, nil
+	default:
+		return pickSandbox(ref, matches)
+	}
+}
+
+// resolveSandboxRef resolves a sandbox ID, workspace or sandbox name, falling
+// back to the current context. Names may be abbreviated to a unique prefix or
+// any characters in order ("wb" for "web-backend").
+func resolveSandboxRef(ctx context.Context, client *claudevps.Client, ref string) (string, error) {
+	return resolveSandbox(ctx, client, ref, true)
+}
+
+// resolveSandbox is resolveSandboxRef with control over fuzzy matching, for
+// commands where a loose match could do damage
+func resolveSandbox(ctx context.Context, client *claudevps.Client, ref string, allowFuzzy bool) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		id, err := getCurrentSandboxID()
+		if err != nil {
+			return "", fmt.Errorf("no sandbox specified: %w", err)
+		}
+		return id, nil
+	}
+	if looksLikeSandboxID(ref) && !isCachedIDPrefix(ref) {
+		noteSandbox(ref, "")
+		return ref, nil
+	}
+	if id, ok, err := resolveWorkspaceSandbox(ref); ok || err != nil {
+		noteSandbox(id, "")
+		return id, err
+	}
+
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sandboxes: %w%s", err, cachedNameHint(ref))
+	}
+	return resolveSandboxInList(sandboxes, ref, allowFuzzy)
+}
+
+// resolveSandboxIDByName resolves a sandbox name, or a unique abbreviation of one
+func resolveSandboxIDByName(ctx context.Context, client *claudevps.Client, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("sandbox name cannot be empty")
+	}
+
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sandboxes: %w%s", err, cachedNameHint(name))
+	}
+	return resolveSandboxInList(sandboxes, name, true)
+}
+
+// isCachedIDPrefix reports whether ref is a shortened form of a cached sandbox
+// ID, in which case it has to be looked up rather than used as is
+func isCachedIDPrefix(ref string) bool {
+	c, err := cache.LoadSandboxes()
+	if err != nil || c == nil {
+		return false
+	}
+	for _, s := range c.Sandboxes {
+		if s.ID == ref {
+			return false
+		}
+	}
+	for _, s := range c.Sandboxes {
+		if strings.HasPrefix(s.ID, ref) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+var resolveFixture = []claudevps.Sandbox{
+	{ID: "sbx-a1b2", Name: "web-backend", Status: "running"},
+	{ID: "sbx-a1c3", Name: "web-frontend", Status: "running"},
+	{ID: "sbx-d4e5", Name: "worker", Status: "stopped"},
+	{ID: "sbx-f6g7", Name: "web", Status: "running"},
+}
+
+func TestMatchSandboxes(t *testing.T) {
+	tests := []struct {
+		ref        string
+		allowFuzzy bool
+		want       []string
+	}{
+		{"sbx-d4e5", true, []string{"sbx-d4e5"}},
+		{"WEB", true, []string{"sbx-f6g7"}},
+		{"wor", true, []string{"sbx-d4e5"}},
+		{"sbx-a1", true, []string{"sbx-a1b2", "sbx-a1c3"}},
+		{"web-", true, []string{"sbx-a1b2", "sbx-a1c3"}},
+		{"wbk", true, []string{"sbx-a1b2"}},
+		{"wbk", false, nil},
+		{"zzz", true, nil},
+	}
+
+	for _, tt := range tests {
+		matches, _ := matchSandboxes(resolveFixture, tt.ref, tt.allowFuzzy)
+		var got []string
+		for _, s := range matches {
+			got = append(got, s.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("matchSandboxes(%q, %v) = %v, want %v", tt.ref, tt.allowFuzzy, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSandboxInList_Ambiguous(t *testing.T) {
+	_, err := resolveSandboxInList(resolveFixture, "web-", true)
+
+	var amb *ambiguousSandboxError
+	if !errors.As(err, &amb) {
+		t.Fatalf("Expected ambiguousSandboxError, got %v", err)
+	}
+	if len(amb.Candidates) != 2 {
+		t.Errorf("Expected 2 candidates, got %d", len(amb.Candidates))
+	}
+
+	oldPick := pickSandbox
+	defer func() { pickSandbox = oldPick }()
+	pickSandbox = func(ref string, candidates []claudevps.Sandbox) (string, error) {
+		return candidates[1].ID, nil
+	}
+	if id, err := resolveSandboxInList(resolveFixture, "web-", true); err != nil || id != "sbx-a1c3" {
+		t.Errorf("Expected picked sandbox sbx-a1c3, got %q, %v", id, err)
+	}
+}
+
+func TestPromptSandboxChoice(t *testing.T) {
+	var out bytes.Buffer
+	id, err := promptSandboxChoice(bufio.NewReader(strings.NewReader("2\n")), &out, "web-", resolveFixture[:2])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != "sbx-a1c3" {
+		t.Errorf("Expected sbx-a1c3, got %s", id)
+	}
+	if !strings.Contains(out.String(), "2) web-frontend") {
+		t.Errorf("Expected numbered candidates, got %q", out.String())
+	}
+
+	if _, err := promptSandboxChoice(bufio.NewReader(strings.NewReader("9\n")), &out, "web-", resolveFixture[:2]); err == nil {
+		t.Error("Expected error for out of range choice")
+	}
+}
+
+func TestResolveSandboxRef_CachedIDPrefix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	listed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listed = true
+		json.NewEncoder(w).Encode(claudevps.SandboxList{Data: resolveFixture, Total: len(resolveFixture)})
+	}))
+	defer server.Close()
+	client := claudevps.NewClient(server.URL, "test-key")
+
+	// Without a cache, anything that looks like an ID is used as is
+	if id, err := resolveSandboxRef(context.Background(), client, "sbx-d4"); err != nil || id != "sbx-d4" || listed {
+		t.Fatalf("Expected sbx-d4 without lookup, got %q, %v (listed %v)", id, err, listed)
+	}
+
+	cache.SaveSandboxes(resolveFixture)
+	id, err := resolveSandboxRef(context.Background(), client, "sbx-d4")
+	if err != nil || id != "sbx-d4e5" {
+		t.Errorf("Expected prefix to resolve to sbx-d4e5, got %q, %v", id, err)
+	}
+
+	listed = false
+	if id, _ := resolveSandboxRef(context.Background(), client, "sbx-f6g7"); id != "sbx-f6g7" || listed {
+		t.Errorf("Expected full cached ID to skip the lookup, got %q (listed %v)", id, listed)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <sandbox>",
+	Short: "Restore a deleted sandbox from the trash",
+	Long: `Bring back a sandbox deleted with 'cvps down' before it is purged.
+
+Use 'cvps status --all --deleted' to list sandboxes in the trash.`,
+	Example: `  # Restore by ID
+  cvps restore sbx-abc123
+
+  # Restore by name
+  cvps restore my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID := strings.TrimSpace(args[0])
+	if !looksLikeSandboxID(sandboxID) {
+		if sandboxID, err = resolveDeletedSandboxByName(ctx, client, sandboxID); err != nil {
+			return err
+		}
+	}
+
+	sandbox, err := client.RestoreSandbox(ctx, sandboxID)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("no deleted sandbox %s found (it may have been purged)", sandboxID)
+		}
+		return fmt.Errorf("failed to restore sandbox: %w", err)
+	}
+
+	fmt.Printf("✓ Restored sandbox '%s' (%s), status: %s\n", sandbox.Name, sandbox.ID, sandbox.Status)
+	fmt.Printf("  Select it with: cvps connect %s\n", sandbox.ID)
+	return nil
+}
+
+// resolveDeletedSandboxByName finds a trashed sandbox by name or a unique
+// abbreviation of one
+func resolveDeletedSandboxByName(ctx context.Context, client *claudevps.Client, name string) (string, error) {
+	list, err := client.ListDeletedSandboxes(ctx, 1, 100)
+	if err != nil {
+		return "", fmt.Errorf("failed to list deleted sandboxes: %w", err)
+	}
+
+	matches, _ := matchSandboxes(list.Data, name, true)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no deleted sandbox named %q. Run 'cvps status --all --deleted' to view the trash", name)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return pickSandbox(name, matches)
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestRunRestore_ByName(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldConfigDir := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldConfigDir)
+
+	restored := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes":
+			json.NewEncoder(w).Encode(claudevps.SandboxList{Data: []claudevps.Sandbox{
+				{ID: "sbx-old", Name: "my-project", DeletedAt: "2024-01-15T10:00:00Z"},
+			}})
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-old/restore":
+			restored = true
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-old", Name: "my-project", Status: "stopped"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.APIBaseURL = server.URL
+	config.Save(cfg)
+
+	if err := runRestore(nil, []string{"my-project"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !restored {
+		t.Error("Expected restore to be called")
+	}
+
+	if err := runRestore(nil, []string{"other"}); err == nil {
+		t.Error("Expected error for unknown deleted sandbox name")
+	}
+}
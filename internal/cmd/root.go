@@ -3,7 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -11,6 +14,7 @@ import (
 var (
 	cfgFile string
 	verbose bool
+	dryRun  bool
 )
 
 var rootCmd = &cobra.Command{
@@ -26,10 +30,37 @@ from anywhere.`,
 
 // Execute executes the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	start := time.Now()
+	args, err := expandConfiguredAlias(os.Args[1:])
+	if err != nil {
+		presentError(os.Stderr, err)
 		os.Exit(1)
 	}
+	rootCmd.SetArgs(args)
+
+	if code, ok := runPlugin(args); ok {
+		os.Exit(code)
+	}
+
+	err = findUnknownCommand(args)
+	if err == nil {
+		var c *cobra.Command
+		c, err = rootCmd.ExecuteC()
+		if dryRun && claudevps.IsReadOnly(err) {
+			presentDryRun(os.Stdout, err)
+			err = nil
+		}
+		recordCommandUsage(c, time.Since(start), err)
+		recordHistory(c, args, time.Since(start), err)
+		if err == nil {
+			notifyUpdate(c)
+		}
+	}
+	if err != nil {
+		debuglog.Printf("error: %v", err)
+		presentError(os.Stderr, err)
+		os.Exit(exitCode(err))
+	}
 }
 
 func init() {
@@ -37,22 +68,25 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cvps/config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would change without changing anything through the API")
 }
 
 func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		viper.AddConfigPath(home + "/.cvps")
+		// No home directory is fine: settings then come from CVPS_ variables
+		if home, err := os.UserHomeDir(); err == nil {
+			viper.AddConfigPath(home + "/.cvps")
+		}
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("config")
 	}
 
 	viper.AutomaticEnv()
 
+	debuglog.Enable()
+
 	if err := viper.ReadInConfig(); err == nil && verbose {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// cacheSandboxes records a complete sandbox listing for offline use
+func cacheSandboxes(sandboxes []claudevps.Sandbox) {
+	if err := cache.SaveSandboxes(sandboxes); err != nil {
+		debuglog.Printf("cache: %v", err)
+	}
+}
+
+// pendingDeletionWarnAfter is how long a deletion may stay unconfirmed before
+// status warns about it
+const pendingDeletionWarnAfter = 10 * time.Minute
+
+// reconcilePendingDeletions checks deletions requested with 'cvps down
+// --no-wait', reporting on stderr those that finished and those that are
+// overdue, and forgets the finished ones
+func reconcilePendingDeletions(ctx context.Context, client *claudevps.Client) {
+	pending, err := cache.LoadPendingDeletions()
+	if err != nil || len(pending) == 0 {
+		if err != nil {
+			debuglog.Printf("cache: %v", err)
+		}
+		return
+	}
+
+	var remaining []cache.PendingDeletion
+	for _, p := range pending {
+		sandbox, err := client.GetSandbox(ctx, p.SandboxID)
+		switch {
+		case claudevps.IsNotFound(err), err == nil && sandbox.DeletedAt != "" && !p.Purge:
+			fmt.Fprintf(os.Stderr, "✓ Sandbox %s (%s) finished deleting\n", p.Name, p.SandboxID)
+		case err != nil:
+			// Unknown; check again next time
+			remaining = append(remaining, p)
+		default:
+			remaining = append(remaining, p)
+			if age := time.Since(p.RequestedAt); age > pendingDeletionWarnAfter {
+				color.New(color.FgYellow).Fprintf(os.Stderr, "⚠ Deletion of %s (%s) requested %s is not done yet (status: %s)\n", p.Name, p.SandboxID, formatAge(age), sandbox.Status)
+			}
+		}
+	}
+	if err := cache.SavePendingDeletions(remaining); err != nil {
+		debuglog.Printf("cache: %v", err)
+	}
+}
+
+// formatAge renders a duration the way people say it ("3m", "2h", "5d")
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// cachedNameHint explains what the cache last knew about name, for errors
+// raised while the API is unreachable
+func cachedNameHint(name string) string {
+	c, err := cache.LoadSandboxes()
+	if err != nil || c == nil {
+		return ""
+	}
+	if s := c.Find(name); s != nil {
+		return fmt.Sprintf(" (last seen %s as %s, %s)", formatAge(c.Age()), s.ID, s.Status)
+	}
+	return ""
+}
+
+// completeSandboxes offers cached sandbox IDs and names for the first argument
+func completeSandboxes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	c, err := cache.LoadSandboxes()
+	if err != nil || c == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var out []string
+	for _, s := range c.Sandboxes {
+		if s.Name != "" && strings.HasPrefix(s.Name, toComplete) {
+			out = append(out, fmt.Sprintf("%s\t%s, %s", s.Name, s.ID, s.Status))
+		}
+		if strings.HasPrefix(s.ID, toComplete) {
+			out = append(out, fmt.Sprintf("%s\t%s, %s", s.ID, s.Name, s.Status))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// showCachedStatus prints the cached sandbox list, or one sandbox from it,
+// without contacting the API
+func showCachedStatus(args []string) error {
+	c, err := cache.LoadSandboxes()
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("no cached sandbox list yet. Run 'cvps status --all' while online first")
+	}
+
+	fmt.Fprintf(os.Stderr, "Cached sandbox list from %s (%s). Statuses may be out of date.\n",
+		c.FetchedAt.Local().Format("2006-01-02 15:04:05"), formatAge(c.Age()))
+
+	if len(args) == 0 {
+		return printSandboxList(context.Background(), nil, c.Sandboxes)
+	}
+
+	s := c.Find(args[0])
+	if s == nil {
+		return fmt.Errorf("sandbox %s is not in the cache", args[0])
+	}
+	switch format := statusFormat(); {
+	case format == outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case isDelimited(format):
+		return writeSandboxRows(os.Stdout, format, []claudevps.Sandbox{*s}, nil, nil)
+	}
+	printSandboxDetails(s)
+	return nil
+}
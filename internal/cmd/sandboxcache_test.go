@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestStatusCached(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	defer func() { statusAll, statusCache = false, false }()
+
+	statusCache = true
+	if err := runStatus(nil, nil); err == nil {
+		t.Fatal("Expected error with nothing cached")
+	}
+	statusCache = false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.SandboxList{
+			Data:  []claudevps.Sandbox{{ID: "sbx-1", Name: "web", Status: "running"}},
+			Total: 1,
+		})
+	}))
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.APIBaseURL = server.URL
+	config.Save(cfg)
+
+	statusAll = true
+	if err := runStatus(nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.Close()
+
+	// The API is gone now, but the listing was cached
+	c, err := cache.LoadSandboxes()
+	if err != nil || c == nil || c.Find("web") == nil {
+		t.Fatalf("Expected listing to be cached, got %+v, %v", c, err)
+	}
+
+	err = runStatus(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "status --cached") {
+		t.Errorf("Expected hint to use --cached, got %v", err)
+	}
+
+	statusCache = true
+	if err := runStatus(nil, nil); err != nil {
+		t.Errorf("Unexpected error with --cached: %v", err)
+	}
+	if err := runStatus(nil, []string{"web"}); err != nil {
+		t.Errorf("Unexpected error for cached sandbox: %v", err)
+	}
+	if err := runStatus(nil, []string{"other"}); err == nil {
+		t.Error("Expected error for sandbox missing from cache")
+	}
+}
+
+func TestCompleteSandboxes(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	cache.SaveSandboxes([]claudevps.Sandbox{
+		{ID: "sbx-1", Name: "web", Status: "running"},
+		{ID: "sbx-2", Name: "worker", Status: "stopped"},
+		{ID: "sbx-3", Name: "db", Status: "running"},
+	})
+
+	got, _ := completeSandboxes(nil, nil, "w")
+	if len(got) != 2 || !strings.HasPrefix(got[0], "web\t") || !strings.HasPrefix(got[1], "worker\t") {
+		t.Errorf("completeSandboxes(w) = %q", got)
+	}
+
+	if got, _ := completeSandboxes(nil, []string{"web"}, ""); len(got) != 0 {
+		t.Errorf("Expected no completions for a second argument, got %q", got)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{72 * time.Hour, "3d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestReconcilePendingDeletions(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sandboxes/sbx-gone":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(claudevps.APIError{Message: "not found"})
+		case "/sandboxes/sbx-trash":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-trash", Status: "deleted", DeletedAt: "2024-01-15T10:00:00Z"})
+		case "/sandboxes/sbx-slow":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-slow", Status: "deleting"})
+		}
+	}))
+
+	cache.SavePendingDeletions([]cache.PendingDeletion{
+		{SandboxID: "sbx-gone", Name: "gone", Purge: true, RequestedAt: time.Now()},
+		{SandboxID: "sbx-trash", Name: "trash", RequestedAt: time.Now()},
+		{SandboxID: "sbx-slow", Name: "slow", RequestedAt: time.Now().Add(-time.Hour)},
+	})
+
+	client, err := newAPIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconcilePendingDeletions(context.Background(), client)
+
+	pending, err := cache.LoadPendingDeletions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].SandboxID != "sbx-slow" {
+		t.Errorf("Expected only sbx-slow to stay pending, got %+v", pending)
+	}
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/schedule"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleSandbox  string
+	scheduleTimezone string
+	scheduleAll      bool
+	scheduleJSON     bool
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Start and stop sandboxes automatically",
+	Long: `Manage server-side schedules that start or stop a sandbox automatically,
+for example to stop it at night and over the weekend.
+
+Schedules use five-field cron expressions (minute hour day-of-month month
+day-of-week) or macros such as @daily, evaluated in an IANA time zone. A
+sandbox has at most one start and one stop schedule.`,
+}
+
+var scheduleSetCmd = &cobra.Command{
+	Use:   "set <start|stop> <cron>",
+	Short: "Set the start or stop schedule of a sandbox",
+	Example: `  # Stop the current sandbox at 19:00 on weekdays and start it at 08:00
+  cvps schedule set stop "0 19 * * 1-5"
+  cvps schedule set start "0 8 * * mon-fri"
+
+  # Stop a named sandbox every night, in a specific time zone
+  cvps schedule set stop @midnight --sandbox web --timezone America/New_York`,
+	Args: cobra.ExactArgs(2),
+	RunE: runScheduleSet,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List schedules",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleList,
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <start|stop|all>",
+	Short: "Remove schedules from a sandbox",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runScheduleRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleSetCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+
+	scheduleCmd.PersistentFlags().StringVarP(&scheduleSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	scheduleSetCmd.Flags().StringVar(&scheduleTimezone, "timezone", "", "IANA time zone of the expression (default: local time zone)")
+	scheduleListCmd.Flags().BoolVar(&scheduleAll, "all", false, "list the schedules of every sandbox")
+	scheduleListCmd.Flags().BoolVar(&scheduleJSON, "json", false, "output in JSON format")
+}
+
+func validScheduleAction(action string) error {
+	if action != claudevps.ScheduleActionStart && action != claudevps.ScheduleActionStop {
+		return fmt.Errorf("invalid action %q (use start or stop)", action)
+	}
+	return nil
+}
+
+// localTimezone returns the IANA name of the local time zone, or UTC if it
+// cannot be determined
+func localTimezone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			return name
+		}
+	}
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	return "UTC"
+}
+
+func runScheduleSet(cmd *cobra.Command, args []string) error {
+	action, expr := args[0], args[1]
+	if err := validScheduleAction(action); err != nil {
+		return err
+	}
+	parsed, err := schedule.Parse(expr)
+	if err != nil {
+		return err
+	}
+	tz := scheduleTimezone
+	if tz == "" {
+		tz = localTimezone()
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", tz)
+	}
+	next := parsed.Next(time.Now().In(loc))
+	if next.IsZero() {
+		return fmt.Errorf("cron expression %q never matches", expr)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, scheduleSandbox)
+	if err != nil {
+		return err
+	}
+	if _, err := client.SetSchedule(ctx, sandboxID, &claudevps.SetScheduleRequest{
+		Action:   action,
+		Cron:     expr,
+		Timezone: tz,
+	}); err != nil {
+		return fmt.Errorf("failed to set schedule: %w", err)
+	}
+
+	fmt.Printf("✓ %s schedule of %s set to %q (%s)\n", strings.ToUpper(action[:1])+action[1:], sandboxID, expr, tz)
+	fmt.Printf("  Next %s: %s\n", action, next.Format("Mon 2006-01-02 15:04 MST"))
+	return nil
+}
+
+func runScheduleList(cmd *cobra.Command, args []string) error {
+	if scheduleAll && scheduleSandbox != "" {
+		return fmt.Errorf("provide either --sandbox or --all, not both")
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID := ""
+	if !scheduleAll {
+		if sandboxID, err = resolveSandboxRef(ctx, client, scheduleSandbox); err != nil {
+			return err
+		}
+	}
+	schedules, err := client.ListSchedules(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	if scheduleJSON {
+		if schedules == nil {
+			schedules = []claudevps.Schedule{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(schedules)
+	}
+
+	if len(schedules) == 0 {
+		fmt.Println("No schedules. Add one with 'cvps schedule set <start|stop> <cron>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SANDBOX\tACTION\tCRON\tTIMEZONE\tNEXT")
+	for _, s := range schedules {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.SandboxID, s.Action, s.Cron, s.Timezone, nextScheduleRun(s))
+	}
+	w.Flush()
+	return nil
+}
+
+// nextScheduleRun formats when s runs next, computing it locally if the API
+// did not say
+func nextScheduleRun(s claudevps.Schedule) string {
+	if s.NextRunAt != "" {
+		return formatTime(s.NextRunAt)
+	}
+	parsed, err := schedule.Parse(s.Cron)
+	if err != nil {
+		return "-"
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return "-"
+	}
+	next := parsed.Next(time.Now().In(loc))
+	if next.IsZero() {
+		return "never"
+	}
+	return next.Local().Format("2006-01-02 15:04:05")
+}
+
+func runScheduleRemove(cmd *cobra.Command, args []string) error {
+	actions := []string{args[0]}
+	if args[0] == "all" {
+		actions = []string{claudevps.ScheduleActionStart, claudevps.ScheduleActionStop}
+	} else if err := validScheduleAction(args[0]); err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, scheduleSandbox)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, action := range actions {
+		if err := client.DeleteSchedule(ctx, sandboxID, action); err != nil {
+			if claudevps.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to remove %s schedule: %w", action, err)
+		}
+		removed++
+		fmt.Printf("✓ Removed %s schedule of %s\n", action, sandboxID)
+	}
+	if removed == 0 {
+		fmt.Printf("No matching schedules on %s\n", sandboxID)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestRunScheduleSet(t *testing.T) {
+	var req claudevps.SetScheduleRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/sandboxes/sbx-web1/schedules" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.Schedule{SandboxID: "sbx-web1", Action: req.Action, Cron: req.Cron, Timezone: req.Timezone})
+	}))
+
+	scheduleSandbox = "sbx-web1"
+	scheduleTimezone = "Europe/Berlin"
+	defer func() { scheduleSandbox, scheduleTimezone = "", "" }()
+
+	out, err := captureStdout(t, func() error {
+		return runScheduleSet(nil, []string{"stop", "0 19 * * mon-fri"})
+	})
+	if err != nil {
+		t.Fatalf("runScheduleSet() error = %v", err)
+	}
+	if req.Action != "stop" || req.Cron != "0 19 * * mon-fri" || req.Timezone != "Europe/Berlin" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	if !strings.Contains(out, "Next stop:") {
+		t.Errorf("expected next run in output, got %q", out)
+	}
+}
+
+func TestRunScheduleSet_Invalid(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+
+	scheduleSandbox = "sbx-web1"
+	defer func() { scheduleSandbox, scheduleTimezone = "", "" }()
+
+	tests := []struct {
+		args     []string
+		timezone string
+		want     string
+	}{
+		{[]string{"restart", "0 8 * * *"}, "", "invalid action"},
+		{[]string{"start", "0 8 * *"}, "", "must have 5 fields"},
+		{[]string{"start", "0 0 31 2 *"}, "UTC", "never matches"},
+		{[]string{"start", "0 8 * * *"}, "Mars/Olympus", "unknown time zone"},
+	}
+	for _, tt := range tests {
+		scheduleTimezone = tt.timezone
+		err := runScheduleSet(nil, tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("runScheduleSet(%q) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestRunScheduleRemove_AllIgnoresMissing(t *testing.T) {
+	var deleted []string
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		deleted = append(deleted, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/start") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	scheduleSandbox = "sbx-web1"
+	defer func() { scheduleSandbox = "" }()
+
+	out, err := captureStdout(t, func() error {
+		return runScheduleRemove(nil, []string{"all"})
+	})
+	if err != nil {
+		t.Fatalf("runScheduleRemove() error = %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("expected 2 deletes, got %v", deleted)
+	}
+	if !strings.Contains(out, "Removed stop schedule") || strings.Contains(out, "Removed start schedule") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
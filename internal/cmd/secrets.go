@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/vault"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	secretsFromFile string
+	secretsMount    string
+	secretsSandbox  string
+	secretsJSON     bool
+	secretsForce    bool
+	secretsOutput   string
+)
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage secrets injected into sandboxes",
+	Long: `Manage secrets that are injected into sandboxes at boot.
+
+Secrets are exposed either as environment variables (--mount env) or as files
+under /run/secrets/<name> (--mount file), or kept server-side for the platform
+only (--mount none), as for backup target credentials. Values are write-only: once set they
+cannot be read back through the CLI or API.
+
+Prefer secrets over embedding credentials in user-data scripts.`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create or replace a secret",
+	Long: `Create a secret or replace the value of an existing one.
+
+The value is read from --from-file, from standard input when it is piped, or
+from a hidden prompt. It is never accepted as a command-line argument so it
+does not end up in shell history. Values encrypted with 'cvps secrets encrypt'
+are decrypted with the vault before upload.`,
+	Example: `  # Prompt for the value
+  cvps secrets set DATABASE_URL
+
+  # Pipe the value in
+  echo -n "$TOKEN" | cvps secrets set GITHUB_TOKEN
+
+  # Mount a key file at /run/secrets/TLS_KEY in one sandbox only
+  cvps secrets set TLS_KEY --from-file ./server.key --mount file --sandbox sbx-abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsSet,
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secrets (names only)",
+	Args:  cobra.NoArgs,
+	RunE:  runSecretsList,
+}
+
+var secretsEncryptCmd = &cobra.Command{
+	Use:   "encrypt <file>",
+	Short: "Encrypt a secret file with the vault",
+	Long: `Encrypt a local secret file with the vault key so it is not kept in plain text
+on disk. Pass the encrypted file to 'cvps secrets set --from-file'; it is
+decrypted locally just before upload. Needs a vault ('cvps vault init').`,
+	Example: `  # Keep only the encrypted key file around
+  cvps secrets encrypt server.key && rm server.key
+  cvps secrets set TLS_KEY --from-file server.key.enc --mount file`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsEncrypt,
+}
+
+var secretsDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretsDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsDeleteCmd)
+	secretsCmd.AddCommand(secretsEncryptCmd)
+
+	secretsSetCmd.Flags().StringVar(&secretsFromFile, "from-file", "", "read the value from a file")
+	secretsSetCmd.Flags().StringVar(&secretsMount, "mount", claudevps.SecretMountEnv, "how to expose the secret (env|file|none)")
+	secretsSetCmd.Flags().StringVar(&secretsSandbox, "sandbox", "", "limit the secret to one sandbox ID or name (default: all sandboxes)")
+
+	secretsListCmd.Flags().BoolVar(&secretsJSON, "json", false, "output in JSON format")
+
+	secretsDeleteCmd.Flags().BoolVarP(&secretsForce, "force", "f", false, "skip confirmation prompt")
+
+	secretsEncryptCmd.Flags().StringVarP(&secretsOutput, "output", "o", "", "encrypted file to write (default: <file>.enc)")
+}
+
+// newAPIClient loads the config and returns a client for the logged-in user
+func newAPIClient() (*claudevps.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return nil, fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	return newClientFromConfig(cfg), nil
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits and underscores, not starting with a digit", name)
+	}
+	switch secretsMount {
+	case claudevps.SecretMountEnv, claudevps.SecretMountFile, claudevps.SecretMountNone:
+	default:
+		return fmt.Errorf("invalid --mount value %q (use env, file or none)", secretsMount)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID := ""
+	if secretsSandbox != "" {
+		if sandboxID, err = resolveSandboxRef(ctx, client, secretsSandbox); err != nil {
+			return err
+		}
+	}
+
+	value, err := readSecretValue(secretsFromFile, os.Stdin, term.IsTerminal(int(os.Stdin.Fd())))
+	if err != nil {
+		return err
+	}
+
+	secret, err := client.SetSecret(ctx, &claudevps.SetSecretRequest{
+		Name:      name,
+		Value:     value,
+		Mount:     secretsMount,
+		SandboxID: sandboxID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set secret: %w", err)
+	}
+
+	fmt.Printf("Secret %s saved (%s)\n", secret.Name, describeSecretMount(*secret))
+	fmt.Println("Running sandboxes pick up changes on their next restart.")
+	return nil
+}
+
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	secrets, err := client.ListSecrets(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	if secretsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(secrets)
+	}
+
+	if len(secrets) == 0 {
+		fmt.Println("No secrets found. Run 'cvps secrets set <name>' to add one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMOUNT\tSCOPE\tUPDATED")
+	for _, s := range secrets {
+		scope := "all sandboxes"
+		if s.SandboxID != "" {
+			scope = s.SandboxID
+		}
+		updated := s.UpdatedAt
+		if updated == "" {
+			updated = s.CreatedAt
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, describeSecretMount(s), scope, formatTime(updated))
+	}
+	w.Flush()
+	return nil
+}
+
+func runSecretsDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	if !secretsForce {
+		fmt.Printf("Delete secret %s? Sandboxes using it will lose access on restart. [y/N]: ", name)
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := client.DeleteSecret(context.Background(), name); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("secret not found: %s", name)
+		}
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+
+	fmt.Printf("Secret %s deleted\n", name)
+	return nil
+}
+
+// readSecretValue reads a secret from a file, piped input, or a hidden
+// prompt, decrypting values sealed with 'cvps secrets encrypt'
+func readSecretValue(fromFile string, stdin io.Reader, interactive bool) (string, error) {
+	value, err := readRawSecretValue(fromFile, stdin, interactive)
+	if err != nil || !vault.IsSealed(value) {
+		return value, err
+	}
+	return openSealedSecret(value)
+}
+
+func readRawSecretValue(fromFile string, stdin io.Reader, interactive bool) (string, error) {
+	if fromFile != "" {
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if !interactive {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+		}
+		// Tolerate the trailing newline added by echo and heredocs
+		value := strings.TrimRight(string(data), "\r\n")
+		if value == "" {
+			return "", fmt.Errorf("secret value is empty")
+		}
+		return value, nil
+	}
+
+	fmt.Print("Enter secret value: ")
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("secret value is empty")
+	}
+	return string(data), nil
+}
+
+func runSecretsEncrypt(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	out := secretsOutput
+	if out == "" {
+		out = path + ".enc"
+	}
+
+	enc, err := requireVault()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read secret file: %w", err)
+	}
+	if vault.IsSealed(string(data)) {
+		return fmt.Errorf("%s is already encrypted", path)
+	}
+	key, err := vaultKey(enc)
+	if err != nil {
+		return err
+	}
+	sealed, err := vault.Seal(key, data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	if err := os.WriteFile(out, []byte(sealed+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write encrypted file: %w", err)
+	}
+
+	fmt.Printf("✓ Encrypted %s to %s\n", path, out)
+	fmt.Printf("  Upload it with 'cvps secrets set <name> --from-file %s'; delete the plain file if you no longer need it.\n", out)
+	return nil
+}
+
+func describeSecretMount(s claudevps.Secret) string {
+	switch s.Mount {
+	case claudevps.SecretMountFile:
+		return "file /run/secrets/" + s.Name
+	case claudevps.SecretMountNone:
+		return "server only"
+	}
+	return "env $" + s.Name
+}
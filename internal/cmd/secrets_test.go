@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestReadSecretValue(t *testing.T) {
+	got, err := readSecretValue("", strings.NewReader("s3cret\n"), false)
+	if err != nil {
+		t.Fatalf("readSecretValue(stdin) error = %v", err)
+	}
+	if got != "s3cret" {
+		t.Fatalf("readSecretValue(stdin) = %q, want trailing newline trimmed", got)
+	}
+
+	if _, err := readSecretValue("", strings.NewReader("\n"), false); err == nil {
+		t.Fatal("readSecretValue(empty stdin) expected error")
+	}
+
+	// File contents are used verbatim, including trailing newlines
+	path := filepath.Join(t.TempDir(), "key.pem")
+	os.WriteFile(path, []byte("-----BEGIN KEY-----\n"), 0600)
+	got, err = readSecretValue(path, strings.NewReader("ignored"), false)
+	if err != nil {
+		t.Fatalf("readSecretValue(file) error = %v", err)
+	}
+	if got != "-----BEGIN KEY-----\n" {
+		t.Fatalf("readSecretValue(file) = %q", got)
+	}
+}
+
+func TestSecretNamePattern(t *testing.T) {
+	valid := []string{"DATABASE_URL", "_private", "token2"}
+	invalid := []string{"", "2FA", "with-dash", "has space", "../escape"}
+
+	for _, name := range valid {
+		if !secretNamePattern.MatchString(name) {
+			t.Errorf("expected %q to be valid", name)
+		}
+	}
+	for _, name := range invalid {
+		if secretNamePattern.MatchString(name) {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestDescribeSecretMount(t *testing.T) {
+	if got := describeSecretMount(claudevps.Secret{Name: "TLS_KEY", Mount: claudevps.SecretMountFile}); got != "file /run/secrets/TLS_KEY" {
+		t.Errorf("describeSecretMount(file) = %q", got)
+	}
+	if got := describeSecretMount(claudevps.Secret{Name: "TOKEN", Mount: claudevps.SecretMountEnv}); got != "env $TOKEN" {
+		t.Errorf("describeSecretMount(env) = %q", got)
+	}
+	if got := describeSecretMount(claudevps.Secret{Name: "CVPS_BACKUP_ACCESS_KEY_ID", Mount: claudevps.SecretMountNone}); got != "server only" {
+		t.Errorf("describeSecretMount(none) = %q", got)
+	}
+}
@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceSandbox string
+	serviceDir     string
+	serviceJSON    bool
+	serviceLines   int
+	serviceFollow  bool
+)
+
+// servicesDir holds one directory per service, relative to the sandbox
+// user's home
+const servicesDir = ".cvps/services"
+
+// serviceSupervisorPath is where the supervisor script is installed, relative
+// to the sandbox user's home
+const serviceSupervisorPath = ".cvps/bin/cvps-supervise"
+
+var serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage long-running processes in a sandbox",
+	Long: `Run dev servers and other long-running processes in a sandbox so they
+survive terminal disconnects.
+
+Each service runs under a small supervisor installed by cvps, which restarts
+the command when it exits and appends its output to a log. Services are kept
+in ~/.cvps/services in the sandbox; stopping one keeps its definition, so it
+can be started again by name.`,
+	Example: `  # Start a dev server in the current sandbox
+  cvps service start web -- npm run dev
+
+  # Start it in another directory of a named sandbox
+  cvps service start api --sandbox myproject --dir /workspace/api -- go run .
+
+  # See what is running
+  cvps service status
+
+  # Follow its output
+  cvps service logs web -f`,
+}
+
+var serviceStartCmd = &cobra.Command{
+	Use:   "start <name> [-- <command>]",
+	Short: "Start a service, defining it if a command is given",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runServiceStart,
+}
+
+var serviceStopCmd = &cobra.Command{
+	Use:   "stop <name>",
+	Short: "Stop a service and everything it started",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runServiceStop,
+}
+
+var serviceRestartCmd = &cobra.Command{
+	Use:   "restart <name>",
+	Short: "Restart a service",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runServiceRestart,
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status [name]",
+	Short: "Show services and whether they are running",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runServiceStatus,
+}
+
+var serviceLogsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "Show the output of a service",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runServiceLogs,
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceStartCmd)
+	serviceCmd.AddCommand(serviceStopCmd)
+	serviceCmd.AddCommand(serviceRestartCmd)
+	serviceCmd.AddCommand(serviceStatusCmd)
+	serviceCmd.AddCommand(serviceLogsCmd)
+
+	serviceCmd.PersistentFlags().StringVarP(&serviceSandbox, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+
+	serviceStartCmd.Flags().StringVar(&serviceDir, "dir", "/workspace", "directory to run the command in")
+
+	serviceStatusCmd.Flags().BoolVar(&serviceJSON, "json", false, "output in JSON format")
+
+	serviceLogsCmd.Flags().IntVarP(&serviceLines, "lines", "n", 100, "number of lines to show")
+	serviceLogsCmd.Flags().BoolVarP(&serviceFollow, "follow", "f", false, "keep printing new output")
+}
+
+// service is one line of serviceStatusScript output
+type service struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	PID      int    `json:"pid,omitempty"`
+	Restarts int    `json:"restarts"`
+	// Seconds since the command was last (re)started, while running
+	UptimeSeconds int64  `json:"uptimeSeconds,omitempty"`
+	Command       string `json:"command"`
+}
+
+func validServiceName(name string) error {
+	if !serviceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid service name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+func runServiceStart(cmd *cobra.Command, args []string) error {
+	name, command := args[0], ""
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		if dash != 1 {
+			return fmt.Errorf("usage: cvps service start <name> [-- <command>]")
+		}
+		command = strings.Join(args[1:], " ")
+	} else if len(args) > 1 {
+		return fmt.Errorf("put the command after --, e.g. cvps service start %s -- npm run dev", name)
+	}
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+
+	conn, err := openSandboxSSHForRef(serviceSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return startService(context.Background(), conn, name, command, serviceDir)
+}
+
+// startService runs serviceStartScript and reports the outcome
+func startService(ctx context.Context, conn *remote.Client, name, command, dir string) error {
+	out, err := conn.Output(ctx, serviceStartScript(name, command, dir))
+	if err != nil {
+		return fmt.Errorf("failed to start service %s: %w", name, err)
+	}
+
+	state, pid, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	switch state {
+	case "running":
+		fmt.Printf("Service %s is already running (pid %s)\n", name, pid)
+	case "started":
+		color.Green("✓ Service %s started (pid %s)", name, pid)
+		fmt.Printf("  Follow its output with: cvps service logs %s -f\n", name)
+	default:
+		return fmt.Errorf("service %s exited right after starting. See 'cvps service logs %s'", name, name)
+	}
+	return nil
+}
+
+func runServiceStop(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+
+	conn, err := openSandboxSSHForRef(serviceSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Output(context.Background(), serviceStopScript(name)); err != nil {
+		return fmt.Errorf("failed to stop service %s: %w", name, err)
+	}
+	fmt.Printf("✓ Service %s stopped\n", name)
+	return nil
+}
+
+func runServiceRestart(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+
+	conn, err := openSandboxSSHForRef(serviceSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if _, err := conn.Output(ctx, serviceStopScript(name)); err != nil {
+		return fmt.Errorf("failed to stop service %s: %w", name, err)
+	}
+	return startService(ctx, conn, name, "", "")
+}
+
+func runServiceStatus(cmd *cobra.Command, args []string) error {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+		if err := validServiceName(name); err != nil {
+			return err
+		}
+	}
+
+	conn, err := openSandboxSSHForRef(serviceSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := conn.Output(context.Background(), serviceStatusScript(name))
+	if err != nil {
+		return fmt.Errorf("failed to get service status: %w", err)
+	}
+	services := parseServiceStatus(out)
+	if name != "" && len(services) == 0 {
+		return fmt.Errorf("no service named %s", name)
+	}
+
+	if serviceJSON {
+		if services == nil {
+			services = []service{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(services)
+	}
+
+	if len(services) == 0 {
+		fmt.Println("No services. Start one with 'cvps service start <name> -- <command>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tPID\tUPTIME\tRESTARTS\tCOMMAND")
+	for _, s := range services {
+		pid, uptime := "-", "-"
+		status := color.YellowString(s.Status)
+		if s.Status == "running" {
+			status = color.GreenString(s.Status)
+			pid = strconv.Itoa(s.PID)
+			uptime = shortDuration(time.Duration(s.UptimeSeconds) * time.Second)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", s.Name, status, pid, uptime, s.Restarts, truncateCommand(s.Command, 60))
+	}
+	w.Flush()
+	return nil
+}
+
+func runServiceLogs(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+	if serviceLines < 0 {
+		return fmt.Errorf("--lines must not be negative")
+	}
+
+	conn, err := openSandboxSSHForRef(serviceSandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = conn.Run(ctx, serviceLogsScript(name, serviceLines, serviceFollow), nil, os.Stdout, os.Stderr)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read logs of service %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseServiceStatus parses serviceStatusScript output, skipping malformed rows
+func parseServiceStatus(out []byte) []service {
+	var services []service
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 6)
+		if len(fields) < 6 {
+			continue
+		}
+		s := service{Name: fields[0], Status: fields[1], Command: fields[5]}
+		s.PID, _ = strconv.Atoi(fields[2])
+		s.Restarts, _ = strconv.Atoi(fields[3])
+		s.UptimeSeconds, _ = strconv.ParseInt(fields[4], 10, 64)
+		services = append(services, s)
+	}
+	return services
+}
+
+// serviceDirShell is the shell expression for a service's directory
+func serviceDirShell(name string) string {
+	return `"$HOME"/` + servicesDir + "/" + remote.Quote(name)
+}
+
+// serviceStartScript installs the supervisor and starts the service under it
+// in its own session, so it outlives the SSH connection. A non-empty command
+// (re)defines the service; otherwise the saved definition is used. It prints
+// "started <pid>", "running <pid>" if the service was already up, or "exited"
+// if it died straight away.
+func serviceStartScript(name, command, dir string) string {
+	lines := []string{
+		"set -e",
+		`command -v setsid >/dev/null 2>&1 || { echo "setsid is not installed in the sandbox" >&2; exit 1; }`,
+		"dir=" + serviceDirShell(name),
+		`mkdir -p "$dir" "$HOME/.cvps/bin"`,
+		`cat > "$HOME/` + serviceSupervisorPath + `" <<'CVPS_SUPERVISOR'` + "\n" + serviceSupervisorScript + "CVPS_SUPERVISOR",
+		`chmod +x "$HOME/` + serviceSupervisorPath + `"`,
+		`if [ -f "$dir/pid" ] && kill -0 "$(cat "$dir/pid")" 2>/dev/null; then echo "running $(cat "$dir/pid")"; exit 0; fi`,
+	}
+	if command != "" {
+		lines = append(lines,
+			"[ -d "+remote.Quote(dir)+" ] || { echo "+remote.Quote("directory "+dir+" does not exist")+" >&2; exit 1; }",
+			"printf '%s\\n' "+remote.Quote(command)+` > "$dir/command"`,
+			"printf '%s\\n' "+remote.Quote(dir)+` > "$dir/workdir"`,
+		)
+	}
+	lines = append(lines,
+		`[ -f "$dir/command" ] || { echo `+remote.Quote("service "+name+" is not defined; give the command to run after --")+` >&2; exit 1; }`,
+		`rm -f "$dir/pid"`,
+		`setsid "$HOME/`+serviceSupervisorPath+`" "$dir" >/dev/null 2>&1 </dev/null &`,
+		`sleep 1`,
+		`if [ -f "$dir/pid" ] && kill -0 "$(cat "$dir/pid")" 2>/dev/null; then echo "started $(cat "$dir/pid")"; else echo exited; fi`,
+	)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// serviceSupervisorScript runs a service's command, appending its output to
+// the log and restarting it when it exits. It leads its own process group, so
+// stopping the group stops everything the command started.
+const serviceSupervisorScript = `#!/bin/sh
+# Installed by 'cvps service': keeps a service running
+dir=$1
+cd "$(cat "$dir/workdir")" || exit 1
+echo $$ > "$dir/pid"
+trap 'rm -f "$dir/pid"; exit 0' TERM INT HUP
+restarts=0
+while :; do
+	echo "$restarts" > "$dir/restarts"
+	date +%s > "$dir/started"
+	echo "[cvps] starting: $(cat "$dir/command")" >> "$dir/log"
+	sh -c "$(cat "$dir/command")" >> "$dir/log" 2>&1 </dev/null &
+	wait $!
+	echo "[cvps] exited with status $?; restarting in 2s" >> "$dir/log"
+	restarts=$((restarts + 1))
+	sleep 2
+done
+`
+
+// serviceStopScript stops the supervisor's process group, forcefully if it
+// has not exited after 10 seconds
+func serviceStopScript(name string) string {
+	return strings.Join([]string{
+		"dir=" + serviceDirShell(name),
+		`[ -f "$dir/command" ] || { echo ` + remote.Quote("no service named "+name) + ` >&2; exit 1; }`,
+		`pid=$(cat "$dir/pid" 2>/dev/null) || exit 0`,
+		`kill -s TERM -- "-$pid" 2>/dev/null || { rm -f "$dir/pid"; exit 0; }`,
+		`i=0`,
+		`while kill -0 "$pid" 2>/dev/null && [ $i -lt 10 ]; do sleep 1; i=$((i + 1)); done`,
+		`kill -0 "$pid" 2>/dev/null && kill -s KILL -- "-$pid" 2>/dev/null`,
+		`rm -f "$dir/pid"`,
+	}, "\n") + "\n"
+}
+
+// serviceStatusScript prints a tab-separated line per service (or just name):
+// name, status, pid, restarts, uptime in seconds and command
+func serviceStatusScript(name string) string {
+	pattern := `"$HOME"/` + servicesDir + "/*/"
+	if name != "" {
+		pattern = serviceDirShell(name) + "/"
+	}
+	return `now=$(date +%s)
+for dir in ` + pattern + `; do
+	[ -f "$dir/command" ] || continue
+	status=stopped pid= uptime=
+	if [ -f "$dir/pid" ] && kill -0 "$(cat "$dir/pid")" 2>/dev/null; then
+		status=running
+		pid=$(cat "$dir/pid")
+		uptime=$((now - $(cat "$dir/started" 2>/dev/null || echo "$now")))
+	fi
+	printf '%s\t%s\t%s\t%s\t%s\t%s\n' "$(basename "$dir")" "$status" "$pid" "$(cat "$dir/restarts" 2>/dev/null || echo 0)" "$uptime" "$(head -n 1 "$dir/command")"
+done
+`
+}
+
+// serviceLogsScript prints the end of a service's log, optionally following it
+func serviceLogsScript(name string, lines int, follow bool) string {
+	tail := "tail -n " + strconv.Itoa(lines)
+	if follow {
+		tail += " -f"
+	}
+	return "dir=" + serviceDirShell(name) + "\n" +
+		`[ -f "$dir/command" ] || { echo ` + remote.Quote("no service named "+name) + ` >&2; exit 1; }` + "\n" +
+		`touch "$dir/log"` + "\n" +
+		tail + ` "$dir/log"` + "\n"
+}
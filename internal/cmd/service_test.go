@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseServiceStatus(t *testing.T) {
+	out := []byte("web\trunning\t4242\t1\t95\tnpm run dev\n" +
+		"api\tstopped\t\t0\t\tgo run . -addr\t:8080\n" +
+		"garbage\n")
+
+	services := parseServiceStatus(out)
+	if len(services) != 2 {
+		t.Fatalf("parseServiceStatus() returned %d services, want 2", len(services))
+	}
+	if s := services[0]; s.PID != 4242 || s.Restarts != 1 || s.UptimeSeconds != 95 || s.Command != "npm run dev" {
+		t.Errorf("Unexpected running service: %+v", s)
+	}
+	if s := services[1]; s.Status != "stopped" || s.PID != 0 || s.Command != "go run . -addr\t:8080" {
+		t.Errorf("Unexpected stopped service: %+v", s)
+	}
+}
+
+func TestValidServiceName(t *testing.T) {
+	for _, name := range []string{"web", "api-v2", "worker_1.blue"} {
+		if err := validServiceName(name); err != nil {
+			t.Errorf("validServiceName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-web", "../etc", "a b", "web;rm"} {
+		if err := validServiceName(name); err == nil {
+			t.Errorf("validServiceName(%q) expected error", name)
+		}
+	}
+}
+
+// TestServiceScripts runs the sandbox scripts locally: start a service, check
+// its status and log, then stop it
+func TestServiceScripts(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not installed")
+	}
+
+	home := t.TempDir()
+	work := filepath.Join(home, "workspace")
+	os.MkdirAll(work, 0755)
+	run := func(script string) string {
+		t.Helper()
+		c := exec.Command("sh", "-c", script)
+		c.Env = append(os.Environ(), "HOME="+home)
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("script failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+
+	if out := run(serviceStartScript("web", "echo hello; sleep 30", work)); !strings.HasPrefix(out, "started ") {
+		t.Fatalf("Expected service to start, got %q", out)
+	}
+	t.Cleanup(func() { run(serviceStopScript("web")) })
+
+	if out := run(serviceStartScript("web", "", "")); !strings.HasPrefix(out, "running ") {
+		t.Errorf("Expected second start to report it running, got %q", out)
+	}
+
+	services := parseServiceStatus([]byte(run(serviceStatusScript(""))))
+	if len(services) != 1 || services[0].Name != "web" || services[0].Status != "running" || services[0].PID == 0 {
+		t.Fatalf("Unexpected status: %+v", services)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(run(serviceLogsScript("web", 10, false)), "hello") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected service output in the log")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	run(serviceStopScript("web"))
+	services = parseServiceStatus([]byte(run(serviceStatusScript("web"))))
+	if len(services) != 1 || services[0].Status != "stopped" {
+		t.Errorf("Expected service to be stopped, got %+v", services)
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareRole    string
+	shareExpires string
+	shareJSON    bool
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Share a sandbox with other account members",
+	Long: `Let other members of your account connect to a sandbox.
+
+The write role gives full terminal and file access. The read-only role gives a
+terminal as a user that cannot write to /workspace, and file transfers (cp,
+ls, edit) can only read, which suits pairing on a bug or a review.
+
+With --expires the grant stops working on its own, so temporary debugging
+access does not have to be remembered and revoked.`,
+}
+
+var shareAddCmd = &cobra.Command{
+	Use:   "add <sandbox> <email>",
+	Short: "Give an account member access to a sandbox",
+	Example: `  # Read-only access for a working day
+  cvps share add repro-123 dev@example.com --role read-only --expires 8h
+
+  # Full access until revoked
+  cvps share add web alice@example.com`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runShareAdd,
+	ValidArgsFunction: completeSandboxes,
+}
+
+var shareListCmd = &cobra.Command{
+	Use:               "list [sandbox]",
+	Short:             "List who a sandbox is shared with",
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runShareList,
+	ValidArgsFunction: completeSandboxes,
+}
+
+var shareRevokeCmd = &cobra.Command{
+	Use:               "revoke <sandbox> <email|grant-id>",
+	Short:             "Remove someone's access to a sandbox",
+	Args:              cobra.ExactArgs(2),
+	RunE:              runShareRevoke,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+	shareCmd.AddCommand(shareAddCmd)
+	shareCmd.AddCommand(shareListCmd)
+	shareCmd.AddCommand(shareRevokeCmd)
+
+	shareAddCmd.Flags().StringVar(&shareRole, "role", claudevps.ShareRoleWrite, "access to give ("+strings.Join(claudevps.ShareRoles, " or ")+")")
+	shareAddCmd.Flags().StringVar(&shareExpires, "expires", "never", "how long the access lasts (e.g. 8h, 7d) or never")
+
+	shareListCmd.Flags().BoolVar(&shareJSON, "json", false, "output in JSON format")
+}
+
+func runShareAdd(cmd *cobra.Command, args []string) error {
+	role := strings.ToLower(strings.TrimSpace(shareRole))
+	if !slices.Contains(claudevps.ShareRoles, role) {
+		return fmt.Errorf("unknown role %q (use %s)", shareRole, strings.Join(claudevps.ShareRoles, " or "))
+	}
+	expires, err := parseExpiry(shareExpires)
+	if err != nil {
+		return err
+	}
+	user := strings.TrimSpace(args[1])
+	if !strings.Contains(user, "@") {
+		return fmt.Errorf("%q is not an email address", user)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	grant, err := client.ShareSandbox(ctx, sandboxID, &claudevps.ShareSandboxRequest{
+		User:             user,
+		Role:             role,
+		ExpiresInSeconds: int(expires / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to share sandbox: %w", err)
+	}
+
+	fmt.Printf("✓ Shared %s with %s (%s)\n", args[0], grant.User, grant.Role)
+	if grant.ExpiresAt != "" {
+		fmt.Printf("  Expires %s\n", formatTime(grant.ExpiresAt))
+	} else {
+		color.Yellow("⚠ This access never expires; remove it with 'cvps share revoke %s %s' when no longer needed.", args[0], grant.User)
+	}
+	return nil
+}
+
+func runShareList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	sandboxID, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+
+	grants, err := client.ListAccessGrants(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("failed to list access grants: %w", err)
+	}
+
+	if shareJSON {
+		if grants == nil {
+			grants = []claudevps.AccessGrant{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(grants)
+	}
+
+	if len(grants) == 0 {
+		fmt.Println("Not shared with anyone. Run 'cvps share add <sandbox> <email>' to share it.")
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tROLE\tEXPIRES\tCREATED")
+	for _, g := range grants {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g.ID, g.User, g.Role, formatExpiry(g.ExpiresAt, now), formatTime(g.CreatedAt))
+	}
+	w.Flush()
+	return nil
+}
+
+func runShareRevoke(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	grantID, who := args[1], args[1]
+	if strings.Contains(who, "@") {
+		grants, err := client.ListAccessGrants(ctx, sandboxID)
+		if err != nil {
+			return fmt.Errorf("failed to list access grants: %w", err)
+		}
+		i := slices.IndexFunc(grants, func(g claudevps.AccessGrant) bool { return strings.EqualFold(g.User, who) })
+		if i < 0 {
+			return fmt.Errorf("%s is not shared with %s", args[0], who)
+		}
+		grantID = grants[i].ID
+	}
+
+	if err := client.RevokeAccessGrant(ctx, sandboxID, grantID); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("access grant not found: %s", who)
+		}
+		return fmt.Errorf("failed to revoke access: %w", err)
+	}
+
+	fmt.Printf("✓ Removed %s's access to %s\n", who, args[0])
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestRunShareAdd(t *testing.T) {
+	var got claudevps.ShareSandboxRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-abc123/grants":
+			json.NewDecoder(r.Body).Decode(&got)
+			json.NewEncoder(w).Encode(claudevps.AccessGrant{ID: "grant-1", User: got.User, Role: got.Role, ExpiresAt: "2030-01-01T08:00:00Z"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	shareRole, shareExpires = "Read-Only", "8h"
+	defer func() { shareRole, shareExpires = claudevps.ShareRoleWrite, "never" }()
+
+	out, err := captureStdout(t, func() error { return runShareAdd(nil, []string{"sbx-abc123", "dev@example.com"}) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.User != "dev@example.com" || got.Role != claudevps.ShareRoleReadOnly || got.ExpiresInSeconds != 8*3600 {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+	if !strings.Contains(out, "Expires") {
+		t.Errorf("Expected the expiry to be shown, got %q", out)
+	}
+
+	shareRole = "admin"
+	if err := runShareAdd(nil, []string{"sbx-abc123", "dev@example.com"}); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+}
+
+func TestRunShareAdd_Expires(t *testing.T) {
+	var got claudevps.ShareSandboxRequest
+	requests := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		got = claudevps.ShareSandboxRequest{}
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(claudevps.AccessGrant{ID: "grant-1", User: got.User, Role: got.Role})
+	}))
+	defer func() { shareExpires = "never" }()
+
+	tests := map[string]int{"7d": 7 * 24 * 3600, "never": 0}
+	for in, want := range tests {
+		shareExpires = in
+		if _, err := captureStdout(t, func() error { return runShareAdd(nil, []string{"sbx-abc123", "dev@example.com"}) }); err != nil {
+			t.Fatalf("--expires %s: %v", in, err)
+		}
+		if got.ExpiresInSeconds != want {
+			t.Errorf("--expires %s sent %d seconds, want %d", in, got.ExpiresInSeconds, want)
+		}
+	}
+
+	requests = 0
+	shareExpires = "30m"
+	if err := runShareAdd(nil, []string{"sbx-abc123", "dev@example.com"}); err == nil || requests != 0 {
+		t.Errorf("Expected --expires 30m to be rejected before any request, got %v after %d requests", err, requests)
+	}
+}
+
+func TestRunShareRevoke_ByEmail(t *testing.T) {
+	var revoked string
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-abc123/grants":
+			json.NewEncoder(w).Encode(claudevps.AccessGrantList{Data: []claudevps.AccessGrant{
+				{ID: "grant-1", User: "alice@example.com", Role: claudevps.ShareRoleWrite},
+				{ID: "grant-2", User: "dev@example.com", Role: claudevps.ShareRoleReadOnly},
+			}})
+		case r.Method == "DELETE":
+			revoked = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	if _, err := captureStdout(t, func() error { return runShareRevoke(nil, []string{"sbx-abc123", "Dev@example.com"}) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if revoked != "/sandboxes/sbx-abc123/grants/grant-2" {
+		t.Errorf("Revoked %q", revoked)
+	}
+
+	if err := runShareRevoke(nil, []string{"sbx-abc123", "bob@example.com"}); err == nil {
+		t.Error("Expected an error for someone without access")
+	}
+}
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+)
+
+// snapshotArchiveTimeout bounds how long an export or import may take to be
+// prepared on the server
+const snapshotArchiveTimeout = 30 * time.Minute
+
+// snapshotCreateTimeout bounds how long snapshot create waits for each snapshot
+const snapshotCreateTimeout = 10 * time.Minute
+
+var (
+	snapshotOutput string
+	snapshotName   string
+	snapshotGroup  string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage sandbox snapshots",
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create [sandbox]",
+	Short: "Take a snapshot of a sandbox or a group",
+	Long: `Take a snapshot of a sandbox, or of every sandbox in a group with --group,
+and wait until each is ready. Without an argument the current sandbox is used.
+
+Snapshots are named after the sandbox and the time unless --name is given.`,
+	Example: `  # Snapshot the current sandbox
+  cvps snapshot create
+
+  # Snapshot a sandbox under a name of your choosing
+  cvps snapshot create web --name before-upgrade
+
+  # Snapshot every sandbox in a group
+  cvps snapshot create --group workshop`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runSnapshotCreate,
+	ValidArgsFunction: completeSandboxes,
+}
+
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export <snapshot-id>",
+	Short: "Download a snapshot as a local archive",
+	Long: `Download a snapshot as a local archive, for keeping environments off-platform
+or moving them to another account with 'cvps snapshot import'.
+
+The download is resumable: if it is interrupted, run the same command again
+and it continues from the partial file.`,
+	Example: `  # Export a snapshot to snap-123.tar.zst
+  cvps snapshot export snap-123
+
+  # Export to a specific file
+  cvps snapshot export snap-123 -o backups/web.tar.zst`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotExport,
+}
+
+var snapshotImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Create a snapshot from a local archive",
+	Long: `Upload an archive created with 'cvps snapshot export' as a new snapshot.
+Interrupted uploads resume when the command is run again.`,
+	Example: `  # Import an archive and start a sandbox from it
+  cvps snapshot import web.tar.zst --name web
+  cvps up --from-snapshot <snapshot-id>`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotImport,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotExportCmd)
+	snapshotCmd.AddCommand(snapshotImportCmd)
+
+	snapshotCreateCmd.Flags().StringVar(&snapshotName, "name", "", "name of the snapshot (default <sandbox>-<time>)")
+	snapshotCreateCmd.Flags().StringVarP(&snapshotGroup, "group", "g", "", "snapshot every sandbox in a group")
+
+	snapshotExportCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "archive path (default <snapshot-id>.tar.zst)")
+	snapshotImportCmd.Flags().StringVar(&snapshotName, "name", "", "name of the imported snapshot")
+	addProgressFlag(snapshotExportCmd)
+	addProgressFlag(snapshotImportCmd)
+}
+
+func runSnapshotCreate(cmd *cobra.Command, args []string) error {
+	if snapshotGroup != "" && len(args) > 0 {
+		return fmt.Errorf("cannot combine a sandbox argument with --group")
+	}
+	if snapshotGroup != "" && snapshotName != "" {
+		return fmt.Errorf("cannot combine --name with --group; snapshots are named after each sandbox")
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if snapshotGroup == "" {
+		ref := ""
+		if len(args) > 0 {
+			ref = args[0]
+		}
+		sandboxID, err := resolveSandboxRef(ctx, client, ref)
+		if err != nil {
+			return err
+		}
+		sandbox, err := client.GetSandbox(ctx, sandboxID)
+		if err != nil {
+			if claudevps.IsNotFound(err) {
+				return &sandboxNotFoundError{Ref: sandboxID}
+			}
+			return fmt.Errorf("failed to get sandbox: %w", err)
+		}
+		_, err = createSnapshot(ctx, client, sandbox, snapshotName)
+		return err
+	}
+
+	sandboxes, err := groupSandboxes(ctx, client, snapshotGroup)
+	if err != nil {
+		return err
+	}
+	if len(sandboxes) == 0 {
+		fmt.Println("No sandboxes to snapshot.")
+		return nil
+	}
+
+	failed := 0
+	for i := range sandboxes {
+		if _, err := createSnapshot(ctx, client, &sandboxes[i], ""); err != nil {
+			if claudevps.IsReadOnly(err) {
+				return err
+			}
+			fmt.Printf("  %s (%s): failed: %s\n", sandboxes[i].Name, sandboxes[i].ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d snapshots failed", failed, len(sandboxes))
+	}
+	fmt.Printf("\n✓ Took %d snapshots of group '%s'\n", len(sandboxes), snapshotGroup)
+	return nil
+}
+
+// createSnapshot snapshots a sandbox and waits until the snapshot is ready.
+// An empty name picks one from the sandbox name and the time.
+func createSnapshot(ctx context.Context, client *claudevps.Client, sandbox *claudevps.Sandbox, name string) (*claudevps.Snapshot, error) {
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", sandbox.Name, time.Now().Format("20060102-150405"))
+	}
+	snap, err := client.CreateSnapshot(ctx, sandbox.ID, &claudevps.CreateSnapshotRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	snap, err = waitForSnapshot(ctx, client, snap, "snapshot", fmt.Sprintf(" Taking snapshot of %s...", sandbox.Name), snapshotCreateTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✓ Snapshot %s of %s is ready. Restore with: cvps up --from-snapshot %s\n", snap.ID, sandbox.Name, snap.ID)
+	return snap, nil
+}
+
+func runSnapshotExport(cmd *cobra.Command, args []string) error {
+	if err := validProgress(); err != nil {
+		return err
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	id := args[0]
+
+	out := snapshotOutput
+	if out == "" {
+		out = id + ".tar.zst"
+	}
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+
+	snap, err := client.GetSnapshot(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snap.Status != claudevps.SnapshotStatusReady {
+		return fmt.Errorf("snapshot %s is not ready (status: %s)", snap.ID, snap.Status)
+	}
+
+	archive, err := waitForSnapshotExport(ctx, client, snap.ID)
+	if err != nil {
+		return err
+	}
+
+	// Download into a partial file so an interrupted export can resume
+	partial := out + ".part"
+	f, err := os.OpenFile(partial, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var offset int64
+	if info, err := f.Stat(); err == nil && info.Size() <= archive.SizeBytes {
+		offset = info.Size()
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	if offset > 0 {
+		fmt.Printf("Resuming download at %s\n", formatBytes(offset))
+	}
+
+	bar := newTransferBar(archive.SizeBytes, "Downloading", "download")
+	bar.Set64(offset)
+	err = client.DownloadSnapshotArchive(ctx, snap.ID, f, offset, archive.SizeBytes, func(n int64) {
+		bar.Set64(n)
+	})
+	if err != nil {
+		fmt.Println()
+		emitStage("download", progressFailed, err.Error())
+		return fmt.Errorf("download interrupted, run the command again to resume: %w", err)
+	}
+	bar.Finish()
+	fmt.Println()
+
+	if archive.SHA256 != "" {
+		sum, err := fileSHA256(f)
+		if err != nil {
+			return err
+		}
+		if sum != archive.SHA256 {
+			f.Close()
+			os.Remove(partial)
+			emitStage("download", progressFailed, "checksum mismatch")
+			return fmt.Errorf("checksum mismatch for snapshot %s: got %s, want %s", snap.ID, sum, archive.SHA256)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partial, out); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Exported snapshot %s to %s (%s)\n", snap.ID, out, formatBytes(archive.SizeBytes))
+	return nil
+}
+
+func runSnapshotImport(cmd *cobra.Command, args []string) error {
+	if err := validProgress(); err != nil {
+		return err
+	}
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("%s is empty", args[0])
+	}
+
+	// The checksum identifies the archive, so a rerun resumes the same import
+	sum, err := fileSHA256(f)
+	if err != nil {
+		return err
+	}
+
+	req := &claudevps.ImportSnapshotRequest{Name: snapshotName, Size: info.Size(), SHA256: sum}
+	bar := newTransferBar(info.Size(), "Uploading", "upload")
+	snap, err := client.ImportSnapshot(ctx, req, f, func(n int64) {
+		bar.Set64(n)
+	})
+	if err != nil {
+		fmt.Println()
+		emitStage("upload", progressFailed, err.Error())
+		return fmt.Errorf("upload interrupted, run the command again to resume: %w", err)
+	}
+	bar.Finish()
+	fmt.Println()
+
+	if snap, err = waitForSnapshot(ctx, client, snap, "restore", " Restoring snapshot...", snapshotArchiveTimeout); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Imported snapshot %s. Start a sandbox from it with: cvps up --from-snapshot %s\n", snap.ID, snap.ID)
+	return nil
+}
+
+// waitForSnapshotExport starts exporting a snapshot and waits until the
+// archive can be downloaded
+func waitForSnapshotExport(ctx context.Context, client *claudevps.Client, id string) (*claudevps.SnapshotArchive, error) {
+	emitStage("prepare", progressRunning, "")
+	archive, err := client.ExportSnapshot(ctx, id)
+	if err != nil {
+		emitStage("prepare", progressFailed, err.Error())
+		return nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	archive, err = pollSnapshotExport(ctx, client, id, archive)
+	if err != nil {
+		emitStage("prepare", progressFailed, err.Error())
+		return nil, err
+	}
+	emitStage("prepare", progressDone, "")
+	return archive, nil
+}
+
+// pollSnapshotExport polls an export until its archive is ready
+func pollSnapshotExport(ctx context.Context, client *claudevps.Client, id string, archive *claudevps.SnapshotArchive) (*claudevps.SnapshotArchive, error) {
+	if archive.Status == claudevps.SnapshotStatusReady {
+		return archive, nil
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Preparing snapshot archive..."
+	s.Start()
+	defer s.Stop()
+
+	var err error
+	deadline := time.Now().Add(snapshotArchiveTimeout)
+	for archive.Status != claudevps.SnapshotStatusReady {
+		switch {
+		case archive.Status == claudevps.SnapshotStatusFailed:
+			return nil, fmt.Errorf("export of snapshot %s failed", id)
+		case time.Now().After(deadline):
+			return nil, fmt.Errorf("timeout waiting for export of snapshot %s", id)
+		}
+
+		time.Sleep(snapshotPollInterval)
+		if archive, err = client.GetSnapshotExport(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to get export status: %w", err)
+		}
+	}
+	return archive, nil
+}
+
+// waitForSnapshot polls snap until it is ready, showing suffix on a spinner
+// and reporting it as stage with --progress json
+func waitForSnapshot(ctx context.Context, client *claudevps.Client, snap *claudevps.Snapshot, stage, suffix string, timeout time.Duration) (*claudevps.Snapshot, error) {
+	emitStage(stage, progressRunning, "")
+	snap, err := pollSnapshot(ctx, client, snap, suffix, timeout)
+	if err != nil {
+		emitStage(stage, progressFailed, err.Error())
+		return nil, err
+	}
+	emitStage(stage, progressDone, "")
+	return snap, nil
+}
+
+func pollSnapshot(ctx context.Context, client *claudevps.Client, snap *claudevps.Snapshot, suffix string, timeout time.Duration) (*claudevps.Snapshot, error) {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = suffix
+	s.Start()
+	defer s.Stop()
+
+	var err error
+	deadline := time.Now().Add(timeout)
+	for snap.Status != claudevps.SnapshotStatusReady {
+		switch {
+		case snap.Status == claudevps.SnapshotStatusFailed:
+			return nil, fmt.Errorf("snapshot %s failed", snap.ID)
+		case time.Now().After(deadline):
+			return nil, fmt.Errorf("timeout waiting for snapshot %s", snap.ID)
+		}
+
+		time.Sleep(snapshotPollInterval)
+		if snap, err = client.GetSnapshot(ctx, snap.ID); err != nil {
+			return nil, fmt.Errorf("failed to get snapshot status: %w", err)
+		}
+	}
+	return snap, nil
+}
+
+// fileSHA256 returns the hex SHA-256 of f's whole content
+func fileSHA256(f *os.File) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRunSnapshotExport_ResumesPartialFile(t *testing.T) {
+	content := []byte(strings.Repeat("disk", 2000))
+	exportPolls := 0
+	var firstRange string
+
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusReady})
+		case r.Method == "POST" && r.URL.Path == "/snapshots/snap-1/export":
+			json.NewEncoder(w).Encode(claudevps.SnapshotArchive{SnapshotID: "snap-1", Status: claudevps.SnapshotStatusPending})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1/export":
+			exportPolls++
+			json.NewEncoder(w).Encode(claudevps.SnapshotArchive{
+				SnapshotID: "snap-1",
+				Status:     claudevps.SnapshotStatusReady,
+				SizeBytes:  int64(len(content)),
+				SHA256:     sha256Hex(content),
+			})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1/export/content":
+			if firstRange == "" {
+				firstRange = r.Header.Get("Range")
+			}
+			var start, end int64
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+			if end >= int64(len(content)) {
+				end = int64(len(content)) - 1
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[start : end+1])
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	out := filepath.Join(t.TempDir(), "snap.tar.zst")
+	if err := os.WriteFile(out+".part", content[:1000], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotOutput = out
+	defer func() { snapshotOutput = "" }()
+
+	if err := runSnapshotExport(nil, []string{"snap-1"}); err != nil {
+		t.Fatalf("runSnapshotExport() error = %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected archive to be written: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("archive has %d bytes, want %d identical bytes", len(got), len(content))
+	}
+	if _, err := os.Stat(out + ".part"); !os.IsNotExist(err) {
+		t.Error("expected partial file to be renamed")
+	}
+	if !strings.HasPrefix(firstRange, "bytes=1000-") {
+		t.Errorf("expected download to resume at 1000, first range %q", firstRange)
+	}
+	if exportPolls != 1 {
+		t.Errorf("expected export status to be polled once, got %d", exportPolls)
+	}
+}
+
+func TestRunSnapshotExport_ChecksumMismatch(t *testing.T) {
+	content := []byte("corrupted archive")
+
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/snapshots/snap-1":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusReady})
+		case "/snapshots/snap-1/export":
+			json.NewEncoder(w).Encode(claudevps.SnapshotArchive{
+				SnapshotID: "snap-1",
+				Status:     claudevps.SnapshotStatusReady,
+				SizeBytes:  int64(len(content)),
+				SHA256:     sha256Hex([]byte("original archive")),
+			})
+		case "/snapshots/snap-1/export/content":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(content)
+		}
+	}))
+
+	out := filepath.Join(t.TempDir(), "snap.tar.zst")
+	snapshotOutput = out
+	defer func() { snapshotOutput = "" }()
+
+	err := runSnapshotExport(nil, []string{"snap-1"})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	for _, p := range []string{out, out + ".part"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", p)
+		}
+	}
+}
+
+func TestRunSnapshotExport_NotReady(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-1", Status: claudevps.SnapshotStatusPending})
+	}))
+
+	snapshotOutput = filepath.Join(t.TempDir(), "snap.tar.zst")
+	defer func() { snapshotOutput = "" }()
+
+	err := runSnapshotExport(nil, []string{"snap-1"})
+	if err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Fatalf("expected not ready error, got %v", err)
+	}
+}
+
+func TestRunSnapshotImport(t *testing.T) {
+	content := []byte(strings.Repeat("archive", 500))
+	var uploaded []byte
+	var req claudevps.ImportSnapshotRequest
+	polls := 0
+
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/snapshots/imports":
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(claudevps.UploadSession{ID: "imp-1", Size: req.Size})
+		case r.Method == "PUT" && r.URL.Path == "/snapshots/imports/imp-1":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, data...)
+			json.NewEncoder(w).Encode(claudevps.UploadSession{ID: "imp-1", Size: req.Size, Offset: int64(len(uploaded))})
+		case r.Method == "POST" && r.URL.Path == "/snapshots/imports/imp-1/complete":
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-new", Status: claudevps.SnapshotStatusPending})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-new":
+			polls++
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-new", Status: claudevps.SnapshotStatusReady})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	archive := filepath.Join(t.TempDir(), "web.tar.zst")
+	if err := os.WriteFile(archive, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotName = "web"
+	defer func() { snapshotName = "" }()
+
+	output, err := captureStdout(t, func() error {
+		return runSnapshotImport(nil, []string{archive})
+	})
+	if err != nil {
+		t.Fatalf("runSnapshotImport() error = %v", err)
+	}
+
+	if req.Name != "web" || req.Size != int64(len(content)) || req.SHA256 != sha256Hex(content) {
+		t.Errorf("Unexpected import request: %+v", req)
+	}
+	if !bytes.Equal(uploaded, content) {
+		t.Errorf("uploaded %d bytes, want %d identical bytes", len(uploaded), len(content))
+	}
+	if polls != 1 {
+		t.Errorf("expected snapshot to be polled once, got %d", polls)
+	}
+	if !strings.Contains(output, "cvps up --from-snapshot snap-new") {
+		t.Errorf("expected restore hint, got %q", output)
+	}
+}
+
+func TestRunSnapshotCreate_Group(t *testing.T) {
+	var created []string
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/batch":
+			json.NewEncoder(w).Encode(map[string]any{"results": []claudevps.BatchResult{
+				{ID: "sbx-1", Status: http.StatusOK, Sandbox: &claudevps.Sandbox{ID: "sbx-1", Name: "web-1"}},
+				{ID: "sbx-2", Status: http.StatusOK, Sandbox: &claudevps.Sandbox{ID: "sbx-2", Name: "web-2"}},
+			}})
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/snapshots"):
+			var req claudevps.CreateSnapshotRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req.Name)
+			json.NewEncoder(w).Encode(claudevps.Snapshot{ID: "snap-" + req.Name, Status: claudevps.SnapshotStatusReady})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	saveTestGroup(t, "workshop", "sbx-1", "sbx-2")
+	snapshotGroup = "workshop"
+	defer func() { snapshotGroup = "" }()
+
+	out, err := captureStdout(t, func() error { return runSnapshotCreate(nil, nil) })
+	if err != nil {
+		t.Fatalf("runSnapshotCreate() error = %v", err)
+	}
+	if len(created) != 2 || !strings.HasPrefix(created[0], "web-1-") || !strings.HasPrefix(created[1], "web-2-") {
+		t.Errorf("Unexpected snapshots: %v", created)
+	}
+	if !strings.Contains(out, "Took 2 snapshots of group 'workshop'") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	if err := runSnapshotCreate(nil, []string{"web-1"}); err == nil {
+		t.Error("Expected an error combining a sandbox with --group")
+	}
+}
@@ -4,31 +4,53 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
+	"github.com/achronon/cvps/internal/cache"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	statusAll   bool
-	statusJSON  bool
-	statusWatch bool
+	statusAll    bool
+	statusJSON   bool
+	statusOut    string
+	statusWatch  bool
+	statusProbe  bool
+	statusGroup  string
+	statusDel    bool
+	statusCache  bool
+	statusWide   bool
+	statusHealth bool
+	statusDetail bool
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status [sandbox-id]",
+	Use:   "status [sandbox...]",
 	Short: "Show sandbox status",
 	Long: `Show the status of sandboxes.
 
 Without arguments, shows the status of the current context sandbox
 (determined by .cvps.yaml in the current directory).
-If no local context exists, falls back to listing all sandboxes.`,
+If no local context exists, falls back to listing all sandboxes.
+
+With --health, each sandbox is checked: its API status, SSH reachability,
+disk usage (unhealthy at 90% full) and, if one is running, its sync session.
+Stopped and provisioning sandboxes are inactive and not checked. The command
+exits non-zero if any sandbox shown is unhealthy, so it can be used as a
+monitoring probe.
+
+Given several sandboxes, or a group with --details, status shows the full
+status and resource usage of each one in a single report, fetching them
+concurrently.`,
 	Example: `  # Show current sandbox status
   cvps status
 
@@ -38,9 +60,35 @@ If no local context exists, falls back to listing all sandboxes.`,
   # Show specific sandbox
   cvps status sbx-abc123
 
+  # Show deleted sandboxes that can still be restored
+  cvps status --all --deleted
+
+  # Show the sandboxes in a group
+  cvps status --group workshop
+
+  # Show the full status and usage of several sandboxes
+  cvps status web api worker
+  cvps status --group workshop --details
+
+  # Export all sandboxes for a spreadsheet
+  cvps status --all -o csv > sandboxes.csv
+
   # Watch status continuously
-  cvps status --watch`,
-	RunE: runStatus,
+  cvps status --watch
+
+  # Check SSH reachability and latency of every sandbox
+  cvps status --all --probe
+
+  # Show region, storage and last activity too, with a health column
+  cvps status --all --wide --health
+
+  # Alert from cron when the project sandbox is unhealthy
+  cvps status --health -o json > /dev/null || notify-send "sandbox unhealthy"
+
+  # Show the last known list when the API is unreachable
+  cvps status --cached`,
+	ValidArgsFunction: completeSandboxes,
+	RunE:              runStatus,
 }
 
 func init() {
@@ -48,10 +96,38 @@ func init() {
 
 	statusCmd.Flags().BoolVarP(&statusAll, "all", "a", false, "list all sandboxes")
 	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "output in JSON format")
+	statusCmd.Flags().StringVarP(&statusOut, "output", "o", outputTable, "output format (table|json|csv|tsv)")
 	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "watch for changes")
+	statusCmd.Flags().BoolVar(&statusProbe, "probe", false, "probe SSH reachability and latency")
+	statusCmd.Flags().BoolVar(&statusDel, "deleted", false, "list deleted sandboxes in the trash (with --all)")
+	statusCmd.Flags().StringVarP(&statusGroup, "group", "g", "", "list the sandboxes in a group")
+	statusCmd.Flags().BoolVar(&statusDetail, "details", false, "with --group, show the full status and usage of every sandbox instead of a list")
+	statusCmd.Flags().BoolVar(&statusWide, "wide", false, "show more columns in the list: region, storage and last activity")
+	statusCmd.Flags().BoolVar(&statusHealth, "health", false, "check SSH, disk and sync health and exit non-zero if any sandbox is unhealthy")
+	statusCmd.Flags().BoolVar(&statusCache, "cached", false, "show the locally cached sandbox list without contacting the API")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	if err := validOutputFormat(statusOut); err != nil {
+		return err
+	}
+
+	if statusHealth && statusWatch {
+		return fmt.Errorf("--health cannot be combined with --watch")
+	}
+	if statusWatch && (len(args) > 1 || statusDetail) {
+		return fmt.Errorf("--watch follows one sandbox; it cannot be combined with several sandboxes or --details")
+	}
+	if statusDetail && statusGroup == "" {
+		return fmt.Errorf("--details requires --group")
+	}
+	if statusCache {
+		if statusWatch || statusProbe || statusHealth {
+			return fmt.Errorf("--cached cannot be combined with --watch, --probe or --health")
+		}
+		return showCachedStatus(args)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -61,8 +137,43 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'cvps login' first")
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
 	ctx := context.Background()
+	reconcilePendingDeletions(ctx, client)
+
+	if statusGroup != "" {
+		sandboxes, err := groupSandboxes(ctx, client, statusGroup)
+		if err != nil {
+			return err
+		}
+		if statusDetail {
+			reports := make([]statusReport, len(sandboxes))
+			for i := range sandboxes {
+				reports[i] = statusReport{ID: sandboxes[i].ID, Sandbox: &sandboxes[i]}
+			}
+			return showStatusReport(ctx, client, reports)
+		}
+		return printSandboxList(ctx, client, sandboxes)
+	}
+
+	if len(args) > 1 {
+		reports := make([]statusReport, len(args))
+		for i, ref := range args {
+			id, err := resolveSandboxRef(ctx, client, ref)
+			if err != nil {
+				return err
+			}
+			reports[i] = statusReport{ID: id}
+		}
+		return showStatusReport(ctx, client, reports)
+	}
+
+	if statusDel {
+		if !statusAll {
+			return fmt.Errorf("--deleted requires --all")
+		}
+		return listDeletedSandboxes(ctx, client)
+	}
 
 	// List all sandboxes
 	if statusAll {
@@ -75,7 +186,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Get sandbox ID from args or context
 	sandboxID := ""
 	if len(args) > 0 {
-		sandboxID = args[0]
+		if sandboxID, err = resolveSandboxRef(ctx, client, args[0]); err != nil {
+			return err
+		}
 	} else {
 		id, err := getCurrentSandboxID()
 		if err != nil {
@@ -97,71 +210,298 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return showSandboxStatus(ctx, client, sandboxID)
 }
 
-func listAllSandboxes(ctx context.Context, client *api.Client) error {
+func listAllSandboxes(ctx context.Context, client *claudevps.Client) error {
 	list, err := client.ListSandboxes(ctx, 1, 100)
 	if err != nil {
+		if c, _ := cache.LoadSandboxes(); c != nil {
+			return fmt.Errorf("failed to list sandboxes: %w\nRun 'cvps status --cached' to see the list from %s", err, formatAge(c.Age()))
+		}
 		return fmt.Errorf("failed to list sandboxes: %w", err)
 	}
+	if len(list.Data) >= list.Total {
+		cacheSandboxes(list.Data)
+	}
+	return printSandboxList(ctx, client, list.Data)
+}
 
-	if statusJSON {
+// checkSandboxes runs the checks asked for with --probe and --health. SSH is
+// probed for --health too, but only reported with --probe.
+func checkSandboxes(ctx context.Context, client *claudevps.Client, sandboxes []claudevps.Sandbox) (map[string]probeResult, map[string]healthResult) {
+	if !statusProbe && !statusHealth {
+		return nil, nil
+	}
+	probes := probeSandboxes(ctx, sandboxes)
+	var health map[string]healthResult
+	if statusHealth {
+		health = checkHealth(ctx, client, sandboxes, probes)
+	}
+	if !statusProbe {
+		probes = nil
+	}
+	return probes, health
+}
+
+// printSandboxList prints sandboxes in the selected format. client may be
+// nil when no checks were asked for.
+func printSandboxList(ctx context.Context, client *claudevps.Client, sandboxes []claudevps.Sandbox) error {
+	probes, health := checkSandboxes(ctx, client, sandboxes)
+
+	switch format := statusFormat(); {
+	case format == outputJSON:
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		return enc.Encode(list.Data)
+		if statusProbe || statusHealth {
+			if err := enc.Encode(withChecks(sandboxes, probes, health)); err != nil {
+				return err
+			}
+			return unhealthyError(sandboxes, health)
+		}
+		return enc.Encode(sandboxes)
+	case isDelimited(format):
+		if err := writeSandboxRows(os.Stdout, format, sandboxes, probes, health); err != nil {
+			return err
+		}
+		return unhealthyError(sandboxes, health)
 	}
 
-	if len(list.Data) == 0 {
+	if len(sandboxes) == 0 {
 		fmt.Println("No sandboxes found. Run 'cvps up' to create one.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tCPU\tMEMORY\tCREATED")
+	header := "ID\tNAME\tSTATUS\tCPU\tMEMORY\tCREATED"
+	if statusWide {
+		header += "\tSTORAGE\tREGION\tLAST ACTIVE"
+	}
+	if statusProbe {
+		header += "\tPROBE"
+	}
+	if statusHealth {
+		header += "\tHEALTH"
+	}
+	// Only shown when there is something to show, as it is the widest column
+	described := slices.ContainsFunc(sandboxes, func(s claudevps.Sandbox) bool { return s.Description != "" })
+	if described {
+		header += "\tDESCRIPTION"
+	}
+	fmt.Fprintln(w, header)
 
-	for _, s := range list.Data {
+	for _, s := range sandboxes {
 		status := colorStatus(s.Status)
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%dGB\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%dGB\t%s",
 			s.ID, s.Name, status, s.CPUCores, s.MemoryGB, formatTime(s.CreatedAt))
+		if statusWide {
+			fmt.Fprintf(w, "\t%dGB\t%s\t%s", s.StorageGB, orDash(s.Region), orDash(formatTime(s.LastActive)))
+		}
+		if statusProbe {
+			probe := "-"
+			if p, ok := probes[s.ID]; ok {
+				probe = p.String()
+			}
+			fmt.Fprintf(w, "\t%s", probe)
+		}
+		if statusHealth {
+			fmt.Fprintf(w, "\t%s", health[s.ID].colored())
+		}
+		if described {
+			fmt.Fprintf(w, "\t%s", orDash(truncate(s.Description, listDescriptionWidth)))
+		}
+		fmt.Fprintln(w)
 	}
 
 	w.Flush()
+	return unhealthyError(sandboxes, health)
+}
+
+// listDescriptionWidth is where descriptions are cut off in the list
+const listDescriptionWidth = 40
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// statusFormat returns the selected output format, honouring --json
+func statusFormat() string {
+	if statusJSON {
+		return outputJSON
+	}
+	return statusOut
+}
+
+// writeSandboxRows writes sandboxes as CSV or TSV with raw, uncoloured values
+func writeSandboxRows(w io.Writer, format string, sandboxes []claudevps.Sandbox, probes map[string]probeResult, health map[string]healthResult) error {
+	header := []string{"id", "name", "status", "cpu_cores", "memory_gb", "storage_gb", "created_at", "last_active_at", "description"}
+	if statusProbe {
+		header = append(header, "probe")
+	}
+	if statusHealth {
+		header = append(header, "health")
+	}
+
+	rows := make([][]string, 0, len(sandboxes))
+	for _, s := range sandboxes {
+		row := []string{
+			s.ID, s.Name, s.Status,
+			strconv.Itoa(s.CPUCores), strconv.Itoa(s.MemoryGB), strconv.Itoa(s.StorageGB),
+			s.CreatedAt, s.LastActive, s.Description,
+		}
+		if statusProbe {
+			probe := ""
+			if p, ok := probes[s.ID]; ok {
+				probe = p.String()
+			}
+			row = append(row, probe)
+		}
+		if statusHealth {
+			row = append(row, health[s.ID].String())
+		}
+		rows = append(rows, row)
+	}
+	return writeDelimited(w, format, header, rows)
+}
+
+func listDeletedSandboxes(ctx context.Context, client *claudevps.Client) error {
+	list, err := client.ListDeletedSandboxes(ctx, 1, 100)
+	if err != nil {
+		return fmt.Errorf("failed to list deleted sandboxes: %w", err)
+	}
+
+	switch format := statusFormat(); {
+	case format == outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list.Data)
+	case isDelimited(format):
+		rows := make([][]string, 0, len(list.Data))
+		for _, s := range list.Data {
+			rows = append(rows, []string{s.ID, s.Name, s.DeletedAt, s.PurgeAt})
+		}
+		return writeDelimited(os.Stdout, format, []string{"id", "name", "deleted_at", "purge_at"}, rows)
+	}
+
+	if len(list.Data) == 0 {
+		fmt.Println("The trash is empty.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tDELETED\tPURGED AFTER")
+	for _, s := range list.Data {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.ID, s.Name, formatTime(s.DeletedAt), formatTime(s.PurgeAt))
+	}
+	w.Flush()
+	fmt.Println("\nRestore with: cvps restore <id>")
 	return nil
 }
 
-func showSandboxStatus(ctx context.Context, client *api.Client, sandboxID string) error {
+// sandboxWithChecks is the JSON shape of a sandbox annotated with probe and
+// health results
+type sandboxWithChecks struct {
+	claudevps.Sandbox
+	Probe  *probeResult  `json:"probe,omitempty"`
+	Health *healthResult `json:"health,omitempty"`
+}
+
+func withChecks(sandboxes []claudevps.Sandbox, probes map[string]probeResult, health map[string]healthResult) []sandboxWithChecks {
+	out := make([]sandboxWithChecks, 0, len(sandboxes))
+	for _, s := range sandboxes {
+		entry := sandboxWithChecks{Sandbox: s}
+		if p, ok := probes[s.ID]; ok {
+			entry.Probe = &p
+		}
+		if h, ok := health[s.ID]; ok {
+			entry.Health = &h
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func showSandboxStatus(ctx context.Context, client *claudevps.Client, sandboxID string) error {
 	sandbox, err := client.GetSandbox(ctx, sandboxID)
 	if err != nil {
-		if api.IsNotFound(err) {
-			return fmt.Errorf("sandbox not found: %s", sandboxID)
+		if claudevps.IsNotFound(err) {
+			return &sandboxNotFoundError{Ref: sandboxID}
 		}
 		return fmt.Errorf("failed to get sandbox: %w", err)
 	}
 
-	if statusJSON {
+	sandboxes := []claudevps.Sandbox{*sandbox}
+	probes, health := checkSandboxes(ctx, client, sandboxes)
+
+	switch format := statusFormat(); {
+	case format == outputJSON:
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
+		if statusProbe || statusHealth {
+			if err := enc.Encode(withChecks(sandboxes, probes, health)[0]); err != nil {
+				return err
+			}
+			return unhealthyError(sandboxes, health)
+		}
 		return enc.Encode(sandbox)
+	case isDelimited(format):
+		if err := writeSandboxRows(os.Stdout, format, sandboxes, probes, health); err != nil {
+			return err
+		}
+		return unhealthyError(sandboxes, health)
 	}
 
 	printSandboxDetails(sandbox)
-	return nil
+
+	if statusProbe {
+		fmt.Println()
+		if p, ok := probes[sandbox.ID]; ok {
+			fmt.Printf("Probe: %s\n", p)
+			if !p.Reachable && sandbox.Connectivity.SSHProxyRequired {
+				fmt.Println("  Note: this route requires a ProxyCommand, so direct probes are expected to fail.")
+			}
+		} else {
+			fmt.Println("Probe: skipped (sandbox is not running)")
+		}
+	}
+	if statusHealth {
+		fmt.Println()
+		fmt.Printf("Health: %s\n", health[sandbox.ID].colored())
+	}
+	return unhealthyError(sandboxes, health)
 }
 
-func printSandboxDetails(s *api.Sandbox) {
+func printSandboxDetails(s *claudevps.Sandbox) {
 	fmt.Printf("Sandbox: %s\n", s.Name)
 	fmt.Printf("ID:      %s\n", s.ID)
 	fmt.Printf("Status:  %s\n", colorStatus(s.Status))
+	if summary := failureSummary(s); summary != "" && isFailedStatus(s.Status) {
+		fmt.Printf("Reason:  %s\n", color.RedString(summary))
+	}
+	if s.Description != "" {
+		fmt.Printf("Notes:   %s\n", s.Description)
+	}
 	fmt.Println()
 
 	fmt.Println("Resources:")
 	fmt.Printf("  CPU:     %d cores\n", s.CPUCores)
 	fmt.Printf("  Memory:  %d GB\n", s.MemoryGB)
 	fmt.Printf("  Storage: %d GB\n", s.StorageGB)
+	if s.Arch != "" {
+		fmt.Printf("  Arch:    %s\n", s.Arch)
+	}
 	fmt.Println()
 
 	fmt.Printf("Created: %s\n", formatTime(s.CreatedAt))
 	if s.LastActive != "" {
 		fmt.Printf("Last Active: %s\n", formatTime(s.LastActive))
 	}
+	if next := s.NextScheduledAction; next != nil {
+		fmt.Printf("Scheduled: %s at %s\n", next.Action, formatTime(next.At))
+	}
+	if s.Policy.SuspendsOnDisconnect() {
+		fmt.Printf("Suspend: %s\n", disconnectSuspendStatus(s.Policy, time.Now()))
+	}
 
 	if isRunningStatus(s.Status) && s.SSHHost != "" {
 		fmt.Println()
@@ -181,7 +521,7 @@ func printSandboxDetails(s *api.Sandbox) {
 	}
 }
 
-func watchSandbox(ctx context.Context, client *api.Client, sandboxID string) error {
+func watchSandbox(ctx context.Context, client *claudevps.Client, sandboxID string) error {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
@@ -208,7 +548,7 @@ func watchSandbox(ctx context.Context, client *api.Client, sandboxID string) err
 	}
 }
 
-func watchAllSandboxes(ctx context.Context, client *api.Client) error {
+func watchAllSandboxes(ctx context.Context, client *claudevps.Client) error {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
@@ -227,6 +567,25 @@ func watchAllSandboxes(ctx context.Context, client *api.Client) error {
 	}
 }
 
+// isFailedStatus reports whether status means the sandbox failed
+func isFailedStatus(status string) bool {
+	return status == "failed" || status == "error"
+}
+
+// failureSummary combines why a sandbox failed with its reason code, or
+// returns "" if the API did not say
+func failureSummary(s *claudevps.Sandbox) string {
+	detail := s.FailureDetail()
+	switch {
+	case detail != "" && s.StatusReason != "":
+		return detail + " (" + s.StatusReason + ")"
+	case detail != "":
+		return detail
+	default:
+		return s.StatusReason
+	}
+}
+
 func colorStatus(status string) string {
 	switch strings.ToLower(strings.TrimSpace(status)) {
 	case "running":
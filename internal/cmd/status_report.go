@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// statusReportConcurrency is how many sandboxes a multi-sandbox status report
+// fetches at once
+const statusReportConcurrency = 8
+
+// statusReport is one sandbox in a multi-sandbox status report, and its JSON
+// shape
+type statusReport struct {
+	ID      string                    `json:"id"`
+	Sandbox *claudevps.Sandbox        `json:"sandbox,omitempty"`
+	Metrics *claudevps.SandboxMetrics `json:"metrics,omitempty"`
+	Probe   *probeResult              `json:"probe,omitempty"`
+	Health  *healthResult             `json:"health,omitempty"`
+	Error   string                    `json:"error,omitempty"`
+}
+
+// fetchStatusReports fills in the sandbox of reports that have none and the
+// metrics of running ones, statusReportConcurrency at a time
+func fetchStatusReports(ctx context.Context, client *claudevps.Client, reports []statusReport) {
+	sem := make(chan struct{}, statusReportConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range reports {
+		wg.Add(1)
+		go func(r *statusReport) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if r.Sandbox == nil {
+				sandbox, err := client.GetSandbox(ctx, r.ID)
+				if err != nil {
+					if claudevps.IsNotFound(err) {
+						r.Error = "sandbox not found"
+					} else {
+						r.Error = err.Error()
+					}
+					return
+				}
+				r.Sandbox = sandbox
+			}
+			if isRunningStatus(r.Sandbox.Status) {
+				m, err := client.GetSandboxMetrics(ctx, r.ID)
+				if err != nil {
+					debuglog.Printf("metrics of %s skipped: %v", r.ID, err)
+				}
+				r.Metrics = m
+			}
+		}(&reports[i])
+	}
+	wg.Wait()
+}
+
+// showStatusReport prints the full status of several sandboxes, in the order
+// given. Sandboxes that cannot be fetched are reported in place and make the
+// command fail once everything else is shown.
+func showStatusReport(ctx context.Context, client *claudevps.Client, reports []statusReport) error {
+	fetchStatusReports(ctx, client, reports)
+
+	var sandboxes []claudevps.Sandbox
+	failed := 0
+	for _, r := range reports {
+		if r.Sandbox != nil {
+			sandboxes = append(sandboxes, *r.Sandbox)
+		} else {
+			failed++
+		}
+	}
+	probes, health := checkSandboxes(ctx, client, sandboxes)
+	for i := range reports {
+		if p, ok := probes[reports[i].ID]; ok {
+			reports[i].Probe = &p
+		}
+		if h, ok := health[reports[i].ID]; ok {
+			reports[i].Health = &h
+		}
+	}
+
+	switch format := statusFormat(); {
+	case format == outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	case isDelimited(format):
+		if err := writeSandboxRows(os.Stdout, format, sandboxes, probes, health); err != nil {
+			return err
+		}
+		for _, r := range reports {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", r.ID, r.Error)
+			}
+		}
+	default:
+		for i, r := range reports {
+			if i > 0 {
+				fmt.Printf("\n%s\n\n", strings.Repeat("─", 40))
+			}
+			printStatusReport(r)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sandboxes could not be fetched", failed, len(reports))
+	}
+	return unhealthyError(sandboxes, health)
+}
+
+func printStatusReport(r statusReport) {
+	if r.Sandbox == nil {
+		color.Red("✗ %s: %s", r.ID, r.Error)
+		return
+	}
+	printSandboxDetails(r.Sandbox)
+
+	if m := r.Metrics; m != nil {
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Printf("  CPU:     %.1f%%\n", m.CPUPercent)
+		fmt.Printf("  Memory:  %s / %s\n", formatBytes(m.MemoryUsedBytes), formatBytes(m.MemoryTotalBytes))
+		fmt.Printf("  Disk:    %s / %s\n", formatBytes(m.DiskUsedBytes), formatBytes(m.DiskTotalBytes))
+		fmt.Printf("  Cost:    %s to date\n", formatMoney(m.CostToDate, m.Currency))
+	}
+	if statusProbe {
+		if r.Probe != nil {
+			fmt.Printf("\nProbe: %s\n", r.Probe)
+		} else {
+			fmt.Println("\nProbe: skipped (sandbox is not running)")
+		}
+	}
+	if r.Health != nil {
+		fmt.Printf("\nHealth: %s\n", r.Health.colored())
+	}
+}
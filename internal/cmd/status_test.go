@@ -5,11 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/fatih/color"
 )
 
@@ -117,17 +119,18 @@ func TestFormatTime(t *testing.T) {
 func TestPrintSandboxDetails(t *testing.T) {
 	tests := []struct {
 		name    string
-		sandbox *api.Sandbox
+		sandbox *claudevps.Sandbox
 	}{
 		{
 			name: "sandbox with all fields",
-			sandbox: &api.Sandbox{
+			sandbox: &claudevps.Sandbox{
 				ID:         "sbx-abc123",
 				Name:       "my-project",
 				Status:     "running",
 				CPUCores:   2,
 				MemoryGB:   4,
 				StorageGB:  20,
+				Arch:       "arm64",
 				CreatedAt:  "2024-01-15T10:00:00Z",
 				LastActive: "2024-01-15T11:30:00Z",
 				SSHHost:    "sbx-abc123.example.com",
@@ -137,7 +140,7 @@ func TestPrintSandboxDetails(t *testing.T) {
 		},
 		{
 			name: "sandbox without connection info",
-			sandbox: &api.Sandbox{
+			sandbox: &claudevps.Sandbox{
 				ID:        "sbx-def456",
 				Name:      "test-env",
 				Status:    "stopped",
@@ -149,7 +152,7 @@ func TestPrintSandboxDetails(t *testing.T) {
 		},
 		{
 			name: "sandbox provisioning",
-			sandbox: &api.Sandbox{
+			sandbox: &claudevps.Sandbox{
 				ID:        "sbx-ghi789",
 				Name:      "new-sandbox",
 				Status:    "provisioning",
@@ -159,6 +162,27 @@ func TestPrintSandboxDetails(t *testing.T) {
 				CreatedAt: "2024-01-15T12:00:00Z",
 			},
 		},
+		{
+			name: "sandbox with scheduled stop",
+			sandbox: &claudevps.Sandbox{
+				ID:                  "sbx-jkl012",
+				Name:                "office-hours",
+				Status:              "running",
+				CreatedAt:           "2024-01-15T08:00:00Z",
+				NextScheduledAction: &claudevps.ScheduledAction{Action: "stop", At: "2024-01-15T19:00:00Z"},
+			},
+		},
+		{
+			name: "failed sandbox",
+			sandbox: &claudevps.Sandbox{
+				ID:             "sbx-mno345",
+				Name:           "broken",
+				Status:         "failed",
+				StatusReason:   "quota_exceeded",
+				FailureMessage: "account limit of 8 CPU cores reached",
+				CreatedAt:      "2024-01-15T08:00:00Z",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,6 +194,26 @@ func TestPrintSandboxDetails(t *testing.T) {
 	}
 }
 
+func TestFailureSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox claudevps.Sandbox
+		want    string
+	}{
+		{"message and code", claudevps.Sandbox{StatusReason: "quota_exceeded", FailureMessage: "CPU limit reached"}, "CPU limit reached (quota_exceeded)"},
+		{"provisioning reason", claudevps.Sandbox{Provisioning: &claudevps.ProvisioningProgress{Reason: "boot timed out"}}, "boot timed out"},
+		{"code only", claudevps.Sandbox{StatusReason: "capacity_unavailable"}, "capacity_unavailable"},
+		{"nothing", claudevps.Sandbox{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureSummary(&tt.sandbox); got != tt.want {
+				t.Errorf("failureSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRunStatus_NoContextFallsBackToListAll(t *testing.T) {
 	homeDir := t.TempDir()
 	oldHome := os.Getenv("HOME")
@@ -187,8 +231,8 @@ func TestRunStatus_NoContextFallsBackToListAll(t *testing.T) {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(api.SandboxList{
-			Data: []api.Sandbox{
+		_ = json.NewEncoder(w).Encode(claudevps.SandboxList{
+			Data: []claudevps.Sandbox{
 				{
 					ID:        "sbx-abc123",
 					Name:      "my-project",
@@ -224,3 +268,60 @@ func TestRunStatus_NoContextFallsBackToListAll(t *testing.T) {
 		t.Fatalf("expected fallback to list all sandboxes, got error: %v", err)
 	}
 }
+
+func TestRunStatus_SeveralSandboxes(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sandboxes/sbx-aaa111", "/sandboxes/sbx-bbb222":
+			id := strings.TrimPrefix(r.URL.Path, "/sandboxes/")
+			_ = json.NewEncoder(w).Encode(claudevps.Sandbox{ID: id, Name: id, Status: "stopped"})
+		case "/sandboxes/sbx-ccc333":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"sandbox not found"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	origJSON, origWatch := statusJSON, statusWatch
+	statusJSON, statusWatch = true, false
+	t.Cleanup(func() { statusJSON, statusWatch = origJSON, origWatch })
+
+	out, err := captureStdout(t, func() error {
+		return runStatus(nil, []string{"sbx-aaa111", "sbx-bbb222", "sbx-ccc333"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("expected 1 of 3 sandboxes to fail, got %v", err)
+	}
+	if maxInFlight.Load() < 2 {
+		t.Errorf("expected sandboxes to be fetched concurrently, max in flight %d", maxInFlight.Load())
+	}
+
+	var reports []statusReport
+	if err := json.Unmarshal([]byte(out), &reports); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got %d", len(reports))
+	}
+	for i, id := range []string{"sbx-aaa111", "sbx-bbb222", "sbx-ccc333"} {
+		if reports[i].ID != id {
+			t.Errorf("report %d is %s, want %s", i, reports[i].ID, id)
+		}
+	}
+	if reports[0].Sandbox == nil || reports[2].Sandbox != nil || reports[2].Error != "sandbox not found" {
+		t.Errorf("unexpected reports: %+v", reports)
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// storageExpandTimeout bounds how long growing a disk may take
+const storageExpandTimeout = 30 * time.Minute
+
+var (
+	storageExpandSize  int
+	storageExpandAdd   int
+	storageExpandForce bool
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage sandbox storage",
+}
+
+var storageExpandCmd = &cobra.Command{
+	Use:   "expand [sandbox]",
+	Short: "Grow a sandbox disk",
+	Long: `Grow the disk of a sandbox, keeping its contents.
+
+Where the platform supports it the disk and filesystem grow while the sandbox
+keeps running; otherwise a running sandbox is restarted, after confirmation.
+The new size is checked against allowed sizes and your account quota first.
+Disks cannot shrink.`,
+	Example: `  # Add 20 GB to the current sandbox
+  cvps storage expand --add 20
+
+  # Grow a named sandbox to 100 GB
+  cvps storage expand web --size 100`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runStorageExpand,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.AddCommand(storageExpandCmd)
+
+	storageExpandCmd.Flags().IntVar(&storageExpandSize, "size", 0, "new disk size in GB")
+	storageExpandCmd.Flags().IntVar(&storageExpandAdd, "add", 0, "GB to add to the disk")
+	storageExpandCmd.Flags().BoolVarP(&storageExpandForce, "force", "f", false, "skip the confirmation prompt when a restart is needed")
+	storageExpandCmd.MarkFlagsMutuallyExclusive("size", "add")
+	storageExpandCmd.MarkFlagsOneRequired("size", "add")
+}
+
+// storageTarget returns the new disk size in GB from --size or --add
+func storageTarget(currentGB, size, add int) (int, error) {
+	target := size
+	if add != 0 {
+		if add < 0 {
+			return 0, fmt.Errorf("--add must be positive; disks cannot shrink")
+		}
+		target = currentGB + add
+	}
+	if target <= currentGB {
+		return 0, fmt.Errorf("new size %d GB must be larger than the current %d GB; disks cannot shrink", target, currentGB)
+	}
+	return target, nil
+}
+
+func runStorageExpand(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	id, err := resolveSandboxRef(ctx, client, ref)
+	if err != nil {
+		return err
+	}
+	sandbox, err := client.GetSandbox(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	target, err := storageTarget(sandbox.StorageGB, storageExpandSize, storageExpandAdd)
+	if err != nil {
+		return err
+	}
+	req := &claudevps.ExpandStorageRequest{StorageGB: target}
+
+	check, err := client.ValidateStorageExpansion(ctx, id, req)
+	if err != nil {
+		return fmt.Errorf("failed to check expansion: %w", err)
+	}
+	for _, warning := range check.Warnings {
+		color.Yellow("⚠ %s", warning.String())
+	}
+	if !check.Valid {
+		for _, issue := range check.Errors {
+			color.Red("✗ %s", issue.String())
+		}
+		return fmt.Errorf("cannot expand %s to %d GB", sandbox.Name, target)
+	}
+
+	if !check.Online && isRunningStatus(sandbox.Status) && !storageExpandForce {
+		color.New(color.FgYellow, color.Bold).Printf("⚠ Sandbox '%s' must restart to grow its disk to %d GB.\n", sandbox.Name, target)
+		fmt.Println("Running processes and open sessions end.")
+		fmt.Print("\nContinue? [y/N]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	expansion, err := client.ExpandStorage(ctx, id, req)
+	if err != nil {
+		return fmt.Errorf("failed to expand storage: %w", err)
+	}
+	if expansion, err = waitForStorageExpansion(ctx, client, expansion); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Disk of %s grown from %d GB to %d GB\n", sandbox.Name, expansion.FromGB, expansion.ToGB)
+	if expansion.Online {
+		fmt.Println("  The filesystem was grown in place; no restart was needed.")
+	}
+	return nil
+}
+
+// waitForStorageExpansion polls an expansion until it is done, showing its
+// progress on a spinner
+func waitForStorageExpansion(ctx context.Context, client *claudevps.Client, expansion *claudevps.StorageExpansion) (*claudevps.StorageExpansion, error) {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Expanding storage..."
+	s.Start()
+	defer s.Stop()
+
+	var err error
+	deadline := time.Now().Add(storageExpandTimeout)
+	for !expansion.Done() {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for storage expansion (waited %s)", storageExpandTimeout)
+		}
+		time.Sleep(snapshotPollInterval)
+		if expansion, err = client.GetStorageExpansion(ctx, expansion.SandboxID, expansion.ID); err != nil {
+			return nil, fmt.Errorf("failed to get expansion status: %w", err)
+		}
+		if expansion.Progress > 0 {
+			s.Suffix = fmt.Sprintf(" Expanding storage... %d%%", expansion.Progress)
+		}
+	}
+
+	if expansion.Status == claudevps.StorageExpansionFailed {
+		if expansion.Error != "" {
+			return nil, fmt.Errorf("storage expansion failed: %s", expansion.Error)
+		}
+		return nil, fmt.Errorf("storage expansion failed")
+	}
+	return expansion, nil
+}
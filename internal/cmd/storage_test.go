@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestStorageTarget(t *testing.T) {
+	tests := []struct {
+		current, size, add int
+		want               int
+		wantErr            bool
+	}{
+		{20, 50, 0, 50, false},
+		{20, 0, 15, 35, false},
+		{20, 20, 0, 0, true},
+		{20, 10, 0, 0, true},
+		{20, 0, -5, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := storageTarget(tt.current, tt.size, tt.add)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("storageTarget(%d, %d, %d) = %d, %v, want %d", tt.current, tt.size, tt.add, got, err, tt.want)
+		}
+	}
+}
+
+func TestRunStorageExpand_Online(t *testing.T) {
+	polls := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-web1":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-web1", Name: "web", Status: "running", StorageGB: 20})
+		case r.URL.Path == "/sandboxes/sbx-web1/storage/validate":
+			json.NewEncoder(w).Encode(claudevps.StorageExpansionCheck{SandboxValidation: claudevps.SandboxValidation{Valid: true}, Online: true})
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-web1/storage/expansions":
+			var req claudevps.ExpandStorageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.StorageGB != 30 {
+				t.Errorf("Expected 30 GB, got %d", req.StorageGB)
+			}
+			json.NewEncoder(w).Encode(claudevps.StorageExpansion{ID: "exp-1", SandboxID: "sbx-web1", Status: claudevps.StorageExpansionPending})
+		case r.URL.Path == "/sandboxes/sbx-web1/storage/expansions/exp-1":
+			polls++
+			status := claudevps.StorageExpansionResizing
+			if polls > 1 {
+				status = claudevps.StorageExpansionCompleted
+			}
+			json.NewEncoder(w).Encode(claudevps.StorageExpansion{ID: "exp-1", SandboxID: "sbx-web1", Status: status, FromGB: 20, ToGB: 30, Online: true})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	storageExpandAdd = 10
+	defer func() { storageExpandAdd = 0 }()
+
+	out, err := captureStdout(t, func() error {
+		return runStorageExpand(nil, []string{"sbx-web1"})
+	})
+	if err != nil {
+		t.Fatalf("runStorageExpand() error = %v", err)
+	}
+	if polls != 2 || !strings.Contains(out, "grown from 20 GB to 30 GB") {
+		t.Errorf("Unexpected result after %d polls: %q", polls, out)
+	}
+}
+
+func TestRunStorageExpand_OverQuota(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sandboxes/sbx-web1":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-web1", Name: "web", Status: "running", StorageGB: 20})
+		case "/sandboxes/sbx-web1/storage/validate":
+			w.Write([]byte(`{"valid":false,"errors":[{"field":"storageGb","message":"exceeds storage quota of 100 GB"}]}`))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	storageExpandSize = 500
+	defer func() { storageExpandSize = 0 }()
+
+	_, err := captureStdout(t, func() error {
+		return runStorageExpand(nil, []string{"sbx-web1"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot expand web to 500 GB") {
+		t.Errorf("runStorageExpand() error = %v, want quota error", err)
+	}
+}
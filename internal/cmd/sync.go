@@ -6,11 +6,14 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"syscall"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/migration"
 	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +21,8 @@ var (
 	syncIgnore  []string
 	syncOneWay  string
 	syncVerbose bool
+	syncForce   bool
+	syncPush    bool
 )
 
 var syncCmd = &cobra.Command{
@@ -26,13 +31,21 @@ var syncCmd = &cobra.Command{
 	Long: `Start bidirectional file synchronization between local directory and sandbox.
 
 Uses Mutagen for efficient, real-time file sync. Changes in either location
-are automatically propagated to the other.`,
+are automatically propagated to the other.
+
+Mutagen's first scan and staging of a large tree is slow. With --init-push the
+directory is first streamed to /workspace as one tar archive over SSH, so
+Mutagen starts from matching trees and only has to follow changes. Local
+files replace their copies on the sandbox during the push.`,
 	Example: `  # Sync current directory
   cvps sync
 
   # Sync specific directory
   cvps sync ./my-project
 
+  # Seed a large tree in bulk, then keep it in sync
+  cvps sync --init-push
+
   # One-way sync (local to remote only)
   cvps sync --one-way=local-to-remote
 
@@ -60,7 +73,9 @@ func init() {
 
 	syncCmd.Flags().StringSliceVar(&syncIgnore, "ignore", nil, "patterns to ignore")
 	syncCmd.Flags().StringVar(&syncOneWay, "one-way", "", "one-way sync (local-to-remote|remote-to-local)")
-	syncCmd.Flags().BoolVarP(&syncVerbose, "verbose", "v", false, "verbose output")
+	syncCmd.Flags().BoolVarP(&syncVerbose, "verbose", "v", false, "show live transfer statistics (files staged, throughput, ETA, problem paths)")
+	syncCmd.Flags().BoolVar(&syncForce, "force", false, "sync even if the files look too big for the free space on the sandbox")
+	syncCmd.Flags().BoolVar(&syncPush, "init-push", false, "push the directory as one archive over SSH before continuous sync starts")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
@@ -78,7 +93,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("mutagen is not installed. Install it with: brew install mutagen-io/mutagen/mutagen")
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
 	ctx := context.Background()
 
 	// Get sandbox ID
@@ -120,6 +135,28 @@ func runSync(cmd *cobra.Command, args []string) error {
 	if syncOneWay != "" && syncOneWay != "local-to-remote" && syncOneWay != "remote-to-local" {
 		return fmt.Errorf("invalid --one-way value: %s (must be 'local-to-remote' or 'remote-to-local')", syncOneWay)
 	}
+	if syncPush && syncOneWay == "remote-to-local" {
+		return fmt.Errorf("--init-push cannot be used with --one-way=remote-to-local")
+	}
+
+	// Only files going to the sandbox can fill its disk
+	if syncOneWay != "remote-to-local" {
+		files, err := migration.NewScanner(absPath, ignores).Scan()
+		if err != nil {
+			return fmt.Errorf("failed to scan directory: %w", err)
+		}
+		if err := checkRemoteFreeSpace(ctx, client, sandbox, files.TotalSize, syncForce); err != nil {
+			return err
+		}
+
+		if syncPush && files.Count > 0 {
+			fmt.Printf("Pushing %d files (%s) before starting sync...\n", files.Count, formatBytes(files.TotalSize))
+			if err := transferWorkspace(ctx, client, sandbox, absPath, files, true, false, transportSSH); err != nil {
+				return fmt.Errorf("initial push failed: %w", err)
+			}
+			fmt.Println()
+		}
+	}
 
 	// Create sync session
 	fmt.Printf("Starting sync: %s ↔ sandbox:%s:/workspace\n", absPath, sandbox.ID)
@@ -146,16 +183,16 @@ func runSync(cmd *cobra.Command, args []string) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	stopMonitor := func() {}
 	if syncVerbose {
-		// Monitor sync status in background
-		go func() {
-			if err := session.Monitor(os.Stdout); err != nil {
-				fmt.Fprintf(os.Stderr, "Monitor error: %v\n", err)
-			}
-		}()
+		// Show live transfer statistics in the background
+		ctx, cancel := context.WithCancel(context.Background())
+		stopMonitor = cancel
+		go monitorSyncSession(ctx, session)
 	}
 
 	<-sigChan
+	stopMonitor()
 
 	fmt.Println("\nStopping sync...")
 	if err := session.Terminate(); err != nil {
@@ -212,3 +249,47 @@ func runSyncStop(cmd *cobra.Command, args []string) error {
 	fmt.Println("✓ Sync session stopped")
 	return nil
 }
+
+// startSyncSession syncs the working directory to /workspace and returns a func
+// that stops the session, or nil if none was started. A session that is
+// already running, e.g. from 'cvps sync', is left alone. extraIgnores are
+// ignored in addition to sync.ignore_patterns.
+func startSyncSession(cfg *config.Config, sandbox *claudevps.Sandbox, extraIgnores ...string) func() {
+	if !mutagen.IsInstalled() {
+		color.Yellow("⚠ Files are not synced: mutagen is not installed")
+		return nil
+	}
+
+	name := fmt.Sprintf("cvps-%s", sandbox.ID)
+	if _, err := mutagen.GetSessionStatus(name); err == nil {
+		fmt.Println("✓ Using the running sync session")
+		return nil
+	}
+
+	absPath, err := filepath.Abs(".")
+	if err != nil {
+		color.Yellow("⚠ Files are not synced: %v", err)
+		return nil
+	}
+
+	session, err := mutagen.CreateSession(mutagen.SessionConfig{
+		Name:       name,
+		LocalPath:  absPath,
+		RemoteHost: fmt.Sprintf("%s@%s", sandbox.SSHUser, sandbox.SSHHost),
+		RemotePort: sandbox.SSHPort,
+		RemotePath: "/workspace",
+		Ignores:    append(slices.Clone(cfg.Sync.IgnorePatterns), extraIgnores...),
+	})
+	if err != nil {
+		color.Yellow("⚠ Files are not synced: %v", err)
+		return nil
+	}
+	fmt.Printf("✓ Syncing %s ↔ sandbox:/workspace\n", absPath)
+
+	return func() {
+		fmt.Println("Stopping sync...")
+		if err := session.Terminate(); err != nil {
+			color.Yellow("⚠ Failed to stop sync: %v", err)
+		}
+	}
+}
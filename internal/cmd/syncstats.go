@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// syncRateSmoothing weights the newest throughput sample in the moving average
+const syncRateSmoothing = 0.3
+
+// syncStats turns successive mutagen states into a one-line status with
+// throughput and ETA, and remembers which problem paths were reported
+type syncStats struct {
+	lastBytes uint64
+	lastTime  time.Time
+	rate      float64 // bytes per second
+	seen      map[string]bool
+}
+
+func newSyncStats() *syncStats {
+	return &syncStats{seen: make(map[string]bool)}
+}
+
+// update records state at now and returns the status line and any problems
+// not reported before
+func (s *syncStats) update(state *mutagen.State, now time.Time) (string, []mutagen.Problem) {
+	parts := []string{state.StatusText()}
+
+	if p := state.Staging(); p != nil {
+		if !s.lastTime.IsZero() && p.TotalReceivedSize >= s.lastBytes {
+			if elapsed := now.Sub(s.lastTime).Seconds(); elapsed > 0 {
+				sample := float64(p.TotalReceivedSize-s.lastBytes) / elapsed
+				s.rate = syncRateSmoothing*sample + (1-syncRateSmoothing)*s.rate
+			}
+		}
+		s.lastBytes, s.lastTime = p.TotalReceivedSize, now
+
+		parts = append(parts, fmt.Sprintf("%d/%d files", p.ReceivedFiles, p.ExpectedFiles))
+		if s.rate > 0 {
+			parts = append(parts, formatBytes(int64(s.rate))+"/s")
+			if p.ExpectedSize > p.TotalReceivedSize {
+				eta := time.Duration(float64(p.ExpectedSize-p.TotalReceivedSize) / s.rate * float64(time.Second))
+				parts = append(parts, "ETA "+eta.Round(time.Second).String())
+			}
+		}
+	} else {
+		// Between staging phases there is nothing to measure
+		s.lastTime, s.rate = time.Time{}, 0
+	}
+
+	if n := len(state.Conflicts); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d conflicts", n))
+	}
+
+	problems := state.Problems()
+	if len(problems) > 0 {
+		parts = append(parts, fmt.Sprintf("%d problems", len(problems)))
+	}
+	var fresh []mutagen.Problem
+	for _, p := range problems {
+		key := p.Path + "\x00" + p.Error
+		if !s.seen[key] {
+			s.seen[key] = true
+			fresh = append(fresh, p)
+		}
+	}
+
+	return strings.Join(parts, " · "), fresh
+}
+
+// statusLine keeps a single status line up to date. On a terminal the line is
+// rewritten in place; elsewhere each change is printed on its own line.
+type statusLine struct {
+	out      io.Writer
+	terminal bool
+	last     string
+}
+
+func (l *statusLine) set(line string) {
+	if line == l.last {
+		return
+	}
+	l.last = line
+	if l.terminal {
+		fmt.Fprintf(l.out, "\r\033[K%s", line)
+	} else {
+		fmt.Fprintln(l.out, line)
+	}
+}
+
+// printAbove prints a message without losing the status line
+func (l *statusLine) printAbove(msg string) {
+	if l.terminal {
+		fmt.Fprintf(l.out, "\r\033[K%s\n%s", msg, l.last)
+	} else {
+		fmt.Fprintln(l.out, msg)
+	}
+}
+
+// monitorSyncSession shows live sync statistics until ctx is done. Mutagen
+// versions without JSON monitor output get the raw monitor instead.
+func monitorSyncSession(ctx context.Context, session *mutagen.Session) {
+	stats := newSyncStats()
+	line := &statusLine{out: os.Stdout, terminal: term.IsTerminal(int(os.Stdout.Fd()))}
+	updated := false
+
+	err := session.MonitorStates(ctx, func(state *mutagen.State) {
+		updated = true
+		status, problems := stats.update(state, time.Now())
+		for _, p := range problems {
+			line.printAbove(color.YellowString("⚠ %s: %s", p.Path, p.Error))
+		}
+		line.set(status)
+	})
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	if updated {
+		fmt.Fprintf(os.Stderr, "\nMonitor error: %v\n", err)
+		return
+	}
+	if err := session.Monitor(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Monitor error: %v\n", err)
+	}
+}
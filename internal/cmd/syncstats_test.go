@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/mutagen"
+)
+
+func stagingState(received uint64, files uint64) *mutagen.State {
+	return &mutagen.State{
+		Status: "staging-beta",
+		Beta: mutagen.EndpointState{StagingProgress: &mutagen.StagingProgress{
+			ReceivedFiles:     files,
+			ExpectedFiles:     100,
+			TotalReceivedSize: received,
+			ExpectedSize:      10 << 20,
+		}},
+	}
+}
+
+func TestSyncStatsUpdate(t *testing.T) {
+	stats := newSyncStats()
+	start := time.Now()
+
+	line, _ := stats.update(stagingState(0, 0), start)
+	if line != "Staging files · 0/100 files" {
+		t.Errorf("first line = %q", line)
+	}
+
+	// 1 MB in one second, smoothed from zero
+	line, _ = stats.update(stagingState(1<<20, 10), start.Add(time.Second))
+	if want := "Staging files · 10/100 files · 307.2 KB/s · ETA 30s"; line != want {
+		t.Errorf("line = %q, want %q", line, want)
+	}
+
+	problem := &mutagen.State{
+		Status: "watching",
+		Alpha: mutagen.EndpointState{
+			ScanProblems: []mutagen.Problem{{Path: "secret.key", Error: "permission denied"}},
+		},
+	}
+	line, fresh := stats.update(problem, start.Add(2*time.Second))
+	if line != "Watching for changes · 1 problems" {
+		t.Errorf("line = %q", line)
+	}
+	if len(fresh) != 1 || fresh[0].Path != "secret.key" {
+		t.Errorf("fresh problems = %+v", fresh)
+	}
+	if _, fresh := stats.update(problem, start.Add(3*time.Second)); len(fresh) != 0 {
+		t.Errorf("problem reported twice: %+v", fresh)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	var buf bytes.Buffer
+	line := &statusLine{out: &buf}
+	line.set("Scanning files")
+	line.set("Scanning files")
+	line.printAbove("⚠ a: denied")
+	line.set("Watching for changes")
+	if want := "Scanning files\n⚠ a: denied\nWatching for changes\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	line = &statusLine{out: &buf, terminal: true}
+	line.set("Scanning files")
+	line.printAbove("⚠ a: denied")
+	if want := "\r\033[KScanning files\r\033[K⚠ a: denied\nScanning files"; buf.String() != want {
+		t.Errorf("terminal output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/telemetry"
+	"github.com/achronon/cvps/internal/version"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+// telemetryUploadTimeout bounds a background upload
+const telemetryUploadTimeout = 10 * time.Second
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Manage anonymous usage telemetry",
+	Long: `Telemetry is off unless you turn it on. When enabled, cvps records the
+name of each command you run, how long it took, whether it succeeded and the
+cvps version. Arguments, flags, sandbox names, IDs and credentials are never
+recorded, and events are sent without your API key.
+
+Events are queued under ~/.cvps/telemetry and uploaded in the background in
+batches. Setting DO_NOT_TRACK=1 disables telemetry regardless of this setting.`,
+}
+
+var telemetryOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Enable anonymous usage telemetry",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryOn,
+}
+
+var telemetryOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Disable telemetry and discard queued events",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryOff,
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is enabled",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryStatus,
+}
+
+// telemetryUploadCmd is started in the background when a batch is due
+var telemetryUploadCmd = &cobra.Command{
+	Use:    "upload",
+	Short:  "Upload queued telemetry events",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runTelemetryUpload,
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryOnCmd)
+	telemetryCmd.AddCommand(telemetryOffCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	telemetryCmd.AddCommand(telemetryUploadCmd)
+}
+
+func setTelemetry(enabled bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.Telemetry = enabled
+	return config.Save(cfg)
+}
+
+func runTelemetryOn(cmd *cobra.Command, args []string) error {
+	if err := setTelemetry(true); err != nil {
+		return err
+	}
+	fmt.Println("✓ Telemetry enabled. Thank you for helping improve cvps!")
+	if telemetry.DisabledByEnv() {
+		fmt.Printf("Note: %s is set, so nothing will be sent until it is unset.\n", telemetry.DoNotTrackEnv)
+	}
+	return nil
+}
+
+func runTelemetryOff(cmd *cobra.Command, args []string) error {
+	if err := setTelemetry(false); err != nil {
+		return err
+	}
+	if err := telemetry.Clear(); err != nil {
+		return fmt.Errorf("failed to discard queued events: %w", err)
+	}
+	fmt.Println("✓ Telemetry disabled")
+	return nil
+}
+
+func runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case telemetry.DisabledByEnv():
+		fmt.Printf("Telemetry: disabled (%s is set)\n", telemetry.DoNotTrackEnv)
+	case cfg.Telemetry:
+		fmt.Println("Telemetry: enabled")
+	default:
+		fmt.Println("Telemetry: disabled")
+	}
+
+	if n, err := telemetry.Pending(); err == nil && n > 0 {
+		fmt.Printf("Queued events: %d\n", n)
+	}
+	return nil
+}
+
+func runTelemetryUpload(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !telemetryEnabled(cfg) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryUploadTimeout)
+	defer cancel()
+
+	// No credentials: events must not be tied to an account
+	client := claudevps.NewClient(cfg.APIBaseURL, "", cliClientOptions()...)
+	return telemetry.Upload(ctx, func(ctx context.Context, batch telemetry.Batch) error {
+		return client.Post(ctx, "/telemetry/events", batch, nil)
+	})
+}
+
+func telemetryEnabled(cfg *config.Config) bool {
+	return cfg.Telemetry && !telemetry.DisabledByEnv()
+}
+
+// telemetryCommandName returns the name recorded for c, or "" if runs of c
+// are not recorded
+func telemetryCommandName(c *cobra.Command) string {
+	if c == nil || c == rootCmd || c.Hidden || !c.Runnable() {
+		return ""
+	}
+	for p := c; p != nil; p = p.Parent() {
+		if p.Hidden || p.Name() == cobra.ShellCompRequestCmd || p.Name() == cobra.ShellCompNoDescRequestCmd {
+			return ""
+		}
+	}
+	return strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" ")
+}
+
+// recordCommandUsage queues a telemetry event for a command run if the user
+// has opted in, and starts a background upload each time another batch is due
+func recordCommandUsage(c *cobra.Command, elapsed time.Duration, runErr error) {
+	name := telemetryCommandName(c)
+	if name == "" {
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil || !telemetryEnabled(cfg) {
+		return
+	}
+
+	n, err := telemetry.Record(telemetry.Event{
+		Command:    name,
+		DurationMS: elapsed.Milliseconds(),
+		Success:    runErr == nil,
+		Version:    version.Version,
+	})
+	if err != nil {
+		debuglog.Printf("telemetry: %v", err)
+		return
+	}
+	if n%telemetry.BatchSize == 0 {
+		startTelemetryUpload()
+	}
+}
+
+// startTelemetryUpload runs 'cvps telemetry upload' detached so the current
+// command can exit without waiting for the network. It is a variable so tests
+// do not start processes.
+var startTelemetryUpload = func() {
+	exe, err := os.Executable()
+	if err != nil {
+		debuglog.Printf("telemetry: %v", err)
+		return
+	}
+	c := exec.Command(exe, "telemetry", "upload")
+	detachProcess(c)
+	if err := c.Start(); err != nil {
+		debuglog.Printf("telemetry: failed to start upload: %v", err)
+		return
+	}
+	c.Process.Release()
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/telemetry"
+)
+
+func TestTelemetryCommandName(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"status"}, want: "status"},
+		{args: []string{"alias", "set"}, want: "alias set"},
+		{args: []string{"telemetry", "upload"}, want: ""},
+		{args: []string{"alias"}, want: ""},
+		{args: nil, want: ""},
+	}
+	for _, tt := range tests {
+		c, _, err := rootCmd.Find(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := telemetryCommandName(c); got != tt.want {
+			t.Errorf("telemetryCommandName(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRecordCommandUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(telemetry.DoNotTrackEnv, "")
+
+	uploads := 0
+	oldStart := startTelemetryUpload
+	startTelemetryUpload = func() { uploads++ }
+	defer func() { startTelemetryUpload = oldStart }()
+
+	// Off by default
+	recordCommandUsage(statusCmd, time.Second, nil)
+	if n, _ := telemetry.Pending(); n != 0 {
+		t.Fatalf("Pending() with telemetry off = %d, want 0", n)
+	}
+
+	if err := runTelemetryOn(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < telemetry.BatchSize; i++ {
+		recordCommandUsage(statusCmd, time.Second, errors.New("boom"))
+	}
+	if n, _ := telemetry.Pending(); n != telemetry.BatchSize {
+		t.Errorf("Pending() = %d, want %d", n, telemetry.BatchSize)
+	}
+	if uploads != 1 {
+		t.Errorf("uploads started = %d, want 1", uploads)
+	}
+
+	t.Setenv(telemetry.DoNotTrackEnv, "1")
+	recordCommandUsage(statusCmd, time.Second, nil)
+	if n, _ := telemetry.Pending(); n != telemetry.BatchSize {
+		t.Errorf("Pending() with %s = %d, want %d", telemetry.DoNotTrackEnv, n, telemetry.BatchSize)
+	}
+
+	if err := runTelemetryOff(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := telemetry.Pending(); n != 0 {
+		t.Errorf("Pending() after off = %d, want 0", n)
+	}
+}
+
+func TestRunTelemetryUpload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(telemetry.DoNotTrackEnv, "")
+
+	var got telemetry.Batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/telemetry/events" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") != "" || r.Header.Get("Authorization") != "" {
+			t.Error("telemetry upload must not send credentials")
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.APIBaseURL = server.URL
+	cfg.APIKey = "cvps_secret"
+	cfg.Telemetry = true
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := telemetry.Record(telemetry.Event{Command: "up", DurationMS: 1500, Success: true, Version: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runTelemetryUpload(nil, nil); err != nil {
+		t.Fatalf("runTelemetryUpload() error = %v", err)
+	}
+	if len(got.Events) != 1 || got.Events[0].Command != "up" || got.Events[0].DurationMS != 1500 {
+		t.Errorf("uploaded %+v", got)
+	}
+	if n, _ := telemetry.Pending(); n != 0 {
+		t.Errorf("Pending() after upload = %d, want 0", n)
+	}
+}
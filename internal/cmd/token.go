@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenName    string
+	tokenScopes  []string
+	tokenExpires string
+	tokenJSON    bool
+	tokenForce   bool
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage scoped API tokens for automation",
+	Long: `Manage API tokens limited to a set of scopes, so scripts and CI can use
+least-privilege credentials instead of your full-access API key.
+
+Scopes are <resource>:<read|write>; write also allows reading. Use a token by
+setting CVPS_API_KEY to it.`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a scoped API token",
+	Example: `  # Read-only access to sandboxes for a month
+  cvps token create --name dashboards --scope sandboxes:read --expires 30d
+
+  # CI that creates sandboxes and reads secrets
+  cvps token create --name ci --scope sandboxes:write,secrets:read`,
+	Args: cobra.NoArgs,
+	RunE: runTokenCreate,
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API tokens",
+	Args:  cobra.NoArgs,
+	RunE:  runTokenList,
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTokenRevoke,
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "name shown in listings")
+	tokenCreateCmd.Flags().StringSliceVar(&tokenScopes, "scope", nil, "scope to grant, repeatable ("+strings.Join(claudevps.TokenScopes, ", ")+")")
+	tokenCreateCmd.Flags().StringVar(&tokenExpires, "expires", "90d", "lifetime of the token (e.g. 12h, 30d) or never")
+	tokenCreateCmd.MarkFlagRequired("scope")
+
+	tokenListCmd.Flags().BoolVar(&tokenJSON, "json", false, "output in JSON format")
+
+	tokenRevokeCmd.Flags().BoolVarP(&tokenForce, "force", "f", false, "skip confirmation prompt")
+}
+
+// parseExpiry parses an --expires lifetime: a Go duration, a number of days
+// such as 30d, or never (returned as 0)
+func parseExpiry(s string) (time.Duration, error) {
+	if s == "never" {
+		return 0, nil
+	}
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --expires %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid --expires %q (use e.g. 12h, 30d or never)", s)
+		}
+	}
+	if d < time.Hour {
+		return 0, fmt.Errorf("--expires must be at least 1h")
+	}
+	return d, nil
+}
+
+// normalizeTokenScopes checks scopes against the known ones and drops
+// duplicates
+func normalizeTokenScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !slices.Contains(claudevps.TokenScopes, s) {
+			return nil, fmt.Errorf("unknown scope %q (use %s)", s, strings.Join(claudevps.TokenScopes, ", "))
+		}
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func runTokenCreate(cmd *cobra.Command, args []string) error {
+	scopes, err := normalizeTokenScopes(tokenScopes)
+	if err != nil {
+		return err
+	}
+	expires, err := parseExpiry(tokenExpires)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	token, err := client.CreateToken(context.Background(), &claudevps.CreateTokenRequest{
+		Name:             tokenName,
+		Scopes:           scopes,
+		ExpiresInSeconds: int(expires / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+
+	fmt.Printf("✓ Token %s created with %s\n", token.ID, strings.Join(token.Scopes, ", "))
+	if token.ExpiresAt != "" {
+		fmt.Printf("  Expires %s\n", formatTime(token.ExpiresAt))
+	} else {
+		color.Yellow("⚠ This token never expires; revoke it with 'cvps token revoke %s' when no longer needed.", token.ID)
+	}
+	fmt.Printf("\nToken: %s\n", token.Token)
+	color.Yellow("Store it now; it will not be shown again. Use it by setting CVPS_API_KEY.")
+	return nil
+}
+
+func runTokenList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := client.ListTokens(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	if tokenJSON {
+		if tokens == nil {
+			tokens = []claudevps.APIToken{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tokens)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("No API tokens. Run 'cvps token create --scope <scope>' to create one.")
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED")
+	for _, t := range tokens {
+		lastUsed := "never"
+		if t.LastUsedAt != "" {
+			lastUsed = formatTime(t.LastUsedAt)
+		}
+		scopes := strings.Join(t.Scopes, ",")
+		if t.Sandbox != "" {
+			scopes += " on " + t.Sandbox
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Name, t.Prefix, scopes, tokenExpiry(t, now), lastUsed)
+	}
+	w.Flush()
+	return nil
+}
+
+// tokenExpiry formats when a token expires, flagging expired ones
+func tokenExpiry(t claudevps.APIToken, now time.Time) string {
+	return formatExpiry(t.ExpiresAt, now)
+}
+
+// formatExpiry formats an expiry time, where empty means never, flagging
+// times that have passed
+func formatExpiry(expiresAt string, now time.Time) string {
+	if expiresAt == "" {
+		return "never"
+	}
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return expiresAt
+	}
+	if !expires.After(now) {
+		return color.RedString("expired")
+	}
+	return formatTime(expiresAt)
+}
+
+func runTokenRevoke(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	if !tokenForce {
+		fmt.Printf("Revoke token %s? Anything using it stops working. [y/N]: ", id)
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := client.RevokeToken(context.Background(), id); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("token not found: %s", id)
+		}
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	fmt.Printf("✓ Token %s revoked\n", id)
+	return nil
+}
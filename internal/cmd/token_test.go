@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"12h":   12 * time.Hour,
+		"never": 0,
+	}
+	for in, want := range tests {
+		got, err := parseExpiry(in)
+		if err != nil || got != want {
+			t.Errorf("parseExpiry(%q) = %s, %v, want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"30m", "xd", "soon", "0d"} {
+		if _, err := parseExpiry(in); err == nil {
+			t.Errorf("parseExpiry(%q) expected error", in)
+		}
+	}
+}
+
+func TestNormalizeTokenScopes(t *testing.T) {
+	got, err := normalizeTokenScopes([]string{"sandboxes:read", " Secrets:Write ", "sandboxes:read"})
+	if err != nil {
+		t.Fatalf("normalizeTokenScopes() error = %v", err)
+	}
+	if want := []string{"sandboxes:read", "secrets:write"}; !slices.Equal(got, want) {
+		t.Errorf("normalizeTokenScopes() = %v, want %v", got, want)
+	}
+	if _, err := normalizeTokenScopes([]string{"sandboxes:admin"}); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
+
+func TestRunTokenCreate(t *testing.T) {
+	var req claudevps.CreateTokenRequest
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/tokens" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.APIToken{
+			ID: "tok-1", Scopes: req.Scopes, ExpiresAt: "2024-04-28T00:00:00Z", Token: "cvps_tok_secret",
+		})
+	}))
+
+	tokenScopes, tokenExpires = []string{"sandboxes:read"}, "30d"
+	defer func() { tokenScopes, tokenExpires = nil, "90d" }()
+
+	out, err := captureStdout(t, func() error { return runTokenCreate(tokenCreateCmd, nil) })
+	if err != nil {
+		t.Fatalf("runTokenCreate() error = %v", err)
+	}
+	if req.ExpiresInSeconds != 30*24*3600 || !slices.Equal(req.Scopes, []string{"sandboxes:read"}) {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	if !strings.Contains(out, "cvps_tok_secret") {
+		t.Errorf("Expected token in output:\n%s", out)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.UTC)
+	if got := tokenExpiry(claudevps.APIToken{}, now); got != "never" {
+		t.Errorf("tokenExpiry(no expiry) = %q", got)
+	}
+	if got := tokenExpiry(claudevps.APIToken{ExpiresAt: "2024-03-01T00:00:00Z"}, now); got != "expired" {
+		t.Errorf("tokenExpiry(past) = %q, want expired", got)
+	}
+}
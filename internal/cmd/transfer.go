@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// File transfer transports
+const (
+	transportAuto = "auto"
+	transportSSH  = "ssh"
+	transportAPI  = "api"
+)
+
+// apiStore implements remoteStore over the HTTPS files API, for networks that
+// block outbound SSH
+type apiStore struct {
+	ctx       context.Context
+	client    *claudevps.Client
+	sandboxID string
+}
+
+// apiFileInfo adapts claudevps.RemoteFile to os.FileInfo
+type apiFileInfo struct {
+	file claudevps.RemoteFile
+}
+
+func (fi apiFileInfo) Name() string { return path.Base(fi.file.Path) }
+func (fi apiFileInfo) Size() int64  { return fi.file.Size }
+func (fi apiFileInfo) IsDir() bool  { return fi.file.IsDir }
+func (fi apiFileInfo) Sys() any     { return nil }
+
+func (fi apiFileInfo) Mode() os.FileMode {
+	mode := os.FileMode(fi.file.Mode & 0777)
+	if fi.file.IsDir {
+		mode |= os.ModeDir
+	}
+	return mode
+}
+
+func (fi apiFileInfo) ModTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, fi.file.ModTime)
+	return t
+}
+
+func (s *apiStore) Stat(p string) (os.FileInfo, error) {
+	file, err := s.client.StatFile(s.ctx, s.sandboxID, p)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+		}
+		return nil, err
+	}
+	return apiFileInfo{*file}, nil
+}
+
+func (s *apiStore) ReadDir(p string) ([]os.FileInfo, error) {
+	files, err := s.client.ListFiles(s.ctx, s.sandboxID, p)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(files))
+	for _, f := range files {
+		infos = append(infos, apiFileInfo{f})
+	}
+	return infos, nil
+}
+
+func (s *apiStore) MkdirAll(p string) error {
+	return s.client.MakeDir(s.ctx, s.sandboxID, p)
+}
+
+func (s *apiStore) Remove(p string) error {
+	err := s.client.DeleteFile(s.ctx, s.sandboxID, p)
+	if claudevps.IsNotFound(err) {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	return err
+}
+
+// Resolve leaves relative paths for the server to resolve against the home directory
+func (s *apiStore) Resolve(p string) (string, error) {
+	p = trimHomePrefix(p)
+	if path.IsAbs(p) {
+		return path.Clean(p), nil
+	}
+	return p, nil
+}
+
+func (s *apiStore) Upload(localPath, remotePath string, info os.FileInfo) (int64, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := s.client.UploadFile(s.ctx, s.sandboxID, remotePath, f, info.Size(), info.Mode(), nil); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// partialSuffix marks a download cvps left unfinished, the only kind of local
+// file it will resume into
+const partialSuffix = ".cvps-partial"
+
+// Download writes to localPath+partialSuffix, resuming from an earlier
+// interrupted attempt, and renames it into place once complete
+func (s *apiStore) Download(remotePath, localPath string, info os.FileInfo) (int64, error) {
+	partial := localPath + partialSuffix
+	f, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	if local, err := f.Stat(); err == nil && local.Size() <= info.Size() {
+		offset = local.Size()
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return 0, err
+	}
+
+	err = s.client.DownloadFile(s.ctx, s.sandboxID, remotePath, f, offset, info.Size(), nil)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(partial, localPath); err != nil {
+		return 0, err
+	}
+	return info.Size() - offset, nil
+}
+
+func (s *apiStore) Close() error {
+	return nil
+}
+
+// validTransport checks a --transport flag value
+func validTransport(t string) error {
+	switch t {
+	case transportAuto, transportSSH, transportAPI:
+		return nil
+	default:
+		return fmt.Errorf("invalid transport %q (use auto, ssh or api)", t)
+	}
+}
+
+// openRemoteStore connects to a sandbox's files using the requested transport.
+// In auto mode SSH is preferred, falling back to HTTPS when it cannot connect.
+func openRemoteStore(ctx context.Context, client *claudevps.Client, sandboxID, transport string) (remoteStore, error) {
+	sandbox, err := client.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return nil, &sandboxNotFoundError{Ref: sandboxID}
+		}
+		return nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	if !isRunningStatus(sandbox.Status) {
+		return nil, fmt.Errorf("sandbox is not running (status: %s)", sandbox.Status)
+	}
+
+	if transport == transportAPI {
+		return &apiStore{ctx: ctx, client: client, sandboxID: sandbox.ID}, nil
+	}
+
+	fs, err := newSandboxFS(ctx, sandbox)
+	if err == nil {
+		return fs, nil
+	}
+	if transport == transportSSH {
+		return nil, err
+	}
+
+	color.Yellow("SSH unavailable (%v); using HTTPS file transfer", err)
+	return &apiStore{ctx: ctx, client: client, sandboxID: sandbox.ID}, nil
+}
+
+// apiUploader uploads migration files through the files API, creating remote
+// directories as needed
+type apiUploader struct {
+	client    *claudevps.Client
+	sandboxID string
+	dirs      map[string]bool
+}
+
+func (u *apiUploader) UploadFile(ctx context.Context, localPath, remotePath string, mode os.FileMode, size int64, onProgress func(int64)) error {
+	if dir := path.Dir(remotePath); !u.dirs[dir] {
+		if err := u.client.MakeDir(ctx, u.sandboxID, dir); err != nil {
+			return err
+		}
+		u.dirs[dir] = true
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return u.client.UploadFile(ctx, u.sandboxID, remotePath, f, size, mode, onProgress)
+}
+
+// useAPITransport decides whether a transfer should go over HTTPS. In auto mode
+// it probes the SSH endpoint first.
+func useAPITransport(ctx context.Context, transport string, sandbox *claudevps.Sandbox) bool {
+	switch transport {
+	case transportAPI:
+		return true
+	case transportSSH:
+		return false
+	}
+
+	if sandbox.SSHHost == "" || sandbox.Connectivity.SSHProxyRequired {
+		return true
+	}
+	return !probeSSH(ctx, sandbox.SSHHost, sandbox.SSHPort, probeTimeout).Reachable
+}
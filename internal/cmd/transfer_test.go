@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestAPIFileInfo(t *testing.T) {
+	fi := apiFileInfo{claudevps.RemoteFile{
+		Path:    "/workspace/src",
+		Mode:    0755,
+		ModTime: "2024-01-15T10:30:00Z",
+		IsDir:   true,
+	}}
+
+	if fi.Name() != "src" {
+		t.Errorf("Name() = %q, want src", fi.Name())
+	}
+	if fi.Mode() != os.ModeDir|0755 {
+		t.Errorf("Mode() = %v, want drwxr-xr-x", fi.Mode())
+	}
+	if !fi.ModTime().Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("ModTime() = %v", fi.ModTime())
+	}
+}
+
+func TestValidTransport(t *testing.T) {
+	for _, v := range []string{"auto", "ssh", "api"} {
+		if err := validTransport(v); err != nil {
+			t.Errorf("validTransport(%q) error = %v", v, err)
+		}
+	}
+	if err := validTransport("ftp"); err == nil {
+		t.Error("validTransport(ftp) expected error")
+	}
+}
+
+func TestUseAPITransport(t *testing.T) {
+	sandbox := &claudevps.Sandbox{}
+	if !useAPITransport(context.Background(), transportAuto, sandbox) {
+		t.Error("expected API transport when the sandbox has no SSH endpoint")
+	}
+	if useAPITransport(context.Background(), transportSSH, sandbox) {
+		t.Error("expected --transport ssh to be honoured")
+	}
+
+	sandbox.SSHHost = "sandbox.example.com"
+	sandbox.Connectivity.SSHProxyRequired = true
+	if !useAPITransport(context.Background(), transportAuto, sandbox) {
+		t.Error("expected API transport when an SSH proxy is required")
+	}
+}
+
+// newContentServer serves content for any files API range request
+func newContentServer(t *testing.T, content []byte) (*apiStore, *[]string) {
+	t.Helper()
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		var start, end int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		end = min(end, len(content)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start : end+1])
+	}))
+	t.Cleanup(server.Close)
+
+	client := claudevps.NewClient(server.URL, "test-key")
+	return &apiStore{ctx: context.Background(), client: client, sandboxID: "sbx-1"}, &ranges
+}
+
+func TestAPIStoreDownload_OverwritesShorterFile(t *testing.T) {
+	store, _ := newContentServer(t, []byte("new contents"))
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := apiFileInfo{claudevps.RemoteFile{Path: "/a.txt", Size: 12, Mode: 0644}}
+	n, err := store.Download("/a.txt", local, info)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if n != 12 {
+		t.Errorf("Download() = %d bytes, want 12", n)
+	}
+	got, _ := os.ReadFile(local)
+	if string(got) != "new contents" {
+		t.Errorf("local file = %q, want %q", got, "new contents")
+	}
+	if _, err := os.Stat(local + partialSuffix); !os.IsNotExist(err) {
+		t.Error("expected the partial file to be renamed into place")
+	}
+}
+
+func TestAPIStoreDownload_ResumesPartialFile(t *testing.T) {
+	store, ranges := newContentServer(t, []byte("new contents"))
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local+partialSuffix, []byte("new "), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := apiFileInfo{claudevps.RemoteFile{Path: "/a.txt", Size: 12, Mode: 0644}}
+	n, err := store.Download("/a.txt", local, info)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if n != 8 {
+		t.Errorf("Download() = %d bytes, want 8", n)
+	}
+	if len(*ranges) != 1 || (*ranges)[0] != "bytes=4-11" {
+		t.Errorf("ranges = %v, want [bytes=4-11]", *ranges)
+	}
+	got, _ := os.ReadFile(local)
+	if string(got) != "new contents" {
+		t.Errorf("local file = %q, want %q", got, "new contents")
+	}
+}
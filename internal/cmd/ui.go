@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/daemon"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var uiRefreshInterval time.Duration
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open an interactive dashboard of your sandboxes",
+	Long: `Open a full-screen dashboard listing your sandboxes with live status.
+
+The lower pane shows details and current resource usage of the selected
+sandbox. Keys:
+
+  ↑/↓, j/k   select a sandbox
+  enter, c   connect (returns to the dashboard when the session ends)
+  l          show recent system logs
+  s          start a stopped sandbox or stop a running one
+  d          delete (moves the sandbox to the trash)
+  r          refresh now
+  q          quit`,
+	Args: cobra.NoArgs,
+	RunE: runUI,
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().DurationVar(&uiRefreshInterval, "interval", 5*time.Second, "how often to refresh the sandbox list")
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("cvps ui needs an interactive terminal. Use 'cvps status --all' in scripts")
+	}
+	if uiRefreshInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &uiSession{
+		cfg:     cfg,
+		client:  newClientFromConfig(cfg),
+		model:   newUIModel(),
+		keys:    make(chan string, 16),
+		updates: make(chan func(*uiModel), 16),
+	}
+	return s.run(ctx)
+}
+
+// uiSession owns the terminal while the dashboard runs
+type uiSession struct {
+	cfg    *config.Config
+	client *claudevps.Client
+	model  *uiModel
+
+	keys    chan string
+	updates chan func(*uiModel)
+
+	// inputMu is held while reading stdin; paused stops reading so a shell
+	// session can have the terminal
+	inputMu sync.Mutex
+	paused  atomic.Bool
+	raw     *term.State
+}
+
+func (s *uiSession) run(ctx context.Context) error {
+	if err := s.enterScreen(); err != nil {
+		return err
+	}
+	defer s.leaveScreen()
+
+	go s.readKeys(ctx)
+	s.showDaemonList(ctx)
+	s.refresh(ctx)
+
+	ticker := time.NewTicker(uiRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s.render()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.refresh(ctx)
+		case update := <-s.updates:
+			update(s.model)
+		case key := <-s.keys:
+			s.model.message = ""
+			switch s.model.handleKey(key) {
+			case uiQuit:
+				return nil
+			case uiRefresh:
+				s.refresh(ctx)
+			case uiSelect:
+				s.fetchMetrics(ctx)
+			case uiFetchLogs:
+				s.fetchLogs(ctx)
+			case uiStartStop:
+				s.startStop(ctx)
+			case uiDelete:
+				s.delete(ctx)
+			case uiConnect:
+				s.connect(ctx)
+			}
+		}
+	}
+}
+
+func (s *uiSession) enterScreen() error {
+	raw, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	s.raw = raw
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	return nil
+}
+
+func (s *uiSession) leaveScreen() {
+	fmt.Print("\x1b[?25h\x1b[?1049l")
+	if s.raw != nil {
+		term.Restore(int(os.Stdin.Fd()), s.raw)
+		s.raw = nil
+	}
+}
+
+func (s *uiSession) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range s.model.view(width, height) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	os.Stdout.WriteString(b.String())
+}
+
+// readKeys forwards key presses until ctx is done. It polls so that it can
+// let go of stdin while a shell session is running.
+func (s *uiSession) readKeys(ctx context.Context) {
+	fd := int(os.Stdin.Fd())
+	buf := make([]byte, 64)
+	for ctx.Err() == nil {
+		s.inputMu.Lock()
+		if s.paused.Load() {
+			s.inputMu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		var keys []string
+		ready, err := inputReady(fd, 100*time.Millisecond)
+		if err == nil && ready {
+			n, _ := os.Stdin.Read(buf)
+			keys = parseKeys(buf[:n])
+		}
+		s.inputMu.Unlock()
+
+		for _, k := range keys {
+			select {
+			case s.keys <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// async runs fn in the background and applies its result in the event loop
+func (s *uiSession) async(ctx context.Context, fn func() func(*uiModel)) {
+	go func() {
+		update := fn()
+		select {
+		case s.updates <- update:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// showDaemonList shows the sandbox list cached by 'cvps daemon', if one is
+// running, until the first refresh replaces it
+func (s *uiSession) showDaemonList(ctx context.Context) {
+	c, err := daemon.Connect(ctx)
+	if err != nil {
+		return
+	}
+	list, err := c.Sandboxes(ctx, false)
+	if err != nil {
+		debuglog.Printf("ui: %v", err)
+		return
+	}
+	s.model.setSandboxes(list.Sandboxes)
+}
+
+func (s *uiSession) refresh(ctx context.Context) {
+	s.async(ctx, func() func(*uiModel) {
+		sandboxes, err := listAllSandboxesForConnect(ctx, s.client)
+		return func(m *uiModel) {
+			if err != nil {
+				m.loadErr = fmt.Errorf("failed to list sandboxes: %w", err)
+				return
+			}
+			m.setSandboxes(sandboxes)
+			s.fetchMetrics(ctx)
+		}
+	})
+}
+
+func (s *uiSession) fetchMetrics(ctx context.Context) {
+	sb := s.model.current()
+	if sb == nil || !isRunningStatus(sb.Status) {
+		return
+	}
+	id := sb.ID
+	s.async(ctx, func() func(*uiModel) {
+		metrics, err := s.client.GetSandboxMetrics(ctx, id)
+		return func(m *uiModel) {
+			if err == nil {
+				m.metrics[id] = metrics
+			}
+		}
+	})
+}
+
+func (s *uiSession) fetchLogs(ctx context.Context) {
+	id := s.model.current().ID
+	s.async(ctx, func() func(*uiModel) {
+		entries, err := s.client.GetSandboxLogs(ctx, id, 200)
+		return func(m *uiModel) {
+			if err != nil {
+				m.mode = uiDetails
+				m.message = fmt.Sprintf("✗ Failed to get logs: %v", err)
+				return
+			}
+			if m.mode == uiLogs && m.current() != nil && m.current().ID == id {
+				if entries == nil {
+					entries = []claudevps.LogEntry{}
+				}
+				m.logs = entries
+			}
+		}
+	})
+}
+
+func (s *uiSession) startStop(ctx context.Context) {
+	sb := *s.model.current()
+	stop := isRunningStatus(sb.Status)
+	if stop {
+		s.model.message = fmt.Sprintf("Stopping %s...", sb.Name)
+	} else {
+		s.model.message = fmt.Sprintf("Starting %s...", sb.Name)
+	}
+
+	s.async(ctx, func() func(*uiModel) {
+		var err error
+		if stop {
+			_, err = s.client.StopSandbox(ctx, sb.ID)
+		} else {
+			_, err = s.client.StartSandbox(ctx, sb.ID)
+		}
+		return func(m *uiModel) {
+			switch {
+			case isConflict(err, claudevps.CodeAlreadyRunning, claudevps.CodeAlreadyStopped):
+				state := "running"
+				if stop {
+					state = "stopped"
+				}
+				m.message = fmt.Sprintf("%s was already %s", sb.Name, state)
+			case isConflict(err, claudevps.CodeTransitioning):
+				m.message = fmt.Sprintf("%s is already starting or stopping", sb.Name)
+			case err != nil:
+				m.message = fmt.Sprintf("✗ %v", err)
+				return
+			}
+			s.refresh(ctx)
+		}
+	})
+}
+
+func (s *uiSession) delete(ctx context.Context) {
+	sb := *s.model.current()
+	s.model.message = fmt.Sprintf("Deleting %s...", sb.Name)
+
+	s.async(ctx, func() func(*uiModel) {
+		err := s.client.DeleteSandbox(ctx, sb.ID)
+		return func(m *uiModel) {
+			if err != nil && !deleteRaced(err) {
+				m.message = fmt.Sprintf("✗ Failed to delete %s: %v", sb.Name, err)
+				return
+			}
+			cleanupLocalContext(sb.ID)
+			m.message = fmt.Sprintf("✓ %s moved to the trash. Restore with: cvps restore %s", sb.Name, sb.ID)
+			s.refresh(ctx)
+		}
+	})
+}
+
+// connect hands the terminal to a shell session and takes it back afterwards
+func (s *uiSession) connect(ctx context.Context) {
+	sb := *s.model.current()
+	if !isRunningStatus(sb.Status) {
+		s.model.message = fmt.Sprintf("%s is not running (status: %s). Press s to start it.", sb.Name, sb.Status)
+		return
+	}
+
+	s.paused.Store(true)
+	s.inputMu.Lock()
+	s.leaveScreen()
+
+	err := openSandboxSession(ctx, s.cfg, s.client, &sb, true)
+
+	s.inputMu.Unlock()
+	if enterErr := s.enterScreen(); enterErr != nil {
+		s.model.message = fmt.Sprintf("✗ %v", enterErr)
+	}
+	s.paused.Store(false)
+
+	if err != nil {
+		s.model.message = fmt.Sprintf("✗ %v", err)
+	} else {
+		s.model.message = fmt.Sprintf("Disconnected from %s", sb.Name)
+	}
+	s.refresh(ctx)
+}
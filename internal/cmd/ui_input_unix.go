@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// inputReady waits up to timeout for fd to become readable
+func inputReady(fd int, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	if err == unix.EINTR {
+		return false, nil
+	}
+	return n > 0, err
+}
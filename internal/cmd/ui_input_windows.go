@@ -0,0 +1,18 @@
+//go:build windows
+
+package cmd
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// inputReady waits up to timeout for console input on fd
+func inputReady(fd int, timeout time.Duration) (bool, error) {
+	event, err := windows.WaitForSingleObject(windows.Handle(fd), uint32(timeout.Milliseconds()))
+	if err != nil {
+		return false, err
+	}
+	return event == windows.WAIT_OBJECT_0, nil
+}
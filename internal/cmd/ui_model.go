@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// uiMode is what the lower pane of the dashboard shows
+type uiMode int
+
+const (
+	uiDetails uiMode = iota
+	uiLogs
+	uiConfirmDelete
+)
+
+// uiAction is work the event loop has to do in response to a key
+type uiAction int
+
+const (
+	uiNone uiAction = iota
+	uiQuit
+	uiRefresh
+	uiConnect
+	uiFetchLogs
+	uiStartStop
+	uiDelete
+	uiSelect
+)
+
+// uiModel is the dashboard state. It is only touched from the event loop.
+type uiModel struct {
+	sandboxes []claudevps.Sandbox
+	selected  int
+	metrics   map[string]*claudevps.SandboxMetrics
+	logs      []claudevps.LogEntry
+	mode      uiMode
+	message   string
+	updated   time.Time
+	loadErr   error
+}
+
+func newUIModel() *uiModel {
+	return &uiModel{metrics: make(map[string]*claudevps.SandboxMetrics)}
+}
+
+// current returns the selected sandbox, or nil when the list is empty
+func (m *uiModel) current() *claudevps.Sandbox {
+	if m.selected < 0 || m.selected >= len(m.sandboxes) {
+		return nil
+	}
+	return &m.sandboxes[m.selected]
+}
+
+// setSandboxes replaces the list, keeping the same sandbox selected if it still exists
+func (m *uiModel) setSandboxes(sandboxes []claudevps.Sandbox) {
+	var selectedID string
+	if s := m.current(); s != nil {
+		selectedID = s.ID
+	}
+
+	m.sandboxes = sandboxes
+	m.selected = 0
+	for i, s := range sandboxes {
+		if s.ID == selectedID {
+			m.selected = i
+		}
+	}
+	m.updated = time.Now()
+	m.loadErr = nil
+}
+
+// handleKey updates the model for a key press and returns the follow-up work
+func (m *uiModel) handleKey(key string) uiAction {
+	if key == "ctrl+c" {
+		return uiQuit
+	}
+
+	switch m.mode {
+	case uiConfirmDelete:
+		m.mode = uiDetails
+		if key == "y" || key == "Y" {
+			return uiDelete
+		}
+		m.message = "Delete cancelled"
+		return uiNone
+	case uiLogs:
+		switch key {
+		case "esc", "l", "enter":
+			m.mode = uiDetails
+			return uiNone
+		}
+	}
+
+	switch key {
+	case "q":
+		return uiQuit
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+			m.mode = uiDetails
+			return uiSelect
+		}
+	case "down", "j":
+		if m.selected < len(m.sandboxes)-1 {
+			m.selected++
+			m.mode = uiDetails
+			return uiSelect
+		}
+	case "r":
+		return uiRefresh
+	case "enter", "c":
+		if m.current() != nil {
+			return uiConnect
+		}
+	case "l":
+		if m.current() != nil {
+			m.mode = uiLogs
+			m.logs = nil
+			return uiFetchLogs
+		}
+	case "s":
+		if m.current() != nil {
+			return uiStartStop
+		}
+	case "d":
+		if m.current() != nil {
+			m.mode = uiConfirmDelete
+		}
+	}
+	return uiNone
+}
+
+// parseKeys turns raw terminal input into key names
+func parseKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case c == 0x1b:
+			keys = append(keys, "esc")
+		case c == 0x03:
+			keys = append(keys, "ctrl+c")
+		case c == '\r' || c == '\n':
+			keys = append(keys, "enter")
+		case c >= 0x20 && c < 0x7f:
+			keys = append(keys, string(c))
+		}
+	}
+	return keys
+}
+
+const uiHelp = "↑/↓ select  enter connect  l logs  s start/stop  d delete  r refresh  q quit"
+
+// view renders the dashboard as lines no wider than width
+func (m *uiModel) view(width, height int) []string {
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, truncate(fmt.Sprintf(format, args...), width))
+	}
+
+	title := fmt.Sprintf(" cvps ui · %d sandboxes", len(m.sandboxes))
+	if !m.updated.IsZero() {
+		title += " · updated " + m.updated.Format("15:04:05")
+	}
+	lines = append(lines, "\x1b[7m"+pad(title, width)+"\x1b[0m")
+	if m.loadErr != nil {
+		add(" ⚠ %v", m.loadErr)
+	}
+
+	// Split the screen between the list and the lower pane
+	lower := m.lowerPane()
+	listHeight := height - len(lines) - len(lower) - 3
+	if listHeight < 3 {
+		listHeight = 3
+	}
+
+	nameWidth := 4
+	for _, s := range m.sandboxes {
+		nameWidth = max(nameWidth, min(len(s.Name), 30))
+	}
+	row := func(name, status, cpu, mem, id string) string {
+		return fmt.Sprintf("%-*s  %-12s  %-3s  %-6s  %s", nameWidth, truncate(name, nameWidth), status, cpu, mem, id)
+	}
+
+	add("  %s", row("NAME", "STATUS", "CPU", "MEMORY", "ID"))
+	start := 0
+	if m.selected >= listHeight {
+		start = m.selected - listHeight + 1
+	}
+	for i := start; i < len(m.sandboxes) && i < start+listHeight; i++ {
+		s := m.sandboxes[i]
+		text := row(s.Name, s.Status, fmt.Sprint(s.CPUCores), fmt.Sprintf("%dGB", s.MemoryGB), s.ID)
+		if i == m.selected {
+			lines = append(lines, "\x1b[7m"+pad("> "+text, width)+"\x1b[0m")
+		} else {
+			add("  %s", text)
+		}
+	}
+	if len(m.sandboxes) == 0 {
+		add("  No sandboxes. Run 'cvps up' to create one.")
+	}
+	for len(lines) < height-len(lower)-2 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, strings.Repeat("─", width))
+	for _, l := range lower {
+		add("%s", l)
+	}
+
+	footer := uiHelp
+	if m.message != "" {
+		footer = m.message
+	}
+	if m.mode == uiConfirmDelete && m.current() != nil {
+		footer = fmt.Sprintf("Delete sandbox '%s'? It moves to the trash. [y/N]", m.current().Name)
+	}
+	add(" %s", footer)
+	return lines
+}
+
+// lowerPane renders sandbox details and metrics, or recent logs
+func (m *uiModel) lowerPane() []string {
+	s := m.current()
+	if s == nil {
+		return nil
+	}
+
+	if m.mode == uiLogs {
+		out := []string{fmt.Sprintf(" Logs for %s (esc to close)", s.Name)}
+		if summary := failureSummary(s); summary != "" && isFailedStatus(s.Status) {
+			out = append(out, "  Failed: "+summary)
+		}
+		if m.logs == nil {
+			return append(out, "  Loading...")
+		}
+		if len(m.logs) == 0 {
+			return append(out, "  No log entries")
+		}
+		for _, e := range m.logs {
+			out = append(out, fmt.Sprintf("  %s %-6s %s", formatTime(e.Time), e.Source, e.Message))
+		}
+		return out
+	}
+
+	out := []string{
+		fmt.Sprintf(" %s (%s) · %s", s.Name, s.ID, s.Status),
+		fmt.Sprintf("  Resources: %d CPU, %d GB RAM, %d GB disk", s.CPUCores, s.MemoryGB, s.StorageGB),
+		fmt.Sprintf("  Created:   %s", formatTime(s.CreatedAt)),
+	}
+	if summary := failureSummary(s); summary != "" && isFailedStatus(s.Status) {
+		out = append(out, fmt.Sprintf("  Failed:    %s", summary))
+	}
+	if s.SSHHost != "" {
+		out = append(out, fmt.Sprintf("  SSH:       ssh %s@%s -p %d", s.SSHUser, s.SSHHost, s.SSHPort))
+	}
+	if mt, ok := m.metrics[s.ID]; ok {
+		out = append(out,
+			fmt.Sprintf("  CPU:       %.1f%%", mt.CPUPercent),
+			fmt.Sprintf("  Memory:    %s / %s", formatBytes(mt.MemoryUsedBytes), formatBytes(mt.MemoryTotalBytes)),
+			fmt.Sprintf("  Disk:      %s / %s", formatBytes(mt.DiskUsedBytes), formatBytes(mt.DiskTotalBytes)),
+			fmt.Sprintf("  Cost:      %s to date", formatMoney(mt.CostToDate, mt.Currency)),
+		)
+	}
+	return out
+}
+
+// truncate cuts s to at most width runes
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width <= 0 || len(r) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// pad truncates or space-fills s to exactly width runes
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	if n := len([]rune(s)); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1b[B\r\x1bq\x03"))
+	want := []string{"j", "up", "down", "enter", "esc", "q", "ctrl+c"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("parseKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestUIModelHandleKey(t *testing.T) {
+	m := newUIModel()
+	m.setSandboxes([]claudevps.Sandbox{
+		{ID: "sbx-1", Name: "web", Status: "running"},
+		{ID: "sbx-2", Name: "worker", Status: "stopped"},
+	})
+
+	if got := m.handleKey("down"); got != uiSelect || m.current().ID != "sbx-2" {
+		t.Errorf("down: action %v, selected %s", got, m.current().ID)
+	}
+	if got := m.handleKey("down"); got != uiNone {
+		t.Errorf("down at the end: action %v, want none", got)
+	}
+
+	// A refresh keeps the selection on the same sandbox
+	m.setSandboxes([]claudevps.Sandbox{
+		{ID: "sbx-0", Name: "new", Status: "running"},
+		{ID: "sbx-1", Name: "web", Status: "running"},
+		{ID: "sbx-2", Name: "worker", Status: "stopped"},
+	})
+	if m.current().ID != "sbx-2" {
+		t.Errorf("Expected selection to follow sbx-2, got %s", m.current().ID)
+	}
+
+	if got := m.handleKey("d"); got != uiNone || m.mode != uiConfirmDelete {
+		t.Fatalf("d: action %v, mode %v", got, m.mode)
+	}
+	if got := m.handleKey("n"); got != uiNone || m.mode != uiDetails {
+		t.Errorf("Expected delete to be cancelled, got action %v", got)
+	}
+	m.handleKey("d")
+	if got := m.handleKey("y"); got != uiDelete {
+		t.Errorf("Expected delete after confirmation, got %v", got)
+	}
+
+	if got := m.handleKey("l"); got != uiFetchLogs || m.mode != uiLogs {
+		t.Errorf("l: action %v, mode %v", got, m.mode)
+	}
+	if m.handleKey("esc"); m.mode != uiDetails {
+		t.Error("Expected esc to close the logs")
+	}
+
+	for key, want := range map[string]uiAction{"enter": uiConnect, "s": uiStartStop, "r": uiRefresh, "q": uiQuit, "ctrl+c": uiQuit} {
+		if got := m.handleKey(key); got != want {
+			t.Errorf("handleKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestUIModelView(t *testing.T) {
+	m := newUIModel()
+	m.setSandboxes([]claudevps.Sandbox{
+		{ID: "sbx-1", Name: "web", Status: "running", CPUCores: 2, MemoryGB: 4, StorageGB: 20},
+	})
+	m.metrics["sbx-1"] = &claudevps.SandboxMetrics{CPUPercent: 12.5, MemoryUsedBytes: 1 << 30, MemoryTotalBytes: 4 << 30, CostToDate: 1.5}
+
+	lines := m.view(80, 24)
+	if len(lines) > 24 {
+		t.Errorf("view has %d lines, want at most 24", len(lines))
+	}
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{"1 sandboxes", "> web", "CPU:       12.5%", "$1.50 to date", uiHelp} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("view missing %q:\n%s", want, screen)
+		}
+	}
+
+	m.mode = uiLogs
+	m.logs = []claudevps.LogEntry{{Time: "2024-01-15T10:00:00Z", Source: "sshd", Message: "accepted key"}}
+	if screen := strings.Join(m.view(80, 24), "\n"); !strings.Contains(screen, "accepted key") {
+		t.Errorf("logs view missing entry:\n%s", screen)
+	}
+}
+
+func TestTruncatePad(t *testing.T) {
+	if got := truncate("sandbox", 4); got != "san…" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := pad("ab", 4); got != "ab  " {
+		t.Errorf("pad() = %q", got)
+	}
+}
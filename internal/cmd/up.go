@@ -1,16 +1,26 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 	"time"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/internal/project"
+	"github.com/achronon/cvps/pkg/claudevps"
 	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
+	"golang.org/x/term"
 )
 
 var (
@@ -18,7 +28,24 @@ var (
 	upCPU     int
 	upMemory  int
 	upStorage int
+	upImage   string
+	upRegion  string
+	upArch    string
 	upDetach  bool
+	upLabels  []string
+	upDesc    string
+
+	upFromSnapshot string
+	upCloneOf      string
+
+	upRepo    string
+	upBranch  string
+	upRepoKey string
+
+	upNoDotfiles bool
+	upOutput     string
+	upCount      int
+	upBootstrap  bool
 )
 
 var upCmd = &cobra.Command{
@@ -27,15 +54,72 @@ var upCmd = &cobra.Command{
 	Long: `Provision a new remote sandbox instance on claudevps.com.
 
 The sandbox will be created with the specified resources and become
-available for connections once provisioning completes.`,
-	Example: `  # Create sandbox with defaults
+available for connections once provisioning completes.
+
+With --repo, a git repository is cloned into /workspace while provisioning.
+Private ssh repositories need a deploy key (--repo-key, which may be encrypted
+with 'cvps secrets encrypt'); for https, set CVPS_REPO_TOKEN to an access
+token. Credentials go to the API with the request and are only used for the
+clone.
+
+When stdout is not a terminal, or with --output json, progress goes to stderr
+and stdout gets only a JSON object describing the sandbox (id, name, status
+and SSH endpoint), so scripts can parse it.
+
+With --bootstrap, the sandbox is set up from the bootstrap section of
+cvps.project.yaml in the current directory. Its settings are used where no
+flag is given, then its setup script runs in /workspace, the directory is
+uploaded to /workspace, file sync is started and its ports are forwarded.
+'cvps export' writes this section for an existing sandbox.
+
+  bootstrap:
+    name: shop
+    cpu_cores: 4
+    image: node:20
+    labels: {team: web}
+    env: [NPM_TOKEN]   # secrets expected with --mount env
+    ports: ["3000"]
+    setup: |
+      npm install -g pnpm
+    exclude: [node_modules]
+    migrate: true   # upload the directory (default true)
+    sync: true      # start file sync (default true)`,
+	Example: `  # Create sandbox interactively (on a terminal with no flags)
   cvps up
 
   # Create named sandbox with custom resources
   cvps up --name my-project --cpu 4 --memory 8 --storage 50
 
+  # Start from a snapshot, or from a copy of another sandbox's disk
+  cvps up --from-snapshot snap-abc123
+  cvps up --name feature-x --clone-of my-project
+
+  # Start with a repository checked out in /workspace
+  cvps up --repo git@github.com:acme/app.git --branch main --repo-key ./deploy_key
+
+  # Run on ARM, e.g. to build arm64 binaries natively
+  cvps up --name builder --arch arm64
+
+  # Note why the sandbox exists
+  cvps up --name repro-123 --description "staging repro for bug #123"
+
+  # Label a sandbox so it can be targeted with 'cvps exec --selector'
+  cvps up --name student-01 --label class=intro --label seat=1
+
+  # Show the request after config defaults and check it against account limits
+  cvps up --cpu 8 --dry-run
+
   # Create and return immediately without waiting
-  cvps up --detach`,
+  cvps up --detach
+
+  # Create a fleet of identical sandboxes, student-1 to student-20
+  cvps up --name student --count 20
+
+  # Set up a project's sandbox from its cvps.project.yaml
+  cvps up --bootstrap
+
+  # Create in a script and capture the ID
+  SANDBOX=$(cvps up --output json | jq -r .id)`,
 	RunE: runUp,
 }
 
@@ -46,104 +130,600 @@ func init() {
 	upCmd.Flags().IntVar(&upCPU, "cpu", 0, "CPU cores (default from config)")
 	upCmd.Flags().IntVar(&upMemory, "memory", 0, "memory in GB (default from config)")
 	upCmd.Flags().IntVar(&upStorage, "storage", 0, "storage in GB (default from config)")
+	upCmd.Flags().StringVar(&upImage, "image", "", "container image or a custom image from 'cvps image build' (default from config)")
+	upCmd.Flags().StringVar(&upRegion, "region", "", "region to create the sandbox in (default: nearest)")
+	upCmd.Flags().StringVar(&upArch, "arch", "", "CPU architecture: amd64 or arm64 (default: amd64)")
 	upCmd.Flags().BoolVarP(&upDetach, "detach", "d", false, "return immediately without waiting")
+	upCmd.Flags().StringVar(&upDesc, "description", "", "note on what the sandbox is for, shown by 'cvps status'")
+	upCmd.Flags().StringArrayVarP(&upLabels, "label", "l", nil, "label to attach as key=value (repeatable)")
+	upCmd.Flags().StringVar(&upFromSnapshot, "from-snapshot", "", "seed the sandbox from a snapshot ID")
+	upCmd.Flags().StringVar(&upCloneOf, "clone-of", "", "seed the sandbox from a copy of another sandbox's disk (ID or name)")
+	upCmd.Flags().StringVar(&upRepo, "repo", "", "git repository to clone into /workspace while provisioning")
+	upCmd.Flags().StringVar(&upBranch, "branch", "", "branch of --repo to check out (default: the repository's default branch)")
+	upCmd.Flags().StringVar(&upRepoKey, "repo-key", "", "file with a private SSH deploy key for --repo")
+	upCmd.Flags().BoolVar(&upNoDotfiles, "no-dotfiles", false, "skip applying configured dotfiles")
+	upCmd.Flags().IntVar(&upCount, "count", 1, "number of sandboxes to create, named <name>-1 to <name>-N")
+	upCmd.Flags().BoolVar(&upBootstrap, "bootstrap", false, "create, set up, upload and sync the project as described in "+project.FileName)
+	upCmd.Flags().StringVarP(&upOutput, "output", "o", "", "output format (text|json; default: json when stdout is not a terminal)")
+	addProgressFlag(upCmd)
 }
 
 func runUp(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	jsonOut, err := upJSONOutput()
 	if err != nil {
 		return err
 	}
+	if upCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	if err := validProgress(); err != nil {
+		return err
+	}
+	if !jsonOut {
+		sandboxes, err := createUpSandbox(cmd, false)
+		noteCreatedSandboxes(sandboxes)
+		switch {
+		case len(sandboxes) == 0 || upDetach:
+		case upCount > 1:
+			printSandboxesReady(sandboxes)
+		default:
+			printSandboxReady(sandboxes[0])
+		}
+		return err
+	}
+
+	// Progress goes to stderr so stdout holds nothing but the result
+	var sandboxes []*claudevps.Sandbox
+	err = withStdoutAsStderr(func() error {
+		var err error
+		sandboxes, err = createUpSandbox(cmd, true)
+		return err
+	})
+	noteCreatedSandboxes(sandboxes)
+	if len(sandboxes) == 0 {
+		return err
+	}
+	if werr := writeUpResult(os.Stdout, sandboxes); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// noteCreatedSandboxes records new sandboxes for the history
+func noteCreatedSandboxes(sandboxes []*claudevps.Sandbox) {
+	for _, s := range sandboxes {
+		noteSandbox(s.ID, s.Name)
+	}
+}
+
+// upJSONOutput reports whether up prints its result as JSON: with
+// --output json, or by default when stdout is not a terminal
+func upJSONOutput() (bool, error) {
+	switch upOutput {
+	case "":
+		return !term.IsTerminal(int(os.Stdout.Fd())), nil
+	case "text":
+		return false, nil
+	case outputJSON:
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid output format %q (must be text or json)", upOutput)
+}
+
+// withStdoutAsStderr runs fn with everything it prints to stdout, including
+// colour output and spinners, sent to stderr instead
+func withStdoutAsStderr(fn func() error) error {
+	oldStdout, oldOutput := os.Stdout, color.Output
+	os.Stdout, color.Output = os.Stderr, color.Error
+	defer func() { os.Stdout, color.Output = oldStdout, oldOutput }()
+	return fn()
+}
+
+// upResult is what 'cvps up --output json' prints
+type upResult struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Status string       `json:"status"`
+	SSH    *upSSHResult `json:"ssh,omitempty"`
+}
+
+type upSSHResult struct {
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	User    string `json:"user"`
+	Command string `json:"command"`
+}
+
+func newUpResult(sandbox *claudevps.Sandbox) upResult {
+	result := upResult{ID: sandbox.ID, Name: sandbox.Name, Status: sandbox.Status}
+	if sandbox.SSHHost != "" {
+		result.SSH = &upSSHResult{
+			Host:    sandbox.SSHHost,
+			Port:    sandbox.SSHPort,
+			User:    sandbox.SSHUser,
+			Command: fmt.Sprintf("ssh %s@%s -p %d", sandbox.SSHUser, sandbox.SSHHost, sandbox.SSHPort),
+		}
+	}
+	return result
+}
+
+// writeUpResult prints one JSON object, or an array of them with --count
+func writeUpResult(w io.Writer, sandboxes []*claudevps.Sandbox) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if upCount <= 1 {
+		return enc.Encode(newUpResult(sandboxes[0]))
+	}
+	results := make([]upResult, len(sandboxes))
+	for i, s := range sandboxes {
+		results[i] = newUpResult(s)
+	}
+	return enc.Encode(results)
+}
+
+// createUpSandbox creates the sandbox, or --count of them, and unless
+// detached waits for them to be ready. It returns nothing for a dry run or a
+// cancelled wizard. The wizard is not offered when quiet, as its prompts
+// would mix with the JSON result.
+func createUpSandbox(cmd *cobra.Command, quiet bool) ([]*claudevps.Sandbox, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
 
 	if !cfg.IsAuthenticated() {
-		return fmt.Errorf("not logged in. Run 'cvps login' first")
+		return nil, fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	labels, err := parseLabels(upLabels)
+	if err != nil {
+		return nil, err
+	}
+	if upFromSnapshot != "" && upCloneOf != "" {
+		return nil, fmt.Errorf("--from-snapshot and --clone-of cannot be used together")
+	}
+	arch, err := normalizeArch(upArch)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repoSeed(upRepo, upBranch, upRepoKey)
+	if err != nil {
+		return nil, err
+	}
+	if repo != nil && (upFromSnapshot != "" || upCloneOf != "") {
+		return nil, fmt.Errorf("--repo cannot be combined with --from-snapshot or --clone-of")
+	}
+	var bootstrap *project.Bootstrap
+	if upBootstrap {
+		if upCount > 1 || upDetach {
+			return nil, fmt.Errorf("--bootstrap cannot be combined with --count or --detach")
+		}
+		if bootstrap, err = loadBootstrap(); err != nil {
+			return nil, err
+		}
 	}
 
-	client := api.NewClientFromConfig(cfg)
+	client := newClientFromConfig(cfg)
+	ctx := context.Background()
 
 	// Build create request
-	req := &api.CreateSandboxRequest{
+	req := &claudevps.CreateSandboxRequest{
 		Name:      upName,
 		CPUCores:  upCPU,
 		MemoryGB:  upMemory,
 		StorageGB: upStorage,
+		Image:     upImage,
+		Region:    upRegion,
+		Arch:      arch,
+		Labels:    labels,
+
+		Description: strings.TrimSpace(upDesc),
+
+		FromSnapshot: upFromSnapshot,
 	}
 
-	// Apply defaults
-	if req.CPUCores == 0 {
-		req.CPUCores = cfg.Defaults.CPUCores
+	if !quiet && shouldRunUpWizard(cmd) {
+		// Estimates are optional; the wizard works without them
+		pricing, _ := client.GetPricing(ctx)
+		wz := &upWizard{in: bufio.NewReader(os.Stdin), out: os.Stdout, pricing: pricing}
+		if req, err = wz.run(wizardDefaultName(), cfg.Defaults.Image); err != nil {
+			return nil, err
+		}
+		if req == nil {
+			fmt.Println("Cancelled.")
+			return nil, nil
+		}
+		fmt.Println()
 	}
-	if req.MemoryGB == 0 {
-		req.MemoryGB = cfg.Defaults.MemoryGB
+	req.Repo = repo
+
+	if upCloneOf != "" {
+		sourceID, err := resolveSandboxRef(ctx, client, upCloneOf)
+		if err != nil {
+			return nil, err
+		}
+		req.CloneOf = sourceID
 	}
-	if req.StorageGB == 0 {
-		req.StorageGB = cfg.Defaults.StorageGB
+
+	var templated map[string]string
+	if bootstrap != nil {
+		if templated, err = applyBootstrapTemplate(req, bootstrap); err != nil {
+			return nil, err
+		}
+		checkBootstrapEnv(ctx, client, bootstrap)
 	}
-	if req.Name == "" {
-		req.Name = fmt.Sprintf("sandbox-%d", time.Now().Unix())
+	sources := applyUpDefaults(req, cfg)
+	maps.Copy(sources, templated)
+
+	if dryRun {
+		return nil, printUpDryRun(ctx, client, req, sources)
+	}
+	if upCount > 1 {
+		return createUpSandboxes(ctx, cfg, client, req, upCount)
 	}
 
 	// Create sandbox
-	fmt.Printf("Creating sandbox '%s'...\n", req.Name)
+	switch {
+	case req.FromSnapshot != "":
+		fmt.Printf("Creating sandbox '%s' from snapshot %s...\n", req.Name, req.FromSnapshot)
+	case req.CloneOf != "":
+		fmt.Printf("Creating sandbox '%s' as a clone of %s...\n", req.Name, req.CloneOf)
+	case req.Repo != nil:
+		fmt.Printf("Creating sandbox '%s' with %s in /workspace...\n", req.Name, req.Repo.URL)
+	default:
+		fmt.Printf("Creating sandbox '%s'...\n", req.Name)
+	}
 
-	ctx := context.Background()
 	sandbox, err := client.CreateSandbox(ctx, req)
-	if err != nil {
-		return fmt.Errorf("failed to create sandbox: %w", err)
+	switch {
+	case isConflict(err, claudevps.CodeNameTaken):
+		if sandbox, err = sandboxBeingCreated(ctx, client, req.Name); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Sandbox '%s' is already being created (%s); waiting for it\n", sandbox.Name, sandbox.ID)
+	case err != nil:
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	default:
+		fmt.Printf("Sandbox created: %s\n", sandbox.ID)
 	}
 
-	fmt.Printf("Sandbox created: %s\n", sandbox.ID)
-
 	if upDetach {
 		fmt.Println("\nSandbox is provisioning. Use 'cvps status' to check progress.")
 		saveLocalContext(sandbox.ID, sandbox.Name)
-		return nil
+		return []*claudevps.Sandbox{sandbox}, nil
 	}
 
-	// Wait for sandbox to be ready
+	status, err := waitForSandboxReady(ctx, client, sandbox.ID)
+	if err != nil {
+		return nil, err
+	}
+	saveLocalContext(sandbox.ID, sandbox.Name)
+	if !upNoDotfiles {
+		bootstrapDotfiles(ctx, cfg, status)
+	}
+	if bootstrap != nil {
+		if err := runBootstrap(ctx, cfg, client, status, bootstrap); err != nil {
+			return []*claudevps.Sandbox{status}, err
+		}
+	}
+	return []*claudevps.Sandbox{status}, nil
+}
+
+// sandboxBeingCreated returns the sandbox that took name if it is still
+// provisioning, as when the same 'cvps up' raced with itself. Otherwise the
+// name is genuinely taken and an error says so.
+func sandboxBeingCreated(ctx context.Context, client *claudevps.Client, name string) (*claudevps.Sandbox, error) {
+	sandboxes, err := listAllSandboxesForConnect(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	for _, s := range sandboxes {
+		if s.Name == name && (s.Status == "provisioning" || s.Status == "starting") {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("a sandbox named '%s' already exists. Use 'cvps connect %s' or choose another --name", name, name)
+}
+
+// waitForSandboxReady polls the sandbox until it is running, showing the
+// provisioning stages as they pass
+func waitForSandboxReady(ctx context.Context, client *claudevps.Client, id string) (*claudevps.Sandbox, error) {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Provisioning sandbox..."
 	s.Start()
 
 	timeout := 5 * time.Minute
 	deadline := time.Now().Add(timeout)
+	stages := &stageChecklist{w: os.Stdout}
+	poller := newSandboxPoller(client, id, deadline, provisioningEnded)
+	defer poller.stop()
+	var reported claudevps.ProvisioningProgress
 
 	for time.Now().Before(deadline) {
-		status, err := client.GetSandboxStatus(ctx, sandbox.ID)
+		status, err := client.GetSandboxStatus(ctx, id)
 		if err != nil {
+			if claudevps.RetryAfter(err) > 0 {
+				poller.wait(ctx, err)
+				continue
+			}
 			s.Stop()
-			return fmt.Errorf("failed to get status: %w", err)
+			return nil, fmt.Errorf("failed to get status: %w", err)
 		}
 
 		switch status.Status {
 		case "running":
 			s.Stop()
-			printSandboxReady(status)
-			saveLocalContext(sandbox.ID, sandbox.Name)
-			return nil
+			stages.complete()
+			emitProgress(progressEvent{Stage: "ready", Status: progressDone, Sandbox: id})
+			return status, nil
 
 		case "failed", "error":
 			s.Stop()
-			return fmt.Errorf("sandbox provisioning failed: %s", status.Status)
+			stage := "provisioning"
+			if p := status.Provisioning; p != nil && p.Stage != "" {
+				stage = p.Stage
+			}
+			emitProgress(progressEvent{Stage: stage, Status: progressFailed, Sandbox: id, Message: failureSummary(status)})
+			if p := status.Provisioning; p != nil && p.Code == claudevps.ProvisioningErrorImagePullAuth {
+				stages.fail(p.Stage, p.Reason)
+				return nil, &imagePullAuthError{Image: status.Image, Reason: p.Reason}
+			}
+			return nil, provisioningFailure(ctx, client, status, stages)
 
 		default:
-			s.Suffix = fmt.Sprintf(" %s...", status.Status)
+			if p := status.Provisioning; p != nil {
+				if *p != reported {
+					reported = *p
+					emitProvisioning(id, p)
+				}
+				if stages.advance(p.Stage) {
+					// Restart so the spinner redraws below the new checklist lines
+					s.Stop()
+					stages.flush()
+					s.Start()
+				}
+				s.Suffix = " " + stageLabel(p.Stage) + "..."
+				if p.Progress > 0 {
+					s.Suffix += fmt.Sprintf(" %d%%", p.Progress)
+				}
+			} else {
+				s.Suffix = fmt.Sprintf(" %s...", status.Status)
+			}
 		}
 
-		time.Sleep(2 * time.Second)
+		poller.wait(ctx, nil)
 	}
 
 	s.Stop()
-	return fmt.Errorf("timeout waiting for sandbox to be ready (waited %s)", timeout)
+	return nil, fmt.Errorf("timeout waiting for sandbox to be ready (waited %s)", timeout)
 }
 
-func printSandboxReady(sandbox *api.Sandbox) {
+// provisioningEnded reports whether an event means provisioning succeeded or
+// failed. The sandbox is then fetched once more for the details.
+func provisioningEnded(e *claudevps.SandboxEvent) bool {
+	switch {
+	case e.Type == claudevps.SandboxEventDeleted:
+		return true
+	case e.Type != claudevps.SandboxEventStatus:
+		return false
+	}
+	return e.Status == "running" || e.Status == "failed" || e.Status == "error"
+}
+
+// emitProvisioning reports a provisioning stage with --progress json
+func emitProvisioning(id string, p *claudevps.ProvisioningProgress) {
+	e := progressEvent{Stage: p.Stage, Status: progressRunning, Sandbox: id}
+	if p.Progress > 0 {
+		percent := p.Progress
+		e.Percent = &percent
+	}
+	emitProgress(e)
+}
+
+// stageLabels are the human-readable names of provisioning stages
+var stageLabels = map[string]string{
+	claudevps.StageQueued:         "Queued",
+	claudevps.StagePullingImage:   "Pulling image",
+	claudevps.StageBooting:        "Booting",
+	claudevps.StageConfiguringSSH: "Configuring SSH",
+	claudevps.StageCloningRepo:    "Cloning repository",
+}
+
+func stageLabel(stage string) string {
+	if label, ok := stageLabels[stage]; ok {
+		return label
+	}
+	return strings.ReplaceAll(stage, "_", " ")
+}
+
+// provisioningFailureLogLines is how many log lines are shown when
+// provisioning fails
+const provisioningFailureLogLines = 10
+
+// provisioningFailure reports why a sandbox failed to provision, with the end
+// of its log, and returns the error to exit with
+func provisioningFailure(ctx context.Context, client *claudevps.Client, status *claudevps.Sandbox, stages *stageChecklist) error {
+	summary := failureSummary(status)
+	msg := "sandbox provisioning failed"
+	if p := status.Provisioning; p != nil && p.Stage != "" {
+		stages.fail(p.Stage, summary)
+		msg += " at " + stageLabel(p.Stage)
+	}
+
+	if entries, err := client.GetSandboxLogs(ctx, status.ID, provisioningFailureLogLines); err == nil && len(entries) > 0 {
+		fmt.Println("\nLast log lines:")
+		for _, e := range entries {
+			fmt.Printf("  %s %-6s %s\n", formatTime(e.Time), e.Source, e.Message)
+		}
+		fmt.Println()
+	}
+
+	if summary == "" {
+		return fmt.Errorf("%s (status: %s, no reason given)", msg, status.Status)
+	}
+	return fmt.Errorf("%s: %s", msg, summary)
+}
+
+// stageChecklist prints a tick for each provisioning stage once it is passed
+type stageChecklist struct {
+	w       io.Writer
+	current int // index of the stage in progress
+	done    int // number of stages already printed
+	started bool
+}
+
+// advance records the current stage and reports whether earlier stages
+// became complete and need to be printed with flush
+func (c *stageChecklist) advance(stage string) bool {
+	idx := slices.Index(claudevps.ProvisioningStages, stage)
+	if idx < 0 {
+		return false
+	}
+	c.started = true
+	if idx > c.current {
+		c.current = idx
+	}
+	return c.current > c.done
+}
+
+func (c *stageChecklist) flush() {
+	for ; c.done < c.current; c.done++ {
+		fmt.Fprintf(c.w, "✓ %s\n", stageLabel(claudevps.ProvisioningStages[c.done]))
+	}
+}
+
+// complete ticks every remaining stage, except trailing optional stages the
+// sandbox never reached. Nothing is printed if the server never reported
+// stages.
+func (c *stageChecklist) complete() {
+	if !c.started {
+		return
+	}
+	last := len(claudevps.ProvisioningStages)
+	for last > c.current+1 && claudevps.OptionalStage(claudevps.ProvisioningStages[last-1]) {
+		last--
+	}
+	c.current = last
+	c.flush()
+}
+
+func (c *stageChecklist) fail(stage, reason string) {
+	if idx := slices.Index(claudevps.ProvisioningStages, stage); idx > c.current {
+		c.current = idx
+	}
+	c.flush()
+	if reason == "" {
+		reason = "failed"
+	}
+	fmt.Fprintf(c.w, "✗ %s: %s\n", stageLabel(stage), reason)
+}
+
+// normalizeArch maps an --arch value, including common aliases such as
+// x86_64 and aarch64, to an API architecture name
+func normalizeArch(arch string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(arch)) {
+	case "":
+		return "", nil
+	case "amd64", "x86_64", "x64":
+		return claudevps.ArchAMD64, nil
+	case "arm64", "aarch64":
+		return claudevps.ArchARM64, nil
+	}
+	return "", fmt.Errorf("unsupported architecture %q (use amd64 or arm64)", arch)
+}
+
+// applyUpDefaults fills unset request fields from the config and returns where
+// each filled value came from. Seeded sandboxes inherit unset resources from
+// their source instead.
+func applyUpDefaults(req *claudevps.CreateSandboxRequest, cfg *config.Config) map[string]string {
+	sources := make(map[string]string)
+	if req.FromSnapshot == "" && req.CloneOf == "" {
+		if req.CPUCores == 0 {
+			req.CPUCores = cfg.Defaults.CPUCores
+			sources["cpuCores"] = "config defaults.cpu_cores"
+		}
+		if req.MemoryGB == 0 {
+			req.MemoryGB = cfg.Defaults.MemoryGB
+			sources["memoryGb"] = "config defaults.memory_gb"
+		}
+		if req.StorageGB == 0 {
+			req.StorageGB = cfg.Defaults.StorageGB
+			sources["storageGb"] = "config defaults.storage_gb"
+		}
+		if req.Image == "" {
+			req.Image = cfg.Defaults.Image
+			sources["image"] = "config defaults.image"
+		}
+	}
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("sandbox-%d", time.Now().Unix())
+		sources["name"] = "generated"
+	}
+	return sources
+}
+
+// printUpDryRun shows the request that would be sent and has the API check it
+// against account limits without creating anything
+func printUpDryRun(ctx context.Context, client *claudevps.Client, req *claudevps.CreateSandboxRequest, sources map[string]string) error {
+	fmt.Println("Request:")
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(redactUpRequest(req)); err != nil {
+		return err
+	}
+
+	fmt.Println("\nSources:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	set := map[string]bool{
+		"name":      req.Name != "",
+		"cpuCores":  req.CPUCores != 0,
+		"memoryGb":  req.MemoryGB != 0,
+		"storageGb": req.StorageGB != 0,
+		"image":     req.Image != "",
+	}
+	for _, field := range []string{"name", "cpuCores", "memoryGb", "storageGb", "image"} {
+		source, ok := sources[field]
+		switch {
+		case ok:
+		case set[field]:
+			source = "flag"
+		default:
+			source = "unset (inherited from source)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", field, source)
+	}
+	w.Flush()
+
+	result, err := client.ValidateSandbox(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to validate request: %w", err)
+	}
+
+	fmt.Println()
+	for _, warning := range result.Warnings {
+		color.Yellow("⚠ %s", warning.String())
+	}
+	if !result.Valid {
+		for _, issue := range result.Errors {
+			color.Red("✗ %s", issue.String())
+		}
+		return fmt.Errorf("request would be rejected (dry run, nothing created)")
+	}
+
+	color.Green("✓ Request is valid (dry run, nothing created)")
+	return nil
+}
+
+func printSandboxReady(sandbox *claudevps.Sandbox) {
 	fmt.Println("\n✓ Sandbox is ready!")
 
 	fmt.Println("Resources:")
 	fmt.Printf("  CPU:     %d cores\n", sandbox.CPUCores)
 	fmt.Printf("  Memory:  %d GB\n", sandbox.MemoryGB)
 	fmt.Printf("  Storage: %d GB\n", sandbox.StorageGB)
+	if sandbox.Arch != "" {
+		fmt.Printf("  Arch:    %s\n", sandbox.Arch)
+	}
 
 	if sandbox.SSHHost != "" {
 		fmt.Println("\nConnection:")
@@ -157,52 +737,133 @@ func printSandboxReady(sandbox *api.Sandbox) {
 	fmt.Println("  cvps down        - Terminate sandbox")
 }
 
-// LocalContext stores current sandbox context in working directory
-type LocalContext struct {
-	SandboxID string `yaml:"sandbox_id"`
-	Name      string `yaml:"name,omitempty"`
-	CreatedAt string `yaml:"created_at"`
-}
+// LocalContext is a sandbox recorded in the working directory
+type LocalContext = localctx.Entry
 
+// saveLocalContext records the sandbox in the project and makes it current
 func saveLocalContext(sandboxID, name string) error {
-	ctx := LocalContext{
-		SandboxID: sandboxID,
-		Name:      name,
-		CreatedAt: time.Now().Format(time.RFC3339),
-	}
+	return updateLocalContext(func(c *localctx.Context) error {
+		c.Put(LocalContext{
+			SandboxID: sandboxID,
+			Name:      name,
+			CreatedAt: time.Now().Format(time.RFC3339),
+		})
+		return nil
+	})
+}
 
-	data, err := yaml.Marshal(ctx)
+// updateLocalContext changes the project context under its lock and mirrors
+// the result into the workspace registry
+func updateLocalContext(fn func(*localctx.Context) error) error {
+	var updated localctx.Context
+	err := localctx.Update(".", func(c *localctx.Context) error {
+		if err := fn(c); err != nil {
+			return err
+		}
+		updated = *c
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(".cvps.yaml", data, 0644)
+	recordWorkspace(".", &updated)
+	return nil
 }
 
+// loadLocalContext returns the current sandbox of the project, or nil
 func loadLocalContext() (*LocalContext, error) {
-	data, err := os.ReadFile(".cvps.yaml")
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
+	c, err := localctx.Load(".")
+	if err != nil || c == nil {
 		return nil, err
 	}
-
-	var ctx LocalContext
-	if err := yaml.Unmarshal(data, &ctx); err != nil {
-		return nil, err
-	}
-
-	return &ctx, nil
+	return c.CurrentEntry(), nil
 }
 
 func getCurrentSandboxID() (string, error) {
-	ctx, err := loadLocalContext()
+	c, err := localctx.Load(".")
 	if err != nil {
 		return "", err
 	}
-	if ctx == nil {
+	if c == nil {
 		return "", fmt.Errorf("no sandbox context. Run 'cvps up' first or pass a sandbox ID as the first argument")
 	}
-	return ctx.SandboxID, nil
+	if e := c.CurrentEntry(); e != nil {
+		noteSandbox(e.SandboxID, e.Name)
+		return e.SandboxID, nil
+	}
+	return "", fmt.Errorf("no current sandbox for this project. Run 'cvps use <name>' to pick one of: %s", strings.Join(localEntryNames(c), ", "))
+}
+
+// localEntryNames lists project sandboxes by name, falling back to ID
+func localEntryNames(c *localctx.Context) []string {
+	names := make([]string, len(c.Sandboxes))
+	for i, e := range c.Sandboxes {
+		names[i] = e.Name
+		if names[i] == "" {
+			names[i] = e.SandboxID
+		}
+	}
+	return names
+}
+
+// redactUpRequest returns a copy of req that is safe to print, with repository
+// credentials masked
+func redactUpRequest(req *claudevps.CreateSandboxRequest) *claudevps.CreateSandboxRequest {
+	if req.Repo == nil {
+		return req
+	}
+	shown, repo := *req, *req.Repo
+	if repo.DeployKey != "" {
+		repo.DeployKey = "<redacted>"
+	}
+	if repo.Token != "" {
+		repo.Token = "<redacted>"
+	}
+	shown.Repo = &repo
+	return &shown
+}
+
+// repoSeed builds the repository to clone from --repo, --branch and
+// --repo-key. https URLs take their token from CVPS_REPO_TOKEN.
+func repoSeed(rawURL, branch, keyFile string) (*claudevps.RepoSeed, error) {
+	if rawURL == "" {
+		if branch != "" || keyFile != "" {
+			return nil, fmt.Errorf("--branch and --repo-key need --repo")
+		}
+		return nil, nil
+	}
+
+	seed := &claudevps.RepoSeed{URL: rawURL, Branch: branch}
+	switch {
+	case isSSHRepoURL(rawURL):
+		if keyFile != "" {
+			key, err := readSecretValue(keyFile, nil, false)
+			if err != nil {
+				return nil, err
+			}
+			seed.DeployKey = key
+		}
+	case strings.HasPrefix(rawURL, "https://"):
+		if keyFile != "" {
+			return nil, fmt.Errorf("--repo-key is for ssh repository URLs; set CVPS_REPO_TOKEN for https")
+		}
+		seed.Token = os.Getenv("CVPS_REPO_TOKEN")
+	default:
+		return nil, fmt.Errorf("unsupported repository URL %q (use git@host:path, ssh:// or https://)", rawURL)
+	}
+	return seed, nil
+}
+
+// isSSHRepoURL reports whether a git URL is ssh://... or scp-like user@host:path
+func isSSHRepoURL(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "ssh://") {
+		return true
+	}
+	if strings.Contains(rawURL, "://") {
+		return false
+	}
+	at := strings.Index(rawURL, "@")
+	colon := strings.Index(rawURL, ":")
+	return at > 0 && colon > at+1 && colon < len(rawURL)-1
 }
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/internal/project"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+)
+
+// loadBootstrap reads the bootstrap section of the project config for
+// 'cvps up --bootstrap'
+func loadBootstrap() (*project.Bootstrap, error) {
+	c, err := project.Load(".")
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.Bootstrap == nil {
+		return nil, fmt.Errorf("--bootstrap needs a bootstrap section in %s", project.FileName)
+	}
+	// Caught before anything is created
+	if _, err := parsePortForwards(c.Bootstrap.Ports); err != nil {
+		return nil, fmt.Errorf("%s bootstrap.ports: %w", project.FileName, err)
+	}
+	return c.Bootstrap, nil
+}
+
+// applyBootstrapTemplate fills unset request fields from the bootstrap
+// section and returns where each filled value came from. Labels from flags
+// are added to the template's. As with the config defaults, seeded sandboxes
+// keep the resources of their source. Without a name in either, the sandbox
+// is named after the directory.
+func applyBootstrapTemplate(req *claudevps.CreateSandboxRequest, b *project.Bootstrap) (map[string]string, error) {
+	sources := make(map[string]string)
+	source := func(key string) string { return project.FileName + " bootstrap." + key }
+
+	if req.Description == "" {
+		req.Description = b.Description
+	}
+	if req.Region == "" {
+		req.Region = b.Region
+	}
+	if req.Arch == "" {
+		arch, err := normalizeArch(b.Arch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source("arch"), err)
+		}
+		req.Arch = arch
+	}
+	if len(b.Labels) > 0 {
+		labels := maps.Clone(b.Labels)
+		maps.Copy(labels, req.Labels)
+		req.Labels = labels
+	}
+
+	if req.Name == "" {
+		req.Name = b.Name
+		sources["name"] = source("name")
+		if req.Name == "" {
+			req.Name = wizardDefaultName()
+			sources["name"] = "directory name"
+		}
+	}
+	if req.FromSnapshot != "" || req.CloneOf != "" {
+		return sources, nil
+	}
+	if req.CPUCores == 0 && b.CPUCores != 0 {
+		req.CPUCores = b.CPUCores
+		sources["cpuCores"] = source("cpu_cores")
+	}
+	if req.MemoryGB == 0 && b.MemoryGB != 0 {
+		req.MemoryGB = b.MemoryGB
+		sources["memoryGb"] = source("memory_gb")
+	}
+	if req.StorageGB == 0 && b.StorageGB != 0 {
+		req.StorageGB = b.StorageGB
+		sources["storageGb"] = source("storage_gb")
+	}
+	if req.Image == "" && b.Image != "" {
+		req.Image = b.Image
+		sources["image"] = source("image")
+	}
+	return sources, nil
+}
+
+// checkBootstrapEnv warns about secrets listed in bootstrap.env that new
+// sandboxes will not get as environment variables
+func checkBootstrapEnv(ctx context.Context, client *claudevps.Client, b *project.Bootstrap) {
+	if len(b.Env) == 0 {
+		return
+	}
+	secrets, err := client.ListSecrets(ctx)
+	if err != nil {
+		debuglog.Printf("bootstrap env check skipped: %v", err)
+		return
+	}
+	for _, name := range b.Env {
+		set := slices.ContainsFunc(secrets, func(s claudevps.Secret) bool {
+			return s.Name == name && s.Mount == claudevps.SecretMountEnv && s.SandboxID == ""
+		})
+		if !set {
+			color.Yellow("⚠ %s is listed in bootstrap.env but not set; set it with 'cvps secrets set %s --mount env'", name, name)
+		}
+	}
+}
+
+// runBootstrap takes a new sandbox through the rest of the bootstrap: the
+// setup script, the upload of the project directory, file sync and port
+// forwards. It stops at the first step that fails; the sandbox is kept
+// either way.
+func runBootstrap(ctx context.Context, cfg *config.Config, client *claudevps.Client, sandbox *claudevps.Sandbox, b *project.Bootstrap) error {
+	if b.Setup != "" {
+		fmt.Println("\nRunning setup script...")
+		if err := runSetupScript(ctx, sandbox, b.Setup); err != nil {
+			return fmt.Errorf("setup script failed: %w", err)
+		}
+		color.Green("✓ Setup script finished")
+	}
+
+	if b.MigrateEnabled() {
+		fmt.Println("\nUploading project files...")
+		if err := migrateProject(ctx, cfg, client, sandbox, b.Exclude); err != nil {
+			return fmt.Errorf("failed to upload project files: %w", err)
+		}
+	}
+
+	if b.SyncEnabled() {
+		fmt.Println()
+		// The session is left running in the mutagen daemon
+		if stop := startSyncSession(cfg, sandbox, b.Exclude...); stop != nil {
+			fmt.Println("Sync keeps running in the background. Use 'cvps sync stop' to stop it.")
+		}
+	}
+
+	if len(b.Ports) > 0 {
+		forwards, err := parsePortForwards(b.Ports)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		if err := requireMutagen(); err != nil {
+			color.Yellow("⚠ Ports not forwarded: %v", err)
+		} else if err := createForwardSessions(sandbox, forwards); err != nil {
+			color.Yellow("⚠ Ports not forwarded: %v", err)
+		} else {
+			fmt.Println("Forwards keep running in the background. Stop them with 'cvps forward terminate'.")
+		}
+	}
+	return nil
+}
+
+// runSetupScript runs script with bash in /workspace, showing its output
+func runSetupScript(ctx context.Context, sandbox *claudevps.Sandbox, script string) error {
+	conn, err := dialSandbox(ctx, sandbox)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Run(ctx, "cd /workspace && bash -ec "+remote.Quote(script), nil, os.Stdout, os.Stderr)
+}
+
+// migrateProject uploads the working directory to /workspace without asking,
+// as 'cvps migrate .' would after confirmation
+func migrateProject(ctx context.Context, cfg *config.Config, client *claudevps.Client, sandbox *claudevps.Sandbox, exclude []string) error {
+	absPath, err := filepath.Abs(".")
+	if err != nil {
+		return err
+	}
+
+	excludes := append(slices.Clone(cfg.Sync.IgnorePatterns), exclude...)
+	files, err := migration.NewScanner(absPath, excludes).Scan()
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+	if files.Count == 0 {
+		fmt.Println("No files to upload")
+		return nil
+	}
+	if err := checkRemoteFreeSpace(ctx, client, sandbox, files.TotalSize, false); err != nil {
+		return err
+	}
+	return transferWorkspace(ctx, client, sandbox, absPath, files, false, false, transportAuto)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+)
+
+// fleetPollInterval is how often 'cvps up --count' checks on the sandboxes
+var fleetPollInterval = 2 * time.Second
+
+// createUpSandboxes creates count copies of req named <name>-1 to
+// <name>-count in batches and waits for them. Sandboxes that could not be
+// created or failed to provision are reported and the rest returned along
+// with an error.
+func createUpSandboxes(ctx context.Context, cfg *config.Config, client *claudevps.Client, req *claudevps.CreateSandboxRequest, count int) ([]*claudevps.Sandbox, error) {
+	reqs := make([]*claudevps.CreateSandboxRequest, count)
+	for i := range reqs {
+		r := *req
+		r.Name = fmt.Sprintf("%s-%d", req.Name, i+1)
+		reqs[i] = &r
+	}
+
+	fmt.Printf("Creating %d sandboxes, %s to %s...\n", count, reqs[0].Name, reqs[count-1].Name)
+	created, errs := createSandboxes(ctx, client, reqs)
+
+	var sandboxes []*claudevps.Sandbox
+	var createErr error
+	for i, err := range errs {
+		if err != nil {
+			color.Red("✗ %s: %v", reqs[i].Name, err)
+			createErr = err
+			continue
+		}
+		fmt.Printf("Sandbox created: %s (%s)\n", created[i].Name, created[i].ID)
+		sandboxes = append(sandboxes, created[i])
+	}
+	if len(sandboxes) == 0 {
+		return nil, fmt.Errorf("failed to create sandboxes: %w", createErr)
+	}
+	if createErr != nil {
+		createErr = fmt.Errorf("%d of %d sandboxes could not be created", count-len(sandboxes), count)
+	}
+
+	if upDetach {
+		fmt.Println("\nSandboxes are provisioning. Use 'cvps status' to check progress.")
+		for _, s := range sandboxes {
+			saveLocalContext(s.ID, s.Name)
+		}
+		return sandboxes, createErr
+	}
+
+	ready, waitErr := waitForSandboxesReady(ctx, client, sandboxes)
+	for _, s := range ready {
+		saveLocalContext(s.ID, s.Name)
+		if !upNoDotfiles {
+			bootstrapDotfiles(ctx, cfg, s)
+		}
+	}
+	return ready, errors.Join(createErr, waitErr)
+}
+
+// waitForSandboxesReady polls the sandboxes until each is running or has
+// failed, checking on all of them in one batch request per poll
+func waitForSandboxesReady(ctx context.Context, client *claudevps.Client, sandboxes []*claudevps.Sandbox) ([]*claudevps.Sandbox, error) {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = fmt.Sprintf(" Provisioning %d sandboxes...", len(sandboxes))
+	s.Start()
+
+	pending := make([]string, len(sandboxes))
+	for i, sb := range sandboxes {
+		pending[i] = sb.ID
+	}
+	byID := make(map[string]*claudevps.Sandbox)
+	var failed []*claudevps.Sandbox
+
+	deadline := time.Now().Add(5 * time.Minute)
+	for len(pending) > 0 && time.Now().Before(deadline) {
+		statuses, errs := sandboxStatuses(ctx, client, pending)
+		var still []string
+		for i, id := range pending {
+			switch {
+			case errs[i] != nil:
+				debuglog.Printf("status of %s: %v", id, errs[i])
+				still = append(still, id)
+			case statuses[i].Status == "running":
+				byID[id] = statuses[i]
+				emitProgress(progressEvent{Stage: "ready", Status: progressDone, Sandbox: id})
+			case statuses[i].Status == "failed" || statuses[i].Status == "error":
+				failed = append(failed, statuses[i])
+				emitProgress(progressEvent{Stage: "provisioning", Status: progressFailed, Sandbox: id, Message: failureSummary(statuses[i])})
+			default:
+				still = append(still, id)
+			}
+		}
+		pending = still
+		s.Suffix = fmt.Sprintf(" Provisioning sandboxes... %d/%d running", len(byID), len(sandboxes))
+		if len(pending) > 0 {
+			time.Sleep(fleetPollInterval)
+		}
+	}
+	s.Stop()
+
+	// Keep the order they were created in
+	var ready []*claudevps.Sandbox
+	for _, sb := range sandboxes {
+		if r, ok := byID[sb.ID]; ok {
+			ready = append(ready, r)
+		}
+	}
+	for _, f := range failed {
+		color.Red("✗ %s (%s): provisioning failed: %s", f.Name, f.ID, failureSummary(f))
+	}
+	for _, id := range pending {
+		color.Red("✗ %s: still not running after 5m", id)
+	}
+
+	if n := len(failed) + len(pending); n > 0 {
+		return ready, fmt.Errorf("%d of %d sandboxes did not become ready", n, len(sandboxes))
+	}
+	return ready, nil
+}
+
+func printSandboxesReady(sandboxes []*claudevps.Sandbox) {
+	fmt.Printf("\n✓ %d sandboxes are ready!\n\n", len(sandboxes))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSSH")
+	for _, s := range sandboxes {
+		ssh := "-"
+		if s.SSHHost != "" {
+			ssh = fmt.Sprintf("ssh %s@%s -p %d", s.SSHUser, s.SSHHost, s.SSHPort)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.ID, s.Name, ssh)
+	}
+	w.Flush()
+
+	fmt.Println("\nNext steps:")
+	fmt.Println("  cvps group create <name> <sandbox>...    - Group them for bulk commands")
+	fmt.Println("  cvps exec --selector key=value -- <cmd>  - Run a command on labelled ones")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// sizePreset is a named resource bundle offered by the up wizard
+type sizePreset struct {
+	Name      string
+	CPUCores  int
+	MemoryGB  int
+	StorageGB int
+}
+
+var sizePresets = []sizePreset{
+	{"small", 1, 2, 10},
+	{"medium", 2, 4, 20},
+	{"large", 4, 8, 50},
+}
+
+// shouldRunUpWizard reports whether up was run bare, outside a project that
+// already has a sandbox, on an interactive terminal
+func shouldRunUpWizard(cmd *cobra.Command) bool {
+	if cmd == nil || cmd.Flags().NFlag() > 0 {
+		return false
+	}
+	if c, err := localctx.Load("."); err != nil || c != nil {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// upWizard asks for the sandbox settings. pricing may be nil when it could
+// not be fetched, in which case no estimates are shown.
+type upWizard struct {
+	in      *bufio.Reader
+	out     io.Writer
+	pricing *claudevps.Pricing
+}
+
+func (wz *upWizard) ask(prompt, def string) string {
+	if def != "" {
+		fmt.Fprintf(wz.out, "%s [%s]: ", prompt, def)
+	} else {
+		fmt.Fprintf(wz.out, "%s: ", prompt)
+	}
+	line, _ := wz.in.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return def
+}
+
+func (wz *upWizard) cost(cpu, mem, storage int) string {
+	if wz.pricing == nil {
+		return ""
+	}
+	hourly := wz.pricing.HourlyCost(cpu, mem, storage)
+	return fmt.Sprintf("~%s/hour", formatMoney(hourly, wz.pricing.Currency))
+}
+
+// run walks through the wizard and returns the request to create, or nil if
+// the user declined at the confirmation step
+func (wz *upWizard) run(defaultName, defaultImage string) (*claudevps.CreateSandboxRequest, error) {
+	fmt.Fprintln(wz.out, "No options given, so let's set up your sandbox. Press Enter to accept a default.")
+	fmt.Fprintln(wz.out)
+
+	req := &claudevps.CreateSandboxRequest{Name: wz.ask("Name", defaultName)}
+
+	fmt.Fprintln(wz.out, "Size:")
+	for i, p := range sizePresets {
+		fmt.Fprintf(wz.out, "  %d) %-7s %d CPU, %d GB RAM, %d GB disk  %s\n",
+			i+1, p.Name, p.CPUCores, p.MemoryGB, p.StorageGB, wz.cost(p.CPUCores, p.MemoryGB, p.StorageGB))
+	}
+	preset, err := pickPreset(wz.ask("Choose size", "1"))
+	if err != nil {
+		return nil, err
+	}
+	req.CPUCores, req.MemoryGB, req.StorageGB = preset.CPUCores, preset.MemoryGB, preset.StorageGB
+
+	req.Image = wz.ask("Image", defaultImage)
+	if region := wz.ask("Region", "auto"); region != "auto" {
+		req.Region = region
+	}
+
+	fmt.Fprintln(wz.out)
+	fmt.Fprintf(wz.out, "Sandbox %s: %s (%d CPU, %d GB RAM, %d GB disk)\n",
+		req.Name, preset.Name, req.CPUCores, req.MemoryGB, req.StorageGB)
+	if wz.pricing != nil {
+		hourly := wz.pricing.HourlyCost(req.CPUCores, req.MemoryGB, req.StorageGB)
+		fmt.Fprintf(wz.out, "Estimated cost: ~%s/hour, ~%s/month if left running. Use 'cvps down' when you are done.\n",
+			formatMoney(hourly, wz.pricing.Currency), formatMoney(hourly*claudevps.HoursPerMonth, wz.pricing.Currency))
+	}
+	fmt.Fprintln(wz.out, "Tip: next time pass flags such as --name or --cpu to skip these questions.")
+
+	answer := strings.ToLower(wz.ask("Create this sandbox? [Y/n]", ""))
+	if answer != "" && answer != "y" && answer != "yes" {
+		return nil, nil
+	}
+	return req, nil
+}
+
+// pickPreset accepts a preset number or name
+func pickPreset(choice string) (sizePreset, error) {
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(sizePresets) {
+		return sizePresets[n-1], nil
+	}
+	for _, p := range sizePresets {
+		if strings.EqualFold(p.Name, choice) {
+			return p, nil
+		}
+	}
+	return sizePreset{}, fmt.Errorf("invalid size %q (choose 1-%d or small, medium, large)", choice, len(sizePresets))
+}
+
+// wizardDefaultName suggests a sandbox name from the working directory
+func wizardDefaultName() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(wd)
+}
+
+// formatMoney shows small amounts with an extra digit so hourly rates don't round to zero
+func formatMoney(amount float64, currency string) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	if amount > 0 && amount < 0.1 {
+		s = strconv.FormatFloat(amount, 'f', 3, 64)
+	}
+	if currency == "" || currency == "USD" {
+		return "$" + s
+	}
+	return s + " " + currency
+}
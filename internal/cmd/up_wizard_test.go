@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestUpWizard_Defaults(t *testing.T) {
+	var out bytes.Buffer
+	wz := &upWizard{
+		in:      bufio.NewReader(strings.NewReader("\n\n\n\n\n")),
+		out:     &out,
+		pricing: &claudevps.Pricing{Currency: "USD", CPUCoreHour: 0.01, MemoryGBHour: 0.005},
+	}
+
+	req, err := wz.run("myproject", "ghcr.io/claudevps/claude-sandbox:latest")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if req == nil {
+		t.Fatal("expected a request")
+	}
+	if req.Name != "myproject" || req.CPUCores != 1 || req.MemoryGB != 2 || req.StorageGB != 10 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.Image != "ghcr.io/claudevps/claude-sandbox:latest" || req.Region != "" {
+		t.Errorf("unexpected image/region: %q %q", req.Image, req.Region)
+	}
+	if !strings.Contains(out.String(), "~$0.020/hour") {
+		t.Errorf("expected cost estimate in output:\n%s", out.String())
+	}
+}
+
+func TestUpWizard_Choices(t *testing.T) {
+	var out bytes.Buffer
+	wz := &upWizard{
+		in:  bufio.NewReader(strings.NewReader("api\nlarge\nubuntu:24.04\neu-west\ny\n")),
+		out: &out,
+	}
+
+	req, err := wz.run("myproject", "default-image")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if req.Name != "api" || req.CPUCores != 4 || req.Image != "ubuntu:24.04" || req.Region != "eu-west" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if strings.Contains(out.String(), "Estimated cost") {
+		t.Error("expected no cost estimate without pricing")
+	}
+}
+
+func TestUpWizard_Declined(t *testing.T) {
+	wz := &upWizard{
+		in:  bufio.NewReader(strings.NewReader("\n2\n\n\nn\n")),
+		out: &bytes.Buffer{},
+	}
+	req, err := wz.run("myproject", "img")
+	if err != nil || req != nil {
+		t.Errorf("run() = %+v, %v, want nil, nil", req, err)
+	}
+}
+
+func TestPickPreset(t *testing.T) {
+	if p, err := pickPreset("2"); err != nil || p.Name != "medium" {
+		t.Errorf("pickPreset(2) = %v, %v", p, err)
+	}
+	if p, err := pickPreset("Large"); err != nil || p.Name != "large" {
+		t.Errorf("pickPreset(Large) = %v, %v", p, err)
+	}
+	if _, err := pickPreset("4"); err == nil {
+		t.Error("expected error for out-of-range preset")
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{0.042, "USD", "$0.042"},
+		{30.66, "", "$30.66"},
+		{1.5, "EUR", "1.50 EUR"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatMoney(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
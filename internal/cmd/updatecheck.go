@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/version"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// updateCheckInterval is how often to look for a newer release
+const updateCheckInterval = 24 * time.Hour
+
+// updateCheckTimeout bounds the check so it never holds up a command for long
+const updateCheckTimeout = 2 * time.Second
+
+// updateHintVisible reports whether a hint would be seen by a person rather
+// than a script or CI job
+func updateHintVisible() bool {
+	return term.IsTerminal(int(os.Stderr.Fd())) && os.Getenv("CI") == ""
+}
+
+// notifyUpdate prints a one-line upgrade hint to stderr after a command when
+// a newer release is out. The API is asked at most once a day; the result is
+// cached under ~/.cvps/cache. Dev builds, scripts and CI are left alone.
+func notifyUpdate(c *cobra.Command) {
+	if telemetryCommandName(c) == "" || version.Version == "dev" || !updateHintVisible() {
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil || !cfg.UpdateCheckEnabled() {
+		return
+	}
+	checkForUpdate(cfg, version.Version, time.Now(), os.Stderr)
+}
+
+// checkForUpdate looks up the latest release unless it was checked within
+// updateCheckInterval, and tells w about it if it is newer than current
+func checkForUpdate(cfg *config.Config, current string, now time.Time, w io.Writer) {
+	last, err := cache.LoadUpdateCheck()
+	if err != nil {
+		debuglog.Printf("update check: %v", err)
+	}
+	if last != nil && now.Sub(last.CheckedAt) < updateCheckInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	// Checked without credentials, like telemetry
+	client := claudevps.NewClient(cfg.APIBaseURL, "", cliClientOptions()...)
+	check := &cache.UpdateCheck{CheckedAt: now}
+	releaseURL := ""
+	release, err := client.LatestCLIRelease(ctx)
+	if err != nil {
+		debuglog.Printf("update check: %v", err)
+	} else {
+		check.LatestVersion, releaseURL = release.Version, release.URL
+	}
+	// Failed checks count too, so an offline machine is not asked every run
+	if err := cache.SaveUpdateCheck(check); err != nil {
+		debuglog.Printf("update check: %v", err)
+	}
+
+	if check.LatestVersion != "" && newerVersion(check.LatestVersion, current) {
+		exe, _ := os.Executable()
+		fmt.Fprintln(w, color.YellowString("A new version of cvps is available: %s → %s. %s (disable with 'cvps config set update_check false')",
+			strings.TrimPrefix(current, "v"), strings.TrimPrefix(check.LatestVersion, "v"), upgradeHint(exe, releaseURL)))
+	}
+}
+
+// latestReleaseURL is where releases are downloaded when the API names none
+const latestReleaseURL = "https://github.com/Achronon/cvps/releases/latest"
+
+// upgradeHint says how to upgrade the cvps at exe: with brew when Homebrew
+// installed it, otherwise from the release page
+func upgradeHint(exe, releaseURL string) string {
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if strings.Contains(filepath.ToSlash(exe), "/Cellar/") {
+		return "Upgrade with 'brew upgrade cvps'."
+	}
+	if releaseURL == "" {
+		releaseURL = latestReleaseURL
+	}
+	return "Download it from " + releaseURL + "."
+}
+
+// newerVersion reports whether version a is newer than b. Versions are
+// dotted numbers with an optional v prefix; a pre-release suffix (-rc1) sorts
+// before the release itself.
+func newerVersion(a, b string) bool {
+	pa, prea := splitVersion(a)
+	pb, preb := splitVersion(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return prea == "" && preb != ""
+}
+
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, pre, _ := strings.Cut(v, "-")
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+	return parts, pre
+}
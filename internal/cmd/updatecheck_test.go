@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0.2.0", "0.1.9", true},
+		{"v0.10.0", "0.9.3", true},
+		{"1.0", "1.0.0", false},
+		{"0.1.4", "v0.1.4", false},
+		{"0.1.3", "0.1.4", false},
+		{"0.2.0", "0.2.0-rc1", true},
+		{"0.2.0-rc2", "0.2.0", false},
+	}
+	for _, tt := range tests {
+		if got := newerVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	requests := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cli/releases/latest" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Expected the update check to be sent without credentials")
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claudevps.CLIRelease{Version: "v0.3.0"})
+	}))
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	checkForUpdate(cfg, "0.2.1", now, &out)
+	if !strings.Contains(out.String(), "0.2.1 → 0.3.0") {
+		t.Errorf("Expected upgrade hint, got %q", out.String())
+	}
+	if check, err := cache.LoadUpdateCheck(); err != nil || check == nil || check.LatestVersion != "v0.3.0" {
+		t.Errorf("Expected check to be cached, got %+v, %v", check, err)
+	}
+
+	// Within a day nothing is fetched or printed
+	out.Reset()
+	checkForUpdate(cfg, "0.2.1", now.Add(time.Hour), &out)
+	if requests != 1 || out.Len() != 0 {
+		t.Errorf("Expected no check within a day, got %d requests and %q", requests, out.String())
+	}
+
+	// Up to date: checked again the next day, but nothing to say
+	checkForUpdate(cfg, "0.3.0", now.Add(25*time.Hour), &out)
+	if requests != 2 || out.Len() != 0 {
+		t.Errorf("Expected a silent check the next day, got %d requests and %q", requests, out.String())
+	}
+}
+
+func TestUpgradeHint(t *testing.T) {
+	if got := upgradeHint("/opt/homebrew/Cellar/cvps/0.3.0/bin/cvps", ""); !strings.Contains(got, "brew upgrade cvps") {
+		t.Errorf("upgradeHint(Homebrew) = %q", got)
+	}
+	if got := upgradeHint("/usr/local/bin/cvps", ""); !strings.Contains(got, latestReleaseURL) {
+		t.Errorf("upgradeHint(release binary) = %q", got)
+	}
+	url := "https://github.com/Achronon/cvps/releases/tag/v0.3.0"
+	if got := upgradeHint("/home/dev/go/bin/cvps", url); !strings.Contains(got, url) || strings.Contains(got, "brew") {
+		t.Errorf("upgradeHint(go install) = %q", got)
+	}
+}
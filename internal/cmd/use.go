@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use [sandbox]",
+	Short: "Switch the current sandbox of this project",
+	Long: `Select which of the project's sandboxes commands use by default.
+
+Each 'cvps up' in a directory adds the new sandbox to .cvps.yaml and makes it
+current, so a project can keep for example a dev and a test sandbox side by
+side. Without arguments, lists the project's sandboxes.`,
+	Example: `  # List the project's sandboxes
+  cvps use
+
+  # Make the test sandbox current
+  cvps use test`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUse,
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+}
+
+func runUse(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return listLocalSandboxes()
+	}
+
+	ref := strings.TrimSpace(args[0])
+	var selected localctx.Entry
+	err := updateLocalContext(func(c *localctx.Context) error {
+		if len(c.Sandboxes) == 0 {
+			return fmt.Errorf("no sandboxes in this project. Run 'cvps up' first")
+		}
+		e := c.Find(ref)
+		if e == nil {
+			return fmt.Errorf("sandbox %q is not part of this project (have: %s)", ref, strings.Join(localEntryNames(c), ", "))
+		}
+		c.Current = e.SandboxID
+		selected = *e
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Now using sandbox '%s' (%s)\n", selected.Name, selected.SandboxID)
+	return nil
+}
+
+func listLocalSandboxes() error {
+	c, err := localctx.Load(".")
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		fmt.Println("No sandboxes in this project. Run 'cvps up' to create one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tID\tCREATED")
+	for _, e := range c.Sandboxes {
+		mark := ""
+		if e.SandboxID == c.Current {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, e.Name, e.SandboxID, formatTime(e.CreatedAt))
+	}
+	w.Flush()
+	return nil
+}
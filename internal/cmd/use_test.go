@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRunUse(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	if err := runUse(nil, []string{"dev"}); err == nil {
+		t.Error("Expected error without a project context")
+	}
+
+	saveLocalContext("sbx-dev", "dev")
+	saveLocalContext("sbx-test", "test")
+
+	if id, _ := getCurrentSandboxID(); id != "sbx-test" {
+		t.Errorf("Expected newest sandbox to be current, got %s", id)
+	}
+
+	if err := runUse(nil, []string{"dev"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id, _ := getCurrentSandboxID(); id != "sbx-dev" {
+		t.Errorf("Expected sbx-dev to be current, got %s", id)
+	}
+
+	if err := runUse(nil, []string{"staging"}); err == nil {
+		t.Error("Expected error for sandbox outside the project")
+	}
+
+	// Terminating the current sandbox leaves the other one selected
+	cleanupLocalContext("sbx-dev")
+	if id, _ := getCurrentSandboxID(); id != "sbx-test" {
+		t.Errorf("Expected sbx-test to be current after cleanup, got %s", id)
+	}
+}
@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/vault"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// vaultDefaultTimeout is how long an unlocked vault stays unlocked
+const vaultDefaultTimeout = 8 * time.Hour
+
+// vaultPassphraseAttempts is how many times a wrong passphrase may be retried
+const vaultPassphraseAttempts = 3
+
+var (
+	vaultTimeout  time.Duration
+	vaultForce    bool
+	vaultAgentTTL time.Duration
+)
+
+// vaultSessionKey caches the vault key for the rest of this process
+var vaultSessionKey []byte
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Encrypt local credentials with a passphrase",
+	Long: `Encrypt the API key and access token in config.yaml, and secret files made
+with 'cvps secrets encrypt', with a key derived from a passphrase.
+
+Useful where no OS keychain protects the config file, such as shared or
+headless machines. Once unlocked, a small agent process keeps the key in
+memory so the passphrase is asked for once per session; it forgets the key
+after the unlock timeout or on 'cvps vault lock'.`,
+}
+
+var vaultInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Choose a passphrase and encrypt stored credentials",
+	Args:  cobra.NoArgs,
+	RunE:  runVaultInit,
+}
+
+var vaultUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock the vault for this session",
+	Example: `  # Unlock for the working day
+  cvps vault unlock --timeout 10h`,
+	Args: cobra.NoArgs,
+	RunE: runVaultUnlock,
+}
+
+var vaultLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Forget the vault key now",
+	Args:  cobra.NoArgs,
+	RunE:  runVaultLock,
+}
+
+var vaultStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the vault is set up and unlocked",
+	Args:  cobra.NoArgs,
+	RunE:  runVaultStatus,
+}
+
+var vaultDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Store credentials unencrypted again",
+	Args:  cobra.NoArgs,
+	RunE:  runVaultDisable,
+}
+
+var vaultAgentCmd = &cobra.Command{
+	Use:    "agent",
+	Short:  "Hold the vault key for other cvps processes",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runVaultAgent,
+}
+
+func init() {
+	config.KeyProvider = vaultKey
+
+	rootCmd.AddCommand(vaultCmd)
+	vaultCmd.AddCommand(vaultInitCmd)
+	vaultCmd.AddCommand(vaultUnlockCmd)
+	vaultCmd.AddCommand(vaultLockCmd)
+	vaultCmd.AddCommand(vaultStatusCmd)
+	vaultCmd.AddCommand(vaultDisableCmd)
+	vaultCmd.AddCommand(vaultAgentCmd)
+
+	vaultUnlockCmd.Flags().DurationVar(&vaultTimeout, "timeout", vaultDefaultTimeout, "how long to stay unlocked")
+	vaultDisableCmd.Flags().BoolVarP(&vaultForce, "force", "f", false, "skip confirmation prompt")
+	vaultAgentCmd.Flags().DurationVar(&vaultAgentTTL, "ttl", vaultDefaultTimeout, "how long to hold the key")
+}
+
+func vaultSocketPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vault-agent.sock"), nil
+}
+
+// vaultKey returns the key for enc from this process, the agent, or a
+// passphrase prompt, in that order. A prompted key is handed to a new agent.
+func vaultKey(enc *config.EncryptionConfig) ([]byte, error) {
+	if vaultSessionKey != nil && vault.Verify(vaultSessionKey, enc.Check) == nil {
+		return vaultSessionKey, nil
+	}
+	if socket, err := vaultSocketPath(); err == nil {
+		if key, err := vault.AgentKey(socket); err == nil && vault.Verify(key, enc.Check) == nil {
+			vaultSessionKey = key
+			return key, nil
+		}
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("credentials are encrypted and the vault is locked. Run 'cvps vault unlock' first")
+	}
+	key, err := promptVaultKey(enc)
+	if err != nil {
+		return nil, err
+	}
+	if err := startVaultAgent(key, vaultDefaultTimeout); err != nil {
+		debuglog.Printf("vault: %v", err)
+	}
+	vaultSessionKey = key
+	return key, nil
+}
+
+// readPassphrase reads a passphrase from the terminal without echo. Prompts go
+// to stderr so they do not mix with command output.
+func readPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return data, nil
+}
+
+// promptVaultKey asks for the passphrase of enc until it is right
+func promptVaultKey(enc *config.EncryptionConfig) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		passphrase, err := readPassphrase("Vault passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		key, err := vault.DeriveKey(passphrase, enc.Salt, enc.Check)
+		if !errors.Is(err, vault.ErrWrongPassphrase) || attempt == vaultPassphraseAttempts {
+			return key, err
+		}
+		fmt.Fprintln(os.Stderr, "Wrong passphrase, try again.")
+	}
+}
+
+// startVaultAgent runs 'cvps vault agent' detached, handing it key over
+// stdin, and waits until it answers. It is a variable so tests do not start
+// processes.
+var startVaultAgent = func(key []byte, ttl time.Duration) error {
+	socket, err := vaultSocketPath()
+	if err != nil {
+		return err
+	}
+	// Replace any agent holding an older key
+	vault.LockAgent(socket)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(exe, "vault", "agent", "--ttl", ttl.String())
+	c.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(key) + "\n")
+	detachProcess(c)
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to start vault agent: %w", err)
+	}
+	c.Process.Release()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := vault.AgentKey(socket); err == nil {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("vault agent did not start")
+}
+
+func runVaultAgent(cmd *cobra.Command, args []string) error {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+	if err != nil || len(key) != vault.KeySize {
+		return fmt.Errorf("invalid key")
+	}
+
+	socket, err := vaultSocketPath()
+	if err != nil {
+		return err
+	}
+	os.Remove(socket)
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+	if err := os.Chmod(socket, 0600); err != nil {
+		ln.Close()
+		return err
+	}
+	return vault.ServeAgent(ln, key, vaultAgentTTL)
+}
+
+func runVaultInit(cmd *cobra.Command, args []string) error {
+	enc, err := config.LoadEncryption()
+	if err != nil {
+		return err
+	}
+	if enc != nil {
+		return fmt.Errorf("the vault is already set up. Use 'cvps vault disable' first to choose a new passphrase")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("'cvps vault init' needs a terminal to read the passphrase")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase("New vault passphrase: ")
+	if err != nil {
+		return err
+	}
+	if len(passphrase) < 8 {
+		return fmt.Errorf("passphrase must be at least 8 characters")
+	}
+	confirm, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return fmt.Errorf("passphrases do not match")
+	}
+
+	key, salt, check, err := vault.NewKey(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to create vault key: %w", err)
+	}
+	vaultSessionKey = key
+	cfg.Encryption = &config.EncryptionConfig{Salt: salt, Check: check}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	if err := startVaultAgent(key, vaultDefaultTimeout); err != nil {
+		color.Yellow("⚠ %v; you will be asked for the passphrase on every command", err)
+	}
+
+	fmt.Println("✓ Vault set up; credentials in config.yaml are now encrypted")
+	fmt.Println("  There is no way to recover the passphrase. If you lose it, run 'cvps login' again.")
+	return nil
+}
+
+// requireVault returns the vault parameters, or an error if there is no vault
+func requireVault() (*config.EncryptionConfig, error) {
+	enc, err := config.LoadEncryption()
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("no vault is set up. Run 'cvps vault init' first")
+	}
+	return enc, nil
+}
+
+func runVaultUnlock(cmd *cobra.Command, args []string) error {
+	if vaultTimeout < time.Minute {
+		return fmt.Errorf("--timeout must be at least 1m")
+	}
+	enc, err := requireVault()
+	if err != nil {
+		return err
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("'cvps vault unlock' needs a terminal to read the passphrase")
+	}
+
+	key, err := promptVaultKey(enc)
+	if err != nil {
+		return err
+	}
+	if err := startVaultAgent(key, vaultTimeout); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Vault unlocked for %s\n", shortDuration(vaultTimeout.Round(time.Minute)))
+	return nil
+}
+
+func runVaultLock(cmd *cobra.Command, args []string) error {
+	socket, err := vaultSocketPath()
+	if err != nil {
+		return err
+	}
+	if err := vault.LockAgent(socket); err != nil {
+		if errors.Is(err, vault.ErrNoAgent) {
+			fmt.Println("Vault is already locked")
+			return nil
+		}
+		return err
+	}
+	fmt.Println("✓ Vault locked")
+	return nil
+}
+
+func runVaultStatus(cmd *cobra.Command, args []string) error {
+	enc, err := config.LoadEncryption()
+	if err != nil {
+		return err
+	}
+	if enc == nil {
+		fmt.Println("Vault: not set up (credentials are stored unencrypted; see 'cvps vault init')")
+		return nil
+	}
+
+	socket, err := vaultSocketPath()
+	if err != nil {
+		return err
+	}
+	if key, err := vault.AgentKey(socket); err == nil && vault.Verify(key, enc.Check) == nil {
+		fmt.Println("Vault: unlocked")
+	} else {
+		fmt.Println("Vault: locked")
+	}
+	return nil
+}
+
+func runVaultDisable(cmd *cobra.Command, args []string) error {
+	if _, err := requireVault(); err != nil {
+		return err
+	}
+
+	if !vaultForce {
+		fmt.Println("Credentials in config.yaml will be stored unencrypted, and files made with")
+		fmt.Println("'cvps secrets encrypt' can no longer be decrypted.")
+		fmt.Print("Continue? [y/N]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.Encryption = nil
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	if socket, err := vaultSocketPath(); err == nil {
+		vault.LockAgent(socket)
+	}
+	vaultSessionKey = nil
+
+	fmt.Println("✓ Vault disabled; credentials are stored unencrypted")
+	return nil
+}
+
+// openSealedSecret decrypts a secret value produced by 'cvps secrets encrypt'
+func openSealedSecret(sealed string) (string, error) {
+	enc, err := config.LoadEncryption()
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return "", fmt.Errorf("secret is encrypted but no vault is set up")
+	}
+	key, err := vaultKey(enc)
+	if err != nil {
+		return "", err
+	}
+	plain, err := vault.Open(key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
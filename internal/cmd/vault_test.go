@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/vault"
+)
+
+// setupVault creates a vault in a fresh home directory and unlocks it for
+// this process
+func setupVault(t *testing.T) *config.EncryptionConfig {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	oldStart, oldKey := startVaultAgent, vaultSessionKey
+	startVaultAgent = func([]byte, time.Duration) error { return nil }
+	t.Cleanup(func() { startVaultAgent, vaultSessionKey = oldStart, oldKey })
+
+	key, salt, check, err := vault.NewKey([]byte("correct horse"))
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	vaultSessionKey = key
+
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "test-key"
+	cfg.Encryption = &config.EncryptionConfig{Salt: salt, Check: check}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	return cfg.Encryption
+}
+
+func TestSecretsEncryptRoundTrip(t *testing.T) {
+	setupVault(t)
+
+	path := filepath.Join(t.TempDir(), "server.key")
+	os.WriteFile(path, []byte("-----BEGIN KEY-----\n"), 0600)
+
+	secretsOutput = ""
+	if _, err := captureStdout(t, func() error { return runSecretsEncrypt(nil, []string{path}) }); err != nil {
+		t.Fatalf("runSecretsEncrypt() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path + ".enc")
+	if err != nil {
+		t.Fatalf("encrypted file not written: %v", err)
+	}
+	if !vault.IsSealed(string(data)) || strings.Contains(string(data), "BEGIN KEY") {
+		t.Fatalf("encrypted file = %q, want sealed value", data)
+	}
+
+	got, err := readSecretValue(path+".enc", nil, false)
+	if err != nil {
+		t.Fatalf("readSecretValue(encrypted file) error = %v", err)
+	}
+	if got != "-----BEGIN KEY-----\n" {
+		t.Errorf("readSecretValue(encrypted file) = %q", got)
+	}
+
+	// Encrypting twice is refused
+	if _, err := captureStdout(t, func() error { return runSecretsEncrypt(nil, []string{path + ".enc"}) }); err == nil {
+		t.Error("runSecretsEncrypt(encrypted file) expected error")
+	}
+}
+
+func TestConfigLoadWithVault(t *testing.T) {
+	setupVault(t)
+
+	raw, _ := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".cvps", "config.yaml"))
+	if strings.Contains(string(raw), "test-key") {
+		t.Fatal("API key stored unencrypted")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.APIKey != "test-key" {
+		t.Errorf("APIKey = %q, want decrypted key", cfg.APIKey)
+	}
+
+	// Without a cached key or a terminal to prompt on, the vault stays locked
+	vaultSessionKey = nil
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "cvps vault unlock") {
+		t.Errorf("Load() while locked error = %v, want unlock hint", err)
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of 'cvps wait' other than 0 and the usual 1 for errors
+const (
+	exitWaitFailed  = 2   // the sandbox can no longer reach the state
+	exitWaitTimeout = 124 // as timeout(1)
+)
+
+// waitStates are the states 'cvps wait --for' accepts
+var waitStates = []string{"running", "stopped", "deleted"}
+
+var waitPollInterval = 2 * time.Second
+
+var (
+	waitFor     string
+	waitTimeout time.Duration
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <sandbox>",
+	Short: "Wait until a sandbox is running, stopped or deleted",
+	Long: `Block until a sandbox reaches a state, for scripts that start, stop or delete
+sandboxes and need to know when it happened.
+
+Changes are followed on the sandbox's event stream; against servers without
+one, the status is polled every few seconds.
+
+With --for deleted, a sandbox that no longer exists counts as deleted only
+when given by ID or by a name cvps has seen before, so a mistyped name fails.
+
+Exit status:
+  0    the sandbox reached the state
+  1    the wait could not be carried out (e.g. the API was unreachable)
+  2    the sandbox can no longer reach the state: it failed, or was deleted
+       while waiting for running or stopped
+  124  --timeout passed first`,
+	Example: `  # Create without waiting, then block on it when it is needed
+  SANDBOX=$(cvps up --detach --output json | jq -r .id)
+  cvps wait "$SANDBOX" --for running
+
+  # Give a deletion up to 10 minutes
+  cvps down web --force --no-wait && cvps wait web --for deleted --timeout 10m`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runWait,
+	ValidArgsFunction: completeSandboxes,
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	waitCmd.Flags().StringVar(&waitFor, "for", "running", "state to wait for ("+strings.Join(waitStates, ", ")+")")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 5*time.Minute, "how long to wait, or 0 to wait indefinitely")
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	target := strings.ToLower(strings.TrimSpace(waitFor))
+	if !slices.Contains(waitStates, target) {
+		return fmt.Errorf("invalid --for %q (must be %s)", waitFor, strings.Join(waitStates, ", "))
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+		defer cancel()
+	}
+
+	// Checked first, as resolving a name refreshes the sandbox cache
+	known := knownSandboxRef(args[0])
+
+	// No fuzzy matching: waiting on the wrong sandbox would pass silently
+	sandboxID, err := resolveSandbox(ctx, client, args[0], false)
+	var notFound *sandboxNotFoundError
+	switch {
+	case target == "deleted" && known && errors.As(err, &notFound):
+		fmt.Printf("✓ %s is deleted\n", args[0])
+		return nil
+	case err != nil:
+		return err
+	}
+
+	w := &stateWaiter{client: client, id: sandboxID, ref: args[0], target: target}
+	if err := w.wait(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("✓ %s is %s\n", args[0], target)
+	return nil
+}
+
+// knownSandboxRef reports whether ref is a full sandbox ID or names a sandbox
+// cvps has seen, in the sandbox cache or among pending deletions. Only then
+// does a sandbox that cannot be found count as deleted; anything else may
+// just be a typo.
+func knownSandboxRef(ref string) bool {
+	ref = strings.TrimSpace(ref)
+	if looksLikeSandboxID(ref) && !isCachedIDPrefix(ref) {
+		return true
+	}
+	if c, err := cache.LoadSandboxes(); err == nil && c != nil && c.Find(ref) != nil {
+		return true
+	}
+	pending, _ := cache.LoadPendingDeletions()
+	for _, p := range pending {
+		if p.SandboxID == ref || strings.EqualFold(p.Name, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// stateWaiter waits for one sandbox to reach a target state
+type stateWaiter struct {
+	client *claudevps.Client
+	id     string
+	ref    string
+	target string
+
+	last string // the last status seen, for the timeout message
+}
+
+// wait follows the event stream if the server has one and polls otherwise,
+// or once the stream ends early
+func (w *stateWaiter) wait(ctx context.Context) error {
+	events, err := w.client.SandboxEvents(ctx, w.id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return w.timedOut()
+		}
+		debuglog.Printf("no event stream, polling: %v", err)
+		return w.poll(ctx)
+	}
+	done, err := w.follow(ctx, events)
+	events.Close()
+	if done || err != nil {
+		return err
+	}
+	return w.poll(ctx)
+}
+
+// follow reads events until the target state is reached, reporting false
+// with no error if the stream ends first
+func (w *stateWaiter) follow(ctx context.Context, events *claudevps.SandboxEventStream) (bool, error) {
+	// The state may have been reached before the stream was opened
+	if done, err := w.check(ctx); done || err != nil {
+		return done, err
+	}
+	for {
+		e, err := events.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, w.timedOut()
+			}
+			debuglog.Printf("event stream ended: %v", err)
+			return false, nil
+		}
+		switch e.Type {
+		case claudevps.SandboxEventStatus:
+			if done, err := w.observe(e.Status, e.Reason); done || err != nil {
+				return done, err
+			}
+		case claudevps.SandboxEventDeleted:
+			return w.observe("deleted", "")
+		}
+	}
+}
+
+func (w *stateWaiter) poll(ctx context.Context) error {
+	for {
+		if done, err := w.check(ctx); done || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return w.timedOut()
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// check fetches the sandbox's status and observes it
+func (w *stateWaiter) check(ctx context.Context) (bool, error) {
+	s, err := w.client.GetSandboxStatus(ctx, w.id)
+	switch {
+	case claudevps.IsNotFound(err):
+		return w.observe("deleted", "")
+	case err != nil && ctx.Err() != nil:
+		return false, w.timedOut()
+	case err != nil:
+		return false, fmt.Errorf("failed to get status: %w", err)
+	}
+	return w.observe(s.Status, s.FailureDetail())
+}
+
+// observe reports whether status is the target state, or an error if the
+// sandbox can no longer get there
+func (w *stateWaiter) observe(status, reason string) (bool, error) {
+	w.last = status
+	debuglog.Printf("%s is %s", w.ref, status)
+	switch {
+	case strings.EqualFold(status, w.target):
+		return true, nil
+	case w.target == "deleted":
+		return false, nil
+	case status == "deleted":
+		return false, &exitCodeError{Code: exitWaitFailed, Err: fmt.Errorf("%s was deleted while waiting for it to be %s", w.ref, w.target)}
+	case status == "failed" || status == "error":
+		msg := fmt.Sprintf("%s failed while waiting for it to be %s", w.ref, w.target)
+		if reason != "" {
+			msg += ": " + reason
+		}
+		return false, &exitCodeError{Code: exitWaitFailed, Err: errors.New(msg)}
+	}
+	return false, nil
+}
+
+func (w *stateWaiter) timedOut() error {
+	msg := fmt.Sprintf("timed out after %s waiting for %s to be %s", waitTimeout, w.ref, w.target)
+	if w.last != "" {
+		msg += " (last status: " + w.last + ")"
+	}
+	return &exitCodeError{Code: exitWaitTimeout, Err: errors.New(msg)}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/cache"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func setWaitFlags(t *testing.T, state string, timeout time.Duration) {
+	t.Helper()
+	oldInterval := waitPollInterval
+	waitPollInterval = 10 * time.Millisecond
+	waitFor, waitTimeout = state, timeout
+	t.Cleanup(func() {
+		waitPollInterval = oldInterval
+		waitFor, waitTimeout = "running", 5*time.Minute
+	})
+}
+
+func TestRunWait_Polls(t *testing.T) {
+	polls := 0
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/sbx-abc123/events":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not_found"})
+		case "/sandboxes/sbx-abc123/status":
+			polls++
+			status := "starting"
+			if polls > 2 {
+				status = "running"
+			}
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-abc123", Status: status})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	setWaitFlags(t, "running", time.Minute)
+
+	out, err := captureStdout(t, func() error { return runWait(nil, []string{"sbx-abc123"}) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if polls != 3 || !strings.Contains(out, "sbx-abc123 is running") {
+		t.Errorf("Unexpected result after %d polls: %q", polls, out)
+	}
+}
+
+func TestRunWait_Events(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/sbx-abc123/events":
+			fmt.Fprintln(w, `{"type":"status","status":"stopping"}`)
+			fmt.Fprintln(w, `{"type":"deleted"}`)
+		case "/sandboxes/sbx-abc123/status":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-abc123", Status: "running"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	setWaitFlags(t, "deleted", time.Minute)
+	if _, err := captureStdout(t, func() error { return runWait(nil, []string{"sbx-abc123"}) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	setWaitFlags(t, "stopped", time.Minute)
+	err := runWait(nil, []string{"sbx-abc123"})
+	if exitCode(err) != exitWaitFailed {
+		t.Errorf("Expected exit code %d for a deleted sandbox, got %d: %v", exitWaitFailed, exitCode(err), err)
+	}
+}
+
+func TestRunWait_FailedAndTimeout(t *testing.T) {
+	status := "failed"
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/sbx-abc123/events":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not_found"})
+		case "/sandboxes/sbx-abc123/status":
+			json.NewEncoder(w).Encode(claudevps.Sandbox{ID: "sbx-abc123", Status: status, FailureMessage: "out of capacity"})
+		}
+	}))
+
+	setWaitFlags(t, "running", time.Minute)
+	err := runWait(nil, []string{"sbx-abc123"})
+	if exitCode(err) != exitWaitFailed || !strings.Contains(err.Error(), "out of capacity") {
+		t.Errorf("Expected a failure with the reason, got %d: %v", exitCode(err), err)
+	}
+
+	status = "stopping"
+	setWaitFlags(t, "stopped", 50*time.Millisecond)
+	err = runWait(nil, []string{"sbx-abc123"})
+	if exitCode(err) != exitWaitTimeout || !strings.Contains(err.Error(), "last status: stopping") {
+		t.Errorf("Expected a timeout, got %d: %v", exitCode(err), err)
+	}
+}
+
+func TestRunWait_DeletedName(t *testing.T) {
+	setupFakeAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		json.NewEncoder(w).Encode(claudevps.SandboxList{Data: []claudevps.Sandbox{}})
+	}))
+	setWaitFlags(t, "deleted", time.Minute)
+
+	// A name cvps has never seen may be a typo
+	err := runWait(nil, []string{"wbe"})
+	var notFound *sandboxNotFoundError
+	if !errors.As(err, &notFound) || exitCode(err) != 1 {
+		t.Errorf("Expected not found with exit code 1, got %d: %v", exitCode(err), err)
+	}
+
+	if err := cache.SaveSandboxes([]claudevps.Sandbox{{ID: "sbx-abc123", Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureStdout(t, func() error { return runWait(nil, []string{"web"}) })
+	if err != nil || !strings.Contains(out, "web is deleted") {
+		t.Errorf("Expected a cached name to count as deleted, got %q, %v", out, err)
+	}
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/migration"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/internal/remote"
+	"github.com/achronon/cvps/internal/sftp"
+	"github.com/achronon/cvps/internal/watch"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchPath       string
+	watchRemoteDir  string
+	watchSandboxRef string
+	watchDebounce   time.Duration
+	watchNoSync     bool
+	watchIgnore     []string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [flags] -- <command>",
+	Short: "Rerun a remote command when local files change",
+	Long: `Watch local files and rerun a command in the sandbox whenever they change.
+
+Changes are synced before each run: through the Mutagen session for the
+sandbox when Mutagen is installed (one is started if needed), otherwise by
+uploading changed files over SFTP. A run still in progress when new changes
+arrive is stopped and restarted.
+
+Sync ignore patterns from the config apply to the watched files.`,
+	Example: `  # Rerun tests on every change
+  cvps watch -- go test ./...
+
+  # Run a shell pipeline in a subdirectory
+  cvps watch --remote-dir /workspace/web -- "npm run build && npm test"
+
+  # Only rerun, files are synced some other way
+  cvps watch --no-sync -- make`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVar(&watchPath, "path", ".", "local directory to watch")
+	watchCmd.Flags().StringVar(&watchRemoteDir, "remote-dir", "/workspace", "remote directory the command runs in")
+	watchCmd.Flags().StringVarP(&watchSandboxRef, "sandbox", "s", "", "sandbox ID or name (defaults to current context)")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 300*time.Millisecond, "quiet period before rerunning")
+	watchCmd.Flags().BoolVar(&watchNoSync, "no-sync", false, "do not sync files before running")
+	watchCmd.Flags().StringSliceVar(&watchIgnore, "ignore", nil, "additional patterns to ignore")
+}
+
+// watchCommandLine joins argv into a remote command. A single argument is
+// passed through unquoted so shell syntax such as pipes keeps working.
+func watchCommandLine(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = remote.Quote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	localRoot, err := filepath.Abs(watchPath)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IsAuthenticated() {
+		return fmt.Errorf("not logged in. Run 'cvps login' first")
+	}
+
+	client := newClientFromConfig(cfg)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sandboxID, err := resolveSandboxRef(ctx, client, watchSandboxRef)
+	if err != nil {
+		return err
+	}
+
+	conn, sandbox, err := openSandboxSSH(ctx, client, sandboxID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ignores := append(cfg.Sync.IgnorePatterns, watchIgnore...)
+	scanner := migration.NewScanner(localRoot, ignores)
+
+	syncer, err := newWatchSyncer(conn, sandbox, localRoot, ignores)
+	if err != nil {
+		return err
+	}
+	defer syncer.close()
+
+	w, err := watch.New(localRoot, watchDebounce, func(rel string, isDir bool) bool {
+		if isDir {
+			return scanner.ExcludesDir(rel)
+		}
+		return scanner.Excludes(rel)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", localRoot, err)
+	}
+	defer w.Close()
+
+	runner := &remoteRunner{
+		conn:    conn,
+		command: "cd " + remote.Quote(watchRemoteDir) + " && " + watchCommandLine(args),
+	}
+	defer runner.stop()
+
+	fmt.Printf("Watching %s, running on %s: %s\n", localRoot, sandbox.Name, watchCommandLine(args))
+	fmt.Println("Press Ctrl+C to stop.")
+	runner.restart(ctx)
+
+	err = w.Run(ctx, func(paths []string) {
+		fmt.Println()
+		color.Cyan("↻ %d file(s) changed", len(paths))
+		runner.stop()
+		if err := syncer.sync(paths); err != nil {
+			color.Yellow("⚠ Sync failed: %v", err)
+		}
+		runner.restart(ctx)
+	})
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("\nStopping watch...")
+		return nil
+	}
+	return err
+}
+
+// remoteRunner runs one remote command at a time, restarting it on demand
+type remoteRunner struct {
+	conn    *remote.Client
+	command string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *remoteRunner) restart(parent context.Context) {
+	r.stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	r.cancel, r.done = cancel, done
+
+	go func() {
+		defer close(done)
+		start := time.Now()
+		err := r.conn.Run(ctx, r.command, nil, os.Stdout, os.Stderr)
+
+		elapsed := time.Since(start).Round(10 * time.Millisecond)
+		var exitErr *remote.ExitError
+		switch {
+		case ctx.Err() != nil:
+		case err == nil:
+			color.Green("✓ Finished in %s", elapsed)
+		case errors.As(err, &exitErr):
+			color.Red("✗ Exited with status %d after %s", exitErr.Status, elapsed)
+		default:
+			color.Red("✗ %v", err)
+		}
+	}()
+}
+
+func (r *remoteRunner) stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// watchSyncer propagates local changes before each run
+type watchSyncer struct {
+	session   string
+	created   bool
+	store     *sftpStore
+	localRoot string
+}
+
+func newWatchSyncer(conn *remote.Client, sandbox *claudevps.Sandbox, localRoot string, ignores []string) (*watchSyncer, error) {
+	s := &watchSyncer{localRoot: localRoot}
+	if watchNoSync {
+		return s, nil
+	}
+
+	if mutagen.IsInstalled() {
+		s.session = fmt.Sprintf("cvps-%s", sandbox.ID)
+		if _, err := mutagen.GetSessionStatus(s.session); err == nil {
+			return s, nil
+		}
+
+		fmt.Println("Starting sync session...")
+		_, err := mutagen.CreateSession(mutagen.SessionConfig{
+			Name:       s.session,
+			LocalPath:  localRoot,
+			RemoteHost: fmt.Sprintf("%s@%s", sandbox.SSHUser, sandbox.SSHHost),
+			RemotePort: sandbox.SSHPort,
+			RemotePath: watchRemoteDir,
+			Ignores:    ignores,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sync session: %w", err)
+		}
+		s.created = true
+		return s, nil
+	}
+
+	sc, err := sftp.NewClient(conn.SSH())
+	if err != nil {
+		return nil, err
+	}
+	s.store = &sftpStore{sc}
+	return s, nil
+}
+
+func (s *watchSyncer) sync(paths []string) error {
+	switch {
+	case s.session != "":
+		return mutagen.FlushSession(s.session)
+	case s.store != nil:
+		return pushChanges(s.store, s.localRoot, watchRemoteDir, paths)
+	}
+	return nil
+}
+
+func (s *watchSyncer) close() {
+	if s.created {
+		_ = mutagen.TerminateSession(s.session)
+	}
+	if s.store != nil {
+		s.store.Close()
+	}
+}
+
+// pushChanges uploads changed files and removes remote copies of deleted ones
+func pushChanges(store remoteStore, localRoot, remoteRoot string, paths []string) error {
+	for _, rel := range paths {
+		local := filepath.Join(localRoot, filepath.FromSlash(rel))
+		target := path.Join(remoteRoot, rel)
+
+		info, err := os.Stat(local)
+		if os.IsNotExist(err) {
+			if err := store.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if err := store.MkdirAll(path.Dir(target)); err != nil {
+			return err
+		}
+		if _, err := store.Upload(local, target, info); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memoryStore records remoteStore calls for tests
+type memoryStore struct {
+	uploaded []string
+	removed  []string
+	dirs     []string
+}
+
+func (m *memoryStore) Stat(p string) (os.FileInfo, error)      { return nil, os.ErrNotExist }
+func (m *memoryStore) ReadDir(p string) ([]os.FileInfo, error) { return nil, nil }
+func (m *memoryStore) MkdirAll(p string) error                 { m.dirs = append(m.dirs, p); return nil }
+func (m *memoryStore) Resolve(p string) (string, error)        { return p, nil }
+func (m *memoryStore) Close() error                            { return nil }
+
+func (m *memoryStore) Remove(p string) error {
+	m.removed = append(m.removed, p)
+	return nil
+}
+
+func (m *memoryStore) Upload(localPath, remotePath string, info os.FileInfo) (int64, error) {
+	m.uploaded = append(m.uploaded, remotePath)
+	return info.Size(), nil
+}
+
+func (m *memoryStore) Download(remotePath, localPath string, info os.FileInfo) (int64, error) {
+	return 0, nil
+}
+
+func TestPushChanges(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0644)
+
+	store := &memoryStore{}
+	err := pushChanges(store, root, "/workspace", []string{"src/main.go", "deleted.txt"})
+	if err != nil {
+		t.Fatalf("pushChanges() error = %v", err)
+	}
+
+	if strings.Join(store.uploaded, ",") != "/workspace/src/main.go" {
+		t.Errorf("uploaded = %v", store.uploaded)
+	}
+	if strings.Join(store.removed, ",") != "/workspace/deleted.txt" {
+		t.Errorf("removed = %v", store.removed)
+	}
+	if strings.Join(store.dirs, ",") != "/workspace/src" {
+		t.Errorf("dirs = %v", store.dirs)
+	}
+}
+
+func TestWatchCommandLine(t *testing.T) {
+	if got := watchCommandLine([]string{"npm run build && npm test"}); got != "npm run build && npm test" {
+		t.Errorf("single argument = %q, want passed through", got)
+	}
+	if got := watchCommandLine([]string{"go", "test", "./..."}); got != "go test ./..." {
+		t.Errorf("argv = %q", got)
+	}
+	if got := watchCommandLine([]string{"echo", "hello world"}); got != "echo 'hello world'" {
+		t.Errorf("argv with spaces = %q", got)
+	}
+}
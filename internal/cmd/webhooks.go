@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhooksEvents      []string
+	webhooksDescription string
+	webhooksJSON        bool
+	webhooksForce       bool
+	webhooksTestEvent   string
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage webhooks for sandbox lifecycle events",
+	Long: `Manage account webhooks that are called when sandboxes change state.
+
+Supported events are created, failed, deleted and idle. Deliveries are signed
+with a secret that is shown once when the webhook is added.`,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook",
+	Example: `  # Notify on every lifecycle event
+  cvps webhooks add https://ci.example.com/hooks/cvps
+
+  # Only failures and idle sandboxes
+  cvps webhooks add https://hooks.slack.com/services/T000/B000/XXX --event failed --event idle`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksAdd,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWebhooksDelete,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <id>",
+	Short: "Send a test delivery to a webhook",
+	Example: `  # Send a test sandbox.created event
+  cvps webhooks test wh-abc123
+
+  # Test how an endpoint handles idle notifications
+  cvps webhooks test wh-abc123 --event idle`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksTest,
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksDeleteCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+
+	webhooksAddCmd.Flags().StringSliceVarP(&webhooksEvents, "event", "e", nil, "event to subscribe to (created|failed|deleted|idle, default all)")
+	webhooksAddCmd.Flags().StringVar(&webhooksDescription, "description", "", "description shown in listings")
+
+	webhooksListCmd.Flags().BoolVar(&webhooksJSON, "json", false, "output in JSON format")
+
+	webhooksDeleteCmd.Flags().BoolVarP(&webhooksForce, "force", "f", false, "skip confirmation prompt")
+
+	webhooksTestCmd.Flags().StringVarP(&webhooksTestEvent, "event", "e", "created", "event to simulate")
+}
+
+// normalizeWebhookEvents accepts short ("idle") or full ("sandbox.idle") event
+// names and returns full names. No events means all of them.
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return slices.Clone(claudevps.WebhookEvents), nil
+	}
+
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !strings.HasPrefix(e, "sandbox.") {
+			e = "sandbox." + e
+		}
+		if !slices.Contains(claudevps.WebhookEvents, e) {
+			return nil, fmt.Errorf("unknown event %q (use created, failed, deleted or idle)", strings.TrimPrefix(e, "sandbox."))
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func runWebhooksAdd(cmd *cobra.Command, args []string) error {
+	u, err := url.Parse(args[0])
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: must be an http(s) URL", args[0])
+	}
+
+	events, err := normalizeWebhookEvents(webhooksEvents)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	webhook, err := client.CreateWebhook(context.Background(), &claudevps.CreateWebhookRequest{
+		URL:         u.String(),
+		Events:      events,
+		Description: webhooksDescription,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	fmt.Printf("✓ Webhook %s added for %s\n", webhook.ID, strings.Join(webhook.Events, ", "))
+	if webhook.SigningSecret != "" {
+		fmt.Printf("\nSigning secret: %s\n", webhook.SigningSecret)
+		color.Yellow("Store it now; it will not be shown again.")
+	}
+	if u.Scheme == "http" {
+		color.Yellow("⚠ Deliveries to plain http URLs are not encrypted in transit.")
+	}
+	return nil
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := client.ListWebhooks(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	if webhooksJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(webhooks)
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("No webhooks found. Run 'cvps webhooks add <url>' to add one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL\tEVENTS\tCREATED")
+	for _, wh := range webhooks {
+		events := make([]string, len(wh.Events))
+		for i, e := range wh.Events {
+			events[i] = strings.TrimPrefix(e, "sandbox.")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wh.ID, wh.URL, strings.Join(events, ","), formatTime(wh.CreatedAt))
+	}
+	w.Flush()
+	return nil
+}
+
+func runWebhooksDelete(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	if !webhooksForce {
+		fmt.Printf("Delete webhook %s? [y/N]: ", id)
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := client.DeleteWebhook(context.Background(), id); err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("webhook not found: %s", id)
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	fmt.Printf("Webhook %s deleted\n", id)
+	return nil
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	events, err := normalizeWebhookEvents([]string{webhooksTestEvent})
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	delivery, err := client.TestWebhook(context.Background(), args[0], events[0])
+	if err != nil {
+		if claudevps.IsNotFound(err) {
+			return fmt.Errorf("webhook not found: %s", args[0])
+		}
+		return fmt.Errorf("failed to send test delivery: %w", err)
+	}
+
+	if !delivery.Success {
+		if delivery.StatusCode != 0 {
+			return fmt.Errorf("test delivery of %s failed: endpoint responded with HTTP %d", events[0], delivery.StatusCode)
+		}
+		return fmt.Errorf("test delivery of %s failed: %s", events[0], delivery.Error)
+	}
+
+	color.Green("✓ Delivered %s (HTTP %d in %dms)", events[0], delivery.StatusCode, delivery.DurationMS)
+	return nil
+}
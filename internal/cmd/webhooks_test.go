@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeWebhookEvents(t *testing.T) {
+	all, err := normalizeWebhookEvents(nil)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("normalizeWebhookEvents(nil) = %v, %v, want all 4 events", all, err)
+	}
+
+	got, err := normalizeWebhookEvents([]string{"failed", "sandbox.idle", " Failed "})
+	if err != nil {
+		t.Fatalf("normalizeWebhookEvents() error = %v", err)
+	}
+	if want := []string{"sandbox.failed", "sandbox.idle"}; !slices.Equal(got, want) {
+		t.Errorf("normalizeWebhookEvents() = %v, want %v", got, want)
+	}
+
+	if _, err := normalizeWebhookEvents([]string{"rebooted"}); err == nil {
+		t.Error("expected error for unknown event")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/achronon/cvps/internal/api"
 	"github.com/achronon/cvps/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -22,7 +21,7 @@ var whoamiCmd = &cobra.Command{
 			return fmt.Errorf("not logged in. Run 'cvps login' first")
 		}
 
-		client := api.NewClientFromConfig(cfg)
+		client := newClientFromConfig(cfg)
 		user, err := client.GetCurrentUser(context.Background())
 		if err != nil {
 			return fmt.Errorf("failed to get user info: %w", err)
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/achronon/cvps/internal/debuglog"
+	"github.com/achronon/cvps/internal/localctx"
+	"github.com/achronon/cvps/internal/workspaces"
+	"github.com/achronon/cvps/pkg/claudevps"
+	"github.com/spf13/cobra"
+)
+
+var workspacesJSON bool
+
+var workspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "Show which project directories use which sandboxes",
+	Long: `Show the registry of project directories and their sandboxes.
+
+Every directory where 'cvps up' or 'cvps use' ran is recorded in
+~/.cvps/workspaces.yaml under the directory's name, so commands such as
+'cvps connect myproject' work from anywhere.`,
+}
+
+var workspacesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List known workspaces and flag stale entries",
+	Long: `List known workspaces with the live status of each sandbox.
+
+Entries whose sandbox no longer exists or whose directory was removed are
+marked stale. Remove them with 'cvps workspaces prune'.`,
+	Args: cobra.NoArgs,
+	RunE: runWorkspacesList,
+}
+
+var workspacesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stale workspace entries",
+	Args:  cobra.NoArgs,
+	RunE:  runWorkspacesPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(workspacesCmd)
+	workspacesCmd.AddCommand(workspacesListCmd)
+	workspacesCmd.AddCommand(workspacesPruneCmd)
+
+	workspacesListCmd.Flags().BoolVar(&workspacesJSON, "json", false, "output in JSON format")
+}
+
+// workspaceSandbox is a registered sandbox with its live status
+type workspaceSandbox struct {
+	localctx.Entry
+	Status string `json:"status,omitempty"`
+	Stale  bool   `json:"stale"`
+}
+
+// workspaceStatus is a workspace checked against the API and filesystem
+type workspaceStatus struct {
+	workspaces.Workspace
+	DirMissing bool               `json:"dir_missing"`
+	Sandboxes  []workspaceSandbox `json:"sandboxes"`
+}
+
+// checkWorkspaces marks sandboxes missing from live and directories that no longer exist
+func checkWorkspaces(reg *workspaces.Registry, live []claudevps.Sandbox) []workspaceStatus {
+	status := make(map[string]string, len(live))
+	for _, s := range live {
+		status[s.ID] = s.Status
+	}
+
+	out := make([]workspaceStatus, 0, len(reg.Workspaces))
+	for _, w := range reg.Workspaces {
+		ws := workspaceStatus{Workspace: w}
+		if _, err := os.Stat(w.Dir); os.IsNotExist(err) {
+			ws.DirMissing = true
+		}
+		for _, e := range w.Sandboxes {
+			st, ok := status[e.SandboxID]
+			ws.Sandboxes = append(ws.Sandboxes, workspaceSandbox{Entry: e, Status: st, Stale: !ok})
+		}
+		out = append(out, ws)
+	}
+	return out
+}
+
+func runWorkspacesList(cmd *cobra.Command, args []string) error {
+	reg, err := workspaces.Load()
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	live, err := listAllSandboxesForConnect(context.Background(), client)
+	if err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	checked := checkWorkspaces(reg, live)
+
+	if workspacesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(checked)
+	}
+
+	if len(checked) == 0 {
+		fmt.Println("No workspaces found. Run 'cvps up' in a project directory to add one.")
+		return nil
+	}
+
+	stale := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tSANDBOX\tID\tSTATUS\tDIR")
+	for _, ws := range checked {
+		for _, s := range ws.Sandboxes {
+			name := s.Name
+			if s.SandboxID == ws.CurrentSandbox() {
+				name += " *"
+			}
+			state := s.Status
+			switch {
+			case s.Stale:
+				state = "stale (sandbox gone)"
+				stale++
+			case ws.DirMissing:
+				state = "stale (directory missing)"
+				stale++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ws.Name, name, s.SandboxID, state, ws.Dir)
+		}
+	}
+	w.Flush()
+
+	if stale > 0 {
+		fmt.Printf("\n%d stale entries. Run 'cvps workspaces prune' to remove them.\n", stale)
+	}
+	return nil
+}
+
+func runWorkspacesPrune(cmd *cobra.Command, args []string) error {
+	reg, err := workspaces.Load()
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+	live, err := listAllSandboxesForConnect(context.Background(), client)
+	if err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	removed := 0
+	for _, ws := range checkWorkspaces(reg, live) {
+		if ws.DirMissing {
+			reg.Forget(ws.Dir)
+			removed += len(ws.Sandboxes)
+			continue
+		}
+
+		var gone []string
+		for _, s := range ws.Sandboxes {
+			if s.Stale {
+				gone = append(gone, s.SandboxID)
+			}
+		}
+		if len(gone) == 0 {
+			continue
+		}
+
+		// Keep the project's own context file in step with the registry
+		var updated localctx.Context
+		err := localctx.Update(ws.Dir, func(c *localctx.Context) error {
+			for _, id := range gone {
+				c.Drop(id)
+			}
+			updated = *c
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", localctx.Path(ws.Dir), err)
+		}
+		reg.Record(ws.Dir, &updated)
+		removed += len(gone)
+	}
+
+	if err := reg.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Removed %d stale entries\n", removed)
+	return nil
+}
+
+// recordWorkspace mirrors the context of dir into the registry. Failures only
+// affect resolving the project from elsewhere, so they are logged, not returned.
+func recordWorkspace(dir string, c *localctx.Context) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		debuglog.Printf("workspaces: %v", err)
+		return
+	}
+	reg, err := workspaces.Load()
+	if err != nil {
+		debuglog.Printf("workspaces: %v", err)
+		return
+	}
+	reg.Record(abs, c)
+	if err := reg.Save(); err != nil {
+		debuglog.Printf("workspaces: %v", err)
+	}
+}
+
+// resolveWorkspaceSandbox looks ref up as a workspace name or directory and
+// returns its current sandbox. ok is false if no workspace matches.
+func resolveWorkspaceSandbox(ref string) (id string, ok bool, err error) {
+	reg, err := workspaces.Load()
+	if err != nil {
+		return "", false, err
+	}
+	if abs, err := filepath.Abs(ref); err == nil && strings.ContainsAny(ref, `/\`) {
+		ref = abs
+	}
+
+	matches := reg.Find(ref)
+	switch len(matches) {
+	case 0:
+		return "", false, nil
+	case 1:
+		w := matches[0]
+		if id := w.CurrentSandbox(); id != "" {
+			return id, true, nil
+		}
+		return "", true, fmt.Errorf("workspace %q has several sandboxes and none is current. Run 'cvps use <name>' in %s", w.Name, w.Dir)
+	default:
+		var b strings.Builder
+		fmt.Fprintf(&b, "workspace name %q is ambiguous. Use the directory instead:\n", ref)
+		for _, w := range matches {
+			fmt.Fprintf(&b, "  - %s\n", w.Dir)
+		}
+		return "", true, fmt.Errorf(strings.TrimRight(b.String(), "\n"))
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/achronon/cvps/internal/workspaces"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func TestResolveSandboxRef_Workspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", oldHome)
+
+	project := filepath.Join(tmpDir, "myproject")
+	os.Mkdir(project, 0755)
+	oldWd, _ := os.Getwd()
+	os.Chdir(project)
+	saveLocalContext("sbx-dev", "dev")
+	os.Chdir(oldWd)
+
+	// No API calls are needed for a registered workspace
+	id, err := resolveSandboxRef(context.Background(), nil, "myproject")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != "sbx-dev" {
+		t.Errorf("Expected sbx-dev, got %s", id)
+	}
+
+	if _, ok, _ := resolveWorkspaceSandbox("unknown"); ok {
+		t.Error("Expected no match for unknown workspace")
+	}
+}
+
+func TestCheckWorkspaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	reg, _ := workspaces.LoadFile(filepath.Join(tmpDir, "workspaces.yaml"))
+	reg.Workspaces = []workspaces.Workspace{
+		{Name: "live", Dir: tmpDir, Sandboxes: []LocalContext{{SandboxID: "sbx-1"}, {SandboxID: "sbx-gone"}}},
+		{Name: "moved", Dir: filepath.Join(tmpDir, "missing"), Sandboxes: []LocalContext{{SandboxID: "sbx-1"}}},
+	}
+
+	checked := checkWorkspaces(reg, []claudevps.Sandbox{{ID: "sbx-1", Status: "running"}})
+
+	if checked[0].DirMissing || checked[0].Sandboxes[0].Stale || checked[0].Sandboxes[0].Status != "running" {
+		t.Errorf("Expected live entry, got %+v", checked[0])
+	}
+	if !checked[0].Sandboxes[1].Stale {
+		t.Error("Expected deleted sandbox to be stale")
+	}
+	if !checked[1].DirMissing {
+		t.Error("Expected missing directory to be flagged")
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/spf13/viper"
+	"github.com/achronon/cvps/internal/vault"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,14 +15,97 @@ type Config struct {
 	APIKey      string `yaml:"api_key" mapstructure:"api_key"`
 	AccessToken string `yaml:"access_token,omitempty" mapstructure:"access_token"`
 
+	// Set when the credentials above are stored encrypted ('cvps vault init')
+	Encryption *EncryptionConfig `yaml:"encryption,omitempty" mapstructure:"encryption"`
+
 	// API settings
 	APIBaseURL string `yaml:"api_base_url" mapstructure:"api_base_url"`
 
+	// How long to wait for an API response, e.g. "90s" (default 30s).
+	// Streams and file transfers are not bound by it.
+	APITimeout string `yaml:"api_timeout,omitempty" mapstructure:"api_timeout"`
+
 	// Default sandbox settings
 	Defaults SandboxDefaults `yaml:"defaults" mapstructure:"defaults"`
 
 	// Sync settings
 	Sync SyncConfig `yaml:"sync" mapstructure:"sync"`
+
+	// Dotfiles repository (git URL) or local directory applied to new sandboxes
+	Dotfiles string `yaml:"dotfiles,omitempty" mapstructure:"dotfiles"`
+
+	// Down settings
+	Down DownConfig `yaml:"down,omitempty" mapstructure:"down"`
+
+	// Connect settings
+	Connect ConnectConfig `yaml:"connect,omitempty" mapstructure:"connect"`
+
+	// Dev session settings
+	Dev DevConfig `yaml:"dev,omitempty" mapstructure:"dev"`
+
+	// Command aliases, e.g. sshc: "connect --method ssh"
+	Aliases map[string]string `yaml:"aliases,omitempty" mapstructure:"aliases"`
+
+	// Send anonymous usage telemetry (off unless enabled with 'cvps telemetry on')
+	Telemetry bool `yaml:"telemetry,omitempty" mapstructure:"telemetry"`
+
+	// Check once a day for a newer cvps release (on unless set to false)
+	UpdateCheck *bool `yaml:"update_check,omitempty" mapstructure:"update_check"`
+
+	// Record commands in ~/.cvps/history.jsonl for 'cvps history' (on unless
+	// set to false)
+	History *bool `yaml:"history,omitempty" mapstructure:"history"`
+
+	// Set by Load
+	resolution *resolution
+}
+
+// RequestTimeout returns api_timeout, or 0 for the client default. Load
+// rejects values that do not parse.
+func (c *Config) RequestTimeout() time.Duration {
+	d, _ := time.ParseDuration(c.APITimeout)
+	return d
+}
+
+// UpdateCheckEnabled reports whether to look for newer releases
+func (c *Config) UpdateCheckEnabled() bool {
+	return c.UpdateCheck == nil || *c.UpdateCheck
+}
+
+// HistoryEnabled reports whether to record commands in the local history
+func (c *Config) HistoryEnabled() bool {
+	return c.History == nil || *c.History
+}
+
+// EncryptionConfig holds the non-secret parameters of the vault key that
+// encrypts credentials
+type EncryptionConfig struct {
+	Salt  string `yaml:"salt" mapstructure:"salt"`
+	Check string `yaml:"check" mapstructure:"check"`
+}
+
+// KeyProvider returns the vault key for enc. The CLI sets it to ask the vault
+// agent or prompt for the passphrase.
+var KeyProvider func(enc *EncryptionConfig) ([]byte, error)
+
+type ConnectConfig struct {
+	// Start file sync for the working directory on every 'cvps connect'
+	Sync bool `yaml:"sync,omitempty" mapstructure:"sync"`
+	// Clipboard copies (OSC 52) from the sandbox: "allow" (default) or "deny"
+	Clipboard string `yaml:"clipboard,omitempty" mapstructure:"clipboard"`
+	// Scopes of the API token put in the sandbox's environment on connect;
+	// none by default, so no token is created
+	TokenScopes []string `yaml:"token_scopes,omitempty" mapstructure:"token_scopes"`
+}
+
+type DevConfig struct {
+	// Ports forwarded by 'cvps dev', as "port" or "local:remote"
+	Forwards []string `yaml:"forwards,omitempty" mapstructure:"forwards"`
+}
+
+type DownConfig struct {
+	// Take a final snapshot before every delete
+	SnapshotBeforeDelete bool `yaml:"snapshot_before_delete,omitempty" mapstructure:"snapshot_before_delete"`
 }
 
 type SandboxDefaults struct {
@@ -76,42 +160,22 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
+// Load returns the effective config. Each setting comes from the first of
+// these that has it: the environment (CVPS_<KEY>, see EnvVar), the config
+// section of the project's cvps.project.yaml, ~/.cvps/config.yaml and the
+// defaults. Explain shows which one that was.
 func Load() (*Config, error) {
-	configPath, err := ConfigPath()
+	cfg, _, err := resolve()
 	if err != nil {
 		return nil, err
 	}
-
-	// Return defaults if config doesn't exist
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return DefaultConfig(), nil
-	}
-
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
-
-	// Environment variable overrides
-	viper.SetEnvPrefix("CVPS")
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config: %w", err)
-	}
-
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	// Apply env var overrides
-	if apiKey := os.Getenv("CVPS_API_KEY"); apiKey != "" {
-		cfg.APIKey = apiKey
+	if err := decryptCredentials(cfg); err != nil {
+		return nil, err
 	}
-	if apiURL := os.Getenv("CVPS_API_URL"); apiURL != "" {
-		cfg.APIBaseURL = apiURL
+	if err := validateAPITimeout(cfg.APITimeout); err != nil {
+		return nil, err
 	}
-
-	return &cfg, nil
+	return cfg, nil
 }
 
 func Save(cfg *Config) error {
@@ -130,7 +194,17 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	data, err := yaml.Marshal(cfg)
+	// Values from the project or the environment stay out of the file
+	if cfg.resolution != nil {
+		copied := *cfg
+		cfg.resolution.restoreFile(&copied)
+		cfg = &copied
+	}
+	out, err := encryptCredentials(cfg)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(out)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -143,10 +217,107 @@ func Save(cfg *Config) error {
 	return nil
 }
 
+// LoadEncryption returns the vault parameters from the config file without
+// decrypting anything, or nil if credentials are not encrypted
+func LoadEncryption() (*EncryptionConfig, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg struct {
+		Encryption *EncryptionConfig `yaml:"encryption"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg.Encryption, nil
+}
+
+// credentials returns pointers to the credential fields of cfg
+func credentials(cfg *Config) []*string {
+	return []*string{&cfg.APIKey, &cfg.AccessToken}
+}
+
+func vaultKey(enc *EncryptionConfig) ([]byte, error) {
+	if KeyProvider == nil {
+		return nil, fmt.Errorf("credentials are encrypted; unlock them with 'cvps vault unlock'")
+	}
+	return KeyProvider(enc)
+}
+
+// decryptCredentials replaces encrypted credentials in cfg with their values
+func decryptCredentials(cfg *Config) error {
+	if cfg.Encryption == nil {
+		return nil
+	}
+	var key []byte
+	for _, field := range credentials(cfg) {
+		if !vault.IsSealed(*field) {
+			continue
+		}
+		if key == nil {
+			var err error
+			if key, err = vaultKey(cfg.Encryption); err != nil {
+				return err
+			}
+		}
+		plain, err := vault.Open(key, *field)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt credentials: %w", err)
+		}
+		*field = string(plain)
+	}
+	return nil
+}
+
+// encryptCredentials returns cfg as it should be written, with credentials
+// encrypted if encryption is on
+func encryptCredentials(cfg *Config) (*Config, error) {
+	if cfg.Encryption == nil {
+		return cfg, nil
+	}
+	out := *cfg
+	var key []byte
+	for _, field := range credentials(&out) {
+		if *field == "" || vault.IsSealed(*field) {
+			continue
+		}
+		if key == nil {
+			var err error
+			if key, err = vaultKey(cfg.Encryption); err != nil {
+				return nil, err
+			}
+		}
+		sealed, err := vault.Seal(key, []byte(*field))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+		}
+		*field = sealed
+	}
+	return &out, nil
+}
+
 func (c *Config) Validate() error {
 	if c.APIBaseURL == "" {
 		return fmt.Errorf("api_base_url is required")
 	}
+	return validateAPITimeout(c.APITimeout)
+}
+
+func validateAPITimeout(timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+		return fmt.Errorf("invalid api_timeout %q: use a duration such as 90s or 2m", timeout)
+	}
 	return nil
 }
 
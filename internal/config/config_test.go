@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/vault"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -169,6 +173,19 @@ func TestEnvironmentVariableOverrides(t *testing.T) {
 	if loaded.APIBaseURL != "https://env-override.com" {
 		t.Errorf("expected APIBaseURL from env to be https://env-override.com, got %s", loaded.APIBaseURL)
 	}
+
+	os.Setenv("CVPS_API_TIMEOUT", "2m")
+	defer os.Unsetenv("CVPS_API_TIMEOUT")
+	if loaded, err = Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.RequestTimeout() != 2*time.Minute {
+		t.Errorf("expected a 2m timeout from env, got %v", loaded.RequestTimeout())
+	}
+	os.Setenv("CVPS_API_TIMEOUT", "soon")
+	if _, err := Load(); err == nil {
+		t.Error("expected an invalid timeout to be rejected")
+	}
 }
 
 func TestValidate(t *testing.T) {
@@ -189,6 +206,11 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "invalid api_timeout",
+			cfg:     &Config{APIBaseURL: "https://api.claudevps.com", APITimeout: "90"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -245,3 +267,77 @@ func TestIsAuthenticated(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveAndLoadEncrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	key, salt, check, err := vault.NewKey([]byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlocks := 0
+	oldProvider := KeyProvider
+	KeyProvider = func(enc *EncryptionConfig) ([]byte, error) {
+		unlocks++
+		return key, vault.Verify(key, enc.Check)
+	}
+	defer func() { KeyProvider = oldProvider }()
+
+	cfg := DefaultConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.AccessToken = "test-access-token"
+	cfg.Encryption = &EncryptionConfig{Salt: salt, Check: check}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if cfg.APIKey != "test-api-key" {
+		t.Error("Save() must not modify the config it is given")
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".cvps", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "test-api-key") || strings.Contains(string(data), "test-access-token") {
+		t.Errorf("credentials written in plain text:\n%s", data)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.APIKey != "test-api-key" || loaded.AccessToken != "test-access-token" {
+		t.Errorf("unexpected credentials after load: %q, %q", loaded.APIKey, loaded.AccessToken)
+	}
+	if unlocks != 2 {
+		t.Errorf("expected one unlock per Save and Load, got %d", unlocks)
+	}
+
+	KeyProvider = nil
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "cvps vault unlock") {
+		t.Errorf("Load() without a key provider error = %v", err)
+	}
+}
+
+func TestUpdateCheckEnabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	if !cfg.UpdateCheckEnabled() {
+		t.Error("update checks should be on by default")
+	}
+
+	disabled := false
+	cfg.UpdateCheck = &disabled
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.UpdateCheckEnabled() {
+		t.Error("update_check: false should survive a save and load")
+	}
+}
@@ -0,0 +1,351 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/project"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting, from lowest to highest precedence. Command flags such
+// as 'cvps up --cpu' outrank all of them.
+const (
+	SourceDefault = "default"
+	SourceFile    = "config file"
+	SourceProject = "project"
+	SourceEnv     = "environment"
+)
+
+// Origin is where a value of a setting came from
+type Origin struct {
+	Source string
+	Detail string // the file or environment variable, if any
+}
+
+func (o Origin) String() string {
+	if o.Detail == "" {
+		return o.Source
+	}
+	return o.Detail + " (" + o.Source + ")"
+}
+
+// SettingValue is the value a setting had in one source
+type SettingValue struct {
+	Origin Origin
+	Value  string
+}
+
+// Setting is a key with the values every source gave it, the effective one
+// first
+type Setting struct {
+	Key    string
+	Values []SettingValue
+}
+
+// Effective returns the value in use
+func (s Setting) Effective() SettingValue {
+	return s.Values[0]
+}
+
+// ProjectKeys are the settings a project may override in the config section
+// of cvps.project.yaml. Credentials, endpoints and personal preferences are
+// left out so a checked-out repository cannot redirect or change them.
+var ProjectKeys = []string{
+	"defaults.cpu_cores",
+	"defaults.memory_gb",
+	"defaults.storage_gb",
+	"defaults.image",
+	"sync.ignore_patterns",
+	"sync.mode",
+	"connect.sync",
+	"dev.forwards",
+	"down.snapshot_before_delete",
+}
+
+// envAliases are environment variables that predate the CVPS_<KEY> scheme
+var envAliases = map[string]string{
+	"api_base_url": "CVPS_API_URL",
+}
+
+// EnvVar returns the environment variable that overrides key, e.g.
+// CVPS_DEFAULTS_CPU_CORES for defaults.cpu_cores, or "" for unknown keys
+func EnvVar(key string) string {
+	if name, ok := envAliases[key]; ok {
+		return name
+	}
+	if _, ok := settingField(reflect.ValueOf(DefaultConfig()), key); !ok {
+		return ""
+	}
+	return "CVPS_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys returns the dotted keys of all settings, e.g. defaults.cpu_cores
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := yamlName(f)
+			if name == "" || name == "encryption" {
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct {
+				walk(f.Type, prefix+name+".")
+				continue
+			}
+			keys = append(keys, prefix+name)
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+func yamlName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// settingField returns the field of the config that v points to for key
+func settingField(v reflect.Value, key string) (reflect.Value, bool) {
+	v = v.Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if yamlName(v.Type().Field(i)) == part {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// formatValue renders a setting for display
+func formatValue(f reflect.Value) string {
+	switch f.Kind() {
+	case reflect.Pointer:
+		if f.IsNil() {
+			return ""
+		}
+		return formatValue(f.Elem())
+	case reflect.Slice:
+		parts := make([]string, f.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(f.Index(i).Interface())
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		keys := f.MapKeys()
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%v=%v", k.Interface(), f.MapIndex(k).Interface()))
+		}
+		slices.Sort(parts)
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(f.Interface())
+}
+
+// setFromString parses s into f. Lists are comma-separated, and so are the
+// name=value pairs of maps.
+func setFromString(f reflect.Value, s string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		f.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		f.SetBool(b)
+	case reflect.Pointer:
+		v := reflect.New(f.Type().Elem())
+		if err := setFromString(v.Elem(), s); err != nil {
+			return err
+		}
+		f.Set(v)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		m := make(map[string]string)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%q is not name=value", item)
+			}
+			m[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		f.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("cannot be set from a string")
+	}
+	return nil
+}
+
+// presentKeys returns the setting keys given in a decoded YAML mapping
+func presentKeys(m map[string]any, prefix string, leaves []string) []string {
+	var keys []string
+	for k, v := range m {
+		key := prefix + k
+		if slices.Contains(leaves, key) {
+			keys = append(keys, key)
+		} else if sub, ok := v.(map[string]any); ok {
+			keys = append(keys, presentKeys(sub, key+".", leaves)...)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resolution records how Load arrived at a config
+type resolution struct {
+	file     *Config // defaults with the config file applied
+	settings map[string]*Setting
+}
+
+func (r *resolution) record(cfg *Config, key string, origin Origin) {
+	f, _ := settingField(reflect.ValueOf(cfg), key)
+	s := r.settings[key]
+	s.Values = append([]SettingValue{{Origin: origin, Value: formatValue(f)}}, s.Values...)
+}
+
+// resolve builds the config from every source: the defaults, then the config
+// file, the project's cvps.project.yaml and the environment
+func resolve() (*Config, *resolution, error) {
+	cfg := DefaultConfig()
+	leaves := Keys()
+	r := &resolution{settings: make(map[string]*Setting)}
+	for _, key := range leaves {
+		r.settings[key] = &Setting{Key: key}
+		r.record(cfg, key, Origin{Source: SourceDefault})
+	}
+
+	// Without a home directory, as in some containers, there is no config
+	// file and the environment has to provide everything
+	configPath, err := ConfigPath()
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	if err == nil {
+		var raw map[string]any
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		for _, key := range presentKeys(raw, "", leaves) {
+			r.record(cfg, key, Origin{Source: SourceFile, Detail: configPath})
+		}
+	}
+	file := *cfg
+	r.file = &file
+
+	proj, err := project.Load(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	if proj != nil && len(proj.Config) > 0 {
+		keys := presentKeys(proj.Config, "", leaves)
+		for _, key := range keys {
+			if !slices.Contains(ProjectKeys, key) {
+				return nil, nil, fmt.Errorf("%s: config.%s cannot be set by a project (allowed: %s)", project.FileName, key, strings.Join(ProjectKeys, ", "))
+			}
+		}
+		data, err := yaml.Marshal(proj.Config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the config section of %s: %w", project.FileName, err)
+		}
+		for _, key := range keys {
+			r.record(cfg, key, Origin{Source: SourceProject, Detail: project.FileName})
+		}
+	}
+
+	for _, key := range leaves {
+		name := EnvVar(key)
+		value := os.Getenv(name)
+		if name == "" || value == "" {
+			continue
+		}
+		f, _ := settingField(reflect.ValueOf(cfg), key)
+		if err := setFromString(f, value); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		r.record(cfg, key, Origin{Source: SourceEnv, Detail: name})
+	}
+
+	cfg.resolution = r
+	return cfg, r, nil
+}
+
+// restoreFile undoes, in a config about to be saved, the values that came
+// from the project or the environment and were not changed since, so they
+// are not written to the config file
+func (r *resolution) restoreFile(cfg *Config) {
+	for key, s := range r.settings {
+		source := s.Effective().Origin.Source
+		if source != SourceProject && source != SourceEnv {
+			continue
+		}
+		f, _ := settingField(reflect.ValueOf(cfg), key)
+		if formatValue(f) != s.Effective().Value {
+			continue
+		}
+		saved, _ := settingField(reflect.ValueOf(r.file), key)
+		f.Set(saved)
+	}
+}
+
+// Explain resolves the config as Load does and returns every setting with
+// the value each source gave it
+func Explain() ([]Setting, error) {
+	_, r, err := resolve()
+	if err != nil {
+		return nil, err
+	}
+	var settings []Setting
+	for _, key := range Keys() {
+		settings = append(settings, *r.settings[key])
+	}
+	return settings, nil
+}
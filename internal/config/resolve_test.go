@@ -0,0 +1,165 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setupResolve gives the test its own home and working directory
+func setupResolve(t *testing.T, file, proj string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if file != "" {
+		if err := os.MkdirAll(filepath.Join(home, ".cvps"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(home, ".cvps", "config.yaml"), []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	if proj != "" {
+		if err := os.WriteFile(filepath.Join(dir, "cvps.project.yaml"), []byte(proj), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldWd, _ := os.Getwd()
+	os.Chdir(dir)
+	t.Cleanup(func() { os.Chdir(oldWd) })
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setupResolve(t,
+		"defaults:\n  cpu_cores: 2\n  memory_gb: 4\n  storage_gb: 20\n",
+		"config:\n  defaults:\n    memory_gb: 8\n    storage_gb: 40\n",
+	)
+	t.Setenv("CVPS_DEFAULTS_STORAGE_GB", "80")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Defaults.CPUCores != 2 || cfg.Defaults.MemoryGB != 8 || cfg.Defaults.StorageGB != 80 {
+		t.Errorf("Defaults = %+v, want cpu from file, memory from project, storage from env", cfg.Defaults)
+	}
+	if cfg.APIBaseURL != DefaultConfig().APIBaseURL {
+		t.Errorf("APIBaseURL = %q, want the default", cfg.APIBaseURL)
+	}
+
+	settings, err := Explain()
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	i := slices.IndexFunc(settings, func(s Setting) bool { return s.Key == "defaults.storage_gb" })
+	var sources []string
+	for _, v := range settings[i].Values {
+		sources = append(sources, v.Origin.Source+"="+v.Value)
+	}
+	want := []string{"environment=80", "project=40", "config file=20", "default=5"}
+	if !slices.Equal(sources, want) {
+		t.Errorf("defaults.storage_gb values = %v, want %v", sources, want)
+	}
+}
+
+func TestLoadRejectsProjectCredentials(t *testing.T) {
+	setupResolve(t, "", "config:\n  api_base_url: https://evil.example.com\n")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "config.api_base_url cannot be set by a project") {
+		t.Fatalf("Load() error = %v, want project key rejected", err)
+	}
+}
+
+func TestLoadEnvLists(t *testing.T) {
+	setupResolve(t, "", "")
+	t.Setenv("CVPS_SYNC_IGNORE_PATTERNS", "node_modules, dist")
+	t.Setenv("CVPS_CONNECT_SYNC", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !slices.Equal(cfg.Sync.IgnorePatterns, []string{"node_modules", "dist"}) {
+		t.Errorf("Sync.IgnorePatterns = %v", cfg.Sync.IgnorePatterns)
+	}
+	if !cfg.Connect.Sync {
+		t.Error("Connect.Sync = false, want true from CVPS_CONNECT_SYNC")
+	}
+
+	t.Setenv("CVPS_CONNECT_SYNC", "maybe")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted CVPS_CONNECT_SYNC=maybe")
+	}
+}
+
+func TestLoadWithoutHome(t *testing.T) {
+	setupResolve(t, "", "")
+	t.Setenv("HOME", "")
+	t.Setenv("CVPS_ACCESS_TOKEN", "token")
+	t.Setenv("CVPS_SYNC_MODE", "rsync")
+	t.Setenv("CVPS_ALIASES", "all=status --all, sshc=connect --method ssh")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "token" || cfg.Sync.Mode != "rsync" {
+		t.Errorf("AccessToken = %q, Sync.Mode = %q", cfg.AccessToken, cfg.Sync.Mode)
+	}
+	if cfg.Aliases["all"] != "status --all" || cfg.Aliases["sshc"] != "connect --method ssh" {
+		t.Errorf("Aliases = %v", cfg.Aliases)
+	}
+
+	t.Setenv("CVPS_ALIASES", "all")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted CVPS_ALIASES=all")
+	}
+}
+
+func TestSaveKeepsOverridesOutOfFile(t *testing.T) {
+	setupResolve(t, "defaults:\n  cpu_cores: 2\n", "config:\n  defaults:\n    memory_gb: 8\n")
+	t.Setenv("CVPS_DEFAULTS_CPU_CORES", "16")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.Dotfiles = "https://example.com/dotfiles"
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	os.Unsetenv("CVPS_DEFAULTS_CPU_CORES")
+	os.Remove("cvps.project.yaml")
+	saved, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.Defaults.CPUCores != 2 || saved.Defaults.MemoryGB != DefaultConfig().Defaults.MemoryGB {
+		t.Errorf("saved Defaults = %+v, want the file's values only", saved.Defaults)
+	}
+	if saved.Dotfiles != "https://example.com/dotfiles" {
+		t.Errorf("saved Dotfiles = %q", saved.Dotfiles)
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	tests := map[string]string{
+		"defaults.cpu_cores": "CVPS_DEFAULTS_CPU_CORES",
+		"api_base_url":       "CVPS_API_URL",
+		"api_key":            "CVPS_API_KEY",
+		"aliases":            "CVPS_ALIASES",
+		"access_token":       "CVPS_ACCESS_TOKEN",
+		"nope":               "",
+	}
+	for key, want := range tests {
+		if got := EnvVar(key); got != want {
+			t.Errorf("EnvVar(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
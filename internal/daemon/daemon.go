@@ -0,0 +1,262 @@
+// Package daemon is the local control API of 'cvps daemon': a long-running
+// process on localhost that keeps the sandbox list warm, supervises sync
+// sessions, manages port forwards and lends credentials, so editor extensions
+// and the TUI need neither a cold CLI start nor their own login per operation.
+//
+// The daemon records its address and a random bearer token in
+// ~/.cvps/daemon.json, readable only by the user. Clients read the file and
+// send the token with every request.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+const stateFile = "daemon.json"
+
+// ErrNotRunning is returned by Connect when no daemon is running
+var ErrNotRunning = errors.New("daemon is not running")
+
+// State tells clients where a running daemon listens
+type State struct {
+	PID       int       `json:"pid"`
+	Addr      string    `json:"addr"` // host:port on the loopback interface
+	Token     string    `json:"token"`
+	Version   string    `json:"version"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StatePath returns the location of the daemon state file
+func StatePath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, stateFile), nil
+}
+
+// LoadState reads the state file. It returns nil without error if there is
+// none.
+func LoadState() (*State, error) {
+	path, err := StatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daemon state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon state: %w", err)
+	}
+	return &s, nil
+}
+
+func saveState(s *State) error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	return nil
+}
+
+// removeState deletes the state file if it still describes s, so a daemon
+// that lost a race does not remove the winner's
+func removeState(s *State) {
+	current, err := LoadState()
+	if err != nil || current == nil || current.Token != s.Token {
+		return
+	}
+	if path, err := StatePath(); err == nil {
+		os.Remove(path)
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Status is what a running daemon reports about itself
+type Status struct {
+	PID             int       `json:"pid"`
+	Version         string    `json:"version"`
+	StartedAt       time.Time `json:"started_at"`
+	Sandboxes       int       `json:"sandboxes"`
+	SandboxesAt     time.Time `json:"sandboxes_fetched_at,omitempty"`
+	SandboxesError  string    `json:"sandboxes_error,omitempty"`
+	SyncSessions    int       `json:"sync_sessions"`
+	MutagenDetected bool      `json:"mutagen"`
+}
+
+// SandboxList is the daemon's cached sandbox list
+type SandboxList struct {
+	FetchedAt time.Time           `json:"fetched_at"`
+	Sandboxes []claudevps.Sandbox `json:"sandboxes"`
+}
+
+// SyncSession is a sync session as last checked by the supervisor
+type SyncSession struct {
+	Name       string    `json:"name"`
+	SandboxID  string    `json:"sandbox_id"`
+	Status     string    `json:"status"`
+	LocalPath  string    `json:"local_path,omitempty"`
+	RemotePath string    `json:"remote_path,omitempty"`
+	Conflicts  int       `json:"conflicts"`
+	Problem    string    `json:"problem,omitempty"` // why the session needs attention
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// ForwardRequest asks for localhost:Local to be forwarded to port Remote of
+// a sandbox
+type ForwardRequest struct {
+	Sandbox string `json:"sandbox"` // ID or name
+	Local   int    `json:"local"`
+	Remote  int    `json:"remote"`
+}
+
+// Credentials are lent to local clients so they can call the API without a
+// login of their own. Exactly one of AccessToken and APIKey is set.
+type Credentials struct {
+	APIURL      string `json:"api_url"`
+	AccessToken string `json:"access_token,omitempty"`
+	APIKey      string `json:"api_key,omitempty"`
+}
+
+// Client talks to a running daemon
+type Client struct {
+	state *State
+	http  *http.Client
+}
+
+// Connect returns a client of the running daemon, or ErrNotRunning
+func Connect(ctx context.Context) (*Client, error) {
+	state, err := LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrNotRunning
+	}
+	c := &Client{state: state, http: &http.Client{Timeout: 10 * time.Second}}
+
+	// A daemon that died without cleaning up leaves a stale file behind
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, err := c.Status(ctx); err != nil {
+		return nil, fmt.Errorf("%w (stale %s: %v)", ErrNotRunning, stateFile, err)
+	}
+	return c, nil
+}
+
+// State returns where the daemon listens
+func (c *Client) State() *State {
+	return c.state
+}
+
+// Status reports on the daemon
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var s Status
+	return &s, c.do(ctx, "GET", "/v1/status", nil, &s)
+}
+
+// Sandboxes returns the cached sandbox list, or a fresh one with refresh
+func (c *Client) Sandboxes(ctx context.Context, refresh bool) (*SandboxList, error) {
+	path := "/v1/sandboxes"
+	if refresh {
+		path += "?refresh=true"
+	}
+	var l SandboxList
+	return &l, c.do(ctx, "GET", path, nil, &l)
+}
+
+// SyncSessions returns the sync sessions as last checked
+func (c *Client) SyncSessions(ctx context.Context) ([]SyncSession, error) {
+	var s []SyncSession
+	return s, c.do(ctx, "GET", "/v1/sync", nil, &s)
+}
+
+// Forwards lists the port forwards
+func (c *Client) Forwards(ctx context.Context) ([]mutagen.ForwardStatus, error) {
+	var f []mutagen.ForwardStatus
+	return f, c.do(ctx, "GET", "/v1/forwards", nil, &f)
+}
+
+// Credentials borrows the CLI's credentials
+func (c *Client) Credentials(ctx context.Context) (*Credentials, error) {
+	var cr Credentials
+	return &cr, c.do(ctx, "GET", "/v1/credentials", nil, &cr)
+}
+
+// Shutdown asks the daemon to exit
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.do(ctx, "POST", "/v1/shutdown", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+c.state.Addr+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.state.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return fmt.Errorf("daemon: %s", e.Error)
+		}
+		return fmt.Errorf("daemon: unexpected status %d", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,218 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+type fakeBackend struct {
+	lists    atomic.Int32
+	forwards []ForwardRequest
+	syncs    []SyncSession
+}
+
+func (b *fakeBackend) ListSandboxes(ctx context.Context) ([]claudevps.Sandbox, error) {
+	b.lists.Add(1)
+	return []claudevps.Sandbox{{ID: "sbx-1", Name: "web", Status: "running"}}, nil
+}
+
+func (b *fakeBackend) GetSandbox(ctx context.Context, ref string) (*claudevps.Sandbox, error) {
+	if ref != "web" && ref != "sbx-1" {
+		return nil, &claudevps.APIError{StatusCode: 404, Message: "sandbox not found"}
+	}
+	return &claudevps.Sandbox{ID: "sbx-1", Name: "web", Status: "stopped"}, nil
+}
+
+func (b *fakeBackend) SyncSessions() ([]SyncSession, error) { return b.syncs, nil }
+func (b *fakeBackend) FlushSync(name string) error          { return nil }
+func (b *fakeBackend) TerminateSync(name string) error      { return nil }
+
+func (b *fakeBackend) Forwards() ([]mutagen.ForwardStatus, error) { return nil, nil }
+
+func (b *fakeBackend) CreateForward(ctx context.Context, req ForwardRequest) error {
+	b.forwards = append(b.forwards, req)
+	return nil
+}
+
+func (b *fakeBackend) TerminateForward(name string) error { return nil }
+
+func (b *fakeBackend) Credentials() (*Credentials, error) {
+	return &Credentials{APIURL: "https://api.example.com", APIKey: "secret"}, nil
+}
+
+func newTestServer(t *testing.T, backend Backend) (*Server, *httptest.Server) {
+	t.Helper()
+	srv, err := NewServer(backend, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+func request(t *testing.T, ts *httptest.Server, token, method, path, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	_, ts := newTestServer(t, &fakeBackend{})
+	for _, token := range []string{"", "wrong"} {
+		if resp := request(t, ts, token, "GET", "/v1/credentials", ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Sandboxes(t *testing.T) {
+	backend := &fakeBackend{}
+	srv, ts := newTestServer(t, backend)
+
+	// The first request fills the cache, later ones are served from it
+	for range 2 {
+		resp := request(t, ts, srv.token, "GET", "/v1/sandboxes", "")
+		var list SandboxList
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil || len(list.Sandboxes) != 1 || list.FetchedAt.IsZero() {
+			t.Fatalf("Unexpected list %+v, %v", list, err)
+		}
+	}
+	if n := backend.lists.Load(); n != 1 {
+		t.Errorf("listed %d times, want 1", n)
+	}
+	request(t, ts, srv.token, "GET", "/v1/sandboxes?refresh=true", "")
+	if n := backend.lists.Load(); n != 2 {
+		t.Errorf("refresh=true listed %d times in total, want 2", n)
+	}
+
+	// Fetching one sandbox updates it in the cache
+	if resp := request(t, ts, srv.token, "GET", "/v1/sandboxes/web", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /v1/sandboxes/web: status %d", resp.StatusCode)
+	}
+	if status := srv.sandboxes.Sandboxes[0].Status; status != "stopped" {
+		t.Errorf("cached status = %s, want stopped", status)
+	}
+	if resp := request(t, ts, srv.token, "GET", "/v1/sandboxes/nope", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET unknown sandbox: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestServer_Forwards(t *testing.T) {
+	backend := &fakeBackend{}
+	srv, ts := newTestServer(t, backend)
+
+	if resp := request(t, ts, srv.token, "POST", "/v1/forwards", `{"sandbox":"web","local":0}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid forward: status %d, want 400", resp.StatusCode)
+	}
+	if resp := request(t, ts, srv.token, "POST", "/v1/forwards", `{"sandbox":"web","local":3000}`); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("create forward: status %d", resp.StatusCode)
+	}
+	if len(backend.forwards) != 1 || backend.forwards[0] != (ForwardRequest{Sandbox: "web", Local: 3000, Remote: 3000}) {
+		t.Errorf("Unexpected forwards %+v", backend.forwards)
+	}
+}
+
+func TestServer_SuperviseSync(t *testing.T) {
+	backend := &fakeBackend{syncs: []SyncSession{
+		{Name: "cvps-sbx-1", Status: "Watching for changes"},
+		{Name: "cvps-sbx-2", Status: "Watching for changes", Conflicts: 2},
+		{Name: "cvps-sbx-3", Status: "Halted on root deletion"},
+	}}
+	var logged []string
+	srv, err := NewServer(backend, Options{Logf: func(format string, args ...any) { logged = append(logged, format) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.superviseSync(context.Background())
+	srv.superviseSync(context.Background())
+
+	want := []string{"", "2 conflicts", "Halted on root deletion"}
+	for i, ss := range srv.syncs {
+		if ss.Problem != want[i] || ss.CheckedAt.IsZero() {
+			t.Errorf("session %s: problem %q, want %q", ss.Name, ss.Problem, want[i])
+		}
+	}
+	// Problems are logged once, not on every check
+	if len(logged) != 2 {
+		t.Errorf("logged %d problems, want 2", len(logged))
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	if _, err := Connect(ctx); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Connect() without a daemon = %v, want ErrNotRunning", err)
+	}
+
+	srv, err := NewServer(&fakeBackend{}, Options{Version: "1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	var c *Client
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if c, err = Connect(ctx); err == nil {
+			break
+		}
+	}
+	if c == nil {
+		t.Fatalf("daemon did not come up: %v", err)
+	}
+
+	st, err := c.Status(ctx)
+	if err != nil || st.Version != "1.2.3" {
+		t.Fatalf("Status() = %+v, %v", st, err)
+	}
+	creds, err := c.Credentials(ctx)
+	if err != nil || creds.APIKey != "secret" {
+		t.Errorf("Credentials() = %+v, %v", creds, err)
+	}
+
+	second, _ := NewServer(&fakeBackend{}, Options{})
+	if err := second.Run(ctx); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("second Run() = %v, want already running", err)
+	}
+
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Run() = %v", err)
+	}
+	if state, _ := LoadState(); state != nil {
+		t.Errorf("state file left behind: %+v", state)
+	}
+}
+
+func TestListenLoopback(t *testing.T) {
+	if _, err := listenLoopback("0.0.0.0:0"); err == nil {
+		t.Error("expected listening on all interfaces to be refused")
+	}
+	ln, err := listenLoopback("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+}
@@ -0,0 +1,427 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achronon/cvps/internal/mutagen"
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+// Backend does the work behind the API. The CLI provides it, so the daemon
+// names sessions and resolves sandboxes exactly as the commands do.
+type Backend interface {
+	ListSandboxes(ctx context.Context) ([]claudevps.Sandbox, error)
+	GetSandbox(ctx context.Context, ref string) (*claudevps.Sandbox, error)
+
+	// SyncSessions returns the current state of the cvps sync sessions
+	SyncSessions() ([]SyncSession, error)
+	FlushSync(name string) error
+	TerminateSync(name string) error
+
+	Forwards() ([]mutagen.ForwardStatus, error)
+	CreateForward(ctx context.Context, req ForwardRequest) error
+	TerminateForward(name string) error
+
+	Credentials() (*Credentials, error)
+}
+
+// Options configure a Server
+type Options struct {
+	Addr            string        // loopback host:port; port 0 picks a free one
+	Version         string        // reported in the status
+	RefreshInterval time.Duration // how often the sandbox list is refreshed
+	SyncInterval    time.Duration // how often sync sessions are checked
+	Logf            func(format string, args ...any)
+}
+
+// Server is the daemon
+type Server struct {
+	backend Backend
+	opts    Options
+	token   string
+	started time.Time
+
+	mu           sync.Mutex
+	sandboxes    *SandboxList
+	sandboxesErr error
+	syncs        []SyncSession
+	syncProblems map[string]string // the problem last logged per session
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewServer returns a daemon with a fresh token
+func NewServer(backend Backend, opts Options) (*Server, error) {
+	if opts.Addr == "" {
+		opts.Addr = "127.0.0.1:0"
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = 30 * time.Second
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 15 * time.Second
+	}
+	if opts.Logf == nil {
+		opts.Logf = func(string, ...any) {}
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create daemon token: %w", err)
+	}
+	return &Server{
+		backend:      backend,
+		opts:         opts,
+		token:        token,
+		started:      time.Now().UTC(),
+		syncProblems: make(map[string]string),
+		shutdown:     make(chan struct{}),
+	}, nil
+}
+
+// Run listens on the loopback address, records it in the state file and
+// serves until ctx is done or a client asks the daemon to shut down
+func (s *Server) Run(ctx context.Context) error {
+	if c, err := Connect(ctx); err == nil {
+		return fmt.Errorf("a daemon is already running (pid %d, %s)", c.state.PID, c.state.Addr)
+	}
+
+	ln, err := listenLoopback(s.opts.Addr)
+	if err != nil {
+		return err
+	}
+	state := &State{
+		PID:       os.Getpid(),
+		Addr:      ln.Addr().String(),
+		Token:     s.token,
+		Version:   s.opts.Version,
+		StartedAt: s.started,
+	}
+	if err := saveState(state); err != nil {
+		ln.Close()
+		return err
+	}
+	defer removeState(state)
+	s.opts.Logf("listening on %s", state.Addr)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.loop(ctx, s.opts.RefreshInterval, s.refreshSandboxes)
+	go s.loop(ctx, s.opts.SyncInterval, s.superviseSync)
+
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	case <-s.shutdown:
+	}
+	s.opts.Logf("shutting down")
+	shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// listenLoopback listens on addr, which must be on the loopback interface:
+// the API lends credentials and must not be reachable from the network
+func listenLoopback(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("the daemon only listens on the loopback interface, not %s", host)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return ln, nil
+}
+
+// loop runs fn now and then every interval until ctx is done
+func (s *Server) loop(ctx context.Context, interval time.Duration, fn func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) refreshSandboxes(ctx context.Context) {
+	sandboxes, err := s.backend.ListSandboxes(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if s.sandboxesErr == nil {
+			s.opts.Logf("failed to list sandboxes: %v", err)
+		}
+		s.sandboxesErr = err
+		return
+	}
+	s.sandboxesErr = nil
+	s.sandboxes = &SandboxList{FetchedAt: time.Now().UTC(), Sandboxes: sandboxes}
+}
+
+// superviseSync checks the sync sessions and logs those that start or stop
+// needing attention
+func (s *Server) superviseSync(ctx context.Context) {
+	sessions, err := s.backend.SyncSessions()
+	if err != nil {
+		return
+	}
+	now := time.Now().UTC()
+	for i := range sessions {
+		sessions[i].CheckedAt = now
+		sessions[i].Problem = syncProblem(sessions[i])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	for _, ss := range sessions {
+		seen[ss.Name] = true
+		if prev := s.syncProblems[ss.Name]; ss.Problem != prev {
+			if ss.Problem != "" {
+				s.opts.Logf("sync %s: %s", ss.Name, ss.Problem)
+			} else {
+				s.opts.Logf("sync %s: recovered", ss.Name)
+			}
+			s.syncProblems[ss.Name] = ss.Problem
+		}
+	}
+	for name := range s.syncProblems {
+		if !seen[name] {
+			delete(s.syncProblems, name)
+		}
+	}
+	s.syncs = sessions
+}
+
+// syncProblem says why a sync session needs attention, or "" if it does not
+func syncProblem(ss SyncSession) string {
+	status := strings.ToLower(ss.Status)
+	switch {
+	case strings.HasPrefix(status, "halted"):
+		return ss.Status
+	case strings.Contains(status, "error"):
+		return ss.Status
+	case ss.Conflicts > 0:
+		return fmt.Sprintf("%d conflicts", ss.Conflicts)
+	}
+	return ""
+}
+
+// Handler returns the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/sandboxes", s.handleSandboxes)
+	mux.HandleFunc("GET /v1/sandboxes/{ref}", s.handleSandbox)
+	mux.HandleFunc("GET /v1/sync", s.handleSync)
+	mux.HandleFunc("POST /v1/sync/{name}/flush", s.handleSyncFlush)
+	mux.HandleFunc("DELETE /v1/sync/{name}", s.handleSyncTerminate)
+	mux.HandleFunc("GET /v1/forwards", s.handleForwards)
+	mux.HandleFunc("POST /v1/forwards", s.handleForwardCreate)
+	mux.HandleFunc("DELETE /v1/forwards/{name}", s.handleForwardTerminate)
+	mux.HandleFunc("GET /v1/credentials", s.handleCredentials)
+	mux.HandleFunc("POST /v1/shutdown", s.handleShutdown)
+	return s.authorize(mux)
+}
+
+// authorize rejects requests without the daemon's token
+func (s *Server) authorize(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong token; read it from "+stateFile))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeBackendError reports a failure of the API or mutagen behind the daemon
+func writeBackendError(w http.ResponseWriter, err error) {
+	if claudevps.IsNotFound(err) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusBadGateway, err)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	st := Status{
+		PID:             os.Getpid(),
+		Version:         s.opts.Version,
+		StartedAt:       s.started,
+		SyncSessions:    len(s.syncs),
+		MutagenDetected: mutagen.IsInstalled(),
+	}
+	if s.sandboxes != nil {
+		st.Sandboxes = len(s.sandboxes.Sandboxes)
+		st.SandboxesAt = s.sandboxes.FetchedAt
+	}
+	if s.sandboxesErr != nil {
+		st.SandboxesError = s.sandboxesErr.Error()
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleSandboxes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	list := s.sandboxes
+	s.mu.Unlock()
+
+	if list == nil || r.URL.Query().Get("refresh") == "true" {
+		s.refreshSandboxes(r.Context())
+		s.mu.Lock()
+		list, err := s.sandboxes, s.sandboxesErr
+		s.mu.Unlock()
+		if err != nil {
+			writeBackendError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleSandbox fetches one sandbox fresh from the API and updates it in the
+// cached list
+func (s *Server) handleSandbox(w http.ResponseWriter, r *http.Request) {
+	sandbox, err := s.backend.GetSandbox(r.Context(), r.PathValue("ref"))
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	s.mu.Lock()
+	if s.sandboxes != nil {
+		for i := range s.sandboxes.Sandboxes {
+			if s.sandboxes.Sandboxes[i].ID == sandbox.ID {
+				s.sandboxes.Sandboxes[i] = *sandbox
+			}
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, sandbox)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("refresh") == "true" {
+		s.superviseSync(r.Context())
+	}
+	s.mu.Lock()
+	syncs := s.syncs
+	s.mu.Unlock()
+	if syncs == nil {
+		syncs = []SyncSession{}
+	}
+	writeJSON(w, http.StatusOK, syncs)
+}
+
+func (s *Server) handleSyncFlush(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.FlushSync(r.PathValue("name")); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSyncTerminate(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.TerminateSync(r.PathValue("name")); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	s.superviseSync(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleForwards(w http.ResponseWriter, r *http.Request) {
+	forwards, err := s.backend.Forwards()
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if forwards == nil {
+		forwards = []mutagen.ForwardStatus{}
+	}
+	writeJSON(w, http.StatusOK, forwards)
+}
+
+func (s *Server) handleForwardCreate(w http.ResponseWriter, r *http.Request) {
+	var req ForwardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	if req.Remote == 0 {
+		req.Remote = req.Local
+	}
+	if req.Sandbox == "" || req.Local < 1 || req.Local > 65535 || req.Remote < 1 || req.Remote > 65535 {
+		writeError(w, http.StatusBadRequest, errors.New("sandbox and ports between 1 and 65535 are required"))
+		return
+	}
+	if err := s.backend.CreateForward(r.Context(), req); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleForwardTerminate(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.TerminateForward(r.PathValue("name")); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCredentials(w http.ResponseWriter, r *http.Request) {
+	creds, err := s.backend.Credentials()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, creds)
+}
+
+func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
@@ -0,0 +1,97 @@
+package debuglog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+// maxSize is the size at which the log is rotated to debug.log.1
+const maxSize = 1 << 20
+
+var (
+	mu      sync.Mutex
+	enabled bool
+)
+
+// Enable turns on persistent debug logging. Until it is called, Printf is a no-op.
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+}
+
+// Path returns the location of the debug log
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs", "debug.log"), nil
+}
+
+// Printf appends a timestamped line to the debug log. Failures are ignored so
+// that logging never breaks a command.
+func Printf(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !enabled {
+		return
+	}
+
+	path, err := Path()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSize {
+		_ = os.Rename(path, path+".1")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	line := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	fmt.Fprintf(f, "%s %s\n", time.Now().UTC().Format(time.RFC3339), line)
+}
+
+// Tail returns up to n of the most recent log lines
+func Tail(n int) ([]string, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+
+	return lines, scanner.Err()
+}
@@ -0,0 +1,50 @@
+package debuglog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintfDisabledIsNoop(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	mu.Lock()
+	enabled = false
+	mu.Unlock()
+
+	Printf("should not be written")
+
+	lines, err := Tail(10)
+	if err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if len(lines) != 0 {
+		t.Fatalf("Tail() = %v, want no lines while disabled", lines)
+	}
+}
+
+func TestPrintfAndTail(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	Enable()
+	t.Cleanup(func() {
+		mu.Lock()
+		enabled = false
+		mu.Unlock()
+	})
+
+	for _, msg := range []string{"first", "second", "third"} {
+		Printf("message %s", msg)
+	}
+
+	lines, err := Tail(2)
+	if err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Tail(2) returned %d lines, want 2", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], "message second") || !strings.HasSuffix(lines[1], "message third") {
+		t.Fatalf("Tail(2) = %v, want the two most recent lines", lines)
+	}
+}
@@ -0,0 +1,144 @@
+package groups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/achronon/cvps/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Group is a named set of sandbox IDs
+type Group struct {
+	Name    string   `yaml:"name" json:"name"`
+	Members []string `yaml:"members" json:"members"`
+}
+
+// Store holds all groups, persisted to ~/.cvps/groups.yaml
+type Store struct {
+	Groups []Group `yaml:"groups"`
+
+	path string
+}
+
+// Path returns the location of the groups file
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "groups.yaml"), nil
+}
+
+// Load reads the groups file. A missing file yields an empty store.
+func Load() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads groups from path
+func LoadFile(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse groups: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store back to the file it was loaded from
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	sort.Slice(s.Groups, func(i, j int) bool { return s.Groups[i].Name < s.Groups[j].Name })
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal groups: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write groups: %w", err)
+	}
+	return nil
+}
+
+// Get returns the named group
+func (s *Store) Get(name string) (*Group, error) {
+	for i := range s.Groups {
+		if s.Groups[i].Name == name {
+			return &s.Groups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("group %q not found. Run 'cvps group list' to view groups", name)
+}
+
+// Create adds an empty group
+func (s *Store) Create(name string) error {
+	if name == "" {
+		return fmt.Errorf("group name cannot be empty")
+	}
+	if _, err := s.Get(name); err == nil {
+		return fmt.Errorf("group %q already exists", name)
+	}
+	s.Groups = append(s.Groups, Group{Name: name, Members: []string{}})
+	return nil
+}
+
+// Delete removes a group. Its sandboxes are not affected.
+func (s *Store) Delete(name string) error {
+	for i := range s.Groups {
+		if s.Groups[i].Name == name {
+			s.Groups = slices.Delete(s.Groups, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("group %q not found", name)
+}
+
+// Add appends sandbox IDs to a group, ignoring ones already present.
+// It returns the number of IDs added.
+func (s *Store) Add(name string, ids ...string) (int, error) {
+	g, err := s.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, id := range ids {
+		if !slices.Contains(g.Members, id) {
+			g.Members = append(g.Members, id)
+			added++
+		}
+	}
+	return added, nil
+}
+
+// Remove drops sandbox IDs from a group and returns the number removed
+func (s *Store) Remove(name string, ids ...string) (int, error) {
+	g, err := s.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	before := len(g.Members)
+	g.Members = slices.DeleteFunc(g.Members, func(id string) bool {
+		return slices.Contains(ids, id)
+	})
+	return before - len(g.Members), nil
+}
@@ -0,0 +1,67 @@
+package groups
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.yaml")
+
+	s, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() on missing file error = %v", err)
+	}
+	if len(s.Groups) != 0 {
+		t.Fatalf("expected no groups, got %d", len(s.Groups))
+	}
+
+	if err := s.Create("workshop"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := s.Create("workshop"); err == nil {
+		t.Error("expected error creating duplicate group")
+	}
+
+	added, err := s.Add("workshop", "sbx-1", "sbx-2", "sbx-1")
+	if err != nil || added != 2 {
+		t.Fatalf("Add() = %d, %v, want 2, nil", added, err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	g, err := loaded.Get("workshop")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(g.Members) != 2 || g.Members[0] != "sbx-1" || g.Members[1] != "sbx-2" {
+		t.Errorf("Members = %v, want [sbx-1 sbx-2]", g.Members)
+	}
+
+	removed, err := loaded.Remove("workshop", "sbx-1", "sbx-9")
+	if err != nil || removed != 1 {
+		t.Errorf("Remove() = %d, %v, want 1, nil", removed, err)
+	}
+	if len(g.Members) != 1 || g.Members[0] != "sbx-2" {
+		t.Errorf("Members after remove = %v, want [sbx-2]", g.Members)
+	}
+
+	if err := loaded.Delete("workshop"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, err := loaded.Get("workshop"); err == nil {
+		t.Error("expected error getting deleted group")
+	}
+}
+
+func TestAddToMissingGroup(t *testing.T) {
+	s, _ := LoadFile(filepath.Join(t.TempDir(), "groups.yaml"))
+	if _, err := s.Add("nope", "sbx-1"); err == nil {
+		t.Error("expected error adding to missing group")
+	}
+}
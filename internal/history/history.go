@@ -0,0 +1,190 @@
+// Package history keeps a local log of the cvps commands run on this machine
+// in ~/.cvps/history.jsonl, so what was done to a sandbox can be looked up
+// later. Nothing in it leaves the machine.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+const historyFile = "history.jsonl"
+
+// maxSize is the file size beyond which the oldest half of the history is
+// dropped
+const maxSize = 4 << 20
+
+// Sandbox is a sandbox a command worked on
+type Sandbox struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Entry is one command run
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"` // e.g. "snapshot create"
+	Args       []string  `json:"args,omitempty"`
+	Dir        string    `json:"dir,omitempty"`
+	Sandboxes  []Sandbox `json:"sandboxes,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Path returns the location of the history file
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFile), nil
+}
+
+// Append adds e to the history
+func Append(e Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSize {
+		return trim(path)
+	}
+	return nil
+}
+
+// trim drops the oldest half of the history at path
+func trim(path string) error {
+	entries, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+	entries = entries[len(entries)/2:]
+
+	var b strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Load returns the recorded commands, oldest first
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return loadFile(path)
+}
+
+// loadFile reads the history at path. Lines that do not parse, such as one
+// cut short by a crash, are skipped.
+func loadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// Clear deletes the history
+func Clear() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+	return nil
+}
+
+// Filter selects history entries. Zero fields match everything.
+type Filter struct {
+	Sandbox string // ID, ID prefix or name
+	Command string // command or its leading words, e.g. "snapshot"
+	Since   time.Time
+	Until   time.Time
+	Failed  bool // only failed commands
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.Failed && e.Success {
+		return false
+	}
+	if f.Command != "" && e.Command != f.Command && !strings.HasPrefix(e.Command, f.Command+" ") {
+		return false
+	}
+	if f.Sandbox != "" {
+		found := false
+		for _, s := range e.Sandboxes {
+			if s.Name == f.Sandbox || strings.HasPrefix(s.ID, f.Sandbox) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
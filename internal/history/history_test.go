@@ -0,0 +1,103 @@
+package history
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	entries, err := Load()
+	if err != nil || entries != nil {
+		t.Fatalf("Load() on a missing file = %v, %v", entries, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, e := range []Entry{
+		{Time: now, Command: "up", Sandboxes: []Sandbox{{ID: "sbx-1", Name: "web"}}, Success: true},
+		{Time: now.Add(time.Minute), Command: "down", Error: "boom"},
+	} {
+		if err := Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// A line cut short by a crash is skipped
+	path, _ := Path()
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"time":`)
+	f.Close()
+
+	entries, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Command != "up" || entries[1].Error != "boom" {
+		t.Fatalf("Load() = %+v", entries)
+	}
+	if !entries[0].Time.Equal(now) || entries[0].Sandboxes[0].Name != "web" {
+		t.Errorf("entry = %+v", entries[0])
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if entries, _ := Load(); len(entries) != 0 {
+		t.Errorf("Load() after Clear() = %v", entries)
+	}
+}
+
+func TestAppendTrims(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	e := Entry{Command: "exec", Args: []string{strings.Repeat("x", 4096)}}
+	for i := 0; i < maxSize/4096+1; i++ {
+		if err := Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	path, _ := Path()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > maxSize*3/4 {
+		t.Errorf("history is %d bytes, want it trimmed well below %d", info.Size(), maxSize)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	day := time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC)
+	e := Entry{
+		Time:      day,
+		Command:   "snapshot create",
+		Sandboxes: []Sandbox{{ID: "sbx-abc123", Name: "web"}},
+		Success:   true,
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"sandbox name", Filter{Sandbox: "web"}, true},
+		{"sandbox ID prefix", Filter{Sandbox: "sbx-abc"}, true},
+		{"other sandbox", Filter{Sandbox: "api"}, false},
+		{"command", Filter{Command: "snapshot create"}, true},
+		{"command group", Filter{Command: "snapshot"}, true},
+		{"command word prefix", Filter{Command: "snap"}, false},
+		{"in range", Filter{Since: day.Add(-time.Hour), Until: day.Add(time.Hour)}, true},
+		{"before range", Filter{Since: day.Add(time.Hour)}, false},
+		{"until is exclusive", Filter{Until: day}, false},
+		{"failed only", Filter{Failed: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
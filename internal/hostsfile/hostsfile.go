@@ -0,0 +1,105 @@
+// Package hostsfile edits the block of the system hosts file that cvps
+// manages. Everything outside the block is left as it is.
+package hostsfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// The lines that delimit the managed block
+const (
+	BeginMarker = "# BEGIN cvps"
+	EndMarker   = "# END cvps"
+)
+
+// Entry maps a hostname to an address
+type Entry struct {
+	Address string
+	Host    string
+}
+
+// Path returns the location of the system hosts file
+func Path() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// Managed returns the entries of the managed block in content
+func Managed(content string) []Entry {
+	var entries []Entry
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == BeginMarker:
+			inBlock = true
+		case line == EndMarker:
+			inBlock = false
+		case inBlock && line != "" && !strings.HasPrefix(line, "#"):
+			fields := strings.Fields(line)
+			for _, host := range fields[1:] {
+				entries = append(entries, Entry{Address: fields[0], Host: host})
+			}
+		}
+	}
+	return entries
+}
+
+// Replace returns content with the managed block holding entries. The block
+// is appended if there is none, and removed when entries is empty. The file's
+// line endings are kept.
+func Replace(content string, entries []Entry) string {
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+
+	var kept []string
+	at := -1 // where the block was
+	inBlock := false
+	for _, line := range strings.Split(strings.TrimRight(content, "\r\n"), "\n") {
+		switch strings.TrimSpace(line) {
+		case BeginMarker:
+			inBlock = true
+			if at < 0 {
+				at = len(kept)
+			}
+		case EndMarker:
+			inBlock = false
+		default:
+			if !inBlock {
+				kept = append(kept, strings.TrimRight(line, "\r"))
+			}
+		}
+	}
+
+	var block []string
+	if len(entries) > 0 {
+		block = append(block, BeginMarker)
+		for _, e := range entries {
+			block = append(block, e.Address+"\t"+e.Host)
+		}
+		block = append(block, EndMarker)
+	}
+	if at >= 0 {
+		kept = slices.Insert(kept, at, block...)
+	} else if len(block) > 0 {
+		if len(kept) > 0 {
+			kept = append(kept, "")
+		}
+		kept = append(kept, block...)
+	}
+	for len(kept) > 0 && kept[len(kept)-1] == "" {
+		kept = kept[:len(kept)-1]
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, newline) + newline
+}
@@ -0,0 +1,39 @@
+package hostsfile
+
+import "testing"
+
+func TestReplace(t *testing.T) {
+	original := "127.0.0.1\tlocalhost\n::1\tlocalhost\n"
+	entries := []Entry{
+		{Address: "127.0.0.1", Host: "web.cvps.local"},
+		{Address: "127.0.0.1", Host: "api.cvps.local"},
+	}
+
+	added := Replace(original, entries)
+	want := original + "\n# BEGIN cvps\n127.0.0.1\tweb.cvps.local\n127.0.0.1\tapi.cvps.local\n# END cvps\n"
+	if added != want {
+		t.Fatalf("Replace() =\n%s\nwant\n%s", added, want)
+	}
+	if got := Managed(added); len(got) != 2 || got[1] != entries[1] {
+		t.Errorf("Managed() = %v", got)
+	}
+
+	// Updating rewrites the block in place, keeping what follows it
+	updated := Replace(added+"10.0.0.5\tnas\n", entries[:1])
+	want = original + "\n# BEGIN cvps\n127.0.0.1\tweb.cvps.local\n# END cvps\n10.0.0.5\tnas\n"
+	if updated != want {
+		t.Errorf("Replace() =\n%s\nwant\n%s", updated, want)
+	}
+
+	if removed := Replace(added, nil); removed != original {
+		t.Errorf("Expected removing the block to restore the file, got %q", removed)
+	}
+}
+
+func TestReplace_CRLF(t *testing.T) {
+	got := Replace("127.0.0.1 localhost\r\n", []Entry{{Address: "127.0.0.1", Host: "web.cvps.local"}})
+	want := "127.0.0.1 localhost\r\n\r\n# BEGIN cvps\r\n127.0.0.1\tweb.cvps.local\r\n# END cvps\r\n"
+	if got != want {
+		t.Errorf("Replace() = %q, want %q", got, want)
+	}
+}
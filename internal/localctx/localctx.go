@@ -0,0 +1,259 @@
+// Package localctx manages the per-project sandbox context file (.cvps.yaml).
+//
+// Writes are serialised with a lock held under ~/.cvps/locks and replace the
+// file atomically, so concurrent cvps invocations never see a partial file.
+package localctx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the context file kept in the project directory
+const FileName = ".cvps.yaml"
+
+// lockTimeout bounds how long a writer waits for another cvps process
+var lockTimeout = 10 * time.Second
+
+// Entry is one sandbox that belongs to the project
+type Entry struct {
+	SandboxID string `yaml:"sandbox_id" json:"sandbox_id"`
+	Name      string `yaml:"name,omitempty" json:"name,omitempty"`
+	CreatedAt string `yaml:"created_at" json:"created_at"`
+}
+
+// Context lists the sandboxes of a project directory. Current holds the ID of
+// the one commands use by default.
+type Context struct {
+	Current   string  `yaml:"current,omitempty"`
+	Sandboxes []Entry `yaml:"sandboxes"`
+}
+
+// fileFormat also accepts the single-sandbox layout written by older versions
+type fileFormat struct {
+	Context `yaml:",inline"`
+	Entry   `yaml:",inline"`
+}
+
+// CurrentEntry returns the current sandbox, or nil if none is selected
+func (c *Context) CurrentEntry() *Entry {
+	for i := range c.Sandboxes {
+		if c.Sandboxes[i].SandboxID == c.Current {
+			return &c.Sandboxes[i]
+		}
+	}
+	return nil
+}
+
+// Find looks up a sandbox by name or ID
+func (c *Context) Find(ref string) *Entry {
+	for i := range c.Sandboxes {
+		if c.Sandboxes[i].Name == ref || c.Sandboxes[i].SandboxID == ref {
+			return &c.Sandboxes[i]
+		}
+	}
+	return nil
+}
+
+// Put adds e, replacing any entry with the same sandbox ID, and makes it current
+func (c *Context) Put(e Entry) {
+	c.Current = e.SandboxID
+	for i := range c.Sandboxes {
+		if c.Sandboxes[i].SandboxID == e.SandboxID {
+			c.Sandboxes[i] = e
+			return
+		}
+	}
+	c.Sandboxes = append(c.Sandboxes, e)
+}
+
+// Drop removes a sandbox by ID. If it was current and exactly one sandbox is
+// left, that one becomes current; with several left none is selected.
+func (c *Context) Drop(sandboxID string) bool {
+	for i := range c.Sandboxes {
+		if c.Sandboxes[i].SandboxID != sandboxID {
+			continue
+		}
+		c.Sandboxes = append(c.Sandboxes[:i], c.Sandboxes[i+1:]...)
+		if c.Current == sandboxID {
+			c.Current = ""
+			if len(c.Sandboxes) == 1 {
+				c.Current = c.Sandboxes[0].SandboxID
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Path returns the context file location for dir
+func Path(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Load reads the context for dir. It returns nil without error if there is none.
+func Load(dir string) (*Context, error) {
+	data, err := os.ReadFile(Path(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var f fileFormat
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	c := f.Context
+	if len(c.Sandboxes) == 0 && f.SandboxID != "" {
+		c.Put(f.Entry)
+	}
+	if len(c.Sandboxes) == 0 {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Save replaces the context for dir
+func Save(dir string, c *Context) error {
+	return Update(dir, func(cur *Context) error {
+		*cur = *c
+		return nil
+	})
+}
+
+// Remove deletes the context for dir, if any
+func Remove(dir string) error {
+	return Update(dir, func(cur *Context) error {
+		*cur = Context{}
+		return nil
+	})
+}
+
+// Update lets fn modify the context while holding the lock. fn gets an empty
+// context when there is none. The file is removed once no sandboxes are left
+// and is not rewritten if fn changed nothing.
+func Update(dir string, fn func(*Context) error) error {
+	unlock, err := lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		c = &Context{}
+	}
+	before, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(c); err != nil {
+		return err
+	}
+
+	if len(c.Sandboxes) == 0 {
+		if err := os.Remove(Path(dir)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	after, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		if _, err := os.Stat(Path(dir)); err == nil {
+			return nil
+		}
+	}
+	return writeAtomic(Path(dir), after)
+}
+
+// writeAtomic writes data to a temp file next to path and renames it into place
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), FileName+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// lock takes an exclusive lock for dir. The lock file lives in the config
+// directory so that projects are not littered with lock files.
+func lock(dir string) (func(), error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	configDir, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	lockDir := filepath.Join(configDir, "locks")
+	if err := os.MkdirAll(lockDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	f, err := os.OpenFile(filepath.Join(lockDir, "context-"+hex.EncodeToString(sum[:8])+".lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", FileName, err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for another cvps process to release %s", Path(abs))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,159 @@
+package localctx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSaveLoadRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	c, err := Load(dir)
+	if err != nil || c != nil {
+		t.Fatalf("Load() on empty dir = %v, %v; want nil, nil", c, err)
+	}
+
+	if err := Save(dir, single("sbx-1", "web")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(Path(dir))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+
+	c, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if e := c.CurrentEntry(); e == nil || e.SandboxID != "sbx-1" || e.Name != "web" {
+		t.Errorf("Load() = %+v", c)
+	}
+
+	if err := Remove(dir); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(Path(dir)); !os.IsNotExist(err) {
+		t.Error("expected context file to be removed")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("left files behind: %v", entries)
+	}
+}
+
+func TestUpdateKeepsUnchangedFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	if err := Save(dir, single("sbx-1", "")); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(Path(dir), 0644)
+
+	err := Update(dir, func(c *Context) error {
+		if !c.Drop("sbx-other") {
+			return nil
+		}
+		t.Error("Drop() of unknown sandbox reported a change")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	info, _ := os.Stat(Path(dir))
+	if perm := info.Mode().Perm(); perm != 0644 {
+		t.Errorf("file was rewritten: permissions = %o", perm)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := Update(dir, func(c *Context) error {
+				c.Put(Entry{SandboxID: fmt.Sprintf("sbx-%d", i)})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	c, err := Load(dir)
+	if err != nil || c == nil {
+		t.Fatalf("Load() after concurrent updates = %+v, %v", c, err)
+	}
+	if len(c.Sandboxes) != 20 {
+		t.Errorf("got %d sandboxes, want 20 (updates were lost)", len(c.Sandboxes))
+	}
+
+	tmps, _ := filepath.Glob(filepath.Join(dir, FileName+".tmp-*"))
+	if len(tmps) != 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+}
+
+func TestDrop(t *testing.T) {
+	c := &Context{}
+	c.Put(Entry{SandboxID: "sbx-dev", Name: "dev"})
+	c.Put(Entry{SandboxID: "sbx-test", Name: "test"})
+	c.Put(Entry{SandboxID: "sbx-ci", Name: "ci"})
+
+	c.Drop("sbx-ci")
+	if c.CurrentEntry() != nil {
+		t.Errorf("expected no current sandbox with two left, got %+v", c.CurrentEntry())
+	}
+
+	c.Current = "sbx-dev"
+	c.Drop("sbx-dev")
+	if e := c.CurrentEntry(); e == nil || e.Name != "test" {
+		t.Errorf("expected the last sandbox to become current, got %+v", e)
+	}
+	if c.Find("test") == nil || c.Find("sbx-test") == nil {
+		t.Error("Find() should match by name and ID")
+	}
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(Path(dir), []byte("sandbox_id: sbx-old\nname: old\n"), 0600)
+
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if e := c.CurrentEntry(); e == nil || e.SandboxID != "sbx-old" || len(c.Sandboxes) != 1 {
+		t.Errorf("Load() = %+v", c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(Path(dir), []byte("sandbox_id: [\n"), 0600)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("expected error for invalid file")
+	}
+}
+
+func single(id, name string) *Context {
+	c := &Context{}
+	c.Put(Entry{SandboxID: id, Name: name})
+	return c
+}
@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/achronon/cvps/internal/remote"
+)
+
+// CommandRunner runs a command on the sandbox with stdin attached.
+// remote.Client implements it.
+type CommandRunner interface {
+	Run(ctx context.Context, cmd string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+// RunArchive migrates files as one gzipped tar stream piped into tar on the
+// sandbox. A single SSH channel carries everything, which is far faster than
+// per-file transfers for trees of many small files.
+func (m *Migrator) RunArchive(ctx context.Context, files *ScanResult, runner CommandRunner, onProgress func(int64)) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	result := &Result{}
+	writeErr := make(chan error, 1)
+	go func() {
+		err := WriteArchive(ctx, pw, files, func(n int64, file bool) {
+			if file {
+				result.FilesTransferred++
+			}
+			result.BytesTransferred = n
+			if onProgress != nil {
+				onProgress(n)
+			}
+		})
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	dir := remote.Quote(m.config.RemotePath)
+	var stderr bytes.Buffer
+	runErr := runner.Run(ctx, fmt.Sprintf("mkdir -p %s && tar -xzf - -C %s", dir, dir), pr, io.Discard, &stderr)
+	// Unblock the writer if the remote side stopped reading early
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err := <-writeErr; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		return result, err
+	}
+	if runErr != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return result, fmt.Errorf("remote tar failed: %w: %s", runErr, msg)
+		}
+		return result, fmt.Errorf("remote tar failed: %w", runErr)
+	}
+	return result, nil
+}
+
+// WriteArchive writes files to w as a gzipped tar stream. onProgress is
+// called with the cumulative content bytes written, and with file set once
+// each file is complete.
+func WriteArchive(ctx context.Context, w io.Writer, files *ScanResult, onProgress func(n int64, file bool)) error {
+	zw, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+
+	var written int64
+	for _, f := range files.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := writeArchiveFile(tw, f, func(n int64) {
+			if onProgress != nil {
+				onProgress(written+n, false)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", f.RelPath, err)
+		}
+		written += n
+		if onProgress != nil {
+			onProgress(written, true)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeArchiveFile(tw *tar.Writer, f FileInfo, onProgress func(int64)) (int64, error) {
+	src, err := os.Open(f.AbsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	// Use the size at open time; the file may have changed since the scan
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     filepath.ToSlash(f.RelPath),
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  time.Unix(f.ModTime, 0),
+		Format:   tar.FormatPAX,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(tw, &progressReader{r: io.LimitReader(src, hdr.Size), onProgress: onProgress})
+	if err != nil {
+		return n, err
+	}
+	if n < hdr.Size {
+		return n, fmt.Errorf("file shrank while archiving")
+	}
+	return n, nil
+}
+
+// progressReader reports the cumulative bytes read from r
+type progressReader struct {
+	r          io.Reader
+	n          int64
+	onProgress func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	if n > 0 && p.onProgress != nil {
+		p.onProgress(p.n)
+	}
+	return n, err
+}
@@ -0,0 +1,112 @@
+package migration
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// extractRunner stands in for the sandbox, unpacking the stream into dir
+type extractRunner struct {
+	dir string
+	cmd string
+	err error
+}
+
+func (r *extractRunner) Run(ctx context.Context, cmd string, stdin io.Reader, stdout, stderr io.Writer) error {
+	r.cmd = cmd
+	if r.err != nil {
+		fmt.Fprint(stderr, "tar: cannot open: Permission denied")
+		return r.err
+	}
+
+	zr, err := gzip.NewReader(stdin)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(r.dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, os.FileMode(hdr.Mode)); err != nil {
+			return err
+		}
+	}
+}
+
+func TestRunArchive(t *testing.T) {
+	src := t.TempDir()
+	for i := 0; i < 50; i++ {
+		path := filepath.Join(src, "node_modules", fmt.Sprintf("pkg%d", i), "index.js")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(fmt.Sprintf("module.exports = %d\n", i)), 0644)
+	}
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	files, err := NewScanner(src, nil).Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	runner := &extractRunner{dir: dst}
+	var lastProgress int64
+	m := NewMigrator(Config{LocalPath: src, RemotePath: "/workspace"})
+	result, err := m.RunArchive(context.Background(), files, runner, func(n int64) { lastProgress = n })
+	if err != nil {
+		t.Fatalf("RunArchive() error = %v", err)
+	}
+
+	if !strings.Contains(runner.cmd, "tar -xzf - -C /workspace") {
+		t.Errorf("remote command = %q", runner.cmd)
+	}
+	if result.FilesTransferred != files.Count || result.BytesTransferred != files.TotalSize {
+		t.Errorf("result = %+v, want %d files, %d bytes", result, files.Count, files.TotalSize)
+	}
+	if lastProgress != files.TotalSize {
+		t.Errorf("last progress = %d, want %d", lastProgress, files.TotalSize)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "node_modules", "pkg7", "index.js"))
+	if err != nil || string(data) != "module.exports = 7\n" {
+		t.Errorf("extracted file = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "run.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("run.sh mode not preserved: %v, %v", info, err)
+	}
+}
+
+func TestRunArchive_RemoteError(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+	files, err := NewScanner(src, nil).Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMigrator(Config{LocalPath: src, RemotePath: "/workspace"})
+	_, err = m.RunArchive(context.Background(), files, &extractRunner{err: errors.New("exit status 2")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Fatalf("RunArchive() error = %v, want remote tar failure with stderr", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 )
 
 // Config contains configuration for the migration process
@@ -73,3 +74,40 @@ func (m *Migrator) Run(ctx context.Context, files *ScanResult, onProgress func(i
 		BytesTransferred: files.TotalSize,
 	}, nil
 }
+
+// Uploader transfers a single file. It is used instead of rsync when the
+// sandbox cannot be reached over SSH.
+type Uploader interface {
+	UploadFile(ctx context.Context, localPath, remotePath string, mode os.FileMode, size int64, onProgress func(int64)) error
+}
+
+// RunUpload migrates files one at a time through up, calling onProgress with
+// the cumulative bytes transferred
+func (m *Migrator) RunUpload(ctx context.Context, files *ScanResult, up Uploader, onProgress func(int64)) (*Result, error) {
+	result := &Result{}
+
+	for _, f := range files.Files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		done := result.BytesTransferred
+		remotePath := path.Join(m.config.RemotePath, f.RelPath)
+		err := up.UploadFile(ctx, f.AbsPath, remotePath, f.Mode, f.Size, func(n int64) {
+			if onProgress != nil {
+				onProgress(done + n)
+			}
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to upload %s: %w", f.RelPath, err)
+		}
+
+		result.FilesTransferred++
+		result.BytesTransferred += f.Size
+		if onProgress != nil {
+			onProgress(result.BytesTransferred)
+		}
+	}
+
+	return result, nil
+}
@@ -2,6 +2,7 @@ package migration
 
 import (
 	"context"
+	"os"
 	"testing"
 )
 
@@ -167,3 +168,57 @@ func TestMigrator_Run_EmptyFileSet(t *testing.T) {
 		t.Log("rsync may succeed or fail depending on SSH availability")
 	}
 }
+
+type recordingUploader struct {
+	paths []string
+	fail  string
+}
+
+func (u *recordingUploader) UploadFile(ctx context.Context, localPath, remotePath string, mode os.FileMode, size int64, onProgress func(int64)) error {
+	if remotePath == u.fail {
+		return os.ErrPermission
+	}
+	u.paths = append(u.paths, remotePath)
+	onProgress(size)
+	return nil
+}
+
+func TestMigrator_RunUpload(t *testing.T) {
+	migrator := NewMigrator(Config{LocalPath: "/src", RemotePath: "/workspace"})
+	files := &ScanResult{
+		Files: []FileInfo{
+			{RelPath: "main.go", AbsPath: "/src/main.go", Size: 100},
+			{RelPath: "pkg/util.go", AbsPath: "/src/pkg/util.go", Size: 50},
+		},
+		Count:     2,
+		TotalSize: 150,
+	}
+
+	up := &recordingUploader{}
+	var progress []int64
+	result, err := migrator.RunUpload(context.Background(), files, up, func(n int64) {
+		progress = append(progress, n)
+	})
+	if err != nil {
+		t.Fatalf("RunUpload() error = %v", err)
+	}
+
+	if result.FilesTransferred != 2 || result.BytesTransferred != 150 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(up.paths) != 2 || up.paths[1] != "/workspace/pkg/util.go" {
+		t.Errorf("unexpected remote paths: %v", up.paths)
+	}
+	if progress[len(progress)-1] != 150 {
+		t.Errorf("final progress = %d, want 150", progress[len(progress)-1])
+	}
+
+	up = &recordingUploader{fail: "/workspace/pkg/util.go"}
+	result, err = migrator.RunUpload(context.Background(), files, up, nil)
+	if err == nil {
+		t.Fatal("RunUpload() expected error")
+	}
+	if result.FilesTransferred != 1 {
+		t.Errorf("FilesTransferred = %d, want 1 before failure", result.FilesTransferred)
+	}
+}
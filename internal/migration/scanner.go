@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/gobwas/glob"
 )
@@ -71,17 +72,11 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 		}
 
 		// Check exclusions
-		for _, g := range s.excludes {
-			// Try matching against relative path with and without trailing slash for directories
+		if s.excluded(filepath.ToSlash(relPath), info.Name(), info.IsDir()) {
 			if info.IsDir() {
-				if g.Match(relPath+"/") || g.Match(relPath) || g.Match(info.Name()+"/") || g.Match(info.Name()) {
-					return filepath.SkipDir
-				}
-			} else {
-				if g.Match(relPath) || g.Match(info.Name()) {
-					return nil
-				}
+				return filepath.SkipDir
 			}
+			return nil
 		}
 
 		// Skip directories and symlinks
@@ -104,3 +99,41 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 	return result, err
 }
+
+// excluded matches a single path against the exclusion patterns, trying
+// directories with and without a trailing slash
+func (s *Scanner) excluded(relPath, name string, isDir bool) bool {
+	for _, g := range s.excludes {
+		if isDir {
+			if g.Match(relPath+"/") || g.Match(relPath) || g.Match(name+"/") || g.Match(name) {
+				return true
+			}
+		} else if g.Match(relPath) || g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excludes reports whether a slash-separated file path relative to the root, or
+// any of its parent directories, matches an exclusion pattern. It applies the
+// same rules as Scan to paths that were not found by walking the local tree.
+func (s *Scanner) Excludes(relPath string) bool {
+	return s.excludesPath(relPath, false)
+}
+
+// ExcludesDir is like Excludes for a directory path
+func (s *Scanner) ExcludesDir(relPath string) bool {
+	return s.excludesPath(relPath, true)
+}
+
+func (s *Scanner) excludesPath(relPath string, isDir bool) bool {
+	parts := strings.Split(relPath, "/")
+	for i := range parts {
+		dir := isDir || i < len(parts)-1
+		if s.excluded(strings.Join(parts[:i+1], "/"), parts[i], dir) {
+			return true
+		}
+	}
+	return false
+}
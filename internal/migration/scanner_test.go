@@ -169,3 +169,40 @@ func TestScanner_NonExistentDirectory(t *testing.T) {
 		t.Error("expected error for non-existent directory")
 	}
 }
+
+func TestScanner_Excludes(t *testing.T) {
+	scanner := NewScanner("/unused", []string{"node_modules/", "*.log", ".git/"})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "src/main.go", want: false},
+		{path: "node_modules/react/index.js", want: true},
+		{path: "web/node_modules/lib.js", want: true},
+		{path: "debug.log", want: true},
+		{path: "logs/app.log", want: true},
+		{path: ".git/HEAD", want: true},
+		{path: ".gitignore", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := scanner.Excludes(tt.path); got != tt.want {
+			t.Errorf("Excludes(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanner_ExcludesDir(t *testing.T) {
+	scanner := NewScanner("/unused", []string{"node_modules/"})
+
+	if !scanner.ExcludesDir("node_modules") {
+		t.Error("ExcludesDir(node_modules) = false, want true")
+	}
+	if scanner.Excludes("node_modules") {
+		t.Error("Excludes(node_modules) = true for a file, want false")
+	}
+	if scanner.ExcludesDir("src") {
+		t.Error("ExcludesDir(src) = true, want false")
+	}
+}
@@ -0,0 +1,129 @@
+package mutagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ForwardConfig contains configuration for creating a forwarding session
+type ForwardConfig struct {
+	Name       string
+	RemoteHost string // user@host
+	RemotePort int
+	LocalPort  int
+	TargetPort int // port on the remote host's localhost
+	Labels     map[string]string
+}
+
+// ForwardStatus describes a forwarding session
+type ForwardStatus struct {
+	Name        string            `json:"name"`
+	Source      string            `json:"source"`
+	Destination string            `json:"destination"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// forwardArgs builds the 'mutagen forward create' arguments for cfg
+func forwardArgs(cfg ForwardConfig) []string {
+	args := []string{"forward", "create", "--name", cfg.Name}
+	keys := make([]string, 0, len(cfg.Labels))
+	for k := range cfg.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--label", k+"="+cfg.Labels[k])
+	}
+
+	// Mutagen expects user@host[:port]:tcp:address for SSH endpoints
+	remote := cfg.RemoteHost
+	if cfg.RemotePort != 0 && cfg.RemotePort != 22 {
+		remote = fmt.Sprintf("%s:%d", remote, cfg.RemotePort)
+	}
+	return append(args,
+		fmt.Sprintf("tcp:localhost:%d", cfg.LocalPort),
+		fmt.Sprintf("%s:tcp:localhost:%d", remote, cfg.TargetPort),
+	)
+}
+
+// CreateForward creates a forwarding session managed by the mutagen daemon
+func CreateForward(cfg ForwardConfig) error {
+	output, err := exec.Command("mutagen", forwardArgs(cfg)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mutagen forward create failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// parseForwards decodes 'mutagen forward list' JSON output
+func parseForwards(output []byte) ([]ForwardStatus, error) {
+	var sessions []struct {
+		Name   string `json:"name"`
+		Source struct {
+			Endpoint string `json:"endpoint"`
+		} `json:"source"`
+		Destination struct {
+			Host     string `json:"host"`
+			Endpoint string `json:"endpoint"`
+		} `json:"destination"`
+		Status string            `json:"status"`
+		Paused bool              `json:"paused"`
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse forward list: %w", err)
+	}
+
+	forwards := make([]ForwardStatus, 0, len(sessions))
+	for _, s := range sessions {
+		status := s.Status
+		if s.Paused {
+			status = "paused"
+		}
+		dest := s.Destination.Endpoint
+		if s.Destination.Host != "" {
+			dest = s.Destination.Host + ":" + dest
+		}
+		forwards = append(forwards, ForwardStatus{
+			Name:        s.Name,
+			Source:      s.Source.Endpoint,
+			Destination: dest,
+			Status:      status,
+			Labels:      s.Labels,
+		})
+	}
+	return forwards, nil
+}
+
+// ListForwards lists forwarding sessions whose names start with prefix
+func ListForwards(prefix string) ([]ForwardStatus, error) {
+	output, err := exec.Command("mutagen", "forward", "list", "--template", "{{json .}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list forwards: %w", err)
+	}
+	all, err := parseForwards(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var forwards []ForwardStatus
+	for _, f := range all {
+		if strings.HasPrefix(f.Name, prefix) {
+			forwards = append(forwards, f)
+		}
+	}
+	return forwards, nil
+}
+
+// TerminateForward terminates a forwarding session by name
+func TerminateForward(name string) error {
+	output, err := exec.Command("mutagen", "forward", "terminate", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to terminate forward: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package mutagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForwardArgs(t *testing.T) {
+	got := forwardArgs(ForwardConfig{
+		Name:       "cvps-sbx1-3000",
+		RemoteHost: "dev@ssh.example.com",
+		RemotePort: 2222,
+		LocalPort:  3000,
+		TargetPort: 80,
+		Labels:     map[string]string{"cvps-sandbox": "sbx1", "a": "b"},
+	})
+	want := []string{
+		"forward", "create", "--name", "cvps-sbx1-3000",
+		"--label", "a=b", "--label", "cvps-sandbox=sbx1",
+		"tcp:localhost:3000", "dev@ssh.example.com:2222:tcp:localhost:80",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forwardArgs() = %q, want %q", got, want)
+	}
+
+	got = forwardArgs(ForwardConfig{Name: "f", RemoteHost: "dev@host", RemotePort: 22, LocalPort: 1, TargetPort: 1})
+	if last := got[len(got)-1]; last != "dev@host:tcp:localhost:1" {
+		t.Errorf("destination with default port = %q", last)
+	}
+}
+
+func TestParseForwards(t *testing.T) {
+	output := []byte(`[
+		{"name":"cvps-sbx1-3000","source":{"endpoint":"tcp:localhost:3000"},"destination":{"host":"ssh.example.com","endpoint":"tcp:localhost:3000"},"status":"forwarding","labels":{"cvps-sandbox":"sbx1"}},
+		{"name":"other","source":{"endpoint":"tcp:localhost:9000"},"destination":{"endpoint":"tcp:localhost:9000"},"status":"connecting-destination","paused":true}
+	]`)
+
+	got, err := parseForwards(output)
+	if err != nil {
+		t.Fatalf("parseForwards() error = %v", err)
+	}
+	want := []ForwardStatus{
+		{
+			Name:        "cvps-sbx1-3000",
+			Source:      "tcp:localhost:3000",
+			Destination: "ssh.example.com:tcp:localhost:3000",
+			Status:      "forwarding",
+			Labels:      map[string]string{"cvps-sandbox": "sbx1"},
+		},
+		{Name: "other", Source: "tcp:localhost:9000", Destination: "tcp:localhost:9000", Status: "paused"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseForwards() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseForwards([]byte("not json")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
@@ -0,0 +1,142 @@
+package mutagen
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// State is one update from 'mutagen sync monitor'
+type State struct {
+	Status    string            `json:"status"`
+	Alpha     EndpointState     `json:"alpha"`
+	Beta      EndpointState     `json:"beta"`
+	Conflicts []json.RawMessage `json:"conflicts"`
+}
+
+// EndpointState is the progress of one side of a session
+type EndpointState struct {
+	Connected          bool             `json:"connected"`
+	Scanned            bool             `json:"scanned"`
+	Files              uint64           `json:"files"`
+	TotalFileSize      uint64           `json:"totalFileSize"`
+	StagingProgress    *StagingProgress `json:"stagingProgress"`
+	ScanProblems       []Problem        `json:"scanProblems"`
+	TransitionProblems []Problem        `json:"transitionProblems"`
+}
+
+// StagingProgress describes files being received by an endpoint
+type StagingProgress struct {
+	Path              string `json:"path"`
+	ReceivedSize      uint64 `json:"receivedSize"`
+	ExpectedSize      uint64 `json:"expectedSize"`
+	ReceivedFiles     uint64 `json:"receivedFiles"`
+	ExpectedFiles     uint64 `json:"expectedFiles"`
+	TotalReceivedSize uint64 `json:"totalReceivedSize"`
+}
+
+// Problem is a path mutagen could not scan or apply
+type Problem struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Staging returns the staging progress of whichever endpoint is receiving
+// files, or nil
+func (s *State) Staging() *StagingProgress {
+	if s.Beta.StagingProgress != nil {
+		return s.Beta.StagingProgress
+	}
+	return s.Alpha.StagingProgress
+}
+
+// Problems returns the scan and transition problems of both endpoints
+func (s *State) Problems() []Problem {
+	var problems []Problem
+	for _, e := range []EndpointState{s.Alpha, s.Beta} {
+		problems = append(problems, e.ScanProblems...)
+		problems = append(problems, e.TransitionProblems...)
+	}
+	return problems
+}
+
+// StatusText describes the session status for people
+func (s *State) StatusText() string {
+	switch {
+	case s.Status == "watching":
+		return "Watching for changes"
+	case s.Status == "scanning":
+		return "Scanning files"
+	case s.Status == "reconciling":
+		return "Reconciling changes"
+	case strings.HasPrefix(s.Status, "staging"):
+		return "Staging files"
+	case s.Status == "transitioning":
+		return "Applying changes"
+	case s.Status == "saving":
+		return "Saving archive"
+	case strings.HasPrefix(s.Status, "connecting"):
+		return "Connecting"
+	case strings.HasPrefix(s.Status, "halted"):
+		return "Halted"
+	case s.Status == "":
+		return "Unknown"
+	}
+	text := strings.ReplaceAll(s.Status, "-", " ")
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// parseState decodes one line of templated monitor output, which is a session
+// object or, for some mutagen versions, a list holding one
+func parseState(line []byte) (*State, error) {
+	var s State
+	if err := json.Unmarshal(line, &s); err == nil {
+		return &s, nil
+	}
+	var list []State
+	if err := json.Unmarshal(line, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse monitor output: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("monitor output has no session")
+	}
+	return &list[0], nil
+}
+
+// MonitorStates runs 'mutagen sync monitor' until ctx is done, calling fn
+// with every state update. It fails without calling fn if the installed
+// mutagen cannot produce JSON output.
+func (s *Session) MonitorStates(ctx context.Context, fn func(*State)) error {
+	cmd := exec.CommandContext(ctx, "mutagen", "sync", "monitor", "--template", "{{json .}}\n", s.Name)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start mutagen monitor: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		state, err := parseState(line)
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return err
+		}
+		fn(state)
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("mutagen monitor failed: %w", err)
+	}
+	return nil
+}
@@ -161,6 +161,16 @@ func GetSessionStatus(name string) (*SessionStatus, error) {
 	}, nil
 }
 
+// FlushSession blocks until pending changes in a sync session have been propagated
+func FlushSession(name string) error {
+	cmd := exec.Command("mutagen", "sync", "flush", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to flush session: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // TerminateSession terminates a sync session by name
 func TerminateSession(name string) error {
 	cmd := exec.Command("mutagen", "sync", "terminate", name)
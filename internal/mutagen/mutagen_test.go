@@ -118,3 +118,34 @@ func TestSessionStatus(t *testing.T) {
 // are integration tests that require Mutagen to be installed and would need
 // a real or mocked Mutagen setup. These would be better suited for integration
 // tests rather than unit tests.
+
+func TestParseState(t *testing.T) {
+	line := []byte(`{"name":"cvps-sbx","status":"staging-beta","alpha":{"connected":true,"scanProblems":[{"path":"secret.key","error":"permission denied"}]},"beta":{"connected":true,"stagingProgress":{"path":"node_modules/a/index.js","receivedFiles":12,"expectedFiles":40,"totalReceivedSize":1024,"expectedSize":4096}},"conflicts":[{}]}`)
+
+	state, err := parseState(line)
+	if err != nil {
+		t.Fatalf("parseState() error = %v", err)
+	}
+	if state.StatusText() != "Staging files" {
+		t.Errorf("StatusText() = %q", state.StatusText())
+	}
+	if p := state.Staging(); p == nil || p.ReceivedFiles != 12 || p.ExpectedSize != 4096 {
+		t.Errorf("Staging() = %+v", p)
+	}
+	if problems := state.Problems(); len(problems) != 1 || problems[0].Path != "secret.key" {
+		t.Errorf("Problems() = %+v", problems)
+	}
+	if len(state.Conflicts) != 1 {
+		t.Errorf("Conflicts = %d, want 1", len(state.Conflicts))
+	}
+
+	// Some versions print a list holding the session
+	state, err = parseState([]byte(`[{"status":"watching"}]`))
+	if err != nil || state.StatusText() != "Watching for changes" {
+		t.Errorf("parseState(list) = %+v, %v", state, err)
+	}
+
+	if _, err := parseState([]byte("Status: Watching for changes")); err == nil {
+		t.Error("expected error for plain text monitor output")
+	}
+}
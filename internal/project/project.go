@@ -0,0 +1,106 @@
+// Package project reads cvps.project.yaml, the settings a project checks in
+// to describe how its sandboxes are set up. Unlike .cvps.yaml, which cvps
+// writes, this file is written by people and never changed by cvps.
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the project config file in the project directory
+const FileName = "cvps.project.yaml"
+
+// Config is the project config
+type Config struct {
+	// How 'cvps up --bootstrap' sets up a new sandbox
+	Bootstrap *Bootstrap `yaml:"bootstrap,omitempty"`
+
+	// Settings of ~/.cvps/config.yaml overridden for commands run in the
+	// project, e.g. defaults.cpu_cores. Which keys are allowed is up to the
+	// config package.
+	Config map[string]any `yaml:"config,omitempty"`
+}
+
+// Bootstrap is a workspace template: the sandbox to create and the steps that
+// make it ready to work in. Resources left unset come from the user's config
+// defaults.
+type Bootstrap struct {
+	Name        string            `yaml:"name,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	CPUCores    int               `yaml:"cpu_cores,omitempty"`
+	MemoryGB    int               `yaml:"memory_gb,omitempty"`
+	StorageGB   int               `yaml:"storage_gb,omitempty"`
+	Image       string            `yaml:"image,omitempty"`
+	Region      string            `yaml:"region,omitempty"`
+	Arch        string            `yaml:"arch,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+
+	// Names of the secrets the sandbox expects as environment variables.
+	// Values are never stored here; set them with 'cvps secrets set'.
+	Env []string `yaml:"env,omitempty"`
+
+	// Ports forwarded to localhost in the background, as "port" or
+	// "local:remote"
+	Ports []string `yaml:"ports,omitempty"`
+
+	// Shell script run in /workspace once the sandbox is up
+	Setup string `yaml:"setup,omitempty"`
+
+	// Upload the project directory (default true)
+	Migrate *bool `yaml:"migrate,omitempty"`
+
+	// Patterns left out of the upload and the sync, in addition to
+	// sync.ignore_patterns from the config
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Start file sync afterwards (default true)
+	Sync *bool `yaml:"sync,omitempty"`
+}
+
+// MigrateEnabled reports whether the project directory is uploaded
+func (b *Bootstrap) MigrateEnabled() bool {
+	return b.Migrate == nil || *b.Migrate
+}
+
+// SyncEnabled reports whether file sync is started
+func (b *Bootstrap) SyncEnabled() bool {
+	return b.Sync == nil || *b.Sync
+}
+
+// Load reads the project config in dir. A missing file yields nil.
+func Load(dir string) (*Config, error) {
+	path := filepath.Join(dir, FileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+
+	// Unknown keys are rejected so a misspelt setting isn't silently ignored
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return &c, nil
+}
+
+// Write encodes c in the project config format
+func Write(w io.Writer, c *Config) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", FileName, err)
+	}
+	return enc.Close()
+}
@@ -0,0 +1,58 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	c, err := Load(t.TempDir())
+	if err != nil || c != nil {
+		t.Errorf("Load() = %v, %v, want nil, nil", c, err)
+	}
+}
+
+func TestLoad_Bootstrap(t *testing.T) {
+	dir := t.TempDir()
+	data := `bootstrap:
+  name: shop
+  cpu_cores: 4
+  image: node:20
+  setup: |
+    npm ci
+  sync: false
+  exclude: [node_modules]
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	b := c.Bootstrap
+	if b == nil || b.Name != "shop" || b.CPUCores != 4 || b.Image != "node:20" || b.Setup != "npm ci\n" {
+		t.Fatalf("Unexpected bootstrap: %+v", b)
+	}
+	if !b.MigrateEnabled() || b.SyncEnabled() {
+		t.Errorf("Expected migrate on by default and sync off, got %v and %v", b.MigrateEnabled(), b.SyncEnabled())
+	}
+	if len(b.Exclude) != 1 || b.Exclude[0] != "node_modules" {
+		t.Errorf("Unexpected exclude: %v", b.Exclude)
+	}
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	data := "bootstrap:\n  cpus: 4\n"
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "cpus") {
+		t.Errorf("Expected an error naming the unknown key, got %v", err)
+	}
+}
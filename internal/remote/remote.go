@@ -0,0 +1,226 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// defaultKeyFiles are the private keys tried when no SSH agent is available
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Endpoint identifies an SSH server
+type Endpoint struct {
+	Host string
+	Port int
+	User string
+}
+
+// Address returns the host:port form of the endpoint
+func (e Endpoint) Address() string {
+	port := e.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(e.Host, strconv.Itoa(port))
+}
+
+// Client is an SSH connection to a sandbox
+type Client struct {
+	conn *ssh.Client
+}
+
+// ExitError is returned by Run when the remote command exits non-zero
+type ExitError struct {
+	Status int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("remote command exited with status %d", e.Status)
+}
+
+// Dial connects to the endpoint using the SSH agent and default key files
+func Dial(ctx context.Context, ep Endpoint) (*Client, error) {
+	methods := authMethods()
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no SSH credentials found (start ssh-agent or create ~/.ssh/id_ed25519)")
+	}
+
+	cfg := &ssh.ClientConfig{
+		User: ep.User,
+		Auth: methods,
+		// Sandbox host keys are regenerated on every provision, matching the
+		// UserKnownHostsFile=/dev/null behaviour of the ssh-based commands.
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         30 * time.Second,
+	}
+
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", ep.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", ep.Address(), err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, ep.Address(), cfg)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("ssh handshake failed: %w", err)
+	}
+
+	return &Client{conn: ssh.NewClient(sshConn, chans, reqs)}, nil
+}
+
+func authMethods() []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return methods
+	}
+
+	var signers []ssh.Signer
+	for _, name := range defaultKeyFiles {
+		data, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err != nil {
+			continue
+		}
+		// Passphrase-protected keys are expected to be served by the agent
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	return methods
+}
+
+// SSH returns the underlying connection for subsystems such as SFTP
+func (c *Client) SSH() *ssh.Client {
+	return c.conn
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Run executes cmd on the remote host, wiring the given streams. Any of them may be nil.
+// Cancelling ctx closes the session.
+func (c *Client) Run(ctx context.Context, cmd string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = session.Signal(ssh.SIGTERM)
+			session.Close()
+		case <-done:
+		}
+	}()
+
+	err = session.Run(cmd)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Status: exitErr.ExitStatus()}
+	}
+	return err
+}
+
+// Output runs cmd and returns its standard output. Standard error is included in
+// the returned error when the command fails.
+func (c *Client) Output(ctx context.Context, cmd string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	if err := c.Run(ctx, cmd, nil, &stdout, &stderr); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return stdout.Bytes(), fmt.Errorf("%w: %s", err, msg)
+		}
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Forward accepts connections on ln and tunnels each one to addr as seen from
+// the remote host. It returns when ln is closed.
+func (c *Client) Forward(ln net.Listener, addr string) error {
+	return forward(ln, func() (net.Conn, error) {
+		return c.conn.Dial("tcp", addr)
+	})
+}
+
+func forward(ln net.Listener, dial func() (net.Conn, error)) error {
+	for {
+		local, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer local.Close()
+			upstream, err := dial()
+			if err != nil {
+				return
+			}
+			defer upstream.Close()
+
+			done := make(chan struct{}, 2)
+			go func() { io.Copy(upstream, local); done <- struct{}{} }()
+			go func() { io.Copy(local, upstream); done <- struct{}{} }()
+			<-done
+		}()
+	}
+}
+
+// Quote returns s quoted for safe use as a single POSIX shell word
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@,+%", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,85 @@
+package remote
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: "''"},
+		{in: "/workspace/file.txt", want: "/workspace/file.txt"},
+		{in: "hello world", want: "'hello world'"},
+		{in: "it's", want: `'it'\''s'`},
+		{in: "$(rm -rf /)", want: "'$(rm -rf /)'"},
+	}
+
+	for _, tt := range tests {
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("Quote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointAddress(t *testing.T) {
+	if got := (Endpoint{Host: "sandbox.example.com"}).Address(); got != "sandbox.example.com:22" {
+		t.Errorf("Address() = %q, want default port 22", got)
+	}
+	if got := (Endpoint{Host: "10.0.0.1", Port: 2222}).Address(); got != "10.0.0.1:2222" {
+		t.Errorf("Address() = %q, want 10.0.0.1:2222", got)
+	}
+}
+
+func TestForward(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- forward(ln, func() (net.Conn, error) { return net.Dial("tcp", echo.Addr().String()) })
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if string(buf) != "ping" {
+		t.Errorf("got %q through the tunnel, want ping", buf)
+	}
+
+	ln.Close()
+	if err := <-done; err != nil {
+		t.Errorf("forward() after close = %v, want nil", err)
+	}
+}
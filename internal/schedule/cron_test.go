@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"0 19 * *":       "must have 5 fields",
+		"60 19 * * *":    "minute 60 out of range",
+		"0 19 * * 1-":    "invalid value",
+		"0 19 * * 5-1":   "invalid range",
+		"*/0 * * * *":    "invalid step",
+		"0 19 * foo *":   "invalid value \"foo\" in month field",
+		"@fortnightly":   "unknown cron macro",
+		"0 24 * * mon":   "hour 24 out of range",
+		"0 8 32 * *":     "day of month 32 out of range",
+		"0 8 * * mon-fr": "invalid value",
+	}
+	for expr, want := range tests {
+		_, err := Parse(expr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	// Friday 2024-03-29 18:30 in Berlin
+	from := time.Date(2024, 3, 29, 18, 30, 0, 0, berlin)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 19 * * 1-5", time.Date(2024, 3, 29, 19, 0, 0, 0, berlin)},
+		// Weekday mornings skip the weekend and the DST change on Sunday
+		{"0 8 * * mon-fri", time.Date(2024, 4, 1, 8, 0, 0, 0, berlin)},
+		{"*/15 * * * *", time.Date(2024, 3, 29, 18, 45, 0, 0, berlin)},
+		{"@daily", time.Date(2024, 3, 30, 0, 0, 0, 0, berlin)},
+		{"0 9 * * 7", time.Date(2024, 3, 31, 9, 0, 0, 0, berlin)},
+		// Day of month and day of week match if either does
+		{"0 12 1 * sat", time.Date(2024, 3, 30, 12, 0, 0, 0, berlin)},
+		{"30 18 * * *", time.Date(2024, 3, 30, 18, 30, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+		}
+		if got := e.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	e, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %s, want zero time", got)
+	}
+}
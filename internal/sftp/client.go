@@ -0,0 +1,500 @@
+package sftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// chunkSize is the payload size of individual read and write requests
+const chunkSize = 32 * 1024
+
+// Client is a minimal SFTP version 3 client covering the file operations cvps
+// needs without external binaries. Requests are issued one at a time.
+type Client struct {
+	r       io.Reader
+	w       io.WriteCloser
+	session *ssh.Session
+
+	mu     sync.Mutex
+	nextID uint32
+}
+
+// NewClient starts the sftp subsystem on an SSH connection
+func NewClient(conn *ssh.Client) (*Client, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session: %w", err)
+	}
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("sftp subsystem unavailable: %w", err)
+	}
+
+	c, err := NewClientPipe(r, w)
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	c.session = session
+	return c, nil
+}
+
+// NewClientPipe speaks SFTP over an arbitrary stream pair, performing the version handshake
+func NewClientPipe(r io.Reader, w io.WriteCloser) (*Client, error) {
+	c := &Client{r: r, w: w}
+
+	var b buffer
+	b.byte(fxpInit)
+	b.uint32(3)
+	if _, err := w.Write(b.frame()); err != nil {
+		return nil, fmt.Errorf("sftp handshake failed: %w", err)
+	}
+
+	typ, data, err := readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("sftp handshake failed: %w", err)
+	}
+	if typ != fxpVersion {
+		return nil, fmt.Errorf("sftp handshake failed: unexpected packet type %d", typ)
+	}
+	rd := reader{b: data}
+	if version := rd.uint32(); version != 3 {
+		return nil, fmt.Errorf("sftp handshake failed: unsupported version %d", version)
+	}
+
+	return c, nil
+}
+
+// Close ends the SFTP session
+func (c *Client) Close() error {
+	err := c.w.Close()
+	if c.session != nil {
+		c.session.Close()
+	}
+	return err
+}
+
+// request sends a packet built by fill and returns the response type and payload
+func (c *Client) request(typ byte, fill func(b *buffer)) (byte, *reader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := c.nextID
+
+	var b buffer
+	b.byte(typ)
+	b.uint32(id)
+	if fill != nil {
+		fill(&b)
+	}
+	if _, err := c.w.Write(b.frame()); err != nil {
+		return 0, nil, err
+	}
+
+	respType, data, err := readPacket(c.r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rd := &reader{b: data}
+	if respID := rd.uint32(); respID != id {
+		return 0, nil, fmt.Errorf("sftp: response id %d does not match request %d", respID, id)
+	}
+	return respType, rd, nil
+}
+
+// status decodes a STATUS payload, returning nil for OK
+func status(rd *reader) error {
+	code := rd.uint32()
+	msg := rd.string()
+	if rd.err != nil {
+		return rd.err
+	}
+	if code == StatusOK {
+		return nil
+	}
+	return &StatusError{Code: code, Message: msg}
+}
+
+// expectStatus issues a request whose only successful reply is STATUS OK
+func (c *Client) expectStatus(typ byte, fill func(b *buffer)) error {
+	respType, rd, err := c.request(typ, fill)
+	if err != nil {
+		return err
+	}
+	if respType != fxpStatus {
+		return fmt.Errorf("sftp: unexpected packet type %d", respType)
+	}
+	return status(rd)
+}
+
+func (c *Client) expectHandle(typ byte, fill func(b *buffer)) (string, error) {
+	respType, rd, err := c.request(typ, fill)
+	if err != nil {
+		return "", err
+	}
+	switch respType {
+	case fxpHandle:
+		handle := rd.string()
+		return handle, rd.err
+	case fxpStatus:
+		if err := status(rd); err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("sftp: unexpected packet type %d", respType)
+}
+
+func (c *Client) expectAttrs(typ byte, fill func(b *buffer)) (fileAttrs, error) {
+	respType, rd, err := c.request(typ, fill)
+	if err != nil {
+		return fileAttrs{}, err
+	}
+	switch respType {
+	case fxpAttrs:
+		attrs := rd.attrs()
+		return attrs, rd.err
+	case fxpStatus:
+		if err := status(rd); err != nil {
+			return fileAttrs{}, err
+		}
+	}
+	return fileAttrs{}, fmt.Errorf("sftp: unexpected packet type %d", respType)
+}
+
+func pathError(op, p string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &os.PathError{Op: op, Path: p, Err: err}
+}
+
+// Stat returns file information, following symlinks
+func (c *Client) Stat(p string) (os.FileInfo, error) {
+	attrs, err := c.expectAttrs(fxpStat, func(b *buffer) { b.string(p) })
+	if err != nil {
+		return nil, pathError("stat", p, err)
+	}
+	return &FileInfo{name: path.Base(p), attrs: attrs}, nil
+}
+
+// Lstat returns file information without following symlinks
+func (c *Client) Lstat(p string) (os.FileInfo, error) {
+	attrs, err := c.expectAttrs(fxpLstat, func(b *buffer) { b.string(p) })
+	if err != nil {
+		return nil, pathError("lstat", p, err)
+	}
+	return &FileInfo{name: path.Base(p), attrs: attrs}, nil
+}
+
+// RealPath canonicalises p on the server, resolving "." and relative paths
+func (c *Client) RealPath(p string) (string, error) {
+	respType, rd, err := c.request(fxpRealpath, func(b *buffer) { b.string(p) })
+	if err != nil {
+		return "", pathError("realpath", p, err)
+	}
+	switch respType {
+	case fxpName:
+		if count := rd.uint32(); count != 1 {
+			return "", pathError("realpath", p, fmt.Errorf("sftp: expected 1 name, got %d", count))
+		}
+		name := rd.string()
+		return name, rd.err
+	case fxpStatus:
+		if err := status(rd); err != nil {
+			return "", pathError("realpath", p, err)
+		}
+	}
+	return "", pathError("realpath", p, fmt.Errorf("sftp: unexpected packet type %d", respType))
+}
+
+// ReadDir lists the entries of a directory, excluding "." and ".."
+func (c *Client) ReadDir(p string) ([]os.FileInfo, error) {
+	handle, err := c.expectHandle(fxpOpendir, func(b *buffer) { b.string(p) })
+	if err != nil {
+		return nil, pathError("readdir", p, err)
+	}
+	defer c.closeHandle(handle)
+
+	var entries []os.FileInfo
+	for {
+		respType, rd, err := c.request(fxpReaddir, func(b *buffer) { b.string(handle) })
+		if err != nil {
+			return nil, pathError("readdir", p, err)
+		}
+
+		if respType == fxpStatus {
+			if err := status(rd); err != nil && !errors.Is(err, io.EOF) {
+				return nil, pathError("readdir", p, err)
+			}
+			return entries, nil
+		}
+		if respType != fxpName {
+			return nil, pathError("readdir", p, fmt.Errorf("sftp: unexpected packet type %d", respType))
+		}
+
+		count := rd.uint32()
+		for i := uint32(0); i < count; i++ {
+			name := rd.string()
+			rd.string() // longname
+			attrs := rd.attrs()
+			if rd.err != nil {
+				return nil, pathError("readdir", p, rd.err)
+			}
+			if name == "." || name == ".." {
+				continue
+			}
+			entries = append(entries, &FileInfo{name: name, attrs: attrs})
+		}
+	}
+}
+
+// Mkdir creates a directory
+func (c *Client) Mkdir(p string) error {
+	return pathError("mkdir", p, c.expectStatus(fxpMkdir, func(b *buffer) {
+		b.string(p)
+		b.attrs(fileAttrs{flags: attrPermissions, perm: 0755})
+	}))
+}
+
+// MkdirAll creates a directory and any missing parents
+func (c *Client) MkdirAll(p string) error {
+	if info, err := c.Stat(p); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return pathError("mkdir", p, fmt.Errorf("not a directory"))
+	}
+
+	if parent := path.Dir(p); parent != p && parent != "." && parent != "/" {
+		if err := c.MkdirAll(parent); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mkdir(p); err != nil {
+		// Another writer may have created it concurrently
+		if info, statErr := c.Stat(p); statErr == nil && info.IsDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove deletes a file or empty directory
+func (c *Client) Remove(p string) error {
+	err := c.expectStatus(fxpRemove, func(b *buffer) { b.string(p) })
+	if err == nil {
+		return nil
+	}
+	if info, statErr := c.Lstat(p); statErr == nil && info.IsDir() {
+		return pathError("remove", p, c.expectStatus(fxpRmdir, func(b *buffer) { b.string(p) }))
+	}
+	return pathError("remove", p, err)
+}
+
+// Rename moves oldpath to newpath. The target must not exist.
+func (c *Client) Rename(oldpath, newpath string) error {
+	return pathError("rename", oldpath, c.expectStatus(fxpRename, func(b *buffer) {
+		b.string(oldpath)
+		b.string(newpath)
+	}))
+}
+
+// Chmod changes the permission bits of a file
+func (c *Client) Chmod(p string, mode os.FileMode) error {
+	return pathError("chmod", p, c.expectStatus(fxpSetstat, func(b *buffer) {
+		b.string(p)
+		b.attrs(fileAttrs{flags: attrPermissions, perm: uint32(mode.Perm())})
+	}))
+}
+
+// Chtimes changes the access and modification times of a file
+func (c *Client) Chtimes(p string, atime, mtime time.Time) error {
+	return pathError("chtimes", p, c.expectStatus(fxpSetstat, func(b *buffer) {
+		b.string(p)
+		b.attrs(fileAttrs{flags: attrACModTime, atime: uint32(atime.Unix()), mtime: uint32(mtime.Unix())})
+	}))
+}
+
+func (c *Client) closeHandle(handle string) error {
+	return c.expectStatus(fxpClose, func(b *buffer) { b.string(handle) })
+}
+
+// Open opens a remote file for reading
+func (c *Client) Open(p string) (*File, error) {
+	return c.OpenFile(p, os.O_RDONLY, 0)
+}
+
+// Create creates or truncates a remote file for writing
+func (c *Client) Create(p string) (*File, error) {
+	return c.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+// OpenFile opens a remote file using os.O_* flags
+func (c *Client) OpenFile(p string, flag int, perm os.FileMode) (*File, error) {
+	var pflags uint32
+	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
+	case os.O_RDONLY:
+		pflags = fxfRead
+	case os.O_WRONLY:
+		pflags = fxfWrite
+	case os.O_RDWR:
+		pflags = fxfRead | fxfWrite
+	}
+	if flag&os.O_APPEND != 0 {
+		pflags |= fxfAppend
+	}
+	if flag&os.O_CREATE != 0 {
+		pflags |= fxfCreat
+	}
+	if flag&os.O_TRUNC != 0 {
+		pflags |= fxfTrunc
+	}
+	if flag&os.O_EXCL != 0 {
+		pflags |= fxfExcl
+	}
+
+	handle, err := c.expectHandle(fxpOpen, func(b *buffer) {
+		b.string(p)
+		b.uint32(pflags)
+		if flag&os.O_CREATE != 0 {
+			b.attrs(fileAttrs{flags: attrPermissions, perm: uint32(perm.Perm())})
+		} else {
+			b.attrs(fileAttrs{})
+		}
+	})
+	if err != nil {
+		return nil, pathError("open", p, err)
+	}
+
+	return &File{c: c, path: p, handle: handle}, nil
+}
+
+// File is an open remote file. It is not safe for concurrent use.
+type File struct {
+	c      *Client
+	path   string
+	handle string
+	offset uint64
+}
+
+// Read reads up to len(p) bytes from the current offset
+func (f *File) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+
+	respType, rd, err := f.c.request(fxpRead, func(b *buffer) {
+		b.string(f.handle)
+		b.uint64(f.offset)
+		b.uint32(uint32(len(p)))
+	})
+	if err != nil {
+		return 0, pathError("read", f.path, err)
+	}
+
+	switch respType {
+	case fxpData:
+		data := rd.bytes()
+		if rd.err != nil {
+			return 0, pathError("read", f.path, rd.err)
+		}
+		n := copy(p, data)
+		f.offset += uint64(n)
+		return n, nil
+	case fxpStatus:
+		err := status(rd)
+		if err == nil || errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		return 0, pathError("read", f.path, err)
+	}
+	return 0, pathError("read", f.path, fmt.Errorf("sftp: unexpected packet type %d", respType))
+}
+
+// Write writes p at the current offset
+func (f *File) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		err := f.c.expectStatus(fxpWrite, func(b *buffer) {
+			b.string(f.handle)
+			b.uint64(f.offset)
+			b.bytes(chunk)
+		})
+		if err != nil {
+			return written, pathError("write", f.path, err)
+		}
+
+		f.offset += uint64(len(chunk))
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Stat returns information about the open file
+func (f *File) Stat() (os.FileInfo, error) {
+	attrs, err := f.c.expectAttrs(fxpFstat, func(b *buffer) { b.string(f.handle) })
+	if err != nil {
+		return nil, pathError("stat", f.path, err)
+	}
+	return &FileInfo{name: path.Base(f.path), attrs: attrs}, nil
+}
+
+// Close releases the remote handle
+func (f *File) Close() error {
+	return pathError("close", f.path, f.c.closeHandle(f.handle))
+}
+
+// ReadFile returns the contents of a remote file
+func (c *Client) ReadFile(p string) ([]byte, error) {
+	f, err := c.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile writes data to a remote file, creating or truncating it
+func (c *Client) WriteFile(p string, data []byte, perm os.FileMode) error {
+	f, err := c.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,442 @@
+package sftp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer serves a subset of SFTP v3 from a local directory
+type testServer struct {
+	root    string
+	r       io.Reader
+	w       io.Writer
+	handles map[string]*os.File
+	dirs    map[string]string
+	next    int
+}
+
+func newTestClient(t *testing.T) (*Client, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	srv := &testServer{
+		root:    root,
+		r:       serverR,
+		w:       serverW,
+		handles: make(map[string]*os.File),
+		dirs:    make(map[string]string),
+	}
+	go srv.serve()
+
+	c, err := NewClientPipe(clientR, clientW)
+	if err != nil {
+		t.Fatalf("NewClientPipe() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, root
+}
+
+func (s *testServer) local(p string) string {
+	return filepath.Join(s.root, filepath.FromSlash(p))
+}
+
+func (s *testServer) send(b *buffer) {
+	_, _ = s.w.Write(b.frame())
+}
+
+func (s *testServer) sendStatus(id uint32, err error) {
+	code := uint32(StatusOK)
+	msg := ""
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		code = StatusEOF
+	case os.IsNotExist(err):
+		code, msg = StatusNoSuchFile, "no such file"
+	case os.IsPermission(err):
+		code, msg = StatusPermissionDenied, "permission denied"
+	default:
+		code, msg = StatusFailure, err.Error()
+	}
+
+	var b buffer
+	b.byte(fxpStatus)
+	b.uint32(id)
+	b.uint32(code)
+	b.string(msg)
+	b.string("")
+	s.send(&b)
+}
+
+func attrsFromInfo(info os.FileInfo) fileAttrs {
+	perm := uint32(info.Mode().Perm())
+	switch {
+	case info.IsDir():
+		perm |= modeDir
+	case info.Mode()&os.ModeSymlink != 0:
+		perm |= modeSymlink
+	default:
+		perm |= modeRegular
+	}
+	mtime := uint32(info.ModTime().Unix())
+	return fileAttrs{
+		flags: attrSize | attrPermissions | attrACModTime,
+		size:  uint64(info.Size()),
+		perm:  perm,
+		atime: mtime,
+		mtime: mtime,
+	}
+}
+
+func (s *testServer) sendAttrs(id uint32, info os.FileInfo) {
+	var b buffer
+	b.byte(fxpAttrs)
+	b.uint32(id)
+	b.attrs(attrsFromInfo(info))
+	s.send(&b)
+}
+
+func (s *testServer) sendHandle(id uint32, handle string) {
+	var b buffer
+	b.byte(fxpHandle)
+	b.uint32(id)
+	b.string(handle)
+	s.send(&b)
+}
+
+func (s *testServer) newHandle() string {
+	s.next++
+	return string(rune('a' + s.next))
+}
+
+func (s *testServer) serve() {
+	for {
+		typ, data, err := readPacket(s.r)
+		if err != nil {
+			return
+		}
+		rd := &reader{b: data}
+
+		if typ == fxpInit {
+			var b buffer
+			b.byte(fxpVersion)
+			b.uint32(3)
+			s.send(&b)
+			continue
+		}
+
+		id := rd.uint32()
+		switch typ {
+		case fxpStat, fxpLstat:
+			info, err := os.Lstat(s.local(rd.string()))
+			if err != nil {
+				s.sendStatus(id, err)
+				continue
+			}
+			s.sendAttrs(id, info)
+		case fxpFstat:
+			f := s.handles[rd.string()]
+			info, err := f.Stat()
+			if err != nil {
+				s.sendStatus(id, err)
+				continue
+			}
+			s.sendAttrs(id, info)
+		case fxpOpen:
+			p := rd.string()
+			pflags := rd.uint32()
+			flag := 0
+			switch {
+			case pflags&fxfRead != 0 && pflags&fxfWrite != 0:
+				flag = os.O_RDWR
+			case pflags&fxfWrite != 0:
+				flag = os.O_WRONLY
+			}
+			if pflags&fxfCreat != 0 {
+				flag |= os.O_CREATE
+			}
+			if pflags&fxfTrunc != 0 {
+				flag |= os.O_TRUNC
+			}
+			f, err := os.OpenFile(s.local(p), flag, 0644)
+			if err != nil {
+				s.sendStatus(id, err)
+				continue
+			}
+			handle := s.newHandle()
+			s.handles[handle] = f
+			s.sendHandle(id, handle)
+		case fxpRead:
+			f := s.handles[rd.string()]
+			offset := rd.uint64()
+			length := rd.uint32()
+			buf := make([]byte, length)
+			n, err := f.ReadAt(buf, int64(offset))
+			if n == 0 {
+				if err == nil {
+					err = io.EOF
+				}
+				s.sendStatus(id, err)
+				continue
+			}
+			var b buffer
+			b.byte(fxpData)
+			b.uint32(id)
+			b.bytes(buf[:n])
+			s.send(&b)
+		case fxpWrite:
+			f := s.handles[rd.string()]
+			offset := rd.uint64()
+			_, err := f.WriteAt(rd.bytes(), int64(offset))
+			s.sendStatus(id, err)
+		case fxpClose:
+			handle := rd.string()
+			if f, ok := s.handles[handle]; ok {
+				f.Close()
+				delete(s.handles, handle)
+			}
+			delete(s.dirs, handle)
+			s.sendStatus(id, nil)
+		case fxpOpendir:
+			p := rd.string()
+			if _, err := os.ReadDir(s.local(p)); err != nil {
+				s.sendStatus(id, err)
+				continue
+			}
+			handle := s.newHandle()
+			s.dirs[handle] = p
+			s.sendHandle(id, handle)
+		case fxpReaddir:
+			handle := rd.string()
+			p, ok := s.dirs[handle]
+			if !ok || p == "" {
+				s.sendStatus(id, io.EOF)
+				continue
+			}
+			s.dirs[handle] = ""
+			entries, _ := os.ReadDir(s.local(p))
+			var b buffer
+			b.byte(fxpName)
+			b.uint32(id)
+			b.uint32(uint32(len(entries) + 1))
+			b.string(".")
+			b.string(".")
+			b.attrs(fileAttrs{})
+			for _, e := range entries {
+				info, _ := e.Info()
+				b.string(e.Name())
+				b.string(e.Name())
+				b.attrs(attrsFromInfo(info))
+			}
+			s.send(&b)
+		case fxpMkdir:
+			s.sendStatus(id, os.Mkdir(s.local(rd.string()), 0755))
+		case fxpRemove:
+			p := s.local(rd.string())
+			if info, err := os.Stat(p); err == nil && info.IsDir() {
+				s.sendStatus(id, errors.New("is a directory"))
+				continue
+			}
+			s.sendStatus(id, os.Remove(p))
+		case fxpRmdir:
+			s.sendStatus(id, os.Remove(s.local(rd.string())))
+		case fxpRename:
+			oldpath := rd.string()
+			newpath := rd.string()
+			s.sendStatus(id, os.Rename(s.local(oldpath), s.local(newpath)))
+		case fxpSetstat:
+			p := s.local(rd.string())
+			attrs := rd.attrs()
+			var err error
+			if attrs.flags&attrPermissions != 0 {
+				err = os.Chmod(p, os.FileMode(attrs.perm&0777))
+			}
+			if err == nil && attrs.flags&attrACModTime != 0 {
+				err = os.Chtimes(p, time.Unix(int64(attrs.atime), 0), time.Unix(int64(attrs.mtime), 0))
+			}
+			s.sendStatus(id, err)
+		case fxpRealpath:
+			p := rd.string()
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			var b buffer
+			b.byte(fxpName)
+			b.uint32(id)
+			b.uint32(1)
+			b.string(filepath.ToSlash(filepath.Clean(p)))
+			b.string("")
+			b.attrs(fileAttrs{})
+			s.send(&b)
+		default:
+			var b buffer
+			b.byte(fxpStatus)
+			b.uint32(id)
+			b.uint32(StatusOpUnsupported)
+			b.string("unsupported")
+			b.string("")
+			s.send(&b)
+		}
+	}
+}
+
+func TestWriteAndReadFile(t *testing.T) {
+	c, root := newTestClient(t)
+
+	// Larger than one chunk to exercise chunked transfer
+	content := bytes.Repeat([]byte("cvps"), chunkSize)
+	if err := c.WriteFile("/data.bin", content, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	onDisk, err := os.ReadFile(filepath.Join(root, "data.bin"))
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+	if !bytes.Equal(onDisk, content) {
+		t.Fatalf("written content mismatch: got %d bytes, want %d", len(onDisk), len(content))
+	}
+
+	got, err := c.ReadFile("/data.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("ReadFile() returned %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestStat(t *testing.T) {
+	c, root := newTestClient(t)
+
+	os.WriteFile(filepath.Join(root, "hello.txt"), []byte("hello"), 0644)
+	os.Mkdir(filepath.Join(root, "dir"), 0755)
+
+	info, err := c.Stat("/hello.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Name() != "hello.txt" || info.Size() != 5 || info.IsDir() {
+		t.Fatalf("Stat() = name %q size %d dir %v", info.Name(), info.Size(), info.IsDir())
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Fatalf("Stat() mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	dirInfo, err := c.Stat("/dir")
+	if err != nil {
+		t.Fatalf("Stat(dir) error = %v", err)
+	}
+	if !dirInfo.IsDir() {
+		t.Fatal("Stat(dir) IsDir() = false, want true")
+	}
+
+	_, err = c.Stat("/missing")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stat(missing) error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	c, root := newTestClient(t)
+
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(root, "b.txt"), []byte("bb"), 0644)
+	os.Mkdir(filepath.Join(root, "sub"), 0755)
+
+	entries, err := c.ReadDir("/")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	want := []string{"a.txt", "b.txt", "sub"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("ReadDir() names = %v, want %v", names, want)
+	}
+}
+
+func TestMkdirAllRenameRemove(t *testing.T) {
+	c, root := newTestClient(t)
+
+	if err := c.MkdirAll("/a/b/c"); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(root, "a", "b", "c")); err != nil || !info.IsDir() {
+		t.Fatalf("MkdirAll() did not create directories: %v", err)
+	}
+
+	if err := c.WriteFile("/a/file", []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := c.Rename("/a/file", "/a/renamed"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a", "renamed")); err != nil {
+		t.Fatalf("Rename() target missing: %v", err)
+	}
+
+	if err := c.Remove("/a/renamed"); err != nil {
+		t.Fatalf("Remove(file) error = %v", err)
+	}
+	if err := c.Remove("/a/b/c"); err != nil {
+		t.Fatalf("Remove(dir) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a", "b", "c")); !os.IsNotExist(err) {
+		t.Fatalf("Remove(dir) left directory behind: %v", err)
+	}
+}
+
+func TestChtimes(t *testing.T) {
+	c, root := newTestClient(t)
+
+	os.WriteFile(filepath.Join(root, "f"), []byte("x"), 0644)
+	mtime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	if err := c.Chtimes("/f", mtime, mtime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	info, err := c.Stat("/f")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("ModTime() = %v, want %v", info.ModTime(), mtime)
+	}
+}
+
+func TestFileInfoMode(t *testing.T) {
+	tests := []struct {
+		name string
+		perm uint32
+		want os.FileMode
+	}{
+		{name: "regular file", perm: modeRegular | 0644, want: 0644},
+		{name: "directory", perm: modeDir | 0755, want: os.ModeDir | 0755},
+		{name: "symlink", perm: modeSymlink | 0777, want: os.ModeSymlink | 0777},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi := &FileInfo{attrs: fileAttrs{perm: tt.perm}}
+			if got := fi.Mode(); got != tt.want {
+				t.Fatalf("Mode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package sftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// SFTP version 3 packet types (draft-ietf-secsh-filexfer-02)
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpLstat    = 7
+	fxpFstat    = 8
+	fxpSetstat  = 9
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpRmdir    = 15
+	fxpRealpath = 16
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+)
+
+// Open flags
+const (
+	fxfRead   = 0x01
+	fxfWrite  = 0x02
+	fxfAppend = 0x04
+	fxfCreat  = 0x08
+	fxfTrunc  = 0x10
+	fxfExcl   = 0x20
+)
+
+// Attribute flags
+const (
+	attrSize        = 0x01
+	attrUIDGID      = 0x02
+	attrPermissions = 0x04
+	attrACModTime   = 0x08
+	attrExtended    = 0x80000000
+)
+
+// Status codes
+const (
+	StatusOK               = 0
+	StatusEOF              = 1
+	StatusNoSuchFile       = 2
+	StatusPermissionDenied = 3
+	StatusFailure          = 4
+	StatusBadMessage       = 5
+	StatusOpUnsupported    = 8
+)
+
+// POSIX file type bits carried in the permissions attribute
+const (
+	modeTypeMask = 0170000
+	modeDir      = 0040000
+	modeRegular  = 0100000
+	modeSymlink  = 0120000
+)
+
+// maxPacket bounds incoming packets so a misbehaving server cannot exhaust memory
+const maxPacket = 256 * 1024
+
+var errShortPacket = errors.New("sftp: short packet")
+
+// StatusError is a non-OK status returned by the server
+type StatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("sftp: %s (code %d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("sftp: status code %d", e.Code)
+}
+
+// Is lets callers use errors.Is(err, os.ErrNotExist) and friends
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case os.ErrNotExist:
+		return e.Code == StatusNoSuchFile
+	case os.ErrPermission:
+		return e.Code == StatusPermissionDenied
+	case io.EOF:
+		return e.Code == StatusEOF
+	}
+	return false
+}
+
+// fileAttrs is the wire form of SFTP file attributes
+type fileAttrs struct {
+	flags uint32
+	size  uint64
+	uid   uint32
+	gid   uint32
+	perm  uint32
+	atime uint32
+	mtime uint32
+}
+
+// FileInfo describes a remote file and implements os.FileInfo
+type FileInfo struct {
+	name  string
+	attrs fileAttrs
+}
+
+func (fi *FileInfo) Name() string       { return fi.name }
+func (fi *FileInfo) Size() int64        { return int64(fi.attrs.size) }
+func (fi *FileInfo) ModTime() time.Time { return time.Unix(int64(fi.attrs.mtime), 0) }
+func (fi *FileInfo) IsDir() bool        { return fi.Mode().IsDir() }
+func (fi *FileInfo) Sys() any           { return nil }
+
+func (fi *FileInfo) Mode() os.FileMode {
+	mode := os.FileMode(fi.attrs.perm & 0777)
+	switch fi.attrs.perm & modeTypeMask {
+	case modeDir:
+		mode |= os.ModeDir
+	case modeSymlink:
+		mode |= os.ModeSymlink
+	case modeRegular:
+	default:
+		if fi.attrs.perm&modeTypeMask != 0 {
+			mode |= os.ModeIrregular
+		}
+	}
+	return mode
+}
+
+// buffer builds outgoing packets
+type buffer struct {
+	b []byte
+}
+
+func (b *buffer) byte(v byte) { b.b = append(b.b, v) }
+
+func (b *buffer) uint32(v uint32) { b.b = binary.BigEndian.AppendUint32(b.b, v) }
+
+func (b *buffer) uint64(v uint64) { b.b = binary.BigEndian.AppendUint64(b.b, v) }
+
+func (b *buffer) string(s string) {
+	b.uint32(uint32(len(s)))
+	b.b = append(b.b, s...)
+}
+
+func (b *buffer) bytes(p []byte) {
+	b.uint32(uint32(len(p)))
+	b.b = append(b.b, p...)
+}
+
+func (b *buffer) attrs(a fileAttrs) {
+	b.uint32(a.flags)
+	if a.flags&attrSize != 0 {
+		b.uint64(a.size)
+	}
+	if a.flags&attrUIDGID != 0 {
+		b.uint32(a.uid)
+		b.uint32(a.gid)
+	}
+	if a.flags&attrPermissions != 0 {
+		b.uint32(a.perm)
+	}
+	if a.flags&attrACModTime != 0 {
+		b.uint32(a.atime)
+		b.uint32(a.mtime)
+	}
+}
+
+// frame returns the packet with its length prefix
+func (b *buffer) frame() []byte {
+	out := make([]byte, 4, 4+len(b.b))
+	binary.BigEndian.PutUint32(out, uint32(len(b.b)))
+	return append(out, b.b...)
+}
+
+// reader parses incoming packets
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || len(r.b) < 1 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.b) < 4 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || len(r.b) < 8 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.b)) < n {
+		r.err = errShortPacket
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+func (r *reader) attrs() fileAttrs {
+	var a fileAttrs
+	a.flags = r.uint32()
+	if a.flags&attrSize != 0 {
+		a.size = r.uint64()
+	}
+	if a.flags&attrUIDGID != 0 {
+		a.uid = r.uint32()
+		a.gid = r.uint32()
+	}
+	if a.flags&attrPermissions != 0 {
+		a.perm = r.uint32()
+	}
+	if a.flags&attrACModTime != 0 {
+		a.atime = r.uint32()
+		a.mtime = r.uint32()
+	}
+	if a.flags&attrExtended != 0 {
+		count := r.uint32()
+		for i := uint32(0); i < count && r.err == nil; i++ {
+			r.string()
+			r.string()
+		}
+	}
+	return a
+}
+
+// readPacket reads one length-prefixed packet and returns its type and payload
+func readPacket(rd io.Reader) (byte, []byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(rd, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(hdr[:])
+	if length == 0 || length > maxPacket {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(rd, data); err != nil {
+		return 0, nil, err
+	}
+	return data[0], data[1:], nil
+}
@@ -0,0 +1,194 @@
+// Package telemetry queues anonymous usage events under ~/.cvps/telemetry
+// while the user has opted in, and uploads them in batches. An event holds
+// the command name, duration, outcome and CLI version only; never arguments,
+// sandbox names or anything else that identifies the user.
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/achronon/cvps/internal/config"
+)
+
+const queueFile = "events.jsonl"
+
+// BatchSize is the number of queued events that makes an upload due
+const BatchSize = 20
+
+// maxQueued bounds the queue while uploads keep failing; newer events are
+// dropped beyond it
+const maxQueued = 500
+
+// DoNotTrackEnv turns telemetry off regardless of the config when set to
+// anything other than "" or "0", following the DO_NOT_TRACK convention
+const DoNotTrackEnv = "DO_NOT_TRACK"
+
+// Event is one command run
+type Event struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	Success    bool   `json:"success"`
+	Version    string `json:"version"`
+}
+
+// Batch is the upload payload
+type Batch struct {
+	Events []Event `json:"events"`
+}
+
+// Dir returns the telemetry directory
+func Dir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "telemetry"), nil
+}
+
+// DisabledByEnv reports whether DO_NOT_TRACK is set
+func DisabledByEnv() bool {
+	v := strings.TrimSpace(os.Getenv(DoNotTrackEnv))
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
+
+// Record appends e to the queue and returns the number of events queued
+func Record(e Event) (int, error) {
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+
+	path := filepath.Join(dir, queueFile)
+	n, err := countLines(path)
+	if err != nil {
+		return 0, err
+	}
+	if n >= maxQueued {
+		return n, nil
+	}
+	if err := appendEvents(path, []Event{e}); err != nil {
+		return 0, err
+	}
+	return n + 1, nil
+}
+
+// Pending returns the number of queued events
+func Pending() (int, error) {
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	return countLines(filepath.Join(dir, queueFile))
+}
+
+// Clear drops all queued events
+func Clear() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, queueFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Upload sends the queued events with send. The queue is claimed first so
+// events recorded meanwhile go into a new one; if send fails the claimed
+// events are put back.
+func Upload(ctx context.Context, send func(context.Context, Batch) error) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, queueFile)
+	claimed := path + ".sending-" + strconv.Itoa(os.Getpid())
+	if err := os.Rename(path, claimed); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to claim telemetry queue: %w", err)
+	}
+	defer os.Remove(claimed)
+
+	events, err := readEvents(claimed)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	if err := send(ctx, Batch{Events: events}); err != nil {
+		requeue(path, events)
+		return fmt.Errorf("failed to upload telemetry: %w", err)
+	}
+	return nil
+}
+
+// requeue puts events back after a failed upload, within maxQueued
+func requeue(path string, events []Event) {
+	n, err := countLines(path)
+	if err != nil {
+		return
+	}
+	if room := maxQueued - n; room < len(events) {
+		events = events[:max(room, 0)]
+	}
+	appendEvents(path, events)
+}
+
+func appendEvents(path string, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open telemetry queue: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write telemetry queue: %w", err)
+		}
+	}
+	return nil
+}
+
+// readEvents reads a queue file, skipping lines that do not parse
+func readEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read telemetry queue: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
+
+func countLines(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read telemetry queue: %w", err)
+	}
+	return strings.Count(string(data), "\n"), nil
+}
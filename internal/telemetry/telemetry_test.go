@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecordAndUpload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for i := 0; i < 3; i++ {
+		n, err := Record(Event{Command: "status", DurationMS: 12, Success: true, Version: "1.2.3"})
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if n != i+1 {
+			t.Errorf("Record() queued = %d, want %d", n, i+1)
+		}
+	}
+
+	// A failed upload keeps the events
+	err := Upload(context.Background(), func(ctx context.Context, b Batch) error {
+		return errors.New("offline")
+	})
+	if err == nil {
+		t.Fatal("Upload() expected error")
+	}
+	if n, _ := Pending(); n != 3 {
+		t.Fatalf("Pending() after failed upload = %d, want 3", n)
+	}
+
+	var sent Batch
+	err = Upload(context.Background(), func(ctx context.Context, b Batch) error {
+		sent = b
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if len(sent.Events) != 3 || sent.Events[0].Command != "status" || !sent.Events[0].Success {
+		t.Errorf("Upload() sent %+v", sent)
+	}
+	if n, _ := Pending(); n != 0 {
+		t.Errorf("Pending() after upload = %d, want 0", n)
+	}
+
+	// Nothing queued, nothing sent
+	err = Upload(context.Background(), func(ctx context.Context, b Batch) error {
+		t.Error("send called with an empty queue")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Upload() with empty queue error = %v", err)
+	}
+}
+
+func TestRecordBounded(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for i := 0; i < maxQueued+5; i++ {
+		if _, err := Record(Event{Command: "up"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := Pending(); n != maxQueued {
+		t.Errorf("Pending() = %d, want %d", n, maxQueued)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := Pending(); n != 0 {
+		t.Errorf("Pending() after Clear() = %d, want 0", n)
+	}
+}
+
+func TestDisabledByEnv(t *testing.T) {
+	for value, want := range map[string]bool{"": false, "0": false, "false": false, "1": true, "true": true} {
+		t.Setenv(DoNotTrackEnv, value)
+		if got := DisabledByEnv(); got != want {
+			t.Errorf("DisabledByEnv() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
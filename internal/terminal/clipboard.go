@@ -0,0 +1,47 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// clipboardCommands lists the local commands that can set the clipboard, in
+// order of preference
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	}
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	return append(cmds,
+		[]string{"xclip", "-selection", "clipboard"},
+		[]string{"xsel", "--clipboard", "--input"},
+		[]string{"clip.exe"}, // WSL
+	)
+}
+
+// CopyToClipboard puts text on the local clipboard using the first clipboard
+// command found in PATH
+func CopyToClipboard(text []byte) error {
+	for _, args := range clipboardCommands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		c := exec.Command(path, args[1:]...)
+		c.Stdin = bytes.NewReader(text)
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", args[0], err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard command found")
+}
@@ -0,0 +1,70 @@
+package terminal
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxLatencySamples is how many recent samples are kept for percentiles
+const maxLatencySamples = 1000
+
+// Latency collects round-trip time samples
+type Latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	count   int
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+	last    time.Duration
+}
+
+// LatencySummary describes the samples collected so far. P95 covers the most
+// recent samples only.
+type LatencySummary struct {
+	Count int
+	Min   time.Duration
+	Avg   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+	Last  time.Duration
+}
+
+// Add records one sample
+func (l *Latency) Add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 || d < l.min {
+		l.min = d
+	}
+	l.max = max(l.max, d)
+	l.count++
+	l.sum += d
+	l.last = d
+	if len(l.samples) == maxLatencySamples {
+		l.samples = l.samples[1:]
+	}
+	l.samples = append(l.samples, d)
+}
+
+// Summary returns the statistics so far
+func (l *Latency) Summary() LatencySummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 {
+		return LatencySummary{}
+	}
+	sorted := slices.Clone(l.samples)
+	slices.Sort(sorted)
+	return LatencySummary{
+		Count: l.count,
+		Min:   l.min,
+		Avg:   l.sum / time.Duration(l.count),
+		P95:   sorted[(len(sorted)*95+99)/100-1],
+		Max:   l.max,
+		Last:  l.last,
+	}
+}
@@ -0,0 +1,31 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencySummary(t *testing.T) {
+	var l Latency
+	if s := l.Summary(); s.Count != 0 {
+		t.Errorf("Expected empty summary, got %+v", s)
+	}
+
+	for i := 1; i <= 20; i++ {
+		l.Add(time.Duration(i) * time.Millisecond)
+	}
+	l.Add(5 * time.Millisecond)
+
+	s := l.Summary()
+	want := LatencySummary{
+		Count: 21,
+		Min:   time.Millisecond,
+		Avg:   215 * time.Millisecond / 21,
+		P95:   19 * time.Millisecond,
+		Max:   20 * time.Millisecond,
+		Last:  5 * time.Millisecond,
+	}
+	if s != want {
+		t.Errorf("Summary() = %+v, want %+v", s, want)
+	}
+}
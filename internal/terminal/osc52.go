@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+)
+
+// osc52Prefix starts an OSC 52 clipboard sequence: ESC ] 52 ; <selection> ; <base64> ST
+var osc52Prefix = []byte("\x1b]52;")
+
+// maxOSC52Len caps how much of an unterminated sequence is held back before
+// the rest of it is discarded
+const maxOSC52Len = 8 << 20
+
+// OSC52Filter is an io.Writer that takes OSC 52 clipboard sequences out of a
+// terminal output stream. Copies are handed to copyFn; if copyFn is nil they
+// are dropped, and if it fails the sequence is passed on so the local
+// terminal can try. Clipboard reads (a "?" payload) are always dropped so the
+// remote side cannot see the local clipboard.
+type OSC52Filter struct {
+	w      io.Writer
+	copyFn func([]byte) error
+
+	held     []byte // a sequence, or the start of one, split across writes
+	skipping bool   // discarding an oversized sequence up to its terminator
+}
+
+// NewOSC52Filter returns a filter writing everything but clipboard sequences to w
+func NewOSC52Filter(w io.Writer, copyFn func([]byte) error) *OSC52Filter {
+	return &OSC52Filter{w: w, copyFn: copyFn}
+}
+
+func (f *OSC52Filter) Write(p []byte) (int, error) {
+	data := p
+	if len(f.held) > 0 {
+		data = append(f.held, p...)
+		f.held = nil
+	}
+
+	var werr error
+	write := func(b []byte) {
+		if len(b) > 0 && werr == nil {
+			_, werr = f.w.Write(b)
+		}
+	}
+
+	for len(data) > 0 {
+		if f.skipping {
+			end := osc52End(data, 0)
+			if end < 0 {
+				if data[len(data)-1] == 0x1b {
+					f.held = []byte{0x1b}
+				}
+				break
+			}
+			f.skipping = false
+			data = data[end:]
+			continue
+		}
+
+		i := bytes.Index(data, osc52Prefix)
+		if i < 0 {
+			keep := partialPrefixLen(data)
+			write(data[:len(data)-keep])
+			f.held = bytes.Clone(data[len(data)-keep:])
+			break
+		}
+		write(data[:i])
+		data = data[i:]
+
+		end := osc52End(data, len(osc52Prefix))
+		if end < 0 {
+			if len(data) > maxOSC52Len {
+				f.skipping = true
+			} else {
+				f.held = bytes.Clone(data)
+			}
+			break
+		}
+		if forward := f.handle(data[:end]); forward {
+			write(data[:end])
+		}
+		data = data[end:]
+	}
+
+	if werr != nil {
+		return 0, werr
+	}
+	return len(p), nil
+}
+
+// handle copies a complete sequence and reports whether it should still be
+// passed on to the terminal
+func (f *OSC52Filter) handle(seq []byte) bool {
+	body := bytes.TrimPrefix(seq, osc52Prefix)
+	body = bytes.TrimSuffix(bytes.TrimSuffix(body, []byte{0x07}), []byte("\x1b\\"))
+	_, payload, ok := bytes.Cut(body, []byte(";"))
+	if !ok || string(payload) == "?" || f.copyFn == nil {
+		return false
+	}
+	text, err := base64.StdEncoding.DecodeString(string(payload))
+	if err != nil {
+		return false
+	}
+	return f.copyFn(text) != nil
+}
+
+// osc52End returns the index just past the sequence terminator (BEL or
+// ESC \) at or after from, or -1 if the sequence is not complete yet
+func osc52End(data []byte, from int) int {
+	for i := from; i < len(data); i++ {
+		switch data[i] {
+		case 0x07:
+			return i + 1
+		case 0x1b:
+			if i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	}
+	return -1
+}
+
+// partialPrefixLen returns how many trailing bytes of data could be the
+// start of an OSC 52 prefix
+func partialPrefixLen(data []byte) int {
+	for n := min(len(osc52Prefix)-1, len(data)); n > 0; n-- {
+		if bytes.HasSuffix(data, osc52Prefix[:n]) {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOSC52Filter(t *testing.T) {
+	// "hello" is aGVsbG8=
+	tests := []struct {
+		name    string
+		chunks  []string
+		copyErr error
+		deny    bool
+		wantOut string
+		wantCp  []string
+	}{
+		{
+			name:    "copy with BEL",
+			chunks:  []string{"a\x1b]52;c;aGVsbG8=\x07b"},
+			wantOut: "ab",
+			wantCp:  []string{"hello"},
+		},
+		{
+			name:    "copy with ST split across writes",
+			chunks:  []string{"a\x1b]5", "2;c;aGVs", "bG8=\x1b", "\\b"},
+			wantOut: "ab",
+			wantCp:  []string{"hello"},
+		},
+		{
+			name:    "denied",
+			chunks:  []string{"a\x1b]52;c;aGVsbG8=\x07b"},
+			deny:    true,
+			wantOut: "ab",
+		},
+		{
+			name:    "clipboard read is never answered",
+			chunks:  []string{"a\x1b]52;c;?\x07b"},
+			wantOut: "ab",
+		},
+		{
+			name:    "passed on when the local copy fails",
+			chunks:  []string{"\x1b]52;c;aGVsbG8=\x07"},
+			copyErr: errors.New("no clipboard command found"),
+			wantOut: "\x1b]52;c;aGVsbG8=\x07",
+			wantCp:  []string{"hello"},
+		},
+		{
+			name:    "other escape sequences untouched",
+			chunks:  []string{"\x1b]0;title\x07\x1b[31mred\x1b", "[0m"},
+			wantOut: "\x1b]0;title\x07\x1b[31mred\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var copied []string
+			copyFn := func(b []byte) error {
+				copied = append(copied, string(b))
+				return tt.copyErr
+			}
+			if tt.deny {
+				copyFn = nil
+			}
+
+			f := NewOSC52Filter(&out, copyFn)
+			for _, chunk := range tt.chunks {
+				if n, err := f.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+				}
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOut)
+			}
+			if strings.Join(copied, "|") != strings.Join(tt.wantCp, "|") {
+				t.Errorf("copied = %q, want %q", copied, tt.wantCp)
+			}
+		})
+	}
+}
@@ -0,0 +1,471 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ScrollbackKey is the prefix key for scrollback commands (Ctrl-]). It is
+// followed by [ to browse the scrollback, / to search it, a key added with
+// Bind, or pressed twice to send it to the sandbox.
+const ScrollbackKey = 0x1d
+
+// Parser states for stripping escape sequences from recorded output
+const (
+	stNormal = iota
+	stEsc
+	stCSI
+	stOSC
+	stOSCEsc
+)
+
+// Scrollback is an io.Writer that passes terminal output through to the
+// screen and keeps the last lines of it as plain text, so output that has
+// scrolled away can be browsed, searched and copied. Output from full-screen
+// programs (the alternate screen) is not kept.
+type Scrollback struct {
+	mu     sync.Mutex
+	out    io.Writer
+	max    int
+	lines  []string
+	cur    []byte
+	cr     bool
+	state  int
+	params []byte
+	alt    bool
+
+	// While browsing, output is held back and written when browsing ends
+	paused  bool
+	pending []byte
+
+	copyFn func([]byte) error
+	size   func() (int, int, error)
+	binds  map[byte]func()
+}
+
+// NewScrollback returns a Scrollback keeping up to max lines of what is
+// written to out. copyFn, if set, is used to copy a line to the clipboard.
+func NewScrollback(out io.Writer, max int, copyFn func([]byte) error) *Scrollback {
+	return &Scrollback{out: out, max: max, copyFn: copyFn, size: GetSize}
+}
+
+func (s *Scrollback) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(p)
+	if s.paused {
+		s.pending = append(s.pending, p...)
+		return len(p), nil
+	}
+	return s.out.Write(p)
+}
+
+// Bind runs fn when key is pressed after the prefix key
+func (s *Scrollback) Bind(key byte, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.binds == nil {
+		s.binds = make(map[byte]func())
+	}
+	s.binds[key] = fn
+}
+
+// record adds p to the kept lines, dropping escape sequences
+func (s *Scrollback) record(p []byte) {
+	for _, b := range p {
+		switch s.state {
+		case stEsc:
+			switch b {
+			case '[':
+				s.state, s.params = stCSI, s.params[:0]
+			case ']':
+				s.state = stOSC
+			default:
+				s.state = stNormal
+			}
+			continue
+		case stCSI:
+			if b >= 0x40 && b <= 0x7e {
+				switch string(s.params) {
+				case "?1049", "?1047", "?47":
+					if b == 'h' || b == 'l' {
+						s.alt = b == 'h'
+					}
+				}
+				s.state = stNormal
+			} else if len(s.params) < 32 {
+				s.params = append(s.params, b)
+			}
+			continue
+		case stOSC:
+			switch b {
+			case 0x07:
+				s.state = stNormal
+			case 0x1b:
+				s.state = stOSCEsc
+			}
+			continue
+		case stOSCEsc:
+			if b == '\\' {
+				s.state = stNormal
+			} else {
+				s.state = stOSC
+			}
+			continue
+		}
+
+		if b == 0x1b {
+			s.state = stEsc
+			continue
+		}
+		if s.alt {
+			continue
+		}
+		switch {
+		case b == '\n':
+			s.lines = append(s.lines, string(s.cur))
+			s.cur, s.cr = s.cur[:0], false
+			// Trim in batches so each line does not copy the whole buffer
+			if len(s.lines) > s.max+s.max/4 {
+				s.lines = append([]string(nil), s.lines[len(s.lines)-s.max:]...)
+			}
+		case b == '\r':
+			s.cr = true
+		case b == '\b':
+			if _, n := utf8.DecodeLastRune(s.cur); n > 0 {
+				s.cur = s.cur[:len(s.cur)-n]
+			}
+		case b == '\t':
+			s.overwrite()
+			s.cur = append(s.cur, bytes.Repeat([]byte(" "), 8-utf8.RuneCount(s.cur)%8)...)
+		case b < 0x20 || b == 0x7f:
+		default:
+			s.overwrite()
+			s.cur = append(s.cur, b)
+		}
+	}
+}
+
+// overwrite starts the line over when text follows a bare carriage return,
+// as progress bars do
+func (s *Scrollback) overwrite() {
+	if s.cr {
+		s.cur, s.cr = s.cur[:0], false
+	}
+}
+
+// Lines returns the kept lines, oldest first, including the current
+// unfinished line
+func (s *Scrollback) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := s.lines
+	if len(lines) > s.max {
+		lines = lines[len(lines)-s.max:]
+	}
+	lines = append([]string(nil), lines...)
+	if len(s.cur) > 0 {
+		lines = append(lines, string(s.cur))
+	}
+	return lines
+}
+
+// Input returns a reader over r that handles the scrollback prefix key and
+// passes everything else on
+func (s *Scrollback) Input(r io.Reader) io.Reader {
+	return &scrollbackInput{s: s, r: r, rbuf: make([]byte, 1024)}
+}
+
+type scrollbackInput struct {
+	s     *Scrollback
+	r     io.Reader
+	rbuf  []byte
+	buf   []byte
+	err   error
+	armed bool
+}
+
+// next returns unread input, reading more if there is none
+func (in *scrollbackInput) next() []byte {
+	if len(in.buf) == 0 && in.err == nil {
+		var n int
+		n, in.err = in.r.Read(in.rbuf)
+		in.buf = in.rbuf[:n]
+	}
+	return in.buf
+}
+
+func (in *scrollbackInput) Read(p []byte) (int, error) {
+	for {
+		chunk := in.next()
+		if len(chunk) == 0 {
+			if in.err != nil {
+				return 0, in.err
+			}
+			continue
+		}
+
+		if in.armed {
+			in.armed = false
+			switch chunk[0] {
+			case '[', '/':
+				in.buf = chunk[1:]
+				if err := in.s.browse(in, chunk[0] == '/'); err != nil {
+					return 0, err
+				}
+				continue
+			case ScrollbackKey:
+				in.buf = chunk[1:]
+			default:
+				in.s.mu.Lock()
+				fn := in.s.binds[chunk[0]]
+				in.s.mu.Unlock()
+				if fn != nil {
+					in.buf = chunk[1:]
+					fn()
+					continue
+				}
+			}
+			// Anything else is sent after the prefix key as typed
+			p[0] = ScrollbackKey
+			return 1, nil
+		}
+
+		i := bytes.IndexByte(chunk, ScrollbackKey)
+		if i == 0 {
+			in.armed = true
+			in.buf = chunk[1:]
+			continue
+		}
+		if i < 0 {
+			i = len(chunk)
+		}
+		n := copy(p, chunk[:i])
+		in.buf = chunk[n:]
+		return n, nil
+	}
+}
+
+// readKey reads one key press, returning escape sequences whole
+func (in *scrollbackInput) readKey() (string, error) {
+	for {
+		chunk := in.next()
+		if len(chunk) == 0 {
+			if in.err != nil {
+				return "", in.err
+			}
+			continue
+		}
+		n := 1
+		if chunk[0] == 0x1b && len(chunk) > 2 && chunk[1] == '[' {
+			n = 2
+			for n < len(chunk) {
+				n++
+				if b := chunk[n-1]; b >= 0x40 && b <= 0x7e {
+					break
+				}
+			}
+		} else if chunk[0] >= 0x80 {
+			_, n = utf8.DecodeRune(chunk)
+		}
+		in.buf = chunk[n:]
+		return string(chunk[:n]), nil
+	}
+}
+
+// browser is the state of one scrollback browsing session
+type browser struct {
+	lines  []string
+	cursor int
+	top    int
+	query  string
+	status string
+}
+
+// browse shows the scrollback full screen until q or Esc, moving a cursor
+// line with the arrow keys and searching with /. Output arriving meanwhile is
+// shown afterwards.
+func (s *Scrollback) browse(in *scrollbackInput, search bool) error {
+	s.mu.Lock()
+	if s.alt {
+		// A full-screen program is running; there is nothing to browse and
+		// switching screens would lose its display
+		s.mu.Unlock()
+		return nil
+	}
+	s.paused = true
+	s.mu.Unlock()
+
+	s.screen("\x1b[?1049h")
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_, _ = s.out.Write([]byte("\x1b[?1049l"))
+		_, _ = s.out.Write(s.pending)
+		s.pending, s.paused = nil, false
+	}()
+
+	b := &browser{lines: s.Lines()}
+	b.cursor = len(b.lines) - 1
+	if search {
+		if err := s.prompt(in, b); err != nil {
+			return err
+		}
+	}
+
+	for {
+		height := s.render(b, "")
+		key, err := in.readKey()
+		if err != nil {
+			return err
+		}
+		b.status = ""
+		switch key {
+		case "q", "\x1b", "\x03":
+			return nil
+		case "j", "\x1b[B", "\x0e":
+			b.cursor++
+		case "k", "\x1b[A", "\x10":
+			b.cursor--
+		case " ", "\x06", "\x1b[6~":
+			b.cursor += height
+		case "\x02", "\x1b[5~":
+			b.cursor -= height
+		case "g", "\x1b[H":
+			b.cursor = 0
+		case "G", "\x1b[F":
+			b.cursor = len(b.lines) - 1
+		case "/":
+			if err := s.prompt(in, b); err != nil {
+				return err
+			}
+		case "n":
+			b.find(-1)
+		case "N":
+			b.find(1)
+		case "y":
+			b.copyLine(s.copyFn)
+		}
+		b.cursor = max(min(b.cursor, len(b.lines)-1), 0)
+	}
+}
+
+// prompt reads a search query on the status line and jumps to the first
+// match above the cursor
+func (s *Scrollback) prompt(in *scrollbackInput, b *browser) error {
+	var query []rune
+	for {
+		s.render(b, "/"+string(query))
+		key, err := in.readKey()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "\r", "\n":
+			if len(query) > 0 {
+				b.query = string(query)
+				b.find(-1)
+			}
+			return nil
+		case "\x1b", "\x03":
+			return nil
+		case "\x7f", "\b":
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+			}
+		default:
+			if r, _ := utf8.DecodeRuneInString(key); r >= 0x20 && r != utf8.RuneError {
+				query = append(query, r)
+			}
+		}
+	}
+}
+
+// find moves the cursor to the next line matching the query, searching up
+// (dir -1, older output) or down (dir 1). Case is ignored.
+func (b *browser) find(dir int) {
+	if b.query == "" {
+		b.status = "No search yet; press / to search"
+		return
+	}
+	q := strings.ToLower(b.query)
+	for i := b.cursor + dir; i >= 0 && i < len(b.lines); i += dir {
+		if strings.Contains(strings.ToLower(b.lines[i]), q) {
+			b.cursor = i
+			return
+		}
+	}
+	b.status = fmt.Sprintf("Pattern not found: %s", b.query)
+}
+
+// copyLine copies the cursor line to the clipboard
+func (b *browser) copyLine(copyFn func([]byte) error) {
+	if copyFn == nil || len(b.lines) == 0 {
+		return
+	}
+	if err := copyFn([]byte(b.lines[b.cursor])); err != nil {
+		b.status = fmt.Sprintf("Copy failed: %v", err)
+		return
+	}
+	b.status = "Copied line to clipboard"
+}
+
+// render draws the visible lines with the cursor line highlighted and a
+// status line at the bottom, returning the number of lines shown
+func (s *Scrollback) render(b *browser, promptLine string) int {
+	cols, rows, err := s.size()
+	if err != nil || cols <= 0 || rows <= 1 {
+		cols, rows = 80, 24
+	}
+	height := rows - 1
+
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+height {
+		b.top = b.cursor - height + 1
+	}
+	b.top = max(b.top, 0)
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	for i := b.top; i < b.top+height && i < len(b.lines); i++ {
+		line := truncateRunes(b.lines[i], cols)
+		if i == b.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		sb.WriteString(line + "\r\n")
+	}
+
+	status := promptLine
+	if status == "" {
+		status = b.status
+	}
+	if status == "" {
+		status = fmt.Sprintf("Scrollback %d/%d  ↑↓ PgUp/PgDn move  / search  n/N next/prev  y copy  q quit", b.cursor+1, len(b.lines))
+	}
+	fmt.Fprintf(&sb, "\x1b[%d;1H\x1b[7m%s\x1b[0m", rows, truncateRunes(status, cols))
+
+	s.screen(sb.String())
+	return height
+}
+
+// screen writes directly to the terminal while output is paused
+func (s *Scrollback) screen(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.out.Write([]byte(text))
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
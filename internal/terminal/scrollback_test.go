@@ -0,0 +1,105 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// chunkReader returns one chunk per Read, like keys typed at a terminal
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestScrollbackRecord(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScrollback(&out, 3, nil)
+
+	written := "one\r\n\x1b[31mtwo\x1b[0m\r\n\x1b]0;title\x07three\n" +
+		"10%\r50%\r100%\r\n" +
+		"\x1b[?1049hvim screen\r\n\x1b[?1049l" +
+		"$ ls"
+	for _, chunk := range []string{written[:9], written[9:30], written[30:]} {
+		s.Write([]byte(chunk))
+	}
+
+	if out.String() != written {
+		t.Errorf("Output should pass through unchanged, got %q", out.String())
+	}
+	want := []string{"two", "three", "100%", "$ ls"}
+	if got := s.Lines(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScrollbackInput(t *testing.T) {
+	var out bytes.Buffer
+	var copied string
+	s := NewScrollback(&out, 100, func(b []byte) error {
+		copied = string(b)
+		return nil
+	})
+	s.size = func() (int, int, error) { return 80, 10, nil }
+	s.Write([]byte("make build\r\nerror: missing dependency\r\nok\r\n"))
+
+	in := s.Input(&chunkReader{chunks: []string{
+		"ls\r",
+		"\x1d", "\x1d", // prefix twice sends it through
+		"\x1d", "/", "err", "\r", // search
+		"y",
+		"q",
+		"pwd\r",
+	}})
+	got, err := io.ReadAll(in)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if string(got) != "ls\r\x1dpwd\r" {
+		t.Errorf("Input passed on %q", got)
+	}
+	if copied != "error: missing dependency" {
+		t.Errorf("Copied %q, want the matching line", copied)
+	}
+	if !strings.Contains(out.String(), "\x1b[?1049h") || !strings.HasSuffix(out.String(), "\x1b[?1049l") {
+		t.Errorf("Expected browsing on the alternate screen, got %q", out.String())
+	}
+}
+
+func TestScrollbackHoldsOutputWhileBrowsing(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScrollback(&out, 100, nil)
+	s.size = func() (int, int, error) { return 80, 10, nil }
+
+	keys := &chunkReader{chunks: []string{"\x1d", "["}}
+	in := s.Input(keys).(*scrollbackInput)
+	// Output arriving while the first key is being read is held back
+	quit := false
+	in.r = readerFunc(func(p []byte) (int, error) {
+		if len(keys.chunks) == 0 && !quit {
+			quit = true
+			s.Write([]byte("late output"))
+			return copy(p, "q"), nil
+		}
+		return keys.Read(p)
+	})
+	in.Read(make([]byte, 16))
+
+	if !strings.HasSuffix(out.String(), "\x1b[?1049llate output") {
+		t.Errorf("Expected held output after leaving the scrollback, got %q", out.String())
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
@@ -1,14 +1,17 @@
 package terminal
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -22,8 +25,31 @@ type SocketIOTerminal struct {
 	closed   bool
 	sessionM sync.RWMutex
 	session  string
+
+	resizeM     sync.Mutex
+	resizeTimer *time.Timer
+	pending     termSize // latest size asked for
+	sent        termSize // last size sent to the server
+
+	// Network round trips measured by Ping, and the time from typing to the
+	// next output, which includes the sandbox's own delay
+	pingM   sync.Mutex
+	pong    chan string
+	rtt     Latency
+	echoM   sync.Mutex
+	inputAt time.Time
+	echo    Latency
+}
+
+// termSize is a terminal size in columns and rows; the zero value means unset
+type termSize struct {
+	cols, rows int
 }
 
+// resizeDebounce is how long resizes must stop before the latest size is
+// sent, so dragging a window sends one resize rather than one per signal
+var resizeDebounce = 100 * time.Millisecond
+
 type terminalStartedPayload struct {
 	SessionID string `json:"sessionId"`
 }
@@ -52,7 +78,20 @@ type terminalResizePayload struct {
 	Rows      int    `json:"rows"`
 }
 
-func NewSocketIOTerminal(rawURL, token, sandboxID string) (*SocketIOTerminal, error) {
+// HandshakeError is returned when the server answered the websocket upgrade
+// with an HTTP status instead of switching protocols
+type HandshakeError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("%v (HTTP %d)", e.Err, e.StatusCode)
+}
+
+func (e *HandshakeError) Unwrap() error { return e.Err }
+
+func NewSocketIOTerminal(ctx context.Context, rawURL, token, sandboxID string) (*SocketIOTerminal, error) {
 	engineURL, namespace, err := buildSocketIOURL(rawURL, token)
 	if err != nil {
 		return nil, err
@@ -61,8 +100,11 @@ func NewSocketIOTerminal(rawURL, token, sandboxID string) (*SocketIOTerminal, er
 	dialer := websocket.Dialer{}
 	headers := make(http.Header)
 	headers.Set("Authorization", "Bearer "+token)
-	conn, _, err := dialer.Dial(engineURL, headers)
+	conn, resp, err := dialer.DialContext(ctx, engineURL, headers)
 	if err != nil {
+		if resp != nil {
+			err = &HandshakeError{StatusCode: resp.StatusCode, Err: err}
+		}
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
@@ -70,7 +112,15 @@ func NewSocketIOTerminal(rawURL, token, sandboxID string) (*SocketIOTerminal, er
 		conn:      conn,
 		namespace: namespace,
 		sandboxID: sandboxID,
+		pong:      make(chan string, 1),
 	}
+	conn.SetPongHandler(func(data string) error {
+		select {
+		case term.pong <- data:
+		default:
+		}
+		return nil
+	})
 
 	if err := term.handshake(); err != nil {
 		_ = conn.Close()
@@ -166,6 +216,12 @@ func (t *SocketIOTerminal) Close() error {
 	}
 	t.closed = true
 
+	t.resizeM.Lock()
+	if t.resizeTimer != nil {
+		t.resizeTimer.Stop()
+	}
+	t.resizeM.Unlock()
+
 	return t.conn.Close()
 }
 
@@ -181,20 +237,84 @@ func (t *SocketIOTerminal) getSessionID() string {
 	return t.session
 }
 
+// Resize asks for the remote terminal to be resized. Sizes are sent once
+// resizing has paused for resizeDebounce, and a size the server already has
+// is not sent again. Before the session has started the size is kept and sent
+// as soon as it does.
 func (t *SocketIOTerminal) Resize(cols, rows int) error {
-	sessionID := t.getSessionID()
-	if sessionID == "" {
-		// Ignore early resize before terminal session starts.
+	t.resizeM.Lock()
+	defer t.resizeM.Unlock()
+
+	t.pending = termSize{cols, rows}
+	if t.getSessionID() == "" {
+		return nil
+	}
+	if t.resizeTimer == nil {
+		t.resizeTimer = time.AfterFunc(resizeDebounce, func() { _ = t.flushResize() })
+	} else {
+		t.resizeTimer.Reset(resizeDebounce)
+	}
+	return nil
+}
+
+// flushResize sends the latest requested size unless the server already has it
+func (t *SocketIOTerminal) flushResize() error {
+	t.resizeM.Lock()
+	size := t.pending
+	if size == (termSize{}) || size == t.sent {
+		t.resizeM.Unlock()
 		return nil
 	}
+	t.sent = size
+	t.resizeM.Unlock()
 
 	return t.emit("terminal:resize", terminalResizePayload{
-		SessionID: sessionID,
-		Cols:      cols,
-		Rows:      rows,
+		SessionID: t.getSessionID(),
+		Cols:      size.cols,
+		Rows:      size.rows,
 	})
 }
 
+// Ping measures one network round trip to the terminal server with a
+// websocket ping. Pongs are read by Run, so Ping only returns while it runs.
+func (t *SocketIOTerminal) Ping(ctx context.Context) (time.Duration, error) {
+	t.pingM.Lock()
+	defer t.pingM.Unlock()
+
+	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+	start := time.Now()
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return 0, io.EOF
+	}
+	err := t.conn.WriteControl(websocket.PingMessage, []byte(nonce), time.Now().Add(5*time.Second))
+	t.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		select {
+		case got := <-t.pong:
+			// A pong for an earlier ping that timed out is skipped
+			if got == nonce {
+				d := time.Since(start)
+				t.rtt.Add(d)
+				return d, nil
+			}
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// RTT returns the network round trips measured by Ping
+func (t *SocketIOTerminal) RTT() *Latency { return &t.rtt }
+
+// Echo returns the times from input being sent to the next output arriving
+func (t *SocketIOTerminal) Echo() *Latency { return &t.echo }
+
 func (t *SocketIOTerminal) emit(event string, payload any) error {
 	frameData, err := json.Marshal([]any{event, payload})
 	if err != nil {
@@ -262,8 +382,20 @@ func (t *SocketIOTerminal) Run(stdin io.Reader, stdout io.Writer) error {
 						return
 					}
 					t.setSessionID(p.SessionID)
+					// Send the size asked for while the session was starting
+					if err := t.flushResize(); err != nil {
+						errChan <- err
+						return
+					}
 					startOnce.Do(func() { close(started) })
 				case "terminal:output":
+					t.echoM.Lock()
+					if !t.inputAt.IsZero() {
+						t.echo.Add(time.Since(t.inputAt))
+						t.inputAt = time.Time{}
+					}
+					t.echoM.Unlock()
+
 					var p terminalOutputPayload
 					if err := json.Unmarshal(payload, &p); err != nil {
 						continue
@@ -310,6 +442,12 @@ func (t *SocketIOTerminal) Run(stdin io.Reader, stdout io.Writer) error {
 				return
 			}
 
+			t.echoM.Lock()
+			if t.inputAt.IsZero() {
+				t.inputAt = time.Now()
+			}
+			t.echoM.Unlock()
+
 			if err := t.emit("terminal:input", terminalInputPayload{
 				SessionID: t.getSessionID(),
 				Data:      base64.StdEncoding.EncodeToString(buf[:n]),
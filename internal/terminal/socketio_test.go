@@ -1,6 +1,17 @@
 package terminal
 
-import "testing"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
 
 func TestBuildSocketIOURL(t *testing.T) {
 	engineURL, namespace, err := buildSocketIOURL(
@@ -60,3 +71,102 @@ func TestParseSocketIOEvent(t *testing.T) {
 		})
 	}
 }
+
+// TestSocketIOTerminalResize checks that a size set before the session starts
+// is sent once it does, that a burst of resizes sends only the last size, and
+// that a size the server already has is not sent again
+func TestSocketIOTerminalResize(t *testing.T) {
+	old := resizeDebounce
+	resizeDebounce = 20 * time.Millisecond
+	t.Cleanup(func() { resizeDebounce = old })
+
+	resizes := make(chan string, 16)
+	startSession := make(chan struct{})
+	endSession := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var writeM sync.Mutex
+		send := func(packet string) {
+			writeM.Lock()
+			defer writeM.Unlock()
+			conn.WriteMessage(websocket.TextMessage, []byte(packet))
+		}
+		send(`0{"sid":"x"}`)
+		go func() {
+			<-startSession
+			send(`42/terminal,["terminal:started",{"sessionId":"s1"}]`)
+			<-endSession
+			send(`42/terminal,["terminal:ended",{}]`)
+		}()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			packet := string(data)
+			switch {
+			case packet == "40/terminal,":
+				send(`40/terminal,{"sid":"y"}`)
+			case strings.Contains(packet, "terminal:resize"):
+				resizes <- packet
+			}
+		}
+	}))
+	defer srv.Close()
+
+	term, err := NewSocketIOTerminal(context.Background(), srv.URL+"/terminal", "token", "sbx")
+	if err != nil {
+		t.Fatalf("NewSocketIOTerminal() error = %v", err)
+	}
+	defer term.Close()
+
+	expectResize := func(want string) {
+		t.Helper()
+		select {
+		case got := <-resizes:
+			if !strings.Contains(got, want) {
+				t.Fatalf("resize packet = %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected resize %s", want)
+		}
+	}
+	expectNoResize := func() {
+		t.Helper()
+		select {
+		case got := <-resizes:
+			t.Fatalf("Unexpected resize %s", got)
+		case <-time.After(5 * resizeDebounce):
+		}
+	}
+
+	// Queued until the session starts
+	term.Resize(80, 24)
+	term.Resize(120, 40)
+
+	stdin, stdinW := io.Pipe()
+	defer stdinW.Close()
+	done := make(chan error, 1)
+	go func() { done <- term.Run(stdin, io.Discard) }()
+
+	close(startSession)
+	expectResize(`"cols":120,"rows":40`)
+
+	for cols := 100; cols <= 140; cols += 10 {
+		term.Resize(cols, 50)
+	}
+	expectResize(`"cols":140,"rows":50`)
+	expectNoResize()
+
+	term.Resize(140, 50)
+	expectNoResize()
+
+	close(endSession)
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
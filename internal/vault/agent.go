@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// agentDialTimeout bounds how long clients wait for the agent
+const agentDialTimeout = 2 * time.Second
+
+// ErrNoAgent is returned when no agent is listening on the socket
+var ErrNoAgent = errors.New("vault agent is not running")
+
+// ServeAgent hands key to clients connecting to ln until ttl has passed or a
+// client asks it to lock. The protocol is one line per connection: "key" is
+// answered with the base64 key, "lock" stops the agent.
+func ServeAgent(ln net.Listener, key []byte, ttl time.Duration) error {
+	var once sync.Once
+	stop := func() { once.Do(func() { ln.Close() }) }
+	timer := time.AfterFunc(ttl, stop)
+	defer timer.Stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if handleAgentConn(conn, key) {
+			stop()
+		}
+	}
+}
+
+// handleAgentConn answers one request and reports whether it was "lock"
+func handleAgentConn(conn net.Conn, key []byte) bool {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentDialTimeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	switch strings.TrimSpace(line) {
+	case "key":
+		fmt.Fprintln(conn, base64.StdEncoding.EncodeToString(key))
+	case "lock":
+		fmt.Fprintln(conn, "ok")
+		return true
+	default:
+		fmt.Fprintln(conn, "error unknown request")
+	}
+	return false
+}
+
+// agentRequest sends one request to the agent at socket and returns its reply
+func agentRequest(socket, request string) (string, error) {
+	conn, err := net.DialTimeout("unix", socket, agentDialTimeout)
+	if err != nil {
+		return "", ErrNoAgent
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentDialTimeout))
+
+	if _, err := fmt.Fprintln(conn, request); err != nil {
+		return "", fmt.Errorf("failed to talk to vault agent: %w", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to talk to vault agent: %w", err)
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// AgentKey asks the agent at socket for the key
+func AgentKey(socket string) ([]byte, error) {
+	reply, err := agentRequest(socket, "key")
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(reply)
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("unexpected reply from vault agent")
+	}
+	return key, nil
+}
+
+// LockAgent stops the agent at socket, forgetting the key. It returns
+// ErrNoAgent if none is running.
+func LockAgent(socket string) error {
+	_, err := agentRequest(socket, "lock")
+	return err
+}
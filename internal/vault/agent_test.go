@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startTestAgent(t *testing.T, key []byte, ttl time.Duration) (string, chan error) {
+	t.Helper()
+	// Unix socket paths are short on macOS, so avoid the long t.TempDir()
+	dir, err := os.MkdirTemp("", "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "agent.sock")
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- ServeAgent(ln, key, ttl) }()
+	return socket, done
+}
+
+func TestAgentKeyAndLock(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	socket, done := startTestAgent(t, key, time.Minute)
+
+	got, err := AgentKey(socket)
+	if err != nil || !bytes.Equal(got, key) {
+		t.Fatalf("AgentKey() = %x, %v", got, err)
+	}
+
+	if err := LockAgent(socket); err != nil {
+		t.Fatalf("LockAgent() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ServeAgent() error = %v", err)
+	}
+	if _, err := AgentKey(socket); !errors.Is(err, ErrNoAgent) {
+		t.Errorf("AgentKey() after lock error = %v, want ErrNoAgent", err)
+	}
+}
+
+func TestAgentExpires(t *testing.T) {
+	socket, done := startTestAgent(t, bytes.Repeat([]byte{1}, KeySize), 50*time.Millisecond)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeAgent() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not expire")
+	}
+	if _, err := AgentKey(socket); !errors.Is(err, ErrNoAgent) {
+		t.Errorf("AgentKey() after expiry error = %v, want ErrNoAgent", err)
+	}
+}
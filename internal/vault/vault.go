@@ -0,0 +1,125 @@
+// Package vault encrypts sensitive local values, such as the credentials in
+// config.yaml and secret files, with a key derived from a passphrase. An
+// agent process can hold the key so the passphrase is entered once per
+// session.
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// sealedPrefix marks and versions sealed values
+const sealedPrefix = "cvps-vault:v1:"
+
+// scrypt cost parameters; deriving a key takes about 100ms
+const (
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+	saltSize = 16
+)
+
+// KeySize is the size of a vault key in bytes
+const KeySize = chacha20poly1305.KeySize
+
+// checkPlaintext is sealed into the check value that tells whether a key is right
+const checkPlaintext = "cvps"
+
+// ErrWrongPassphrase is returned when a passphrase or key does not match the vault
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// NewKey derives a key from passphrase with a fresh salt. The returned salt
+// and check are not secret and must be stored to derive the key again.
+func NewKey(passphrase []byte) (key []byte, salt, check string, err error) {
+	rawSalt := make([]byte, saltSize)
+	if _, err := rand.Read(rawSalt); err != nil {
+		return nil, "", "", err
+	}
+	salt = base64.StdEncoding.EncodeToString(rawSalt)
+	if key, err = derive(passphrase, rawSalt); err != nil {
+		return nil, "", "", err
+	}
+	if check, err = Seal(key, []byte(checkPlaintext)); err != nil {
+		return nil, "", "", err
+	}
+	return key, salt, check, nil
+}
+
+// DeriveKey derives the key of an existing vault from its passphrase,
+// returning ErrWrongPassphrase if it does not match check
+func DeriveKey(passphrase []byte, salt, check string) ([]byte, error) {
+	rawSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault salt: %w", err)
+	}
+	key, err := derive(passphrase, rawSalt)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(key, check); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Verify returns ErrWrongPassphrase if key is not the key check was made with
+func Verify(key []byte, check string) error {
+	plain, err := Open(key, check)
+	if err != nil || string(plain) != checkPlaintext {
+		return ErrWrongPassphrase
+	}
+	return nil
+}
+
+func derive(passphrase, salt []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, KeySize)
+}
+
+// IsSealed reports whether s is a value produced by Seal
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, sealedPrefix)
+}
+
+// Seal encrypts plaintext with key into a printable string
+func Seal(key, plaintext []byte) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func Open(key []byte, sealed string) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, fmt.Errorf("value is not encrypted with cvps vault")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(sealed, sealedPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("corrupt encrypted value: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("corrupt encrypted value")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
@@ -0,0 +1,56 @@
+package vault
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key, salt, check, err := NewKey([]byte("correct horse"))
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	sealed, err := Seal(key, []byte("sk-live-123"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "sk-live-123") {
+		t.Errorf("Seal() = %q, want an opaque sealed value", sealed)
+	}
+
+	again, err := DeriveKey([]byte("correct horse"), salt, check)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	plain, err := Open(again, sealed)
+	if err != nil || string(plain) != "sk-live-123" {
+		t.Errorf("Open() = %q, %v", plain, err)
+	}
+}
+
+func TestDeriveKeyWrongPassphrase(t *testing.T) {
+	_, salt, check, err := NewKey([]byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeriveKey([]byte("battery staple"), salt, check); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("DeriveKey() error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	key, _, _, err := NewKey([]byte("pw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := Seal(key, []byte("value"))
+	tampered := sealed[:len(sealed)-4] + "AAAA"
+	if _, err := Open(key, tampered); err == nil {
+		t.Error("expected error opening a tampered value")
+	}
+	if _, err := Open(key, "plain"); err == nil {
+		t.Error("expected error opening an unsealed value")
+	}
+}
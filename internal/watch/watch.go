@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// IgnoreFunc reports whether a slash-separated path relative to the root should be ignored
+type IgnoreFunc func(relPath string, isDir bool) bool
+
+// Watcher reports batches of changed files under a directory tree. Changes are
+// debounced so an editor save or a git checkout produces a single batch.
+type Watcher struct {
+	root     string
+	debounce time.Duration
+	ignore   IgnoreFunc
+	fs       *fsnotify.Watcher
+}
+
+// New watches root recursively, skipping ignored directories
+func New(root string, debounce time.Duration, ignore IgnoreFunc) (*Watcher, error) {
+	if ignore == nil {
+		ignore = func(string, bool) bool { return false }
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{root: root, debounce: debounce, ignore: ignore, fs: fsw}
+	if err := w.addTree(root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// addTree adds watches for dir and its non-ignored subdirectories
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Directories can vanish between the event and the walk
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel := w.rel(p); rel != "." && w.ignore(rel, true) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(p)
+	})
+}
+
+func (w *Watcher) rel(p string) string {
+	rel, err := filepath.Rel(w.root, p)
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Run delivers batches of changed relative paths to onChange until ctx is
+// cancelled or the watcher fails
+func (w *Watcher) Run(ctx context.Context, onChange func([]string)) error {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			rel := w.rel(event.Name)
+			info, err := os.Stat(event.Name)
+			isDir := err == nil && info.IsDir()
+			if w.ignore(rel, isDir) {
+				continue
+			}
+
+			if isDir && event.Has(fsnotify.Create) {
+				if err := w.addTree(event.Name); err != nil {
+					return err
+				}
+			}
+			if !isDir {
+				pending[rel] = true
+			}
+			timer.Reset(w.debounce)
+
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			batch := make([]string, 0, len(pending))
+			for p := range pending {
+				batch = append(batch, p)
+			}
+			sort.Strings(batch)
+			pending = make(map[string]bool)
+			onChange(batch)
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcherBatchesChanges(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "ignored"), 0755)
+
+	w, err := New(root, 50*time.Millisecond, func(rel string, isDir bool) bool {
+		return rel == "ignored" || strings.HasPrefix(rel, "ignored/") || strings.HasSuffix(rel, ".log")
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batches := make(chan []string, 4)
+	go w.Run(ctx, func(paths []string) { batches <- paths })
+
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(root, "b.txt"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(root, "debug.log"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(root, "ignored", "c.txt"), []byte("c"), 0644)
+
+	select {
+	case batch := <-batches:
+		if strings.Join(batch, ",") != "a.txt,b.txt" {
+			t.Fatalf("batch = %v, want [a.txt b.txt]", batch)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for changes")
+	}
+}
+
+func TestWatcherFollowsNewDirectories(t *testing.T) {
+	root := t.TempDir()
+
+	w, err := New(root, 50*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batches := make(chan []string, 4)
+	go w.Run(ctx, func(paths []string) { batches <- paths })
+
+	os.Mkdir(filepath.Join(root, "src"), 0755)
+	// Give the watcher a moment to register the new directory
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0644)
+
+	for {
+		select {
+		case batch := <-batches:
+			for _, p := range batch {
+				if p == "src/main.go" {
+					return
+				}
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for change in new directory")
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package workspaces keeps a registry of project directories and their
+// sandboxes so commands can resolve a project by name from any directory.
+package workspaces
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/achronon/cvps/internal/config"
+	"github.com/achronon/cvps/internal/localctx"
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is a project directory and the sandboxes recorded in it
+type Workspace struct {
+	Name      string           `yaml:"name" json:"name"`
+	Dir       string           `yaml:"dir" json:"dir"`
+	Current   string           `yaml:"current,omitempty" json:"current,omitempty"`
+	Sandboxes []localctx.Entry `yaml:"sandboxes" json:"sandboxes"`
+	UpdatedAt string           `yaml:"updated_at" json:"updated_at"`
+}
+
+// CurrentSandbox returns the ID commands should use for the workspace
+func (w *Workspace) CurrentSandbox() string {
+	if w.Current != "" {
+		return w.Current
+	}
+	if len(w.Sandboxes) == 1 {
+		return w.Sandboxes[0].SandboxID
+	}
+	return ""
+}
+
+// Registry holds all workspaces, persisted to ~/.cvps/workspaces.yaml
+type Registry struct {
+	Workspaces []Workspace `yaml:"workspaces"`
+
+	path string
+}
+
+// Path returns the location of the registry file
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspaces.yaml"), nil
+}
+
+// Load reads the registry. A missing file yields an empty registry.
+func Load() (*Registry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the registry from path
+func LoadFile(path string) (*Registry, error) {
+	r := &Registry{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspaces: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces: %w", err)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to the file it was loaded from
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	sort.Slice(r.Workspaces, func(i, j int) bool { return r.Workspaces[i].Dir < r.Workspaces[j].Dir })
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspaces: %w", err)
+	}
+
+	if err := os.WriteFile(r.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write workspaces: %w", err)
+	}
+	return nil
+}
+
+// Record stores the sandboxes of the project in dir, which must be absolute.
+// A nil or empty context forgets the directory.
+func (r *Registry) Record(dir string, c *localctx.Context) {
+	if c == nil || len(c.Sandboxes) == 0 {
+		r.Forget(dir)
+		return
+	}
+
+	w := Workspace{
+		Name:      filepath.Base(dir),
+		Dir:       dir,
+		Current:   c.Current,
+		Sandboxes: slices.Clone(c.Sandboxes),
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Dir == dir {
+			r.Workspaces[i] = w
+			return
+		}
+	}
+	r.Workspaces = append(r.Workspaces, w)
+}
+
+// Forget removes a directory and reports whether it was registered
+func (r *Registry) Forget(dir string) bool {
+	before := len(r.Workspaces)
+	r.Workspaces = slices.DeleteFunc(r.Workspaces, func(w Workspace) bool { return w.Dir == dir })
+	return len(r.Workspaces) != before
+}
+
+// Find returns the workspaces whose name or directory is ref
+func (r *Registry) Find(ref string) []Workspace {
+	var matches []Workspace
+	for _, w := range r.Workspaces {
+		if w.Name == ref || w.Dir == ref {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,48 @@
+package workspaces
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/achronon/cvps/internal/localctx"
+)
+
+func TestRecordAndFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workspaces.yaml")
+
+	r, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	c := &localctx.Context{}
+	c.Put(localctx.Entry{SandboxID: "sbx-dev", Name: "dev"})
+	c.Put(localctx.Entry{SandboxID: "sbx-test", Name: "test"})
+	r.Record("/home/me/src/myproject", c)
+	r.Record("/home/me/src/other", &localctx.Context{Sandboxes: []localctx.Entry{{SandboxID: "sbx-other"}}})
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	matches := loaded.Find("myproject")
+	if len(matches) != 1 {
+		t.Fatalf("Find() returned %d workspaces, want 1", len(matches))
+	}
+	if got := matches[0].CurrentSandbox(); got != "sbx-test" {
+		t.Errorf("CurrentSandbox() = %s, want sbx-test", got)
+	}
+
+	other := loaded.Find("/home/me/src/other")
+	if len(other) != 1 || other[0].CurrentSandbox() != "sbx-other" {
+		t.Errorf("Find() by dir = %+v", other)
+	}
+
+	loaded.Record("/home/me/src/myproject", &localctx.Context{})
+	if len(loaded.Find("myproject")) != 0 {
+		t.Error("expected empty context to forget the workspace")
+	}
+}
@@ -0,0 +1,68 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Kinds of connection recorded in the access log
+const (
+	AccessKindSSH      = "ssh"
+	AccessKindTerminal = "terminal"
+)
+
+// AccessLogEntry is one SSH or terminal connection to a sandbox
+type AccessLogEntry struct {
+	ID        string `json:"id"`
+	SandboxID string `json:"sandboxId"`
+	Kind      string `json:"kind"`
+
+	// Email of the account member who connected
+	User     string `json:"user"`
+	SourceIP string `json:"sourceIp"`
+
+	StartedAt string `json:"startedAt"`
+	// Empty while the connection is still open
+	EndedAt         string `json:"endedAt,omitempty"`
+	DurationSeconds int    `json:"durationSeconds"`
+}
+
+// Active reports whether the connection is still open
+func (e *AccessLogEntry) Active() bool {
+	return e.EndedAt == ""
+}
+
+// Duration returns how long the connection lasted, or has lasted so far
+func (e *AccessLogEntry) Duration() time.Duration {
+	return time.Duration(e.DurationSeconds) * time.Second
+}
+
+// AccessLog is the response of ListAccessLog
+type AccessLog struct {
+	Data []AccessLogEntry `json:"data"`
+}
+
+// ListAccessLog returns the connections to a sandbox started after since,
+// newest first and at most limit of them. A zero since or limit leaves
+// that bound to the server.
+func (c *Client) ListAccessLog(ctx context.Context, sandboxID string, since time.Time, limit int) ([]AccessLogEntry, error) {
+	query := url.Values{}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if limit > 0 {
+		query.Set("limit", fmt.Sprint(limit))
+	}
+	path := "/sandboxes/" + sandboxID + "/access-log"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var log AccessLog
+	if err := c.Get(ctx, path, &log); err != nil {
+		return nil, err
+	}
+	return log.Data, nil
+}
@@ -0,0 +1,52 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListAccessLog(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-1/access-log" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(AccessLog{Data: []AccessLogEntry{
+			{ID: "acc-2", Kind: AccessKindTerminal, User: "bob@example.com", StartedAt: "2024-03-29T17:00:00Z", DurationSeconds: 600},
+			{ID: "acc-1", Kind: AccessKindSSH, User: "alice@example.com", StartedAt: "2024-03-29T09:00:00Z", EndedAt: "2024-03-29T10:30:00Z", DurationSeconds: 5400},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	since := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	entries, err := client.ListAccessLog(context.Background(), "sbx-1", since, 50)
+	if err != nil {
+		t.Fatalf("ListAccessLog() error = %v", err)
+	}
+	if query != "limit=50&since=2024-03-29T00%3A00%3A00Z" {
+		t.Errorf("Unexpected query: %s", query)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if !entries[0].Active() || entries[1].Active() {
+		t.Error("Expected only the first connection to be active")
+	}
+	if entries[1].Duration() != 90*time.Minute {
+		t.Errorf("Duration() = %s, want 1h30m", entries[1].Duration())
+	}
+
+	if _, err := client.ListAccessLog(context.Background(), "sbx-1", time.Time{}, 0); err != nil {
+		t.Fatalf("ListAccessLog() error = %v", err)
+	}
+	if query != "" {
+		t.Errorf("Expected no query without bounds, got %s", query)
+	}
+}
@@ -0,0 +1,220 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeviceAuthResponse starts a device login: the user visits VerificationURI
+// and enters UserCode while the client polls with DeviceCode
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+
+	// VerificationPhrase is shown both in the browser and the terminal. When
+	// set, the login only completes after ConfirmDeviceAuth, so a device code
+	// phished from someone else cannot be approved unnoticed.
+	VerificationPhrase string `json:"verification_phrase,omitempty"`
+}
+
+// MFARequiredError is returned while polling a device login when the account
+// requires a second factor. Pass MFAToken and a one-time code to
+// CompleteMFAChallenge.
+type MFARequiredError struct {
+	MFAToken string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "mfa_required"
+}
+
+// TokenResponse is issued once a device login is approved
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// User is the account a client is authenticated as
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// InitiateDeviceAuth starts an OAuth device login
+func (c *Client) InitiateDeviceAuth(ctx context.Context) (*DeviceAuthResponse, error) {
+	data := url.Values{}
+	data.Set("client_id", "cvps-cli")
+	data.Set("scope", "sandboxes:read sandboxes:write")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/auth/device", strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.applyHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var result DeviceAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PollDeviceAuth waits until the device login is approved, checking every
+// interval, and returns the access token. Cancel ctx to give up.
+func (c *Client) PollDeviceAuth(ctx context.Context, deviceCode string, interval time.Duration) (*TokenResponse, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			token, err := c.checkDeviceAuth(ctx, deviceCode)
+			if err != nil {
+				// Check if it's an "authorization_pending" error
+				if strings.Contains(err.Error(), "authorization_pending") {
+					continue
+				}
+				return nil, err
+			}
+			return token, nil
+		}
+	}
+}
+
+func (c *Client) checkDeviceAuth(ctx context.Context, deviceCode string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("device_code", deviceCode)
+	data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	return c.requestToken(ctx, data)
+}
+
+// ConfirmDeviceAuth tells the server the user saw the same verification
+// phrase in the terminal as in the browser
+func (c *Client) ConfirmDeviceAuth(ctx context.Context, deviceCode, phrase string) error {
+	data := url.Values{}
+	data.Set("device_code", deviceCode)
+	data.Set("verification_phrase", phrase)
+	return c.postAuthForm(ctx, "/auth/device/confirm", data)
+}
+
+// DenyDeviceAuth cancels a device login, e.g. because the verification
+// phrases did not match
+func (c *Client) DenyDeviceAuth(ctx context.Context, deviceCode string) error {
+	data := url.Values{}
+	data.Set("device_code", deviceCode)
+	return c.postAuthForm(ctx, "/auth/device/deny", data)
+}
+
+// CompleteMFAChallenge finishes a login that returned MFARequiredError with a
+// one-time code from the user's authenticator
+func (c *Client) CompleteMFAChallenge(ctx context.Context, mfaToken, code string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("mfa_token", mfaToken)
+	data.Set("otp", code)
+	data.Set("grant_type", "urn:cvps:params:oauth:grant-type:mfa-otp")
+	return c.requestToken(ctx, data)
+}
+
+// requestToken posts a grant to the token endpoint. OAuth errors are returned
+// as their error code, e.g. "authorization_pending".
+func (c *Client) requestToken(ctx context.Context, data url.Values) (*TokenResponse, error) {
+	resp, err := c.sendAuthForm(ctx, "/auth/token", data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error    string `json:"error"`
+			MFAToken string `json:"mfa_token"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "mfa_required" {
+			return nil, &MFARequiredError{MFAToken: errResp.MFAToken}
+		}
+		return nil, errors.New(errResp.Error)
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// postAuthForm posts to an auth endpoint that returns no body
+func (c *Client) postAuthForm(ctx context.Context, path string, data url.Values) error {
+	resp, err := c.sendAuthForm(ctx, path, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendAuthForm posts form data, with the CLI's client ID, to an auth endpoint
+func (c *Client) sendAuthForm(ctx context.Context, path string, data url.Values) (*http.Response, error) {
+	data.Set("client_id", "cvps-cli")
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.applyHeaders(req)
+
+	return c.httpClient.Do(req)
+}
+
+// GetCurrentUser returns the authenticated user
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/users/me", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -1,4 +1,4 @@
-package api
+package claudevps
 
 import (
 	"context"
@@ -197,3 +197,54 @@ func TestPollDeviceAuth_Timeout(t *testing.T) {
 		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
 	}
 }
+
+func TestDeviceAuthMFA(t *testing.T) {
+	var confirmed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("client_id") != "cvps-cli" {
+			t.Errorf("Expected client_id cvps-cli, got %q", r.Form.Get("client_id"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/auth/device/confirm":
+			confirmed = r.Form.Get("verification_phrase")
+		case r.Form.Get("grant_type") == "urn:ietf:params:oauth:grant-type:device_code":
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "mfa_required", "mfa_token": "mfa-1"})
+		case r.Form.Get("otp") == "123456" && r.Form.Get("mfa_token") == "mfa-1":
+			json.NewEncoder(w).Encode(TokenResponse{AccessToken: "test-access-token"})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_otp"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "")
+	ctx := context.Background()
+
+	if err := client.ConfirmDeviceAuth(ctx, "device-code", "amber falcon river"); err != nil {
+		t.Fatalf("ConfirmDeviceAuth failed: %v", err)
+	}
+	if confirmed != "amber falcon river" {
+		t.Errorf("Expected phrase to be sent, got %q", confirmed)
+	}
+
+	_, err := client.PollDeviceAuth(ctx, "device-code", time.Millisecond)
+	var mfaErr *MFARequiredError
+	if !errors.As(err, &mfaErr) || mfaErr.MFAToken != "mfa-1" {
+		t.Fatalf("Expected MFARequiredError, got %v", err)
+	}
+
+	if _, err := client.CompleteMFAChallenge(ctx, "mfa-1", "000000"); err == nil || err.Error() != "invalid_otp" {
+		t.Errorf("Expected invalid_otp, got %v", err)
+	}
+	token, err := client.CompleteMFAChallenge(ctx, "mfa-1", "123456")
+	if err != nil {
+		t.Fatalf("CompleteMFAChallenge failed: %v", err)
+	}
+	if token.AccessToken != "test-access-token" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+}
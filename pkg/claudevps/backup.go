@@ -0,0 +1,74 @@
+package claudevps
+
+import "context"
+
+// BackupTarget is a customer-owned S3-compatible bucket that snapshots and
+// backups are written to. Credentials are referenced by secret name; the
+// secrets themselves stay server-side.
+type BackupTarget struct {
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"` // empty for AWS S3
+	Region   string `json:"region,omitempty"`
+
+	AccessKeyIDSecret     string `json:"accessKeyIdSecret"`
+	SecretAccessKeySecret string `json:"secretAccessKeySecret"`
+
+	VerifiedAt string `json:"verifiedAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt,omitempty"`
+}
+
+// SetBackupTargetRequest creates or replaces the backup target
+type SetBackupTargetRequest struct {
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Region   string `json:"region,omitempty"`
+
+	AccessKeyIDSecret     string `json:"accessKeyIdSecret"`
+	SecretAccessKeySecret string `json:"secretAccessKeySecret"`
+}
+
+// BackupTargetVerification is the result of writing, reading back and
+// deleting a test object in the backup target
+type BackupTargetVerification struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	CheckedAt string `json:"checkedAt"`
+}
+
+// SetBackupTarget sets the bucket snapshots and backups are written to,
+// replacing any existing target
+func (c *Client) SetBackupTarget(ctx context.Context, req *SetBackupTargetRequest) (*BackupTarget, error) {
+	var target BackupTarget
+	if err := c.Post(ctx, "/backup/target", req, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// GetBackupTarget returns the backup target; it returns a not found error if
+// none is set
+func (c *Client) GetBackupTarget(ctx context.Context) (*BackupTarget, error) {
+	var target BackupTarget
+	if err := c.Get(ctx, "/backup/target", &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// DeleteBackupTarget removes the backup target, reverting to platform storage
+func (c *Client) DeleteBackupTarget(ctx context.Context) error {
+	return c.Delete(ctx, "/backup/target")
+}
+
+// VerifyBackupTarget checks that the backup target is writable with its
+// stored credentials
+func (c *Client) VerifyBackupTarget(ctx context.Context) (*BackupTargetVerification, error) {
+	var result BackupTargetVerification
+	if err := c.Post(ctx, "/backup/target/verify", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,60 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBackupTarget(t *testing.T) {
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/backup/target":
+			var req SetBackupTargetRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(BackupTarget{Bucket: req.Bucket, Prefix: req.Prefix, AccessKeyIDSecret: req.AccessKeyIDSecret})
+		case r.Method == "GET" && r.URL.Path == "/backup/target":
+			json.NewEncoder(w).Encode(BackupTarget{Bucket: "acme", VerifiedAt: "2024-01-15T10:00:00Z"})
+		case r.Method == "POST" && r.URL.Path == "/backup/target/verify":
+			json.NewEncoder(w).Encode(BackupTargetVerification{OK: false, Error: "AccessDenied"})
+		case r.Method == "DELETE" && r.URL.Path == "/backup/target":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	target, err := client.SetBackupTarget(ctx, &SetBackupTargetRequest{Bucket: "acme", Prefix: "cvps", AccessKeyIDSecret: "KEY"})
+	if err != nil {
+		t.Fatalf("SetBackupTarget() error = %v", err)
+	}
+	if target.Bucket != "acme" || target.Prefix != "cvps" || target.AccessKeyIDSecret != "KEY" {
+		t.Errorf("Unexpected target: %+v", target)
+	}
+
+	if target, err = client.GetBackupTarget(ctx); err != nil || target.VerifiedAt == "" {
+		t.Errorf("GetBackupTarget() = %+v, %v", target, err)
+	}
+
+	result, err := client.VerifyBackupTarget(ctx)
+	if err != nil {
+		t.Fatalf("VerifyBackupTarget() error = %v", err)
+	}
+	if result.OK || result.Error != "AccessDenied" {
+		t.Errorf("Unexpected verification: %+v", result)
+	}
+
+	if err := client.DeleteBackupTarget(ctx); err != nil || !deleted {
+		t.Errorf("DeleteBackupTarget() error = %v, deleted = %v", err, deleted)
+	}
+}
@@ -0,0 +1,111 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxBatchSize is the most sandboxes the API accepts in one batch request;
+// the batch methods split larger sets into several requests
+const MaxBatchSize = 50
+
+// Batch operations
+const (
+	BatchCreate = "create"
+	BatchDelete = "delete"
+	BatchStatus = "status"
+)
+
+type batchRequest struct {
+	Op        string                  `json:"op"`
+	Sandboxes []*CreateSandboxRequest `json:"sandboxes,omitempty"`
+	IDs       []string                `json:"ids,omitempty"`
+	Purge     bool                    `json:"purge,omitempty"`
+}
+
+type batchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of one item of a batch request, in the order
+// the items were sent. Status is the HTTP status the item would have had as a
+// request of its own.
+type BatchResult struct {
+	ID      string    `json:"id,omitempty"`
+	Status  int       `json:"status"`
+	Sandbox *Sandbox  `json:"sandbox,omitempty"`
+	Error   *APIError `json:"error,omitempty"`
+}
+
+// Err returns the item's error, which works with IsNotFound, IsConflict and
+// the other helpers, or nil if it succeeded
+func (r BatchResult) Err() error {
+	if r.Error == nil && r.Status < 300 {
+		return nil
+	}
+	apiErr := &APIError{StatusCode: r.Status, Message: fmt.Sprintf("unexpected status: %d", r.Status)}
+	if r.Error != nil {
+		*apiErr = *r.Error
+		apiErr.StatusCode = r.Status
+	}
+	return apiErr
+}
+
+// IsBatchUnsupported reports whether err means the API has no batch
+// endpoint, so callers should fall back to one request per sandbox
+func IsBatchUnsupported(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 404 || apiErr.StatusCode == 405
+	}
+	return false
+}
+
+// BatchCreateSandboxes creates several sandboxes. Items fail independently;
+// check each result's Err.
+func (c *Client) BatchCreateSandboxes(ctx context.Context, reqs []*CreateSandboxRequest) ([]BatchResult, error) {
+	var results []BatchResult
+	for start := 0; start < len(reqs); start += MaxBatchSize {
+		chunk := reqs[start:min(start+MaxBatchSize, len(reqs))]
+		res, err := c.batch(ctx, &batchRequest{Op: BatchCreate, Sandboxes: chunk}, len(chunk))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// BatchDeleteSandboxes deletes several sandboxes, moving them to the trash
+// or, with purge, deleting them permanently
+func (c *Client) BatchDeleteSandboxes(ctx context.Context, ids []string, purge bool) ([]BatchResult, error) {
+	return c.batchIDs(ctx, BatchDelete, ids, purge)
+}
+
+// BatchSandboxStatus gets the status of several sandboxes
+func (c *Client) BatchSandboxStatus(ctx context.Context, ids []string) ([]BatchResult, error) {
+	return c.batchIDs(ctx, BatchStatus, ids, false)
+}
+
+func (c *Client) batchIDs(ctx context.Context, op string, ids []string, purge bool) ([]BatchResult, error) {
+	var results []BatchResult
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		chunk := ids[start:min(start+MaxBatchSize, len(ids))]
+		res, err := c.batch(ctx, &batchRequest{Op: op, IDs: chunk, Purge: purge}, len(chunk))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+func (c *Client) batch(ctx context.Context, req *batchRequest, n int) ([]BatchResult, error) {
+	var resp batchResponse
+	if err := c.Post(ctx, "/sandboxes/batch", req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != n {
+		return nil, fmt.Errorf("batch %s returned %d results for %d sandboxes", req.Op, len(resp.Results), n)
+	}
+	return resp.Results, nil
+}
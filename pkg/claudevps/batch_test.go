@@ -0,0 +1,70 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchDeleteSandboxes_SplitsLargeBatches(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/sandboxes/batch" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req batchRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Op != BatchDelete || !req.Purge {
+			t.Errorf("Unexpected batch request: %+v", req)
+		}
+		sizes = append(sizes, len(req.IDs))
+
+		var resp batchResponse
+		for _, id := range req.IDs {
+			if id == "sbx-7" {
+				resp.Results = append(resp.Results, BatchResult{ID: id, Status: 409, Error: &APIError{Code: CodeAlreadyDeleting, Message: "already being deleted"}})
+				continue
+			}
+			resp.Results = append(resp.Results, BatchResult{ID: id, Status: 204})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	ids := make([]string, MaxBatchSize+10)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("sbx-%d", i)
+	}
+
+	client := NewClient(server.URL, "test-key")
+	results, err := client.BatchDeleteSandboxes(context.Background(), ids, true)
+	if err != nil {
+		t.Fatalf("BatchDeleteSandboxes() error = %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != MaxBatchSize || sizes[1] != 10 {
+		t.Errorf("Expected batches of %d and 10, got %v", MaxBatchSize, sizes)
+	}
+	if len(results) != len(ids) {
+		t.Fatalf("Expected %d results, got %d", len(ids), len(results))
+	}
+	if err := results[7].Err(); ConflictCode(err) != CodeAlreadyDeleting {
+		t.Errorf("Expected already_deleting conflict for sbx-7, got %v", err)
+	}
+	if err := results[8].Err(); err != nil {
+		t.Errorf("Expected sbx-8 to succeed, got %v", err)
+	}
+}
+
+func TestBatchSandboxStatus_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	_, err := client.BatchSandboxStatus(context.Background(), []string{"sbx-1"})
+	if !IsBatchUnsupported(err) {
+		t.Errorf("Expected unsupported batch endpoint, got %v", err)
+	}
+}
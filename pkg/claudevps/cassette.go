@@ -0,0 +1,218 @@
+package claudevps
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+// Environment variables that switch every client into record or replay mode.
+// Each names a cassette file holding the recorded API interactions.
+const (
+	RecordEnv = "CVPS_HTTP_RECORD"
+	ReplayEnv = "CVPS_HTTP_REPLAY"
+)
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Method string `json:"method"`
+	Path   string `json:"path"` // path and query, without the host
+
+	Status     int               `json:"status"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBase64 []byte            `json:"body_base64,omitempty"` // set instead of Body for binary responses
+}
+
+func (i *Interaction) body() []byte {
+	if i.BodyBase64 != nil {
+		return i.BodyBase64
+	}
+	return []byte(i.Body)
+}
+
+// Cassette is the file format for recorded interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// recordedHeaders are the response headers kept in cassettes; the rest vary
+// between runs or are irrelevant to the client
+var recordedHeaders = []string{"Content-Type", requestIDHeader}
+
+// cassetteTransport returns a transport recording to or replaying from the
+// cassette named by the environment, or next if neither is set
+func cassetteTransport(next http.RoundTripper) http.RoundTripper {
+	if path := os.Getenv(ReplayEnv); path != "" {
+		return sharedReplay(path)
+	}
+	if path := os.Getenv(RecordEnv); path != "" {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return &recordTransport{next: next, cassette: sharedRecording(path)}
+	}
+	return next
+}
+
+// recording is a cassette being written. Every client in the process
+// appends to the same one so commands that create several clients are
+// captured in order.
+type recording struct {
+	mu       sync.Mutex
+	path     string
+	cassette Cassette
+}
+
+var (
+	cassettesMu sync.Mutex
+	recordings  = map[string]*recording{}
+	replays     = map[string]*replayTransport{}
+)
+
+func sharedRecording(path string) *recording {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+	if r, ok := recordings[path]; ok {
+		return r
+	}
+	r := &recording{path: path}
+	recordings[path] = r
+	return r
+}
+
+// add appends the interaction and rewrites the file, so the cassette is
+// complete even if the process exits without cleanup
+func (r *recording) add(i Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0600)
+}
+
+type recordTransport struct {
+	next     http.RoundTripper
+	cassette *recording
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	i := Interaction{Method: req.Method, Path: req.URL.RequestURI(), Status: resp.StatusCode}
+	for _, key := range recordedHeaders {
+		if v := resp.Header.Get(key); v != "" {
+			if i.Header == nil {
+				i.Header = make(map[string]string)
+			}
+			i.Header[key] = v
+		}
+	}
+	if utf8.Valid(body) {
+		i.Body = string(body)
+	} else {
+		i.BodyBase64 = body
+	}
+
+	if err := t.cassette.add(i); err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, i.Path, err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from a cassette without touching the
+// network. Each interaction is used once, in order, so repeated calls to
+// the same endpoint get successive responses. Like recordings, a cassette is
+// shared by every client in the process.
+type replayTransport struct {
+	path string
+
+	once     sync.Once
+	loadErr  error
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+func sharedReplay(path string) *replayTransport {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+	if t, ok := replays[path]; ok {
+		return t
+	}
+	t := &replayTransport{path: path}
+	replays[path] = t
+	return t
+}
+
+func (t *replayTransport) load() error {
+	t.once.Do(func() {
+		data, err := os.ReadFile(t.path)
+		if err != nil {
+			t.loadErr = fmt.Errorf("failed to read cassette: %w", err)
+			return
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			t.loadErr = fmt.Errorf("failed to parse cassette %s: %w", t.path, err)
+			return
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+	})
+	return t.loadErr
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path := req.URL.RequestURI()
+	for idx := range t.cassette.Interactions {
+		i := &t.cassette.Interactions[idx]
+		if t.used[idx] || i.Method != req.Method || i.Path != path {
+			continue
+		}
+		t.used[idx] = true
+
+		resp := &http.Response{
+			Status:     fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode: i.Status,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(bytes.NewReader(i.body())),
+			Request:    req,
+		}
+		for key, v := range i.Header {
+			resp.Header.Set(key, v)
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, path, t.path)
+}
@@ -0,0 +1,100 @@
+package claudevps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(requestIDHeader, "req-1")
+		switch {
+		case r.URL.Path == "/sandboxes/sbx-1/status" && calls == 1:
+			w.Write([]byte(`{"id":"sbx-1","status":"provisioning"}`))
+		case r.URL.Path == "/sandboxes/sbx-1/status":
+			w.Write([]byte(`{"id":"sbx-1","status":"running"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not_found","message":"sandbox not found"}`))
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	t.Setenv(RecordEnv, path)
+	client := NewClient(server.URL, "test-key")
+	for _, want := range []string{"provisioning", "running"} {
+		s, err := client.GetSandboxStatus(ctx, "sbx-1")
+		if err != nil {
+			t.Fatalf("GetSandboxStatus() while recording error = %v", err)
+		}
+		if s.Status != want {
+			t.Fatalf("status = %q, want %q", s.Status, want)
+		}
+	}
+	if _, err := client.GetSandbox(ctx, "sbx-gone"); !IsNotFound(err) {
+		t.Fatalf("GetSandbox() while recording error = %v, want not found", err)
+	}
+	server.Close()
+
+	t.Setenv(RecordEnv, "")
+	t.Setenv(ReplayEnv, path)
+	client = NewClient("https://api.example.invalid", "other-key")
+	for _, want := range []string{"provisioning", "running"} {
+		s, err := client.GetSandboxStatus(ctx, "sbx-1")
+		if err != nil {
+			t.Fatalf("GetSandboxStatus() on replay error = %v", err)
+		}
+		if s.Status != want {
+			t.Errorf("replayed status = %q, want %q", s.Status, want)
+		}
+	}
+
+	_, err := client.GetSandbox(ctx, "sbx-gone")
+	if !IsNotFound(err) {
+		t.Fatalf("replayed GetSandbox() error = %v, want not found", err)
+	}
+	if apiErr := err.(*APIError); apiErr.RequestID != "req-1" {
+		t.Errorf("RequestID = %q, want the recorded req-1", apiErr.RequestID)
+	}
+
+	if _, err := client.GetSandboxStatus(ctx, "sbx-1"); err == nil || !strings.Contains(err.Error(), "no recorded response for GET /sandboxes/sbx-1/status") {
+		t.Errorf("extra request error = %v, want no recorded response", err)
+	}
+}
+
+func TestCassetteBinaryBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0x00, 0xfe})
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	t.Setenv(RecordEnv, path)
+	body, err := NewClient(server.URL, "test-key").GetStream(context.Background(), "/blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+	server.Close()
+
+	t.Setenv(RecordEnv, "")
+	t.Setenv(ReplayEnv, path)
+	got, err := NewClient(server.URL, "test-key").GetStream(context.Background(), "/blob")
+	if err != nil {
+		t.Fatalf("GetStream() on replay error = %v", err)
+	}
+	defer got.Close()
+	data := make([]byte, 8)
+	n, _ := got.Read(data)
+	if string(data[:n]) != "\xff\x00\xfe" {
+		t.Errorf("replayed body = %x, want ff00fe", data[:n])
+	}
+}
@@ -0,0 +1,515 @@
+package claudevps
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent identifies SDK requests unless WithUserAgent is used
+const DefaultUserAgent = "claudevps-go"
+
+// requestIDHeader carries the server-assigned ID used to correlate support requests
+const requestIDHeader = "X-Request-Id"
+
+// Client is the HTTP client for the ClaudeVPS API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	token      string
+	httpClient *http.Client
+	headers    http.Header
+	userAgent  string
+	logf       func(format string, args ...any)
+	verbose    bool
+
+	// gzipMinSize is the request body size from which bodies are gzipped, or
+	// zero to never compress them
+	gzipMinSize int
+
+	// readOnly refuses requests that could change anything
+	readOnly bool
+}
+
+// ClientOption is a function that configures a Client
+type ClientOption func(*Client)
+
+// WithVerbose enables verbose logging
+func WithVerbose(verbose bool) ClientOption {
+	return func(c *Client) {
+		c.verbose = verbose
+	}
+}
+
+// WithTimeout sets the HTTP client timeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLogger receives one line per request with its status, server request
+// ID and duration, for debug logs
+func WithLogger(logf func(format string, args ...any)) ClientOption {
+	return func(c *Client) {
+		c.logf = logf
+	}
+}
+
+// WithHeaders adds static headers to every request, e.g. for an
+// authenticating gateway in front of the API
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		for key, value := range headers {
+			c.headers.Set(key, value)
+		}
+	}
+}
+
+// WithRequestCompression gzips JSON request bodies of at least minSize bytes,
+// such as manifests and batched operations. Responses are always decompressed
+// transparently by the transport.
+func WithRequestCompression(minSize int) ClientOption {
+	return func(c *Client) {
+		c.gzipMinSize = minSize
+	}
+}
+
+// WithReadOnly makes the client refuse every request that could change
+// something, returning a *ReadOnlyError instead of sending it. Only GET and
+// HEAD requests, validation endpoints and status batches get through, for
+// dry runs.
+func WithReadOnly() ClientOption {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// readOnlyRequest reports whether req cannot change anything
+func readOnlyRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		if req.URL.Path == "/sandboxes/batch" {
+			var batch batchRequest
+			return json.Unmarshal(requestBody(req), &batch) == nil && batch.Op == BatchStatus
+		}
+		return strings.HasSuffix(req.URL.Path, "/validate")
+	}
+	return false
+}
+
+// readOnlyError describes a request refused by a read-only client
+func readOnlyError(req *http.Request) *ReadOnlyError {
+	return &ReadOnlyError{Method: req.Method, Path: req.URL.RequestURI(), Body: requestBody(req)}
+}
+
+// requestBody returns a copy of req's JSON body, uncompressed, or nil
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	var r io.Reader = body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		if r, err = gzip.NewReader(body); err != nil {
+			return nil
+		}
+	}
+	b, _ := io.ReadAll(r)
+	return b
+}
+
+// listTimeout is the least time listing requests get, since large accounts
+// and directories can take the API longer than the default timeout
+const listTimeout = 2 * time.Minute
+
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a context that overrides the client timeout for
+// requests made with it. Zero means no timeout, for long-running calls that
+// are bounded by ctx instead.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// withMinimumTimeout gives requests made with ctx at least timeout when
+// neither ctx nor a shorter-lived client has a say, for endpoints that
+// legitimately take longer than the default client timeout
+func (c *Client) withMinimumTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if _, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok {
+		return ctx
+	}
+	if c.httpClient.Timeout == 0 || c.httpClient.Timeout >= timeout {
+		return ctx
+	}
+	return WithRequestTimeout(ctx, timeout)
+}
+
+// httpClientFor returns the HTTP client to use for a request, honoring a
+// timeout override carried by ctx
+func (c *Client) httpClientFor(ctx context.Context) *http.Client {
+	timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	if !ok || timeout == c.httpClient.Timeout {
+		return c.httpClient
+	}
+	hc := *c.httpClient
+	hc.Timeout = timeout
+	return &hc
+}
+
+// applyHeaders sets the headers added with WithHeaders
+func (c *Client) applyHeaders(req *http.Request) {
+	for key, values := range c.headers {
+		req.Header[key] = slices.Clone(values)
+	}
+}
+
+// unixSocketScheme marks a base URL served on a local unix socket, e.g.
+// unix:///tmp/cvps.sock for a control plane running on a developer machine
+const unixSocketScheme = "unix://"
+
+// newHTTPClient returns the URL to build requests against and the HTTP client
+// to send them with. unix:// base URLs are reached over the socket at that path,
+// and RecordEnv or ReplayEnv put the client in record or replay mode.
+func newHTTPClient(baseURL string) (string, *http.Client) {
+	hc := &http.Client{Timeout: 30 * time.Second}
+
+	socket, ok := strings.CutPrefix(baseURL, unixSocketScheme)
+	if ok {
+		baseURL = "http://unix"
+		hc.Transport = dialerTransport(func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		})
+	}
+	hc.Transport = cassetteTransport(hc.Transport)
+	return baseURL, hc
+}
+
+// dialerTransport is the default transport with connections made by dial.
+// Proxy settings are ignored since dial decides where connections go.
+func dialerTransport(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = dial
+	return t
+}
+
+// WithDialer makes connections with dial instead of over TCP to the base URL
+// host, e.g. to reach an in-memory test server
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = cassetteTransport(dialerTransport(dial))
+	}
+}
+
+// NewClient creates a new API client with an API key
+func NewClient(baseURL, apiKey string, opts ...ClientOption) *Client {
+	return newClient(baseURL, apiKey, "", opts)
+}
+
+// NewClientWithToken creates a new API client with an OAuth access token
+func NewClientWithToken(baseURL, token string, opts ...ClientOption) *Client {
+	return newClient(baseURL, "", token, opts)
+}
+
+func newClient(baseURL, apiKey, token string, opts []ClientOption) *Client {
+	c := &Client{
+		apiKey:    apiKey,
+		token:     token,
+		userAgent: DefaultUserAgent,
+		logf:      func(string, ...any) {},
+	}
+	c.baseURL, c.httpClient = newHTTPClient(baseURL)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// doAuthenticatedRequest adds authentication headers to a request and executes it
+func (c *Client) doAuthenticatedRequest(req *http.Request) (*http.Response, error) {
+	c.applyHeaders(req)
+
+	// Set auth header
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	// Set common headers
+	req.Header.Set("User-Agent", c.userAgent)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	if c.readOnly && !readOnlyRequest(req) {
+		c.logf("%s %s refused by read-only client", req.Method, req.URL.Path)
+		return nil, readOnlyError(req)
+	}
+
+	if c.verbose {
+		fmt.Printf("-> %s %s\n", req.Method, req.URL)
+	}
+
+	start := time.Now()
+	hc := c.httpClientFor(req.Context())
+	resp, err := hc.Do(req)
+	if err != nil {
+		c.logf("%s %s error=%q", req.Method, req.URL.Path, err)
+		// Tell the client timeout apart from the caller's own deadline
+		if hc.Timeout > 0 && req.Context().Err() == nil && isNetTimeout(err) {
+			return nil, &TimeoutError{Timeout: hc.Timeout, Err: err}
+		}
+		return nil, err
+	}
+
+	c.logf("%s %s status=%d request_id=%s duration=%s",
+		req.Method, req.URL.Path, resp.StatusCode, resp.Header.Get(requestIDHeader), time.Since(start).Round(time.Millisecond))
+
+	if c.verbose {
+		fmt.Printf("<- %d %s\n", resp.StatusCode, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// Get performs a GET request
+func (c *Client) Get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return err
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetStream performs a GET request and returns the response body for the
+// caller to read and close. The client timeout does not apply, since it would
+// cut off long downloads and log streams; bound the call with ctx or
+// WithRequestTimeout instead.
+func (c *Client) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	if _, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); !ok {
+		ctx = WithRequestTimeout(ctx, 0)
+	}
+	resp, err := c.doRaw(ctx, "GET", path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Post performs a POST request
+func (c *Client) Post(ctx context.Context, path string, body interface{}, result interface{}) error {
+	req, err := c.newJSONRequest(ctx, "POST", path, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return err
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Delete performs a DELETE request
+func (c *Client) Delete(ctx context.Context, path string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.checkResponse(resp)
+}
+
+// Patch performs a PATCH request
+func (c *Client) Patch(ctx context.Context, path string, body interface{}, result interface{}) error {
+	req, err := c.newJSONRequest(ctx, "PATCH", path, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return err
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newJSONRequest builds a request with body encoded as JSON, gzipped when it
+// is large enough and compression is enabled
+func (c *Client) newJSONRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	if body == nil {
+		return http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	compress := c.gzipMinSize > 0 && len(data) >= c.gzipMinSize
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req, nil
+}
+
+// doRaw performs a request with a non-JSON body and returns the response for
+// streaming. The caller must close the body.
+func (c *Client) doRaw(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	resp, err := c.doAuthenticatedRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var apiErr APIError
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected status: %d", resp.StatusCode),
+			RequestID:  resp.Header.Get(requestIDHeader),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	apiErr.StatusCode = resp.StatusCode
+	apiErr.RequestID = resp.Header.Get(requestIDHeader)
+	apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return &apiErr
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date. It returns 0 if the header is missing, invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,554 @@
+package claudevps
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	client := NewClient("https://api.example.com", "test-api-key")
+	if client == nil {
+		t.Fatal("Expected non-nil client")
+	}
+	if client.baseURL != "https://api.example.com" {
+		t.Errorf("Expected baseURL https://api.example.com, got %s", client.baseURL)
+	}
+	if client.apiKey != "test-api-key" {
+		t.Errorf("Expected apiKey test-api-key, got %s", client.apiKey)
+	}
+}
+
+func TestNewClientWithToken(t *testing.T) {
+	client := NewClientWithToken("https://api.example.com", "test-token")
+	if client == nil {
+		t.Fatal("Expected non-nil client")
+	}
+	if client.token != "test-token" {
+		t.Errorf("Expected token test-token, got %s", client.token)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	t.Run("WithVerbose", func(t *testing.T) {
+		client := NewClient("https://api.example.com", "key", WithVerbose(true))
+		if !client.verbose {
+			t.Error("Expected verbose to be true")
+		}
+	})
+
+	t.Run("WithUserAgent", func(t *testing.T) {
+		if client := NewClient("https://api.example.com", "key"); client.userAgent != DefaultUserAgent {
+			t.Errorf("Expected default user agent, got %s", client.userAgent)
+		}
+		client := NewClient("https://api.example.com", "key", WithUserAgent("my-tool/1.0"))
+		if client.userAgent != "my-tool/1.0" {
+			t.Errorf("Expected user agent my-tool/1.0, got %s", client.userAgent)
+		}
+	})
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		timeout := 10 * time.Second
+		client := NewClient("https://api.example.com", "key", WithTimeout(timeout))
+		if client.httpClient.Timeout != timeout {
+			t.Errorf("Expected timeout %v, got %v", timeout, client.httpClient.Timeout)
+		}
+	})
+}
+
+func TestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/test" {
+			t.Errorf("Expected path /test, got %s", r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") != "test-key" {
+			t.Error("Expected X-API-Key header")
+		}
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("Expected User-Agent header")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	var result map[string]string
+	err := client.Get(context.Background(), "/test", &result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result["status"] != "ok" {
+		t.Errorf("Expected status ok, got %s", result["status"])
+	}
+}
+
+func TestClientPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Error("Expected Authorization header with Bearer token")
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "test" {
+			t.Errorf("Expected name test, got %s", body["name"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": "123", "name": "test"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithToken(server.URL, "test-token")
+	var result map[string]string
+	err := client.Post(context.Background(), "/test", map[string]string{"name": "test"}, &result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result["id"] != "123" {
+		t.Errorf("Expected id 123, got %s", result["id"])
+	}
+}
+
+func TestClientDelete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Expected DELETE request, got %s", r.Method)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.Delete(context.Background(), "/test/123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestClientPatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("Expected PATCH request, got %s", r.Method)
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "updated" {
+			t.Errorf("Expected name updated, got %s", body["name"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"id": "123", "name": "updated"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	var result map[string]string
+	err := client.Patch(context.Background(), "/test/123", map[string]string{"name": "updated"}, &result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result["name"] != "updated" {
+		t.Errorf("Expected name updated, got %s", result["name"])
+	}
+}
+
+func TestClientErrorHandling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIError{
+			Message: "Resource not found",
+			Code:    "NOT_FOUND",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.Get(context.Background(), "/test", nil)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected APIError, got %T", err)
+	}
+
+	if apiErr.StatusCode != 404 {
+		t.Errorf("Expected status code 404, got %d", apiErr.StatusCode)
+	}
+
+	if apiErr.Message != "Resource not found" {
+		t.Errorf("Expected message 'Resource not found', got %s", apiErr.Message)
+	}
+
+	if !IsNotFound(err) {
+		t.Error("Expected IsNotFound to return true")
+	}
+}
+
+func TestClientUnauthorizedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(APIError{
+			Message: "Invalid credentials",
+			Code:    "UNAUTHORIZED",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "invalid-key")
+	err := client.Get(context.Background(), "/test", nil)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if !IsUnauthorized(err) {
+		t.Error("Expected IsUnauthorized to return true")
+	}
+}
+
+func TestClientForbiddenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(APIError{
+			Message: "Access denied",
+			Code:    "FORBIDDEN",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.Get(context.Background(), "/test", nil)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if !IsForbidden(err) {
+		t.Error("Expected IsForbidden to return true")
+	}
+}
+
+func TestClientConflictError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(APIError{
+			Message: "Sandbox is already being deleted",
+			Code:    CodeAlreadyDeleting,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.DeleteSandbox(context.Background(), "sbx-1")
+	if !IsConflict(err) {
+		t.Fatalf("Expected IsConflict to return true, got %v", err)
+	}
+	if code := ConflictCode(err); code != CodeAlreadyDeleting {
+		t.Errorf("ConflictCode() = %q, want %q", code, CodeAlreadyDeleting)
+	}
+	if code := ConflictCode(&APIError{StatusCode: 400, Code: CodeAlreadyDeleting}); code != "" {
+		t.Errorf("ConflictCode(400) = %q, want empty", code)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.Get(context.Background(), "/test", nil)
+	if got := RetryAfter(fmt.Errorf("failed to get status: %w", err)); got != 7*time.Second {
+		t.Errorf("RetryAfter() = %s, want 7s", got)
+	}
+	if got := RetryAfter(errors.New("boom")); got != 0 {
+		t.Errorf("RetryAfter(other error) = %s, want 0", got)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-1":                            0,
+		"soon":                          0,
+		"Fri, 16 Oct 2026 12:00:30 GMT": 30 * time.Second,
+		"Fri, 16 Oct 2026 11:59:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestClientWithHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Gateway-Token"); got != "gw-secret" {
+			t.Errorf("X-Gateway-Token = %q, want gw-secret", got)
+		}
+		if r.Header.Get("X-API-Key") != "test-key" {
+			t.Error("Expected X-API-Key header alongside extra headers")
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", WithHeaders(map[string]string{"X-Gateway-Token": "gw-secret"}))
+	if err := client.Get(context.Background(), "/test", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", WithTimeout(50*time.Millisecond))
+	err := client.Get(context.Background(), "/slow", nil)
+	if !IsTimeout(err) {
+		t.Fatalf("expected the client timeout to apply, got %v", err)
+	}
+
+	// The caller's own deadline is not reported as the client timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Get(ctx, "/slow", nil); err == nil || IsTimeout(err) {
+		t.Errorf("expected a context error, got %v", err)
+	}
+
+	// Listings get longer than the default timeout
+	var list SandboxList
+	if err := client.Get(client.withMinimumTimeout(context.Background(), 5*time.Second), "/slow", &list); err != nil {
+		t.Errorf("Get() with a minimum timeout error = %v", err)
+	}
+
+	if err := client.Get(WithRequestTimeout(context.Background(), 5*time.Second), "/slow", nil); err != nil {
+		t.Fatalf("Get() with a longer request timeout error = %v", err)
+	}
+	if client.httpClient.Timeout != 50*time.Millisecond {
+		t.Errorf("client timeout changed to %v", client.httpClient.Timeout)
+	}
+}
+
+func TestClientGetStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not_found","message":"no such log"}`))
+			return
+		}
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// Streams outlive the client timeout
+	client := NewClient(server.URL, "test-key", WithTimeout(75*time.Millisecond))
+	body, err := client.GetStream(context.Background(), "/logs")
+	if err != nil {
+		t.Fatalf("GetStream() error = %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	if string(data) != "line 0\nline 1\nline 2\n" {
+		t.Errorf("stream = %q", data)
+	}
+
+	if _, err := client.GetStream(context.Background(), "/missing"); !IsNotFound(err) {
+		t.Errorf("GetStream() error = %v, want not found", err)
+	}
+}
+
+func TestClientRequestCompression(t *testing.T) {
+	var gotEncoding string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if gotEncoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("invalid gzip body: %v", err)
+			}
+			body = zr
+		}
+		gotBody = nil
+		json.NewDecoder(body).Decode(&gotBody)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", WithRequestCompression(64))
+
+	if err := client.Post(context.Background(), "/small", map[string]string{"a": "b"}, nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if gotEncoding != "" || gotBody["a"] != "b" {
+		t.Errorf("small body: encoding %q, body %v; want uncompressed", gotEncoding, gotBody)
+	}
+
+	large := map[string]string{"manifest": strings.Repeat("x", 1000)}
+	if err := client.Patch(context.Background(), "/large", large, nil); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if gotEncoding != "gzip" || gotBody["manifest"] != large["manifest"] {
+		t.Errorf("large body: encoding %q; want gzip that decodes to the original", gotEncoding)
+	}
+}
+
+func TestClientDecompressesResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Error("expected the client to accept gzip responses")
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"name":"compressed"}`))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	var result struct{ Name string }
+	if err := NewClient(server.URL, "test-key").Get(context.Background(), "/test", &result); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if result.Name != "compressed" {
+		t.Errorf("Name = %q, want compressed", result.Name)
+	}
+}
+
+func TestClientUnixSocket(t *testing.T) {
+	// Keep the path short; unix socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "cvps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "api.sock")
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-1" {
+			t.Errorf("path = %s, want /sandboxes/sbx-1", r.URL.Path)
+		}
+		w.Write([]byte(`{"id":"sbx-1"}`))
+	})}
+	go server.Serve(ln)
+	defer server.Close()
+
+	client := NewClient("unix://"+socket, "test-key")
+	sandbox, err := client.GetSandbox(context.Background(), "sbx-1")
+	if err != nil {
+		t.Fatalf("GetSandbox() over unix socket error = %v", err)
+	}
+	if sandbox.ID != "sbx-1" {
+		t.Errorf("ID = %q, want sbx-1", sandbox.ID)
+	}
+}
+
+func TestClientWithDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var dialed string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	client := NewClient("http://api.internal.test", "test-key", WithDialer(dial))
+	if err := client.Get(context.Background(), "/test", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if dialed != "api.internal.test:80" {
+		t.Errorf("dialed %q, want the base URL host", dialed)
+	}
+}
+
+func TestClientReadOnly(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", WithReadOnly(), WithRequestCompression(1))
+	ctx := context.Background()
+	if err := client.Get(ctx, "/sandboxes", nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := client.Post(ctx, "/sandboxes/validate", map[string]int{"cpu_cores": 2}, nil); err != nil {
+		t.Fatalf("Post validate: %v", err)
+	}
+
+	err := client.Post(ctx, "/sandboxes", map[string]string{"name": "web"}, nil)
+	var readOnlyErr *ReadOnlyError
+	if !errors.As(err, &readOnlyErr) {
+		t.Fatalf("Post error = %v, want *ReadOnlyError", err)
+	}
+	if readOnlyErr.Method != "POST" || readOnlyErr.Path != "/sandboxes" || string(readOnlyErr.Body) != `{"name":"web"}` {
+		t.Errorf("ReadOnlyError = %+v, body %s", readOnlyErr, readOnlyErr.Body)
+	}
+	if err := client.Delete(ctx, "/sandboxes/sbx-1"); !IsReadOnly(err) {
+		t.Errorf("Delete error = %v, want read-only", err)
+	}
+	if err := client.Post(ctx, "/sandboxes/batch", &batchRequest{Op: BatchStatus, IDs: []string{"sbx-1"}}, nil); err != nil {
+		t.Errorf("Post status batch: %v", err)
+	}
+	if err := client.Post(ctx, "/sandboxes/batch", &batchRequest{Op: BatchDelete, IDs: []string{"sbx-1"}}, nil); !IsReadOnly(err) {
+		t.Errorf("Post delete batch error = %v, want read-only", err)
+	}
+
+	want := []string{"GET /sandboxes", "POST /sandboxes/validate", "POST /sandboxes/batch"}
+	if strings.Join(sent, ",") != strings.Join(want, ",") {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
@@ -0,0 +1,29 @@
+// Package claudevps is a Go client for the ClaudeVPS API. It is the client
+// the cvps CLI itself uses, so tools can provision and manage sandboxes
+// without shelling out to the CLI.
+//
+// Create a client with an API key or an OAuth access token and call its
+// methods:
+//
+//	client := claudevps.NewClient("https://api.claudevps.com", os.Getenv("CVPS_API_KEY"))
+//
+//	sandbox, err := client.CreateSandbox(ctx, &claudevps.CreateSandboxRequest{Name: "ci-runner"})
+//	if err != nil {
+//		return err
+//	}
+//	status, err := client.GetSandboxStatus(ctx, sandbox.ID)
+//
+// Errors returned for API responses are *APIError; use IsNotFound,
+// IsUnauthorized and IsForbidden to check for common cases. Options such as
+// WithTimeout, WithHeaders and WithUserAgent configure the client.
+//
+// Requests give up after the client timeout, 30 seconds by default, with a
+// *TimeoutError. Streams such as ImageBuildLogs and SandboxEvents, file
+// chunks and sandbox listings are exempt or get longer; bound them with the
+// context instead. WithRequestTimeout overrides the timeout for one call:
+//
+//	ctx = claudevps.WithRequestTimeout(ctx, 5*time.Minute)
+//
+// Exported identifiers follow semantic versioning with the CLI: they are
+// only removed or changed incompatibly in a major release.
+package claudevps
@@ -0,0 +1,135 @@
+package claudevps
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// APIError is returned for non-2xx responses. RequestID identifies the
+// request in support tickets.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	Details    any    `json:"details,omitempty"`
+	RequestID  string `json:"-"`
+	// RetryAfter is how long the server asked to wait before trying again,
+	// from the Retry-After header of a 429 or 503
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	return e.Message
+}
+
+// IsNotFound reports whether err is a 404 from the API
+func IsNotFound(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 404
+	}
+	return false
+}
+
+// IsUnauthorized reports whether err is a 401, e.g. an expired token
+func IsUnauthorized(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 401
+	}
+	return false
+}
+
+// IsForbidden reports whether err is a 403
+func IsForbidden(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 403
+	}
+	return false
+}
+
+// RetryAfter returns how long the server asked to wait before retrying the
+// request that failed with err, or 0 if it did not say
+func RetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// Codes of 409 responses to lifecycle requests that raced with another
+// change to the same sandbox
+const (
+	CodeAlreadyDeleting = "already_deleting"
+	CodeAlreadyRunning  = "already_running"
+	CodeAlreadyStopped  = "already_stopped"
+	// The sandbox is starting or stopping; retry once it settles
+	CodeTransitioning = "transitioning"
+	// Another sandbox already has the requested name
+	CodeNameTaken = "name_taken"
+)
+
+// IsConflict reports whether err is a 409, i.e. the request clashed with the
+// current state of the resource
+func IsConflict(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode == 409
+	}
+	return false
+}
+
+// ConflictCode returns the code of a 409 from the API, or "" if err is not
+// a conflict
+func ConflictCode(err error) string {
+	if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == 409 {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// TimeoutError is returned when the API did not answer within the client
+// timeout. Calls that legitimately take longer can raise it for one request
+// with WithRequestTimeout.
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("no response from the API within %s", e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// IsTimeout reports whether err, or an error it wraps, is a TimeoutError
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+func isNetTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// ReadOnlyError is returned by a client made with WithReadOnly for a request
+// that could change something. The request was not sent.
+type ReadOnlyError struct {
+	Method string
+	Path   string
+	Body   []byte // the JSON request body, if any
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("read-only client: %s %s was not sent", e.Method, e.Path)
+}
+
+// IsReadOnly reports whether err, or an error it wraps, is a ReadOnlyError
+func IsReadOnly(err error) bool {
+	var readOnlyErr *ReadOnlyError
+	return errors.As(err, &readOnlyErr)
+}
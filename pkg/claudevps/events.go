@@ -0,0 +1,53 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+)
+
+// Sandbox event types
+const (
+	SandboxEventStatus  = "status"  // the sandbox changed status
+	SandboxEventDeleted = "deleted" // the sandbox was deleted; the stream ends
+)
+
+// SandboxEvent is a change to a sandbox, as reported by its event stream
+type SandboxEvent struct {
+	Type   string `json:"type"`
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Time   string `json:"time"`
+}
+
+// SandboxEventStream reads the events of a sandbox as they happen
+type SandboxEventStream struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// Next blocks until the next event and returns it. It returns io.EOF when
+// the server ends the stream.
+func (s *SandboxEventStream) Next() (*SandboxEvent, error) {
+	var e SandboxEvent
+	if err := s.dec.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Close ends the stream
+func (s *SandboxEventStream) Close() error {
+	return s.body.Close()
+}
+
+// SandboxEvents streams a sandbox's events as newline-delimited JSON. Older
+// servers without the endpoint answer 404; poll GetSandboxStatus instead.
+func (c *Client) SandboxEvents(ctx context.Context, id string) (*SandboxEventStream, error) {
+	body, err := c.GetStream(ctx, "/sandboxes/"+url.PathEscape(id)+"/events?follow=true")
+	if err != nil {
+		return nil, err
+	}
+	return &SandboxEventStream{body: body, dec: json.NewDecoder(body)}, nil
+}
@@ -0,0 +1,44 @@
+package claudevps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSandboxEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sandboxes/sbx-123/events" || r.URL.Query().Get("follow") != "true" {
+			t.Errorf("Expected GET /sandboxes/sbx-123/events?follow=true, got %s", r.URL.RequestURI())
+		}
+		fmt.Fprintln(w, `{"type":"status","status":"stopping","time":"2024-01-15T10:00:00Z"}`)
+		fmt.Fprintln(w, `{"type":"status","status":"stopped","time":"2024-01-15T10:00:05Z"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	events, err := client.SandboxEvents(context.Background(), "sbx-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer events.Close()
+
+	var statuses []string
+	for {
+		e, err := events.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		statuses = append(statuses, e.Status)
+	}
+	if len(statuses) != 2 || statuses[1] != "stopped" {
+		t.Errorf("Unexpected events: %v", statuses)
+	}
+}
@@ -0,0 +1,46 @@
+package claudevps_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/achronon/cvps/pkg/claudevps"
+)
+
+func Example() {
+	client := claudevps.NewClient("https://api.claudevps.com", os.Getenv("CVPS_API_KEY"),
+		claudevps.WithUserAgent("my-tool/1.0"))
+	ctx := context.Background()
+
+	sandbox, err := client.CreateSandbox(ctx, &claudevps.CreateSandboxRequest{
+		Name:     "ci-runner",
+		CPUCores: 2,
+		MemoryGB: 4,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		status, err := client.GetSandboxStatus(ctx, sandbox.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if status.Status == "running" {
+			fmt.Printf("ssh %s@%s -p %d\n", status.SSHUser, status.SSHHost, status.SSHPort)
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func ExampleIsNotFound() {
+	client := claudevps.NewClient("https://api.claudevps.com", os.Getenv("CVPS_API_KEY"))
+
+	if _, err := client.GetSandbox(context.Background(), "sbx-missing"); claudevps.IsNotFound(err) {
+		fmt.Println("sandbox is gone")
+	}
+}
@@ -0,0 +1,272 @@
+package claudevps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// FileChunkSize is the size of each upload or download request
+const FileChunkSize = 4 * 1024 * 1024
+
+// chunkTimeout is the least time a chunk request gets, so a FileChunkSize
+// chunk still gets through on a slow link (about 300 kbit/s)
+const chunkTimeout = 2 * time.Minute
+
+// maxChunkRetries bounds how often a failed chunk is retried from the server offset
+const maxChunkRetries = 3
+
+// RemoteFile describes a file in a sandbox as reported by the files API
+type RemoteFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Mode    uint32 `json:"mode"`
+	ModTime string `json:"modTime"`
+	IsDir   bool   `json:"isDir"`
+}
+
+// RemoteFileList is a directory listing
+type RemoteFileList struct {
+	Data []RemoteFile `json:"data"`
+}
+
+// StartUploadRequest opens a resumable upload
+type StartUploadRequest struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Mode uint32 `json:"mode,omitempty"`
+}
+
+// UploadSession tracks a resumable upload. Offset is the number of bytes the
+// server has committed.
+type UploadSession struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+}
+
+func filesPath(sandboxID, suffix, p string) string {
+	return "/sandboxes/" + sandboxID + "/files" + suffix + "?path=" + url.QueryEscape(p)
+}
+
+// StatFile returns information about the file or directory at p
+func (c *Client) StatFile(ctx context.Context, sandboxID, p string) (*RemoteFile, error) {
+	var file RemoteFile
+	if err := c.Get(ctx, filesPath(sandboxID, "/stat", p), &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// ListFiles lists the directory at p
+func (c *Client) ListFiles(ctx context.Context, sandboxID, p string) ([]RemoteFile, error) {
+	var list RemoteFileList
+	if err := c.Get(c.withMinimumTimeout(ctx, listTimeout), filesPath(sandboxID, "", p), &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// MakeDir creates a directory and any missing parents
+func (c *Client) MakeDir(ctx context.Context, sandboxID, p string) error {
+	body := map[string]interface{}{"path": p, "parents": true}
+	return c.Post(ctx, "/sandboxes/"+sandboxID+"/files/mkdir", body, nil)
+}
+
+// DeleteFile removes a file or empty directory
+func (c *Client) DeleteFile(ctx context.Context, sandboxID, p string) error {
+	return c.Delete(ctx, filesPath(sandboxID, "", p))
+}
+
+// StartUpload opens an upload session. If an unfinished session exists for the
+// same path and size, the server returns it with its committed offset.
+func (c *Client) StartUpload(ctx context.Context, sandboxID string, req *StartUploadRequest) (*UploadSession, error) {
+	var session UploadSession
+	if err := c.Post(ctx, "/sandboxes/"+sandboxID+"/files/uploads", req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UploadChunk sends data at offset and returns the updated session
+func (c *Client) UploadChunk(ctx context.Context, sandboxID, uploadID string, offset, total int64, data []byte) (*UploadSession, error) {
+	return c.putChunk(ctx, "/sandboxes/"+sandboxID+"/files/uploads/"+uploadID, offset, total, data)
+}
+
+// putChunk sends data at offset of a total-byte upload to path
+func (c *Client) putChunk(ctx context.Context, path string, offset, total int64, data []byte) (*UploadSession, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(data))-1, total))
+
+	resp, err := c.doRaw(c.withMinimumTimeout(ctx, chunkTimeout), "PUT", path, bytes.NewReader(data), header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var session UploadSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &session, nil
+}
+
+// CompleteUpload finishes an upload once every chunk has been sent
+func (c *Client) CompleteUpload(ctx context.Context, sandboxID, uploadID string) (*RemoteFile, error) {
+	var file RemoteFile
+	if err := c.Post(ctx, "/sandboxes/"+sandboxID+"/files/uploads/"+uploadID+"/complete", nil, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// UploadFile uploads size bytes from r to p, resuming from the server offset
+// after interruptions. onProgress, if set, receives the committed byte count.
+func (c *Client) UploadFile(ctx context.Context, sandboxID, p string, r io.ReaderAt, size int64, mode os.FileMode, onProgress func(int64)) error {
+	req := &StartUploadRequest{Path: p, Size: size, Mode: uint32(mode.Perm())}
+	start := func() (*UploadSession, error) {
+		return c.StartUpload(ctx, sandboxID, req)
+	}
+	put := func(session *UploadSession, data []byte) (*UploadSession, error) {
+		return c.UploadChunk(ctx, sandboxID, session.ID, session.Offset, size, data)
+	}
+
+	session, err := uploadChunks(ctx, r, size, start, put, onProgress)
+	if err != nil {
+		return err
+	}
+	if _, err := c.CompleteUpload(ctx, sandboxID, session.ID); err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+	return nil
+}
+
+// uploadChunks sends size bytes from r in FileChunkSize pieces through put,
+// starting and resuming sessions with start. It returns the final session.
+func uploadChunks(ctx context.Context, r io.ReaderAt, size int64, start func() (*UploadSession, error), put func(*UploadSession, []byte) (*UploadSession, error), onProgress func(int64)) (*UploadSession, error) {
+	session, err := start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	buf := make([]byte, FileChunkSize)
+	retries := 0
+	for session.Offset < size {
+		n, err := r.ReadAt(buf, session.Offset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("unexpected end of file at offset %d", session.Offset)
+		}
+
+		next, err := put(session, buf[:n])
+		if err != nil {
+			if ctx.Err() != nil || retries >= maxChunkRetries {
+				return nil, fmt.Errorf("upload failed at offset %d: %w", session.Offset, err)
+			}
+			retries++
+			time.Sleep(time.Duration(retries) * time.Second)
+
+			// Ask the server where to continue from
+			if next, err = start(); err != nil {
+				return nil, fmt.Errorf("failed to resume upload: %w", err)
+			}
+		} else {
+			if next.Offset <= session.Offset {
+				return nil, fmt.Errorf("upload stalled: server did not advance past offset %d", session.Offset)
+			}
+			retries = 0
+		}
+
+		session = next
+		if onProgress != nil {
+			onProgress(session.Offset)
+		}
+	}
+	return session, nil
+}
+
+// DownloadChunk returns up to length bytes of p starting at offset
+func (c *Client) DownloadChunk(ctx context.Context, sandboxID, p string, offset, length int64) ([]byte, error) {
+	return c.downloadRange(ctx, filesPath(sandboxID, "/content", p), offset, length)
+}
+
+// downloadRange fetches up to length bytes of the resource at path from offset
+func (c *Client) downloadRange(ctx context.Context, path string, offset, length int64) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/octet-stream")
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := c.doRaw(c.withMinimumTimeout(ctx, chunkTimeout), "GET", path, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Writing bytes from anywhere else at offset would corrupt the file
+	switch contentRange := resp.Header.Get("Content-Range"); {
+	case resp.StatusCode == http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-", &start); err != nil || start != offset {
+			return nil, fmt.Errorf("%w: asked for offset %d, got Content-Range %q", errRangeIgnored, offset, contentRange)
+		}
+	case offset != 0:
+		// The whole resource, which only lines up from its start
+		return nil, fmt.Errorf("%w: asked for offset %d, got status %d", errRangeIgnored, offset, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, length))
+}
+
+// errRangeIgnored means the server answered a ranged download with other
+// bytes than those asked for; retrying would not help
+var errRangeIgnored = errors.New("server did not return the requested byte range")
+
+// DownloadFile writes p to w starting at offset, so interrupted downloads can
+// resume from the size of the partial local file.
+func (c *Client) DownloadFile(ctx context.Context, sandboxID, p string, w io.WriterAt, offset, size int64, onProgress func(int64)) error {
+	return downloadChunks(ctx, func(offset, length int64) ([]byte, error) {
+		return c.DownloadChunk(ctx, sandboxID, p, offset, length)
+	}, w, offset, size, onProgress)
+}
+
+// downloadChunks copies bytes offset..size from fetch to w in FileChunkSize
+// pieces, retrying failed chunks
+func downloadChunks(ctx context.Context, fetch func(offset, length int64) ([]byte, error), w io.WriterAt, offset, size int64, onProgress func(int64)) error {
+	retries := 0
+	for offset < size {
+		length := min(int64(FileChunkSize), size-offset)
+		data, err := fetch(offset, length)
+		if err != nil {
+			if ctx.Err() != nil || retries >= maxChunkRetries || errors.Is(err, errRangeIgnored) {
+				return fmt.Errorf("download failed at offset %d: %w", offset, err)
+			}
+			retries++
+			time.Sleep(time.Duration(retries) * time.Second)
+			continue
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("unexpected end of file at offset %d", offset)
+		}
+		retries = 0
+
+		if _, err := w.WriteAt(data, offset); err != nil {
+			return err
+		}
+		offset += int64(len(data))
+		if onProgress != nil {
+			onProgress(offset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package claudevps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeFileServer implements the upload and download endpoints in memory
+type fakeFileServer struct {
+	mu       sync.Mutex
+	uploaded []byte
+	offset   int64
+	files    map[string][]byte
+	failOnce map[int64]bool
+	starts   int
+	complete bool
+}
+
+func (f *fakeFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/files/uploads":
+		f.starts++
+		var req StartUploadRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(UploadSession{ID: "up-1", Path: req.Path, Size: req.Size, Offset: f.offset})
+
+	case r.Method == "PUT" && r.URL.Path == "/sandboxes/sbx-1/files/uploads/up-1":
+		var start, end, total int64
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+		if f.failOnce[start] {
+			delete(f.failOnce, start)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if start != f.offset {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.uploaded = append(f.uploaded, data...)
+		f.offset += int64(len(data))
+		json.NewEncoder(w).Encode(UploadSession{ID: "up-1", Size: total, Offset: f.offset})
+
+	case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/files/uploads/up-1/complete":
+		f.complete = true
+		json.NewEncoder(w).Encode(RemoteFile{Path: "/workspace/big.bin", Size: f.offset})
+
+	case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-1/files/content":
+		if r.Header.Get("Accept") != "application/octet-stream" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		content := f.files[r.URL.Query().Get("path")]
+		var start, end int64
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		if end >= int64(len(content)) {
+			end = int64(len(content)) - 1
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start : end+1])
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type bufferWriterAt struct {
+	buf []byte
+}
+
+func (b *bufferWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if need := int(off) + len(p); need > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, need-len(b.buf))...)
+	}
+	copy(b.buf[off:], p)
+	return len(p), nil
+}
+
+func TestUploadFileResumesAfterFailedChunk(t *testing.T) {
+	fake := &fakeFileServer{failOnce: map[int64]bool{FileChunkSize: true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), (2*FileChunkSize+100)/10)
+	client := NewClient(server.URL, "test-key")
+
+	var lastProgress int64
+	err := client.UploadFile(context.Background(), "sbx-1", "/workspace/big.bin",
+		bytes.NewReader(content), int64(len(content)), 0644, func(n int64) { lastProgress = n })
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	if !bytes.Equal(fake.uploaded, content) {
+		t.Fatalf("uploaded %d bytes, want %d identical bytes", len(fake.uploaded), len(content))
+	}
+	if !fake.complete {
+		t.Error("expected upload to be completed")
+	}
+	if fake.starts != 2 {
+		t.Errorf("expected upload session to be re-queried once after failure, got %d starts", fake.starts)
+	}
+	if lastProgress != int64(len(content)) {
+		t.Errorf("last progress = %d, want %d", lastProgress, len(content))
+	}
+}
+
+func TestDownloadFileFromOffset(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefgh", 1000))
+	fake := &fakeFileServer{files: map[string][]byte{"/workspace/log.txt": content}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+
+	// Simulate a partial local file of 100 bytes
+	w := &bufferWriterAt{buf: append([]byte(nil), content[:100]...)}
+	if err := client.DownloadFile(context.Background(), "sbx-1", "/workspace/log.txt", w, 100, int64(len(content)), nil); err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+
+	if !bytes.Equal(w.buf, content) {
+		t.Fatalf("downloaded content mismatch: got %d bytes, want %d", len(w.buf), len(content))
+	}
+}
+
+func TestDownloadFile_RangeIgnored(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefgh", 1000))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy that drops the Range header and sends the whole file
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	w := &bufferWriterAt{buf: append([]byte(nil), content[:100]...)}
+	err := client.DownloadFile(context.Background(), "sbx-1", "/workspace/log.txt", w, 100, int64(len(content)), nil)
+	if !errors.Is(err, errRangeIgnored) {
+		t.Fatalf("DownloadFile() error = %v, want errRangeIgnored", err)
+	}
+	if len(w.buf) != 100 {
+		t.Errorf("wrote %d bytes past the partial file", len(w.buf)-100)
+	}
+}
+
+func TestUploadChunks_Stalled(t *testing.T) {
+	content := []byte(strings.Repeat("x", 100))
+	start := func() (*UploadSession, error) { return &UploadSession{ID: "up-1", Size: 100}, nil }
+	put := func(s *UploadSession, data []byte) (*UploadSession, error) {
+		return &UploadSession{ID: "up-1", Size: 100, Offset: s.Offset}, nil
+	}
+	_, err := uploadChunks(context.Background(), bytes.NewReader(content), 100, start, put, nil)
+	if err == nil || !strings.Contains(err.Error(), "stalled") {
+		t.Fatalf("uploadChunks() error = %v, want a stalled upload", err)
+	}
+}
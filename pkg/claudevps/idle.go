@@ -0,0 +1,94 @@
+package claudevps
+
+import (
+	"context"
+	"time"
+)
+
+// SandboxActivity reports when a sandbox was last used. Timestamps are empty
+// if the activity has not been seen since the sandbox last started.
+type SandboxActivity struct {
+	SandboxID       string `json:"sandboxId"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	LastSSHAt       string `json:"lastSshAt,omitempty"`
+	LastTerminalAt  string `json:"lastTerminalAt,omitempty"`
+	LastCPUActiveAt string `json:"lastCpuActiveAt,omitempty"`
+
+	// Average CPU usage over the last 15 minutes
+	CPUPercent float64 `json:"cpuPercent"`
+
+	// Set while the sandbox is idle
+	IdleSince string `json:"idleSince,omitempty"`
+
+	// When auto-suspend will stop the sandbox if it stays idle; empty if it
+	// is not running, auto-suspend is off, or the sandbox is exempt
+	SuspendAt string `json:"suspendAt,omitempty"`
+	Exempt    bool   `json:"exempt"`
+}
+
+// SandboxActivityList is the response of ListSandboxActivity
+type SandboxActivityList struct {
+	Data []SandboxActivity `json:"data"`
+}
+
+// IdlePolicy controls auto-suspend, which stops running sandboxes that have
+// had no SSH, terminal or CPU activity for SuspendAfterSeconds. Sandboxes
+// carrying ExemptLabel (with any value) are never suspended.
+type IdlePolicy struct {
+	SuspendAfterSeconds int    `json:"suspendAfterSeconds"`
+	ExemptLabel         string `json:"exemptLabel"`
+}
+
+// Enabled reports whether auto-suspend is on
+func (p *IdlePolicy) Enabled() bool {
+	return p.SuspendAfterSeconds > 0
+}
+
+// SuspendAfter returns the idle time after which sandboxes are suspended
+func (p *IdlePolicy) SuspendAfter() time.Duration {
+	return time.Duration(p.SuspendAfterSeconds) * time.Second
+}
+
+// UpdateIdlePolicyRequest changes the fields of the idle policy that are set.
+// A SuspendAfterSeconds of 0 turns auto-suspend off.
+type UpdateIdlePolicyRequest struct {
+	SuspendAfterSeconds *int    `json:"suspendAfterSeconds,omitempty"`
+	ExemptLabel         *string `json:"exemptLabel,omitempty"`
+}
+
+// ListSandboxActivity returns the activity of every sandbox
+func (c *Client) ListSandboxActivity(ctx context.Context) ([]SandboxActivity, error) {
+	var list SandboxActivityList
+	if err := c.Get(ctx, "/activity", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// GetSandboxActivity returns the activity of one sandbox
+func (c *Client) GetSandboxActivity(ctx context.Context, id string) (*SandboxActivity, error) {
+	var activity SandboxActivity
+	if err := c.Get(ctx, "/sandboxes/"+id+"/activity", &activity); err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// GetIdlePolicy returns the account's auto-suspend policy
+func (c *Client) GetIdlePolicy(ctx context.Context) (*IdlePolicy, error) {
+	var policy IdlePolicy
+	if err := c.Get(ctx, "/idle-policy", &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdateIdlePolicy changes the account's auto-suspend policy
+func (c *Client) UpdateIdlePolicy(ctx context.Context, req *UpdateIdlePolicyRequest) (*IdlePolicy, error) {
+	var policy IdlePolicy
+	if err := c.Patch(ctx, "/idle-policy", req, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
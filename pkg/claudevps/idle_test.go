@@ -0,0 +1,87 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdlePolicy(t *testing.T) {
+	var patch map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/idle-policy":
+			json.NewEncoder(w).Encode(IdlePolicy{ExemptLabel: "no-suspend"})
+		case r.Method == "PATCH" && r.URL.Path == "/idle-policy":
+			json.NewDecoder(r.Body).Decode(&patch)
+			json.NewEncoder(w).Encode(IdlePolicy{SuspendAfterSeconds: 3600, ExemptLabel: "no-suspend"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	policy, err := client.GetIdlePolicy(ctx)
+	if err != nil {
+		t.Fatalf("GetIdlePolicy() error = %v", err)
+	}
+	if policy.Enabled() {
+		t.Error("expected auto-suspend to be off")
+	}
+
+	after := 3600
+	policy, err = client.UpdateIdlePolicy(ctx, &UpdateIdlePolicyRequest{SuspendAfterSeconds: &after})
+	if err != nil {
+		t.Fatalf("UpdateIdlePolicy() error = %v", err)
+	}
+	if !policy.Enabled() || policy.SuspendAfter() != time.Hour {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+	// Unset fields are left out so the server keeps them
+	if _, ok := patch["exemptLabel"]; ok || patch["suspendAfterSeconds"] != float64(3600) {
+		t.Errorf("Unexpected patch: %v", patch)
+	}
+}
+
+func TestSandboxActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/activity":
+			json.NewEncoder(w).Encode(SandboxActivityList{Data: []SandboxActivity{{SandboxID: "sbx-1"}, {SandboxID: "sbx-2"}}})
+		case "/sandboxes/sbx-1/activity":
+			json.NewEncoder(w).Encode(SandboxActivity{SandboxID: "sbx-1", CPUPercent: 1.5})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	list, err := client.ListSandboxActivity(ctx)
+	if err != nil {
+		t.Fatalf("ListSandboxActivity() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("expected 2 sandboxes, got %d", len(list))
+	}
+
+	activity, err := client.GetSandboxActivity(ctx, "sbx-1")
+	if err != nil {
+		t.Fatalf("GetSandboxActivity() error = %v", err)
+	}
+	if activity.CPUPercent != 1.5 {
+		t.Errorf("Unexpected activity: %+v", activity)
+	}
+}
@@ -0,0 +1,134 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Image build statuses
+const (
+	ImageBuildStatusQueued    = "queued"
+	ImageBuildStatusBuilding  = "building"
+	ImageBuildStatusSucceeded = "succeeded"
+	ImageBuildStatusFailed    = "failed"
+)
+
+// Image is a custom image registered for use with CreateSandboxRequest.Image
+type Image struct {
+	Name      string `json:"name"`
+	Tag       string `json:"tag"`
+	Ref       string `json:"ref"`
+	SizeBytes int64  `json:"sizeBytes,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ImageList is the response of ListImages
+type ImageList struct {
+	Data []Image `json:"data"`
+}
+
+// StartImageContextRequest opens a resumable upload of a gzipped tar build context
+type StartImageContextRequest struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BuildImageRequest starts a server-side image build from either an uploaded
+// context or a git repository
+type BuildImageRequest struct {
+	Name       string            `json:"name"`
+	Tag        string            `json:"tag,omitempty"`
+	Dockerfile string            `json:"dockerfile,omitempty"` // relative to the context root
+	ContextID  string            `json:"contextId,omitempty"`
+	GitURL     string            `json:"gitUrl,omitempty"`
+	GitRef     string            `json:"gitRef,omitempty"`
+	BuildArgs  map[string]string `json:"buildArgs,omitempty"`
+}
+
+// ImageBuild tracks a server-side image build. Image is the reference to
+// pass to CreateSandboxRequest.Image once the build has succeeded.
+type ImageBuild struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Tag       string `json:"tag,omitempty"`
+	Status    string `json:"status"`
+	Image     string `json:"image,omitempty"`
+	Error     string `json:"error,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// Done reports whether the build has finished, successfully or not
+func (b *ImageBuild) Done() bool {
+	return b.Status == ImageBuildStatusSucceeded || b.Status == ImageBuildStatusFailed
+}
+
+// StartImageContextUpload opens a build context upload. If an unfinished upload
+// with the same checksum exists, the server returns it with its committed offset.
+func (c *Client) StartImageContextUpload(ctx context.Context, req *StartImageContextRequest) (*UploadSession, error) {
+	var session UploadSession
+	if err := c.Post(ctx, "/images/contexts", req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UploadImageContextChunk sends context data at offset and returns the updated session
+func (c *Client) UploadImageContextChunk(ctx context.Context, contextID string, offset, total int64, data []byte) (*UploadSession, error) {
+	return c.putChunk(ctx, "/images/contexts/"+contextID, offset, total, data)
+}
+
+// UploadImageContext uploads a gzipped tar build context of req.Size bytes
+// from r, resuming after interruptions, and returns the context ID to pass
+// to BuildImage.
+func (c *Client) UploadImageContext(ctx context.Context, req *StartImageContextRequest, r io.ReaderAt, onProgress func(int64)) (string, error) {
+	start := func() (*UploadSession, error) {
+		return c.StartImageContextUpload(ctx, req)
+	}
+	put := func(session *UploadSession, data []byte) (*UploadSession, error) {
+		return c.UploadImageContextChunk(ctx, session.ID, session.Offset, req.Size, data)
+	}
+
+	session, err := uploadChunks(ctx, r, req.Size, start, put, onProgress)
+	if err != nil {
+		return "", err
+	}
+	if err := c.Post(ctx, "/images/contexts/"+session.ID+"/complete", nil, nil); err != nil {
+		return "", fmt.Errorf("failed to complete context upload: %w", err)
+	}
+	return session.ID, nil
+}
+
+// BuildImage starts an image build
+func (c *Client) BuildImage(ctx context.Context, req *BuildImageRequest) (*ImageBuild, error) {
+	var build ImageBuild
+	if err := c.Post(ctx, "/images/builds", req, &build); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+// GetImageBuild returns an image build by ID
+func (c *Client) GetImageBuild(ctx context.Context, id string) (*ImageBuild, error) {
+	var build ImageBuild
+	if err := c.Get(ctx, "/images/builds/"+url.PathEscape(id), &build); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+// ImageBuildLogs streams the output of a build. The stream ends when the build
+// finishes; the caller must close it.
+func (c *Client) ImageBuildLogs(ctx context.Context, id string) (io.ReadCloser, error) {
+	return c.GetStream(ctx, "/images/builds/"+url.PathEscape(id)+"/logs?follow=true")
+}
+
+// ListImages lists the custom images of the account
+func (c *Client) ListImages(ctx context.Context) ([]Image, error) {
+	var list ImageList
+	if err := c.Get(ctx, "/images", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
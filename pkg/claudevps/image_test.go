@@ -0,0 +1,91 @@
+package claudevps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadContextAndBuildImage(t *testing.T) {
+	content := []byte(strings.Repeat("context", 100))
+	var uploaded []byte
+	completed := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/images/contexts":
+			var req StartImageContextRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(UploadSession{ID: "ctx-1", Size: req.Size, Offset: int64(len(uploaded))})
+		case r.Method == "PUT" && r.URL.Path == "/images/contexts/ctx-1":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, data...)
+			json.NewEncoder(w).Encode(UploadSession{ID: "ctx-1", Offset: int64(len(uploaded))})
+		case r.Method == "POST" && r.URL.Path == "/images/contexts/ctx-1/complete":
+			completed = true
+			w.Write([]byte("{}"))
+		case r.Method == "POST" && r.URL.Path == "/images/builds":
+			var req BuildImageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.ContextID != "ctx-1" || req.Name != "toolchain" || req.BuildArgs["GO_VERSION"] != "1.23" {
+				t.Errorf("Unexpected build request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(ImageBuild{ID: "bld-1", Name: req.Name, Status: ImageBuildStatusQueued})
+		case r.Method == "GET" && r.URL.Path == "/images/builds/bld-1/logs":
+			if r.URL.Query().Get("follow") != "true" {
+				t.Errorf("Expected follow=true, got %q", r.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("Step 1/2 : FROM ubuntu\nStep 2/2 : RUN make\n"))
+		case r.Method == "GET" && r.URL.Path == "/images/builds/bld-1":
+			json.NewEncoder(w).Encode(ImageBuild{ID: "bld-1", Status: ImageBuildStatusSucceeded, Image: "images.claudevps.dev/acme/toolchain:latest"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	contextID, err := client.UploadImageContext(ctx, &StartImageContextRequest{Size: int64(len(content)), SHA256: "abc"}, bytes.NewReader(content), nil)
+	if err != nil {
+		t.Fatalf("UploadImageContext() error = %v", err)
+	}
+	if contextID != "ctx-1" || !completed || !bytes.Equal(uploaded, content) {
+		t.Fatalf("context upload incomplete: id %q, completed %v, %d bytes", contextID, completed, len(uploaded))
+	}
+
+	build, err := client.BuildImage(ctx, &BuildImageRequest{Name: "toolchain", ContextID: contextID, BuildArgs: map[string]string{"GO_VERSION": "1.23"}})
+	if err != nil {
+		t.Fatalf("BuildImage() error = %v", err)
+	}
+	if build.Done() {
+		t.Errorf("queued build should not be done")
+	}
+
+	logs, err := client.ImageBuildLogs(ctx, build.ID)
+	if err != nil {
+		t.Fatalf("ImageBuildLogs() error = %v", err)
+	}
+	out, _ := io.ReadAll(logs)
+	logs.Close()
+	if !strings.Contains(string(out), "Step 2/2") {
+		t.Errorf("Unexpected logs: %q", out)
+	}
+
+	build, err = client.GetImageBuild(ctx, build.ID)
+	if err != nil {
+		t.Fatalf("GetImageBuild() error = %v", err)
+	}
+	if !build.Done() || build.Image == "" {
+		t.Errorf("Unexpected build: %+v", build)
+	}
+}
@@ -0,0 +1,22 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+)
+
+// LogEntry is one line of a sandbox's system log
+type LogEntry struct {
+	Time    string `json:"time"`
+	Source  string `json:"source,omitempty"` // e.g. "boot", "sshd", "agent"
+	Message string `json:"message"`
+}
+
+// GetSandboxLogs returns the last tail lines of a sandbox's system log
+func (c *Client) GetSandboxLogs(ctx context.Context, id string, tail int) ([]LogEntry, error) {
+	var entries []LogEntry
+	if err := c.Get(ctx, fmt.Sprintf("/sandboxes/%s/logs?tail=%d", id, tail), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,32 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSandboxLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/sandboxes/sbx-123/logs" || r.URL.Query().Get("tail") != "50" {
+			t.Errorf("Expected GET /sandboxes/sbx-123/logs?tail=50, got %s %s", r.Method, r.URL.RequestURI())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]LogEntry{
+			{Time: "2024-01-15T10:00:00Z", Source: "boot", Message: "kernel started"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	entries, err := client.GetSandboxLogs(context.Background(), "sbx-123", 50)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "kernel started" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,47 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SandboxMetrics is a point-in-time resource and cost sample for a sandbox
+type SandboxMetrics struct {
+	SandboxID        string  `json:"sandboxId"`
+	CPUPercent       float64 `json:"cpuPercent"`
+	MemoryUsedBytes  int64   `json:"memoryUsedBytes"`
+	MemoryTotalBytes int64   `json:"memoryTotalBytes"`
+	DiskUsedBytes    int64   `json:"diskUsedBytes"`
+	DiskTotalBytes   int64   `json:"diskTotalBytes"`
+	CostToDate       float64 `json:"costToDate"`
+	Currency         string  `json:"currency,omitempty"`
+	SampledAt        string  `json:"sampledAt"`
+}
+
+// GetSandboxMetrics returns current resource usage and cost of a running sandbox
+func (c *Client) GetSandboxMetrics(ctx context.Context, id string) (*SandboxMetrics, error) {
+	var metrics SandboxMetrics
+	if err := c.Get(ctx, "/sandboxes/"+id+"/metrics", &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// MetricsHistory is the series of samples recorded while a sandbox was
+// running, oldest first
+type MetricsHistory struct {
+	SandboxID string           `json:"sandboxId"`
+	Samples   []SandboxMetrics `json:"samples"`
+}
+
+// GetSandboxMetricsHistory returns the samples recorded for a sandbox over the
+// last window
+func (c *Client) GetSandboxMetricsHistory(ctx context.Context, id string, window time.Duration) (*MetricsHistory, error) {
+	var history MetricsHistory
+	path := fmt.Sprintf("/sandboxes/%s/metrics/history?windowSeconds=%d", id, int(window/time.Second))
+	if err := c.Get(ctx, path, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
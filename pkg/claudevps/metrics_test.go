@@ -0,0 +1,63 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetSandboxMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/sandboxes/sbx-123/metrics" {
+			t.Errorf("Expected GET /sandboxes/sbx-123/metrics, got %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SandboxMetrics{
+			SandboxID:       "sbx-123",
+			CPUPercent:      42.5,
+			MemoryUsedBytes: 1 << 30,
+			CostToDate:      1.25,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	metrics, err := client.GetSandboxMetrics(context.Background(), "sbx-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.CPUPercent != 42.5 || metrics.MemoryUsedBytes != 1<<30 || metrics.CostToDate != 1.25 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestGetSandboxMetricsHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/sandboxes/sbx-123/metrics/history" {
+			t.Errorf("Expected GET /sandboxes/sbx-123/metrics/history, got %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("windowSeconds"); got != "604800" {
+			t.Errorf("Expected windowSeconds=604800, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MetricsHistory{
+			SandboxID: "sbx-123",
+			Samples:   []SandboxMetrics{{CPUPercent: 10}, {CPUPercent: 20}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	history, err := client.GetSandboxMetricsHistory(context.Background(), "sbx-123", 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history.Samples) != 2 || history.Samples[1].CPUPercent != 20 {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
@@ -0,0 +1,58 @@
+package claudevps
+
+import (
+	"context"
+	"time"
+)
+
+// SandboxPolicy holds the automation settings of one sandbox. Suspending on
+// disconnect complements the account's idle policy: it stops a sandbox a
+// fixed time after its last terminal, SSH or sync connection ends, however
+// busy its CPU is.
+type SandboxPolicy struct {
+	// 0 when the sandbox is not suspended on disconnect
+	SuspendOnDisconnectSeconds int `json:"suspendOnDisconnectSeconds"`
+
+	// Set while the policy is on and nothing is connected: when the sandbox
+	// will be suspended unless something connects first
+	SuspendAt string `json:"suspendAt,omitempty"`
+}
+
+// SuspendsOnDisconnect reports whether the sandbox is suspended on disconnect
+func (p *SandboxPolicy) SuspendsOnDisconnect() bool {
+	return p != nil && p.SuspendOnDisconnectSeconds > 0
+}
+
+// SuspendOnDisconnect returns how long after the last connection ends the
+// sandbox is suspended
+func (p *SandboxPolicy) SuspendOnDisconnect() time.Duration {
+	return time.Duration(p.SuspendOnDisconnectSeconds) * time.Second
+}
+
+// UpdateSandboxPolicyRequest changes the fields of a sandbox policy that are
+// set. A SuspendOnDisconnectSeconds of 0 turns suspending on disconnect off.
+type UpdateSandboxPolicyRequest struct {
+	SuspendOnDisconnectSeconds *int `json:"suspendOnDisconnectSeconds,omitempty"`
+}
+
+func policyPath(sandboxID string) string {
+	return "/sandboxes/" + sandboxID + "/policy"
+}
+
+// GetSandboxPolicy returns the policy of a sandbox
+func (c *Client) GetSandboxPolicy(ctx context.Context, sandboxID string) (*SandboxPolicy, error) {
+	var policy SandboxPolicy
+	if err := c.Get(ctx, policyPath(sandboxID), &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdateSandboxPolicy changes the policy of a sandbox
+func (c *Client) UpdateSandboxPolicy(ctx context.Context, sandboxID string, req *UpdateSandboxPolicyRequest) (*SandboxPolicy, error) {
+	var policy SandboxPolicy
+	if err := c.Patch(ctx, policyPath(sandboxID), req, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
@@ -0,0 +1,56 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSandboxPolicy(t *testing.T) {
+	var patch map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-1/policy":
+			json.NewEncoder(w).Encode(SandboxPolicy{})
+		case r.Method == "PATCH" && r.URL.Path == "/sandboxes/sbx-1/policy":
+			json.NewDecoder(r.Body).Decode(&patch)
+			json.NewEncoder(w).Encode(SandboxPolicy{SuspendOnDisconnectSeconds: 900})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	policy, err := client.GetSandboxPolicy(ctx, "sbx-1")
+	if err != nil {
+		t.Fatalf("GetSandboxPolicy() error = %v", err)
+	}
+	if policy.SuspendsOnDisconnect() {
+		t.Error("expected suspending on disconnect to be off")
+	}
+
+	seconds := 900
+	policy, err = client.UpdateSandboxPolicy(ctx, "sbx-1", &UpdateSandboxPolicyRequest{SuspendOnDisconnectSeconds: &seconds})
+	if err != nil {
+		t.Fatalf("UpdateSandboxPolicy() error = %v", err)
+	}
+	if !policy.SuspendsOnDisconnect() || policy.SuspendOnDisconnect() != 15*time.Minute {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+	if patch["suspendOnDisconnectSeconds"] != float64(900) {
+		t.Errorf("Unexpected patch: %v", patch)
+	}
+
+	var none *SandboxPolicy
+	if none.SuspendsOnDisconnect() {
+		t.Error("a sandbox without a policy does not suspend on disconnect")
+	}
+}
@@ -0,0 +1,30 @@
+package claudevps
+
+import "context"
+
+// Pricing holds the per-resource rates used to estimate sandbox cost
+type Pricing struct {
+	Currency       string  `json:"currency"`
+	CPUCoreHour    float64 `json:"cpuCoreHour"`
+	MemoryGBHour   float64 `json:"memoryGbHour"`
+	StorageGBMonth float64 `json:"storageGbMonth"`
+}
+
+// HoursPerMonth is the average month length used for monthly estimates
+const HoursPerMonth = 730
+
+// HourlyCost estimates the cost of running a sandbox of the given size for an hour
+func (p *Pricing) HourlyCost(cpuCores, memoryGB, storageGB int) float64 {
+	return float64(cpuCores)*p.CPUCoreHour +
+		float64(memoryGB)*p.MemoryGBHour +
+		float64(storageGB)*p.StorageGBMonth/HoursPerMonth
+}
+
+// GetPricing returns the current resource prices
+func (c *Client) GetPricing(ctx context.Context) (*Pricing, error) {
+	var pricing Pricing
+	if err := c.Get(ctx, "/pricing", &pricing); err != nil {
+		return nil, err
+	}
+	return &pricing, nil
+}
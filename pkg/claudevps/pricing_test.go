@@ -0,0 +1,32 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPricing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/pricing" {
+			t.Errorf("Expected GET /pricing, got %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Pricing{Currency: "USD", CPUCoreHour: 0.01, MemoryGBHour: 0.005, StorageGBMonth: 0.073})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	pricing, err := client.GetPricing(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 2 * 0.01 + 4 * 0.005 + 20 * 0.073 / 730
+	if got := pricing.HourlyCost(2, 4, 20); math.Abs(got-0.042) > 1e-9 {
+		t.Errorf("HourlyCost() = %v, want 0.042", got)
+	}
+}
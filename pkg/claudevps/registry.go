@@ -0,0 +1,51 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// RegistryCredential lets sandboxes pull private images from a registry.
+// Passwords are write-only and never returned by the API.
+type RegistryCredential struct {
+	Server    string `json:"server"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// SetRegistryCredentialRequest creates or replaces the credentials of a registry
+type SetRegistryCredentialRequest struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RegistryCredentialList is the response of ListRegistryCredentials
+type RegistryCredentialList struct {
+	Data []RegistryCredential `json:"data"`
+}
+
+// SetRegistryCredential stores credentials for a registry, replacing existing ones.
+// The server verifies them against the registry before saving.
+func (c *Client) SetRegistryCredential(ctx context.Context, req *SetRegistryCredentialRequest) (*RegistryCredential, error) {
+	var cred RegistryCredential
+	if err := c.Post(ctx, "/registries", req, &cred); err != nil {
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// ListRegistryCredentials lists stored registry credentials without passwords
+func (c *Client) ListRegistryCredentials(ctx context.Context) ([]RegistryCredential, error) {
+	var list RegistryCredentialList
+	if err := c.Get(ctx, "/registries", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// DeleteRegistryCredential removes the credentials of a registry
+func (c *Client) DeleteRegistryCredential(ctx context.Context, server string) error {
+	return c.Delete(ctx, "/registries/"+url.PathEscape(server))
+}
@@ -0,0 +1,60 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryCredentials(t *testing.T) {
+	deleted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/registries":
+			var req SetRegistryCredentialRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Server != "ghcr.io" || req.Username != "octocat" || req.Password != "ghp_token" {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(RegistryCredential{Server: req.Server, Username: req.Username})
+		case r.Method == "GET" && r.URL.Path == "/registries":
+			json.NewEncoder(w).Encode(RegistryCredentialList{Data: []RegistryCredential{{Server: "ghcr.io", Username: "octocat"}}})
+		case r.Method == "DELETE" && r.URL.Path == "/registries/registry.acme.dev:5000":
+			deleted = "registry.acme.dev:5000"
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	cred, err := client.SetRegistryCredential(ctx, &SetRegistryCredentialRequest{Server: "ghcr.io", Username: "octocat", Password: "ghp_token"})
+	if err != nil {
+		t.Fatalf("SetRegistryCredential() error = %v", err)
+	}
+	if cred.Server != "ghcr.io" || cred.Username != "octocat" {
+		t.Errorf("Unexpected credential: %+v", cred)
+	}
+
+	creds, err := client.ListRegistryCredentials(ctx)
+	if err != nil {
+		t.Fatalf("ListRegistryCredentials() error = %v", err)
+	}
+	if len(creds) != 1 || creds[0].Server != "ghcr.io" {
+		t.Errorf("Unexpected credentials: %+v", creds)
+	}
+
+	if err := client.DeleteRegistryCredential(ctx, "registry.acme.dev:5000"); err != nil {
+		t.Fatalf("DeleteRegistryCredential() error = %v", err)
+	}
+	if deleted == "" {
+		t.Error("expected credential to be deleted")
+	}
+}
@@ -0,0 +1,20 @@
+package claudevps
+
+import "context"
+
+// CLIRelease is a published release of the cvps CLI
+type CLIRelease struct {
+	Version    string `json:"version"`
+	URL        string `json:"url,omitempty"`
+	ReleasedAt string `json:"releasedAt,omitempty"`
+}
+
+// LatestCLIRelease returns the newest stable release of the CLI. It does not
+// need credentials.
+func (c *Client) LatestCLIRelease(ctx context.Context) (*CLIRelease, error) {
+	var release CLIRelease
+	if err := c.Get(ctx, "/cli/releases/latest", &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
@@ -0,0 +1,305 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+)
+
+// Sandbox is a cloud development environment
+type Sandbox struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	CPUCores   int    `json:"cpuCores"`
+	MemoryGB   int    `json:"memoryGb"`
+	StorageGB  int    `json:"storageGb"`
+	Image      string `json:"image,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Arch       string `json:"arch,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	LastActive string `json:"lastActiveAt,omitempty"`
+
+	// Free-text note on what the sandbox is for
+	Description string `json:"description,omitempty"`
+
+	// Set when the sandbox failed: StatusReason is a machine-readable code
+	// such as "quota_exceeded" and FailureMessage explains it
+	StatusReason   string `json:"statusReason,omitempty"`
+	FailureMessage string `json:"failureMessage,omitempty"`
+
+	// Set while the sandbox is in the trash
+	DeletedAt string `json:"deletedAt,omitempty"`
+	PurgeAt   string `json:"purgeAt,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Set when a start/stop schedule applies to the sandbox
+	NextScheduledAction *ScheduledAction `json:"nextScheduledAction,omitempty"`
+
+	// Set when a sandbox policy applies, such as suspending on disconnect
+	Policy *SandboxPolicy `json:"policy,omitempty"`
+
+	// Provisioning progress (while creating, and on failure)
+	Provisioning *ProvisioningProgress `json:"provisioning,omitempty"`
+
+	// Connection info (when running)
+	SSHHost string `json:"sshHost,omitempty"`
+	SSHPort int    `json:"sshPort,omitempty"`
+	SSHUser string `json:"sshUser,omitempty"`
+
+	Connectivity struct {
+		SSHDirect         bool `json:"sshDirect"`
+		SSHProxyRequired  bool `json:"sshProxyRequired"`
+		WebsocketTerminal bool `json:"websocketTerminal"`
+	} `json:"connectivity"`
+}
+
+// FailureDetail explains why the sandbox failed, from FailureMessage or the
+// provisioning failure reason. It is empty if the API did not say.
+func (s *Sandbox) FailureDetail() string {
+	if s.FailureMessage != "" {
+		return s.FailureMessage
+	}
+	if s.Provisioning != nil {
+		return s.Provisioning.Reason
+	}
+	return ""
+}
+
+// CPU architectures a sandbox can run on. ARM is not offered in every region.
+const (
+	ArchAMD64 = "amd64"
+	ArchARM64 = "arm64"
+)
+
+// Provisioning stages, in the order a new sandbox goes through them
+const (
+	StageQueued         = "queued"
+	StagePullingImage   = "pulling_image"
+	StageBooting        = "booting"
+	StageConfiguringSSH = "configuring_ssh"
+	// Only for sandboxes created with a Repo
+	StageCloningRepo = "cloning_repo"
+)
+
+// ProvisioningStages lists every provisioning stage in order
+var ProvisioningStages = []string{StageQueued, StagePullingImage, StageBooting, StageConfiguringSSH, StageCloningRepo}
+
+// OptionalStage reports whether a sandbox may skip the stage
+func OptionalStage(stage string) bool {
+	return stage == StageCloningRepo
+}
+
+// Provisioning failure codes
+const (
+	// ProvisioningErrorImagePullAuth means the image registry rejected the
+	// pull, usually because no or wrong registry credentials are stored
+	ProvisioningErrorImagePullAuth = "image_pull_unauthorized"
+)
+
+// ProvisioningProgress reports how far provisioning has got. On failure, Stage
+// is the stage that failed, Reason explains why and Code classifies it.
+type ProvisioningProgress struct {
+	Stage    string `json:"stage"`
+	Progress int    `json:"progress,omitempty"` // percent complete within the stage
+	Reason   string `json:"reason,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// CreateSandboxRequest describes a sandbox to create. Unset resources use
+// server defaults.
+type CreateSandboxRequest struct {
+	Name      string `json:"name"`
+	CPUCores  int    `json:"cpuCores,omitempty"`
+	MemoryGB  int    `json:"memoryGb,omitempty"`
+	StorageGB int    `json:"storageGb,omitempty"`
+	Image     string `json:"image,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Arch      string `json:"arch,omitempty"` // ArchAMD64 or ArchARM64; server default if unset
+
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+
+	// Seed the disk from a snapshot or from another sandbox's disk. Unset
+	// resources are inherited from the source.
+	FromSnapshot string `json:"fromSnapshot,omitempty"`
+	CloneOf      string `json:"cloneOf,omitempty"`
+
+	// Clone a git repository into /workspace while provisioning
+	Repo *RepoSeed `json:"repo,omitempty"`
+}
+
+// RepoSeed is a git repository cloned into a new sandbox's workspace. The
+// credentials are write-only: they are used for the clone and never returned.
+type RepoSeed struct {
+	URL string `json:"url"`
+	// Branch to check out; the repository's default branch if empty
+	Branch string `json:"branch,omitempty"`
+
+	// Private SSH deploy key, for ssh URLs
+	DeployKey string `json:"deployKey,omitempty"`
+	// Access token, for https URLs
+	Token string `json:"token,omitempty"`
+}
+
+// SandboxList is one page of sandboxes
+type SandboxList struct {
+	Data  []Sandbox `json:"data"`
+	Total int       `json:"total"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+}
+
+// CreateSandbox starts provisioning a sandbox and returns it right away;
+// poll GetSandboxStatus until it is running
+func (c *Client) CreateSandbox(ctx context.Context, req *CreateSandboxRequest) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Post(ctx, "/sandboxes", req, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// ValidationIssue is a problem found when validating a request
+type ValidationIssue struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Field == "" {
+		return i.Message
+	}
+	return i.Field + ": " + i.Message
+}
+
+// SandboxValidation is the result of checking a create request against
+// naming rules, allowed sizes and account quota
+type SandboxValidation struct {
+	Valid    bool              `json:"valid"`
+	Errors   []ValidationIssue `json:"errors,omitempty"`
+	Warnings []ValidationIssue `json:"warnings,omitempty"`
+}
+
+// ValidateSandbox checks a create request without creating anything
+func (c *Client) ValidateSandbox(ctx context.Context, req *CreateSandboxRequest) (*SandboxValidation, error) {
+	var result SandboxValidation
+	if err := c.Post(ctx, "/sandboxes/validate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSandboxes returns one page of sandboxes, starting at page 1
+func (c *Client) ListSandboxes(ctx context.Context, page, limit int) (*SandboxList, error) {
+	var list SandboxList
+	path := fmt.Sprintf("/sandboxes?page=%d&limit=%d", page, limit)
+	if err := c.Get(c.withMinimumTimeout(ctx, listTimeout), path, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetSandbox returns a sandbox by ID
+func (c *Client) GetSandbox(ctx context.Context, id string) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Get(ctx, "/sandboxes/"+id, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// GetSandboxStatus returns a sandbox with its current status and
+// provisioning progress
+func (c *Client) GetSandboxStatus(ctx context.Context, id string) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Get(ctx, "/sandboxes/"+id+"/status", &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// DeleteSandbox moves a sandbox to the trash, from which it can be restored until it is purged
+func (c *Client) DeleteSandbox(ctx context.Context, id string) error {
+	return c.Delete(ctx, "/sandboxes/"+id)
+}
+
+// PurgeSandbox deletes a sandbox permanently, skipping the trash
+func (c *Client) PurgeSandbox(ctx context.Context, id string) error {
+	return c.Delete(ctx, "/sandboxes/"+id+"?purge=true")
+}
+
+// ListDeletedSandboxes lists sandboxes in the trash
+func (c *Client) ListDeletedSandboxes(ctx context.Context, page, limit int) (*SandboxList, error) {
+	var list SandboxList
+	path := fmt.Sprintf("/sandboxes?deleted=true&page=%d&limit=%d", page, limit)
+	if err := c.Get(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// RestoreSandbox brings a sandbox back from the trash
+func (c *Client) RestoreSandbox(ctx context.Context, id string) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Post(ctx, "/sandboxes/"+id+"/restore", nil, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// RenameSandbox changes the name of a sandbox. Names are unique per account.
+func (c *Client) RenameSandbox(ctx context.Context, id, name string) (*Sandbox, error) {
+	var sandbox Sandbox
+	body := map[string]string{"name": name}
+	if err := c.Patch(ctx, "/sandboxes/"+id, body, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// SetSandboxDescription replaces the description of a sandbox; an empty
+// description removes it
+func (c *Client) SetSandboxDescription(ctx context.Context, id, description string) (*Sandbox, error) {
+	var sandbox Sandbox
+	body := map[string]string{"description": description}
+	if err := c.Patch(ctx, "/sandboxes/"+id, body, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// StartSandbox boots a stopped sandbox
+func (c *Client) StartSandbox(ctx context.Context, id string) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Post(ctx, "/sandboxes/"+id+"/start", nil, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// StopSandbox shuts a sandbox down, keeping its disk
+func (c *Client) StopSandbox(ctx context.Context, id string) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Post(ctx, "/sandboxes/"+id+"/stop", nil, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
+
+// ResizeSandboxRequest changes the CPU and memory of a sandbox. Unset fields
+// keep their current value.
+type ResizeSandboxRequest struct {
+	CPUCores int `json:"cpuCores,omitempty"`
+	MemoryGB int `json:"memoryGb,omitempty"`
+}
+
+// ResizeSandbox changes the size of a sandbox. A running sandbox is restarted
+// to apply the new size; poll GetSandboxStatus until it is running again.
+func (c *Client) ResizeSandbox(ctx context.Context, id string, req *ResizeSandboxRequest) (*Sandbox, error) {
+	var sandbox Sandbox
+	if err := c.Post(ctx, "/sandboxes/"+id+"/resize", req, &sandbox); err != nil {
+		return nil, err
+	}
+	return &sandbox, nil
+}
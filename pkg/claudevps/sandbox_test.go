@@ -0,0 +1,356 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if r.URL.Path != "/sandboxes" {
+			t.Errorf("Expected path /sandboxes, got %s", r.URL.Path)
+		}
+
+		var req CreateSandboxRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if req.Name != "test-sandbox" {
+			t.Errorf("Expected name test-sandbox, got %s", req.Name)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Sandbox{
+			ID:        "sb-123",
+			Name:      req.Name,
+			Status:    "creating",
+			CPUCores:  req.CPUCores,
+			MemoryGB:  req.MemoryGB,
+			StorageGB: req.StorageGB,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	sandbox, err := client.CreateSandbox(context.Background(), &CreateSandboxRequest{
+		Name:      "test-sandbox",
+		CPUCores:  2,
+		MemoryGB:  4,
+		StorageGB: 20,
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sandbox.ID != "sb-123" {
+		t.Errorf("Expected ID sb-123, got %s", sandbox.ID)
+	}
+
+	if sandbox.Name != "test-sandbox" {
+		t.Errorf("Expected name test-sandbox, got %s", sandbox.Name)
+	}
+
+	if sandbox.Status != "creating" {
+		t.Errorf("Expected status creating, got %s", sandbox.Status)
+	}
+}
+
+func TestListSandboxes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/sandboxes" {
+			t.Errorf("Expected path /sandboxes, got %s", r.URL.Path)
+		}
+
+		page := r.URL.Query().Get("page")
+		limit := r.URL.Query().Get("limit")
+		if page != "1" {
+			t.Errorf("Expected page 1, got %s", page)
+		}
+		if limit != "10" {
+			t.Errorf("Expected limit 10, got %s", limit)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SandboxList{
+			Data: []Sandbox{
+				{ID: "sb-1", Name: "sandbox-1", Status: "running"},
+				{ID: "sb-2", Name: "sandbox-2", Status: "stopped"},
+			},
+			Total: 2,
+			Page:  1,
+			Limit: 10,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	list, err := client.ListSandboxes(context.Background(), 1, 10)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if list.Total != 2 {
+		t.Errorf("Expected total 2, got %d", list.Total)
+	}
+
+	if len(list.Data) != 2 {
+		t.Fatalf("Expected 2 sandboxes, got %d", len(list.Data))
+	}
+
+	if list.Data[0].ID != "sb-1" {
+		t.Errorf("Expected first sandbox ID sb-1, got %s", list.Data[0].ID)
+	}
+}
+
+func TestGetSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/sandboxes/sb-123" {
+			t.Errorf("Expected path /sandboxes/sb-123, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Sandbox{
+			ID:      "sb-123",
+			Name:    "test-sandbox",
+			Status:  "running",
+			SSHHost: "sandbox.example.com",
+			SSHPort: 2222,
+			SSHUser: "ubuntu",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	sandbox, err := client.GetSandbox(context.Background(), "sb-123")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sandbox.ID != "sb-123" {
+		t.Errorf("Expected ID sb-123, got %s", sandbox.ID)
+	}
+
+	if sandbox.Status != "running" {
+		t.Errorf("Expected status running, got %s", sandbox.Status)
+	}
+
+	if sandbox.SSHHost != "sandbox.example.com" {
+		t.Errorf("Expected SSHHost sandbox.example.com, got %s", sandbox.SSHHost)
+	}
+}
+
+func TestGetSandboxStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/sandboxes/sb-123/status" {
+			t.Errorf("Expected path /sandboxes/sb-123/status, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Sandbox{
+			ID:     "sb-123",
+			Name:   "test-sandbox",
+			Status: "running",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	sandbox, err := client.GetSandboxStatus(context.Background(), "sb-123")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sandbox.Status != "running" {
+		t.Errorf("Expected status running, got %s", sandbox.Status)
+	}
+}
+
+func TestDeleteSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Expected DELETE request, got %s", r.Method)
+		}
+		if r.URL.Path != "/sandboxes/sb-123" {
+			t.Errorf("Expected path /sandboxes/sb-123, got %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	err := client.DeleteSandbox(context.Background(), "sb-123")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/sandboxes/validate" {
+			t.Errorf("Expected POST /sandboxes/validate, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var req CreateSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SandboxValidation{
+			Valid:  false,
+			Errors: []ValidationIssue{{Field: "cpuCores", Message: "exceeds plan limit of 4"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	result, err := client.ValidateSandbox(context.Background(), &CreateSandboxRequest{Name: "big", CPUCores: 16})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if got := result.Errors[0].String(); got != "cpuCores: exceeds plan limit of 4" {
+		t.Errorf("Unexpected issue string: %q", got)
+	}
+}
+
+func TestTrashLifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == "/sandboxes/sbx-1":
+			if r.URL.Query().Get("purge") != "true" {
+				t.Error("Expected purge=true")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == "/sandboxes":
+			if r.URL.Query().Get("deleted") != "true" {
+				t.Error("Expected deleted=true")
+			}
+			json.NewEncoder(w).Encode(SandboxList{Data: []Sandbox{{ID: "sbx-2", Name: "old", DeletedAt: "2024-01-15T10:00:00Z"}}})
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-2/restore":
+			json.NewEncoder(w).Encode(Sandbox{ID: "sbx-2", Name: "old", Status: "stopped"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	if err := client.PurgeSandbox(ctx, "sbx-1"); err != nil {
+		t.Fatalf("PurgeSandbox() error: %v", err)
+	}
+
+	list, err := client.ListDeletedSandboxes(ctx, 1, 100)
+	if err != nil {
+		t.Fatalf("ListDeletedSandboxes() error: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].DeletedAt == "" {
+		t.Errorf("Unexpected list: %+v", list.Data)
+	}
+
+	sandbox, err := client.RestoreSandbox(ctx, "sbx-2")
+	if err != nil {
+		t.Fatalf("RestoreSandbox() error: %v", err)
+	}
+	if sandbox.ID != "sbx-2" || sandbox.DeletedAt != "" {
+		t.Errorf("Unexpected sandbox: %+v", sandbox)
+	}
+}
+
+func TestStartStopSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/start":
+			json.NewEncoder(w).Encode(Sandbox{ID: "sbx-1", Status: "starting"})
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/stop":
+			json.NewEncoder(w).Encode(Sandbox{ID: "sbx-1", Status: "stopping"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	started, err := client.StartSandbox(context.Background(), "sbx-1")
+	if err != nil || started.Status != "starting" {
+		t.Fatalf("StartSandbox() = %+v, %v", started, err)
+	}
+	stopped, err := client.StopSandbox(context.Background(), "sbx-1")
+	if err != nil || stopped.Status != "stopping" {
+		t.Fatalf("StopSandbox() = %+v, %v", stopped, err)
+	}
+}
+
+func TestRenameSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/sandboxes/sbx-123" {
+			t.Errorf("Expected PATCH /sandboxes/sbx-123, got %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Sandbox{ID: "sbx-123", Name: body["name"], Region: "eu-west"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	sandbox, err := client.RenameSandbox(context.Background(), "sbx-123", "web")
+	if err != nil {
+		t.Fatalf("RenameSandbox() error = %v", err)
+	}
+	if sandbox.Name != "web" || sandbox.Region != "eu-west" {
+		t.Errorf("Unexpected sandbox: %+v", sandbox)
+	}
+}
+
+func TestResizeSandbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/sandboxes/sbx-123/resize" {
+			t.Errorf("Expected POST /sandboxes/sbx-123/resize, got %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]int
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["cpuCores"]; ok || body["memoryGb"] != 2 {
+			t.Errorf("Unexpected body: %v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Sandbox{ID: "sbx-123", Status: "starting", CPUCores: 4, MemoryGB: body["memoryGb"]})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	sandbox, err := client.ResizeSandbox(context.Background(), "sbx-123", &ResizeSandboxRequest{MemoryGB: 2})
+	if err != nil {
+		t.Fatalf("ResizeSandbox() error = %v", err)
+	}
+	if sandbox.CPUCores != 4 || sandbox.MemoryGB != 2 {
+		t.Errorf("Unexpected sandbox: %+v", sandbox)
+	}
+}
@@ -0,0 +1,74 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// Scheduled actions
+const (
+	ScheduleActionStart = "start"
+	ScheduleActionStop  = "stop"
+)
+
+// Schedule starts or stops a sandbox automatically. Cron is a five-field cron
+// expression evaluated in Timezone, an IANA time zone name.
+type Schedule struct {
+	SandboxID string `json:"sandboxId"`
+	Action    string `json:"action"`
+	Cron      string `json:"cron"`
+	Timezone  string `json:"timezone"`
+	NextRunAt string `json:"nextRunAt,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ScheduledAction is the next action a schedule will take on a sandbox
+type ScheduledAction struct {
+	Action string `json:"action"`
+	At     string `json:"at"`
+}
+
+// SetScheduleRequest creates or replaces the schedule of one action
+type SetScheduleRequest struct {
+	Action   string `json:"action"`
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone"`
+}
+
+// ScheduleList is the response of ListSchedules
+type ScheduleList struct {
+	Data []Schedule `json:"data"`
+}
+
+func schedulesPath(sandboxID string) string {
+	return "/sandboxes/" + sandboxID + "/schedules"
+}
+
+// SetSchedule creates the schedule of req.Action for a sandbox, replacing any
+// existing schedule for the same action
+func (c *Client) SetSchedule(ctx context.Context, sandboxID string, req *SetScheduleRequest) (*Schedule, error) {
+	var schedule Schedule
+	if err := c.Post(ctx, schedulesPath(sandboxID), req, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListSchedules lists the schedules of a sandbox, or of every sandbox if
+// sandboxID is empty
+func (c *Client) ListSchedules(ctx context.Context, sandboxID string) ([]Schedule, error) {
+	path := "/schedules"
+	if sandboxID != "" {
+		path = schedulesPath(sandboxID)
+	}
+	var list ScheduleList
+	if err := c.Get(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// DeleteSchedule removes the schedule of one action from a sandbox
+func (c *Client) DeleteSchedule(ctx context.Context, sandboxID, action string) error {
+	return c.Delete(ctx, schedulesPath(sandboxID)+"/"+url.PathEscape(action))
+}
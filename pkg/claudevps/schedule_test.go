@@ -0,0 +1,59 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/schedules":
+			var req SetScheduleRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Action != ScheduleActionStop || req.Cron != "0 19 * * 1-5" || req.Timezone != "Europe/Berlin" {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(Schedule{SandboxID: "sbx-1", Action: req.Action, Cron: req.Cron, Timezone: req.Timezone})
+		case r.Method == "GET" && r.URL.Path == "/schedules":
+			json.NewEncoder(w).Encode(ScheduleList{Data: []Schedule{{SandboxID: "sbx-1", Action: ScheduleActionStop}, {SandboxID: "sbx-2", Action: ScheduleActionStart}}})
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-1/schedules":
+			json.NewEncoder(w).Encode(ScheduleList{Data: []Schedule{{SandboxID: "sbx-1", Action: ScheduleActionStop}}})
+		case r.Method == "DELETE" && r.URL.Path == "/sandboxes/sbx-1/schedules/stop":
+			deleted = ScheduleActionStop
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	if _, err := client.SetSchedule(ctx, "sbx-1", &SetScheduleRequest{Action: ScheduleActionStop, Cron: "0 19 * * 1-5", Timezone: "Europe/Berlin"}); err != nil {
+		t.Fatalf("SetSchedule() error = %v", err)
+	}
+
+	all, err := client.ListSchedules(ctx, "")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListSchedules(all) = %v, %v", all, err)
+	}
+	one, err := client.ListSchedules(ctx, "sbx-1")
+	if err != nil || len(one) != 1 {
+		t.Fatalf("ListSchedules(sbx-1) = %v, %v", one, err)
+	}
+
+	if err := client.DeleteSchedule(ctx, "sbx-1", ScheduleActionStop); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	if deleted != ScheduleActionStop {
+		t.Error("expected schedule to be deleted")
+	}
+}
@@ -0,0 +1,59 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// Secret mount types. SecretMountNone keeps a secret server-side for use by
+// the platform itself, such as backup target credentials.
+const (
+	SecretMountEnv  = "env"
+	SecretMountFile = "file"
+	SecretMountNone = "none"
+)
+
+// Secret is a stored credential. Values are write-only and never returned by the API.
+type Secret struct {
+	Name      string `json:"name"`
+	Mount     string `json:"mount"`
+	SandboxID string `json:"sandboxId,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// SetSecretRequest creates or replaces a secret
+type SetSecretRequest struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Mount     string `json:"mount,omitempty"`
+	SandboxID string `json:"sandboxId,omitempty"`
+}
+
+// SecretList is the response of ListSecrets
+type SecretList struct {
+	Data []Secret `json:"data"`
+}
+
+// SetSecret creates a secret or replaces the value of an existing one
+func (c *Client) SetSecret(ctx context.Context, req *SetSecretRequest) (*Secret, error) {
+	var secret Secret
+	if err := c.Post(ctx, "/secrets", req, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// ListSecrets lists secrets without their values
+func (c *Client) ListSecrets(ctx context.Context) ([]Secret, error) {
+	var list SecretList
+	if err := c.Get(ctx, "/secrets", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// DeleteSecret removes a secret
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	return c.Delete(ctx, "/secrets/"+url.PathEscape(name))
+}
@@ -0,0 +1,74 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/secrets" {
+			t.Errorf("Expected POST /secrets, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var req SetSecretRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Name != "DATABASE_URL" || req.Value != "postgres://secret" || req.Mount != SecretMountFile {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Secret{Name: req.Name, Mount: req.Mount, CreatedAt: "2024-01-15T10:30:00Z"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	secret, err := client.SetSecret(context.Background(), &SetSecretRequest{
+		Name:  "DATABASE_URL",
+		Value: "postgres://secret",
+		Mount: SecretMountFile,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if secret.Name != "DATABASE_URL" || secret.Mount != SecretMountFile {
+		t.Errorf("Unexpected secret: %+v", secret)
+	}
+}
+
+func TestListAndDeleteSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/secrets":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(SecretList{Data: []Secret{
+				{Name: "API_TOKEN", Mount: SecretMountEnv},
+				{Name: "TLS_KEY", Mount: SecretMountFile, SandboxID: "sbx-abc123"},
+			}})
+		case r.Method == "DELETE" && r.URL.Path == "/secrets/API_TOKEN":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	secrets, err := client.ListSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(secrets) != 2 || secrets[1].SandboxID != "sbx-abc123" {
+		t.Errorf("Unexpected secrets: %+v", secrets)
+	}
+
+	if err := client.DeleteSecret(context.Background(), "API_TOKEN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// Roles a sandbox can be shared with
+const (
+	// ShareRoleWrite gives full terminal and file access
+	ShareRoleWrite = "write"
+	// ShareRoleReadOnly gives a terminal as a user that cannot write to
+	// /workspace, with SFTP limited to reading
+	ShareRoleReadOnly = "read-only"
+)
+
+// ShareRoles lists the roles a sandbox can be shared with
+var ShareRoles = []string{ShareRoleWrite, ShareRoleReadOnly}
+
+// AccessGrant lets another account member connect to a sandbox. Grants with
+// an expiry stop working, and are removed, once it passes.
+type AccessGrant struct {
+	ID        string `json:"id"`
+	SandboxID string `json:"sandboxId"`
+
+	// Email of the account member the sandbox is shared with
+	User string `json:"user"`
+	Role string `json:"role"`
+
+	CreatedAt string `json:"createdAt"`
+	// Empty if the grant never expires
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// ShareSandboxRequest shares a sandbox with an account member. An
+// ExpiresInSeconds of 0 creates a grant that never expires.
+type ShareSandboxRequest struct {
+	User             string `json:"user"`
+	Role             string `json:"role"`
+	ExpiresInSeconds int    `json:"expiresInSeconds,omitempty"`
+}
+
+// AccessGrantList is the response of ListAccessGrants
+type AccessGrantList struct {
+	Data []AccessGrant `json:"data"`
+}
+
+// ShareSandbox grants an account member access to a sandbox. Sharing with
+// someone who already has access replaces their grant.
+func (c *Client) ShareSandbox(ctx context.Context, sandboxID string, req *ShareSandboxRequest) (*AccessGrant, error) {
+	var grant AccessGrant
+	if err := c.Post(ctx, "/sandboxes/"+sandboxID+"/grants", req, &grant); err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// ListAccessGrants lists who a sandbox is shared with
+func (c *Client) ListAccessGrants(ctx context.Context, sandboxID string) ([]AccessGrant, error) {
+	var list AccessGrantList
+	if err := c.Get(ctx, "/sandboxes/"+sandboxID+"/grants", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// RevokeAccessGrant removes a grant; open connections made with it are closed
+func (c *Client) RevokeAccessGrant(ctx context.Context, sandboxID, grantID string) error {
+	return c.Delete(ctx, "/sandboxes/"+sandboxID+"/grants/"+url.PathEscape(grantID))
+}
@@ -0,0 +1,56 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessGrants(t *testing.T) {
+	var revoked string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/grants":
+			var req ShareSandboxRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.User != "dev@example.com" || req.Role != ShareRoleReadOnly || req.ExpiresInSeconds != 28800 {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(AccessGrant{ID: "grant-1", SandboxID: "sbx-1", User: req.User, Role: req.Role})
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-1/grants":
+			json.NewEncoder(w).Encode(AccessGrantList{Data: []AccessGrant{{ID: "grant-1", User: "dev@example.com", Role: ShareRoleReadOnly}}})
+		case r.Method == "DELETE":
+			revoked = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	grant, err := client.ShareSandbox(ctx, "sbx-1", &ShareSandboxRequest{User: "dev@example.com", Role: ShareRoleReadOnly, ExpiresInSeconds: 28800})
+	if err != nil || grant.ID != "grant-1" {
+		t.Fatalf("ShareSandbox() = %+v, %v", grant, err)
+	}
+
+	grants, err := client.ListAccessGrants(ctx, "sbx-1")
+	if err != nil || len(grants) != 1 || grants[0].Role != ShareRoleReadOnly {
+		t.Fatalf("ListAccessGrants() = %+v, %v", grants, err)
+	}
+
+	if err := client.RevokeAccessGrant(ctx, "sbx-1", "grant-1"); err != nil {
+		t.Fatalf("RevokeAccessGrant() error = %v", err)
+	}
+	if revoked != "/sandboxes/sbx-1/grants/grant-1" {
+		t.Errorf("Revoked %q", revoked)
+	}
+}
@@ -0,0 +1,149 @@
+package claudevps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Snapshot statuses
+const (
+	SnapshotStatusPending = "pending"
+	SnapshotStatusReady   = "ready"
+	SnapshotStatusFailed  = "failed"
+)
+
+// Snapshot is a point-in-time copy of a sandbox disk
+type Snapshot struct {
+	ID        string `json:"id"`
+	SandboxID string `json:"sandboxId"`
+	Name      string `json:"name,omitempty"`
+	Status    string `json:"status"`
+	SizeBytes int64  `json:"sizeBytes,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// CreateSnapshotRequest takes a snapshot of a sandbox
+type CreateSnapshotRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// CreateSnapshot starts a snapshot of a sandbox. It is usable once its status is ready.
+func (c *Client) CreateSnapshot(ctx context.Context, sandboxID string, req *CreateSnapshotRequest) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := c.Post(ctx, "/sandboxes/"+sandboxID+"/snapshots", req, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetSnapshot returns a snapshot by ID
+func (c *Client) GetSnapshot(ctx context.Context, id string) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := c.Get(ctx, "/snapshots/"+url.PathEscape(id), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// SnapshotArchive describes a snapshot exported as a downloadable archive.
+// The archive is ready to download once its status is ready.
+type SnapshotArchive struct {
+	SnapshotID string `json:"snapshotId"`
+	Status     string `json:"status"`
+	Format     string `json:"format"`
+	SizeBytes  int64  `json:"sizeBytes,omitempty"`
+	SHA256     string `json:"sha256,omitempty"`
+}
+
+// ImportSnapshotRequest opens a resumable snapshot import
+type ImportSnapshotRequest struct {
+	Name   string `json:"name,omitempty"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func snapshotPath(id string) string {
+	return "/snapshots/" + url.PathEscape(id)
+}
+
+// ExportSnapshot starts exporting a ready snapshot as an archive, or returns
+// the existing export
+func (c *Client) ExportSnapshot(ctx context.Context, id string) (*SnapshotArchive, error) {
+	var archive SnapshotArchive
+	if err := c.Post(ctx, snapshotPath(id)+"/export", nil, &archive); err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// GetSnapshotExport returns the export of a snapshot
+func (c *Client) GetSnapshotExport(ctx context.Context, id string) (*SnapshotArchive, error) {
+	var archive SnapshotArchive
+	if err := c.Get(ctx, snapshotPath(id)+"/export", &archive); err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// DownloadSnapshotChunk returns up to length bytes of a snapshot archive
+// starting at offset
+func (c *Client) DownloadSnapshotChunk(ctx context.Context, id string, offset, length int64) ([]byte, error) {
+	return c.downloadRange(ctx, snapshotPath(id)+"/export/content", offset, length)
+}
+
+// DownloadSnapshotArchive writes the archive of snapshot id to w starting at
+// offset, so interrupted downloads can resume from a partial local file.
+func (c *Client) DownloadSnapshotArchive(ctx context.Context, id string, w io.WriterAt, offset, size int64, onProgress func(int64)) error {
+	return downloadChunks(ctx, func(offset, length int64) ([]byte, error) {
+		return c.DownloadSnapshotChunk(ctx, id, offset, length)
+	}, w, offset, size, onProgress)
+}
+
+// StartSnapshotImport opens an import session. If an unfinished import of an
+// archive with the same checksum exists, the server returns it with its
+// committed offset.
+func (c *Client) StartSnapshotImport(ctx context.Context, req *ImportSnapshotRequest) (*UploadSession, error) {
+	var session UploadSession
+	if err := c.Post(ctx, "/snapshots/imports", req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UploadSnapshotChunk sends archive data at offset and returns the updated session
+func (c *Client) UploadSnapshotChunk(ctx context.Context, importID string, offset, total int64, data []byte) (*UploadSession, error) {
+	return c.putChunk(ctx, "/snapshots/imports/"+importID, offset, total, data)
+}
+
+// CompleteSnapshotImport finishes an import once the whole archive has been
+// sent. The returned snapshot is pending until the archive has been restored.
+func (c *Client) CompleteSnapshotImport(ctx context.Context, importID string) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := c.Post(ctx, "/snapshots/imports/"+importID+"/complete", nil, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ImportSnapshot uploads a snapshot archive of req.Size bytes from r, resuming
+// from the server offset after interruptions, and returns the new snapshot.
+func (c *Client) ImportSnapshot(ctx context.Context, req *ImportSnapshotRequest, r io.ReaderAt, onProgress func(int64)) (*Snapshot, error) {
+	start := func() (*UploadSession, error) {
+		return c.StartSnapshotImport(ctx, req)
+	}
+	put := func(session *UploadSession, data []byte) (*UploadSession, error) {
+		return c.UploadSnapshotChunk(ctx, session.ID, session.Offset, req.Size, data)
+	}
+
+	session, err := uploadChunks(ctx, r, req.Size, start, put, onProgress)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := c.CompleteSnapshotImport(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete import: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,141 @@
+package claudevps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndGetSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-123/snapshots":
+			var req CreateSnapshotRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Name != "final" {
+				t.Errorf("Expected name final, got %q", req.Name)
+			}
+			json.NewEncoder(w).Encode(Snapshot{ID: "snap-1", SandboxID: "sbx-123", Name: req.Name, Status: SnapshotStatusPending})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1":
+			json.NewEncoder(w).Encode(Snapshot{ID: "snap-1", SandboxID: "sbx-123", Status: SnapshotStatusReady})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	snapshot, err := client.CreateSnapshot(context.Background(), "sbx-123", &CreateSnapshotRequest{Name: "final"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if snapshot.ID != "snap-1" || snapshot.Status != SnapshotStatusPending {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	snapshot, err = client.GetSnapshot(context.Background(), "snap-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if snapshot.Status != SnapshotStatusReady {
+		t.Errorf("Expected ready, got %s", snapshot.Status)
+	}
+}
+
+func TestExportAndDownloadSnapshotArchive(t *testing.T) {
+	content := []byte(strings.Repeat("snapshot", 1000))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/snapshots/snap-1/export":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(SnapshotArchive{SnapshotID: "snap-1", Status: SnapshotStatusReady, SizeBytes: int64(len(content))})
+		case r.Method == "GET" && r.URL.Path == "/snapshots/snap-1/export/content":
+			var start, end int64
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+			if end >= int64(len(content)) {
+				end = int64(len(content)) - 1
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[start : end+1])
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	archive, err := client.ExportSnapshot(context.Background(), "snap-1")
+	if err != nil {
+		t.Fatalf("ExportSnapshot() error = %v", err)
+	}
+	if archive.SizeBytes != int64(len(content)) {
+		t.Fatalf("SizeBytes = %d, want %d", archive.SizeBytes, len(content))
+	}
+
+	w := &bufferWriterAt{buf: append([]byte(nil), content[:500]...)}
+	if err := client.DownloadSnapshotArchive(context.Background(), "snap-1", w, 500, archive.SizeBytes, nil); err != nil {
+		t.Fatalf("DownloadSnapshotArchive() error = %v", err)
+	}
+	if !bytes.Equal(w.buf, content) {
+		t.Fatalf("downloaded content mismatch: got %d bytes, want %d", len(w.buf), len(content))
+	}
+}
+
+func TestImportSnapshotResumesFromServerOffset(t *testing.T) {
+	content := []byte(strings.Repeat("archive!", 1000))
+	uploaded := append([]byte(nil), content[:800]...)
+	completed := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/snapshots/imports":
+			var req ImportSnapshotRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.SHA256 != "abc" || req.Size != int64(len(content)) {
+				t.Errorf("Unexpected import request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(UploadSession{ID: "imp-1", Size: req.Size, Offset: int64(len(uploaded))})
+		case r.Method == "PUT" && r.URL.Path == "/snapshots/imports/imp-1":
+			var start, end, total int64
+			fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+			if start != int64(len(uploaded)) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, data...)
+			json.NewEncoder(w).Encode(UploadSession{ID: "imp-1", Size: total, Offset: int64(len(uploaded))})
+		case r.Method == "POST" && r.URL.Path == "/snapshots/imports/imp-1/complete":
+			completed = true
+			json.NewEncoder(w).Encode(Snapshot{ID: "snap-9", Name: "imported", Status: SnapshotStatusPending})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	req := &ImportSnapshotRequest{Name: "imported", Size: int64(len(content)), SHA256: "abc"}
+	snapshot, err := client.ImportSnapshot(context.Background(), req, bytes.NewReader(content), nil)
+	if err != nil {
+		t.Fatalf("ImportSnapshot() error = %v", err)
+	}
+	if snapshot.ID != "snap-9" || !completed {
+		t.Errorf("Unexpected snapshot %+v (completed %v)", snapshot, completed)
+	}
+	if !bytes.Equal(uploaded, content) {
+		t.Fatalf("uploaded %d bytes, want %d identical bytes", len(uploaded), len(content))
+	}
+}
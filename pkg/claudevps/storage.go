@@ -0,0 +1,75 @@
+package claudevps
+
+import "context"
+
+// Storage expansion statuses
+const (
+	StorageExpansionPending   = "pending"
+	StorageExpansionResizing  = "resizing"
+	StorageExpansionCompleted = "completed"
+	StorageExpansionFailed    = "failed"
+)
+
+// ExpandStorageRequest grows a sandbox disk to StorageGB. Disks cannot shrink.
+type ExpandStorageRequest struct {
+	StorageGB int `json:"storageGb"`
+}
+
+// StorageExpansionCheck is the result of checking a storage expansion
+// against allowed sizes and account quota
+type StorageExpansionCheck struct {
+	SandboxValidation
+
+	// Online is true if the disk can grow while the sandbox runs; otherwise
+	// a running sandbox is restarted to apply the new size
+	Online bool `json:"online"`
+}
+
+// StorageExpansion tracks a sandbox disk being grown
+type StorageExpansion struct {
+	ID        string `json:"id"`
+	SandboxID string `json:"sandboxId"`
+	Status    string `json:"status"`
+	FromGB    int    `json:"fromGb"`
+	ToGB      int    `json:"toGb"`
+	Online    bool   `json:"online"`
+	Progress  int    `json:"progress,omitempty"` // percent complete
+	Error     string `json:"error,omitempty"`
+}
+
+// Done reports whether the expansion has finished, successfully or not
+func (e *StorageExpansion) Done() bool {
+	return e.Status == StorageExpansionCompleted || e.Status == StorageExpansionFailed
+}
+
+func storagePath(sandboxID string) string {
+	return "/sandboxes/" + sandboxID + "/storage"
+}
+
+// ValidateStorageExpansion checks an expansion without starting it
+func (c *Client) ValidateStorageExpansion(ctx context.Context, sandboxID string, req *ExpandStorageRequest) (*StorageExpansionCheck, error) {
+	var result StorageExpansionCheck
+	if err := c.Post(ctx, storagePath(sandboxID)+"/validate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExpandStorage starts growing a sandbox disk and returns right away; poll
+// GetStorageExpansion until it is done
+func (c *Client) ExpandStorage(ctx context.Context, sandboxID string, req *ExpandStorageRequest) (*StorageExpansion, error) {
+	var expansion StorageExpansion
+	if err := c.Post(ctx, storagePath(sandboxID)+"/expansions", req, &expansion); err != nil {
+		return nil, err
+	}
+	return &expansion, nil
+}
+
+// GetStorageExpansion returns the progress of a storage expansion
+func (c *Client) GetStorageExpansion(ctx context.Context, sandboxID, id string) (*StorageExpansion, error) {
+	var expansion StorageExpansion
+	if err := c.Get(ctx, storagePath(sandboxID)+"/expansions/"+id, &expansion); err != nil {
+		return nil, err
+	}
+	return &expansion, nil
+}
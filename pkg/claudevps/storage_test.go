@@ -0,0 +1,57 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStorageExpansion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/storage/validate":
+			w.Write([]byte(`{"valid":false,"online":true,"errors":[{"field":"storageGb","message":"exceeds quota"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/sandboxes/sbx-1/storage/expansions":
+			var req ExpandStorageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(StorageExpansion{ID: "exp-1", Status: StorageExpansionPending, FromGB: 20, ToGB: req.StorageGB})
+		case r.Method == "GET" && r.URL.Path == "/sandboxes/sbx-1/storage/expansions/exp-1":
+			json.NewEncoder(w).Encode(StorageExpansion{ID: "exp-1", Status: StorageExpansionCompleted, ToGB: 40})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+	req := &ExpandStorageRequest{StorageGB: 40}
+
+	check, err := client.ValidateStorageExpansion(ctx, "sbx-1", req)
+	if err != nil {
+		t.Fatalf("ValidateStorageExpansion() error = %v", err)
+	}
+	if check.Valid || !check.Online || len(check.Errors) != 1 || check.Errors[0].Field != "storageGb" {
+		t.Errorf("Unexpected check: %+v", check)
+	}
+
+	expansion, err := client.ExpandStorage(ctx, "sbx-1", req)
+	if err != nil {
+		t.Fatalf("ExpandStorage() error = %v", err)
+	}
+	if expansion.Done() || expansion.ToGB != 40 {
+		t.Errorf("Unexpected expansion: %+v", expansion)
+	}
+
+	expansion, err = client.GetStorageExpansion(ctx, "sbx-1", "exp-1")
+	if err != nil {
+		t.Fatalf("GetStorageExpansion() error = %v", err)
+	}
+	if !expansion.Done() {
+		t.Errorf("expected expansion to be done: %+v", expansion)
+	}
+}
@@ -1,12 +1,15 @@
-package api
+package claudevps
 
 import "context"
 
+// TerminalWebSocketInfo is where to open an interactive terminal over WebSocket
 type TerminalWebSocketInfo struct {
 	URL   string `json:"url"`
 	Token string `json:"token"`
 }
 
+// GetTerminalWebSocket returns the WebSocket URL and token for a terminal on
+// the sandbox
 func (c *Client) GetTerminalWebSocket(ctx context.Context, sandboxID string) (*TerminalWebSocketInfo, error) {
 	var info TerminalWebSocketInfo
 	if err := c.Post(ctx, "/sandboxes/"+sandboxID+"/terminal", nil, &info); err != nil {
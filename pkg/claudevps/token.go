@@ -0,0 +1,75 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// TokenScopes lists the scopes an API token can be limited to. A write scope
+// also allows reading the same resource.
+var TokenScopes = []string{
+	"sandboxes:read", "sandboxes:write",
+	"snapshots:read", "snapshots:write",
+	"secrets:read", "secrets:write",
+	"webhooks:read", "webhooks:write",
+	"billing:read",
+}
+
+// APIToken is a least-privilege credential for automation. It authenticates
+// like an API key but can only do what its scopes allow.
+type APIToken struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Set if the token only reaches one sandbox and its resources
+	Sandbox string `json:"sandbox,omitempty"`
+
+	// First characters of the token, to tell tokens apart
+	Prefix string `json:"prefix"`
+
+	CreatedAt string `json:"createdAt"`
+	// Empty if the token never expires
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	LastUsedAt string `json:"lastUsedAt,omitempty"`
+
+	// Token is only returned when the token is created
+	Token string `json:"token,omitempty"`
+}
+
+// CreateTokenRequest creates an API token. An ExpiresInSeconds of 0 creates
+// a token that never expires.
+type CreateTokenRequest struct {
+	Name             string   `json:"name,omitempty"`
+	Scopes           []string `json:"scopes"`
+	ExpiresInSeconds int      `json:"expiresInSeconds,omitempty"`
+	// Sandbox limits the token to one sandbox and its resources
+	Sandbox string `json:"sandbox,omitempty"`
+}
+
+// TokenList is the response of ListTokens
+type TokenList struct {
+	Data []APIToken `json:"data"`
+}
+
+// CreateToken creates an API token. The token itself is only returned here.
+func (c *Client) CreateToken(ctx context.Context, req *CreateTokenRequest) (*APIToken, error) {
+	var token APIToken
+	if err := c.Post(ctx, "/tokens", req, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListTokens lists the account's API tokens
+func (c *Client) ListTokens(ctx context.Context) ([]APIToken, error) {
+	var list TokenList
+	if err := c.Get(ctx, "/tokens", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// RevokeToken revokes an API token; requests made with it fail from then on
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	return c.Delete(ctx, "/tokens/"+url.PathEscape(id))
+}
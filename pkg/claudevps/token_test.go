@@ -0,0 +1,62 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	var revoked string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/tokens":
+			var req CreateTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Name != "ci" || len(req.Scopes) != 1 || req.ExpiresInSeconds != 86400 {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(APIToken{ID: "tok-1", Name: req.Name, Scopes: req.Scopes, Prefix: "cvps_tok_ab", Token: "cvps_tok_abcdef"})
+		case r.Method == "GET" && r.URL.Path == "/tokens":
+			json.NewEncoder(w).Encode(TokenList{Data: []APIToken{{ID: "tok-1", Name: "ci", Scopes: []string{"sandboxes:read"}}}})
+		case r.Method == "DELETE":
+			revoked = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	ctx := context.Background()
+
+	token, err := client.CreateToken(ctx, &CreateTokenRequest{Name: "ci", Scopes: []string{"sandboxes:read"}, ExpiresInSeconds: 86400})
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if token.Token != "cvps_tok_abcdef" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+
+	tokens, err := client.ListTokens(ctx)
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Token != "" {
+		t.Errorf("Unexpected tokens: %+v", tokens)
+	}
+
+	if err := client.RevokeToken(ctx, "tok-1"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if revoked != "/tokens/tok-1" {
+		t.Errorf("Expected DELETE /tokens/tok-1, got %s", revoked)
+	}
+}
@@ -0,0 +1,84 @@
+package claudevps
+
+import (
+	"context"
+	"net/url"
+)
+
+// Sandbox lifecycle events that webhooks can subscribe to
+const (
+	WebhookEventCreated = "sandbox.created"
+	WebhookEventFailed  = "sandbox.failed"
+	WebhookEventDeleted = "sandbox.deleted"
+	WebhookEventIdle    = "sandbox.idle"
+)
+
+// WebhookEvents lists every supported event
+var WebhookEvents = []string{WebhookEventCreated, WebhookEventFailed, WebhookEventDeleted, WebhookEventIdle}
+
+// Webhook is called when sandboxes change state
+type Webhook struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
+
+	// SigningSecret is only returned when the webhook is created
+	SigningSecret string `json:"signingSecret,omitempty"`
+}
+
+// CreateWebhookRequest registers a webhook. No events means all of them.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+}
+
+// WebhookList is the response of ListWebhooks
+type WebhookList struct {
+	Data []Webhook `json:"data"`
+}
+
+// WebhookDelivery is the result of sending an event to a webhook endpoint
+type WebhookDelivery struct {
+	ID         string `json:"id"`
+	Event      string `json:"event"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int    `json:"durationMs,omitempty"`
+}
+
+// CreateWebhook registers a webhook. The signing secret is only returned here.
+func (c *Client) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.Post(ctx, "/webhooks", req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks lists the account's webhooks
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var list WebhookList
+	if err := c.Get(ctx, "/webhooks", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// DeleteWebhook removes a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.Delete(ctx, "/webhooks/"+url.PathEscape(id))
+}
+
+// TestWebhook triggers a test delivery and waits for the endpoint's response
+func (c *Client) TestWebhook(ctx context.Context, id, event string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	body := map[string]string{"event": event}
+	if err := c.Post(ctx, "/webhooks/"+url.PathEscape(id)+"/test", body, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
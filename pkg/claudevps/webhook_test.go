@@ -0,0 +1,96 @@
+package claudevps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/webhooks" {
+			t.Errorf("Expected POST /webhooks, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var req CreateWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.URL != "https://example.com/hook" || len(req.Events) != 2 {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Webhook{ID: "wh-1", URL: req.URL, Events: req.Events, SigningSecret: "whsec_abc"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	webhook, err := client.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		URL:    "https://example.com/hook",
+		Events: []string{WebhookEventCreated, WebhookEventFailed},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if webhook.ID != "wh-1" || webhook.SigningSecret != "whsec_abc" {
+		t.Errorf("Unexpected webhook: %+v", webhook)
+	}
+}
+
+func TestTestWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/webhooks/wh-1/test" {
+			t.Errorf("Expected POST /webhooks/wh-1/test, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["event"] != WebhookEventIdle {
+			t.Errorf("Expected event %s, got %q", WebhookEventIdle, body["event"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WebhookDelivery{ID: "dl-1", Event: body["event"], StatusCode: 500, Error: "internal error"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	delivery, err := client.TestWebhook(context.Background(), "wh-1", WebhookEventIdle)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if delivery.Success || delivery.StatusCode != 500 {
+		t.Errorf("Unexpected delivery: %+v", delivery)
+	}
+}
+
+func TestListAndDeleteWebhooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/webhooks":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(WebhookList{Data: []Webhook{{ID: "wh-1", URL: "https://example.com/hook"}}})
+		case r.Method == "DELETE" && r.URL.Path == "/webhooks/wh-1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	webhooks, err := client.ListWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].ID != "wh-1" {
+		t.Errorf("Unexpected webhooks: %+v", webhooks)
+	}
+
+	if err := client.DeleteWebhook(context.Background(), "wh-1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}